@@ -17,7 +17,7 @@ type LnEncodeData struct {
 var EncodeLNURLFunc = EncodeLNURL
 
 func EncodeLNURL(host string) (LnEncodeData, error) {
-	hostUrl := config.Host
+	hostUrl := config.Current().Server.Host
 	if !strings.Contains(host, "localhost") {
 		hostUrl = "https://" + host
 	}
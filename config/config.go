@@ -18,63 +18,63 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
-var Host string
+// The auth middleware and the logger run outside any handler, so the
+// settings they need are kept here. Everything else receives the *Config.
 var JwtKey string
-var JarvisUrl string
-var JarvisToken string
-var RelayUrl string
-var MemeUrl string
-var RelayAuthKey string
-var RelayNodeKey string
 var SuperAdmins []string = []string{""}
 var LogLevel string
 
 // these are constants for the store
 var InvoiceList = "INVOICELIST"
 var BudgetInvoiceList = "BUDGETINVOICELIST"
-var S3BucketName string
-var S3FolderName string
-var S3Url string
-var AdminCheck string
 var AdminDevFreePass = "FREE_PASS"
 var Connection_Auth string
 var AdminStrings string
 
-var S3Client *s3.Client
-var PresignClient *s3.PresignClient
-
-var V2BotUrl string
-var V2BotToken string
-var IsV2Payment bool = false
-var FfWebsocket bool = false
 var SWAuth string
 
-func InitConfig() {
-	Host = os.Getenv("LN_SERVER_BASE_URL")
-	JwtKey = os.Getenv("LN_JWT_KEY")
-	JarvisUrl = os.Getenv("JARVIS_URL")
-	JarvisToken = os.Getenv("JARVIS_TOKEN")
-	RelayUrl = os.Getenv("RELAY_URL")
-	MemeUrl = os.Getenv("MEME_URL")
-	RelayAuthKey = os.Getenv("RELAY_AUTH_KEY")
-	AdminStrings = os.Getenv("ADMINS")
-	AwsSecret := os.Getenv("AWS_SECRET_ACCESS")
-	AwsAccess := os.Getenv("AWS_ACCESS_KEY_ID")
-	AwsRegion := os.Getenv("AWS_REGION")
-	S3BucketName = os.Getenv("S3_BUCKET_NAME")
-	S3FolderName = os.Getenv("S3_FOLDER_NAME")
-	S3Url = os.Getenv("S3_URL")
-	AdminCheck = os.Getenv("ADMIN_CHECK")
-	Connection_Auth = os.Getenv("CONNECTION_AUTH")
-	V2BotUrl = os.Getenv("V2_BOT_URL")
-	V2BotToken = os.Getenv("V2_BOT_TOKEN")
-	FfWebsocket = os.Getenv("FF_WEBSOCKET") == "true"
-	LogLevel = strings.ToUpper(os.Getenv("LOG_LEVEL"))
-	SWAuth = os.Getenv("SWAUTH")
+// InitConfig loads the configuration and exits with every validation error
+// if it is invalid. The returned config is the one to hand to the rest of
+// the server.
+func InitConfig() *Config {
+	cfg, err := Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	Apply(cfg)
+	return cfg
+}
+
+// Apply makes cfg the current configuration and sets the settings the auth
+// middleware and the logger read.
+func Apply(cfg *Config) {
+	if cfg.Auth.JwtKey == "" {
+		fmt.Println("JwtKey is empty , no Jwt found =================================")
+		cfg.Auth.JwtKey = GenerateRandomString()
+	}
+
+	SetCurrent(cfg)
+
+	JwtKey = cfg.Auth.JwtKey
+	AdminStrings = strings.Join(cfg.Auth.Admins, ",")
+	Connection_Auth = cfg.Auth.ConnectionAuth
+	LogLevel = cfg.Server.LogLevel
+	SWAuth = cfg.Auth.SWAuth
 
 	// Add to super admins
 	SuperAdmins = StripSuperAdmins(AdminStrings)
 
+	if cfg.IsV2Payment() {
+		fmt.Println("DEBUG == IT IS V2")
+	}
+}
+
+// NewPresignClient creates the client that signs S3 upload and download URLs.
+func NewPresignClient(cfg S3Config) *s3.PresignClient {
+	AwsSecret := cfg.SecretAccessKey
+	AwsAccess := cfg.AccessKeyID
+	AwsRegion := cfg.Region
+
 	var awsConfig aws.Config
 	var err error
 
@@ -93,60 +93,7 @@ func InitConfig() {
 		fmt.Println("Could not setup AWS session", err)
 	}
 
-	// create a s3 client session
-	S3Client = s3.NewFromConfig(awsConfig)
-	PresignClient = s3.NewPresignClient(S3Client)
-
-	// only make this call if there is a Relay auth key
-	if RelayAuthKey != "" {
-		RelayNodeKey = GetNodePubKey()
-	}
-
-	if V2BotUrl != "" && V2BotToken != "" {
-		// contact_key := GetV2ContactKey()
-		fmt.Println("DEBUG == IT IS V2")
-		IsV2Payment = true
-	}
-
-	if Host == "" {
-		Host = "https://people.sphinx.chat"
-		if os.Getenv("ALT_HOST") != "" {
-			Host = os.Getenv("ALT_HOST")
-		}
-	}
-
-	if MemeUrl == "" {
-		MemeUrl = "https://memes.sphinx.chat"
-	}
-
-	if JwtKey == "" {
-		fmt.Println("JwtKey is empty , no Jwt found =================================")
-		JwtKey = GenerateRandomString()
-	}
-
-	if S3BucketName == "" {
-		S3BucketName = "sphinx-tribes"
-	}
-
-	if S3FolderName == "" {
-		S3FolderName = "metrics"
-	}
-
-	if S3Url == "" {
-		S3Url = "https://sphinx-tribes.s3.amazonaws.com"
-	}
-
-	if JarvisUrl == "" {
-		JarvisUrl = ""
-	}
-
-	if JarvisToken == "" {
-		JarvisToken = ""
-	}
-
-	if LogLevel == "" {
-		LogLevel = "DEBUG"
-	}
+	return s3.NewPresignClient(s3.NewFromConfig(awsConfig))
 }
 
 func StripSuperAdmins(adminStrings string) []string {
@@ -312,8 +259,8 @@ type ProxyContacts struct {
 	Response ContactResponse `json:"response"`
 }
 
-func GetV2ContactKey() string {
-	url := fmt.Sprintf("%s/account", V2BotUrl)
+func GetV2ContactKey(bot V2BotConfig) string {
+	url := fmt.Sprintf("%s/account", bot.URL)
 
 	client := &http.Client{}
 	req, err := http.NewRequest(http.MethodGet, url, nil)
@@ -322,7 +269,7 @@ func GetV2ContactKey() string {
 		log.Printf("Get Contact Request Failed: %s", err)
 	}
 
-	req.Header.Set("x-admin-token", V2BotToken)
+	req.Header.Set("x-admin-token", bot.Token)
 	req.Header.Set("Content-Type", "application/json")
 	res, err := client.Do(req)
 
@@ -350,16 +297,16 @@ func GetV2ContactKey() string {
 	return contact_key
 }
 
-func GetNodePubKey() string {
+func GetNodePubKey(relay RelayConfig) string {
 	var pubkey string
 	var url string
 	var isProxy bool = false
 
-	if strings.Contains(RelayUrl, "swarm") {
-		url = fmt.Sprintf("%s/contacts", RelayUrl)
+	if strings.Contains(relay.URL, "swarm") {
+		url = fmt.Sprintf("%s/contacts", relay.URL)
 		isProxy = true
 	} else {
-		url = fmt.Sprintf("%s/getinfo", RelayUrl)
+		url = fmt.Sprintf("%s/getinfo", relay.URL)
 	}
 
 	client := &http.Client{}
@@ -369,7 +316,7 @@ func GetNodePubKey() string {
 		log.Printf("Request Failed: %s", err)
 	}
 
-	req.Header.Set("x-user-token", RelayAuthKey)
+	req.Header.Set("x-user-token", relay.AuthKey)
 	req.Header.Set("Content-Type", "application/json")
 	res, err := client.Do(req)
 
@@ -2,7 +2,6 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"testing"
 
 	"github.com/h2non/gock"
@@ -10,13 +9,13 @@ import (
 )
 
 func TestInitConfig(t *testing.T) {
-	InitConfig()
+	cfg := InitConfig()
 
-	if Host == "" {
+	if cfg.Server.Host == "" {
 		t.Error("Could not load default host")
 	}
 
-	if MemeUrl == "" {
+	if cfg.Meme.URL == "" {
 		t.Error("Could not load default meme url")
 	}
 
@@ -53,9 +52,7 @@ func TestGetNodePubKey(t *testing.T) {
 		Reply(200).
 		JSON(nodeGetInfo)
 
-	os.Setenv("RELAY_URL", "https://relay.com")
-	InitConfig()
-	nodePubKey := GetNodePubKey()
+	nodePubKey := GetNodePubKey(RelayConfig{URL: "https://relay.com"})
 	fmt.Print(nodePubKey)
 	if nodePubKey != "1234" {
 		t.Error("Node pubkey is incorrect")
@@ -0,0 +1,492 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the typed runtime configuration. Values come from the defaults
+// below, then an optional YAML file (CONFIG_FILE), then the environment, so an
+// env var always wins over the file.
+//
+// Fields are mapped to env vars with the `env` tag; fields tagged
+// `secret:"true"` are masked by Redacted.
+type Config struct {
+	Server       ServerConfig      `yaml:"server"`
+	Database     DatabaseConfig    `yaml:"database"`
+	Auth         AuthConfig        `yaml:"auth"`
+	Relay        RelayConfig       `yaml:"relay"`
+	V2Bot        V2BotConfig       `yaml:"v2_bot"`
+	Jarvis       JarvisConfig      `yaml:"jarvis"`
+	Meme         MemeConfig        `yaml:"meme"`
+	S3           S3Config          `yaml:"s3"`
+	Redis        RedisConfig       `yaml:"redis"`
+	Alerts       AlertConfig       `yaml:"alerts"`
+	Posthog      PosthogConfig     `yaml:"posthog"`
+	Jobs         JobsConfig        `yaml:"jobs"`
+	Storage      StorageConfig     `yaml:"storage"`
+	Knowledge    KnowledgeConfig   `yaml:"knowledge"`
+	Github       GithubConfig      `yaml:"github"`
+	FeatureFlags FeatureFlagConfig `yaml:"feature_flags"`
+}
+
+type ServerConfig struct {
+	Host            string `yaml:"host" env:"LN_SERVER_BASE_URL"`
+	AltHost         string `yaml:"alt_host" env:"ALT_HOST"`
+	Port            int    `yaml:"port" env:"PORT"`
+	LogLevel        string `yaml:"log_level" env:"LOG_LEVEL"`
+	SkipLoops       bool   `yaml:"skip_loops" env:"SKIP_LOOPS"`
+	ShutdownTimeout int    `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+// DatabaseConfig locates the postgres database, either as a single URL or
+// as the RDS_* parts it is built from.
+type DatabaseConfig struct {
+	URL      string `yaml:"url" env:"DATABASE_URL" secret:"true"`
+	Host     string `yaml:"host" env:"RDS_HOSTNAME"`
+	Port     int    `yaml:"port" env:"RDS_PORT"`
+	Name     string `yaml:"name" env:"RDS_DB_NAME"`
+	User     string `yaml:"user" env:"RDS_USERNAME"`
+	Password string `yaml:"password" env:"RDS_PASSWORD" secret:"true"`
+}
+
+type AuthConfig struct {
+	JwtKey         string   `yaml:"jwt_key" env:"LN_JWT_KEY" secret:"true"`
+	Admins         []string `yaml:"admins" env:"ADMINS"`
+	AdminCheck     string   `yaml:"admin_check" env:"ADMIN_CHECK" secret:"true"`
+	ConnectionAuth string   `yaml:"connection_auth" env:"CONNECTION_AUTH" secret:"true"`
+	SWAuth         string   `yaml:"swauth" env:"SWAUTH" secret:"true"`
+}
+
+type RelayConfig struct {
+	URL     string `yaml:"url" env:"RELAY_URL"`
+	AuthKey string `yaml:"auth_key" env:"RELAY_AUTH_KEY" secret:"true"`
+}
+
+type V2BotConfig struct {
+	URL   string `yaml:"url" env:"V2_BOT_URL"`
+	Token string `yaml:"token" env:"V2_BOT_TOKEN" secret:"true"`
+}
+
+type JarvisConfig struct {
+	URL   string `yaml:"url" env:"JARVIS_URL"`
+	Token string `yaml:"token" env:"JARVIS_TOKEN" secret:"true"`
+}
+
+type MemeConfig struct {
+	URL string `yaml:"url" env:"MEME_URL"`
+}
+
+type S3Config struct {
+	BucketName      string `yaml:"bucket_name" env:"S3_BUCKET_NAME"`
+	FolderName      string `yaml:"folder_name" env:"S3_FOLDER_NAME"`
+	URL             string `yaml:"url" env:"S3_URL"`
+	Region          string `yaml:"region" env:"AWS_REGION"`
+	AccessKeyID     string `yaml:"access_key_id" env:"AWS_ACCESS_KEY_ID" secret:"true"`
+	SecretAccessKey string `yaml:"secret_access_key" env:"AWS_SECRET_ACCESS" secret:"true"`
+}
+
+type RedisConfig struct {
+	URL      string `yaml:"url" env:"REDIS_URL" secret:"true"`
+	Host     string `yaml:"host" env:"REDIS_HOST"`
+	User     string `yaml:"user" env:"REDIS_USER"`
+	Password string `yaml:"password" env:"REDIS_PASS" secret:"true"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+}
+
+type AlertConfig struct {
+	URL       string `yaml:"url" env:"ALERT_URL"`
+	Secret    string `yaml:"secret" env:"ALERT_SECRET" secret:"true"`
+	TribeUUID string `yaml:"tribe_uuid" env:"ALERT_TRIBE_UUID"`
+	BotID     string `yaml:"bot_id" env:"ALERT_BOT_ID"`
+}
+
+type PosthogConfig struct {
+	Key string `yaml:"key" env:"POSTHOG_KEY" secret:"true"`
+	URL string `yaml:"url" env:"POSTHOG_URL"`
+}
+
+type JobsConfig struct {
+	LockBackend string `yaml:"lock_backend" env:"JOB_LOCK_BACKEND"`
+}
+
+// StorageConfig selects where chat uploads are stored. The s3 driver works
+// with any S3 compatible service, such as MinIO, through Endpoint and
+// UsePathStyle.
+type StorageConfig struct {
+	Driver           string `yaml:"driver" env:"STORAGE_DRIVER"`
+	LocalDir         string `yaml:"local_dir" env:"STORAGE_LOCAL_DIR"`
+	Bucket           string `yaml:"bucket" env:"STORAGE_BUCKET"`
+	Prefix           string `yaml:"prefix" env:"STORAGE_PREFIX"`
+	Endpoint         string `yaml:"endpoint" env:"STORAGE_ENDPOINT"`
+	Region           string `yaml:"region" env:"STORAGE_REGION"`
+	AccessKeyID      string `yaml:"access_key_id" env:"STORAGE_ACCESS_KEY_ID" secret:"true"`
+	SecretAccessKey  string `yaml:"secret_access_key" env:"STORAGE_SECRET_ACCESS_KEY" secret:"true"`
+	UsePathStyle     bool   `yaml:"use_path_style" env:"STORAGE_USE_PATH_STYLE"`
+	SigningKey       string `yaml:"signing_key" env:"STORAGE_SIGNING_KEY" secret:"true"`
+	SignedURLTTL     int    `yaml:"signed_url_ttl" env:"STORAGE_SIGNED_URL_TTL"`
+	WorkspaceQuotaMB int    `yaml:"workspace_quota_mb" env:"STORAGE_WORKSPACE_QUOTA_MB"`
+	ArchiveAfterDays int    `yaml:"archive_after_days" env:"STORAGE_ARCHIVE_AFTER_DAYS"`
+	DeleteAfterDays  int    `yaml:"delete_after_days" env:"STORAGE_DELETE_AFTER_DAYS"`
+}
+
+// KnowledgeConfig selects how workspace knowledge is ranked for chat and
+// ticket review context. The bm25 provider runs locally; the embeddings
+// provider calls an OpenAI compatible embeddings API and falls back to bm25
+// when it fails.
+type KnowledgeConfig struct {
+	Provider        string `yaml:"provider" env:"KNOWLEDGE_PROVIDER"`
+	EmbeddingsURL   string `yaml:"embeddings_url" env:"KNOWLEDGE_EMBEDDINGS_URL"`
+	EmbeddingsModel string `yaml:"embeddings_model" env:"KNOWLEDGE_EMBEDDINGS_MODEL"`
+	EmbeddingsKey   string `yaml:"embeddings_key" env:"KNOWLEDGE_EMBEDDINGS_KEY" secret:"true"`
+	MaxItems        int    `yaml:"max_items" env:"KNOWLEDGE_MAX_ITEMS"`
+	MaxChars        int    `yaml:"max_chars" env:"KNOWLEDGE_MAX_CHARS"`
+	RefreshSeconds  int    `yaml:"refresh_seconds" env:"KNOWLEDGE_REFRESH_SECONDS"`
+}
+
+// GithubConfig configures the GitHub API client and the sync of tickets with
+// GitHub issues. Issues are polled every SyncMinutes; zero turns polling off
+// and leaves the webhook as the only way to sync.
+type GithubConfig struct {
+	Token         string `yaml:"token" env:"GITHUB_TOKEN" secret:"true"`
+	WebhookSecret string `yaml:"webhook_secret" env:"GITHUB_WEBHOOK_SECRET" secret:"true"`
+	SyncMinutes   int    `yaml:"sync_minutes" env:"GITHUB_SYNC_MINUTES"`
+}
+
+type FeatureFlagConfig struct {
+	Websocket bool `yaml:"websocket" env:"FF_WEBSOCKET"`
+}
+
+const redactedValue = "[redacted]"
+
+var (
+	currentMu sync.RWMutex
+	current   *Config
+)
+
+func Defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            5002,
+			LogLevel:        "DEBUG",
+			ShutdownTimeout: 30,
+		},
+		Database: DatabaseConfig{Port: 5432},
+		Meme:     MemeConfig{URL: "https://memes.sphinx.chat"},
+		S3: S3Config{
+			BucketName: "sphinx-tribes",
+			FolderName: "metrics",
+			URL:        "https://sphinx-tribes.s3.amazonaws.com",
+		},
+		Jobs: JobsConfig{LockBackend: "postgres"},
+		Storage: StorageConfig{
+			Driver:           "local",
+			LocalDir:         "uploads/files",
+			SignedURLTTL:     900,
+			ArchiveAfterDays: 90,
+			DeleteAfterDays:  30,
+		},
+		Knowledge: KnowledgeConfig{
+			Provider:       "bm25",
+			MaxItems:       5,
+			MaxChars:       12000,
+			RefreshSeconds: 300,
+		},
+		Github: GithubConfig{SyncMinutes: 15},
+	}
+}
+
+// Current returns the configuration loaded by InitConfig, or the defaults
+// if it has not run yet.
+func Current() *Config {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	if current == nil {
+		return Defaults()
+	}
+	return current
+}
+
+func SetCurrent(cfg *Config) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = cfg
+}
+
+// Load reads the config file at path, if any, and the process environment.
+func Load(path string) (*Config, error) {
+	return LoadFrom(path, os.Getenv)
+}
+
+func LoadFrom(path string, getenv func(string) string) (*Config, error) {
+	cfg := Defaults()
+
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open config file: %w", err)
+		}
+		defer file.Close()
+
+		decoder := yaml.NewDecoder(file)
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(cfg).Elem(), getenv); err != nil {
+		return nil, err
+	}
+
+	cfg.normalize()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) normalize() {
+	if c.Server.Host == "" {
+		c.Server.Host = "https://people.sphinx.chat"
+		if c.Server.AltHost != "" {
+			c.Server.Host = c.Server.AltHost
+		}
+	}
+	if c.Server.LogLevel == "" {
+		c.Server.LogLevel = "DEBUG"
+	}
+	c.Server.LogLevel = strings.ToUpper(c.Server.LogLevel)
+	c.Jobs.LockBackend = strings.ToLower(c.Jobs.LockBackend)
+	c.Storage.Driver = strings.ToLower(c.Storage.Driver)
+	if c.Storage.Region == "" {
+		c.Storage.Region = c.S3.Region
+	}
+	c.Knowledge.Provider = strings.ToLower(c.Knowledge.Provider)
+}
+
+// Validate reports every invalid or missing value at once.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		add("PORT must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	switch c.Server.LogLevel {
+	case "MACHINE", "DEBUG", "INFO", "WARNING", "ERROR":
+	default:
+		add("LOG_LEVEL must be one of MACHINE, DEBUG, INFO, WARNING or ERROR, got %q", c.Server.LogLevel)
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		add("SHUTDOWN_TIMEOUT must be greater than zero")
+	}
+
+	problems = append(problems, c.Database.problems()...)
+
+	if c.Relay.AuthKey != "" && c.Relay.URL == "" {
+		add("RELAY_URL is required when RELAY_AUTH_KEY is set")
+	}
+	if (c.V2Bot.URL == "") != (c.V2Bot.Token == "") {
+		add("V2_BOT_URL and V2_BOT_TOKEN must be set together")
+	}
+
+	urls := []struct{ name, value string }{
+		{"LN_SERVER_BASE_URL", c.Server.Host},
+		{"RELAY_URL", c.Relay.URL},
+		{"V2_BOT_URL", c.V2Bot.URL},
+		{"MEME_URL", c.Meme.URL},
+		{"S3_URL", c.S3.URL},
+		{"ALERT_URL", c.Alerts.URL},
+		{"STORAGE_ENDPOINT", c.Storage.Endpoint},
+		{"KNOWLEDGE_EMBEDDINGS_URL", c.Knowledge.EmbeddingsURL},
+	}
+	for _, u := range urls {
+		if u.value != "" && !isHTTPURL(u.value) {
+			add("%s must be an http(s) URL, got %q", u.name, u.value)
+		}
+	}
+
+	if c.Redis.DB < 0 {
+		add("REDIS_DB must not be negative")
+	}
+	if c.Jobs.LockBackend != "postgres" && c.Jobs.LockBackend != "redis" {
+		add("JOB_LOCK_BACKEND must be postgres or redis, got %q", c.Jobs.LockBackend)
+	}
+
+	switch c.Storage.Driver {
+	case "local":
+		if c.Storage.LocalDir == "" {
+			add("STORAGE_LOCAL_DIR is required for the local storage driver")
+		}
+	case "s3":
+		if c.Storage.Bucket == "" {
+			add("STORAGE_BUCKET is required for the s3 storage driver")
+		}
+	default:
+		add("STORAGE_DRIVER must be local or s3, got %q", c.Storage.Driver)
+	}
+	if c.Storage.SignedURLTTL <= 0 {
+		add("STORAGE_SIGNED_URL_TTL must be greater than zero")
+	}
+	if c.Storage.WorkspaceQuotaMB < 0 {
+		add("STORAGE_WORKSPACE_QUOTA_MB must not be negative")
+	}
+	if c.Storage.ArchiveAfterDays <= 0 || c.Storage.DeleteAfterDays <= 0 {
+		add("STORAGE_ARCHIVE_AFTER_DAYS and STORAGE_DELETE_AFTER_DAYS must be greater than zero")
+	}
+
+	switch c.Knowledge.Provider {
+	case "bm25":
+	case "embeddings":
+		if c.Knowledge.EmbeddingsURL == "" || c.Knowledge.EmbeddingsModel == "" {
+			add("KNOWLEDGE_EMBEDDINGS_URL and KNOWLEDGE_EMBEDDINGS_MODEL are required for the embeddings knowledge provider")
+		}
+	default:
+		add("KNOWLEDGE_PROVIDER must be bm25 or embeddings, got %q", c.Knowledge.Provider)
+	}
+	if c.Knowledge.MaxItems < 0 || c.Knowledge.MaxChars < 0 {
+		add("KNOWLEDGE_MAX_ITEMS and KNOWLEDGE_MAX_CHARS must not be negative")
+	}
+	if c.Knowledge.RefreshSeconds <= 0 {
+		add("KNOWLEDGE_REFRESH_SECONDS must be greater than zero")
+	}
+	if c.Github.SyncMinutes < 0 {
+		add("GITHUB_SYNC_MINUTES must not be negative")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// problems reports a malformed DATABASE_URL or an incomplete set of RDS_*
+// values. A database that is not configured at all is reported by DSN, so
+// commands that never connect can still load the configuration.
+func (d DatabaseConfig) problems() []string {
+	var problems []string
+	if d.URL != "" {
+		if u, err := url.Parse(d.URL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") || u.Host == "" {
+			problems = append(problems, "DATABASE_URL must be a postgres:// URL")
+		}
+		return problems
+	}
+	if d.Host != "" || d.Name != "" || d.User != "" || d.Password != "" {
+		if d.Host == "" || d.Name == "" || d.User == "" {
+			problems = append(problems, "RDS_HOSTNAME, RDS_DB_NAME and RDS_USERNAME must be set together")
+		}
+	}
+	if d.Port < 1 || d.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("RDS_PORT must be between 1 and 65535, got %d", d.Port))
+	}
+	return problems
+}
+
+// DSN returns DATABASE_URL, or the URL built from the RDS_* values when it
+// is not set.
+func (d DatabaseConfig) DSN() (string, error) {
+	if d.URL != "" {
+		return d.URL, nil
+	}
+	if d.Host == "" || d.Name == "" || d.User == "" {
+		return "", errors.New("DATABASE_URL or RDS_HOSTNAME, RDS_DB_NAME and RDS_USERNAME are required")
+	}
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(d.User, d.Password),
+		Host:   fmt.Sprintf("%s:%d", d.Host, d.Port),
+		Path:   "/" + d.Name,
+	}
+	return u.String(), nil
+}
+
+func (c *Config) IsV2Payment() bool {
+	return c.V2Bot.URL != "" && c.V2Bot.Token != ""
+}
+
+// Redacted returns the configuration keyed like the YAML file with every
+// secret that is set replaced by a placeholder.
+func (c *Config) Redacted() map[string]interface{} {
+	return redact(reflect.ValueOf(c).Elem())
+}
+
+func redact(v reflect.Value) map[string]interface{} {
+	out := map[string]interface{}{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		value := v.Field(i)
+
+		switch {
+		case value.Kind() == reflect.Struct:
+			out[name] = redact(value)
+		case field.Tag.Get("secret") == "true" && !value.IsZero():
+			out[name] = redactedValue
+		default:
+			out[name] = value.Interface()
+		}
+	}
+	return out
+}
+
+func applyEnv(v reflect.Value, getenv func(string) string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+
+		if value.Kind() == reflect.Struct {
+			if err := applyEnv(value, getenv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		raw := getenv(name)
+		if raw == "" {
+			continue
+		}
+
+		switch value.Kind() {
+		case reflect.String:
+			value.SetString(raw)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("%s must be true or false, got %q", name, raw)
+			}
+			value.SetBool(b)
+		case reflect.Int:
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return fmt.Errorf("%s must be a number, got %q", name, raw)
+			}
+			value.SetInt(int64(n))
+		case reflect.Slice:
+			value.Set(reflect.ValueOf(StripSuperAdmins(raw)))
+		}
+	}
+	return nil
+}
+
+func isHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
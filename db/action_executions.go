@@ -0,0 +1,112 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// ErrActionAlreadyExecuted is returned when an option of an action artifact
+// is running or has already succeeded.
+var ErrActionAlreadyExecuted = errors.New("action option has already been executed")
+
+// ActionClaimTimeout is how long an execution may stay running before it is
+// taken to have died with its server and may be claimed again.
+const ActionClaimTimeout = 5 * time.Minute
+
+// ClaimActionExecution records that an option of an action artifact is
+// about to run. It fails with ErrActionAlreadyExecuted unless the option has
+// never run, its last execution failed or it has been running for longer
+// than ActionClaimTimeout, in which case the execution is started again.
+func (db database) ClaimActionExecution(execution *ActionExecution) (ActionExecution, error) {
+	if execution.ArtifactID == uuid.Nil {
+		return ActionExecution{}, errors.New("artifact ID is required")
+	}
+	if execution.Kind == "" {
+		return ActionExecution{}, errors.New("action kind is required")
+	}
+
+	now := time.Now()
+	execution.ID = uuid.New()
+	execution.Status = ActionRunning
+	execution.Result = PropertyMap{}
+	execution.Error = ""
+	execution.ResultArtifactID = nil
+	execution.CreatedAt = now
+	execution.UpdatedAt = now
+
+	// the unique index lets only one request claim an option
+	result := db.db.Clauses(clause.OnConflict{DoNothing: true}).Create(execution)
+	if result.Error != nil {
+		return ActionExecution{}, fmt.Errorf("failed to record action execution: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return *execution, nil
+	}
+
+	result = db.db.Model(&ActionExecution{}).
+		Where("artifact_id = ? AND option_index = ? AND (status = ? OR (status = ? AND updated_at < ?))",
+			execution.ArtifactID, execution.OptionIndex, ActionFailed, ActionRunning, now.Add(-ActionClaimTimeout)).
+		Updates(map[string]interface{}{
+			"status":             ActionRunning,
+			"kind":               execution.Kind,
+			"chat_id":            execution.ChatID,
+			"result":             PropertyMap{},
+			"error":              "",
+			"result_artifact_id": nil,
+			"executed_by":        execution.ExecutedBy,
+			"updated_at":         now,
+		})
+	if result.Error != nil {
+		return ActionExecution{}, fmt.Errorf("failed to retry action execution: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ActionExecution{}, ErrActionAlreadyExecuted
+	}
+
+	var claimed ActionExecution
+	if err := db.db.Where("artifact_id = ? AND option_index = ?", execution.ArtifactID, execution.OptionIndex).First(&claimed).Error; err != nil {
+		return ActionExecution{}, fmt.Errorf("failed to fetch action execution: %w", err)
+	}
+	return claimed, nil
+}
+
+// CompleteActionExecution stores the outcome of a claimed execution.
+func (db database) CompleteActionExecution(execution *ActionExecution) (ActionExecution, error) {
+	if execution.ID == uuid.Nil {
+		return ActionExecution{}, errors.New("action execution ID is required")
+	}
+	if execution.Status != ActionSucceeded && execution.Status != ActionFailed {
+		return ActionExecution{}, fmt.Errorf("invalid action execution status: %s", execution.Status)
+	}
+	if execution.Result == nil {
+		execution.Result = PropertyMap{}
+	}
+
+	execution.UpdatedAt = time.Now()
+	result := db.db.Model(&ActionExecution{}).Where("id = ?", execution.ID).Updates(map[string]interface{}{
+		"status":             execution.Status,
+		"result":             execution.Result,
+		"error":              execution.Error,
+		"result_artifact_id": execution.ResultArtifactID,
+		"updated_at":         execution.UpdatedAt,
+	})
+	if result.Error != nil {
+		return ActionExecution{}, fmt.Errorf("failed to update action execution: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ActionExecution{}, fmt.Errorf("action execution not found")
+	}
+	return *execution, nil
+}
+
+func (db database) GetActionExecutions(artifactID uuid.UUID) ([]ActionExecution, error) {
+	executions := []ActionExecution{}
+	if err := db.db.Where("artifact_id = ?", artifactID).Order("option_index ASC").Find(&executions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch action executions: %w", err)
+	}
+	return executions, nil
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionExecutions(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	artifactID := uuid.New()
+	claim := func(optionIndex int) (ActionExecution, error) {
+		return TestDB.ClaimActionExecution(&ActionExecution{
+			ArtifactID:  artifactID,
+			OptionIndex: optionIndex,
+			ChatID:      "action-chat",
+			Kind:        CreateTicketAction,
+			ExecutedBy:  "action-user",
+		})
+	}
+
+	t.Run("Option Is Claimed Once", func(t *testing.T) {
+		execution, err := claim(0)
+		require.NoError(t, err)
+		assert.Equal(t, ActionRunning, execution.Status)
+
+		_, err = claim(0)
+		assert.ErrorIs(t, err, ErrActionAlreadyExecuted)
+
+		execution.Status = ActionSucceeded
+		execution.Result = PropertyMap{"ticket_uuid": "t-1"}
+		_, err = TestDB.CompleteActionExecution(&execution)
+		require.NoError(t, err)
+
+		_, err = claim(0)
+		assert.ErrorIs(t, err, ErrActionAlreadyExecuted)
+	})
+
+	t.Run("Failed Option Can Be Retried", func(t *testing.T) {
+		execution, err := claim(1)
+		require.NoError(t, err)
+		execution.Status = ActionFailed
+		execution.Error = "feature not found"
+		_, err = TestDB.CompleteActionExecution(&execution)
+		require.NoError(t, err)
+
+		retried, err := claim(1)
+		require.NoError(t, err)
+		assert.Equal(t, execution.ID, retried.ID)
+		assert.Equal(t, ActionRunning, retried.Status)
+		assert.Empty(t, retried.Error)
+	})
+
+	t.Run("Stale Running Option Can Be Claimed Again", func(t *testing.T) {
+		execution, err := claim(2)
+		require.NoError(t, err)
+
+		_, err = claim(2)
+		assert.ErrorIs(t, err, ErrActionAlreadyExecuted)
+
+		stale := time.Now().Add(-ActionClaimTimeout - time.Minute)
+		require.NoError(t, TestDB.db.Model(&ActionExecution{}).Where("id = ?", execution.ID).Update("updated_at", stale).Error)
+
+		reclaimed, err := claim(2)
+		require.NoError(t, err)
+		assert.Equal(t, execution.ID, reclaimed.ID)
+		assert.Equal(t, ActionRunning, reclaimed.Status)
+	})
+
+	t.Run("List Executions", func(t *testing.T) {
+		executions, err := TestDB.GetActionExecutions(artifactID)
+		require.NoError(t, err)
+		require.Len(t, executions, 3)
+		assert.Equal(t, 0, executions[0].OptionIndex)
+		assert.Equal(t, "t-1", executions[0].Result["ticket_uuid"])
+	})
+}
@@ -7,7 +7,6 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"net/http"
-	"os"
 	"strconv"
 
 	"github.com/stakwork/sphinx-tribes/logger"
@@ -32,10 +31,11 @@ func (db database) ProcessAlerts(p Person) {
 	// if they match, build an Action with their pubkey
 	// post all the Actions you have build to relay with the HMAC header
 
-	relayUrl := os.Getenv("ALERT_URL")
-	alertSecret := os.Getenv("ALERT_SECRET")
-	alertTribeUuid := os.Getenv("ALERT_TRIBE_UUID")
-	botId := os.Getenv("ALERT_BOT_ID")
+	alerts := db.alerts
+	relayUrl := alerts.URL
+	alertSecret := alerts.Secret
+	alertTribeUuid := alerts.TribeUUID
+	botId := alerts.BotID
 	if relayUrl == "" || alertSecret == "" || alertTribeUuid == "" || botId == "" {
 		logger.Log.Info("Ticket alerts: ENV information not found")
 		return
@@ -45,6 +45,8 @@ func (db database) CreateArtifact(artifact *Artifact) (*Artifact, error) {
 		return nil, fmt.Errorf("failed to create artifact: %w", err)
 	}
 
+	db.recordArtifactEvent(artifact)
+
 	return artifact, nil
 }
 
@@ -107,6 +109,8 @@ func (db database) UpdateArtifact(artifact *Artifact) (*Artifact, error) {
 		return nil, fmt.Errorf("failed to update artifact: %w", err)
 	}
 
+	db.recordArtifactEvent(artifact)
+
 	return artifact, nil
 }
 
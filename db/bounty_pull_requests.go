@@ -0,0 +1,137 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrPullRequestNotFound is returned for pull request links that do not exist.
+	ErrPullRequestNotFound = errors.New("pull request not found")
+	// ErrPullRequestLinked is returned when a pull request is already linked to the bounty.
+	ErrPullRequestLinked = errors.New("pull request is already linked to the bounty")
+)
+
+// CreateBountyPullRequest links a pull request to a bounty. Owner and repo
+// are stored lowercased, the way GitHub matches them.
+func (db database) CreateBountyPullRequest(pull *BountyPullRequest) (BountyPullRequest, error) {
+	if pull.BountyID == 0 {
+		return BountyPullRequest{}, errors.New("bounty ID is required")
+	}
+	if pull.Owner == "" || pull.Repo == "" || pull.Number < 1 {
+		return BountyPullRequest{}, errors.New("owner, repo and pull request number are required")
+	}
+	pull.Owner = strings.ToLower(pull.Owner)
+	pull.Repo = strings.ToLower(pull.Repo)
+
+	var count int64
+	db.db.Model(&BountyPullRequest{}).
+		Where("bounty_id = ? AND owner = ? AND repo = ? AND number = ?", pull.BountyID, pull.Owner, pull.Repo, pull.Number).
+		Count(&count)
+	if count > 0 {
+		return BountyPullRequest{}, ErrPullRequestLinked
+	}
+
+	if pull.ID == uuid.Nil {
+		pull.ID = uuid.New()
+	}
+	if pull.State == "" {
+		pull.State = PullRequestOpen
+	}
+	now := time.Now()
+	pull.CreatedAt = now
+	pull.UpdatedAt = now
+
+	if err := db.db.Create(pull).Error; err != nil {
+		return BountyPullRequest{}, fmt.Errorf("failed to link pull request: %w", err)
+	}
+	return *pull, nil
+}
+
+func (db database) GetBountyPullRequest(id uuid.UUID) (BountyPullRequest, error) {
+	var pull BountyPullRequest
+	err := db.db.Where("id = ?", id).First(&pull).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return BountyPullRequest{}, ErrPullRequestNotFound
+	}
+	if err != nil {
+		return BountyPullRequest{}, fmt.Errorf("failed to fetch pull request: %w", err)
+	}
+	return pull, nil
+}
+
+func (db database) GetBountyPullRequests(bountyID uint) ([]BountyPullRequest, error) {
+	var pulls []BountyPullRequest
+	if err := db.db.Where("bounty_id = ?", bountyID).Order("created_at ASC").Find(&pulls).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch bounty pull requests: %w", err)
+	}
+	return pulls, nil
+}
+
+// GetBountyPullRequestsByPull lists the bounties a pull request is linked to.
+func (db database) GetBountyPullRequestsByPull(owner, repo string, number int) ([]BountyPullRequest, error) {
+	var pulls []BountyPullRequest
+	err := db.db.Where("owner = ? AND repo = ? AND number = ?", strings.ToLower(owner), strings.ToLower(repo), number).
+		Order("created_at ASC").
+		Find(&pulls).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bounty pull requests: %w", err)
+	}
+	return pulls, nil
+}
+
+// GetQueuedBountyPullRequests lists the merged pull requests whose bounty
+// payment is queued and not processed yet, oldest first.
+func (db database) GetQueuedBountyPullRequests() ([]BountyPullRequest, error) {
+	var pulls []BountyPullRequest
+	err := db.db.Where("payment_queued_at IS NOT NULL AND payment_processed_at IS NULL").
+		Order("payment_queued_at ASC").
+		Find(&pulls).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch queued bounty payments: %w", err)
+	}
+	return pulls, nil
+}
+
+// UpdateBountyPullRequest saves the state, options and payment queue of a
+// pull request link.
+func (db database) UpdateBountyPullRequest(pull *BountyPullRequest) error {
+	pull.UpdatedAt = time.Now()
+	result := db.db.Model(&BountyPullRequest{}).
+		Where("id = ?", pull.ID).
+		Updates(map[string]interface{}{
+			"title":                pull.Title,
+			"state":                pull.State,
+			"proof_id":             pull.ProofID,
+			"auto_complete":        pull.AutoComplete,
+			"auto_pay":             pull.AutoPay,
+			"auto_pay_by":          pull.AutoPayBy,
+			"merged_at":            pull.MergedAt,
+			"payment_queued_at":    pull.PaymentQueuedAt,
+			"payment_processed_at": pull.PaymentProcessedAt,
+			"updated_at":           pull.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update pull request: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPullRequestNotFound
+	}
+	return nil
+}
+
+func (db database) DeleteBountyPullRequest(id uuid.UUID) error {
+	result := db.db.Where("id = ?", id).Delete(&BountyPullRequest{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete pull request: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPullRequestNotFound
+	}
+	return nil
+}
@@ -1,12 +1,15 @@
 package db
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func (db database) AddChat(chat *Chat) (Chat, error) {
@@ -17,6 +20,9 @@ func (db database) AddChat(chat *Chat) (Chat, error) {
 	now := time.Now()
 	chat.CreatedAt = now
 	chat.UpdatedAt = now
+	if chat.ActiveBranchID == "" {
+		chat.ActiveBranchID = MainChatBranch
+	}
 
 	if err := db.db.Create(&chat).Error; err != nil {
 		return Chat{}, fmt.Errorf("failed to create chat: %w", err)
@@ -44,6 +50,9 @@ func (db database) UpdateChat(chat *Chat) (Chat, error) {
 	if chat.Status != "" {
 		existingChat.Status = chat.Status
 	}
+	if chat.Visibility != "" {
+		existingChat.Visibility = chat.Visibility
+	}
 	existingChat.UpdatedAt = time.Now()
 
 	if err := db.db.Save(&existingChat).Error; err != nil {
@@ -75,10 +84,16 @@ func (db database) AddChatMessage(chatMessage *ChatMessage) (ChatMessage, error)
 	now := time.Now()
 	chatMessage.Timestamp = now
 
+	if err := placeChatMessage(db.db, chatMessage); err != nil {
+		return ChatMessage{}, err
+	}
+
 	if err := db.db.Create(&chatMessage).Error; err != nil {
 		return ChatMessage{}, fmt.Errorf("failed to create chat message: %w", err)
 	}
 
+	db.recordChatEvent(chatMessage.ChatID, ChatMessageEvent, chatMessage)
+
 	return *chatMessage, nil
 }
 
@@ -108,9 +123,196 @@ func (db database) UpdateChatMessage(chatMessage *ChatMessage) (ChatMessage, err
 		return ChatMessage{}, fmt.Errorf("failed to update chat message: %w", err)
 	}
 
+	db.recordChatEvent(existingMessage.ChatID, ChatMessageEvent, existingMessage)
+
 	return existingMessage, nil
 }
 
+// ErrNotStreamingReply is returned for stream updates to a message that is
+// not an assistant reply of the chat.
+var ErrNotStreamingReply = errors.New("message is not an assistant reply of the chat")
+
+// lockStreamingReply loads an assistant reply of the chat for update.
+func lockStreamingReply(tx *gorm.DB, chatID, messageID string) (ChatMessage, error) {
+	var message ChatMessage
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&message, "id = ?", messageID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ChatMessage{}, ErrNotStreamingReply
+	}
+	if err != nil {
+		return ChatMessage{}, fmt.Errorf("failed to fetch streaming message: %w", err)
+	}
+	if message.ChatID != chatID || message.Role != AssistantRole {
+		return ChatMessage{}, ErrNotStreamingReply
+	}
+	return message, nil
+}
+
+// AppendChatMessageChunk adds a chunk of a streamed reply to an assistant
+// message that is still streaming. Chunks are applied in sequence order: a
+// chunk that arrives early is kept until the chunks before it arrive, and
+// applied returns every chunk the call applied. Chunks that were already
+// applied and chunks that arrive after the message was finalized are
+// ignored.
+func (db database) AppendChatMessageChunk(chatID, messageID string, sequence int, delta string) (message ChatMessage, applied []ChatMessageDelta, err error) {
+	if chatID == "" || messageID == "" {
+		return ChatMessage{}, nil, errors.New("chat ID and message ID are required")
+	}
+
+	err = db.db.Transaction(func(tx *gorm.DB) error {
+		applied = nil
+		message, err = lockStreamingReply(tx, chatID, messageID)
+		if err != nil {
+			return err
+		}
+		if message.Status != StreamingStatus || (sequence > 0 && sequence <= message.StreamSequence) {
+			return nil
+		}
+
+		if sequence > message.StreamSequence+1 {
+			chunk := ChatMessageChunk{MessageID: messageID, Sequence: sequence, Delta: delta}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&chunk).Error; err != nil {
+				return fmt.Errorf("failed to keep message chunk: %w", err)
+			}
+			return nil
+		}
+
+		applied = append(applied, ChatMessageDelta{MessageID: messageID, ChatID: chatID, Sequence: sequence, Delta: delta})
+		if sequence > 0 {
+			var pending []ChatMessageChunk
+			if err := tx.Where("message_id = ? AND sequence > ?", messageID, sequence).Order("sequence ASC").Find(&pending).Error; err != nil {
+				return fmt.Errorf("failed to fetch kept message chunks: %w", err)
+			}
+			next := sequence + 1
+			for _, chunk := range pending {
+				if chunk.Sequence != next {
+					break
+				}
+				applied = append(applied, ChatMessageDelta{MessageID: messageID, ChatID: chatID, Sequence: chunk.Sequence, Delta: chunk.Delta})
+				next++
+			}
+			message.StreamSequence = next - 1
+			if err := tx.Where("message_id = ? AND sequence <= ?", messageID, message.StreamSequence).Delete(&ChatMessageChunk{}).Error; err != nil {
+				return fmt.Errorf("failed to remove applied message chunks: %w", err)
+			}
+		}
+
+		for _, chunk := range applied {
+			message.Message += chunk.Delta
+		}
+		if err := tx.Model(&ChatMessage{}).Where("id = ?", messageID).Updates(map[string]interface{}{
+			"message":         message.Message,
+			"stream_sequence": message.StreamSequence,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to append message chunk: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return ChatMessage{}, nil, err
+	}
+
+	for _, chunk := range applied {
+		db.recordChatEvent(chatID, ChatMessageDeltaEvent, chunk)
+	}
+
+	return message, applied, nil
+}
+
+// FinalizeChatMessage completes a streamed assistant reply. A non empty
+// response replaces the streamed text, otherwise chunks still waiting for
+// earlier ones are added in order. finalized is false when the reply was
+// already complete, which leaves it unchanged.
+func (db database) FinalizeChatMessage(chatID, messageID, response string) (message ChatMessage, finalized bool, err error) {
+	if chatID == "" || messageID == "" {
+		return ChatMessage{}, false, errors.New("chat ID and message ID are required")
+	}
+
+	err = db.db.Transaction(func(tx *gorm.DB) error {
+		finalized = false
+		message, err = lockStreamingReply(tx, chatID, messageID)
+		if err != nil {
+			return err
+		}
+		if message.Status != StreamingStatus {
+			return nil
+		}
+
+		if response != "" {
+			message.Message = response
+		} else {
+			var pending []ChatMessageChunk
+			if err := tx.Where("message_id = ?", messageID).Order("sequence ASC").Find(&pending).Error; err != nil {
+				return fmt.Errorf("failed to fetch kept message chunks: %w", err)
+			}
+			for _, chunk := range pending {
+				message.Message += chunk.Delta
+			}
+		}
+		if err := tx.Where("message_id = ?", messageID).Delete(&ChatMessageChunk{}).Error; err != nil {
+			return fmt.Errorf("failed to remove message chunks: %w", err)
+		}
+
+		message.Status = SentStatus
+		message.Timestamp = time.Now()
+		if err := tx.Model(&ChatMessage{}).Where("id = ?", messageID).Updates(map[string]interface{}{
+			"message":   message.Message,
+			"status":    message.Status,
+			"timestamp": message.Timestamp,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to finalize message: %w", err)
+		}
+		finalized = true
+		return nil
+	})
+	if err != nil {
+		return ChatMessage{}, false, err
+	}
+
+	if finalized {
+		db.recordChatEvent(chatID, ChatMessageEvent, message)
+	}
+	return message, finalized, nil
+}
+
+// ExpireStreamingChatMessages marks assistant replies created before the
+// given time that are still streaming as failed, dropping the chunks kept
+// for them. It returns the expired replies.
+func (db database) ExpireStreamingChatMessages(before time.Time) ([]ChatMessage, error) {
+	var expired []ChatMessage
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("role = ? AND status = ? AND timestamp < ?", AssistantRole, StreamingStatus, before).
+			Find(&expired).Error; err != nil {
+			return fmt.Errorf("failed to fetch streaming messages: %w", err)
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(expired))
+		for i := range expired {
+			ids[i] = expired[i].ID
+			expired[i].Status = ErrorStatus
+		}
+		if err := tx.Where("message_id IN ?", ids).Delete(&ChatMessageChunk{}).Error; err != nil {
+			return fmt.Errorf("failed to remove message chunks: %w", err)
+		}
+		if err := tx.Model(&ChatMessage{}).Where("id IN ?", ids).Update("status", ErrorStatus).Error; err != nil {
+			return fmt.Errorf("failed to expire streaming messages: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, message := range expired {
+		db.recordChatEvent(message.ChatID, ChatMessageEvent, message)
+	}
+	return expired, nil
+}
+
 func (db database) GetChatMessagesForChatID(chatID string) ([]ChatMessage, error) {
 	var chatMessages []ChatMessage
 
@@ -190,6 +392,19 @@ func (db database) GetFileAssetByID(id uint) (*FileAsset, error) {
 	return &asset, nil
 }
 
+// GetFileAssetsByStoragePaths returns the files stored at the given URLs,
+// which is how chat messages refer to their attachments.
+func (db database) GetFileAssetsByStoragePaths(paths []string) ([]FileAsset, error) {
+	assets := []FileAsset{}
+	if len(paths) == 0 {
+		return assets, nil
+	}
+	if err := db.db.Where("storage_path IN ? AND status != ?", paths, DeletedFileStatus).Find(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch file assets: %w", err)
+	}
+	return assets, nil
+}
+
 func (db database) UpdateFileAssetReference(id uint) error {
 	result := db.db.Model(&FileAsset{}).
 		Where("id = ?", id).
@@ -207,6 +422,26 @@ func (db database) UpdateFileAssetReference(id uint) error {
 	return nil
 }
 
+// IsFileAssetReferenced reports whether a chat message still refers to the
+// file, as its attachment or with a file context tag.
+func (db database) IsFileAssetReferenced(asset FileAsset) (bool, error) {
+	tag, err := json.Marshal(ContextTags{{Type: FileContext, ID: strconv.FormatUint(uint64(asset.ID), 10)}})
+	if err != nil {
+		return false, err
+	}
+
+	query := db.db.Model(&ChatMessage{}).Where("context_tags @> ?::jsonb", string(tag))
+	if asset.StoragePath != "" {
+		query = query.Or("pdf_url = ?", asset.StoragePath)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to count references of file %d: %w", asset.ID, err)
+	}
+	return count > 0, nil
+}
+
 func (db database) ListFileAssets(params ListFileAssetsParams) ([]FileAsset, int64, error) {
 	var assets []FileAsset
 	var total int64
@@ -252,6 +487,8 @@ func (db database) UpdateFileAsset(asset *FileAsset) error {
 	return db.db.Save(asset).Error
 }
 
+// DeleteFileAsset marks a file as deleted and removes its text chunks in the
+// same transaction.
 func (db database) DeleteFileAsset(id uint) error {
 
 	var asset FileAsset
@@ -259,21 +496,26 @@ func (db database) DeleteFileAsset(id uint) error {
 		return fmt.Errorf("file not found: %w", err)
 	}
 
-	now := time.Now()
-	result := db.db.Model(&FileAsset{}).
-		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"status":     DeletedFileStatus,
-			"deleted_at": &now,
-		})
+	return db.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&FileAsset{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"status":     DeletedFileStatus,
+				"deleted_at": &now,
+			})
 
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("no file asset found with id %d", id)
-	}
-	return nil
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("no file asset found with id %d", id)
+		}
+		if err := tx.Where("file_id = ?", id).Delete(&FileChunk{}).Error; err != nil {
+			return fmt.Errorf("failed to remove chunks: %w", err)
+		}
+		return nil
+	})
 }
 
 func (db database) CreateOrEditChatWorkflow(workflow *ChatWorkflow) (*ChatWorkflow, error) {
@@ -346,6 +588,8 @@ func (db database) AddChatStatus(status *ChatWorkflowStatus) (ChatWorkflowStatus
 		return ChatWorkflowStatus{}, fmt.Errorf("failed to create chat status: %w", err)
 	}
 
+	db.recordChatEvent(status.ChatID, ChatWorkflowStatusEvent, status)
+
 	return *status, nil
 }
 
@@ -384,6 +628,8 @@ func (db database) UpdateChatStatus(status *ChatWorkflowStatus) (ChatWorkflowSta
 		return ChatWorkflowStatus{}, fmt.Errorf("failed to update chat status: %w", err)
 	}
 
+	db.recordChatEvent(existingStatus.ChatID, ChatWorkflowStatusEvent, existingStatus)
+
 	return existingStatus, nil
 }
 
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// placeChatMessage puts a message that does not say where it belongs at the
+// end of the active branch of its chat.
+func placeChatMessage(tx *gorm.DB, message *ChatMessage) error {
+	if message.BranchID == "" {
+		branchID, err := activeChatBranch(tx, message.ChatID)
+		if err != nil {
+			return err
+		}
+		message.BranchID = branchID
+	}
+
+	if message.ParentID == "" {
+		parentID, err := chatBranchLeaf(tx, message.ChatID, message.BranchID, message.ID)
+		if err != nil {
+			return err
+		}
+		message.ParentID = parentID
+	}
+
+	return nil
+}
+
+func activeChatBranch(tx *gorm.DB, chatID string) (string, error) {
+	var chat Chat
+	err := tx.Select("active_branch_id").First(&chat, "id = ?", chatID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && chat.ActiveBranchID == "") {
+		return MainChatBranch, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch chat: %w", err)
+	}
+	return chat.ActiveBranchID, nil
+}
+
+// chatBranchLeaf returns the id of the last message of a branch, or the
+// message the branch continues from when it has none yet.
+func chatBranchLeaf(tx *gorm.DB, chatID, branchID, excludeID string) (string, error) {
+	var last ChatMessage
+	query := tx.Select("id").Where("chat_id = ? AND branch_id = ?", chatID, branchID)
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+	result := query.Order("timestamp DESC, id DESC").Limit(1).Find(&last)
+	if result.Error != nil {
+		return "", fmt.Errorf("failed to fetch last branch message: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return last.ID, nil
+	}
+
+	if branchID == MainChatBranch {
+		return "", nil
+	}
+
+	var branch ChatBranch
+	if err := tx.First(&branch, "id = ? AND chat_id = ?", branchID, chatID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("branch not found")
+		}
+		return "", fmt.Errorf("failed to fetch branch: %w", err)
+	}
+	return branch.ParentMessageID, nil
+}
+
+// CreateChatBranch starts a new branch of a chat that continues from
+// parentMessageID. An empty parentMessageID starts the chat over.
+func (db database) CreateChatBranch(chatID, parentMessageID string) (ChatBranch, error) {
+	if chatID == "" {
+		return ChatBranch{}, errors.New("chat ID is required")
+	}
+
+	if parentMessageID != "" {
+		var parent ChatMessage
+		if err := db.db.Select("id").First(&parent, "id = ? AND chat_id = ?", parentMessageID, chatID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ChatBranch{}, fmt.Errorf("parent message not found")
+			}
+			return ChatBranch{}, fmt.Errorf("failed to fetch parent message: %w", err)
+		}
+	}
+
+	branch := ChatBranch{
+		ID:              uuid.New().String(),
+		ChatID:          chatID,
+		ParentMessageID: parentMessageID,
+		CreatedAt:       time.Now(),
+	}
+	if err := db.db.Create(&branch).Error; err != nil {
+		return ChatBranch{}, fmt.Errorf("failed to create chat branch: %w", err)
+	}
+
+	return branch, nil
+}
+
+func (db database) GetChatBranches(chatID string) ([]ChatBranch, error) {
+	var branches []ChatBranch
+	if err := db.db.Where("chat_id = ?", chatID).Order("created_at ASC").Find(&branches).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch chat branches: %w", err)
+	}
+	return branches, nil
+}
+
+// SetActiveChatBranch switches the branch a chat shows and continues on.
+func (db database) SetActiveChatBranch(chatID, branchID string) (Chat, error) {
+	if chatID == "" || branchID == "" {
+		return Chat{}, errors.New("chat ID and branch ID are required")
+	}
+
+	if branchID != MainChatBranch {
+		var branch ChatBranch
+		if err := db.db.First(&branch, "id = ? AND chat_id = ?", branchID, chatID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return Chat{}, fmt.Errorf("branch not found")
+			}
+			return Chat{}, fmt.Errorf("failed to fetch branch: %w", err)
+		}
+	}
+
+	result := db.db.Model(&Chat{}).Where("id = ?", chatID).Updates(map[string]interface{}{
+		"active_branch_id": branchID,
+		"updated_at":       time.Now(),
+	})
+	if result.Error != nil {
+		return Chat{}, fmt.Errorf("failed to switch chat branch: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return Chat{}, fmt.Errorf("chat not found")
+	}
+
+	return db.GetChatByChatID(chatID)
+}
+
+// GetChatBranchHistory returns the conversation that leads to the end of a
+// branch, oldest message first, following parent links from its last
+// message. An empty branchID reads the active branch of the chat.
+func (db database) GetChatBranchHistory(chatID, branchID string) ([]ChatMessage, error) {
+	if branchID == "" {
+		active, err := activeChatBranch(db.db, chatID)
+		if err != nil {
+			return nil, err
+		}
+		branchID = active
+	}
+
+	leafID, err := chatBranchLeaf(db.db, chatID, branchID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := db.GetChatMessagesForChatID(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]ChatMessage, len(messages))
+	for _, message := range messages {
+		byID[message.ID] = message
+	}
+
+	history := []ChatMessage{}
+	seen := make(map[string]bool)
+	for id := leafID; id != "" && !seen[id]; {
+		message, ok := byID[id]
+		if !ok {
+			break
+		}
+		seen[id] = true
+		history = append(history, message)
+		id = message.ParentID
+	}
+
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+
+	return history, nil
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatBranches(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	chat := Chat{ID: uuid.New().String(), WorkspaceID: "workspace", Title: "Branches"}
+	_, err := TestDB.AddChat(&chat)
+	assert.NoError(t, err)
+
+	add := func(role ChatRole, text string) ChatMessage {
+		message, err := TestDB.AddChatMessage(&ChatMessage{ID: uuid.New().String(), ChatID: chat.ID, Message: text, Role: role, Status: SentStatus})
+		assert.NoError(t, err)
+		time.Sleep(time.Millisecond)
+		return message
+	}
+
+	question := add(UserRole, "question")
+	answer := add(AssistantRole, "answer")
+
+	t.Run("Messages Chain On The Main Branch", func(t *testing.T) {
+		assert.Equal(t, MainChatBranch, question.BranchID)
+		assert.Empty(t, question.ParentID)
+		assert.Equal(t, question.ID, answer.ParentID)
+	})
+
+	t.Run("Regenerated Reply Goes On The Active Branch", func(t *testing.T) {
+		branch, err := TestDB.CreateChatBranch(chat.ID, question.ID)
+		assert.NoError(t, err)
+		_, err = TestDB.SetActiveChatBranch(chat.ID, branch.ID)
+		assert.NoError(t, err)
+
+		history, err := TestDB.GetChatBranchHistory(chat.ID, "")
+		assert.NoError(t, err)
+		assert.Len(t, history, 1)
+
+		regenerated := add(AssistantRole, "better answer")
+		assert.Equal(t, branch.ID, regenerated.BranchID)
+		assert.Equal(t, question.ID, regenerated.ParentID)
+
+		history, err = TestDB.GetChatBranchHistory(chat.ID, "")
+		assert.NoError(t, err)
+		assert.Len(t, history, 2)
+		assert.Equal(t, "better answer", history[1].Message)
+	})
+
+	t.Run("Main Branch Is Unchanged", func(t *testing.T) {
+		history, err := TestDB.GetChatBranchHistory(chat.ID, MainChatBranch)
+
+		assert.NoError(t, err)
+		assert.Len(t, history, 2)
+		assert.Equal(t, "answer", history[1].Message)
+	})
+
+	t.Run("Switching Back Continues The Main Branch", func(t *testing.T) {
+		_, err := TestDB.SetActiveChatBranch(chat.ID, MainChatBranch)
+		assert.NoError(t, err)
+
+		followUp := add(UserRole, "follow up")
+
+		assert.Equal(t, MainChatBranch, followUp.BranchID)
+		assert.Equal(t, answer.ID, followUp.ParentID)
+	})
+
+	t.Run("Lists Branches", func(t *testing.T) {
+		branches, err := TestDB.GetChatBranches(chat.ID)
+
+		assert.NoError(t, err)
+		assert.Len(t, branches, 1)
+	})
+
+	t.Run("Unknown Branch", func(t *testing.T) {
+		_, err := TestDB.SetActiveChatBranch(chat.ID, "missing")
+		assert.EqualError(t, err, "branch not found")
+
+		_, err = TestDB.GetChatBranchHistory(chat.ID, "missing")
+		assert.EqualError(t, err, "branch not found")
+	})
+
+	t.Run("Parent From Another Chat", func(t *testing.T) {
+		_, err := TestDB.CreateChatBranch(uuid.New().String(), question.ID)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// OnChatEvent is called after every recorded chat event so open chat streams
+// on this replica are woken up without waiting for their next poll.
+var OnChatEvent func(event ChatEvent)
+
+func (db database) RecordChatEvent(chatID string, eventType ChatEventType, payload interface{}) (*ChatEvent, error) {
+	if chatID == "" {
+		return nil, errors.New("chat ID is required")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chat event: %w", err)
+	}
+	var payloadMap PropertyMap
+	if err := json.Unmarshal(data, &payloadMap); err != nil {
+		return nil, fmt.Errorf("chat event payload must be an object: %w", err)
+	}
+
+	event := ChatEvent{
+		ChatID:    chatID,
+		Type:      eventType,
+		Payload:   payloadMap,
+		CreatedAt: time.Now(),
+	}
+	if err := db.db.Create(&event).Error; err != nil {
+		return nil, fmt.Errorf("failed to create chat event: %w", err)
+	}
+
+	if OnChatEvent != nil {
+		OnChatEvent(event)
+	}
+
+	return &event, nil
+}
+
+func (db database) GetChatEventsAfter(chatID string, afterID uint64, limit int) ([]ChatEvent, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var events []ChatEvent
+	if err := db.db.Where("chat_id = ? AND id > ?", chatID, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch chat events: %w", err)
+	}
+
+	return events, nil
+}
+
+// recordChatEvent logs instead of failing, the change it describes is
+// already saved.
+func (db database) recordChatEvent(chatID string, eventType ChatEventType, payload interface{}) {
+	if _, err := db.RecordChatEvent(chatID, eventType, payload); err != nil {
+		logger.Log.Error("[chat events] failed to record %s event for chat %s: %v", eventType, chatID, err)
+	}
+}
+
+func (db database) chatIDForMessage(messageID string) (string, error) {
+	var message ChatMessage
+	if err := db.db.Select("chat_id").First(&message, "id = ?", messageID).Error; err != nil {
+		return "", fmt.Errorf("failed to fetch message: %w", err)
+	}
+	return message.ChatID, nil
+}
+
+func (db database) recordArtifactEvent(artifact *Artifact) {
+	chatID, err := db.chatIDForMessage(artifact.MessageID)
+	if err != nil {
+		logger.Log.Error("[chat events] failed to find chat of artifact %s: %v", artifact.ID, err)
+		return
+	}
+	db.recordChatEvent(chatID, ChatArtifactEvent, artifact)
+}
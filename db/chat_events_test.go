@@ -0,0 +1,86 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordChatEvent(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	chatID := uuid.New().String()
+
+	t.Run("Requires Chat ID", func(t *testing.T) {
+		_, err := TestDB.RecordChatEvent("", ChatMessageEvent, ChatMessage{})
+		assert.Error(t, err)
+	})
+
+	t.Run("Rejects Non Object Payload", func(t *testing.T) {
+		_, err := TestDB.RecordChatEvent(chatID, ChatMessageEvent, "text")
+		assert.Error(t, err)
+	})
+
+	t.Run("Notifies Listener", func(t *testing.T) {
+		var notified []ChatEvent
+		OnChatEvent = func(event ChatEvent) {
+			notified = append(notified, event)
+		}
+		defer func() { OnChatEvent = nil }()
+
+		event, err := TestDB.RecordChatEvent(chatID, ChatWorkflowStatusEvent, ChatWorkflowStatus{ChatID: chatID, Status: "pending"})
+
+		assert.NoError(t, err)
+		assert.NotZero(t, event.ID)
+		assert.Equal(t, "pending", event.Payload["status"])
+		assert.Len(t, notified, 1)
+		assert.Equal(t, event.ID, notified[0].ID)
+	})
+}
+
+func TestGetChatEventsAfter(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	chat := Chat{ID: uuid.New().String(), WorkspaceID: "workspace", Title: "Stream"}
+	TestDB.AddChat(&chat)
+
+	first := ChatMessage{ID: uuid.New().String(), ChatID: chat.ID, Message: "first", Role: "user", Status: SentStatus}
+	_, err := TestDB.AddChatMessage(&first)
+	assert.NoError(t, err)
+	second := ChatMessage{ID: uuid.New().String(), ChatID: chat.ID, Message: "second", Role: "assistant", Status: SentStatus}
+	_, err = TestDB.AddChatMessage(&second)
+	assert.NoError(t, err)
+	_, err = TestDB.AddChatStatus(&ChatWorkflowStatus{ChatID: chat.ID, Status: "success"})
+	assert.NoError(t, err)
+
+	t.Run("Returns Events In Order", func(t *testing.T) {
+		events, err := TestDB.GetChatEventsAfter(chat.ID, 0, 0)
+
+		assert.NoError(t, err)
+		assert.Len(t, events, 3)
+		assert.Equal(t, ChatMessageEvent, events[0].Type)
+		assert.Equal(t, "first", events[0].Payload["message"])
+		assert.Equal(t, ChatWorkflowStatusEvent, events[2].Type)
+	})
+
+	t.Run("Resumes After Event ID", func(t *testing.T) {
+		all, err := TestDB.GetChatEventsAfter(chat.ID, 0, 0)
+		assert.NoError(t, err)
+
+		events, err := TestDB.GetChatEventsAfter(chat.ID, all[0].ID, 1)
+
+		assert.NoError(t, err)
+		assert.Len(t, events, 1)
+		assert.Equal(t, "second", events[0].Payload["message"])
+	})
+
+	t.Run("Other Chat", func(t *testing.T) {
+		events, err := TestDB.GetChatEventsAfter(uuid.New().String(), 0, 0)
+
+		assert.NoError(t, err)
+		assert.Empty(t, events)
+	})
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ImportChat stores a chat with its branches, messages and artifacts in one
+// transaction. The rows are stored as given, so ids must already be unique
+// and timestamps keep the order of the original conversation.
+func (db database) ImportChat(chat Chat, branches []ChatBranch, messages []ChatMessage, artifacts []Artifact) (Chat, error) {
+	if chat.ID == "" || chat.WorkspaceID == "" {
+		return Chat{}, errors.New("chat ID and workspace ID are required")
+	}
+
+	now := time.Now()
+	if chat.CreatedAt.IsZero() {
+		chat.CreatedAt = now
+	}
+	chat.UpdatedAt = now
+	if chat.ActiveBranchID == "" {
+		chat.ActiveBranchID = MainChatBranch
+	}
+	if chat.Status == "" {
+		chat.Status = ActiveStatus
+	}
+
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&chat).Error; err != nil {
+			return fmt.Errorf("failed to create chat: %w", err)
+		}
+		if len(branches) > 0 {
+			if err := tx.Create(&branches).Error; err != nil {
+				return fmt.Errorf("failed to create chat branches: %w", err)
+			}
+		}
+		if len(messages) > 0 {
+			if err := tx.Create(&messages).Error; err != nil {
+				return fmt.Errorf("failed to create chat messages: %w", err)
+			}
+		}
+		if len(artifacts) > 0 {
+			if err := tx.Create(&artifacts).Error; err != nil {
+				return fmt.Errorf("failed to create artifacts: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Chat{}, err
+	}
+
+	return chat, nil
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportChat(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	start := time.Now().Add(-time.Hour)
+	chat := Chat{ID: uuid.New().String(), WorkspaceID: "workspace", Title: "Imported", CreatedAt: start}
+	branch := ChatBranch{ID: uuid.New().String(), ChatID: chat.ID}
+	first := ChatMessage{ID: uuid.New().String(), ChatID: chat.ID, Message: "first", Role: UserRole, Status: SentStatus, BranchID: MainChatBranch, Timestamp: start}
+	second := ChatMessage{ID: uuid.New().String(), ChatID: chat.ID, Message: "second", Role: AssistantRole, Status: SentStatus, ParentID: first.ID, BranchID: MainChatBranch, Timestamp: start.Add(time.Minute)}
+	branch.ParentMessageID = first.ID
+	chat.ActiveBranchID = branch.ID
+	artifact := Artifact{ID: uuid.New(), MessageID: second.ID, Type: TextArtifact, Content: PropertyMap{"content": "notes"}}
+
+	t.Run("Stores Everything", func(t *testing.T) {
+		imported, err := TestDB.ImportChat(chat, []ChatBranch{branch}, []ChatMessage{first, second}, []Artifact{artifact})
+		assert.NoError(t, err)
+		assert.Equal(t, ActiveStatus, imported.Status)
+
+		messages, err := TestDB.GetChatMessagesForChatID(chat.ID)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 2)
+		assert.Equal(t, "first", messages[0].Message)
+		assert.WithinDuration(t, start, messages[0].Timestamp, time.Second)
+
+		artifacts, err := TestDB.GetArtifactsByMessageID(second.ID)
+		assert.NoError(t, err)
+		assert.Len(t, artifacts, 1)
+
+		branches, err := TestDB.GetChatBranches(chat.ID)
+		assert.NoError(t, err)
+		assert.Len(t, branches, 1)
+	})
+
+	t.Run("Rolls Back On Failure", func(t *testing.T) {
+		other := Chat{ID: uuid.New().String(), WorkspaceID: "workspace"}
+		duplicate := first
+
+		_, err := TestDB.ImportChat(other, nil, []ChatMessage{duplicate}, nil)
+		assert.Error(t, err)
+
+		_, err = TestDB.GetChatByChatID(other.ID)
+		assert.Error(t, err)
+	})
+}
+
+func TestGetFileAssetsByStoragePaths(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	path := "https://files.example.com/" + uuid.New().String() + ".pdf"
+	_, err := TestDB.CreateFileAsset(&FileAsset{OriginFilename: "spec.pdf", UploadFilename: uuid.New().String(), StoragePath: path, Status: ActiveFileStatus})
+	assert.NoError(t, err)
+
+	assets, err := TestDB.GetFileAssetsByStoragePaths([]string{path, "https://files.example.com/missing.pdf"})
+	assert.NoError(t, err)
+	assert.Len(t, assets, 1)
+	assert.Equal(t, "spec.pdf", assets[0].OriginFilename)
+
+	assets, err = TestDB.GetFileAssetsByStoragePaths(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, assets)
+}
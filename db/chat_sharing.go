@@ -0,0 +1,83 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (db database) GetChatMessageByID(messageID string) (ChatMessage, error) {
+	var message ChatMessage
+	if err := db.db.Where("id = ?", messageID).First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ChatMessage{}, fmt.Errorf("message not found")
+		}
+		return ChatMessage{}, fmt.Errorf("failed to fetch message: %w", err)
+	}
+	return message, nil
+}
+
+func (db database) GetChatStatusByUUID(id uuid.UUID) (ChatWorkflowStatus, error) {
+	var status ChatWorkflowStatus
+	if err := db.db.Where("uuid = ?", id).First(&status).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ChatWorkflowStatus{}, fmt.Errorf("chat status not found with UUID: %s", id.String())
+		}
+		return ChatWorkflowStatus{}, fmt.Errorf("failed to fetch chat status: %w", err)
+	}
+	return status, nil
+}
+
+func (db database) CreateChatShareLink(link *ChatShareLink) (ChatShareLink, error) {
+	if link.ID == "" || link.ChatID == "" || link.TokenHash == "" {
+		return ChatShareLink{}, errors.New("share link ID, chat ID and token are required")
+	}
+
+	link.CreatedAt = time.Now()
+	if err := db.db.Create(link).Error; err != nil {
+		return ChatShareLink{}, fmt.Errorf("failed to create share link: %w", err)
+	}
+	return *link, nil
+}
+
+func (db database) GetChatShareLinkByTokenHash(tokenHash string) (ChatShareLink, error) {
+	var link ChatShareLink
+	if err := db.db.Where("token_hash = ?", tokenHash).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ChatShareLink{}, fmt.Errorf("share link not found")
+		}
+		return ChatShareLink{}, fmt.Errorf("failed to fetch share link: %w", err)
+	}
+	return link, nil
+}
+
+func (db database) GetChatShareLinks(chatID string) ([]ChatShareLink, error) {
+	links := []ChatShareLink{}
+	if err := db.db.Where("chat_id = ?", chatID).Order("created_at DESC").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch share links: %w", err)
+	}
+	return links, nil
+}
+
+// RevokeChatShareLink stops a share link of a chat from working. Revoking a
+// revoked link keeps its first revocation time.
+func (db database) RevokeChatShareLink(chatID, linkID string) error {
+	var link ChatShareLink
+	if err := db.db.Where("id = ? AND chat_id = ?", linkID, chatID).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("share link not found")
+		}
+		return fmt.Errorf("failed to fetch share link: %w", err)
+	}
+	if link.RevokedAt != nil {
+		return nil
+	}
+
+	if err := db.db.Model(&ChatShareLink{}).Where("id = ?", linkID).Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatShareLinks(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	chat, err := TestDB.AddChat(&Chat{
+		ID:          uuid.New().String(),
+		WorkspaceID: "share-workspace",
+		Title:       "Shared",
+		OwnerPubKey: "share-owner",
+		Visibility:  WorkspaceChat,
+	})
+	require.NoError(t, err)
+
+	t.Run("Create And Find By Token Hash", func(t *testing.T) {
+		link, err := TestDB.CreateChatShareLink(&ChatShareLink{
+			ID:        uuid.New().String(),
+			ChatID:    chat.ID,
+			TokenHash: "hash-1",
+			CreatedBy: "share-owner",
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		found, err := TestDB.GetChatShareLinkByTokenHash("hash-1")
+		require.NoError(t, err)
+		assert.Equal(t, link.ID, found.ID)
+
+		_, err = TestDB.GetChatShareLinkByTokenHash("unknown")
+		assert.EqualError(t, err, "share link not found")
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		link, err := TestDB.CreateChatShareLink(&ChatShareLink{
+			ID:        uuid.New().String(),
+			ChatID:    chat.ID,
+			TokenHash: "hash-2",
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, TestDB.RevokeChatShareLink(chat.ID, link.ID))
+		assert.EqualError(t, TestDB.RevokeChatShareLink("other-chat", link.ID), "share link not found")
+
+		links, err := TestDB.GetChatShareLinks(chat.ID)
+		require.NoError(t, err)
+		assert.Len(t, links, 2)
+		for _, l := range links {
+			if l.ID == link.ID {
+				assert.NotNil(t, l.RevokedAt)
+			}
+		}
+	})
+
+	t.Run("Update Visibility", func(t *testing.T) {
+		updated, err := TestDB.UpdateChat(&Chat{ID: chat.ID, Visibility: PrivateChat})
+		require.NoError(t, err)
+		assert.Equal(t, PrivateChat, updated.Visibility)
+		assert.Equal(t, "Shared", updated.Title)
+	})
+}
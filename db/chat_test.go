@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetChatsForWorkspace(t *testing.T) {
@@ -801,3 +802,195 @@ func TestUpdateChatMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestAppendChatMessageChunk(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	chatID := "stream-chat"
+	messageID := "stream-msg"
+	_, err := TestDB.AddChatMessage(&ChatMessage{ID: "stream-prompt", ChatID: chatID, Role: UserRole, Message: "Hi"})
+	assert.NoError(t, err)
+	_, err = TestDB.AddChatMessage(&ChatMessage{ID: messageID, ChatID: chatID, Role: AssistantRole, Status: StreamingStatus, ParentID: "stream-prompt"})
+	assert.NoError(t, err)
+	sentAt := time.Now().Add(-time.Hour)
+	TestDB.db.Model(&ChatMessage{}).Where("id = ?", messageID).Update("timestamp", sentAt)
+
+	t.Run("Requires IDs", func(t *testing.T) {
+		_, _, err := TestDB.AppendChatMessageChunk("", messageID, 1, "Hel")
+		assert.Error(t, err)
+	})
+
+	t.Run("First Chunk Is Appended", func(t *testing.T) {
+		message, applied, err := TestDB.AppendChatMessageChunk(chatID, messageID, 1, "Hel")
+
+		assert.NoError(t, err)
+		assert.Len(t, applied, 1)
+		assert.Equal(t, "Hel", message.Message)
+		assert.Equal(t, StreamingStatus, message.Status)
+		assert.Equal(t, "stream-prompt", message.ParentID)
+		assert.WithinDuration(t, sentAt, message.Timestamp, time.Second)
+	})
+
+	t.Run("Early Chunk Waits For The One Before It", func(t *testing.T) {
+		message, applied, err := TestDB.AppendChatMessageChunk(chatID, messageID, 3, "!")
+
+		assert.NoError(t, err)
+		assert.Empty(t, applied)
+		assert.Equal(t, "Hel", message.Message)
+
+		message, applied, err = TestDB.AppendChatMessageChunk(chatID, messageID, 2, "lo")
+
+		assert.NoError(t, err)
+		require.Len(t, applied, 2)
+		assert.Equal(t, "!", applied[1].Delta)
+		assert.Equal(t, "Hello!", message.Message)
+		assert.Equal(t, 3, message.StreamSequence)
+	})
+
+	t.Run("Duplicate Chunk Is Ignored", func(t *testing.T) {
+		message, applied, err := TestDB.AppendChatMessageChunk(chatID, messageID, 2, "lo")
+
+		assert.NoError(t, err)
+		assert.Empty(t, applied)
+		assert.Equal(t, "Hello!", message.Message)
+	})
+
+	t.Run("Other Chat", func(t *testing.T) {
+		_, _, err := TestDB.AppendChatMessageChunk("other-chat", messageID, 4, "!")
+		assert.ErrorIs(t, err, ErrNotStreamingReply)
+	})
+
+	t.Run("User Message", func(t *testing.T) {
+		_, _, err := TestDB.AppendChatMessageChunk(chatID, "stream-prompt", 1, "!")
+		assert.ErrorIs(t, err, ErrNotStreamingReply)
+	})
+
+	t.Run("Unknown Message", func(t *testing.T) {
+		_, _, err := TestDB.AppendChatMessageChunk(chatID, "missing", 1, "!")
+		assert.ErrorIs(t, err, ErrNotStreamingReply)
+	})
+
+	t.Run("Records Delta Events", func(t *testing.T) {
+		events, err := TestDB.GetChatEventsAfter(chatID, 0, 0)
+
+		assert.NoError(t, err)
+		require.Len(t, events, 5)
+		assert.Equal(t, ChatMessageDeltaEvent, events[2].Type)
+		assert.Equal(t, "lo", events[3].Payload["delta"])
+		assert.Equal(t, "!", events[4].Payload["delta"])
+	})
+
+	t.Run("Chunk After Finalize Is Ignored", func(t *testing.T) {
+		finalized, done, err := TestDB.FinalizeChatMessage(chatID, messageID, "")
+		assert.NoError(t, err)
+		assert.True(t, done)
+		assert.Equal(t, SentStatus, finalized.Status)
+		assert.Equal(t, "Hello!", finalized.Message)
+
+		message, applied, err := TestDB.AppendChatMessageChunk(chatID, messageID, 4, "?")
+
+		assert.NoError(t, err)
+		assert.Empty(t, applied)
+		assert.Equal(t, "Hello!", message.Message)
+	})
+}
+
+func TestFinalizeChatMessage(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	reply := func(id string) {
+		_, err := TestDB.AddChatMessage(&ChatMessage{ID: id, ChatID: "final-chat", Role: AssistantRole, Status: StreamingStatus})
+		assert.NoError(t, err)
+	}
+
+	t.Run("Unknown Message", func(t *testing.T) {
+		_, _, err := TestDB.FinalizeChatMessage("final-chat", "final-missing", "Full answer")
+
+		assert.ErrorIs(t, err, ErrNotStreamingReply)
+	})
+
+	t.Run("Sets Response Without Chunks", func(t *testing.T) {
+		reply("final-msg")
+
+		message, finalized, err := TestDB.FinalizeChatMessage("final-chat", "final-msg", "Full answer")
+
+		assert.NoError(t, err)
+		assert.True(t, finalized)
+		assert.Equal(t, "Full answer", message.Message)
+		assert.Equal(t, SentStatus, message.Status)
+	})
+
+	t.Run("Replaces Streamed Text With Response", func(t *testing.T) {
+		reply("final-msg-2")
+		_, _, err := TestDB.AppendChatMessageChunk("final-chat", "final-msg-2", 1, "Draf")
+		assert.NoError(t, err)
+
+		message, _, err := TestDB.FinalizeChatMessage("final-chat", "final-msg-2", "Final")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Final", message.Message)
+		assert.Equal(t, SentStatus, message.Status)
+	})
+
+	t.Run("Adds Chunks Still Waiting", func(t *testing.T) {
+		reply("final-msg-3")
+		_, _, err := TestDB.AppendChatMessageChunk("final-chat", "final-msg-3", 1, "a")
+		assert.NoError(t, err)
+		_, _, err = TestDB.AppendChatMessageChunk("final-chat", "final-msg-3", 3, "c")
+		assert.NoError(t, err)
+
+		message, _, err := TestDB.FinalizeChatMessage("final-chat", "final-msg-3", "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ac", message.Message)
+	})
+
+	t.Run("Second Completion Changes Nothing", func(t *testing.T) {
+		message, finalized, err := TestDB.FinalizeChatMessage("final-chat", "final-msg", "Other answer")
+
+		assert.NoError(t, err)
+		assert.False(t, finalized)
+		assert.Equal(t, "Full answer", message.Message)
+	})
+}
+
+func TestExpireStreamingChatMessages(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	reply := func(id string, status ChatMessageStatus, sentAt time.Time) {
+		_, err := TestDB.AddChatMessage(&ChatMessage{ID: id, ChatID: "expire-chat", Role: AssistantRole, Status: status})
+		assert.NoError(t, err)
+		TestDB.db.Model(&ChatMessage{}).Where("id = ?", id).Update("timestamp", sentAt)
+	}
+	cutoff := time.Now().Add(-30 * time.Minute)
+	reply("expire-old", StreamingStatus, time.Now().Add(-time.Hour))
+	reply("expire-recent", StreamingStatus, time.Now())
+	reply("expire-sent", SentStatus, time.Now().Add(-time.Hour))
+	_, _, err := TestDB.AppendChatMessageChunk("expire-chat", "expire-old", 2, "late")
+	assert.NoError(t, err)
+
+	expired, err := TestDB.ExpireStreamingChatMessages(cutoff)
+
+	assert.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "expire-old", expired[0].ID)
+	assert.Equal(t, ErrorStatus, expired[0].Status)
+
+	old, _ := TestDB.GetChatMessageByID("expire-old")
+	assert.Equal(t, ErrorStatus, old.Status)
+	recent, _ := TestDB.GetChatMessageByID("expire-recent")
+	assert.Equal(t, StreamingStatus, recent.Status)
+	sent, _ := TestDB.GetChatMessageByID("expire-sent")
+	assert.Equal(t, SentStatus, sent.Status)
+
+	var kept int64
+	TestDB.db.Model(&ChatMessageChunk{}).Where("message_id = ?", "expire-old").Count(&kept)
+	assert.Zero(t, kept)
+
+	expired, err = TestDB.ExpireStreamingChatMessages(cutoff)
+	assert.NoError(t, err)
+	assert.Empty(t, expired)
+}
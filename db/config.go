@@ -1,11 +1,10 @@
 package db
 
 import (
-	"fmt"
 	"log"
-	"os"
 
 	"github.com/rs/xid"
+	"github.com/stakwork/sphinx-tribes/config"
 	"github.com/stakwork/sphinx-tribes/logger"
 	"gopkg.in/go-playground/validator.v9"
 	"gorm.io/driver/postgres"
@@ -26,6 +25,7 @@ type database struct {
 	db                 *gorm.DB
 	getWorkspaceByUuid func(uuid string) Workspace
 	getUserRoles       func(uuid string, pubkey string) []WorkspaceUserRoles
+	alerts             config.AlertConfig
 }
 
 func NewDatabaseConfig(db *gorm.DB) *database {
@@ -39,25 +39,23 @@ func NewDatabaseConfig(db *gorm.DB) *database {
 // DB is the object
 var DB database
 
-func InitDB() {
-	dbURL := os.Getenv("DATABASE_URL")
-	logger.Log.Info("db url : %v", dbURL)
-
-	if dbURL == "" {
-		rdsHost := os.Getenv("RDS_HOSTNAME")
-		rdsPort := os.Getenv("RDS_PORT")
-		rdsDbName := os.Getenv("RDS_DB_NAME")
-		rdsUsername := os.Getenv("RDS_USERNAME")
-		rdsPassword := os.Getenv("RDS_PASSWORD")
-		dbURL = fmt.Sprintf("postgres://%s:%s@%s:%s/%s", rdsUsername, rdsPassword, rdsHost, rdsPort, rdsDbName)
-	}
+// InTransaction runs fn against a database bound to a single transaction,
+// which is committed when fn returns nil and rolled back otherwise.
+func (db database) InTransaction(fn func(Database) error) error {
+	return db.db.Transaction(func(tx *gorm.DB) error {
+		txDb := db
+		txDb.db = tx
+		return fn(txDb)
+	})
+}
 
-	if dbURL == "" {
-		panic("DB env vars not found")
+// ConnectDB opens the postgres connection without touching the schema.
+func ConnectDB(cfg config.DatabaseConfig) {
+	dbURL, err := cfg.DSN()
+	if err != nil {
+		panic(err)
 	}
 
-	var err error
-
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		DSN:                  dbURL,
 		PreferSimpleProtocol: true,
@@ -69,48 +67,22 @@ func InitDB() {
 
 	DB.db = db
 	logger.Log.Info("db connected")
+}
+
+func InitDB(cfg *config.Config) {
+	ConnectDB(cfg.Database)
+	DB.alerts = cfg.Alerts
 
-	// migrate table changes
-	db.AutoMigrate(&Tribe{})
-	db.AutoMigrate(&Person{})
-	db.AutoMigrate(&Channel{})
-	db.AutoMigrate(&LeaderBoard{})
-	db.AutoMigrate(&ConnectionCodes{})
-	db.AutoMigrate(&BountyRoles{})
-	db.AutoMigrate(&UserInvoiceData{})
-	db.AutoMigrate(&WorkspaceRepositories{})
-	db.AutoMigrate(&WorkspaceCodeGraph{})
-	db.AutoMigrate(&WorkspaceFeatures{})
-	db.AutoMigrate(&FeaturePhase{})
-	db.AutoMigrate(&FeatureStory{})
-	db.AutoMigrate(&WfRequest{})
-	db.AutoMigrate(&WfProcessingMap{})
-	db.AutoMigrate(&Tickets{})
-	db.AutoMigrate(&ChatMessage{})
-	db.AutoMigrate(&Chat{})
-	db.AutoMigrate(&ProofOfWork{})
-	db.AutoMigrate(&BountyTiming{})
-	db.AutoMigrate(&FeatureFlag{})
-	db.AutoMigrate(&Endpoint{})
-	db.AutoMigrate(&FeaturedBounty{})
-	db.AutoMigrate(&Notification{})
-	db.AutoMigrate(&TextSnippet{})
-	db.AutoMigrate(&BountyTiming{})
-	db.AutoMigrate(&FileAsset{})
-	db.AutoMigrate(&TicketPlan{})
-	db.AutoMigrate(&Activity{})
-	db.AutoMigrate(&Artifact{})
-	db.AutoMigrate(&FeatureCall{})
-	db.AutoMigrate(&ChatWorkflow{})
-	db.AutoMigrate(&Skill{})
-	db.AutoMigrate(&SkillInstall{})
-	db.AutoMigrate(&SSEMessageLog{})
-	db.AutoMigrate(&CodeSpaceMap{})
-	db.AutoMigrate(&BountyStake{})
-	db.AutoMigrate(&ChatWorkflowStatus{})
-
-	DB.MigrateTablesWithOrgUuid()
-	DB.MigrateOrganizationToWorkspace()
+	migrator, err := DB.NewMigrator()
+	if err != nil {
+		panic(err)
+	}
+
+	applied, err := migrator.Up(0)
+	if err != nil {
+		panic(err)
+	}
+	logger.Log.Info("applied %d migrations", len(applied))
 
 	people := DB.GetAllPeople()
 	for _, p := range people {
@@ -214,114 +186,6 @@ func (db database) GetRolesCount() int64 {
 	return count
 }
 
-func (db database) MigrateTablesWithOrgUuid() {
-	if db.db.Migrator().HasTable("bounty") {
-		if !db.db.Migrator().HasColumn(Bounty{}, "workspace_uuid") {
-			db.db.AutoMigrate(&Bounty{})
-		} else {
-			db.db.AutoMigrate(&NewBounty{})
-		}
-	} else {
-		db.db.AutoMigrate(&NewBounty{})
-	}
-	if !db.db.Migrator().HasTable("budget_histories") {
-		if !db.db.Migrator().HasColumn(BudgetHistory{}, "workspace_uuid") {
-			db.db.AutoMigrate(&BudgetHistory{})
-		}
-	}
-	if !db.db.Migrator().HasTable("payment_histories") {
-		if !db.db.Migrator().HasColumn(PaymentHistory{}, "workspace_uuid") {
-			db.db.AutoMigrate(&PaymentHistory{})
-		} else {
-			db.db.AutoMigrate(&NewPaymentHistory{})
-		}
-	} else {
-		db.db.AutoMigrate(&NewPaymentHistory{})
-	}
-	if !db.db.Migrator().HasTable("invoice_list") {
-		if !db.db.Migrator().HasColumn(InvoiceList{}, "workspace_uuid") {
-			db.db.AutoMigrate(&InvoiceList{})
-		} else {
-			db.db.AutoMigrate(&NewInvoiceList{})
-		}
-	}
-	if !db.db.Migrator().HasTable("bounty_budgets") {
-		if !db.db.Migrator().HasColumn(BountyBudget{}, "workspace_uuid") {
-			db.db.AutoMigrate(&BountyBudget{})
-		} else {
-			db.db.AutoMigrate(&NewBountyBudget{})
-		}
-	}
-	if !db.db.Migrator().HasTable("workspace_user_roles") {
-		if !db.db.Migrator().HasColumn(UserRoles{}, "workspace_uuid") {
-			db.db.AutoMigrate(&UserRoles{})
-		}
-	} else {
-		db.db.AutoMigrate(&WorkspaceUserRoles{})
-	}
-	if !db.db.Migrator().HasTable("workspaces") {
-		db.db.AutoMigrate(&Organization{})
-	} else {
-		db.db.AutoMigrate(&Workspace{})
-	}
-	if !db.db.Migrator().HasTable("workspace_users") {
-		db.db.AutoMigrate(&OrganizationUsers{})
-	} else {
-		db.db.AutoMigrate(&WorkspaceUsers{})
-	}
-}
-
-func (db database) MigrateOrganizationToWorkspace() {
-	if (db.db.Migrator().HasTable(&Organization{}) && !db.db.Migrator().HasTable("workspaces")) {
-		db.db.Migrator().RenameTable(&Organization{}, "workspaces")
-	}
-
-	if (db.db.Migrator().HasTable(&OrganizationUsers{}) && !db.db.Migrator().HasTable("workspace_users")) {
-		if db.db.Migrator().HasColumn(&OrganizationUsers{}, "org_uuid") {
-			db.db.Migrator().RenameColumn(&OrganizationUsers{}, "org_uuid", "workspace_uuid")
-		}
-		db.db.Migrator().RenameTable(&OrganizationUsers{}, "workspace_users")
-	}
-
-	if (db.db.Migrator().HasTable(&UserRoles{}) && !db.db.Migrator().HasTable("workspace_user_roles")) {
-		if db.db.Migrator().HasColumn(&UserRoles{}, "org_uuid") {
-			db.db.Migrator().RenameColumn(&UserRoles{}, "org_uuid", "workspace_uuid")
-		}
-
-		db.db.Migrator().RenameTable(&UserRoles{}, "workspace_user_roles")
-	}
-
-	if (db.db.Migrator().HasTable(&Bounty{})) {
-		if db.db.Migrator().HasColumn(&Bounty{}, "org_uuid") {
-			db.db.Migrator().RenameColumn(&Bounty{}, "org_uuid", "workspace_uuid")
-		}
-	}
-
-	if (db.db.Migrator().HasTable(&BountyBudget{})) {
-		if db.db.Migrator().HasColumn(&BountyBudget{}, "org_uuid") {
-			db.db.Migrator().RenameColumn(&BountyBudget{}, "org_uuid", "workspace_uuid")
-		}
-	}
-
-	if (db.db.Migrator().HasTable(&BudgetHistory{})) {
-		if db.db.Migrator().HasColumn(&BudgetHistory{}, "org_uuid") {
-			db.db.Migrator().RenameColumn(&BudgetHistory{}, "org_uuid", "workspace_uuid")
-		}
-	}
-
-	if (db.db.Migrator().HasTable(&PaymentHistory{})) {
-		if db.db.Migrator().HasColumn(&PaymentHistory{}, "org_uuid") {
-			db.db.Migrator().RenameColumn(&PaymentHistory{}, "org_uuid", "workspace_uuid")
-		}
-	}
-
-	if (db.db.Migrator().HasTable(&InvoiceList{})) {
-		if db.db.Migrator().HasColumn(&InvoiceList{}, "org_uuid") {
-			db.db.Migrator().RenameColumn(&InvoiceList{}, "org_uuid", "workspace_uuid")
-		}
-	}
-}
-
 func (db database) CreateRoles() {
 	db.db.Create(&ConfigBountyRoles)
 }
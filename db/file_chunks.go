@@ -0,0 +1,87 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const maxFileChunkResults = 50
+
+// ReplaceFileChunks swaps the chunks of a file for the given ones and marks
+// the file as indexed.
+func (db database) ReplaceFileChunks(fileID uint, chunks []FileChunk) error {
+	return db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("file_id = ?", fileID).Delete(&FileChunk{}).Error; err != nil {
+			return fmt.Errorf("failed to remove old chunks: %w", err)
+		}
+
+		now := time.Now()
+		for i := range chunks {
+			chunks[i].ID = 0
+			chunks[i].FileID = fileID
+			chunks[i].CreatedAt = now
+		}
+		if len(chunks) > 0 {
+			if err := tx.CreateInBatches(chunks, 100).Error; err != nil {
+				return fmt.Errorf("failed to save chunks: %w", err)
+			}
+		}
+
+		result := tx.Model(&FileAsset{}).Where("id = ?", fileID).Update("extraction_status", IndexedExtraction)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update file: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("no file asset found with id %d", fileID)
+		}
+		return nil
+	})
+}
+
+func (db database) GetFileChunks(fileID uint) ([]FileChunk, error) {
+	chunks := []FileChunk{}
+	if err := db.db.Where("file_id = ?", fileID).Order("chunk_index ASC").Find(&chunks).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch file chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+func (db database) SetFileAssetExtractionStatus(id uint, status ExtractionStatus) error {
+	result := db.db.Model(&FileAsset{}).Where("id = ?", id).Update("extraction_status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no file asset found with id %d", id)
+	}
+	return nil
+}
+
+// SearchFileChunks runs a full text search over the chunks of the files of
+// a workspace that have not been deleted, best matches first.
+func (db database) SearchFileChunks(workspaceID string, query string, limit int) ([]FileChunkMatch, error) {
+	matches := []FileChunkMatch{}
+	query = strings.TrimSpace(query)
+	if workspaceID == "" || query == "" {
+		return matches, nil
+	}
+	if limit <= 0 || limit > maxFileChunkResults {
+		limit = maxFileChunkResults
+	}
+
+	err := db.db.Table("file_chunks").
+		Select("file_chunks.*, file_assets.origin_filename, ts_rank(to_tsvector('english', file_chunks.content), plainto_tsquery('english', ?)) AS rank", query).
+		Joins("JOIN file_assets ON file_assets.id = file_chunks.file_id").
+		Where("file_chunks.workspace_id = ? AND file_assets.status != ?", workspaceID, DeletedFileStatus).
+		Where("to_tsvector('english', file_chunks.content) @@ plainto_tsquery('english', ?)", query).
+		Order("rank DESC, file_chunks.file_id, file_chunks.chunk_index").
+		Limit(limit).
+		Scan(&matches).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search file chunks: %w", err)
+	}
+	return matches, nil
+}
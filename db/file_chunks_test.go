@@ -0,0 +1,119 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileChunks(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	workspaceID := uuid.New().String()
+	create := func(name string) *FileAsset {
+		asset, err := TestDB.CreateFileAsset(&FileAsset{
+			OriginFilename:   name,
+			UploadFilename:   uuid.New().String(),
+			MimeType:         "text/plain",
+			Status:           ActiveFileStatus,
+			WorkspaceID:      workspaceID,
+			ExtractionStatus: PendingExtraction,
+		})
+		require.NoError(t, err)
+		return asset
+	}
+	plan := create("plan.txt")
+	notes := create("notes.txt")
+
+	t.Run("Replace And Read Chunks", func(t *testing.T) {
+		err := TestDB.ReplaceFileChunks(plan.ID, []FileChunk{
+			{WorkspaceID: workspaceID, ChunkIndex: 0, Content: "The launch is planned for Friday"},
+			{WorkspaceID: workspaceID, ChunkIndex: 1, Content: "Marketing starts next week"},
+		})
+		assert.NoError(t, err)
+		err = TestDB.ReplaceFileChunks(notes.ID, []FileChunk{
+			{WorkspaceID: workspaceID, ChunkIndex: 0, Content: "Meeting notes about hiring"},
+		})
+		assert.NoError(t, err)
+
+		chunks, err := TestDB.GetFileChunks(plan.ID)
+		assert.NoError(t, err)
+		assert.Len(t, chunks, 2)
+		assert.Equal(t, "Marketing starts next week", chunks[1].Content)
+
+		asset, err := TestDB.GetFileAssetByID(plan.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, IndexedExtraction, asset.ExtractionStatus)
+	})
+
+	t.Run("Replacing Drops Old Chunks", func(t *testing.T) {
+		err := TestDB.ReplaceFileChunks(notes.ID, []FileChunk{
+			{WorkspaceID: workspaceID, ChunkIndex: 0, Content: "Hiring plan for the launch"},
+		})
+		assert.NoError(t, err)
+
+		chunks, err := TestDB.GetFileChunks(notes.ID)
+		assert.NoError(t, err)
+		assert.Len(t, chunks, 1)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		matches, err := TestDB.SearchFileChunks(workspaceID, "launches", 10)
+		assert.NoError(t, err)
+		assert.Len(t, matches, 2)
+
+		matches, err = TestDB.SearchFileChunks(workspaceID, "marketing", 10)
+		assert.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, "plan.txt", matches[0].OriginFilename)
+		assert.Equal(t, 1, matches[0].ChunkIndex)
+
+		matches, err = TestDB.SearchFileChunks(uuid.New().String(), "marketing", 10)
+		assert.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+
+	t.Run("Search Skips Deleted Files", func(t *testing.T) {
+		assert.NoError(t, TestDB.DeleteFileAsset(plan.ID))
+
+		matches, err := TestDB.SearchFileChunks(workspaceID, "marketing", 10)
+
+		assert.NoError(t, err)
+		assert.Empty(t, matches)
+		chunks, err := TestDB.GetFileChunks(plan.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, chunks)
+	})
+
+	t.Run("Extraction Status", func(t *testing.T) {
+		assert.NoError(t, TestDB.SetFileAssetExtractionStatus(notes.ID, FailedExtraction))
+		assert.Error(t, TestDB.SetFileAssetExtractionStatus(0, FailedExtraction))
+	})
+}
+
+func TestChatMessageContextTags(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	chat := Chat{ID: uuid.New().String(), WorkspaceID: "workspace"}
+	_, err := TestDB.AddChat(&chat)
+	require.NoError(t, err)
+
+	message, err := TestDB.AddChatMessage(&ChatMessage{
+		ID:          uuid.New().String(),
+		ChatID:      chat.ID,
+		Message:     "read this",
+		Role:        UserRole,
+		Status:      SentStatus,
+		ContextTags: ContextTags{{Type: FileContext, ID: "12"}},
+	})
+	require.NoError(t, err)
+
+	messages, err := TestDB.GetChatMessagesForChatID(chat.ID)
+	assert.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, message.ContextTags, messages[0].ContextTags)
+}
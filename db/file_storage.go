@@ -0,0 +1,65 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetFileAssetByUploadFilename returns the file stored under the name it was
+// given at upload, which is what stable file links refer to.
+func (db database) GetFileAssetByUploadFilename(name string) (*FileAsset, error) {
+	var asset FileAsset
+	if err := db.db.Where("upload_filename = ? AND status != ?", name, DeletedFileStatus).First(&asset).Error; err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// GetWorkspaceStorageUsage returns the bytes used by the files of a
+// workspace that have not been deleted.
+func (db database) GetWorkspaceStorageUsage(workspaceID string) (int64, error) {
+	var usage int64
+	if err := db.db.Model(&FileAsset{}).
+		Where("workspace_id = ? AND status != ?", workspaceID, DeletedFileStatus).
+		Select("COALESCE(SUM(file_size), 0)").
+		Scan(&usage).Error; err != nil {
+		return 0, fmt.Errorf("failed to fetch storage usage: %w", err)
+	}
+	return usage, nil
+}
+
+// GetWorkspaceStorageQuota returns the quota override of a workspace, or nil
+// when it uses the default.
+func (db database) GetWorkspaceStorageQuota(workspaceID string) (*WorkspaceStorageQuota, error) {
+	var quota WorkspaceStorageQuota
+	err := db.db.Where("workspace_id = ?", workspaceID).First(&quota).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch storage quota: %w", err)
+	}
+	return &quota, nil
+}
+
+func (db database) SetWorkspaceStorageQuota(quota *WorkspaceStorageQuota) error {
+	if quota.WorkspaceID == "" {
+		return errors.New("workspace ID is required")
+	}
+	if quota.QuotaBytes < 0 {
+		return errors.New("quota cannot be negative")
+	}
+	quota.UpdatedAt = time.Now()
+
+	if err := db.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workspace_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quota_bytes", "updated_at"}),
+	}).Create(quota).Error; err != nil {
+		return fmt.Errorf("failed to save storage quota: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceStorage(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	workspaceID := uuid.New().String()
+	create := func(name string, size int64, status FileStatus) *FileAsset {
+		asset, err := TestDB.CreateFileAsset(&FileAsset{
+			OriginFilename: name,
+			UploadFilename: uuid.New().String() + ".txt",
+			FileSize:       size,
+			Status:         status,
+			WorkspaceID:    workspaceID,
+			StorageKey:     "chat/" + name,
+		})
+		assert.NoError(t, err)
+		return asset
+	}
+
+	active := create("a.txt", 100, ActiveFileStatus)
+	create("b.txt", 50, ArchivedFileStatus)
+	deleted := create("c.txt", 1000, ActiveFileStatus)
+	assert.NoError(t, TestDB.DeleteFileAsset(deleted.ID))
+
+	t.Run("Usage Skips Deleted Files", func(t *testing.T) {
+		usage, err := TestDB.GetWorkspaceStorageUsage(workspaceID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(150), usage)
+	})
+
+	t.Run("Find By Upload Filename", func(t *testing.T) {
+		asset, err := TestDB.GetFileAssetByUploadFilename(active.UploadFilename)
+		assert.NoError(t, err)
+		assert.Equal(t, "chat/a.txt", asset.StorageKey)
+
+		_, err = TestDB.GetFileAssetByUploadFilename(deleted.UploadFilename)
+		assert.Error(t, err)
+	})
+
+	t.Run("Quota", func(t *testing.T) {
+		quota, err := TestDB.GetWorkspaceStorageQuota(workspaceID)
+		assert.NoError(t, err)
+		assert.Nil(t, quota)
+
+		assert.NoError(t, TestDB.SetWorkspaceStorageQuota(&WorkspaceStorageQuota{WorkspaceID: workspaceID, QuotaBytes: 10}))
+		assert.NoError(t, TestDB.SetWorkspaceStorageQuota(&WorkspaceStorageQuota{WorkspaceID: workspaceID, QuotaBytes: 20}))
+
+		quota, err = TestDB.GetWorkspaceStorageQuota(workspaceID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(20), quota.QuotaBytes)
+
+		assert.Error(t, TestDB.SetWorkspaceStorageQuota(&WorkspaceStorageQuota{WorkspaceID: workspaceID, QuotaBytes: -1}))
+	})
+	t.Run("References From Messages", func(t *testing.T) {
+		attached := create("d.pdf", 10, ActiveFileStatus)
+		attached.StoragePath = "https://host/hivechat/file/blob/" + attached.UploadFilename
+		assert.NoError(t, TestDB.UpdateFileAsset(attached))
+		tagged := create("e.txt", 10, ActiveFileStatus)
+		unused := create("f.txt", 10, ActiveFileStatus)
+
+		_, err := TestDB.AddChatMessage(&ChatMessage{ID: uuid.New().String(), ChatID: "storage-chat", PDFURL: attached.StoragePath})
+		assert.NoError(t, err)
+		_, err = TestDB.AddChatMessage(&ChatMessage{
+			ID:          uuid.New().String(),
+			ChatID:      "storage-chat",
+			ContextTags: ContextTags{{Type: FileContext, ID: fmt.Sprint(tagged.ID)}},
+		})
+		assert.NoError(t, err)
+
+		for asset, want := range map[*FileAsset]bool{attached: true, tagged: true, unused: false} {
+			referenced, err := TestDB.IsFileAssetReferenced(*asset)
+			assert.NoError(t, err)
+			assert.Equal(t, want, referenced, asset.OriginFilename)
+		}
+	})
+}
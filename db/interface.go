@@ -115,6 +115,8 @@ type Database interface {
 	WithdrawBudget(sender_pubkey string, workspace_uuid string, amount uint)
 	AddPaymentHistory(payment NewPaymentHistory) NewPaymentHistory
 	ProcessBountyPayment(payment NewPaymentHistory, bounty NewBounty) error
+	ClaimBountyPayment(id uint) (bool, error)
+	ReleaseBountyPayment(id uint, failed bool) error
 	GetPaymentHistory(workspace_uuid string, r *http.Request) []NewPaymentHistory
 	GetInvoice(payment_request string) NewInvoiceList
 	GetWorkspaceInvoices(workspace_uuid string) []NewInvoiceList
@@ -166,6 +168,11 @@ type Database interface {
 	GetPhasesByFeatureUuid(featureUuid string) []FeaturePhase
 	GetFeaturePhaseByUuid(featureUuid, phaseUuid string) (FeaturePhase, error)
 	DeleteFeaturePhase(featureUuid, phaseUuid string) error
+	SaveRoadmapCalendar(calendar *RoadmapCalendar) (RoadmapCalendar, error)
+	GetRoadmapCalendar(workspaceUuid string) (RoadmapCalendar, error)
+	DeleteRoadmapCalendar(workspaceUuid string) error
+	SetFeatureRoadmapDates(uuid string, start, target *time.Time) (WorkspaceFeatures, error)
+	SetFeaturePhaseRoadmapDates(uuid string, start, target *time.Time) (FeaturePhase, error)
 	CreateOrEditFeatureStory(story FeatureStory) (FeatureStory, error)
 	GetFeatureStoriesByFeatureUuid(featureUuid string) ([]FeatureStory, error)
 	GetFeatureStoryByUuid(featureUuid, storyUuid string) (FeatureStory, error)
@@ -212,8 +219,22 @@ type Database interface {
 	GetChatByChatID(chatID string) (Chat, error)
 	AddChatMessage(message *ChatMessage) (ChatMessage, error)
 	UpdateChatMessage(message *ChatMessage) (ChatMessage, error)
+	AppendChatMessageChunk(chatID, messageID string, sequence int, delta string) (ChatMessage, []ChatMessageDelta, error)
+	FinalizeChatMessage(chatID, messageID, response string) (ChatMessage, bool, error)
+	ExpireStreamingChatMessages(before time.Time) ([]ChatMessage, error)
 	GetChatMessagesForChatID(chatID string) ([]ChatMessage, error)
+	GetChatBranchHistory(chatID, branchID string) ([]ChatMessage, error)
+	CreateChatBranch(chatID, parentMessageID string) (ChatBranch, error)
+	GetChatBranches(chatID string) ([]ChatBranch, error)
+	SetActiveChatBranch(chatID, branchID string) (Chat, error)
+	ImportChat(chat Chat, branches []ChatBranch, messages []ChatMessage, artifacts []Artifact) (Chat, error)
 	GetChatsForWorkspace(workspaceID string, chatStatus string) ([]Chat, error)
+	GetChatMessageByID(messageID string) (ChatMessage, error)
+	GetChatStatusByUUID(id uuid.UUID) (ChatWorkflowStatus, error)
+	CreateChatShareLink(link *ChatShareLink) (ChatShareLink, error)
+	GetChatShareLinkByTokenHash(tokenHash string) (ChatShareLink, error)
+	GetChatShareLinks(chatID string) ([]ChatShareLink, error)
+	RevokeChatShareLink(chatID, linkID string) error
 	GetCodeGraphByUUID(uuid string) (WorkspaceCodeGraph, error)
 	GetCodeGraphByWorkspaceUuid(workspace_uuid string) (WorkspaceCodeGraph, error)
 	CreateOrEditCodeGraph(m WorkspaceCodeGraph) (WorkspaceCodeGraph, error)
@@ -237,7 +258,7 @@ type Database interface {
 	UpdateBountyTimingOnProof(bountyID uint) error
 	GetWorkspaceBountyCardsData(r *http.Request) []NewBounty
 	UpdateFeatureStatus(uuid string, status FeatureStatus) (WorkspaceFeatures, error)
-	CreateBountyFromTicket(ticket Tickets, pubkey string) (*NewBounty, error)
+	CreateBountyFromTicket(ticket Tickets, pubkey string, template WorkspaceTemplate) (*NewBounty, error)
 	AddFeatureFlag(flag *FeatureFlag) (FeatureFlag, error)
 	UpdateFeatureFlag(flag *FeatureFlag) (FeatureFlag, error)
 	DeleteFeatureFlag(flagUUID uuid.UUID) error
@@ -251,6 +272,38 @@ type Database interface {
 	GetEndpointByPath(path string) (Endpoint, error)
 	GetAllEndpoints() ([]Endpoint, error)
 	GetLatestTicketByGroup(ticketGroup uuid.UUID) (Tickets, error)
+	GetTicketRevisions(ticketGroup uuid.UUID) ([]TicketRevision, error)
+	GetTicketRevision(ticketGroup uuid.UUID, version int) (TicketRevision, error)
+	GetTicketWorkflow(workspaceUuid string) (TicketWorkflow, error)
+	UpsertTicketWorkflow(workflow *TicketWorkflow) (TicketWorkflow, error)
+	GetWorkspaceTickets(workspaceUuid string) ([]Tickets, error)
+	GetPeopleByMentions(names []string) ([]Person, error)
+	CreateTicketComment(comment *TicketComment) (TicketComment, error)
+	GetTicketComment(id uuid.UUID) (TicketComment, error)
+	GetTicketComments(targetType CommentTargetType, targetUUID string) ([]TicketComment, error)
+	UpdateTicketCommentBody(id uuid.UUID, body string, mentions []string) (TicketComment, error)
+	ResolveTicketCommentThread(id uuid.UUID, resolved bool, resolvedBy string) (TicketComment, error)
+	DeleteTicketComment(id uuid.UUID) error
+	GetTicketCommentCounts(targetType CommentTargetType, targetUUIDs []string) (map[string]TicketCommentCount, error)
+	InTransaction(fn func(Database) error) error
+	GetWorkspaceTemplates(workspaceUuid string, kind TemplateKind) ([]WorkspaceTemplate, error)
+	GetWorkspaceTemplate(id uuid.UUID) (WorkspaceTemplate, error)
+	GetDefaultWorkspaceTemplate(workspaceUuid string, kind TemplateKind) (WorkspaceTemplate, error)
+	CreateOrEditWorkspaceTemplate(template *WorkspaceTemplate) (WorkspaceTemplate, error)
+	DeleteWorkspaceTemplate(id uuid.UUID) error
+	CreateTicketGithubIssue(link *TicketGithubIssue) (TicketGithubIssue, error)
+	GetTicketGithubIssuesByRepo(owner, repo string) ([]TicketGithubIssue, error)
+	GetAllTicketGithubIssues() ([]TicketGithubIssue, error)
+	UpdateTicketGithubIssue(link *TicketGithubIssue) error
+	MarkTicketGithubIssuesSynced(ids []uuid.UUID, syncedAt time.Time) error
+	DeleteTicketGithubIssue(id uuid.UUID) error
+	CreateBountyPullRequest(pull *BountyPullRequest) (BountyPullRequest, error)
+	GetBountyPullRequest(id uuid.UUID) (BountyPullRequest, error)
+	GetBountyPullRequests(bountyID uint) ([]BountyPullRequest, error)
+	GetBountyPullRequestsByPull(owner, repo string, number int) ([]BountyPullRequest, error)
+	GetQueuedBountyPullRequests() ([]BountyPullRequest, error)
+	UpdateBountyPullRequest(pull *BountyPullRequest) error
+	DeleteBountyPullRequest(id uuid.UUID) error
 	GetAllTicketGroups(workspaceUuid string) ([]uuid.UUID, error)
 	GetFeaturedBountyById(id string) (FeaturedBounty, error)
 	GetAllFeaturedBounties() ([]FeaturedBounty, error)
@@ -277,11 +330,26 @@ type Database interface {
 	DeleteSnippet(id uint) error
 	CreateFileAsset(asset *FileAsset) (*FileAsset, error)
 	GetFileAssetByHash(fileHash string) (*FileAsset, error)
+	GetFileAssetsByStoragePaths(paths []string) ([]FileAsset, error)
 	GetFileAssetByID(id uint) (*FileAsset, error)
 	UpdateFileAssetReference(id uint) error
+	IsFileAssetReferenced(asset FileAsset) (bool, error)
 	ListFileAssets(params ListFileAssetsParams) ([]FileAsset, int64, error)
 	UpdateFileAsset(asset *FileAsset) error
 	DeleteFileAsset(id uint) error
+	GetFileAssetByUploadFilename(name string) (*FileAsset, error)
+	GetWorkspaceStorageUsage(workspaceID string) (int64, error)
+	GetWorkspaceStorageQuota(workspaceID string) (*WorkspaceStorageQuota, error)
+	SetWorkspaceStorageQuota(quota *WorkspaceStorageQuota) error
+	ReplaceFileChunks(fileID uint, chunks []FileChunk) error
+	GetFileChunks(fileID uint) ([]FileChunk, error)
+	SetFileAssetExtractionStatus(id uint, status ExtractionStatus) error
+	SearchFileChunks(workspaceID string, query string, limit int) ([]FileChunkMatch, error)
+	GetKnowledgeSources(workspaceUUID string) ([]KnowledgeItem, error)
+	SyncKnowledgeItems(workspaceUUID string, items []KnowledgeItem) error
+	GetKnowledgeItems(workspaceUUID string) ([]KnowledgeItem, error)
+	GetKnowledgeItemByID(id uint) (*KnowledgeItem, error)
+	UpdateKnowledgeItemEmbedding(id uint, embedding Vector, model string) error
 	DeleteBountyTiming(bountyID uint) error
 	DeleteTicketGroup(TicketGroupUUID uuid.UUID) error
 	PauseBountyTiming(bountyID uint) error
@@ -296,6 +364,10 @@ type Database interface {
 	GetTicketPlansByFeature(featureUUID string) ([]TicketPlan, error)
 	GetTicketPlansByPhase(phaseUUID string) ([]TicketPlan, error)
 	GetTicketPlansByWorkspace(workspaceUUID string) ([]TicketPlan, error)
+	CreateTicketPlanTicket(link *TicketPlanTicket) (TicketPlanTicket, error)
+	GetTicketPlanTickets(planUUID string) ([]TicketPlanTicket, error)
+	UpdateTicketPlanTicket(link *TicketPlanTicket) error
+	DeleteTicketPlanTicket(id uuid.UUID) error
 	CreateActivity(activity *Activity) (*Activity, error)
 	UpdateActivity(activity *Activity) (*Activity, error)
 	GetActivity(id string) (*Activity, error)
@@ -317,6 +389,9 @@ type Database interface {
 	UpdateArtifact(artifact *Artifact) (*Artifact, error)
 	DeleteArtifactByID(id uuid.UUID) error
 	DeleteAllArtifactsByChatID(chatID string) error
+	ClaimActionExecution(execution *ActionExecution) (ActionExecution, error)
+	CompleteActionExecution(execution *ActionExecution) (ActionExecution, error)
+	GetActionExecutions(artifactID uuid.UUID) ([]ActionExecution, error)
 	CreateOrUpdateFeatureCall(workspaceID string, url string) (*FeatureCall, error)
 	GetFeatureCallByWorkspaceID(workspaceID string) (*FeatureCall, error)
 	DeleteFeatureCall(workspaceID string) error
@@ -341,6 +416,10 @@ type Database interface {
 	GetSSEMessageLogByID(id uuid.UUID) (*SSEMessageLog, error)
 	GetSSEMessageLogsByChatID(chatID string) ([]SSEMessageLog, error)
 	GetNewSSEMessageLogsByChatID(chatID string) ([]SSEMessageLog, error)
+	SaveSSEClientState(state SSEClientState) error
+	GetSSEClientState(chatID, url string) (*SSEClientState, error)
+	GetSSEClientStates(chatID string) ([]SSEClientState, error)
+	GetResumableSSEClientStates() ([]SSEClientState, error)
 	CreateCodeSpaceMap(codeSpace CodeSpaceMap) (CodeSpaceMap, error)
 	GetCodeSpaceMaps() ([]CodeSpaceMap, error)
 	GetCodeSpaceMapByWorkspace(workspaceID string) ([]CodeSpaceMap, error)
@@ -362,4 +441,11 @@ type Database interface {
 	GetChatStatusByChatID(chatID string) ([]ChatWorkflowStatus, error)
 	GetLatestChatStatusByChatID(chatID string) (ChatWorkflowStatus, error)
 	DeleteChatStatus(uuid uuid.UUID) error
+	StartJobRun(name, holder string) (uint, error)
+	FinishJobRun(id uint, runErr error) error
+	GetLastJobSuccess(name string) (*time.Time, error)
+	GetJobRuns(name string, limit int) ([]JobRun, error)
+	GetJobStatuses() ([]JobStatus, error)
+	RecordChatEvent(chatID string, eventType ChatEventType, payload interface{}) (*ChatEvent, error)
+	GetChatEventsAfter(chatID string, afterID uint64, limit int) ([]ChatEvent, error)
 }
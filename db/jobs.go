@@ -0,0 +1,110 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (db database) StartJobRun(name, holder string) (uint, error) {
+	if name == "" {
+		return 0, errors.New("job name is required")
+	}
+
+	run := JobRun{
+		JobName:   name,
+		Holder:    holder,
+		Status:    JobRunRunning,
+		StartedAt: time.Now(),
+	}
+	if err := db.db.Create(&run).Error; err != nil {
+		return 0, fmt.Errorf("failed to record job run: %w", err)
+	}
+
+	return run.ID, nil
+}
+
+func (db database) FinishJobRun(id uint, runErr error) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      JobRunSuccess,
+		"finished_at": &now,
+	}
+	if runErr != nil {
+		updates["status"] = JobRunFailed
+		updates["error"] = runErr.Error()
+	}
+
+	result := db.db.Model(&JobRun{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to finish job run: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("job run not found")
+	}
+
+	return nil
+}
+
+// GetLastJobSuccess returns when the latest successful run of a job started,
+// or nil if the job never succeeded.
+func (db database) GetLastJobSuccess(name string) (*time.Time, error) {
+	var run JobRun
+	err := db.db.Where("job_name = ? AND status = ?", name, JobRunSuccess).
+		Order("started_at DESC").
+		First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch last job success: %w", err)
+	}
+
+	return &run.StartedAt, nil
+}
+
+func (db database) GetJobRuns(name string, limit int) ([]JobRun, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var runs []JobRun
+	if err := db.db.Where("job_name = ?", name).
+		Order("started_at DESC").
+		Limit(limit).
+		Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch job runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+func (db database) GetJobStatuses() ([]JobStatus, error) {
+	var latest []JobRun
+	if err := db.db.Raw(`
+		SELECT DISTINCT ON (job_name) *
+		FROM job_runs
+		ORDER BY job_name, started_at DESC
+	`).Scan(&latest).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch job statuses: %w", err)
+	}
+
+	statuses := make([]JobStatus, 0, len(latest))
+	for _, run := range latest {
+		lastSuccess, err := db.GetLastJobSuccess(run.JobName)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, JobStatus{
+			JobName:       run.JobName,
+			LastRunAt:     run.StartedAt,
+			LastStatus:    run.Status,
+			LastHolder:    run.Holder,
+			LastSuccessAt: lastSuccess,
+		})
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobRuns(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	TestDB.db.Exec("DELETE FROM job_runs")
+
+	lastSuccess, err := TestDB.GetLastJobSuccess("v2 payments")
+	assert.NoError(t, err)
+	assert.Nil(t, lastSuccess)
+
+	firstID, err := TestDB.StartJobRun("v2 payments", "replica-a")
+	assert.NoError(t, err)
+	assert.NoError(t, TestDB.FinishJobRun(firstID, nil))
+
+	secondID, err := TestDB.StartJobRun("v2 payments", "replica-b")
+	assert.NoError(t, err)
+	assert.NoError(t, TestDB.FinishJobRun(secondID, errors.New("relay unavailable")))
+
+	_, err = TestDB.StartJobRun("", "replica-a")
+	assert.Error(t, err)
+	assert.Error(t, TestDB.FinishJobRun(999999, nil))
+
+	lastSuccess, err = TestDB.GetLastJobSuccess("v2 payments")
+	assert.NoError(t, err)
+	assert.NotNil(t, lastSuccess)
+
+	runs, err := TestDB.GetJobRuns("v2 payments", 10)
+	assert.NoError(t, err)
+	assert.Len(t, runs, 2)
+	assert.Equal(t, secondID, runs[0].ID)
+	assert.Equal(t, JobRunFailed, runs[0].Status)
+	assert.Equal(t, "relay unavailable", runs[0].Error)
+	assert.Equal(t, JobRunSuccess, runs[1].Status)
+
+	statuses, err := TestDB.GetJobStatuses()
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, JobRunFailed, statuses[0].LastStatus)
+	assert.Equal(t, "replica-b", statuses[0].LastHolder)
+	assert.NotNil(t, statuses[0].LastSuccessAt)
+}
+
+func TestAdvisoryLocker(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	locker := TestDB.NewAdvisoryLocker()
+	ctx := context.Background()
+
+	unlock, acquired, err := locker.TryLock(ctx, "test job")
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	_, acquired, err = locker.TryLock(ctx, "test job")
+	assert.NoError(t, err)
+	assert.False(t, acquired)
+
+	unlock()
+
+	unlock, acquired, err = locker.TryLock(ctx, "test job")
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+	unlock()
+}
@@ -0,0 +1,185 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetKnowledgeSources collects the knowledge of a workspace from its
+// mission and tactics, the briefs, requirements and architecture of its
+// features that are not archived, their phase designs and stories, and its
+// text snippets. Empty texts are skipped.
+func (db database) GetKnowledgeSources(workspaceUUID string) ([]KnowledgeItem, error) {
+	items := []KnowledgeItem{}
+	add := func(sourceType KnowledgeSourceType, sourceID, title, content string) {
+		content = strings.TrimSpace(content)
+		if content == "" {
+			return
+		}
+		items = append(items, KnowledgeItem{
+			WorkspaceUUID: workspaceUUID,
+			SourceType:    sourceType,
+			SourceID:      sourceID,
+			Title:         title,
+			Content:       content,
+		})
+	}
+
+	workspace := Workspace{}
+	if err := db.db.Where("uuid = ?", workspaceUUID).First(&workspace).Error; err != nil {
+		return nil, fmt.Errorf("error getting workspace: %w", err)
+	}
+	add(KnowledgeMission, workspace.Uuid, workspace.Name+" mission", workspace.Mission)
+	add(KnowledgeTactics, workspace.Uuid, workspace.Name+" tactics", workspace.Tactics)
+
+	features := []WorkspaceFeatures{}
+	if err := db.db.Where("workspace_uuid = ? AND feat_status != ?", workspaceUUID, ArchivedFeature).
+		Order("priority ASC").Find(&features).Error; err != nil {
+		return nil, fmt.Errorf("error getting features: %w", err)
+	}
+	featureNames := map[string]string{}
+	featureUUIDs := make([]string, len(features))
+	for i, feature := range features {
+		featureNames[feature.Uuid] = feature.Name
+		featureUUIDs[i] = feature.Uuid
+		add(KnowledgeFeatureBrief, feature.Uuid, feature.Name+" brief", feature.Brief)
+		add(KnowledgeRequirements, feature.Uuid, feature.Name+" requirements", feature.Requirements)
+		add(KnowledgeArchitecture, feature.Uuid, feature.Name+" architecture", feature.Architecture)
+	}
+
+	if len(featureUUIDs) > 0 {
+		phases := []FeaturePhase{}
+		if err := db.db.Where("feature_uuid IN ?", featureUUIDs).Order("priority ASC").Find(&phases).Error; err != nil {
+			return nil, fmt.Errorf("error getting phases: %w", err)
+		}
+		for _, phase := range phases {
+			add(KnowledgePhaseDesign, phase.Uuid, fmt.Sprintf("%s / %s design", featureNames[phase.FeatureUuid], phase.Name), phase.PhaseDesign)
+		}
+
+		stories := []FeatureStory{}
+		if err := db.db.Where("feature_uuid IN ?", featureUUIDs).Order("priority ASC").Find(&stories).Error; err != nil {
+			return nil, fmt.Errorf("error getting stories: %w", err)
+		}
+		for _, story := range stories {
+			add(KnowledgeStory, story.Uuid, featureNames[story.FeatureUuid]+" story", story.Description)
+		}
+	}
+
+	snippets := []TextSnippet{}
+	if err := db.db.Where("workspace_uuid = ?", workspaceUUID).Order("id ASC").Find(&snippets).Error; err != nil {
+		return nil, fmt.Errorf("error getting snippets: %w", err)
+	}
+	for _, snippet := range snippets {
+		add(KnowledgeSnippet, strconv.FormatUint(uint64(snippet.ID), 10), snippet.Title, snippet.Snippet)
+	}
+
+	return items, nil
+}
+
+func knowledgeKey(sourceType KnowledgeSourceType, sourceID string) string {
+	return string(sourceType) + ":" + sourceID
+}
+
+func knowledgeHash(item KnowledgeItem) string {
+	sum := sha256.Sum256([]byte(item.Title + "\x00" + item.Content))
+	return hex.EncodeToString(sum[:])
+}
+
+// SyncKnowledgeItems makes the stored knowledge of a workspace match items.
+// Items whose text changed lose their embedding; items no longer present are
+// removed.
+func (db database) SyncKnowledgeItems(workspaceUUID string, items []KnowledgeItem) error {
+	return db.db.Transaction(func(tx *gorm.DB) error {
+		existing := []KnowledgeItem{}
+		if err := tx.Where("workspace_uuid = ?", workspaceUUID).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to fetch knowledge items: %w", err)
+		}
+		stored := map[string]KnowledgeItem{}
+		for _, item := range existing {
+			stored[knowledgeKey(item.SourceType, item.SourceID)] = item
+		}
+
+		now := time.Now()
+		for _, item := range items {
+			key := knowledgeKey(item.SourceType, item.SourceID)
+			hash := knowledgeHash(item)
+			old, found := stored[key]
+			delete(stored, key)
+
+			if found && old.ContentHash == hash {
+				continue
+			}
+			if found {
+				err := tx.Model(&KnowledgeItem{}).Where("id = ?", old.ID).Updates(map[string]interface{}{
+					"title":           item.Title,
+					"content":         item.Content,
+					"content_hash":    hash,
+					"embedding":       nil,
+					"embedding_model": "",
+					"updated_at":      now,
+				}).Error
+				if err != nil {
+					return fmt.Errorf("failed to update knowledge item: %w", err)
+				}
+				continue
+			}
+
+			item.ID = 0
+			item.WorkspaceUUID = workspaceUUID
+			item.ContentHash = hash
+			item.Embedding = nil
+			item.EmbeddingModel = ""
+			item.UpdatedAt = now
+			if err := tx.Create(&item).Error; err != nil {
+				return fmt.Errorf("failed to save knowledge item: %w", err)
+			}
+		}
+
+		if len(stored) > 0 {
+			ids := make([]uint, 0, len(stored))
+			for _, item := range stored {
+				ids = append(ids, item.ID)
+			}
+			if err := tx.Where("id IN ?", ids).Delete(&KnowledgeItem{}).Error; err != nil {
+				return fmt.Errorf("failed to remove knowledge items: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (db database) GetKnowledgeItems(workspaceUUID string) ([]KnowledgeItem, error) {
+	items := []KnowledgeItem{}
+	if err := db.db.Where("workspace_uuid = ?", workspaceUUID).Order("id ASC").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch knowledge items: %w", err)
+	}
+	return items, nil
+}
+
+func (db database) GetKnowledgeItemByID(id uint) (*KnowledgeItem, error) {
+	item := KnowledgeItem{}
+	if err := db.db.Where("id = ?", id).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (db database) UpdateKnowledgeItemEmbedding(id uint, embedding Vector, model string) error {
+	result := db.db.Model(&KnowledgeItem{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"embedding":       embedding,
+		"embedding_model": model,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no knowledge item found with id %d", id)
+	}
+	return nil
+}
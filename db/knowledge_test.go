@@ -0,0 +1,102 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKnowledgeItems(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	workspace := Workspace{
+		Uuid:        uuid.New().String(),
+		Name:        "knowledge-" + uuid.New().String()[:8],
+		OwnerPubKey: "knowledge-owner",
+		Mission:     "Pay developers to build open source",
+	}
+	TestDB.CreateOrEditWorkspace(workspace)
+
+	feature, err := TestDB.CreateOrEditFeature(WorkspaceFeatures{
+		Uuid:          uuid.New().String(),
+		WorkspaceUuid: workspace.Uuid,
+		Name:          "Payments",
+		Brief:         "Pay bounties over lightning",
+		Architecture:  "Payments go through the v2 bot",
+	})
+	require.NoError(t, err)
+	archived, err := TestDB.CreateOrEditFeature(WorkspaceFeatures{
+		Uuid:          uuid.New().String(),
+		WorkspaceUuid: workspace.Uuid,
+		Name:          "Old",
+		Brief:         "Archived brief",
+		FeatStatus:    ArchivedFeature,
+	})
+	require.NoError(t, err)
+	_, err = TestDB.CreateOrEditFeaturePhase(FeaturePhase{Uuid: uuid.New().String(), FeatureUuid: feature.Uuid, Name: "MVP", PhaseDesign: "Invoices are paid in the background"})
+	require.NoError(t, err)
+	_, err = TestDB.CreateOrEditFeatureStory(FeatureStory{Uuid: uuid.New().String(), FeatureUuid: feature.Uuid, Description: "As a hunter I get paid"})
+	require.NoError(t, err)
+	snippet, err := TestDB.CreateSnippet(&TextSnippet{WorkspaceUUID: workspace.Uuid, Title: "Tone", Snippet: "Be brief"})
+	require.NoError(t, err)
+
+	t.Run("Collects Sources", func(t *testing.T) {
+		items, err := TestDB.GetKnowledgeSources(workspace.Uuid)
+		require.NoError(t, err)
+
+		types := map[KnowledgeSourceType]string{}
+		for _, item := range items {
+			assert.NotEqual(t, archived.Uuid, item.SourceID)
+			types[item.SourceType] = item.Content
+		}
+		assert.Len(t, items, 6)
+		assert.Equal(t, "Pay developers to build open source", types[KnowledgeMission])
+		assert.Equal(t, "Payments go through the v2 bot", types[KnowledgeArchitecture])
+		assert.Equal(t, "Invoices are paid in the background", types[KnowledgePhaseDesign])
+		assert.Equal(t, "As a hunter I get paid", types[KnowledgeStory])
+		assert.Equal(t, "Be brief", types[KnowledgeSnippet])
+		assert.NotContains(t, types, KnowledgeTactics)
+	})
+
+	t.Run("Sync Keeps Embeddings Of Unchanged Items", func(t *testing.T) {
+		items, err := TestDB.GetKnowledgeSources(workspace.Uuid)
+		require.NoError(t, err)
+		require.NoError(t, TestDB.SyncKnowledgeItems(workspace.Uuid, items))
+
+		stored, err := TestDB.GetKnowledgeItems(workspace.Uuid)
+		require.NoError(t, err)
+		require.Len(t, stored, 6)
+		for _, item := range stored {
+			require.NoError(t, TestDB.UpdateKnowledgeItemEmbedding(item.ID, Vector{1, 2}, "model"))
+		}
+
+		require.NoError(t, TestDB.DeleteSnippet(snippet.ID))
+		feature.Brief = "Pay bounties over lightning and on chain"
+		_, err = TestDB.CreateOrEditFeature(feature)
+		require.NoError(t, err)
+
+		items, err = TestDB.GetKnowledgeSources(workspace.Uuid)
+		require.NoError(t, err)
+		require.NoError(t, TestDB.SyncKnowledgeItems(workspace.Uuid, items))
+
+		stored, err = TestDB.GetKnowledgeItems(workspace.Uuid)
+		require.NoError(t, err)
+		assert.Len(t, stored, 5)
+		for _, item := range stored {
+			assert.NotEqual(t, KnowledgeSnippet, item.SourceType)
+			if item.SourceType == KnowledgeFeatureBrief {
+				assert.Equal(t, "Pay bounties over lightning and on chain", item.Content)
+				assert.Empty(t, item.Embedding)
+			} else {
+				assert.Equal(t, Vector{1, 2}, item.Embedding)
+			}
+		}
+
+		found, err := TestDB.GetKnowledgeItemByID(stored[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, workspace.Uuid, found.WorkspaceUUID)
+	})
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/utils"
+	"gorm.io/gorm"
+)
+
+const lockKeyPrefix = "tribes:lock:"
+
+// AdvisoryLocker hands out cluster wide locks using postgres session level
+// advisory locks. Each held lock pins one pooled connection until unlocked,
+// and is released by postgres if the process dies.
+type AdvisoryLocker struct {
+	db *gorm.DB
+}
+
+func (db database) NewAdvisoryLocker() *AdvisoryLocker {
+	return &AdvisoryLocker{db: db.db}
+}
+
+func (l *AdvisoryLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get lock connection: %w", err)
+	}
+
+	key := lockKeyPrefix + name
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", key); err != nil {
+			logger.Log.Error("[locks] failed to release lock %s: %v", name, err)
+		}
+		conn.Close()
+	}
+	return unlock, true, nil
+}
+
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+var extendLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// RedisLocker hands out cluster wide locks stored in redis. Locks expire
+// after ttl unless they are renewed, which happens while they are held.
+type RedisLocker struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisLocker(client *redis.Client, ttl time.Duration) *RedisLocker {
+	return &RedisLocker{client: client, ttl: ttl}
+}
+
+func (l *RedisLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	key := lockKeyPrefix + name
+	token := utils.GetRandomToken(20)
+
+	acquired, err := l.client.SetNX(ctx, key, token, l.ttl).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	if !acquired {
+		return nil, false, nil
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				err := extendLockScript.Run(context.Background(), l.client, []string{key}, token, l.ttl.Milliseconds()).Err()
+				if err != nil {
+					logger.Log.Error("[locks] failed to renew lock %s: %v", name, err)
+				}
+			}
+		}
+	}()
+
+	unlock := func() {
+		close(stop)
+		if err := releaseLockScript.Run(context.Background(), l.client, []string{key}, token).Err(); err != nil {
+			logger.Log.Error("[locks] failed to release lock %s: %v", name, err)
+		}
+	}
+	return unlock, true, nil
+}
@@ -0,0 +1,352 @@
+package db
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/logger"
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey is the postgres advisory lock taken while a migration runs,
+// so that replicas booting at the same time do not apply the same version twice.
+const migrationLockKey = 72616269
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// goMigrations are migrations that cannot be expressed as plain SQL.
+var goMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		Up:      baselineUp,
+		Down: func(tx *gorm.DB) error {
+			return errors.New("the baseline migration cannot be reverted")
+		},
+	},
+}
+
+func baselineUp(tx *gorm.DB) error {
+	for _, model := range baselineModels {
+		if err := tx.AutoMigrate(model); err != nil {
+			return fmt.Errorf("failed to migrate %T: %w", model, err)
+		}
+	}
+
+	migrateLegacyTables(tx)
+
+	return nil
+}
+
+func (db database) NewMigrator() (*Migrator, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	return NewMigrator(db.db, migrations), nil
+}
+
+func NewMigrator(gormDB *gorm.DB, migrations []Migration) *Migrator {
+	return &Migrator{
+		db:         gormDB,
+		migrations: migrations,
+	}
+}
+
+// LoadMigrations returns the Go migrations and the embedded SQL migrations
+// ordered by version.
+func LoadMigrations() ([]Migration, error) {
+	byVersion := map[int]*Migration{}
+	for i := range goMigrations {
+		m := goMigrations[i]
+		byVersion[m.Version] = &m
+	}
+
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	for _, entry := range entries {
+		version, name, direction, err := parseMigrationFilename(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, m.Name, name)
+		}
+
+		if direction == "up" {
+			m.Up = sqlMigration(string(content))
+		} else {
+			m.Down = sqlMigration(string(content))
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == nil {
+			return nil, fmt.Errorf("migration %04d_%s has no up migration", m.Version, m.Name)
+		}
+		if m.Down == nil {
+			return nil, fmt.Errorf("migration %04d_%s has no down migration", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func parseMigrationFilename(filename string) (int, string, string, error) {
+	matches := migrationFilePattern.FindStringSubmatch(filename)
+	if matches == nil {
+		return 0, "", "", fmt.Errorf("invalid migration filename: %s", filename)
+	}
+
+	version, err := strconv.Atoi(matches[1])
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("invalid migration version in %s", filename)
+	}
+
+	return version, matches[2], matches[3], nil
+}
+
+func sqlMigration(statements string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		if isEmptySQL(statements) {
+			return nil
+		}
+		return tx.Exec(statements).Error
+	}
+}
+
+func isEmptySQL(statements string) bool {
+	for _, line := range strings.Split(statements, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Migrator) ensureTable() error {
+	return m.db.AutoMigrate(&SchemaMigration{})
+}
+
+func (m *Migrator) applied() (map[int]SchemaMigration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, fmt.Errorf("failed to create %s table: %w", SchemaMigration{}.TableName(), err)
+	}
+
+	var rows []SchemaMigration
+	if err := m.db.Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	applied := make(map[int]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Up applies pending migrations in version order. A steps value of zero
+// applies every pending migration.
+func (m *Migrator) Up(steps int) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := pendingMigrations(m.migrations, applied)
+	if steps > 0 && steps < len(pending) {
+		pending = pending[:steps]
+	}
+
+	var done []Migration
+	for _, migration := range pending {
+		ran, err := m.apply(migration)
+		if err != nil {
+			return done, err
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+
+	return done, nil
+}
+
+// Down reverts the most recently applied migrations, newest first.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be greater than zero")
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	var done []Migration
+	for _, version := range versions {
+		migration, ok := known[version]
+		if !ok {
+			return done, fmt.Errorf("applied migration %d is not known to this build", version)
+		}
+		if err := m.revert(migration); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Redo reverts and re-applies the latest migration.
+func (m *Migrator) Redo() (*Migration, error) {
+	reverted, err := m.Down(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(reverted) == 0 {
+		return nil, errors.New("no applied migration to redo")
+	}
+
+	migration := reverted[0]
+	if _, err := m.apply(migration); err != nil {
+		return nil, err
+	}
+	return &migration, nil
+}
+
+func (m *Migrator) apply(migration Migration) (bool, error) {
+	ran := false
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+
+		var count int64
+		if err := tx.Model(&SchemaMigration{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		logger.Log.Info("applying migration %04d_%s", migration.Version, migration.Name)
+		if err := migration.Up(tx); err != nil {
+			return fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+
+		ran = true
+		return tx.Create(&SchemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now(),
+		}).Error
+	})
+	return ran, err
+}
+
+func (m *Migrator) revert(migration Migration) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+
+		logger.Log.Info("reverting migration %04d_%s", migration.Version, migration.Name)
+		if err := migration.Down(tx); err != nil {
+			return fmt.Errorf("reverting migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+
+		return tx.Where("version = ?", migration.Version).Delete(&SchemaMigration{}).Error
+	})
+}
+
+func pendingMigrations(migrations []Migration, applied map[int]SchemaMigration) []Migration {
+	var pending []Migration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
+}
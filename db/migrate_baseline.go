@@ -0,0 +1,974 @@
+package db
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// The baseline migration creates the schema from these copies of the models
+// as they stood when startup AutoMigrate was retired. They only carry the gorm
+// tags and must never change; new tables and columns belong in a SQL migration.
+var baselineModels = []interface{}{
+	&baselineTribe{},
+	&baselinePerson{},
+	&baselineChannel{},
+	&baselineLeaderBoard{},
+	&baselineConnectionCodes{},
+	&baselineBountyRoles{},
+	&baselineUserInvoiceData{},
+	&baselineWorkspaceRepositories{},
+	&baselineWorkspaceCodeGraph{},
+	&baselineWorkspaceFeatures{},
+	&baselineFeaturePhase{},
+	&baselineFeatureStory{},
+	&baselineWfRequest{},
+	&baselineWfProcessingMap{},
+	&baselineTickets{},
+	&baselineChatMessage{},
+	&baselineChat{},
+	&baselineProofOfWork{},
+	&baselineBountyTiming{},
+	&baselineFeatureFlag{},
+	&baselineEndpoint{},
+	&baselineFeaturedBounty{},
+	&baselineNotification{},
+	&baselineTextSnippet{},
+	&baselineFileAsset{},
+	&baselineTicketPlan{},
+	&baselineActivity{},
+	&baselineArtifact{},
+	&baselineFeatureCall{},
+	&baselineChatWorkflow{},
+	&baselineSkill{},
+	&baselineSkillInstall{},
+	&baselineSSEMessageLog{},
+	&baselineCodeSpaceMap{},
+	&baselineBountyStake{},
+	&baselineChatWorkflowStatus{},
+}
+
+type baselineTribe struct {
+	UUID            string
+	OwnerPubKey     string
+	OwnerAlias      string
+	GroupKey        string
+	Name            string
+	UniqueName      string
+	Description     string
+	Tags            pq.StringArray `gorm:"type:text[]"`
+	Img             string
+	PriceToJoin     int64
+	PricePerMessage int64
+	EscrowAmount    int64
+	EscrowMillis    int64
+	Created         *time.Time
+	Updated         *time.Time
+	MemberCount     uint64
+	Unlisted        bool
+	Private         bool
+	Deleted         bool
+	AppURL          string
+	FeedURL         string
+	SecondBrainUrl  string
+	FeedType        uint64
+	LastActive      int64
+	Bots            string
+	OwnerRouteHint  string
+	Pin             string
+	Preview         string
+	ProfileFilters  string
+	Badges          pq.StringArray `gorm:"type:text[]"`
+}
+
+func (baselineTribe) TableName() string { return "tribes" }
+
+type baselinePerson struct {
+	ID               uint
+	Uuid             string
+	OwnerPubKey      string `gorm:"uniqueIndex,unique"`
+	OwnerAlias       string
+	UniqueName       string
+	Description      string
+	Tags             pq.StringArray `gorm:"type:text[]"`
+	Img              string
+	Created          *time.Time
+	Updated          *time.Time
+	Unlisted         bool
+	Deleted          bool
+	LastLogin        int64
+	OwnerRouteHint   string
+	OwnerContactKey  string
+	PriceToMeet      int64
+	NewTicketTime    int64
+	TwitterConfirmed bool
+	ReferredBy       uint
+	Extras           PropertyMap
+	GithubIssues     PropertyMap
+}
+
+func (baselinePerson) TableName() string { return "people" }
+
+type baselineChannel struct {
+	ID        uint
+	TribeUUID string
+	Name      string
+	Created   *time.Time
+	Deleted   bool
+}
+
+func (baselineChannel) TableName() string { return "channels" }
+
+type baselineLeaderBoard struct {
+	TribeUuid  string
+	Alias      string
+	Spent      int64
+	Earned     int64
+	Reputation int64
+}
+
+func (baselineLeaderBoard) TableName() string { return "leader_boards" }
+
+type baselineConnectionCodes struct {
+	ID               uint
+	ConnectionString string
+	IsUsed           bool
+	DateCreated      *time.Time
+}
+
+func (baselineConnectionCodes) TableName() string { return "connectioncodes" }
+
+type baselineBountyRoles struct {
+	Name string
+}
+
+func (baselineBountyRoles) TableName() string { return "bounty_roles" }
+
+type baselineUserInvoiceData struct {
+	ID             uint
+	Amount         uint
+	PaymentRequest string
+	Created        int
+	UserPubkey     string
+	AssignedHours  uint
+	CommitmentFee  uint
+	BountyExpires  string
+	RouteHint      string
+}
+
+func (baselineUserInvoiceData) TableName() string { return "user_invoice_data" }
+
+type baselineWorkspaceRepositories struct {
+	ID            uint
+	Uuid          string `gorm:"not null"`
+	WorkspaceUuid string `gorm:"not null"`
+	Name          string `gorm:"not null"`
+	Url           string
+	Created       *time.Time
+	Updated       *time.Time
+	CreatedBy     string
+	UpdatedBy     string
+}
+
+func (baselineWorkspaceRepositories) TableName() string { return "workspace_repositories" }
+
+type baselineWorkspaceCodeGraph struct {
+	ID            uint
+	Uuid          string `gorm:"not null"`
+	WorkspaceUuid string `gorm:"not null"`
+	Name          string `gorm:"not null"`
+	Url           string
+	SecretAlias   string
+	Created       *time.Time
+	Updated       *time.Time
+	CreatedBy     string
+	UpdatedBy     string
+}
+
+func (baselineWorkspaceCodeGraph) TableName() string { return "workspace_code_graphs" }
+
+type baselineWorkspaceFeatures struct {
+	ID                     uint
+	Uuid                   string `gorm:"unique;not null"`
+	WorkspaceUuid          string `gorm:"not null"`
+	Name                   string `gorm:"not null"`
+	Brief                  string
+	Requirements           string
+	Architecture           string
+	Url                    string
+	Priority               int
+	Created                *time.Time
+	Updated                *time.Time
+	CreatedBy              string
+	UpdatedBy              string
+	BountiesCountCompleted int           `gorm:"-"`
+	BountiesCountAssigned  int           `gorm:"-"`
+	BountiesCountOpen      int           `gorm:"-"`
+	FeatStatus             FeatureStatus `gorm:"type:varchar(20);default:'active';not null"`
+}
+
+func (baselineWorkspaceFeatures) TableName() string { return "workspace_features" }
+
+type baselineFeaturePhase struct {
+	Uuid         string `gorm:"primary_key"`
+	FeatureUuid  string
+	Name         string
+	Priority     int
+	PhasePurpose string `gorm:"default:null"`
+	PhaseOutcome string `gorm:"default:null"`
+	PhaseScope   string `gorm:"default:null"`
+	PhaseDesign  string `gorm:"default:null"`
+	Created      *time.Time
+	Updated      *time.Time
+	CreatedBy    string
+	UpdatedBy    string
+}
+
+func (baselineFeaturePhase) TableName() string { return "feature_phases" }
+
+type baselineFeatureStory struct {
+	ID          uint
+	Uuid        string
+	FeatureUuid string
+	Description string
+	Priority    int
+	Created     *time.Time
+	Updated     *time.Time
+	CreatedBy   string
+	UpdatedBy   string
+}
+
+func (baselineFeatureStory) TableName() string { return "feature_stories" }
+
+type baselineWfRequest struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	RequestID    string `gorm:"unique;not null"`
+	WorkflowID   string `gorm:"index"`
+	Source       string `gorm:"index"`
+	Action       string `gorm:"index"`
+	Status       WfRequestStatus
+	ProjectID    string
+	RequestData  PropertyMap `gorm:"type:jsonb"`
+	ResponseData PropertyMap `gorm:"type:jsonb"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (baselineWfRequest) TableName() string { return "wf_requests" }
+
+type baselineWfProcessingMap struct {
+	ID                 uint   `gorm:"primaryKey;autoIncrement"`
+	Type               string `gorm:"index;not null"`
+	ProcessKey         string `gorm:"index;not null"`
+	RequiresProcessing bool   `gorm:"default:false"`
+	HandlerFunc        string
+	Config             PropertyMap `gorm:"type:jsonb"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+func (baselineWfProcessingMap) TableName() string { return "wf_processing_maps" }
+
+type baselineTickets struct {
+	UUID          uuid.UUID                 `gorm:"primaryKey;type:uuid"`
+	TicketGroup   *uuid.UUID                `gorm:"type:uuid;index:group_index"`
+	WorkspaceUuid string                    `gorm:"type:varchar(255);index:workspace_index"`
+	FeatureUUID   string                    `gorm:"type:varchar(255);index:composite_index;default:null"`
+	Features      baselineWorkspaceFeatures `gorm:"foreignKey:FeatureUUID;references:Uuid;constraint:OnDelete:SET NULL"`
+	PhaseUUID     string                    `gorm:"type:varchar(255);index:phase_index;default:null"`
+	FeaturePhase  baselineFeaturePhase      `gorm:"foreignKey:PhaseUUID;references:Uuid;constraint:OnDelete:SET NULL"`
+	Name          string                    `gorm:"type:varchar(255)"`
+	Sequence      int                       `gorm:"type:integer;index:composite_index;default:0"`
+	Dependency    []int                     `gorm:"type:integer[]"`
+	Description   string                    `gorm:"type:text"`
+	Status        TicketStatus              `gorm:"type:varchar(50);default:'DRAFT'"`
+	Version       int                       `gorm:"type:integer;default:0"`
+	Author        *Author                   `gorm:"type:varchar(50)"`
+	AuthorID      *string                   `gorm:"type:varchar(255)"`
+	Amount        *int64                    `gorm:"type:bigint;default:null"`
+	Category      *Category                 `gorm:"type:varchar(50);default:null"`
+	Mode          string
+	CreatedAt     time.Time `gorm:"type:timestamp;default:current_timestamp"`
+	UpdatedAt     time.Time `gorm:"type:timestamp;default:current_timestamp"`
+}
+
+func (baselineTickets) TableName() string { return "tickets" }
+
+type baselineChatMessage struct {
+	ID          string `gorm:"primaryKey"`
+	ChatID      string `gorm:"index"`
+	Message     string
+	PDFURL      string
+	Role        ChatRole
+	Timestamp   time.Time
+	ContextTags ContextTags `gorm:"type:jsonb"`
+	Status      ChatMessageStatus
+	Source      ChatSource
+}
+
+func (baselineChatMessage) TableName() string { return "chat_messages" }
+
+type baselineChat struct {
+	ID          string `gorm:"primaryKey"`
+	WorkspaceID string `gorm:"index"`
+	Title       string
+	Status      ChatStatus `gorm:"default:active"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (baselineChat) TableName() string { return "chats" }
+
+type baselineProofOfWork struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	BountyID    uint
+	Description string            `gorm:"type:text;not null"`
+	Status      ProofOfWorkStatus `gorm:"type:varchar(20);default:'New'"`
+	CreatedAt   time.Time         `gorm:"type:timestamp;default:current_timestamp"`
+	SubmittedAt time.Time         `gorm:"type:timestamp;default:current_timestamp"`
+}
+
+func (baselineProofOfWork) TableName() string { return "proof_of_works" }
+
+type baselineBountyTiming struct {
+	ID                      uuid.UUID `gorm:"type:uuid;primaryKey"`
+	BountyID                uint      `gorm:"not null"`
+	TotalWorkTimeSeconds    int       `gorm:"default:0"`
+	TotalDurationSeconds    int       `gorm:"default:0"`
+	TotalAttempts           int       `gorm:"default:0"`
+	FirstAssignedAt         *time.Time
+	LastPoWAt               *time.Time
+	ClosedAt                *time.Time
+	IsPaused                bool `gorm:"default:false"`
+	LastPausedAt            *time.Time
+	AccumulatedPauseSeconds int       `gorm:"default:0"`
+	CreatedAt               time.Time `gorm:"default:current_timestamp"`
+	UpdatedAt               time.Time `gorm:"default:current_timestamp"`
+}
+
+func (baselineBountyTiming) TableName() string { return "bounty_timings" }
+
+type baselineFeatureFlag struct {
+	UUID        uuid.UUID          `gorm:"type:uuid;primaryKey"`
+	Name        string             `gorm:"type:varchar(255);unique;not null"`
+	Description string             `gorm:"type:text"`
+	Enabled     bool               `gorm:"type:boolean;default:false"`
+	Endpoints   []baselineEndpoint `gorm:"foreignKey:FeatureFlagUUID"`
+	CreatedAt   time.Time          `gorm:"type:timestamp;default:current_timestamp"`
+	UpdatedAt   time.Time          `gorm:"type:timestamp;default:current_timestamp"`
+}
+
+func (baselineFeatureFlag) TableName() string { return "feature_flags" }
+
+type baselineEndpoint struct {
+	UUID            uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Path            string    `gorm:"type:varchar(255);not null"`
+	FeatureFlagUUID uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt       time.Time `gorm:"type:timestamp;default:current_timestamp"`
+	UpdatedAt       time.Time `gorm:"type:timestamp;default:current_timestamp"`
+}
+
+func (baselineEndpoint) TableName() string { return "endpoints" }
+
+type baselineFeaturedBounty struct {
+	BountyID  string `gorm:"uniqueIndex; unique; not null"`
+	URL       string `gorm:"not null"`
+	AddedAt   int64
+	Title     string
+	CreatedAt time.Time `gorm:"default:current_timestamp"`
+	UpdatedAt time.Time `gorm:"default:current_timestamp"`
+}
+
+func (baselineFeaturedBounty) TableName() string { return "featured_bounties" }
+
+type baselineNotification struct {
+	ID        uint               `gorm:"primaryKey;autoIncrement"`
+	UUID      string             `gorm:"type:uuid;uniqueIndex;not null"`
+	Event     string             `gorm:"type:varchar(50);not null"`
+	PubKey    string             `gorm:"type:varchar(100);not null;index"`
+	Content   string             `gorm:"type:text;not null"`
+	Retries   int                `gorm:"default:0"`
+	Status    NotificationStatus `gorm:"type:varchar(20);default:'PENDING'"`
+	CreatedAt *time.Time         `gorm:"default:current_timestamp"`
+	UpdatedAt *time.Time         `gorm:"default:current_timestamp"`
+}
+
+func (baselineNotification) TableName() string { return "notifications" }
+
+type baselineTextSnippet struct {
+	ID            uint      `gorm:"primarykey"`
+	WorkspaceUUID string    `gorm:"type:varchar(255);not null;index"`
+	Title         string    `gorm:"type:varchar(255);not null"`
+	Snippet       string    `gorm:"type:text;not null"`
+	DateCreated   time.Time `gorm:"autoCreateTime"`
+	LastEdited    time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineTextSnippet) TableName() string { return "text_snippets" }
+
+type baselineFileAsset struct {
+	ID             uint `gorm:"primaryKey;autoIncrement"`
+	OriginFilename string
+	FileHash       string `gorm:"index"`
+	UploadFilename string `gorm:"uniqueIndex"`
+	UploadTime     time.Time
+	LastReferenced time.Time
+	FileSize       int64
+	MimeType       string
+	Status         FileStatus `gorm:"type:varchar(20);default:'active'"`
+	UploadedBy     string
+	StoragePath    string
+	WorkspaceID    string `gorm:"index"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      *time.Time `gorm:"index"`
+}
+
+func (baselineFileAsset) TableName() string { return "file_assets" }
+
+type baselineTicketPlan struct {
+	UUID          uuid.UUID                 `gorm:"primaryKey;type:uuid"`
+	WorkspaceUuid string                    `gorm:"type:varchar(255);index:workspace_index"`
+	FeatureUUID   string                    `gorm:"type:varchar(255);index:composite_index;default:null"`
+	Features      baselineWorkspaceFeatures `gorm:"foreignKey:FeatureUUID;references:Uuid;constraint:OnDelete:SET NULL"`
+	PhaseUUID     string                    `gorm:"type:varchar(255);index:phase_index;default:null"`
+	FeaturePhase  baselineFeaturePhase      `gorm:"foreignKey:PhaseUUID;references:Uuid;constraint:OnDelete:SET NULL"`
+	Name          string                    `gorm:"type:varchar(255);not null"`
+	Description   string                    `gorm:"type:text"`
+	TicketGroups  pq.StringArray            `gorm:"type:uuid[];not null;default:'{}'"`
+	Status        PlanStatus                `gorm:"type:varchar(50);default:'DRAFT'"`
+	Version       int                       `gorm:"type:integer;default:0"`
+	CreatedBy     string                    `gorm:"type:varchar(255)"`
+	UpdatedBy     string                    `gorm:"type:varchar(255)"`
+	CreatedAt     time.Time                 `gorm:"type:timestamp;default:current_timestamp"`
+	UpdatedAt     time.Time                 `gorm:"type:timestamp;default:current_timestamp"`
+}
+
+func (baselineTicketPlan) TableName() string { return "ticket_plans" }
+
+type baselineActivity struct {
+	ID          uuid.UUID      `gorm:"primaryKey;type:uuid"`
+	ThreadID    uuid.UUID      `gorm:"type:uuid;index:thread_index"`
+	Title       string         `gorm:"type:varchar(200)"`
+	Sequence    int            `gorm:"type:integer;not null"`
+	ContentType ContentType    `gorm:"type:varchar(50);not null"`
+	Content     string         `gorm:"type:text;not null;check:content,length(content) <= 10000"`
+	Workspace   string         `gorm:"type:varchar(255);index:workspace_index"`
+	FeatureUUID string         `gorm:"type:varchar(255);index:feature_index"`
+	PhaseUUID   string         `gorm:"type:varchar(255);index:phase_index"`
+	Feedback    string         `gorm:"type:text"`
+	Actions     pq.StringArray `gorm:"type:text[];default:'{}'"`
+	Questions   pq.StringArray `gorm:"type:text[];default:'{}'"`
+	TimeCreated time.Time      `gorm:"type:timestamp;default:current_timestamp"`
+	TimeUpdated time.Time      `gorm:"type:timestamp;default:current_timestamp"`
+	Status      string         `gorm:"type:varchar(20);default:'active'"`
+	Author      AuthorType     `gorm:"type:varchar(10);not null"`
+	AuthorRef   string         `gorm:"type:varchar(255);not null"`
+}
+
+func (baselineActivity) TableName() string { return "activities" }
+
+type baselineArtifact struct {
+	ID        uuid.UUID    `gorm:"type:uuid;primaryKey"`
+	MessageID string       `gorm:"index"`
+	Type      ArtifactType `gorm:"type:varchar(20);not null"`
+	Content   PropertyMap  `gorm:"type:jsonb;not null;default:'{}'::jsonb"`
+	CreatedAt time.Time    `gorm:"type:timestamp;default:current_timestamp"`
+	UpdatedAt time.Time    `gorm:"type:timestamp;default:current_timestamp"`
+}
+
+func (baselineArtifact) TableName() string { return "artifacts" }
+
+type baselineFeatureCall struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	WorkspaceID string         `gorm:"type:varchar(255);uniqueIndex;not null"`
+	URL         string         `gorm:"type:text"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (baselineFeatureCall) TableName() string { return "feature_calls" }
+
+type baselineChatWorkflow struct {
+	ID          uint   `gorm:"primaryKey"`
+	WorkspaceID string `gorm:"index;not null"`
+	URL         string `gorm:"type:text;not null"`
+	StackworkID string `gorm:"column:stackwork_id"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (baselineChatWorkflow) TableName() string { return "chat_workflows" }
+
+type baselineSkill struct {
+	ID          uuid.UUID      `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name        string         `gorm:"type:varchar(255);not null"`
+	Tagline     string         `gorm:"type:varchar(255)"`
+	Description string         `gorm:"type:text"`
+	IconURL     string         `gorm:"type:varchar(255)"`
+	OwnerPubkey string         `gorm:"type:varchar(255);not null"`
+	ChargeModel ChargeModel    `gorm:"type:varchar(50);not null"`
+	Labels      pq.StringArray `gorm:"type:text[]"`
+	Status      SkillStatus    `gorm:"type:varchar(50);not null;default:'Draft'"`
+	CreatedAt   time.Time      `gorm:"type:timestamp;default:current_timestamp"`
+	UpdatedAt   time.Time      `gorm:"type:timestamp;default:current_timestamp"`
+}
+
+func (baselineSkill) TableName() string { return "skills" }
+
+type baselineSkillInstall struct {
+	ID                 uuid.UUID     `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	SkillID            uuid.UUID     `gorm:"type:uuid;not null;index:skill_index"`
+	Skill              baselineSkill `gorm:"foreignKey:SkillID;references:ID;constraint:OnDelete:CASCADE"`
+	Client             ClientType    `gorm:"type:varchar(50);not null"`
+	InstallDescription string        `gorm:"type:text"`
+	InstallFile        string        `gorm:"type:varchar(255)"`
+	CreatedAt          time.Time     `gorm:"type:timestamp;default:current_timestamp"`
+	UpdatedAt          time.Time     `gorm:"type:timestamp;default:current_timestamp"`
+}
+
+func (baselineSkillInstall) TableName() string { return "skill_installs" }
+
+type baselineSSEMessageLog struct {
+	ID        uuid.UUID        `gorm:"primaryKey;type:uuid"`
+	CreatedAt time.Time        `gorm:"type:timestamp;default:current_timestamp"`
+	UpdatedAt time.Time        `gorm:"type:timestamp;default:current_timestamp"`
+	Event     PropertyMap      `gorm:"type:jsonb;not null;default:'{}'::jsonb"`
+	ChatID    string           `gorm:"index;not null"`
+	From      string           `gorm:"not null"`
+	To        string           `gorm:"not null"`
+	Status    SSEMessageStatus `gorm:"type:varchar(10);default:'new'"`
+}
+
+func (baselineSSEMessageLog) TableName() string { return "sse_message_logs" }
+
+type baselineCodeSpaceMap struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	WorkspaceID  string `gorm:"index"`
+	CodeSpaceURL string
+	UserPubkey   string `gorm:"index"`
+}
+
+func (baselineCodeSpaceMap) TableName() string { return "code_space_maps" }
+
+type baselineBountyStake struct {
+	ID           uuid.UUID   `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	BountyID     uint        `gorm:"index;not null"`
+	HunterPubKey string      `gorm:"type:varchar(255);not null"`
+	Amount       int64       `gorm:"not null"`
+	Status       StakeStatus `gorm:"type:varchar(20);default:'NEW'"`
+	Invoice      string      `gorm:"type:text"`
+	StakeReceipt string      `gorm:"type:text"`
+	StakeReturn  string      `gorm:"type:text"`
+	Note         string      `gorm:"type:text"`
+	CreatedAt    time.Time   `gorm:"autoCreateTime"`
+	StakedAt     *time.Time
+	ReturnedAt   *time.Time
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineBountyStake) TableName() string { return "bounty_stakes" }
+
+type baselineChatWorkflowStatus struct {
+	UUID      uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ChatID    string    `gorm:"index;not null"`
+	Status    string    `gorm:"type:varchar(255);not null"`
+	Message   string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:current_timestamp"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:current_timestamp"`
+}
+
+func (baselineChatWorkflowStatus) TableName() string { return "chat_workflow_statuses" }
+
+// migrateLegacyTables creates the bounty, budget and workspace tables, or
+// renames them from their organization era names on older databases.
+func migrateLegacyTables(tx *gorm.DB) {
+	if tx.Migrator().HasTable("bounty") {
+		if !tx.Migrator().HasColumn(baselineBounty{}, "workspace_uuid") {
+			tx.AutoMigrate(&baselineBounty{})
+		} else {
+			tx.AutoMigrate(&baselineNewBounty{})
+		}
+	} else {
+		tx.AutoMigrate(&baselineNewBounty{})
+	}
+	if !tx.Migrator().HasTable("budget_histories") {
+		if !tx.Migrator().HasColumn(baselineBudgetHistory{}, "workspace_uuid") {
+			tx.AutoMigrate(&baselineBudgetHistory{})
+		}
+	}
+	if !tx.Migrator().HasTable("payment_histories") {
+		if !tx.Migrator().HasColumn(baselinePaymentHistory{}, "workspace_uuid") {
+			tx.AutoMigrate(&baselinePaymentHistory{})
+		} else {
+			tx.AutoMigrate(&baselineNewPaymentHistory{})
+		}
+	} else {
+		tx.AutoMigrate(&baselineNewPaymentHistory{})
+	}
+	if !tx.Migrator().HasTable("invoice_list") {
+		if !tx.Migrator().HasColumn(baselineInvoiceList{}, "workspace_uuid") {
+			tx.AutoMigrate(&baselineInvoiceList{})
+		} else {
+			tx.AutoMigrate(&baselineNewInvoiceList{})
+		}
+	}
+	if !tx.Migrator().HasTable("bounty_budgets") {
+		if !tx.Migrator().HasColumn(baselineBountyBudget{}, "workspace_uuid") {
+			tx.AutoMigrate(&baselineBountyBudget{})
+		} else {
+			tx.AutoMigrate(&baselineNewBountyBudget{})
+		}
+	}
+	if !tx.Migrator().HasTable("workspace_user_roles") {
+		if !tx.Migrator().HasColumn(baselineUserRoles{}, "workspace_uuid") {
+			tx.AutoMigrate(&baselineUserRoles{})
+		}
+	} else {
+		tx.AutoMigrate(&baselineWorkspaceUserRoles{})
+	}
+	if !tx.Migrator().HasTable("workspaces") {
+		tx.AutoMigrate(&baselineOrganization{})
+	} else {
+		tx.AutoMigrate(&baselineWorkspace{})
+	}
+	if !tx.Migrator().HasTable("workspace_users") {
+		tx.AutoMigrate(&baselineOrganizationUsers{})
+	} else {
+		tx.AutoMigrate(&baselineWorkspaceUsers{})
+	}
+
+	if tx.Migrator().HasTable(&baselineOrganization{}) && !tx.Migrator().HasTable("workspaces") {
+		tx.Migrator().RenameTable(&baselineOrganization{}, "workspaces")
+	}
+
+	if tx.Migrator().HasTable(&baselineOrganizationUsers{}) && !tx.Migrator().HasTable("workspace_users") {
+		if tx.Migrator().HasColumn(&baselineOrganizationUsers{}, "org_uuid") {
+			tx.Migrator().RenameColumn(&baselineOrganizationUsers{}, "org_uuid", "workspace_uuid")
+		}
+		tx.Migrator().RenameTable(&baselineOrganizationUsers{}, "workspace_users")
+	}
+
+	if tx.Migrator().HasTable(&baselineUserRoles{}) && !tx.Migrator().HasTable("workspace_user_roles") {
+		if tx.Migrator().HasColumn(&baselineUserRoles{}, "org_uuid") {
+			tx.Migrator().RenameColumn(&baselineUserRoles{}, "org_uuid", "workspace_uuid")
+		}
+
+		tx.Migrator().RenameTable(&baselineUserRoles{}, "workspace_user_roles")
+	}
+
+	if tx.Migrator().HasTable(&baselineBounty{}) {
+		if tx.Migrator().HasColumn(&baselineBounty{}, "org_uuid") {
+			tx.Migrator().RenameColumn(&baselineBounty{}, "org_uuid", "workspace_uuid")
+		}
+	}
+
+	if tx.Migrator().HasTable(&baselineBountyBudget{}) {
+		if tx.Migrator().HasColumn(&baselineBountyBudget{}, "org_uuid") {
+			tx.Migrator().RenameColumn(&baselineBountyBudget{}, "org_uuid", "workspace_uuid")
+		}
+	}
+
+	if tx.Migrator().HasTable(&baselineBudgetHistory{}) {
+		if tx.Migrator().HasColumn(&baselineBudgetHistory{}, "org_uuid") {
+			tx.Migrator().RenameColumn(&baselineBudgetHistory{}, "org_uuid", "workspace_uuid")
+		}
+	}
+
+	if tx.Migrator().HasTable(&baselinePaymentHistory{}) {
+		if tx.Migrator().HasColumn(&baselinePaymentHistory{}, "org_uuid") {
+			tx.Migrator().RenameColumn(&baselinePaymentHistory{}, "org_uuid", "workspace_uuid")
+		}
+	}
+
+	if tx.Migrator().HasTable(&baselineInvoiceList{}) {
+		if tx.Migrator().HasColumn(&baselineInvoiceList{}, "org_uuid") {
+			tx.Migrator().RenameColumn(&baselineInvoiceList{}, "org_uuid", "workspace_uuid")
+		}
+	}
+}
+
+type baselineBounty struct {
+	ID                      uint
+	OwnerID                 string
+	Paid                    bool
+	Show                    bool `gorm:"default:false"`
+	Completed               bool `gorm:"default:false"`
+	Type                    string
+	Award                   string
+	AssignedHours           uint8
+	BountyExpires           string
+	CommitmentFee           uint64
+	Price                   uint
+	Title                   string
+	Tribe                   string
+	Assignee                string
+	TicketUrl               string
+	OrgUuid                 string
+	Description             string
+	WantedType              string
+	Deliverables            string
+	GithubDescription       bool
+	OneSentenceSummary      string
+	EstimatedSessionLength  string
+	EstimatedCompletionDate string
+	Created                 int64
+	Updated                 *time.Time
+	AssignedDate            *time.Time
+	CompletionDate          *time.Time
+	MarkAsPaidDate          *time.Time
+	PaidDate                *time.Time
+	CodingLanguages         pq.StringArray `gorm:"type:text[];not null default:'[]'"`
+	PhaseUuid               *string
+	PhasePriority           *int
+	PaymentPending          bool                   `gorm:"default:false"`
+	PaymentFailed           bool                   `gorm:"default:false"`
+	AccessRestriction       *AccessRestrictionType `gorm:"type:varchar(20);default:null"`
+	UnlockCode              *string                `gorm:"type:varchar(6);default:null;index"`
+	IsStakable              bool                   `gorm:"default:false"`
+	StakeMin                int64                  `gorm:"default:0"`
+	MaxStakers              int                    `gorm:"default:1"`
+	CurrentStakers          int                    `gorm:"default:0"`
+	Stakes                  []baselineBountyStake  `gorm:"foreignKey:BountyID"`
+}
+
+func (baselineBounty) TableName() string { return "bounty" }
+
+type baselineNewBounty struct {
+	ID                      uint
+	OwnerID                 string
+	Paid                    bool
+	Show                    bool `gorm:"default:false"`
+	Completed               bool `gorm:"default:false"`
+	Type                    string
+	Award                   string
+	AssignedHours           uint8
+	BountyExpires           string
+	CommitmentFee           uint64
+	Price                   uint
+	Title                   string
+	Tribe                   string
+	Assignee                string
+	TicketUrl               string
+	OrgUuid                 string `gorm:"-"`
+	WorkspaceUuid           string
+	FeatureUuid             string
+	Description             string
+	WantedType              string
+	Deliverables            string
+	GithubDescription       bool
+	OneSentenceSummary      string
+	EstimatedSessionLength  string
+	EstimatedCompletionDate string
+	Created                 int64
+	Updated                 *time.Time
+	AssignedDate            *time.Time
+	CompletionDate          *time.Time
+	MarkAsPaidDate          *time.Time
+	PaidDate                *time.Time
+	CodingLanguages         pq.StringArray `gorm:"type:text[];not null default:'[]'"`
+	PhaseUuid               string
+	PhasePriority           int
+	PaymentPending          bool                   `gorm:"default:false"`
+	PaymentFailed           bool                   `gorm:"default:false"`
+	ProofOfWorkCount        int                    `gorm:"type:integer;default:0;not null"`
+	AccessRestriction       *AccessRestrictionType `gorm:"type:varchar(20);default:null"`
+	UnlockCode              *string                `gorm:"type:varchar(6);default:null;index"`
+	IsStakable              bool                   `gorm:"default:false"`
+	StakeMin                int64                  `gorm:"default:0"`
+	MaxStakers              int                    `gorm:"default:1"`
+	CurrentStakers          int                    `gorm:"default:0"`
+	Stakes                  []baselineBountyStake  `gorm:"foreignKey:BountyID"`
+}
+
+func (baselineNewBounty) TableName() string { return "bounty" }
+
+type baselineBudgetHistory struct {
+	ID           uint
+	OrgUuid      string
+	Amount       uint
+	SenderPubKey string
+	Created      *time.Time
+	Updated      *time.Time
+	Status       bool
+	PaymentType  PaymentType
+}
+
+func (baselineBudgetHistory) TableName() string { return "budget_histories" }
+
+type baselinePaymentHistory struct {
+	ID             uint
+	Amount         uint
+	BountyId       uint
+	PaymentType    PaymentType
+	OrgUuid        string
+	SenderPubKey   string
+	ReceiverPubKey string
+	Tag            string
+	PaymentStatus  string
+	Error          string
+	Created        *time.Time
+	Updated        *time.Time
+	Status         bool
+}
+
+func (baselinePaymentHistory) TableName() string { return "payment_histories" }
+
+type baselineNewPaymentHistory struct {
+	ID             uint
+	Amount         uint
+	BountyId       uint
+	PaymentType    PaymentType
+	OrgUuid        string `gorm:"-"`
+	WorkspaceUuid  string
+	SenderPubKey   string
+	ReceiverPubKey string
+	Tag            string
+	PaymentStatus  string
+	Error          string
+	Created        *time.Time
+	Updated        *time.Time
+	Status         bool
+}
+
+func (baselineNewPaymentHistory) TableName() string { return "payment_histories" }
+
+type baselineInvoiceList struct {
+	ID             uint
+	PaymentRequest string
+	Status         bool
+	Type           InvoiceType
+	OwnerPubkey    string
+	OrgUuid        string
+	Created        *time.Time
+	Updated        *time.Time
+}
+
+func (baselineInvoiceList) TableName() string { return "invoice_lists" }
+
+type baselineNewInvoiceList struct {
+	ID             uint
+	PaymentRequest string
+	Status         bool
+	Type           InvoiceType
+	OwnerPubkey    string
+	OrgUuid        string `gorm:"-"`
+	WorkspaceUuid  string
+	Created        *time.Time
+	Updated        *time.Time
+}
+
+func (baselineNewInvoiceList) TableName() string { return "invoice_lists" }
+
+type baselineBountyBudget struct {
+	ID            uint
+	OrgUuid       string
+	WorkspaceUuid string `gorm:"-"`
+	TotalBudget   uint
+	Created       *time.Time
+	Updated       *time.Time
+}
+
+func (baselineBountyBudget) TableName() string { return "bounty_budgets" }
+
+type baselineNewBountyBudget struct {
+	ID            uint
+	OrgUuid       string `gorm:"-"`
+	WorkspaceUuid string
+	TotalBudget   uint
+	Created       *time.Time
+	Updated       *time.Time
+}
+
+func (baselineNewBountyBudget) TableName() string { return "bounty_budgets" }
+
+type baselineUserRoles struct {
+	Role        string
+	OwnerPubKey string
+	OrgUuid     string
+	Created     *time.Time
+}
+
+func (baselineUserRoles) TableName() string { return "user_roles" }
+
+type baselineWorkspaceUserRoles struct {
+	Role          string
+	OwnerPubKey   string
+	OrgUuid       string `gorm:"-"`
+	WorkspaceUuid string
+	Created       *time.Time
+}
+
+func (baselineWorkspaceUserRoles) TableName() string { return "workspace_user_roles" }
+
+type baselineOrganization struct {
+	ID           uint
+	Uuid         string
+	Name         string `gorm:"unique;not null"`
+	OwnerPubKey  string
+	Img          string
+	Created      *time.Time
+	Updated      *time.Time
+	Show         bool
+	Deleted      bool `gorm:"default:false"`
+	BountyCount  int64
+	Budget       uint
+	Website      string
+	Github       string
+	Description  string
+	Mission      string
+	Tactics      string
+	SchematicUrl string
+	SchematicImg string
+}
+
+func (baselineOrganization) TableName() string { return "organizations" }
+
+type baselineWorkspace struct {
+	ID           uint
+	Uuid         string
+	Name         string `gorm:"unique;not null"`
+	OwnerPubKey  string
+	Img          string
+	Created      *time.Time
+	Updated      *time.Time
+	Show         bool
+	Deleted      bool `gorm:"default:false"`
+	BountyCount  int64
+	Budget       uint
+	Website      string
+	Github       string
+	Description  string
+	Mission      string
+	Tactics      string
+	SchematicUrl string
+	SchematicImg string
+}
+
+func (baselineWorkspace) TableName() string { return "workspaces" }
+
+type baselineOrganizationUsers struct {
+	ID          uint
+	OwnerPubKey string
+	OrgUuid     string
+	Created     *time.Time
+	Updated     *time.Time
+}
+
+func (baselineOrganizationUsers) TableName() string { return "organization_users" }
+
+type baselineWorkspaceUsers struct {
+	ID            uint
+	OwnerPubKey   string
+	OrgUuid       string `gorm:"-"`
+	WorkspaceUuid string
+	Created       *time.Time
+	Updated       *time.Time
+}
+
+func (baselineWorkspaceUsers) TableName() string { return "workspace_users" }
@@ -0,0 +1,217 @@
+package db
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func TestParseMigrationFilename(t *testing.T) {
+	tests := []struct {
+		name              string
+		filename          string
+		expectedVersion   int
+		expectedName      string
+		expectedDirection string
+		expectError       bool
+	}{
+		{
+			name:              "Up Migration",
+			filename:          "0002_backfill_ticket_groups.up.sql",
+			expectedVersion:   2,
+			expectedName:      "backfill_ticket_groups",
+			expectedDirection: "up",
+		},
+		{
+			name:              "Down Migration",
+			filename:          "0010_add_index.down.sql",
+			expectedVersion:   10,
+			expectedName:      "add_index",
+			expectedDirection: "down",
+		},
+		{
+			name:        "Missing Direction",
+			filename:    "0003_backfill.sql",
+			expectError: true,
+		},
+		{
+			name:        "Missing Version",
+			filename:    "backfill.up.sql",
+			expectError: true,
+		},
+		{
+			name:        "Zero Version",
+			filename:    "0000_backfill.up.sql",
+			expectError: true,
+		},
+		{
+			name:        "Uppercase Name",
+			filename:    "0005_Backfill.up.sql",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, name, direction, err := parseMigrationFilename(tt.filename)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedVersion, version)
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedDirection, direction)
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "baseline", migrations[0].Name)
+
+	seen := map[int]bool{}
+	for i, m := range migrations {
+		assert.False(t, seen[m.Version], "duplicate version %d", m.Version)
+		seen[m.Version] = true
+		assert.NotNil(t, m.Up, "migration %d has no up", m.Version)
+		assert.NotNil(t, m.Down, "migration %d has no down", m.Version)
+		if i > 0 {
+			assert.Greater(t, m.Version, migrations[i-1].Version)
+		}
+	}
+}
+
+func TestBaselineModelsKeepTheirTables(t *testing.T) {
+	cache := &sync.Map{}
+	tables := map[string]bool{}
+	for _, model := range baselineModels {
+		parsed, err := schema.Parse(model, cache, schema.NamingStrategy{})
+		assert.NoError(t, err, "%T", model)
+		assert.False(t, strings.HasPrefix(parsed.Table, "baseline"), "%T maps to %s", model, parsed.Table)
+		assert.False(t, tables[parsed.Table], "%s is migrated twice", parsed.Table)
+		tables[parsed.Table] = true
+	}
+
+	assert.True(t, tables["people"])
+	assert.True(t, tables["tickets"])
+	assert.True(t, tables["connectioncodes"])
+}
+
+func TestIsEmptySQL(t *testing.T) {
+	assert.True(t, isEmptySQL(""))
+	assert.True(t, isEmptySQL("-- nothing to undo\n\n"))
+	assert.False(t, isEmptySQL("-- drop it\nDROP TABLE foo;"))
+}
+
+func TestPendingMigrations(t *testing.T) {
+	noop := func(tx *gorm.DB) error { return nil }
+	migrations := []Migration{
+		{Version: 1, Name: "one", Up: noop, Down: noop},
+		{Version: 2, Name: "two", Up: noop, Down: noop},
+		{Version: 3, Name: "three", Up: noop, Down: noop},
+	}
+
+	tests := []struct {
+		name     string
+		applied  map[int]SchemaMigration
+		expected []int
+	}{
+		{
+			name:     "Nothing Applied",
+			applied:  map[int]SchemaMigration{},
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:     "Partially Applied",
+			applied:  map[int]SchemaMigration{1: {Version: 1}},
+			expected: []int{2, 3},
+		},
+		{
+			name:     "Gap In Applied Versions",
+			applied:  map[int]SchemaMigration{1: {Version: 1}, 3: {Version: 3}},
+			expected: []int{2},
+		},
+		{
+			name:     "All Applied",
+			applied:  map[int]SchemaMigration{1: {Version: 1}, 2: {Version: 2}, 3: {Version: 3}},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending := pendingMigrations(migrations, tt.applied)
+			var versions []int
+			for _, m := range pending {
+				versions = append(versions, m.Version)
+			}
+			assert.Equal(t, tt.expected, versions)
+		})
+	}
+}
+
+func TestMigratorUpDown(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	TestDB.db.Exec("DROP TABLE IF EXISTS test_migration_items")
+	TestDB.db.Exec("DELETE FROM schema_migrations WHERE version >= 9000")
+
+	migrations := []Migration{
+		{
+			Version: 9000,
+			Name:    "create_test_migration_items",
+			Up:      sqlMigration("CREATE TABLE test_migration_items (id SERIAL PRIMARY KEY, name TEXT);"),
+			Down:    sqlMigration("DROP TABLE test_migration_items;"),
+		},
+		{
+			Version: 9001,
+			Name:    "seed_test_migration_items",
+			Up:      sqlMigration("INSERT INTO test_migration_items (name) VALUES ('first');"),
+			Down:    sqlMigration("DELETE FROM test_migration_items;"),
+		},
+	}
+	migrator := NewMigrator(TestDB.db, migrations)
+
+	applied, err := migrator.Up(1)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.True(t, TestDB.db.Migrator().HasTable("test_migration_items"))
+
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+
+	applied, err = migrator.Up(0)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+
+	var count int64
+	TestDB.db.Table("test_migration_items").Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	redone, err := migrator.Redo()
+	assert.NoError(t, err)
+	assert.Equal(t, 9001, redone.Version)
+	TestDB.db.Table("test_migration_items").Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	reverted, err := migrator.Down(2)
+	assert.NoError(t, err)
+	assert.Len(t, reverted, 2)
+	assert.Equal(t, 9001, reverted[0].Version)
+	assert.False(t, TestDB.db.Migrator().HasTable("test_migration_items"))
+
+	_, err = migrator.Down(0)
+	assert.Error(t, err)
+}
@@ -0,0 +1 @@
+-- Data migration: ticket groups are not cleared on rollback.
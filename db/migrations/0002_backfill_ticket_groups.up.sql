@@ -0,0 +1,8 @@
+-- Every ticket is the first version of its own group unless it already
+-- belongs to one. Replaces the startup ProcessUpdateTicketsWithoutGroup pass.
+UPDATE tickets
+SET ticket_group = uuid,
+    author_id = COALESCE(author_id, '12345'),
+    author = COALESCE(author, 'HUMAN')
+WHERE ticket_group IS NULL
+   OR ticket_group = '00000000-0000-0000-0000-000000000000';
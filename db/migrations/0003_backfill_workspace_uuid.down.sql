@@ -0,0 +1 @@
+-- Data migration: workspace_uuid values are kept on rollback.
@@ -0,0 +1,32 @@
+-- Copy org_uuid into workspace_uuid on tables that still carry both columns
+-- from the organization to workspace rename.
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY[
+        'bounty',
+        'bounty_budgets',
+        'budget_histories',
+        'payment_histories',
+        'invoice_lists',
+        'workspace_users',
+        'workspace_user_roles'
+    ]
+    LOOP
+        IF EXISTS (
+            SELECT 1 FROM information_schema.columns
+            WHERE table_name = tbl AND column_name = 'org_uuid'
+        ) AND EXISTS (
+            SELECT 1 FROM information_schema.columns
+            WHERE table_name = tbl AND column_name = 'workspace_uuid'
+        ) THEN
+            EXECUTE format(
+                'UPDATE %I SET workspace_uuid = org_uuid
+                 WHERE COALESCE(workspace_uuid, '''') = ''''
+                   AND COALESCE(org_uuid, '''') <> ''''',
+                tbl
+            );
+        END IF;
+    END LOOP;
+END $$;
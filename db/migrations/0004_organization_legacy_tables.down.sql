@@ -0,0 +1 @@
+-- Data migration: copied workspace rows are kept on rollback.
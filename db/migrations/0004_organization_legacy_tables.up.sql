@@ -0,0 +1,23 @@
+-- Fold rows left behind in the pre-rename organization tables into the
+-- workspace tables, so the Organization* structs are no longer read.
+DO $$
+BEGIN
+    IF to_regclass('organizations') IS NOT NULL AND to_regclass('workspaces') IS NOT NULL THEN
+        INSERT INTO workspaces (uuid, name, owner_pub_key, img, created, updated, show, deleted,
+                                website, github, description)
+        SELECT o.uuid, o.name, o.owner_pub_key, o.img, o.created, o.updated, o.show, o.deleted,
+               o.website, o.github, o.description
+        FROM organizations o
+        WHERE NOT EXISTS (SELECT 1 FROM workspaces w WHERE w.uuid = o.uuid OR w.name = o.name);
+    END IF;
+
+    IF to_regclass('organization_users') IS NOT NULL AND to_regclass('workspace_users') IS NOT NULL THEN
+        INSERT INTO workspace_users (owner_pub_key, workspace_uuid, created, updated)
+        SELECT ou.owner_pub_key, ou.org_uuid, ou.created, ou.updated
+        FROM organization_users ou
+        WHERE NOT EXISTS (
+            SELECT 1 FROM workspace_users wu
+            WHERE wu.owner_pub_key = ou.owner_pub_key AND wu.workspace_uuid = ou.org_uuid
+        );
+    END IF;
+END $$;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS sse_client_states (
+    chat_id VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    webhook_url TEXT,
+    last_event_id VARCHAR(255),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chat_id, url)
+);
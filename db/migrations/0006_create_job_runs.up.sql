@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS job_runs (
+    id SERIAL PRIMARY KEY,
+    job_name VARCHAR(255) NOT NULL,
+    holder VARCHAR(255),
+    status VARCHAR(50) NOT NULL,
+    error TEXT,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_name ON job_runs (job_name);
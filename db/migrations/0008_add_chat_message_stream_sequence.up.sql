@@ -0,0 +1 @@
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS stream_sequence INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+ALTER TABLE sse_client_states DROP COLUMN IF EXISTS last_error;
+ALTER TABLE sse_client_states DROP COLUMN IF EXISTS retry_count;
+ALTER TABLE sse_client_states DROP COLUMN IF EXISTS status;
+
+DROP INDEX IF EXISTS idx_sse_message_logs_event;
+ALTER TABLE sse_message_logs DROP COLUMN IF EXISTS event_id;
@@ -0,0 +1,9 @@
+ALTER TABLE sse_message_logs ADD COLUMN IF NOT EXISTS event_id VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sse_message_logs_event
+    ON sse_message_logs (chat_id, "from", event_id)
+    WHERE event_id IS NOT NULL AND event_id <> '';
+
+ALTER TABLE sse_client_states ADD COLUMN IF NOT EXISTS status VARCHAR(20) DEFAULT 'connecting';
+ALTER TABLE sse_client_states ADD COLUMN IF NOT EXISTS retry_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sse_client_states ADD COLUMN IF NOT EXISTS last_error TEXT;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS chat_branches;
+
+DROP INDEX IF EXISTS idx_chat_messages_branch_id;
+DROP INDEX IF EXISTS idx_chat_messages_parent_id;
+
+ALTER TABLE chats DROP COLUMN IF EXISTS active_branch_id;
+ALTER TABLE chat_messages DROP COLUMN IF EXISTS branch_id;
+ALTER TABLE chat_messages DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,24 @@
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS parent_id VARCHAR(255);
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS branch_id VARCHAR(255) NOT NULL DEFAULT 'main';
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS active_branch_id VARCHAR(255) NOT NULL DEFAULT 'main';
+
+CREATE INDEX IF NOT EXISTS idx_chat_messages_parent_id ON chat_messages (parent_id);
+CREATE INDEX IF NOT EXISTS idx_chat_messages_branch_id ON chat_messages (chat_id, branch_id);
+
+CREATE TABLE IF NOT EXISTS chat_branches (
+    id VARCHAR(255) PRIMARY KEY,
+    chat_id VARCHAR(255) NOT NULL,
+    parent_message_id VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_branches_chat_id ON chat_branches (chat_id);
+
+-- existing chats are linear, every message continues from the one before it
+UPDATE chat_messages m
+SET parent_id = ordered.previous_id
+FROM (
+    SELECT id, LAG(id) OVER (PARTITION BY chat_id ORDER BY timestamp, id) AS previous_id
+    FROM chat_messages
+) ordered
+WHERE m.id = ordered.id AND m.parent_id IS NULL AND ordered.previous_id IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_file_assets_status_last_referenced;
+
+DROP TABLE IF EXISTS workspace_storage_quotas;
+
+ALTER TABLE file_assets DROP COLUMN IF EXISTS storage_key;
@@ -0,0 +1,9 @@
+ALTER TABLE file_assets ADD COLUMN IF NOT EXISTS storage_key TEXT;
+
+CREATE TABLE IF NOT EXISTS workspace_storage_quotas (
+    workspace_id VARCHAR(255) PRIMARY KEY,
+    quota_bytes BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_assets_status_last_referenced ON file_assets (status, last_referenced);
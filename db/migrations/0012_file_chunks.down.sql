@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS file_chunks;
+
+ALTER TABLE file_assets DROP COLUMN IF EXISTS extraction_status;
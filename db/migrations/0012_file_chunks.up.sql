@@ -0,0 +1,14 @@
+ALTER TABLE file_assets ADD COLUMN IF NOT EXISTS extraction_status VARCHAR(20);
+
+CREATE TABLE IF NOT EXISTS file_chunks (
+    id SERIAL PRIMARY KEY,
+    file_id BIGINT NOT NULL,
+    workspace_id VARCHAR(255),
+    chunk_index INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_chunks_file_id ON file_chunks (file_id);
+CREATE INDEX IF NOT EXISTS idx_file_chunks_workspace_id ON file_chunks (workspace_id);
+CREATE INDEX IF NOT EXISTS idx_file_chunks_search ON file_chunks USING GIN (to_tsvector('english', content));
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS knowledge_items (
+    id SERIAL PRIMARY KEY,
+    workspace_uuid VARCHAR(255) NOT NULL,
+    source_type VARCHAR(32) NOT NULL,
+    source_id VARCHAR(255) NOT NULL,
+    title TEXT,
+    content TEXT,
+    content_hash VARCHAR(64),
+    embedding JSONB,
+    embedding_model VARCHAR(255),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_items_source ON knowledge_items (workspace_uuid, source_type, source_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS chat_share_links;
+
+DROP INDEX IF EXISTS idx_chats_owner_pub_key;
+ALTER TABLE chats DROP COLUMN IF EXISTS visibility;
+ALTER TABLE chats DROP COLUMN IF EXISTS owner_pub_key;
@@ -0,0 +1,17 @@
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS owner_pub_key VARCHAR(255);
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'workspace';
+
+CREATE INDEX IF NOT EXISTS idx_chats_owner_pub_key ON chats (owner_pub_key);
+
+CREATE TABLE IF NOT EXISTS chat_share_links (
+    id VARCHAR(255) PRIMARY KEY,
+    chat_id VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_chat_share_links_token_hash ON chat_share_links (token_hash);
+CREATE INDEX IF NOT EXISTS idx_chat_share_links_chat_id ON chat_share_links (chat_id);
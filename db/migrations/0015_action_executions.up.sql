@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS action_executions (
+    id UUID PRIMARY KEY,
+    artifact_id UUID NOT NULL,
+    option_index INTEGER NOT NULL,
+    chat_id VARCHAR(255),
+    kind VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    result JSONB NOT NULL DEFAULT '{}'::jsonb,
+    error TEXT,
+    result_artifact_id UUID,
+    executed_by VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_action_executions_option ON action_executions (artifact_id, option_index);
+CREATE INDEX IF NOT EXISTS idx_action_executions_chat_id ON action_executions (chat_id);
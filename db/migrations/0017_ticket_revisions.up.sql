@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS ticket_revisions (
+    id UUID PRIMARY KEY,
+    ticket_group UUID NOT NULL,
+    version INTEGER NOT NULL,
+    ticket_uuid UUID NOT NULL,
+    snapshot JSONB NOT NULL,
+    author VARCHAR(50) NOT NULL,
+    author_id VARCHAR(255),
+    note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ticket_revisions_group_version ON ticket_revisions (ticket_group, version);
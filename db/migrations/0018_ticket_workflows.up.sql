@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS ticket_workflows (
+    id UUID PRIMARY KEY,
+    workspace_uuid VARCHAR(255) NOT NULL,
+    columns JSONB NOT NULL,
+    transitions JSONB NOT NULL,
+    updated_by VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ticket_workflows_workspace_uuid ON ticket_workflows (workspace_uuid);
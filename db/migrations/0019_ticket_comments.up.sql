@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS ticket_comments (
+    id UUID PRIMARY KEY,
+    target_type VARCHAR(20) NOT NULL,
+    target_uuid VARCHAR(255) NOT NULL,
+    parent_id UUID,
+    body TEXT NOT NULL,
+    author_pub_key VARCHAR(255) NOT NULL,
+    author_alias VARCHAR(255),
+    mentions TEXT[] DEFAULT '{}',
+    resolved BOOLEAN DEFAULT FALSE,
+    resolved_by VARCHAR(255),
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_comments_target ON ticket_comments (target_type, target_uuid);
+CREATE INDEX IF NOT EXISTS idx_ticket_comments_parent_id ON ticket_comments (parent_id);
@@ -0,0 +1 @@
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS assignee VARCHAR(255);
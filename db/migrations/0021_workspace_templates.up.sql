@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS workspace_templates (
+    id UUID PRIMARY KEY,
+    workspace_uuid VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    title TEXT,
+    description TEXT,
+    category VARCHAR(50),
+    amount BIGINT,
+    bounty_type VARCHAR(100),
+    coding_languages TEXT[] DEFAULT '{}',
+    acceptance_criteria TEXT[] DEFAULT '{}',
+    access_restriction VARCHAR(20),
+    is_default BOOLEAN DEFAULT FALSE,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_templates_workspace_uuid ON workspace_templates (workspace_uuid);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workspace_templates_default ON workspace_templates (workspace_uuid, kind) WHERE is_default;
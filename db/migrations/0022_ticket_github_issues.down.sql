@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ticket_github_issues;
+ALTER TABLE tickets DROP COLUMN IF EXISTS github_issue_url;
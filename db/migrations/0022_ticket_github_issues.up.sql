@@ -0,0 +1,19 @@
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS github_issue_url TEXT;
+
+CREATE TABLE IF NOT EXISTS ticket_github_issues (
+    id UUID PRIMARY KEY,
+    ticket_group UUID NOT NULL,
+    workspace_uuid VARCHAR(255) NOT NULL,
+    repository_uuid VARCHAR(255),
+    owner VARCHAR(255) NOT NULL,
+    repo VARCHAR(255) NOT NULL,
+    number INTEGER NOT NULL,
+    url TEXT,
+    state VARCHAR(20),
+    labels TEXT[] DEFAULT '{}',
+    last_synced_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ticket_github_issues_ticket_group ON ticket_github_issues (ticket_group);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ticket_github_issues_issue ON ticket_github_issues (workspace_uuid, owner, repo, number);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS bounty_pull_requests;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS bounty_pull_requests (
+    id UUID PRIMARY KEY,
+    bounty_id INTEGER NOT NULL,
+    workspace_uuid VARCHAR(255),
+    repository_uuid VARCHAR(255),
+    owner VARCHAR(255) NOT NULL,
+    repo VARCHAR(255) NOT NULL,
+    number INTEGER NOT NULL,
+    url TEXT,
+    title TEXT,
+    author VARCHAR(255),
+    state VARCHAR(20),
+    submitted_by VARCHAR(255),
+    proof_id UUID,
+    auto_complete BOOLEAN DEFAULT FALSE,
+    auto_pay BOOLEAN DEFAULT FALSE,
+    auto_pay_by VARCHAR(255),
+    merged_at TIMESTAMP,
+    payment_queued_at TIMESTAMP,
+    payment_processed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bounty_pull_requests_bounty_id ON bounty_pull_requests (bounty_id);
+CREATE INDEX IF NOT EXISTS idx_bounty_pull_requests_pull ON bounty_pull_requests (owner, repo, number);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bounty_pull_requests_unique ON bounty_pull_requests (bounty_id, owner, repo, number);
+CREATE INDEX IF NOT EXISTS idx_bounty_pull_requests_payment_queue ON bounty_pull_requests (payment_queued_at) WHERE payment_processed_at IS NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ticket_plan_tickets;
+ALTER TABLE ticket_plans DROP COLUMN IF EXISTS stub_tickets;
@@ -0,0 +1,15 @@
+ALTER TABLE ticket_plans ADD COLUMN IF NOT EXISTS stub_tickets JSONB NOT NULL DEFAULT '[]';
+
+CREATE TABLE IF NOT EXISTS ticket_plan_tickets (
+    id UUID PRIMARY KEY,
+    plan_uuid UUID NOT NULL,
+    stub_key VARCHAR(255) NOT NULL,
+    ticket_group UUID,
+    bounty_id BIGINT,
+    plan_version INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ticket_plan_tickets_stub ON ticket_plan_tickets (plan_uuid, stub_key);
+CREATE INDEX IF NOT EXISTS idx_ticket_plan_tickets_ticket_group ON ticket_plan_tickets (ticket_group);
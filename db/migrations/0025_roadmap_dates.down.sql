@@ -0,0 +1,4 @@
+ALTER TABLE feature_phases DROP COLUMN IF EXISTS target_date;
+ALTER TABLE feature_phases DROP COLUMN IF EXISTS start_date;
+ALTER TABLE workspace_features DROP COLUMN IF EXISTS target_date;
+ALTER TABLE workspace_features DROP COLUMN IF EXISTS start_date;
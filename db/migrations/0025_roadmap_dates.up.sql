@@ -0,0 +1,4 @@
+ALTER TABLE workspace_features ADD COLUMN IF NOT EXISTS start_date TIMESTAMP;
+ALTER TABLE workspace_features ADD COLUMN IF NOT EXISTS target_date TIMESTAMP;
+ALTER TABLE feature_phases ADD COLUMN IF NOT EXISTS start_date TIMESTAMP;
+ALTER TABLE feature_phases ADD COLUMN IF NOT EXISTS target_date TIMESTAMP;
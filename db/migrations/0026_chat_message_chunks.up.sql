@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS chat_message_chunks (
+    message_id VARCHAR(255) NOT NULL,
+    sequence INTEGER NOT NULL,
+    delta TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, sequence)
+);
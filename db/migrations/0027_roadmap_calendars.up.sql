@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS roadmap_calendars (
+    workspace_uuid VARCHAR(255) PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roadmap_calendars_token_hash ON roadmap_calendars (token_hash);
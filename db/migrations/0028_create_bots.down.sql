@@ -0,0 +1 @@
+-- bots may hold data from before this migration, so it is left in place.
@@ -0,0 +1,20 @@
+-- The bots table predates the Go server and was never created by it, so
+-- fresh databases need it created here.
+CREATE TABLE IF NOT EXISTS bots (
+    uuid TEXT,
+    owner_pub_key TEXT,
+    owner_alias TEXT,
+    name TEXT,
+    unique_name TEXT,
+    description TEXT,
+    tags TEXT[],
+    img TEXT,
+    price_per_use BIGINT,
+    created TIMESTAMPTZ,
+    updated TIMESTAMPTZ,
+    unlisted BOOLEAN,
+    deleted BOOLEAN,
+    member_count BIGINT,
+    owner_route_hint TEXT,
+    tsv TSVECTOR
+);
@@ -2,12 +2,11 @@ package db
 
 import (
 	"context"
-	"os"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/stakwork/sphinx-tribes/config"
 	"github.com/stakwork/sphinx-tribes/logger"
-	"github.com/stakwork/sphinx-tribes/utils"
 )
 
 var ctx = context.Background()
@@ -15,16 +14,15 @@ var RedisClient *redis.Client
 var RedisError error
 var expireTime = 6 * time.Hour
 
-func InitRedis() {
-	redisURL := os.Getenv("REDIS_URL")
+func InitRedis(redisConfig config.RedisConfig) {
+	redisURL := redisConfig.URL
 
 	if redisURL == "" {
-		dbInt, _ := utils.ConvertStringToInt(os.Getenv("REDIS_DB"))
 		RedisClient = redis.NewClient(&redis.Options{
-			Addr:     os.Getenv("REDIS_HOST"),
-			Username: os.Getenv("REDIS_USER"),
-			Password: os.Getenv("REDIS_PASS"),
-			DB:       dbInt,
+			Addr:     redisConfig.Host,
+			Username: redisConfig.User,
+			Password: redisConfig.Password,
+			DB:       redisConfig.DB,
 		})
 	} else {
 		opt, err := redis.ParseURL(redisURL)
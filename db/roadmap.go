@@ -0,0 +1,290 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrRoadmapDates is returned when a target date comes before its start date.
+var ErrRoadmapDates = errors.New("target_date cannot be before start_date")
+
+// RoadmapProgress counts the latest tickets and the bounties of a feature or
+// a phase. PercentComplete is the share of them that are completed.
+type RoadmapProgress struct {
+	Tickets           int `json:"tickets"`
+	TicketsCompleted  int `json:"tickets_completed"`
+	Bounties          int `json:"bounties"`
+	BountiesCompleted int `json:"bounties_completed"`
+	PercentComplete   int `json:"percent_complete"`
+}
+
+func (p *RoadmapProgress) addTicket(ticket Tickets) {
+	p.Tickets++
+	if ticket.Status == CompletedTicket {
+		p.TicketsCompleted++
+	}
+}
+
+func (p *RoadmapProgress) addBounty(bounty NewBounty) {
+	p.Bounties++
+	if bounty.Completed || bounty.Paid {
+		p.BountiesCompleted++
+	}
+}
+
+func (p *RoadmapProgress) rollup() {
+	p.PercentComplete = 0
+	if total := p.Tickets + p.Bounties; total > 0 {
+		p.PercentComplete = (p.TicketsCompleted + p.BountiesCompleted) * 100 / total
+	}
+}
+
+// Done reports whether there is work and all of it is completed.
+func (p RoadmapProgress) Done() bool {
+	return p.Tickets+p.Bounties > 0 && p.PercentComplete == 100
+}
+
+type RoadmapPhase struct {
+	Uuid       string          `json:"uuid"`
+	Name       string          `json:"name"`
+	Priority   int             `json:"priority"`
+	StartDate  *time.Time      `json:"start_date,omitempty"`
+	TargetDate *time.Time      `json:"target_date,omitempty"`
+	Progress   RoadmapProgress `json:"progress"`
+	// Slipped is set once the target date has passed with work left.
+	Slipped  bool `json:"slipped"`
+	DaysLate int  `json:"days_late,omitempty"`
+}
+
+type RoadmapFeature struct {
+	Uuid          string          `json:"uuid"`
+	Name          string          `json:"name"`
+	Priority      int             `json:"priority"`
+	Status        FeatureStatus   `json:"status"`
+	StartDate     *time.Time      `json:"start_date,omitempty"`
+	TargetDate    *time.Time      `json:"target_date,omitempty"`
+	Progress      RoadmapProgress `json:"progress"`
+	Slipped       bool            `json:"slipped"`
+	DaysLate      int             `json:"days_late,omitempty"`
+	SlippedPhases int             `json:"slipped_phases"`
+	Phases        []RoadmapPhase  `json:"phases"`
+}
+
+// WorkspaceRoadmap lays the features of a workspace and their phases on a
+// timeline running from the earliest start date to the latest target date.
+type WorkspaceRoadmap struct {
+	WorkspaceUuid string           `json:"workspace_uuid"`
+	Start         *time.Time       `json:"start,omitempty"`
+	End           *time.Time       `json:"end,omitempty"`
+	Features      []RoadmapFeature `json:"features"`
+	// CalendarCreatedAt is set when the workspace has a calendar feed.
+	CalendarCreatedAt *time.Time `json:"calendar_created_at,omitempty"`
+}
+
+// ValidateRoadmapDates checks that a target date does not come before its
+// start date. Either may be unset.
+func ValidateRoadmapDates(start, target *time.Time) error {
+	if start != nil && target != nil && target.Before(*start) {
+		return ErrRoadmapDates
+	}
+	return nil
+}
+
+// RoadmapSlip reports whether work due on target is late at now, and by how
+// many days. Work is due by the end of its target day.
+func RoadmapSlip(target *time.Time, progress RoadmapProgress, now time.Time) (bool, int) {
+	if target == nil || progress.Done() {
+		return false, 0
+	}
+	year, month, day := target.Date()
+	due := time.Date(year, month, day, 0, 0, 0, 0, target.Location()).AddDate(0, 0, 1)
+	if now.Before(due) {
+		return false, 0
+	}
+	return true, int(now.Sub(due).Hours()/24) + 1
+}
+
+// roadmapBefore orders roadmap entries by start date, then target date and
+// priority. Entries without dates come last.
+func roadmapBefore(startA, targetA *time.Time, priorityA int, startB, targetB *time.Time, priorityB int) bool {
+	dateBefore := func(a, b *time.Time) (bool, bool) {
+		switch {
+		case a == nil && b == nil:
+			return false, false
+		case a == nil:
+			return false, true
+		case b == nil:
+			return true, true
+		case a.Equal(*b):
+			return false, false
+		}
+		return a.Before(*b), true
+	}
+	if before, decided := dateBefore(startA, startB); decided {
+		return before
+	}
+	if before, decided := dateBefore(targetA, targetB); decided {
+		return before
+	}
+	return priorityA < priorityB
+}
+
+// FeatureRoadmap rolls up the progress of a feature and of each of its
+// phases from the latest version of their tickets and their bounties.
+// Bounties and tickets outside any phase only count for the feature.
+func FeatureRoadmap(feature WorkspaceFeatures, phases []FeaturePhase, tickets []Tickets, bounties []NewBounty, now time.Time) RoadmapFeature {
+	progress := map[string]*RoadmapProgress{}
+	for _, phase := range phases {
+		progress[phase.Uuid] = &RoadmapProgress{}
+	}
+
+	entry := RoadmapFeature{
+		Uuid:       feature.Uuid,
+		Name:       feature.Name,
+		Priority:   feature.Priority,
+		Status:     feature.FeatStatus,
+		StartDate:  feature.StartDate,
+		TargetDate: feature.TargetDate,
+		Phases:     []RoadmapPhase{},
+	}
+	for _, ticket := range LatestTickets(tickets) {
+		entry.Progress.addTicket(ticket)
+		if phase, ok := progress[ticket.PhaseUUID]; ok {
+			phase.addTicket(ticket)
+		}
+	}
+	for _, bounty := range bounties {
+		entry.Progress.addBounty(bounty)
+		if phase, ok := progress[bounty.PhaseUuid]; ok {
+			phase.addBounty(bounty)
+		}
+	}
+	entry.Progress.rollup()
+	entry.Slipped, entry.DaysLate = RoadmapSlip(entry.TargetDate, entry.Progress, now)
+
+	for _, phase := range phases {
+		phaseProgress := progress[phase.Uuid]
+		phaseProgress.rollup()
+		roadmapPhase := RoadmapPhase{
+			Uuid:       phase.Uuid,
+			Name:       phase.Name,
+			Priority:   phase.Priority,
+			StartDate:  phase.StartDate,
+			TargetDate: phase.TargetDate,
+			Progress:   *phaseProgress,
+		}
+		roadmapPhase.Slipped, roadmapPhase.DaysLate = RoadmapSlip(phase.TargetDate, *phaseProgress, now)
+		if roadmapPhase.Slipped {
+			entry.SlippedPhases++
+		}
+		entry.Phases = append(entry.Phases, roadmapPhase)
+	}
+	sort.SliceStable(entry.Phases, func(i, j int) bool {
+		a, b := entry.Phases[i], entry.Phases[j]
+		return roadmapBefore(a.StartDate, a.TargetDate, a.Priority, b.StartDate, b.TargetDate, b.Priority)
+	})
+	return entry
+}
+
+// BuildWorkspaceRoadmap orders the features of a workspace on the timeline
+// and sets its bounds from the dates of the features and their phases.
+func BuildWorkspaceRoadmap(workspaceUuid string, features []RoadmapFeature) WorkspaceRoadmap {
+	roadmap := WorkspaceRoadmap{WorkspaceUuid: workspaceUuid, Features: features}
+	if roadmap.Features == nil {
+		roadmap.Features = []RoadmapFeature{}
+	}
+	sort.SliceStable(roadmap.Features, func(i, j int) bool {
+		a, b := roadmap.Features[i], roadmap.Features[j]
+		return roadmapBefore(a.StartDate, a.TargetDate, a.Priority, b.StartDate, b.TargetDate, b.Priority)
+	})
+
+	extend := func(start, target *time.Time) {
+		for _, date := range []*time.Time{start, target} {
+			if date == nil {
+				continue
+			}
+			if roadmap.Start == nil || date.Before(*roadmap.Start) {
+				roadmap.Start = date
+			}
+			if roadmap.End == nil || date.After(*roadmap.End) {
+				roadmap.End = date
+			}
+		}
+	}
+	for _, feature := range roadmap.Features {
+		extend(feature.StartDate, feature.TargetDate)
+		for _, phase := range feature.Phases {
+			extend(phase.StartDate, phase.TargetDate)
+		}
+	}
+	return roadmap
+}
+
+// SaveRoadmapCalendar stores the calendar feed of a workspace, replacing the
+// token of the feed it had.
+func (db database) SaveRoadmapCalendar(calendar *RoadmapCalendar) (RoadmapCalendar, error) {
+	if calendar.WorkspaceUuid == "" || calendar.TokenHash == "" {
+		return RoadmapCalendar{}, errors.New("workspace and token are required")
+	}
+	calendar.CreatedAt = time.Now()
+
+	if err := db.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workspace_uuid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token_hash", "created_by", "created_at"}),
+	}).Create(calendar).Error; err != nil {
+		return RoadmapCalendar{}, fmt.Errorf("failed to save roadmap calendar: %w", err)
+	}
+	return *calendar, nil
+}
+
+func (db database) GetRoadmapCalendar(workspaceUuid string) (RoadmapCalendar, error) {
+	var calendar RoadmapCalendar
+	if err := db.db.Where("workspace_uuid = ?", workspaceUuid).First(&calendar).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return RoadmapCalendar{}, fmt.Errorf("roadmap calendar not found")
+		}
+		return RoadmapCalendar{}, fmt.Errorf("failed to fetch roadmap calendar: %w", err)
+	}
+	return calendar, nil
+}
+
+// DeleteRoadmapCalendar revokes the calendar feed of a workspace.
+func (db database) DeleteRoadmapCalendar(workspaceUuid string) error {
+	if err := db.db.Where("workspace_uuid = ?", workspaceUuid).Delete(&RoadmapCalendar{}).Error; err != nil {
+		return fmt.Errorf("failed to delete roadmap calendar: %w", err)
+	}
+	return nil
+}
+
+// SetFeatureRoadmapDates sets both roadmap dates of a feature, a nil date
+// clears it.
+func (db database) SetFeatureRoadmapDates(uuid string, start, target *time.Time) (WorkspaceFeatures, error) {
+	var feature WorkspaceFeatures
+	if err := db.db.Model(&WorkspaceFeatures{}).Where("uuid = ?", uuid).
+		Updates(map[string]interface{}{"start_date": start, "target_date": target}).Error; err != nil {
+		return feature, fmt.Errorf("failed to set feature dates: %w", err)
+	}
+	if err := db.db.Where("uuid = ?", uuid).First(&feature).Error; err != nil {
+		return feature, fmt.Errorf("failed to fetch feature: %w", err)
+	}
+	return feature, nil
+}
+
+// SetFeaturePhaseRoadmapDates sets both roadmap dates of a phase, a nil date
+// clears it.
+func (db database) SetFeaturePhaseRoadmapDates(uuid string, start, target *time.Time) (FeaturePhase, error) {
+	var phase FeaturePhase
+	if err := db.db.Model(&FeaturePhase{}).Where("uuid = ?", uuid).
+		Updates(map[string]interface{}{"start_date": start, "target_date": target}).Error; err != nil {
+		return phase, fmt.Errorf("failed to set phase dates: %w", err)
+	}
+	if err := db.db.Where("uuid = ?", uuid).First(&phase).Error; err != nil {
+		return phase, fmt.Errorf("failed to fetch phase: %w", err)
+	}
+	return phase, nil
+}
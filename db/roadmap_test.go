@@ -0,0 +1,161 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func roadmapDate(value string) *time.Time {
+	date, _ := time.Parse("2006-01-02", value)
+	return &date
+}
+
+func TestValidateRoadmapDates(t *testing.T) {
+	assert.NoError(t, ValidateRoadmapDates(nil, roadmapDate("2026-01-01")))
+	assert.NoError(t, ValidateRoadmapDates(roadmapDate("2026-01-01"), roadmapDate("2026-01-01")))
+	assert.ErrorIs(t, ValidateRoadmapDates(roadmapDate("2026-01-02"), roadmapDate("2026-01-01")), ErrRoadmapDates)
+}
+
+func TestRoadmapSlip(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	open := RoadmapProgress{Tickets: 2, TicketsCompleted: 1, PercentComplete: 50}
+	done := RoadmapProgress{Tickets: 2, TicketsCompleted: 2, PercentComplete: 100}
+
+	tests := []struct {
+		name     string
+		target   *time.Time
+		progress RoadmapProgress
+		slipped  bool
+		daysLate int
+	}{
+		{"No Target", nil, open, false, 0},
+		{"Due Today", roadmapDate("2026-03-10"), open, false, 0},
+		{"Due Yesterday", roadmapDate("2026-03-09"), open, true, 1},
+		{"Due Last Week", roadmapDate("2026-03-03"), open, true, 7},
+		{"Done Late", roadmapDate("2026-03-03"), done, false, 0},
+		{"Nothing Planned", roadmapDate("2026-03-03"), RoadmapProgress{}, true, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slipped, daysLate := RoadmapSlip(tt.target, tt.progress, now)
+			assert.Equal(t, tt.slipped, slipped)
+			assert.Equal(t, tt.daysLate, daysLate)
+		})
+	}
+}
+
+func TestFeatureRoadmap(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	feature := WorkspaceFeatures{Uuid: "feature-1", Name: "Payments", FeatStatus: ActiveFeature, TargetDate: roadmapDate("2026-04-01")}
+	phases := []FeaturePhase{
+		{Uuid: "phase-late", Name: "Later", Priority: 1, StartDate: roadmapDate("2026-03-15"), TargetDate: roadmapDate("2026-03-31")},
+		{Uuid: "phase-early", Name: "Build", Priority: 2, StartDate: roadmapDate("2026-03-01"), TargetDate: roadmapDate("2026-03-05")},
+		{Uuid: "phase-undated", Name: "Someday", Priority: 0},
+	}
+
+	done := graphTicket(groupA, 1, CompletedTicket)
+	done.PhaseUUID = "phase-early"
+	oldVersion := graphTicket(groupB, 2, CompletedTicket)
+	oldVersion.PhaseUUID = "phase-early"
+	inProgress := graphTicket(groupB, 2, InProgressTicket)
+	inProgress.PhaseUUID = "phase-early"
+	inProgress.Version = 2
+	bounties := []NewBounty{
+		{PhaseUuid: "phase-early", Paid: true},
+		{PhaseUuid: "phase-late", Completed: true},
+		{PhaseUuid: ""},
+	}
+
+	roadmap := FeatureRoadmap(feature, phases, []Tickets{done, oldVersion, inProgress}, bounties, now)
+
+	assert.Equal(t, RoadmapProgress{Tickets: 2, TicketsCompleted: 1, Bounties: 3, BountiesCompleted: 2, PercentComplete: 60}, roadmap.Progress)
+	assert.False(t, roadmap.Slipped)
+	assert.Equal(t, 1, roadmap.SlippedPhases)
+
+	require.Len(t, roadmap.Phases, 3)
+	early, late, undated := roadmap.Phases[0], roadmap.Phases[1], roadmap.Phases[2]
+	assert.Equal(t, "phase-early", early.Uuid)
+	assert.Equal(t, RoadmapProgress{Tickets: 2, TicketsCompleted: 1, Bounties: 1, BountiesCompleted: 1, PercentComplete: 66}, early.Progress)
+	assert.True(t, early.Slipped)
+	assert.Equal(t, 5, early.DaysLate)
+	assert.Equal(t, "phase-late", late.Uuid)
+	assert.Equal(t, 100, late.Progress.PercentComplete)
+	assert.Equal(t, "phase-undated", undated.Uuid)
+	assert.Equal(t, 0, undated.Progress.PercentComplete)
+}
+
+func TestBuildWorkspaceRoadmap(t *testing.T) {
+	roadmap := BuildWorkspaceRoadmap("workspace-1", []RoadmapFeature{
+		{Uuid: "undated", Priority: 0},
+		{Uuid: "second", StartDate: roadmapDate("2026-02-01"), TargetDate: roadmapDate("2026-03-01")},
+		{Uuid: "first", StartDate: roadmapDate("2026-01-01"), Phases: []RoadmapPhase{{TargetDate: roadmapDate("2026-05-01")}}},
+	})
+
+	require.Len(t, roadmap.Features, 3)
+	assert.Equal(t, "first", roadmap.Features[0].Uuid)
+	assert.Equal(t, "second", roadmap.Features[1].Uuid)
+	assert.Equal(t, "undated", roadmap.Features[2].Uuid)
+	assert.Equal(t, roadmapDate("2026-01-01"), roadmap.Start)
+	assert.Equal(t, roadmapDate("2026-05-01"), roadmap.End)
+}
+
+func TestRoadmapCalendar(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	workspace := "calendar-workspace"
+	TestDB.DeleteRoadmapCalendar(workspace)
+
+	_, err := TestDB.GetRoadmapCalendar(workspace)
+	assert.EqualError(t, err, "roadmap calendar not found")
+
+	_, err = TestDB.SaveRoadmapCalendar(&RoadmapCalendar{WorkspaceUuid: workspace, TokenHash: "hash-1", CreatedBy: "owner"})
+	require.NoError(t, err)
+	_, err = TestDB.SaveRoadmapCalendar(&RoadmapCalendar{WorkspaceUuid: workspace, TokenHash: "hash-2", CreatedBy: "member"})
+	require.NoError(t, err)
+
+	calendar, err := TestDB.GetRoadmapCalendar(workspace)
+	require.NoError(t, err)
+	assert.Equal(t, "hash-2", calendar.TokenHash)
+	assert.Equal(t, "member", calendar.CreatedBy)
+
+	require.NoError(t, TestDB.DeleteRoadmapCalendar(workspace))
+	_, err = TestDB.GetRoadmapCalendar(workspace)
+	assert.EqualError(t, err, "roadmap calendar not found")
+}
+
+func TestSetFeatureRoadmapDates(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	feature, err := TestDB.CreateOrEditFeature(WorkspaceFeatures{
+		Uuid:          "roadmap-dates-feature",
+		WorkspaceUuid: "roadmap-dates-workspace",
+		Name:          "Payments",
+		StartDate:     roadmapDate("2026-03-01"),
+		TargetDate:    roadmapDate("2026-03-20"),
+	})
+	require.NoError(t, err)
+	defer TestDB.DeleteFeatureByUuid(feature.Uuid)
+
+	updated, err := TestDB.SetFeatureRoadmapDates(feature.Uuid, feature.StartDate, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, updated.StartDate)
+	assert.Nil(t, updated.TargetDate)
+
+	phase, err := TestDB.CreateOrEditFeaturePhase(FeaturePhase{
+		Uuid:        "roadmap-dates-phase",
+		FeatureUuid: feature.Uuid,
+		Name:        "Invoices",
+		TargetDate:  roadmapDate("2026-03-20"),
+	})
+	require.NoError(t, err)
+	defer TestDB.DeleteFeaturePhase(feature.Uuid, phase.Uuid)
+
+	updatedPhase, err := TestDB.SetFeaturePhaseRoadmapDates(phase.Uuid, nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, updatedPhase.TargetDate)
+}
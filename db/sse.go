@@ -7,8 +7,13 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrDuplicateSSEEvent is returned when an event with the same id was already
+// stored for the chat and source.
+var ErrDuplicateSSEEvent = errors.New("SSE event already stored")
+
 func (db database) CreateSSEMessageLog(event map[string]interface{}, chatID, from, to string) (*SSEMessageLog, error) {
 	if chatID == "" {
 		return nil, errors.New("chat ID is required")
@@ -20,6 +25,19 @@ func (db database) CreateSSEMessageLog(event map[string]interface{}, chatID, fro
 		return nil, errors.New("target URL is required")
 	}
 
+	eventID, _ := event["id"].(string)
+	if eventID != "" {
+		var count int64
+		if err := db.db.Model(&SSEMessageLog{}).
+			Where("chat_id = ? AND \"from\" = ? AND event_id = ?", chatID, from, eventID).
+			Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to check SSE event: %w", err)
+		}
+		if count > 0 {
+			return nil, ErrDuplicateSSEEvent
+		}
+	}
+
 	now := time.Now()
 	messageLog := &SSEMessageLog{
 		ID:        uuid.New(),
@@ -30,10 +48,16 @@ func (db database) CreateSSEMessageLog(event map[string]interface{}, chatID, fro
 		From:      from,
 		To:        to,
 		Status:    SSEStatusNew,
+		EventID:   eventID,
 	}
 
-	if err := db.db.Create(messageLog).Error; err != nil {
-		return nil, fmt.Errorf("failed to create SSE message log: %w", err)
+	// the unique index catches an event stored concurrently since the check
+	result := db.db.Clauses(clause.OnConflict{DoNothing: true}).Create(messageLog)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to create SSE message log: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrDuplicateSSEEvent
 	}
 
 	return messageLog, nil
@@ -178,3 +202,58 @@ func (db database) GetSSEMessagesByChatID(chatID string, limit int, offset int,
 
 	return messages, total, nil
 }
+
+func (db database) SaveSSEClientState(state SSEClientState) error {
+	if state.ChatID == "" {
+		return errors.New("chat ID is required")
+	}
+	if state.URL == "" {
+		return errors.New("source URL is required")
+	}
+
+	state.UpdatedAt = time.Now()
+
+	if err := db.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}, {Name: "url"}},
+		DoUpdates: clause.AssignmentColumns([]string{"webhook_url", "last_event_id", "status", "retry_count", "last_error", "updated_at"}),
+	}).Create(&state).Error; err != nil {
+		return fmt.Errorf("failed to save SSE client state: %w", err)
+	}
+
+	return nil
+}
+
+func (db database) GetSSEClientState(chatID, url string) (*SSEClientState, error) {
+	var state SSEClientState
+	if err := db.db.Where("chat_id = ? AND url = ?", chatID, url).First(&state).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve SSE client state: %w", err)
+	}
+	return &state, nil
+}
+
+func (db database) GetSSEClientStates(chatID string) ([]SSEClientState, error) {
+	query := db.db.Model(&SSEClientState{})
+	if chatID != "" {
+		query = query.Where("chat_id = ?", chatID)
+	}
+
+	var states []SSEClientState
+	if err := query.Order("updated_at DESC").Find(&states).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve SSE client states: %w", err)
+	}
+	return states, nil
+}
+
+// GetResumableSSEClientStates returns the clients that should be running,
+// every client that was not stopped on request and did not give up.
+func (db database) GetResumableSSEClientStates() ([]SSEClientState, error) {
+	var states []SSEClientState
+	if err := db.db.Where("status IS NULL OR status NOT IN ?", []SSEClientStatus{SSEClientFailed, SSEClientStopped}).
+		Find(&states).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve resumable SSE client states: %w", err)
+	}
+	return states, nil
+}
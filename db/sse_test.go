@@ -732,4 +732,83 @@ func TestGetNewSSEMessageLogsByChatID(t *testing.T) {
 			}
 		})
 	}
-} 
\ No newline at end of file
+} 
+func TestCreateSSEMessageLogDedupe(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	chatID := uuid.New().String()
+	TestDB.db.Exec("DELETE FROM sse_message_logs WHERE chat_id = ?", chatID)
+
+	event := map[string]interface{}{"id": "evt-1", "type": "message"}
+
+	t.Run("Stores First Delivery", func(t *testing.T) {
+		messageLog, err := TestDB.CreateSSEMessageLog(event, chatID, "https://source.com/sse", "https://target.com/webhook")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "evt-1", messageLog.EventID)
+	})
+
+	t.Run("Rejects Replayed Event", func(t *testing.T) {
+		_, err := TestDB.CreateSSEMessageLog(event, chatID, "https://source.com/sse", "https://target.com/webhook")
+
+		assert.ErrorIs(t, err, ErrDuplicateSSEEvent)
+	})
+
+	t.Run("Same Event ID From Another Source", func(t *testing.T) {
+		_, err := TestDB.CreateSSEMessageLog(event, chatID, "https://other.com/sse", "https://target.com/webhook")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Events Without ID Are Always Stored", func(t *testing.T) {
+		noID := map[string]interface{}{"type": "message"}
+		_, err := TestDB.CreateSSEMessageLog(noID, chatID, "https://source.com/sse", "https://target.com/webhook")
+		assert.NoError(t, err)
+		_, err = TestDB.CreateSSEMessageLog(noID, chatID, "https://source.com/sse", "https://target.com/webhook")
+		assert.NoError(t, err)
+	})
+}
+
+func TestGetSSEClientStates(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	TestDB.db.Exec("DELETE FROM sse_client_states")
+
+	chatID := uuid.New().String()
+	states := []SSEClientState{
+		{ChatID: chatID, URL: "https://a.com/sse", Status: SSEClientConnected},
+		{ChatID: chatID, URL: "https://b.com/sse", Status: SSEClientRetrying, RetryCount: 3},
+		{ChatID: chatID, URL: "https://c.com/sse", Status: SSEClientFailed, LastError: "gave up"},
+		{ChatID: uuid.New().String(), URL: "https://d.com/sse", Status: SSEClientStopped},
+	}
+	for _, state := range states {
+		assert.NoError(t, TestDB.SaveSSEClientState(state))
+	}
+
+	t.Run("Filters By Chat", func(t *testing.T) {
+		result, err := TestDB.GetSSEClientStates(chatID)
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 3)
+	})
+
+	t.Run("All Chats", func(t *testing.T) {
+		result, err := TestDB.GetSSEClientStates("")
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 4)
+	})
+
+	t.Run("Resumable Skips Failed And Stopped", func(t *testing.T) {
+		result, err := TestDB.GetResumableSSEClientStates()
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		for _, state := range result {
+			assert.NotEqual(t, SSEClientFailed, state.Status)
+			assert.NotEqual(t, SSEClientStopped, state.Status)
+		}
+	})
+}
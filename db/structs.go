@@ -161,6 +161,81 @@ type GithubIssue struct {
 	Description string `json:"description"`
 }
 
+// GithubIssueDetails is a GitHub issue as it is imported into a ticket.
+type GithubIssueDetails struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"`
+	Labels    []string  `json:"labels"`
+	URL       string    `json:"url"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TicketGithubIssue links a ticket group to the GitHub issue it was imported
+// from, with the labels and state of the issue at the last sync.
+type TicketGithubIssue struct {
+	ID             uuid.UUID      `gorm:"primaryKey;type:uuid" json:"id"`
+	TicketGroup    uuid.UUID      `gorm:"type:uuid;uniqueIndex;not null" json:"ticket_group"`
+	WorkspaceUuid  string         `gorm:"type:varchar(255);not null" json:"workspace_uuid"`
+	RepositoryUuid string         `gorm:"type:varchar(255)" json:"repository_uuid"`
+	Owner          string         `gorm:"type:varchar(255);not null" json:"owner"`
+	Repo           string         `gorm:"type:varchar(255);not null" json:"repo"`
+	Number         int            `gorm:"not null" json:"number"`
+	URL            string         `gorm:"type:text" json:"url"`
+	State          string         `gorm:"type:varchar(20)" json:"state"`
+	Labels         pq.StringArray `gorm:"type:text[];default:'{}'" json:"labels"`
+	LastSyncedAt   time.Time      `json:"last_synced_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+type PullRequestState string
+
+const (
+	PullRequestOpen   PullRequestState = "open"
+	PullRequestReview PullRequestState = "review"
+	PullRequestMerged PullRequestState = "merged"
+	PullRequestClosed PullRequestState = "closed"
+)
+
+// GithubPullRequest is a GitHub pull request as it is tracked on a bounty.
+type GithubPullRequest struct {
+	Number   int              `json:"number"`
+	Title    string           `json:"title"`
+	State    PullRequestState `json:"state"`
+	Author   string           `json:"author"`
+	URL      string           `json:"url"`
+	MergedAt *time.Time       `json:"merged_at"`
+}
+
+// BountyPullRequest links a bounty to a pull request of one of its
+// workspace repositories. The proof of work created for the pull request is
+// accepted when it merges; with AutoComplete the bounty is then marked
+// completed, and with AutoPay its payment is queued for AutoPayBy.
+type BountyPullRequest struct {
+	ID                 uuid.UUID        `gorm:"primaryKey;type:uuid" json:"id"`
+	BountyID           uint             `gorm:"index;not null" json:"bounty_id"`
+	WorkspaceUuid      string           `gorm:"type:varchar(255)" json:"workspace_uuid"`
+	RepositoryUuid     string           `gorm:"type:varchar(255)" json:"repository_uuid"`
+	Owner              string           `gorm:"type:varchar(255);not null" json:"owner"`
+	Repo               string           `gorm:"type:varchar(255);not null" json:"repo"`
+	Number             int              `gorm:"not null" json:"number"`
+	URL                string           `gorm:"type:text" json:"url"`
+	Title              string           `gorm:"type:text" json:"title"`
+	Author             string           `gorm:"type:varchar(255)" json:"author"`
+	State              PullRequestState `gorm:"type:varchar(20)" json:"state"`
+	SubmittedBy        string           `gorm:"type:varchar(255)" json:"submitted_by"`
+	ProofID            *uuid.UUID       `gorm:"type:uuid" json:"proof_id"`
+	AutoComplete       bool             `json:"auto_complete"`
+	AutoPay            bool             `json:"auto_pay"`
+	AutoPayBy          string           `gorm:"type:varchar(255)" json:"auto_pay_by,omitempty"`
+	MergedAt           *time.Time       `json:"merged_at"`
+	PaymentQueuedAt    *time.Time       `json:"payment_queued_at"`
+	PaymentProcessedAt *time.Time       `json:"payment_processed_at"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+}
+
 type Pagination struct {
 	Limit int    `json:"limit"`
 	Page  int    `json:"page"`
@@ -653,6 +728,10 @@ type WorkspaceFeatures struct {
 	BountiesCountAssigned  int           `gorm:"-" json:"bounties_count_assigned"`
 	BountiesCountOpen      int           `gorm:"-" json:"bounties_count_open"`
 	FeatStatus             FeatureStatus `gorm:"type:varchar(20);default:'active';not null" json:"feat_status"`
+
+	// StartDate and TargetDate place the feature on the workspace roadmap.
+	StartDate  *time.Time `json:"start_date,omitempty"`
+	TargetDate *time.Time `json:"target_date,omitempty"`
 }
 
 type FeaturePhase struct {
@@ -668,6 +747,19 @@ type FeaturePhase struct {
 	Updated      *time.Time `json:"updated"`
 	CreatedBy    string     `json:"created_by"`
 	UpdatedBy    string     `json:"updated_by"`
+
+	// StartDate and TargetDate place the phase on the workspace roadmap.
+	StartDate  *time.Time `json:"start_date,omitempty"`
+	TargetDate *time.Time `json:"target_date,omitempty"`
+}
+
+// RoadmapCalendar is the calendar feed of a workspace roadmap. Only the
+// SHA-256 hash of its token is stored, a new feed replaces the old one.
+type RoadmapCalendar struct {
+	WorkspaceUuid string    `gorm:"primaryKey" json:"workspace_uuid"`
+	TokenHash     string    `gorm:"uniqueIndex;not null" json:"-"`
+	CreatedBy     string    `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type BountyRoles struct {
@@ -1087,6 +1179,132 @@ type Tickets struct {
 	Mode          string            `json:"mode,omitempty"`
 	CreatedAt     time.Time         `gorm:"type:timestamp;default:current_timestamp" json:"created_at"`
 	UpdatedAt     time.Time         `gorm:"type:timestamp;default:current_timestamp" json:"updated_at"`
+
+	// DependsOn lists the ticket groups of the same phase that block this ticket.
+	DependsOn pq.StringArray `gorm:"type:text[];default:'{}'" json:"depends_on"`
+	// RevisionNote describes the edit in the revision it records.
+	RevisionNote string `gorm:"-" json:"-"`
+	// Comments counts the comments on the ticket group, when listed with them.
+	Comments *TicketCommentCount `gorm:"-" json:"comments,omitempty"`
+	// Assignee is the pubkey of the person working on the ticket.
+	Assignee string `gorm:"type:varchar(255)" json:"assignee,omitempty"`
+	// GithubIssueURL links a ticket imported from a GitHub issue.
+	GithubIssueURL string `gorm:"type:text" json:"github_issue_url,omitempty"`
+}
+
+// TicketSnapshot is the content of a ticket as kept in its revisions.
+type TicketSnapshot struct {
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Status        TicketStatus `json:"status"`
+	WorkspaceUuid string       `json:"workspace_uuid"`
+	FeatureUUID   string       `json:"feature_uuid"`
+	PhaseUUID     string       `json:"phase_uuid"`
+	Sequence      int          `json:"sequence"`
+	Amount        *int64       `json:"amount"`
+	Category      *Category    `json:"category"`
+	DependsOn     []string     `json:"depends_on"`
+	Assignee      string       `json:"assignee,omitempty"`
+}
+
+func (s TicketSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *TicketSnapshot) Scan(src interface{}) error {
+	switch source := src.(type) {
+	case []byte:
+		return json.Unmarshal(source, s)
+	case string:
+		return json.Unmarshal([]byte(source), s)
+	}
+	return errors.New("ticket snapshot must be scanned from json")
+}
+
+// TicketRevision is an immutable record of a ticket group after an edit.
+// Versions count up from 1 in every group.
+type TicketRevision struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	TicketGroup uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_ticket_revisions_group_version" json:"ticket_group"`
+	Version     int            `gorm:"not null;uniqueIndex:idx_ticket_revisions_group_version" json:"version"`
+	TicketUUID  uuid.UUID      `gorm:"type:uuid;not null" json:"ticket_uuid"`
+	Snapshot    TicketSnapshot `gorm:"type:jsonb;not null" json:"snapshot"`
+	Author      Author         `gorm:"type:varchar(50);not null" json:"author"`
+	AuthorID    string         `gorm:"type:varchar(255)" json:"author_id,omitempty"`
+	Note        string         `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// TicketFieldChange is a field that differs between two ticket revisions.
+type TicketFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// WorkflowColumn is a column of a workspace board. Tickets in the column
+// carry its status.
+type WorkflowColumn struct {
+	Status TicketStatus `json:"status"`
+	Name   string       `json:"name"`
+	// WIPLimit caps the number of tickets in the column, 0 means no limit.
+	WIPLimit int `json:"wip_limit"`
+	// RequiredFields must be set on a ticket before it enters the column.
+	RequiredFields []string `json:"required_fields"`
+	// BountyStatuses are the bounty card statuses shown in the column.
+	BountyStatuses []BountyStatus `json:"bounty_statuses"`
+}
+
+type WorkflowColumns []WorkflowColumn
+
+func (c WorkflowColumns) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *WorkflowColumns) Scan(src interface{}) error {
+	switch source := src.(type) {
+	case []byte:
+		return json.Unmarshal(source, c)
+	case string:
+		return json.Unmarshal([]byte(source), c)
+	}
+	return errors.New("workflow columns must be scanned from json")
+}
+
+type WorkflowTransition struct {
+	From TicketStatus `json:"from"`
+	To   TicketStatus `json:"to"`
+}
+
+type WorkflowTransitions []WorkflowTransition
+
+func (t WorkflowTransitions) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+func (t *WorkflowTransitions) Scan(src interface{}) error {
+	switch source := src.(type) {
+	case []byte:
+		return json.Unmarshal(source, t)
+	case string:
+		return json.Unmarshal([]byte(source), t)
+	}
+	return errors.New("workflow transitions must be scanned from json")
+}
+
+// TicketWorkflow is the board definition of a workspace. Columns are kept in
+// board order. When Transitions is empty tickets may move between any two
+// columns.
+type TicketWorkflow struct {
+	ID            uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	WorkspaceUuid string              `gorm:"type:varchar(255);uniqueIndex;not null" json:"workspace_uuid"`
+	Columns       WorkflowColumns     `gorm:"type:jsonb;not null" json:"columns"`
+	Transitions   WorkflowTransitions `gorm:"type:jsonb;not null" json:"transitions"`
+	UpdatedBy     string              `gorm:"type:varchar(255)" json:"updated_by"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+	// IsDefault is set on the built-in workflow of workspaces without one.
+	IsDefault bool `gorm:"-" json:"is_default"`
 }
 
 type TicketArrayItem struct {
@@ -1126,6 +1344,8 @@ const (
 	ProductBriefContext ContextTagType = "productBrief"
 	FeatureBriefContext ContextTagType = "featureBrief"
 	SchematicContext    ContextTagType = "schematic"
+	FileContext         ContextTagType = "file"
+	KnowledgeContext    ContextTagType = "knowledge"
 )
 
 type ContextTag struct {
@@ -1133,6 +1353,29 @@ type ContextTag struct {
 	ID   string         `json:"id"`
 }
 
+// ContextTags is stored as a jsonb array.
+type ContextTags []ContextTag
+
+func (c ContextTags) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *ContextTags) Scan(src interface{}) error {
+	switch source := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(source, c)
+	case string:
+		return json.Unmarshal([]byte(source), c)
+	}
+	return errors.New("context tags must be scanned from json")
+}
+
 type ChatRole string
 
 const (
@@ -1143,9 +1386,10 @@ const (
 type ChatMessageStatus string
 
 const (
-	SendingStatus ChatMessageStatus = "sending"
-	SentStatus    ChatMessageStatus = "sent"
-	ErrorStatus   ChatMessageStatus = "error"
+	SendingStatus   ChatMessageStatus = "sending"
+	SentStatus      ChatMessageStatus = "sent"
+	ErrorStatus     ChatMessageStatus = "error"
+	StreamingStatus ChatMessageStatus = "streaming"
 )
 
 type ChatSource string
@@ -1156,15 +1400,48 @@ const (
 )
 
 type ChatMessage struct {
-	ID          string            `json:"id" gorm:"primaryKey"`
-	ChatID      string            `json:"chatId" gorm:"index"`
-	Message     string            `json:"message"`
-	PDFURL      string            `json:"pdf_url,omitempty"`
-	Role        ChatRole          `json:"role"`
-	Timestamp   time.Time         `json:"timestamp"`
-	ContextTags []ContextTag      `json:"contextTags" gorm:"type:jsonb"`
-	Status      ChatMessageStatus `json:"status"`
-	Source      ChatSource        `json:"source"`
+	ID             string            `json:"id" gorm:"primaryKey"`
+	ChatID         string            `json:"chatId" gorm:"index"`
+	Message        string            `json:"message"`
+	PDFURL         string            `json:"pdf_url,omitempty"`
+	Role           ChatRole          `json:"role"`
+	Timestamp      time.Time         `json:"timestamp"`
+	ContextTags    ContextTags       `json:"contextTags" gorm:"type:jsonb"`
+	Status         ChatMessageStatus `json:"status"`
+	Source         ChatSource        `json:"source"`
+	StreamSequence int               `json:"-" gorm:"not null;default:0"`
+	ParentID       string            `json:"parentId,omitempty" gorm:"index"`
+	BranchID       string            `json:"branchId" gorm:"not null;default:main"`
+}
+
+// MainChatBranch is the branch every chat starts on.
+const MainChatBranch = "main"
+
+// ChatBranch is an alternative continuation of a chat, created when a user
+// message is edited or an assistant reply is regenerated. It continues from
+// ParentMessageID, which is empty for a branch that starts a new conversation.
+type ChatBranch struct {
+	ID              string    `json:"id" gorm:"primaryKey"`
+	ChatID          string    `json:"chatId" gorm:"index;not null"`
+	ParentMessageID string    `json:"parentMessageId"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// ChatMessageChunk is a chunk of a streamed reply that arrived before the
+// chunks it follows. It is applied once they arrive.
+type ChatMessageChunk struct {
+	MessageID string    `json:"messageId" gorm:"primaryKey"`
+	Sequence  int       `json:"sequence" gorm:"primaryKey;autoIncrement:false"`
+	Delta     string    `json:"delta" gorm:"type:text"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ChatMessageDelta is a chunk of an assistant reply that is still streaming.
+type ChatMessageDelta struct {
+	MessageID string `json:"messageId"`
+	ChatID    string `json:"chatId"`
+	Sequence  int    `json:"sequence"`
+	Delta     string `json:"delta"`
 }
 
 type ChatStatus string
@@ -1175,12 +1452,41 @@ const (
 )
 
 type Chat struct {
-	ID          string     `json:"id" gorm:"primaryKey"`
-	WorkspaceID string     `json:"workspaceId" gorm:"index"`
-	Title       string     `json:"title"`
-	Status      ChatStatus `json:"status" gorm:"default:active"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
+	ID             string     `json:"id" gorm:"primaryKey"`
+	WorkspaceID    string     `json:"workspaceId" gorm:"index"`
+	Title          string     `json:"title"`
+	Status         ChatStatus `json:"status" gorm:"default:active"`
+	ActiveBranchID string     `json:"activeBranchId" gorm:"not null;default:main"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+
+	// OwnerPubKey is the creator of the chat, empty for chats created
+	// before owners were recorded.
+	OwnerPubKey string         `json:"ownerPubKey" gorm:"index"`
+	Visibility  ChatVisibility `json:"visibility" gorm:"type:varchar(20);not null;default:workspace"`
+}
+
+// ChatVisibility decides who can open a chat. Private chats are only open to
+// their owner, workspace chats to the members of the workspace, and shared
+// chats also to anyone holding an unexpired share link, read only.
+type ChatVisibility string
+
+const (
+	PrivateChat   ChatVisibility = "private"
+	WorkspaceChat ChatVisibility = "workspace"
+	SharedChat    ChatVisibility = "shared"
+)
+
+// ChatShareLink gives read only access to a shared chat until it expires or
+// is revoked. Only the SHA-256 hash of the token is stored.
+type ChatShareLink struct {
+	ID        string     `json:"id" gorm:"primaryKey"`
+	ChatID    string     `json:"chatId" gorm:"index;not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	CreatedBy string     `json:"createdBy"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
 }
 
 type ChatWorkflowStatus struct {
@@ -1192,6 +1498,51 @@ type ChatWorkflowStatus struct {
 	UpdatedAt time.Time `gorm:"type:timestamp;default:current_timestamp" json:"updated_at"`
 }
 
+type ChatEventType string
+
+const (
+	ChatMessageEvent        ChatEventType = "message"
+	ChatMessageDeltaEvent   ChatEventType = "message_delta"
+	ChatWorkflowStatusEvent ChatEventType = "workflow_status"
+	ChatArtifactEvent       ChatEventType = "artifact"
+)
+
+// ChatEvent is an append only log of changes to a chat. Its ID is the SSE
+// event id, so browsers can resume a stream without gaps.
+type ChatEvent struct {
+	ID        uint64        `gorm:"primaryKey;autoIncrement" json:"id"`
+	ChatID    string        `gorm:"type:varchar(255);index;not null" json:"chat_id"`
+	Type      ChatEventType `gorm:"type:varchar(50);not null" json:"type"`
+	Payload   PropertyMap   `gorm:"type:jsonb;not null;default:'{}'::jsonb" json:"payload"`
+	CreatedAt time.Time     `gorm:"type:timestamp;default:current_timestamp" json:"created_at"`
+}
+
+type JobRunStatus string
+
+const (
+	JobRunRunning JobRunStatus = "running"
+	JobRunSuccess JobRunStatus = "success"
+	JobRunFailed  JobRunStatus = "failed"
+)
+
+type JobRun struct {
+	ID         uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	JobName    string       `gorm:"type:varchar(255);index;not null" json:"job_name"`
+	Holder     string       `gorm:"type:varchar(255)" json:"holder"`
+	Status     JobRunStatus `gorm:"type:varchar(50);not null" json:"status"`
+	Error      string       `gorm:"type:text" json:"error,omitempty"`
+	StartedAt  time.Time    `gorm:"type:timestamp;not null" json:"started_at"`
+	FinishedAt *time.Time   `gorm:"type:timestamp" json:"finished_at"`
+}
+
+type JobStatus struct {
+	JobName       string       `json:"job_name"`
+	LastRunAt     time.Time    `json:"last_run_at"`
+	LastStatus    JobRunStatus `json:"last_status"`
+	LastHolder    string       `json:"last_holder"`
+	LastSuccessAt *time.Time   `json:"last_success_at"`
+}
+
 type ProofOfWorkStatus string
 
 const (
@@ -1303,10 +1654,101 @@ type FileAsset struct {
 	Status         FileStatus `json:"status" gorm:"type:varchar(20);default:'active'"`
 	UploadedBy     string     `json:"uploadedBy"`
 	StoragePath    string     `json:"storagePath"`
+	StorageKey     string     `json:"storageKey,omitempty"`
 	WorkspaceID    string     `json:"workspaceId" gorm:"index"`
 	CreatedAt      time.Time  `json:"createdAt"`
 	UpdatedAt      time.Time  `json:"updatedAt"`
 	DeletedAt      *time.Time `json:"deletedAt,omitempty" gorm:"index"`
+
+	// ExtractionStatus tracks indexing of the text of PDF, Markdown and
+	// plain text files. It is empty for other files.
+	ExtractionStatus ExtractionStatus `json:"extractionStatus,omitempty" gorm:"type:varchar(20)"`
+}
+
+type ExtractionStatus string
+
+const (
+	PendingExtraction ExtractionStatus = "pending"
+	IndexedExtraction ExtractionStatus = "indexed"
+	FailedExtraction  ExtractionStatus = "failed"
+)
+
+// FileChunk is a piece of the text extracted from a file, searchable within
+// the workspace of the file.
+type FileChunk struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	FileID      uint      `json:"fileId" gorm:"index"`
+	WorkspaceID string    `json:"workspaceId" gorm:"index"`
+	ChunkIndex  int       `json:"chunkIndex"`
+	Content     string    `json:"content" gorm:"type:text"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// FileChunkMatch is a chunk found by a search, with the file it came from.
+type FileChunkMatch struct {
+	FileChunk
+	OriginFilename string  `json:"originFilename"`
+	Rank           float64 `json:"rank"`
+}
+
+// WorkspaceStorageQuota overrides the default upload quota of a workspace.
+// A quota of zero means the workspace has no limit.
+type WorkspaceStorageQuota struct {
+	WorkspaceID string    `json:"workspaceId" gorm:"primaryKey"`
+	QuotaBytes  int64     `json:"quotaBytes"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type KnowledgeSourceType string
+
+const (
+	KnowledgeMission      KnowledgeSourceType = "mission"
+	KnowledgeTactics      KnowledgeSourceType = "tactics"
+	KnowledgeFeatureBrief KnowledgeSourceType = "featureBrief"
+	KnowledgeRequirements KnowledgeSourceType = "requirements"
+	KnowledgeArchitecture KnowledgeSourceType = "architecture"
+	KnowledgePhaseDesign  KnowledgeSourceType = "phaseDesign"
+	KnowledgeStory        KnowledgeSourceType = "story"
+	KnowledgeSnippet      KnowledgeSourceType = "snippet"
+)
+
+// KnowledgeItem is one piece of workspace knowledge, such as a feature brief
+// or a snippet, indexed for chat and ticket review context. SourceID is the
+// UUID or ID of the record it was taken from.
+type KnowledgeItem struct {
+	ID             uint                `json:"id" gorm:"primaryKey;autoIncrement"`
+	WorkspaceUUID  string              `json:"workspaceUuid" gorm:"not null;uniqueIndex:idx_knowledge_items_source"`
+	SourceType     KnowledgeSourceType `json:"sourceType" gorm:"type:varchar(32);not null;uniqueIndex:idx_knowledge_items_source"`
+	SourceID       string              `json:"sourceId" gorm:"not null;uniqueIndex:idx_knowledge_items_source"`
+	Title          string              `json:"title"`
+	Content        string              `json:"content" gorm:"type:text"`
+	ContentHash    string              `json:"-"`
+	Embedding      Vector              `json:"-" gorm:"type:jsonb"`
+	EmbeddingModel string              `json:"-"`
+	UpdatedAt      time.Time           `json:"updatedAt"`
+}
+
+// Vector is an embedding, stored as a jsonb array.
+type Vector []float32
+
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func (v *Vector) Scan(src interface{}) error {
+	switch source := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(source, v)
+	case string:
+		return json.Unmarshal([]byte(source), v)
+	}
+	return errors.New("vector must be scanned from json")
 }
 
 type ListFileAssetsParams struct {
@@ -1343,12 +1785,126 @@ type TicketPlan struct {
 	UpdatedBy     string            `gorm:"type:varchar(255)" json:"updated_by"`
 	CreatedAt     time.Time         `gorm:"type:timestamp;default:current_timestamp" json:"created_at"`
 	UpdatedAt     time.Time         `gorm:"type:timestamp;default:current_timestamp" json:"updated_at"`
+
+	// StubTickets are the tickets the plan proposes, materialized on approval.
+	StubTickets PlanStubTickets `gorm:"type:jsonb;not null;default:'[]'" json:"stub_tickets"`
+}
+
+// TicketPlanTicket records the ticket group, or the bounty, an approved plan
+// made out of one of its stub tickets.
+type TicketPlanTicket struct {
+	ID          uuid.UUID  `gorm:"primaryKey;type:uuid" json:"id"`
+	PlanUUID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_ticket_plan_tickets_stub" json:"plan_uuid"`
+	StubKey     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_ticket_plan_tickets_stub" json:"stub_key"`
+	TicketGroup *uuid.UUID `gorm:"type:uuid;index" json:"ticket_group,omitempty"`
+	BountyID    *uint      `json:"bounty_id,omitempty"`
+	PlanVersion int        `gorm:"not null;default:0" json:"plan_version"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type CommentTargetType string
+
+const (
+	TicketCommentTarget     CommentTargetType = "ticket"
+	TicketPlanCommentTarget CommentTargetType = "ticket_plan"
+)
+
+// TicketComment is a Markdown comment on a ticket group or a ticket plan.
+// Replies point to the first comment of their thread, which holds the
+// resolved state of the thread.
+type TicketComment struct {
+	ID           uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
+	TargetType   CommentTargetType `gorm:"type:varchar(20);not null;index:idx_ticket_comments_target" json:"target_type"`
+	TargetUUID   string            `gorm:"type:varchar(255);not null;index:idx_ticket_comments_target" json:"target_uuid"`
+	ParentID     *uuid.UUID        `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	Body         string            `gorm:"type:text;not null" json:"body"`
+	AuthorPubKey string            `gorm:"type:varchar(255);not null" json:"author_pubkey"`
+	AuthorAlias  string            `gorm:"type:varchar(255)" json:"author_alias"`
+	Mentions     pq.StringArray    `gorm:"type:text[];default:'{}'" json:"mentions"`
+	Resolved     bool              `gorm:"default:false" json:"resolved"`
+	ResolvedBy   string            `gorm:"type:varchar(255)" json:"resolved_by,omitempty"`
+	ResolvedAt   *time.Time        `json:"resolved_at,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+type TicketCommentThread struct {
+	TicketComment
+	Replies []TicketComment `json:"replies"`
+}
+
+// TicketCommentCount counts the comments of a target and its threads that
+// are not resolved.
+type TicketCommentCount struct {
+	Total      int `json:"total"`
+	Unresolved int `json:"unresolved"`
+}
+
+type TemplateKind string
+
+const (
+	TicketTemplateKind TemplateKind = "ticket"
+	BountyTemplateKind TemplateKind = "bounty"
+)
+
+// WorkspaceTemplate holds the defaults of new tickets or bounties of a
+// workspace. Title and Description may contain {{placeholders}}.
+type WorkspaceTemplate struct {
+	ID                 uuid.UUID              `gorm:"primaryKey;type:uuid" json:"id"`
+	WorkspaceUuid      string                 `gorm:"type:varchar(255);index;not null" json:"workspace_uuid"`
+	Kind               TemplateKind           `gorm:"type:varchar(20);not null" json:"kind"`
+	Name               string                 `gorm:"type:varchar(255);not null" json:"name"`
+	Title              string                 `gorm:"type:text" json:"title"`
+	Description        string                 `gorm:"type:text" json:"description"`
+	Category           *Category              `gorm:"type:varchar(50)" json:"category,omitempty"`
+	Amount             *int64                 `gorm:"type:bigint" json:"amount,omitempty"`
+	BountyType         string                 `gorm:"type:varchar(100)" json:"bounty_type,omitempty"`
+	CodingLanguages    pq.StringArray         `gorm:"type:text[];default:'{}'" json:"coding_languages"`
+	AcceptanceCriteria pq.StringArray         `gorm:"type:text[];default:'{}'" json:"acceptance_criteria"`
+	AccessRestriction  *AccessRestrictionType `gorm:"type:varchar(20)" json:"access_restriction,omitempty"`
+	IsDefault          bool                   `gorm:"default:false" json:"is_default"`
+	CreatedBy          string                 `gorm:"type:varchar(255)" json:"created_by"`
+	CreatedAt          time.Time              `json:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at"`
+	// IsBuiltin marks the template used when a workspace has no default.
+	IsBuiltin bool `gorm:"-" json:"is_builtin,omitempty"`
 }
 
 type StubTicket struct {
 	TicketName        string `json:"ticketName"`
 	TicketDescription string `json:"ticketDescription"`
 	Reasoning         string `json:"reasoning"`
+	// Key identifies the stub across revisions of a plan; the lowercased
+	// name is used when it is empty.
+	Key string `json:"key,omitempty"`
+	// DependsOn lists the keys, or names, of the stubs blocking this one.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Amount is the bounty price in sats when the stub becomes a bounty.
+	Amount int64 `json:"amount,omitempty"`
+}
+
+// PlanStubTickets is stored as a jsonb array.
+type PlanStubTickets []StubTicket
+
+func (s PlanStubTickets) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *PlanStubTickets) Scan(src interface{}) error {
+	switch source := src.(type) {
+	case []byte:
+		return json.Unmarshal(source, s)
+	case string:
+		return json.Unmarshal([]byte(source), s)
+	case nil:
+		*s = PlanStubTickets{}
+		return nil
+	}
+	return errors.New("stub tickets must be scanned from json")
 }
 
 type PhasePlan struct {
@@ -1364,6 +1920,8 @@ type TicketPlanReviewRequest struct {
 	} `json:"value"`
 	RequestUUID     string `json:"requestUUID"`
 	SourceWebsocket string `json:"sourceWebsocket"`
+	// TicketPlanUUID names the plan the stub tickets are stored on.
+	TicketPlanUUID string `json:"ticketPlanUUID,omitempty"`
 }
 
 type TicketPlanReviewResponse struct {
@@ -1429,11 +1987,13 @@ type QuickBountiesResponse struct {
 }
 
 type QuickTicketItem struct {
-	TicketUUID    uuid.UUID    `json:"ticketUUID"`
-	TicketTitle   string       `json:"ticketTitle"`
-	Status        BountyStatus `json:"status"`
-	AssignedAlias *string      `json:"assignedAlias"`
-	PhaseID       *string      `json:"phaseID"`
+	TicketUUID             uuid.UUID    `json:"ticketUUID"`
+	TicketTitle            string       `json:"ticketTitle"`
+	Status                 BountyStatus `json:"status"`
+	AssignedAlias          *string      `json:"assignedAlias"`
+	PhaseID                *string      `json:"phaseID"`
+	CommentCount           int          `json:"commentCount"`
+	UnresolvedCommentCount int          `json:"unresolvedCommentCount"`
 }
 
 type QuickTicketsResponse struct {
@@ -1454,10 +2014,11 @@ type NodeListResponse struct {
 type ArtifactType string
 
 const (
-	TextArtifact   ArtifactType = "text"
-	VisualArtifact ArtifactType = "visual"
-	ActionArtifact ArtifactType = "action"
-	SSEArtifact    ArtifactType = "sse_connection"
+	TextArtifact         ArtifactType = "text"
+	VisualArtifact       ArtifactType = "visual"
+	ActionArtifact       ArtifactType = "action"
+	SSEArtifact          ArtifactType = "sse_connection"
+	ActionResultArtifact ArtifactType = "action_result"
 )
 
 type Artifact struct {
@@ -1491,10 +2052,49 @@ type ActionContent struct {
 }
 
 type Option struct {
-	ActionType     string `json:"action_type"`
-	OptionLabel    string `json:"option_label"`
-	OptionResponse string `json:"option_response"`
-	Webhook        string `json:"webhook"`
+	ActionType     string      `json:"action_type"`
+	OptionLabel    string      `json:"option_label"`
+	OptionResponse string      `json:"option_response"`
+	Webhook        string      `json:"webhook"`
+	Kind           ActionKind  `json:"kind,omitempty"`
+	Params         PropertyMap `json:"params,omitempty"`
+}
+
+// ActionKind is what the server does when an action option is chosen.
+// Options without a kind but with a webhook are called as webhooks.
+type ActionKind string
+
+const (
+	CreateTicketAction       ActionKind = "create_ticket"
+	CreateBountyAction       ActionKind = "create_bounty"
+	UpdateFeatureBriefAction ActionKind = "update_feature_brief"
+	OpenCodespaceAction      ActionKind = "open_codespace"
+	CallWebhookAction        ActionKind = "call_webhook"
+)
+
+type ActionExecutionStatus string
+
+const (
+	ActionRunning   ActionExecutionStatus = "running"
+	ActionSucceeded ActionExecutionStatus = "succeeded"
+	ActionFailed    ActionExecutionStatus = "failed"
+)
+
+// ActionExecution records the execution of an option of an action
+// artifact. An option runs at most once; failed executions can be retried.
+type ActionExecution struct {
+	ID               uuid.UUID             `json:"id" gorm:"type:uuid;primaryKey"`
+	ArtifactID       uuid.UUID             `json:"artifact_id" gorm:"type:uuid;not null;uniqueIndex:idx_action_executions_option"`
+	OptionIndex      int                   `json:"option_index" gorm:"not null;uniqueIndex:idx_action_executions_option"`
+	ChatID           string                `json:"chat_id" gorm:"index"`
+	Kind             ActionKind            `json:"kind" gorm:"type:varchar(50);not null"`
+	Status           ActionExecutionStatus `json:"status" gorm:"type:varchar(20);not null"`
+	Result           PropertyMap           `json:"result" gorm:"type:jsonb;not null;default:'{}'::jsonb"`
+	Error            string                `json:"error,omitempty" gorm:"type:text"`
+	ResultArtifactID *uuid.UUID            `json:"result_artifact_id,omitempty" gorm:"type:uuid"`
+	ExecutedBy       string                `json:"executed_by"`
+	CreatedAt        time.Time             `json:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at"`
 }
 
 type FeatureCall struct {
@@ -1580,6 +2180,28 @@ type SSEMessageLog struct {
 	From      string           `gorm:"not null" json:"from"`
 	To        string           `gorm:"not null" json:"to"`
 	Status    SSEMessageStatus `gorm:"type:varchar(10);default:'new'" json:"status"`
+	EventID   string           `gorm:"type:varchar(255);index" json:"event_id,omitempty"`
+}
+
+type SSEClientStatus string
+
+const (
+	SSEClientConnecting SSEClientStatus = "connecting"
+	SSEClientConnected  SSEClientStatus = "connected"
+	SSEClientRetrying   SSEClientStatus = "retrying"
+	SSEClientFailed     SSEClientStatus = "failed"
+	SSEClientStopped    SSEClientStatus = "stopped"
+)
+
+type SSEClientState struct {
+	ChatID      string          `gorm:"primaryKey;type:varchar(255)" json:"chat_id"`
+	URL         string          `gorm:"primaryKey;type:text" json:"url"`
+	WebhookURL  string          `gorm:"type:text" json:"webhook_url"`
+	LastEventID string          `gorm:"type:varchar(255)" json:"last_event_id"`
+	Status      SSEClientStatus `gorm:"type:varchar(20);default:'connecting'" json:"status"`
+	RetryCount  int             `gorm:"not null;default:0" json:"retry_count"`
+	LastError   string          `gorm:"type:text" json:"last_error,omitempty"`
+	UpdatedAt   time.Time       `gorm:"type:timestamp;default:current_timestamp" json:"updated_at"`
 }
 
 type CodeSpaceMap struct {
@@ -1618,6 +2240,19 @@ type BountyStake struct {
 	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	AppliedAt time.Time `gorm:"type:timestamp;default:current_timestamp" json:"applied_at"`
+}
+
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
 func (Person) TableName() string {
 	return "people"
 }
@@ -1662,6 +2297,10 @@ func (WfRequest) TableName() string {
 	return "wf_requests"
 }
 
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
 // PropertyMap ...
 type PropertyMap map[string]interface{}
 
@@ -48,52 +48,15 @@ func InitTestDB() {
         END $$;
     `)
 
-	// migrate table changes
-	db.AutoMigrate(&Tribe{})
-	db.AutoMigrate(&Person{})
-	db.AutoMigrate(&Channel{})
-	db.AutoMigrate(&LeaderBoard{})
-	db.AutoMigrate(&ConnectionCodes{})
-	db.AutoMigrate(&BountyRoles{})
-	db.AutoMigrate(&UserInvoiceData{})
-	db.AutoMigrate(&WorkspaceRepositories{})
-	db.AutoMigrate(&WorkspaceFeatures{})
-	db.AutoMigrate(&FeaturePhase{})
-	db.AutoMigrate(&FeatureStory{})
-	db.AutoMigrate(&NewBounty{})
-	db.AutoMigrate(&BudgetHistory{})
-	db.AutoMigrate(&NewPaymentHistory{})
-	db.AutoMigrate(&NewInvoiceList{})
-	db.AutoMigrate(&NewBountyBudget{})
-	db.AutoMigrate(&Workspace{})
-	db.AutoMigrate(&WorkspaceUsers{})
-	db.AutoMigrate(&WorkspaceUserRoles{})
-	db.AutoMigrate(&Bot{})
-	db.AutoMigrate(&WfRequest{})
-	db.AutoMigrate(&WfProcessingMap{})
-	db.AutoMigrate(&Tickets{})
-	db.AutoMigrate(&ChatMessage{})
-	db.AutoMigrate(&Chat{})
-	db.AutoMigrate(&WorkspaceCodeGraph{})
-	db.AutoMigrate(&FeatureFlag{})
-	db.AutoMigrate(&Bounty{})
-	db.AutoMigrate(&Notification{})
-	db.AutoMigrate(&BountyTiming{})
-	db.AutoMigrate(&FileAsset{})
-	db.AutoMigrate(&TextSnippet{})
-	db.AutoMigrate(&ConnectionCodesList{})
-	db.AutoMigrate(&TicketPlan{})
-	db.AutoMigrate(&Activity{})
-	db.AutoMigrate(&Artifact{})
-	db.AutoMigrate(&FeatureCall{})
-	db.AutoMigrate(&ChatWorkflow{})
-	db.AutoMigrate(&Skill{})
-	db.AutoMigrate(&SkillInstall{})
-	db.AutoMigrate(&SSEMessageLog{})
-	db.AutoMigrate(&CodeSpaceMap{})
-	db.AutoMigrate(&BountyStake{})
-	db.AutoMigrate(&ChatWorkflowStatus{})
-	
+	// the test schema comes from the same migrations as production
+	migrator, err := TestDB.NewMigrator()
+	if err != nil {
+		panic(err)
+	}
+	if _, err := migrator.Up(0); err != nil {
+		panic(err)
+	}
+
 	people := TestDB.GetAllPeople()
 	for _, p := range people {
 		if p.Uuid == "" {
@@ -0,0 +1,228 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_][A-Za-z0-9_.-]*)`)
+
+// ParseMentions returns the lowercased names mentioned as @name in a
+// comment, in order and without repeats. Mentions inside code are ignored.
+func ParseMentions(body string) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for i, part := range strings.Split(body, "`") {
+		// odd parts are inside inline code or code fences
+		if i%2 == 1 {
+			continue
+		}
+		for _, match := range mentionPattern.FindAllStringSubmatch(part, -1) {
+			name := strings.ToLower(strings.TrimRight(match[1], ".-"))
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// BuildTicketCommentThreads groups comments, oldest first, into threads of
+// a first comment and its replies.
+func BuildTicketCommentThreads(comments []TicketComment) []TicketCommentThread {
+	index := map[uuid.UUID]int{}
+	threads := []TicketCommentThread{}
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			index[comment.ID] = len(threads)
+			threads = append(threads, TicketCommentThread{TicketComment: comment, Replies: []TicketComment{}})
+		}
+	}
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			continue
+		}
+		if i, ok := index[*comment.ParentID]; ok {
+			threads[i].Replies = append(threads[i].Replies, comment)
+		}
+	}
+	return threads
+}
+
+// GetPeopleByMentions finds the people whose unique name or alias matches
+// one of the mentioned names, ignoring case.
+func (db database) GetPeopleByMentions(names []string) ([]Person, error) {
+	people := []Person{}
+	if len(names) == 0 {
+		return people, nil
+	}
+	err := db.db.
+		Where("(LOWER(unique_name) IN ? OR LOWER(owner_alias) IN ?) AND (deleted = 'f' OR deleted is null)", names, names).
+		Find(&people).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch mentioned people: %w", err)
+	}
+	return people, nil
+}
+
+// CreateTicketComment stores a comment. A reply to a reply joins the thread
+// of its parent.
+func (db database) CreateTicketComment(comment *TicketComment) (TicketComment, error) {
+	if comment.TargetType != TicketCommentTarget && comment.TargetType != TicketPlanCommentTarget {
+		return TicketComment{}, errors.New("invalid comment target type")
+	}
+	if comment.TargetUUID == "" {
+		return TicketComment{}, errors.New("comment target is required")
+	}
+	if strings.TrimSpace(comment.Body) == "" {
+		return TicketComment{}, errors.New("comment body is required")
+	}
+	if comment.AuthorPubKey == "" {
+		return TicketComment{}, errors.New("comment author is required")
+	}
+
+	if comment.ParentID != nil {
+		parent, err := db.GetTicketComment(*comment.ParentID)
+		if err != nil {
+			return TicketComment{}, err
+		}
+		if parent.TargetType != comment.TargetType || parent.TargetUUID != comment.TargetUUID {
+			return TicketComment{}, errors.New("parent comment belongs to another target")
+		}
+		if parent.ParentID != nil {
+			comment.ParentID = parent.ParentID
+		}
+	}
+
+	now := time.Now()
+	comment.ID = uuid.New()
+	comment.Resolved = false
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+	if comment.Mentions == nil {
+		comment.Mentions = []string{}
+	}
+
+	if err := db.db.Create(comment).Error; err != nil {
+		return TicketComment{}, fmt.Errorf("failed to create comment: %w", err)
+	}
+	return *comment, nil
+}
+
+func (db database) GetTicketComment(id uuid.UUID) (TicketComment, error) {
+	var comment TicketComment
+	if err := db.db.Where("id = ?", id).First(&comment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return TicketComment{}, errors.New("comment not found")
+		}
+		return TicketComment{}, fmt.Errorf("failed to fetch comment: %w", err)
+	}
+	return comment, nil
+}
+
+func (db database) GetTicketComments(targetType CommentTargetType, targetUUID string) ([]TicketComment, error) {
+	comments := []TicketComment{}
+	err := db.db.
+		Where("target_type = ? AND target_uuid = ?", targetType, targetUUID).
+		Order("created_at ASC").
+		Find(&comments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %w", err)
+	}
+	return comments, nil
+}
+
+func (db database) UpdateTicketCommentBody(id uuid.UUID, body string, mentions []string) (TicketComment, error) {
+	if strings.TrimSpace(body) == "" {
+		return TicketComment{}, errors.New("comment body is required")
+	}
+	if mentions == nil {
+		mentions = []string{}
+	}
+	err := db.db.Model(&TicketComment{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"body":       body,
+		"mentions":   pq.StringArray(mentions),
+		"updated_at": time.Now(),
+	}).Error
+	if err != nil {
+		return TicketComment{}, fmt.Errorf("failed to update comment: %w", err)
+	}
+	return db.GetTicketComment(id)
+}
+
+// ResolveTicketCommentThread marks the thread of a comment as resolved or
+// reopens it.
+func (db database) ResolveTicketCommentThread(id uuid.UUID, resolved bool, resolvedBy string) (TicketComment, error) {
+	comment, err := db.GetTicketComment(id)
+	if err != nil {
+		return TicketComment{}, err
+	}
+	if comment.ParentID != nil {
+		id = *comment.ParentID
+	}
+
+	updates := map[string]interface{}{
+		"resolved":    resolved,
+		"resolved_by": "",
+		"resolved_at": nil,
+		"updated_at":  time.Now(),
+	}
+	if resolved {
+		now := time.Now()
+		updates["resolved_by"] = resolvedBy
+		updates["resolved_at"] = &now
+	}
+	if err := db.db.Model(&TicketComment{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return TicketComment{}, fmt.Errorf("failed to resolve comment thread: %w", err)
+	}
+	return db.GetTicketComment(id)
+}
+
+// DeleteTicketComment deletes a comment, and its replies when it starts a
+// thread.
+func (db database) DeleteTicketComment(id uuid.UUID) error {
+	result := db.db.Where("id = ? OR parent_id = ?", id, id).Delete(&TicketComment{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete comment: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("comment not found")
+	}
+	return nil
+}
+
+// GetTicketCommentCounts counts the comments and unresolved threads of
+// several targets of a type, by target.
+func (db database) GetTicketCommentCounts(targetType CommentTargetType, targetUUIDs []string) (map[string]TicketCommentCount, error) {
+	counts := map[string]TicketCommentCount{}
+	if len(targetUUIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		TargetUUID string
+		Total      int
+		Unresolved int
+	}
+	err := db.db.Model(&TicketComment{}).
+		Select("target_uuid, COUNT(*) AS total, COUNT(*) FILTER (WHERE parent_id IS NULL AND NOT resolved) AS unresolved").
+		Where("target_type = ? AND target_uuid IN ?", targetType, targetUUIDs).
+		Group("target_uuid").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count comments: %w", err)
+	}
+	for _, row := range rows {
+		counts[row.TargetUUID] = TicketCommentCount{Total: row.Total, Unresolved: row.Unresolved}
+	}
+	return counts, nil
+}
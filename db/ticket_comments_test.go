@@ -0,0 +1,45 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "Single Mention", body: "@alice can you look?", want: []string{"alice"}},
+		{name: "Case And Repeats", body: "@Alice and @bob, then @alice again.", want: []string{"alice", "bob"}},
+		{name: "Trailing Punctuation", body: "thanks @carol.", want: []string{"carol"}},
+		{name: "Email Is Not A Mention", body: "mail dave@example.com", want: []string{}},
+		{name: "Inline Code Is Ignored", body: "run `@decorator` with @erin", want: []string{"erin"}},
+		{name: "Code Fence Is Ignored", body: "```\n@frank\n```\n@grace", want: []string{"grace"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseMentions(tt.body))
+		})
+	}
+}
+
+func TestBuildTicketCommentThreads(t *testing.T) {
+	first := TicketComment{ID: uuid.New(), Body: "first"}
+	second := TicketComment{ID: uuid.New(), Body: "second"}
+	reply := TicketComment{ID: uuid.New(), ParentID: &first.ID, Body: "reply"}
+	orphan := TicketComment{ID: uuid.New(), ParentID: func() *uuid.UUID { id := uuid.New(); return &id }(), Body: "orphan"}
+
+	threads := BuildTicketCommentThreads([]TicketComment{first, reply, second, orphan})
+
+	require.Len(t, threads, 2)
+	assert.Equal(t, "first", threads[0].Body)
+	require.Len(t, threads[0].Replies, 1)
+	assert.Equal(t, "reply", threads[0].Replies[0].Body)
+	assert.Empty(t, threads[1].Replies)
+}
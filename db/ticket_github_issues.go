@@ -0,0 +1,102 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// CreateTicketGithubIssue links a ticket group to a GitHub issue. Owner and
+// repo are stored lowercased, the way GitHub matches them.
+func (db database) CreateTicketGithubIssue(link *TicketGithubIssue) (TicketGithubIssue, error) {
+	if link.TicketGroup == uuid.Nil {
+		return TicketGithubIssue{}, errors.New("ticket group is required")
+	}
+	if link.Owner == "" || link.Repo == "" || link.Number < 1 {
+		return TicketGithubIssue{}, errors.New("owner, repo and issue number are required")
+	}
+	if link.ID == uuid.Nil {
+		link.ID = uuid.New()
+	}
+	link.Owner = strings.ToLower(link.Owner)
+	link.Repo = strings.ToLower(link.Repo)
+	if link.Labels == nil {
+		link.Labels = pq.StringArray{}
+	}
+	now := time.Now()
+	link.CreatedAt = now
+	if link.LastSyncedAt.IsZero() {
+		link.LastSyncedAt = now
+	}
+
+	if err := db.db.Create(link).Error; err != nil {
+		return TicketGithubIssue{}, fmt.Errorf("failed to link github issue: %w", err)
+	}
+	return *link, nil
+}
+
+// GetTicketGithubIssuesByRepo lists the issues of a repository linked to
+// tickets, across workspaces.
+func (db database) GetTicketGithubIssuesByRepo(owner, repo string) ([]TicketGithubIssue, error) {
+	var links []TicketGithubIssue
+	err := db.db.Where("owner = ? AND repo = ?", strings.ToLower(owner), strings.ToLower(repo)).
+		Order("number ASC").
+		Find(&links).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch github issue links: %w", err)
+	}
+	return links, nil
+}
+
+func (db database) GetAllTicketGithubIssues() ([]TicketGithubIssue, error) {
+	var links []TicketGithubIssue
+	if err := db.db.Order("owner ASC, repo ASC, number ASC").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch github issue links: %w", err)
+	}
+	return links, nil
+}
+
+// UpdateTicketGithubIssue saves the state, labels and sync time of a link.
+func (db database) UpdateTicketGithubIssue(link *TicketGithubIssue) error {
+	if link.Labels == nil {
+		link.Labels = pq.StringArray{}
+	}
+	result := db.db.Model(&TicketGithubIssue{}).
+		Where("id = ?", link.ID).
+		Updates(map[string]interface{}{
+			"state":          link.State,
+			"labels":         link.Labels,
+			"url":            link.URL,
+			"last_synced_at": link.LastSyncedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update github issue link: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("github issue link not found")
+	}
+	return nil
+}
+
+func (db database) DeleteTicketGithubIssue(id uuid.UUID) error {
+	if err := db.db.Where("id = ?", id).Delete(&TicketGithubIssue{}).Error; err != nil {
+		return fmt.Errorf("failed to delete github issue link: %w", err)
+	}
+	return nil
+}
+
+// MarkTicketGithubIssuesSynced moves the sync time of links whose issue had
+// not changed when their repository was polled.
+func (db database) MarkTicketGithubIssuesSynced(ids []uuid.UUID, syncedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := db.db.Model(&TicketGithubIssue{}).Where("id IN ?", ids).Update("last_synced_at", syncedAt).Error; err != nil {
+		return fmt.Errorf("failed to mark github issue links synced: %w", err)
+	}
+	return nil
+}
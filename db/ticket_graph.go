@@ -0,0 +1,228 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrDependencyCycle is returned when ticket dependencies would form a cycle.
+var ErrDependencyCycle = errors.New("ticket dependencies form a cycle")
+
+// TicketGraphNode is the latest version of a ticket group in a phase.
+type TicketGraphNode struct {
+	TicketGroup string   `json:"ticket_group"`
+	Ticket      Tickets  `json:"ticket"`
+	DependsOn   []string `json:"depends_on"`
+	Blocks      []string `json:"blocks"`
+	// Depth is the length of the longest chain of blockers before the ticket.
+	Depth int `json:"depth"`
+	// Blocked is set while a blocker is not completed.
+	Blocked bool `json:"blocked"`
+}
+
+type TicketGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// TicketGraph is the dependency DAG of the tickets of a phase. Order lists
+// ticket groups so that every ticket comes after its blockers, keeping the
+// manual sequence where dependencies allow. CriticalPath is the longest chain
+// of dependent tickets.
+type TicketGraph struct {
+	Nodes        []TicketGraphNode `json:"nodes"`
+	Edges        []TicketGraphEdge `json:"edges"`
+	Order        []string          `json:"order"`
+	CriticalPath []string          `json:"critical_path"`
+}
+
+// TicketGroupID is the ticket group of a ticket, or its UUID for tickets
+// without a group.
+func TicketGroupID(ticket Tickets) string {
+	if ticket.TicketGroup != nil {
+		return ticket.TicketGroup.String()
+	}
+	return ticket.UUID.String()
+}
+
+// LatestTickets keeps the latest version of every ticket group, in the
+// order the groups first appear.
+func LatestTickets(tickets []Tickets) []Tickets {
+	index := map[string]int{}
+	latest := []Tickets{}
+	for _, ticket := range tickets {
+		group := TicketGroupID(ticket)
+		i, ok := index[group]
+		if !ok {
+			index[group] = len(latest)
+			latest = append(latest, ticket)
+			continue
+		}
+		if ticket.Version > latest[i].Version {
+			latest[i] = ticket
+		}
+	}
+	return latest
+}
+
+// ValidateTicketDependencies checks that a ticket group of a phase may
+// depend on dependsOn: only other tickets of the same phase, without
+// forming a cycle. phaseTickets are the latest tickets of the phase.
+func ValidateTicketDependencies(ticketGroup string, dependsOn []string, phaseTickets []Tickets) error {
+	deps := map[string][]string{}
+	for _, ticket := range phaseTickets {
+		deps[TicketGroupID(ticket)] = ticket.DependsOn
+	}
+
+	for _, dep := range dependsOn {
+		if dep == ticketGroup {
+			return errors.New("a ticket cannot depend on itself")
+		}
+		if _, ok := deps[dep]; !ok {
+			return fmt.Errorf("ticket %s is not in the same phase", dep)
+		}
+	}
+	deps[ticketGroup] = dependsOn
+
+	// a cycle through the ticket leads from one of its blockers back to it
+	visited := map[string]bool{}
+	var path []string
+	var reaches func(group string) bool
+	reaches = func(group string) bool {
+		if group == ticketGroup {
+			return true
+		}
+		if visited[group] {
+			return false
+		}
+		visited[group] = true
+		path = append(path, group)
+		for _, dep := range deps[group] {
+			if reaches(dep) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	for _, dep := range dependsOn {
+		path = path[:0]
+		if reaches(dep) {
+			cycle := append([]string{ticketGroup}, path...)
+			cycle = append(cycle, ticketGroup)
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+		}
+	}
+	return nil
+}
+
+// BuildTicketGraph builds the dependency graph of the latest tickets of a
+// phase. Dependencies on tickets outside the phase are ignored.
+func BuildTicketGraph(tickets []Tickets) (TicketGraph, error) {
+	latest := LatestTickets(tickets)
+	nodes := make(map[string]*TicketGraphNode, len(latest))
+	for _, ticket := range latest {
+		nodes[TicketGroupID(ticket)] = &TicketGraphNode{
+			TicketGroup: TicketGroupID(ticket),
+			Ticket:      ticket,
+			DependsOn:   []string{},
+			Blocks:      []string{},
+		}
+	}
+
+	graph := TicketGraph{
+		Nodes:        []TicketGraphNode{},
+		Edges:        []TicketGraphEdge{},
+		Order:        []string{},
+		CriticalPath: []string{},
+	}
+	indegree := map[string]int{}
+	for _, ticket := range latest {
+		group := TicketGroupID(ticket)
+		seen := map[string]bool{}
+		for _, dep := range ticket.DependsOn {
+			blocker, ok := nodes[dep]
+			if !ok || dep == group || seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			nodes[group].DependsOn = append(nodes[group].DependsOn, dep)
+			blocker.Blocks = append(blocker.Blocks, group)
+			graph.Edges = append(graph.Edges, TicketGraphEdge{From: dep, To: group})
+			indegree[group]++
+			if blocker.Ticket.Status != CompletedTicket {
+				nodes[group].Blocked = true
+			}
+		}
+	}
+
+	// Kahn's algorithm, taking ready tickets by sequence
+	before := func(a, b string) bool {
+		ta, tb := nodes[a].Ticket, nodes[b].Ticket
+		if ta.Sequence != tb.Sequence {
+			return ta.Sequence < tb.Sequence
+		}
+		return a < b
+	}
+	ready := []string{}
+	for group := range nodes {
+		if indegree[group] == 0 {
+			ready = append(ready, group)
+		}
+	}
+	previous := map[string]string{}
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return before(ready[i], ready[j]) })
+		group := ready[0]
+		ready = ready[1:]
+		graph.Order = append(graph.Order, group)
+
+		node := nodes[group]
+		for _, next := range node.Blocks {
+			if node.Depth+1 > nodes[next].Depth {
+				nodes[next].Depth = node.Depth + 1
+				previous[next] = group
+			}
+			indegree[next]--
+			if indegree[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+	if len(graph.Order) != len(nodes) {
+		return TicketGraph{}, ErrDependencyCycle
+	}
+
+	end := ""
+	for _, group := range graph.Order {
+		if end == "" || nodes[group].Depth > nodes[end].Depth {
+			end = group
+		}
+	}
+	for group := end; group != ""; group = previous[group] {
+		graph.CriticalPath = append([]string{group}, graph.CriticalPath...)
+	}
+
+	for _, group := range graph.Order {
+		graph.Nodes = append(graph.Nodes, *nodes[group])
+	}
+	return graph, nil
+}
+
+// UnfinishedBlockers returns the blockers of a ticket that are not in the
+// done status, among the latest tickets of its phase.
+func UnfinishedBlockers(ticket Tickets, phaseTickets []Tickets, done TicketStatus) []Tickets {
+	blockers := []Tickets{}
+	for _, other := range phaseTickets {
+		group := TicketGroupID(other)
+		for _, dep := range ticket.DependsOn {
+			if dep == group && other.Status != done {
+				blockers = append(blockers, other)
+				break
+			}
+		}
+	}
+	return blockers
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func graphTicket(group string, sequence int, status TicketStatus, dependsOn ...string) Tickets {
+	id := uuid.MustParse(group)
+	return Tickets{
+		UUID:        uuid.New(),
+		TicketGroup: &id,
+		Sequence:    sequence,
+		Status:      status,
+		Version:     1,
+		DependsOn:   pq.StringArray(dependsOn),
+	}
+}
+
+const (
+	groupA = "00000000-0000-0000-0000-00000000000a"
+	groupB = "00000000-0000-0000-0000-00000000000b"
+	groupC = "00000000-0000-0000-0000-00000000000c"
+	groupD = "00000000-0000-0000-0000-00000000000d"
+)
+
+func TestLatestTickets(t *testing.T) {
+	v1 := graphTicket(groupA, 1, DraftTicket)
+	v2 := graphTicket(groupA, 1, ReadyTicket)
+	v2.Version = 2
+	other := graphTicket(groupB, 2, DraftTicket)
+
+	latest := LatestTickets([]Tickets{v2, other, v1})
+
+	require.Len(t, latest, 2)
+	assert.Equal(t, v2.UUID, latest[0].UUID)
+	assert.Equal(t, other.UUID, latest[1].UUID)
+}
+
+func TestValidateTicketDependencies(t *testing.T) {
+	phase := []Tickets{
+		graphTicket(groupA, 1, DraftTicket),
+		graphTicket(groupB, 2, DraftTicket, groupA),
+		graphTicket(groupC, 3, DraftTicket, groupB),
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		assert.NoError(t, ValidateTicketDependencies(groupD, []string{groupA, groupC}, phase))
+	})
+
+	t.Run("Self Reference", func(t *testing.T) {
+		assert.Error(t, ValidateTicketDependencies(groupA, []string{groupA}, phase))
+	})
+
+	t.Run("Other Phase", func(t *testing.T) {
+		err := ValidateTicketDependencies(groupA, []string{groupD}, phase)
+		assert.EqualError(t, err, "ticket "+groupD+" is not in the same phase")
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		err := ValidateTicketDependencies(groupA, []string{groupC}, phase)
+		require.True(t, errors.Is(err, ErrDependencyCycle))
+		assert.Contains(t, err.Error(), groupA+" -> "+groupC+" -> "+groupB+" -> "+groupA)
+	})
+}
+
+func TestBuildTicketGraph(t *testing.T) {
+	t.Run("Orders By Dependencies Then Sequence", func(t *testing.T) {
+		graph, err := BuildTicketGraph([]Tickets{
+			graphTicket(groupA, 3, CompletedTicket),
+			graphTicket(groupB, 1, DraftTicket, groupA),
+			graphTicket(groupC, 2, DraftTicket),
+			graphTicket(groupD, 4, DraftTicket, groupB, groupC, "unknown"),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{groupC, groupA, groupB, groupD}, graph.Order)
+		assert.Equal(t, []string{groupA, groupB, groupD}, graph.CriticalPath)
+		assert.Len(t, graph.Edges, 3)
+
+		nodes := map[string]TicketGraphNode{}
+		for _, node := range graph.Nodes {
+			nodes[node.TicketGroup] = node
+		}
+		assert.False(t, nodes[groupB].Blocked)
+		assert.True(t, nodes[groupD].Blocked)
+		assert.Equal(t, 2, nodes[groupD].Depth)
+		assert.Equal(t, []string{groupB, groupC}, nodes[groupD].DependsOn)
+		assert.Equal(t, []string{groupD}, nodes[groupC].Blocks)
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		_, err := BuildTicketGraph([]Tickets{
+			graphTicket(groupA, 1, DraftTicket, groupB),
+			graphTicket(groupB, 2, DraftTicket, groupA),
+		})
+		assert.ErrorIs(t, err, ErrDependencyCycle)
+	})
+
+	t.Run("Empty Phase", func(t *testing.T) {
+		graph, err := BuildTicketGraph(nil)
+		require.NoError(t, err)
+		assert.Empty(t, graph.Order)
+		assert.Empty(t, graph.CriticalPath)
+	})
+}
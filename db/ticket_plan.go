@@ -72,6 +72,10 @@ func (db database) DeleteTicketPlan(uuid string) error {
 	if result.RowsAffected == 0 {
 		return errors.New("ticket plan not found")
 	}
+	// the tickets stay, only their link to the plan goes
+	if err := db.db.Where("plan_uuid = ?", uuid).Delete(&TicketPlanTicket{}).Error; err != nil {
+		return fmt.Errorf("failed to delete plan tickets: %w", err)
+	}
 	return nil
 }
 
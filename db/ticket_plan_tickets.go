@@ -0,0 +1,70 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StubTicketKey is the key identifying a stub ticket across revisions of a
+// plan: its own key, or its lowercased name.
+func StubTicketKey(stub StubTicket) string {
+	if key := strings.TrimSpace(stub.Key); key != "" {
+		return key
+	}
+	return strings.ToLower(strings.TrimSpace(stub.TicketName))
+}
+
+func (db database) CreateTicketPlanTicket(link *TicketPlanTicket) (TicketPlanTicket, error) {
+	if link.PlanUUID == uuid.Nil || link.StubKey == "" {
+		return TicketPlanTicket{}, errors.New("plan UUID and stub key are required")
+	}
+	if link.ID == uuid.Nil {
+		link.ID = uuid.New()
+	}
+	now := time.Now()
+	link.CreatedAt = now
+	link.UpdatedAt = now
+
+	if err := db.db.Create(link).Error; err != nil {
+		return TicketPlanTicket{}, fmt.Errorf("failed to record plan ticket: %w", err)
+	}
+	return *link, nil
+}
+
+// GetTicketPlanTickets returns the tickets and bounties made by a plan.
+func (db database) GetTicketPlanTickets(planUUID string) ([]TicketPlanTicket, error) {
+	var links []TicketPlanTicket
+	if err := db.db.Where("plan_uuid = ?", planUUID).Order("created_at ASC").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch plan tickets: %w", err)
+	}
+	return links, nil
+}
+
+func (db database) UpdateTicketPlanTicket(link *TicketPlanTicket) error {
+	link.UpdatedAt = time.Now()
+	result := db.db.Model(&TicketPlanTicket{}).Where("id = ?", link.ID).
+		Updates(map[string]interface{}{
+			"ticket_group": link.TicketGroup,
+			"bounty_id":    link.BountyID,
+			"plan_version": link.PlanVersion,
+			"updated_at":   link.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update plan ticket: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("plan ticket not found")
+	}
+	return nil
+}
+
+func (db database) DeleteTicketPlanTicket(id uuid.UUID) error {
+	if err := db.db.Where("id = ?", id).Delete(&TicketPlanTicket{}).Error; err != nil {
+		return fmt.Errorf("failed to delete plan ticket: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func NewTicketSnapshot(ticket Tickets) TicketSnapshot {
+	dependsOn := []string{}
+	dependsOn = append(dependsOn, ticket.DependsOn...)
+	return TicketSnapshot{
+		Name:          ticket.Name,
+		Description:   ticket.Description,
+		Status:        ticket.Status,
+		WorkspaceUuid: ticket.WorkspaceUuid,
+		FeatureUUID:   ticket.FeatureUUID,
+		PhaseUUID:     ticket.PhaseUUID,
+		Sequence:      ticket.Sequence,
+		Amount:        ticket.Amount,
+		Category:      ticket.Category,
+		DependsOn:     dependsOn,
+		Assignee:      ticket.Assignee,
+	}
+}
+
+// Apply copies the content of the snapshot onto a ticket.
+func (s TicketSnapshot) Apply(ticket *Tickets) {
+	ticket.Name = s.Name
+	ticket.Description = s.Description
+	ticket.Status = s.Status
+	ticket.WorkspaceUuid = s.WorkspaceUuid
+	ticket.FeatureUUID = s.FeatureUUID
+	ticket.PhaseUUID = s.PhaseUUID
+	ticket.Sequence = s.Sequence
+	ticket.Amount = s.Amount
+	ticket.Category = s.Category
+	ticket.DependsOn = append([]string{}, s.DependsOn...)
+	ticket.Assignee = s.Assignee
+}
+
+// DiffTicketSnapshots lists the fields that changed from one snapshot to
+// another, by field name.
+func DiffTicketSnapshots(from, to TicketSnapshot) ([]TicketFieldChange, error) {
+	fromFields, err := snapshotFields(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := snapshotFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []TicketFieldChange{}
+	for field, value := range toFields {
+		if !reflect.DeepEqual(fromFields[field], value) {
+			changes = append(changes, TicketFieldChange{Field: field, From: fromFields[field], To: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+func snapshotFields(snapshot TicketSnapshot) (map[string]interface{}, error) {
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// recordTicketRevision stores the current state of a ticket as the next
+// revision of its group. Nothing is stored when the content did not change
+// since the last revision. The version of the ticket is raised to the
+// version of the revision when it is behind.
+func (db database) recordTicketRevision(ticket Tickets, note string) (TicketRevision, error) {
+	group, err := uuid.Parse(TicketGroupID(ticket))
+	if err != nil {
+		return TicketRevision{}, fmt.Errorf("invalid ticket group: %w", err)
+	}
+	snapshot := NewTicketSnapshot(ticket)
+
+	var latest TicketRevision
+	err = db.db.Where("ticket_group = ?", group).Order("version DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return TicketRevision{}, fmt.Errorf("failed to fetch ticket revision: %w", err)
+	}
+	hasLatest := err == nil
+	if hasLatest && note == "" && reflect.DeepEqual(latest.Snapshot, snapshot) {
+		return latest, nil
+	}
+
+	version := ticket.Version
+	if hasLatest && latest.Version+1 > version {
+		version = latest.Version + 1
+	}
+	if version < 1 {
+		version = 1
+	}
+
+	author := HumanAuthor
+	if ticket.Author != nil && *ticket.Author != "" {
+		author = *ticket.Author
+	}
+	authorID := ""
+	if ticket.AuthorID != nil {
+		authorID = *ticket.AuthorID
+	}
+
+	// the unique index catches a revision stored concurrently; take the next version
+	for attempt := 0; attempt < 3; attempt++ {
+		revision := TicketRevision{
+			ID:          uuid.New(),
+			TicketGroup: group,
+			Version:     version,
+			TicketUUID:  ticket.UUID,
+			Snapshot:    snapshot,
+			Author:      author,
+			AuthorID:    authorID,
+			Note:        note,
+			CreatedAt:   time.Now(),
+		}
+		result := db.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&revision)
+		if result.Error != nil {
+			return TicketRevision{}, fmt.Errorf("failed to create ticket revision: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			if version != ticket.Version {
+				if err := db.db.Model(&Tickets{}).Where("uuid = ?", ticket.UUID).UpdateColumn("version", version).Error; err != nil {
+					return revision, fmt.Errorf("failed to update ticket version: %w", err)
+				}
+			}
+			return revision, nil
+		}
+		version++
+	}
+	return TicketRevision{}, errors.New("failed to create ticket revision: too many concurrent edits")
+}
+
+// logTicketRevision records a revision of a saved ticket and updates its
+// version. Failures are logged so that the edit itself still succeeds.
+func (db database) logTicketRevision(ticket *Tickets, note string) {
+	revision, err := db.recordTicketRevision(*ticket, note)
+	if err != nil {
+		logger.Log.Error("failed to record revision of ticket %s: %v", ticket.UUID, err)
+		return
+	}
+	if revision.Version > ticket.Version {
+		ticket.Version = revision.Version
+	}
+}
+
+func (db database) GetTicketRevisions(ticketGroup uuid.UUID) ([]TicketRevision, error) {
+	revisions := []TicketRevision{}
+	if err := db.db.Where("ticket_group = ?", ticketGroup).Order("version ASC").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch ticket revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+func (db database) GetTicketRevision(ticketGroup uuid.UUID, version int) (TicketRevision, error) {
+	var revision TicketRevision
+	if err := db.db.Where("ticket_group = ? AND version = ?", ticketGroup, version).First(&revision).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return TicketRevision{}, fmt.Errorf("ticket revision not found")
+		}
+		return TicketRevision{}, fmt.Errorf("failed to fetch ticket revision: %w", err)
+	}
+	return revision, nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTicketSnapshots(t *testing.T) {
+	amount := int64(500)
+	from := TicketSnapshot{Name: "Login", Description: "Add login", Status: DraftTicket, DependsOn: []string{}}
+	to := TicketSnapshot{Name: "Login", Description: "Add LNURL login", Status: ReadyTicket, Amount: &amount, DependsOn: []string{"group-1"}}
+
+	changes, err := DiffTicketSnapshots(from, to)
+	require.NoError(t, err)
+
+	require.Len(t, changes, 4)
+	assert.Equal(t, TicketFieldChange{Field: "amount", From: nil, To: float64(500)}, changes[0])
+	assert.Equal(t, "depends_on", changes[1].Field)
+	assert.Equal(t, TicketFieldChange{Field: "description", From: "Add login", To: "Add LNURL login"}, changes[2])
+	assert.Equal(t, TicketFieldChange{Field: "status", From: "DRAFT", To: "READY"}, changes[3])
+
+	changes, err = DiffTicketSnapshots(from, from)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestTicketRevisions(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	workspace := Workspace{Uuid: uuid.New().String(), Name: "revisions-" + uuid.New().String()[:8], OwnerPubKey: "revisions-owner"}
+	TestDB.CreateOrEditWorkspace(workspace)
+	feature, err := TestDB.CreateOrEditFeature(WorkspaceFeatures{Uuid: uuid.New().String(), WorkspaceUuid: workspace.Uuid, Name: "Revisions"})
+	require.NoError(t, err)
+
+	group := uuid.New()
+	agent := AgentAuthor
+	ticket, err := TestDB.CreateOrEditTicket(&Tickets{
+		UUID:        uuid.New(),
+		TicketGroup: &group,
+		FeatureUUID: feature.Uuid,
+		Name:        "Login",
+		Description: "Add login",
+		Version:     1,
+	})
+	require.NoError(t, err)
+
+	ticket.Description = "Add LNURL login"
+	ticket.Author = &agent
+	_, err = TestDB.CreateOrEditTicket(&ticket)
+	require.NoError(t, err)
+
+	// saving the same content again does not add a revision
+	saved, err := TestDB.CreateOrEditTicket(&ticket)
+	require.NoError(t, err)
+
+	revisions, err := TestDB.GetTicketRevisions(group)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, 1, revisions[0].Version)
+	assert.Equal(t, HumanAuthor, revisions[0].Author)
+	assert.Equal(t, "Add login", revisions[0].Snapshot.Description)
+	assert.Equal(t, 2, revisions[1].Version)
+	assert.Equal(t, AgentAuthor, revisions[1].Author)
+	assert.Equal(t, 2, saved.Version)
+
+	revision, err := TestDB.GetTicketRevision(group, 1)
+	require.NoError(t, err)
+	assert.Equal(t, ticket.UUID, revision.TicketUUID)
+
+	_, err = TestDB.GetTicketRevision(group, 9)
+	assert.EqualError(t, err, "ticket revision not found")
+}
@@ -0,0 +1,275 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WorkflowTicketFields are the ticket fields a column may require.
+var WorkflowTicketFields = []string{"name", "description", "amount", "category", "phase_uuid", "depends_on", "assignee"}
+
+// DefaultTicketWorkflow is the workflow of a workspace that has not defined
+// its own: the built-in ticket statuses with any transition allowed.
+func DefaultTicketWorkflow(workspaceUuid string) TicketWorkflow {
+	return TicketWorkflow{
+		WorkspaceUuid: workspaceUuid,
+		Columns: WorkflowColumns{
+			{Status: DraftTicket, Name: "Draft", BountyStatuses: []BountyStatus{StatusDraft}},
+			{Status: ReadyTicket, Name: "Ready", BountyStatuses: []BountyStatus{StatusTodo}},
+			{Status: InProgressTicket, Name: "In Progress", BountyStatuses: []BountyStatus{StatusInProgress}},
+			{Status: TestTicket, Name: "Test", BountyStatuses: []BountyStatus{StatusInReview}},
+			{Status: DeployTicket, Name: "Deploy"},
+			{Status: PayTicket, Name: "Pay", BountyStatuses: []BountyStatus{StatusComplete}},
+			{Status: CompletedTicket, Name: "Completed", BountyStatuses: []BountyStatus{StatusPaid}},
+		},
+		Transitions: WorkflowTransitions{},
+		IsDefault:   true,
+	}
+}
+
+// Column returns the column of a status.
+func (w TicketWorkflow) Column(status TicketStatus) (WorkflowColumn, bool) {
+	for _, column := range w.Columns {
+		if column.Status == status {
+			return column, true
+		}
+	}
+	return WorkflowColumn{}, false
+}
+
+// BountyColumn returns the column that shows bounties of a status.
+func (w TicketWorkflow) BountyColumn(status BountyStatus) (WorkflowColumn, bool) {
+	for _, column := range w.Columns {
+		for _, bountyStatus := range column.BountyStatuses {
+			if bountyStatus == status {
+				return column, true
+			}
+		}
+	}
+	return WorkflowColumn{}, false
+}
+
+// StartStatus is the status of the column where work on a ticket starts:
+// the column showing in-progress bounties, or IN_PROGRESS. It is empty when
+// the workflow has neither.
+func (w TicketWorkflow) StartStatus() TicketStatus {
+	if column, ok := w.BountyColumn(StatusInProgress); ok {
+		return column.Status
+	}
+	if column, ok := w.Column(InProgressTicket); ok {
+		return column.Status
+	}
+	return ""
+}
+
+// DoneStatus is the status of finished tickets: COMPLETED, or the last
+// column of workflows without one.
+func (w TicketWorkflow) DoneStatus() TicketStatus {
+	if _, ok := w.Column(CompletedTicket); ok || len(w.Columns) == 0 {
+		return CompletedTicket
+	}
+	return w.Columns[len(w.Columns)-1].Status
+}
+
+// CanTransition reports whether a ticket may move from one column to
+// another. Staying in a column is always allowed.
+func (w TicketWorkflow) CanTransition(from, to TicketStatus) bool {
+	if from == to || len(w.Transitions) == 0 {
+		return true
+	}
+	for _, transition := range w.Transitions {
+		if transition.From == from && transition.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTicketWorkflow checks that columns have unique statuses that fit
+// the ticket status column, known required fields, and that every bounty
+// status and transition refers to a single column.
+func ValidateTicketWorkflow(workflow TicketWorkflow) error {
+	if len(workflow.Columns) == 0 {
+		return errors.New("workflow needs at least one column")
+	}
+
+	statuses := map[TicketStatus]bool{}
+	bountyStatuses := map[BountyStatus]TicketStatus{}
+	for _, column := range workflow.Columns {
+		status := strings.TrimSpace(string(column.Status))
+		if status == "" {
+			return errors.New("column status is required")
+		}
+		if status != string(column.Status) || len(status) > 50 {
+			return fmt.Errorf("invalid column status %q", column.Status)
+		}
+		if statuses[column.Status] {
+			return fmt.Errorf("duplicate column status %s", column.Status)
+		}
+		statuses[column.Status] = true
+
+		if column.WIPLimit < 0 {
+			return fmt.Errorf("column %s has a negative WIP limit", column.Status)
+		}
+		for _, field := range column.RequiredFields {
+			if !isWorkflowTicketField(field) {
+				return fmt.Errorf("column %s requires unknown field %s", column.Status, field)
+			}
+		}
+		for _, bountyStatus := range column.BountyStatuses {
+			if !isBountyStatus(bountyStatus) {
+				return fmt.Errorf("column %s shows unknown bounty status %s", column.Status, bountyStatus)
+			}
+			if other, ok := bountyStatuses[bountyStatus]; ok {
+				return fmt.Errorf("bounty status %s is shown in columns %s and %s", bountyStatus, other, column.Status)
+			}
+			bountyStatuses[bountyStatus] = column.Status
+		}
+	}
+
+	for _, transition := range workflow.Transitions {
+		if !statuses[transition.From] || !statuses[transition.To] {
+			return fmt.Errorf("transition %s -> %s refers to an unknown column", transition.From, transition.To)
+		}
+	}
+	return nil
+}
+
+func isWorkflowTicketField(field string) bool {
+	for _, known := range WorkflowTicketFields {
+		if field == known {
+			return true
+		}
+	}
+	return false
+}
+
+func isBountyStatus(status BountyStatus) bool {
+	switch status {
+	case StatusTodo, StatusInProgress, StatusInReview, StatusComplete, StatusPaid, StatusDraft:
+		return true
+	default:
+		return false
+	}
+}
+
+// MissingTicketFields lists the fields of a ticket that are required but
+// not set.
+func MissingTicketFields(ticket Tickets, fields []string) []string {
+	missing := []string{}
+	for _, field := range fields {
+		set := true
+		switch field {
+		case "name":
+			set = strings.TrimSpace(ticket.Name) != ""
+		case "description":
+			set = strings.TrimSpace(ticket.Description) != ""
+		case "amount":
+			set = ticket.Amount != nil && *ticket.Amount > 0
+		case "category":
+			set = ticket.Category != nil && *ticket.Category != ""
+		case "phase_uuid":
+			set = ticket.PhaseUUID != ""
+		case "depends_on":
+			set = len(ticket.DependsOn) > 0
+		case "assignee":
+			set = ticket.Assignee != ""
+		}
+		if !set {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// GetTicketWorkflow returns the workflow of a workspace, or the default
+// workflow when the workspace has none.
+func (db database) GetTicketWorkflow(workspaceUuid string) (TicketWorkflow, error) {
+	var workflow TicketWorkflow
+	err := db.db.Where("workspace_uuid = ?", workspaceUuid).First(&workflow).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return DefaultTicketWorkflow(workspaceUuid), nil
+	}
+	if err != nil {
+		return TicketWorkflow{}, fmt.Errorf("failed to fetch ticket workflow: %w", err)
+	}
+	return workflow, nil
+}
+
+func (db database) UpsertTicketWorkflow(workflow *TicketWorkflow) (TicketWorkflow, error) {
+	if workflow.WorkspaceUuid == "" {
+		return TicketWorkflow{}, errors.New("workspace UUID is required")
+	}
+	if err := ValidateTicketWorkflow(*workflow); err != nil {
+		return TicketWorkflow{}, err
+	}
+	if workflow.Transitions == nil {
+		workflow.Transitions = WorkflowTransitions{}
+	}
+
+	now := time.Now()
+	workflow.ID = uuid.New()
+	workflow.CreatedAt = now
+	workflow.UpdatedAt = now
+	workflow.IsDefault = false
+
+	err := db.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workspace_uuid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"columns", "transitions", "updated_by", "updated_at"}),
+	}).Create(workflow).Error
+	if err != nil {
+		return TicketWorkflow{}, fmt.Errorf("failed to save ticket workflow: %w", err)
+	}
+	return db.GetTicketWorkflow(workflow.WorkspaceUuid)
+}
+
+// GetWorkspaceTickets returns the latest version of every ticket of a
+// workspace, both the tickets of its features and its drafts.
+func (db database) GetWorkspaceTickets(workspaceUuid string) ([]Tickets, error) {
+	var tickets []Tickets
+	features := db.db.Model(&WorkspaceFeatures{}).Select("uuid").Where("workspace_uuid = ?", workspaceUuid)
+	err := db.db.
+		Where("feature_uuid IN (?)", features).
+		Or("workspace_uuid = ? AND feature_uuid IS NULL", workspaceUuid).
+		Order("sequence ASC, created_at ASC").
+		Find(&tickets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace tickets: %w", err)
+	}
+	return LatestTickets(tickets), nil
+}
+
+// ticketWorkspace is the workspace of a ticket, through its feature when the
+// ticket does not name one.
+func (db database) ticketWorkspace(ticket Tickets) string {
+	if ticket.FeatureUUID != "" {
+		if feature := db.GetFeatureByUuid(ticket.FeatureUUID); feature.WorkspaceUuid != "" {
+			return feature.WorkspaceUuid
+		}
+	}
+	return ticket.WorkspaceUuid
+}
+
+// isTicketStatusAllowed accepts the built-in statuses and the columns of the
+// workflow of the ticket's workspace.
+func (db database) isTicketStatusAllowed(ticket Tickets) bool {
+	if ticket.Status == "" || IsValidTicketStatus(ticket.Status) {
+		return true
+	}
+	workspaceUuid := db.ticketWorkspace(ticket)
+	if workspaceUuid == "" {
+		return false
+	}
+	workflow, err := db.GetTicketWorkflow(workspaceUuid)
+	if err != nil {
+		return false
+	}
+	_, ok := workflow.Column(ticket.Status)
+	return ok
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTicketWorkflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow TicketWorkflow
+		err      string
+	}{
+		{
+			name:     "Default Workflow",
+			workflow: DefaultTicketWorkflow("workspace-1"),
+		},
+		{
+			name:     "No Columns",
+			workflow: TicketWorkflow{},
+			err:      "at least one column",
+		},
+		{
+			name:     "Duplicate Status",
+			workflow: TicketWorkflow{Columns: WorkflowColumns{{Status: "TODO"}, {Status: "TODO"}}},
+			err:      "duplicate column status TODO",
+		},
+		{
+			name:     "Unknown Required Field",
+			workflow: TicketWorkflow{Columns: WorkflowColumns{{Status: "TODO", RequiredFields: []string{"estimate"}}}},
+			err:      "unknown field estimate",
+		},
+		{
+			name:     "Negative WIP Limit",
+			workflow: TicketWorkflow{Columns: WorkflowColumns{{Status: "TODO", WIPLimit: -1}}},
+			err:      "negative WIP limit",
+		},
+		{
+			name: "Bounty Status In Two Columns",
+			workflow: TicketWorkflow{Columns: WorkflowColumns{
+				{Status: "TODO", BountyStatuses: []BountyStatus{StatusTodo}},
+				{Status: "NEXT", BountyStatuses: []BountyStatus{StatusTodo}},
+			}},
+			err: "shown in columns TODO and NEXT",
+		},
+		{
+			name: "Transition To Unknown Column",
+			workflow: TicketWorkflow{
+				Columns:     WorkflowColumns{{Status: "TODO"}},
+				Transitions: WorkflowTransitions{{From: "TODO", To: "DONE"}},
+			},
+			err: "unknown column",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTicketWorkflow(tt.workflow)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestTicketWorkflowTransitions(t *testing.T) {
+	workflow := TicketWorkflow{
+		Columns:     WorkflowColumns{{Status: "TODO"}, {Status: "DOING"}, {Status: "DONE"}},
+		Transitions: WorkflowTransitions{{From: "TODO", To: "DOING"}},
+	}
+
+	assert.True(t, workflow.CanTransition("TODO", "DOING"))
+	assert.True(t, workflow.CanTransition("DONE", "DONE"))
+	assert.False(t, workflow.CanTransition("DOING", "TODO"))
+	assert.True(t, DefaultTicketWorkflow("workspace-1").CanTransition(CompletedTicket, DraftTicket))
+}
+
+func TestTicketWorkflowStartAndDoneStatus(t *testing.T) {
+	workflow := TicketWorkflow{
+		Columns: WorkflowColumns{{Status: "TODO"}, {Status: "DOING", BountyStatuses: []BountyStatus{StatusInProgress}}, {Status: "SHIPPED"}},
+	}
+
+	assert.Equal(t, TicketStatus("DOING"), workflow.StartStatus())
+	assert.Equal(t, TicketStatus("SHIPPED"), workflow.DoneStatus())
+	assert.Equal(t, InProgressTicket, DefaultTicketWorkflow("workspace-1").StartStatus())
+	assert.Equal(t, CompletedTicket, DefaultTicketWorkflow("workspace-1").DoneStatus())
+	assert.Empty(t, TicketWorkflow{Columns: WorkflowColumns{{Status: "TODO"}}}.StartStatus())
+}
+
+func TestMissingTicketFields(t *testing.T) {
+	amount := int64(500)
+	ticket := Tickets{Name: "Login", Description: "  ", Amount: &amount}
+
+	missing := MissingTicketFields(ticket, []string{"name", "description", "amount", "category", "depends_on"})
+
+	assert.Equal(t, []string{"description", "category", "depends_on"}, missing)
+}
@@ -21,7 +21,7 @@ func (db database) CreateOrEditTicket(ticket *Tickets) (Tickets, error) {
 		return Tickets{}, errors.New("feature UUID is required")
 	}
 
-	if ticket.Status != "" && !IsValidTicketStatus(ticket.Status) {
+	if !db.isTicketStatusAllowed(*ticket) {
 		return Tickets{}, errors.New("invalid ticket status")
 	}
 
@@ -41,6 +41,7 @@ func (db database) CreateOrEditTicket(ticket *Tickets) (Tickets, error) {
 		if err := db.db.Create(&ticket).Error; err != nil {
 			return Tickets{}, fmt.Errorf("failed to create ticket: %w", err)
 		}
+		db.logTicketRevision(ticket, ticket.RevisionNote)
 		return *ticket, nil
 	}
 
@@ -56,6 +57,7 @@ func (db database) CreateOrEditTicket(ticket *Tickets) (Tickets, error) {
 	if err := db.db.Where("uuid = ?", ticket.UUID).First(&updatedTicket).Error; err != nil {
 		return Tickets{}, fmt.Errorf("failed to fetch updated ticket: %w", err)
 	}
+	db.logTicketRevision(&updatedTicket, ticket.RevisionNote)
 
 	return updatedTicket, nil
 }
@@ -90,7 +92,7 @@ func (db database) UpdateTicket(ticket Tickets) (Tickets, error) {
 		return Tickets{}, errors.New("ticket UUID is required")
 	}
 
-	if ticket.Status != "" && !IsValidTicketStatus(ticket.Status) {
+	if !db.isTicketStatusAllowed(ticket) {
 		return Tickets{}, errors.New("invalid ticket status")
 	}
 
@@ -110,6 +112,7 @@ func (db database) UpdateTicket(ticket Tickets) (Tickets, error) {
 			if err := db.db.Create(&ticket).Error; err != nil {
 				return Tickets{}, fmt.Errorf("failed to create ticket: %w", err)
 			}
+			db.logTicketRevision(&ticket, ticket.RevisionNote)
 			return ticket, nil
 		}
 		return Tickets{}, fmt.Errorf("database error: %w", result.Error)
@@ -123,6 +126,7 @@ func (db database) UpdateTicket(ticket Tickets) (Tickets, error) {
 	if err := db.db.Where("uuid = ?", ticket.UUID).First(&updatedTicket).Error; err != nil {
 		return Tickets{}, fmt.Errorf("failed to fetch updated ticket: %w", err)
 	}
+	db.logTicketRevision(&updatedTicket, ticket.RevisionNote)
 
 	return updatedTicket, nil
 }
@@ -209,21 +213,13 @@ func (db database) UpdateTicketsWithoutGroup(ticket Tickets) error {
 	return nil
 }
 
-func (db database) CreateBountyFromTicket(ticket Tickets, pubkey string) (*NewBounty, error) {
+// CreateBountyFromTicket creates a bounty from a ticket, with the fields the
+// ticket does not set taken from a bounty template.
+func (db database) CreateBountyFromTicket(ticket Tickets, pubkey string, template WorkspaceTemplate) (*NewBounty, error) {
 	now := time.Now()
 
 	feature := db.GetFeatureByUuid(ticket.FeatureUUID)
 
-	price := uint(21)
-	if ticket.Amount != nil {
-		price = uint(*ticket.Amount)
-	}
-
-	wantedType := "Other"
-	if ticket.Category != nil {
-		wantedType = string(*ticket.Category)
-	}
-
 	bounty := &NewBounty{
 		Title:           ticket.Name,
 		Description:     ticket.Description,
@@ -231,13 +227,26 @@ func (db database) CreateBountyFromTicket(ticket Tickets, pubkey string) (*NewBo
 		FeatureUuid:     ticket.FeatureUUID,
 		WorkspaceUuid:   feature.WorkspaceUuid,
 		OwnerID:         pubkey,
-		Type:            "freelance_job_request",
-		WantedType:      wantedType,
-		Price:           price,
 		Created:         now.Unix(),
 		Updated:         &now,
 		Show:            true,
 		CodingLanguages: pq.StringArray{},
+		Assignee:        ticket.Assignee,
+	}
+	if bounty.WorkspaceUuid == "" {
+		bounty.WorkspaceUuid = ticket.WorkspaceUuid
+	}
+	if ticket.Amount != nil {
+		bounty.Price = uint(*ticket.Amount)
+	}
+	if ticket.Category != nil {
+		bounty.WantedType = string(*ticket.Category)
+	}
+
+	ApplyBountyTemplate(bounty, template, map[string]string{"feature": feature.Name})
+	if !template.IsBuiltin {
+		// fields the workspace template leaves out keep the built-in defaults
+		ApplyBountyTemplate(bounty, BuiltinWorkspaceTemplate(bounty.WorkspaceUuid, BountyTemplateKind), nil)
 	}
 
 	if err := db.db.Create(bounty).Error; err != nil {
@@ -321,6 +330,7 @@ func (db database) CreateWorkspaceDraftTicket(ticket *Tickets) (Tickets, error)
 	if err := db.db.Where("uuid = ?", ticket.UUID).First(&createdTicket).Error; err != nil {
 		return Tickets{}, fmt.Errorf("failed to fetch created ticket: %w", err)
 	}
+	db.logTicketRevision(&createdTicket, ticket.RevisionNote)
 
 	return createdTicket, nil
 }
@@ -353,6 +363,7 @@ func (db database) UpdateWorkspaceDraftTicket(ticket *Tickets) (Tickets, error)
 	if err := db.db.Where("uuid = ?", ticket.UUID).First(&updatedTicket).Error; err != nil {
 		return Tickets{}, fmt.Errorf("failed to fetch updated ticket: %w", err)
 	}
+	db.logTicketRevision(&updatedTicket, ticket.RevisionNote)
 
 	return updatedTicket, nil
 }
@@ -380,5 +391,8 @@ func (db database) DeleteTicketGroup(TicketGroupUUID uuid.UUID) error {
 	if result.RowsAffected == 0 {
 		return errors.New("no tickets found in group")
 	}
+	if err := db.db.Where("ticket_group = ?", TicketGroupUUID).Delete(&TicketGithubIssue{}).Error; err != nil {
+		return fmt.Errorf("failed to unlink github issue: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,286 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultBountyPrice      int64 = 21
+	defaultBountyType             = "freelance_job_request"
+	defaultBountyWantedType       = Other
+)
+
+// ErrTemplateNotFound is returned for templates that do not exist.
+var ErrTemplateNotFound = errors.New("template not found")
+
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// BuiltinWorkspaceTemplate is the template of a kind used by workspaces
+// without a default of their own. The bounty template carries the defaults
+// bounties created from tickets have always had.
+func BuiltinWorkspaceTemplate(workspaceUuid string, kind TemplateKind) WorkspaceTemplate {
+	template := WorkspaceTemplate{
+		WorkspaceUuid:      workspaceUuid,
+		Kind:               kind,
+		Name:               "Default",
+		Title:              "{{name}}",
+		Description:        "{{description}}",
+		CodingLanguages:    pq.StringArray{},
+		AcceptanceCriteria: pq.StringArray{},
+		IsDefault:          true,
+		IsBuiltin:          true,
+	}
+	if kind == BountyTemplateKind {
+		price := defaultBountyPrice
+		category := defaultBountyWantedType
+		template.Amount = &price
+		template.Category = &category
+		template.BountyType = defaultBountyType
+	}
+	return template
+}
+
+// RenderTemplate replaces the {{placeholders}} of text with their values.
+// Placeholders without a value are kept so they stand out to the reader.
+func RenderTemplate(text string, vars map[string]string) string {
+	return templatePlaceholder.ReplaceAllStringFunc(text, func(match string) string {
+		key := templatePlaceholder.FindStringSubmatch(match)[1]
+		if value, ok := vars[key]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+func hasPlaceholder(text, key string) bool {
+	for _, match := range templatePlaceholder.FindAllStringSubmatch(text, -1) {
+		if match[1] == key {
+			return true
+		}
+	}
+	return false
+}
+
+// renderTemplateField renders a template field over the current value. The
+// current value is available as {{placeholder}}; when the template does not
+// use it, a value that is already set wins.
+func renderTemplateField(current, template, placeholder string, vars map[string]string) string {
+	if template == "" {
+		return current
+	}
+	if current != "" && !hasPlaceholder(template, placeholder) {
+		return current
+	}
+	return RenderTemplate(template, vars)
+}
+
+func withAcceptanceCriteria(description string, criteria []string) string {
+	if len(criteria) == 0 {
+		return description
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(description, "\n"))
+	if description != "" {
+		b.WriteString("\n\n")
+	}
+	b.WriteString("## Acceptance Criteria\n")
+	for _, criterion := range criteria {
+		b.WriteString("- [ ] " + criterion + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func templateVars(vars map[string]string, name, description string) map[string]string {
+	merged := map[string]string{}
+	for key, value := range vars {
+		merged[key] = value
+	}
+	merged["name"] = name
+	merged["description"] = description
+	return merged
+}
+
+// ApplyTicketTemplate fills a new ticket from a template. Its name and
+// description are available to the template as {{name}} and
+// {{description}}, next to vars.
+func ApplyTicketTemplate(ticket *Tickets, template WorkspaceTemplate, vars map[string]string) {
+	merged := templateVars(vars, ticket.Name, ticket.Description)
+	ticket.Name = renderTemplateField(ticket.Name, template.Title, "name", merged)
+	ticket.Description = withAcceptanceCriteria(
+		renderTemplateField(ticket.Description, template.Description, "description", merged),
+		template.AcceptanceCriteria,
+	)
+	if ticket.Category == nil && template.Category != nil {
+		category := *template.Category
+		ticket.Category = &category
+	}
+	if ticket.Amount == nil && template.Amount != nil {
+		amount := *template.Amount
+		ticket.Amount = &amount
+	}
+}
+
+// ApplyBountyTemplate fills the fields of a new bounty that are not set from
+// a template. Its title and description are available to the template as
+// {{name}} and {{description}}, next to vars.
+func ApplyBountyTemplate(bounty *NewBounty, template WorkspaceTemplate, vars map[string]string) {
+	merged := templateVars(vars, bounty.Title, bounty.Description)
+	bounty.Title = renderTemplateField(bounty.Title, template.Title, "name", merged)
+	bounty.Description = withAcceptanceCriteria(
+		renderTemplateField(bounty.Description, template.Description, "description", merged),
+		template.AcceptanceCriteria,
+	)
+	if bounty.Price == 0 && template.Amount != nil {
+		bounty.Price = uint(*template.Amount)
+	}
+	if bounty.Type == "" {
+		bounty.Type = template.BountyType
+	}
+	if bounty.WantedType == "" && template.Category != nil {
+		bounty.WantedType = string(*template.Category)
+	}
+	if len(bounty.CodingLanguages) == 0 && len(template.CodingLanguages) > 0 {
+		bounty.CodingLanguages = append(pq.StringArray{}, template.CodingLanguages...)
+	}
+	if bounty.AccessRestriction == nil && template.AccessRestriction != nil {
+		access := *template.AccessRestriction
+		bounty.AccessRestriction = &access
+	}
+}
+
+func ValidateWorkspaceTemplate(template WorkspaceTemplate) error {
+	if template.Kind != TicketTemplateKind && template.Kind != BountyTemplateKind {
+		return fmt.Errorf("unknown template kind %q", template.Kind)
+	}
+	if strings.TrimSpace(template.Name) == "" {
+		return errors.New("template name is required")
+	}
+	if len(template.Name) > 255 {
+		return errors.New("template name is too long")
+	}
+	if template.Amount != nil && *template.Amount < 0 {
+		return errors.New("template amount cannot be negative")
+	}
+	if template.AccessRestriction != nil {
+		switch *template.AccessRestriction {
+		case BlankAccess, WorkspaceAccess, OwnerAccess, AssignedAccess:
+		default:
+			return fmt.Errorf("unknown access restriction %q", *template.AccessRestriction)
+		}
+	}
+	return nil
+}
+
+// GetWorkspaceTemplates lists the templates of a workspace, of every kind
+// when kind is empty.
+func (db database) GetWorkspaceTemplates(workspaceUuid string, kind TemplateKind) ([]WorkspaceTemplate, error) {
+	var templates []WorkspaceTemplate
+	query := db.db.Where("workspace_uuid = ?", workspaceUuid)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if err := query.Order("is_default DESC, name ASC").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (db database) GetWorkspaceTemplate(id uuid.UUID) (WorkspaceTemplate, error) {
+	var template WorkspaceTemplate
+	err := db.db.Where("id = ?", id).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return WorkspaceTemplate{}, ErrTemplateNotFound
+	}
+	if err != nil {
+		return WorkspaceTemplate{}, fmt.Errorf("failed to fetch workspace template: %w", err)
+	}
+	return template, nil
+}
+
+// GetDefaultWorkspaceTemplate returns the default template of a kind of a
+// workspace, or the built-in template when the workspace has none.
+func (db database) GetDefaultWorkspaceTemplate(workspaceUuid string, kind TemplateKind) (WorkspaceTemplate, error) {
+	var template WorkspaceTemplate
+	err := db.db.Where("workspace_uuid = ? AND kind = ? AND is_default = ?", workspaceUuid, kind, true).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return BuiltinWorkspaceTemplate(workspaceUuid, kind), nil
+	}
+	if err != nil {
+		return WorkspaceTemplate{}, fmt.Errorf("failed to fetch default workspace template: %w", err)
+	}
+	return template, nil
+}
+
+// CreateOrEditWorkspaceTemplate saves a template. A template saved as the
+// default replaces the previous default of its kind.
+func (db database) CreateOrEditWorkspaceTemplate(template *WorkspaceTemplate) (WorkspaceTemplate, error) {
+	if template.WorkspaceUuid == "" {
+		return WorkspaceTemplate{}, errors.New("workspace UUID is required")
+	}
+	if err := ValidateWorkspaceTemplate(*template); err != nil {
+		return WorkspaceTemplate{}, err
+	}
+	if template.CodingLanguages == nil {
+		template.CodingLanguages = pq.StringArray{}
+	}
+	if template.AcceptanceCriteria == nil {
+		template.AcceptanceCriteria = pq.StringArray{}
+	}
+
+	now := time.Now()
+	template.UpdatedAt = now
+	template.IsBuiltin = false
+
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		if template.IsDefault {
+			err := tx.Model(&WorkspaceTemplate{}).
+				Where("workspace_uuid = ? AND kind = ? AND id <> ?", template.WorkspaceUuid, template.Kind, template.ID).
+				Update("is_default", false).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if template.ID == uuid.Nil {
+			template.ID = uuid.New()
+			template.CreatedAt = now
+			return tx.Create(template).Error
+		}
+
+		result := tx.Model(&WorkspaceTemplate{}).
+			Where("id = ? AND workspace_uuid = ?", template.ID, template.WorkspaceUuid).
+			Select("*").
+			Omit("id", "workspace_uuid", "created_by", "created_at").
+			Updates(template)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTemplateNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return WorkspaceTemplate{}, fmt.Errorf("failed to save workspace template: %w", err)
+	}
+	return db.GetWorkspaceTemplate(template.ID)
+}
+
+func (db database) DeleteWorkspaceTemplate(id uuid.UUID) error {
+	result := db.db.Where("id = ?", id).Delete(&WorkspaceTemplate{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete workspace template: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	vars := map[string]string{"name": "Login", "feature": "Auth"}
+
+	assert.Equal(t, "Login for Auth", RenderTemplate("{{name}} for {{ feature }}", vars))
+	assert.Equal(t, "Login needs {{owner}}", RenderTemplate("{{name}} needs {{owner}}", vars))
+}
+
+func TestApplyTicketTemplate(t *testing.T) {
+	amount := int64(500)
+	category := Design
+	template := WorkspaceTemplate{
+		Kind:               TicketTemplateKind,
+		Description:        "## Context\n{{description}}\n\nFeature: {{feature}}",
+		Category:           &category,
+		Amount:             &amount,
+		AcceptanceCriteria: pq.StringArray{"Works on mobile"},
+	}
+
+	t.Run("Fills Defaults", func(t *testing.T) {
+		ticket := Tickets{Name: "Login", Description: "Add a login page"}
+		ApplyTicketTemplate(&ticket, template, map[string]string{"feature": "Auth"})
+
+		assert.Equal(t, "Login", ticket.Name)
+		assert.Equal(t, "## Context\nAdd a login page\n\nFeature: Auth\n\n## Acceptance Criteria\n- [ ] Works on mobile", ticket.Description)
+		assert.Equal(t, Design, *ticket.Category)
+		assert.Equal(t, int64(500), *ticket.Amount)
+	})
+
+	t.Run("Keeps Set Values", func(t *testing.T) {
+		own := int64(10)
+		ticket := Tickets{Name: "Login", Description: "Custom", Amount: &own}
+		ApplyTicketTemplate(&ticket, WorkspaceTemplate{Kind: TicketTemplateKind, Description: "Skeleton", Amount: &amount}, nil)
+
+		assert.Equal(t, "Custom", ticket.Description)
+		assert.Equal(t, int64(10), *ticket.Amount)
+	})
+}
+
+func TestApplyBountyTemplate(t *testing.T) {
+	t.Run("Builtin Keeps The Old Defaults", func(t *testing.T) {
+		bounty := NewBounty{Title: "Login", Description: "Add a login page"}
+		ApplyBountyTemplate(&bounty, BuiltinWorkspaceTemplate("workspace-1", BountyTemplateKind), nil)
+
+		assert.Equal(t, "Login", bounty.Title)
+		assert.Equal(t, "Add a login page", bounty.Description)
+		assert.Equal(t, uint(21), bounty.Price)
+		assert.Equal(t, "freelance_job_request", bounty.Type)
+		assert.Equal(t, "Other", bounty.WantedType)
+	})
+
+	t.Run("Workspace Template", func(t *testing.T) {
+		amount := int64(3000)
+		access := WorkspaceAccess
+		bounty := NewBounty{Title: "Login", Price: 0}
+		ApplyBountyTemplate(&bounty, WorkspaceTemplate{
+			Kind:              BountyTemplateKind,
+			Title:             "[Auth] {{name}}",
+			Description:       "Build {{name}}",
+			Amount:            &amount,
+			BountyType:        "coding_task",
+			CodingLanguages:   pq.StringArray{"Golang"},
+			AccessRestriction: &access,
+		}, nil)
+
+		assert.Equal(t, "[Auth] Login", bounty.Title)
+		assert.Equal(t, "Build Login", bounty.Description)
+		assert.Equal(t, uint(3000), bounty.Price)
+		assert.Equal(t, "coding_task", bounty.Type)
+		assert.Equal(t, pq.StringArray{"Golang"}, bounty.CodingLanguages)
+		assert.Equal(t, WorkspaceAccess, *bounty.AccessRestriction)
+	})
+}
+
+func TestValidateWorkspaceTemplate(t *testing.T) {
+	negative := int64(-1)
+	unknown := AccessRestrictionType("everyone")
+
+	assert.NoError(t, ValidateWorkspaceTemplate(WorkspaceTemplate{Kind: BountyTemplateKind, Name: "Bug"}))
+	assert.EqualError(t, ValidateWorkspaceTemplate(WorkspaceTemplate{Kind: "epic", Name: "Bug"}), `unknown template kind "epic"`)
+	assert.EqualError(t, ValidateWorkspaceTemplate(WorkspaceTemplate{Kind: TicketTemplateKind}), "template name is required")
+	assert.EqualError(t, ValidateWorkspaceTemplate(WorkspaceTemplate{Kind: TicketTemplateKind, Name: "Bug", Amount: &negative}), "template amount cannot be negative")
+	assert.EqualError(t, ValidateWorkspaceTemplate(WorkspaceTemplate{Kind: TicketTemplateKind, Name: "Bug", AccessRestriction: &unknown}), `unknown access restriction "everyone"`)
+}
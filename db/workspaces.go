@@ -546,6 +546,30 @@ func (db database) ProcessBountyPayment(payment NewPaymentHistory, bounty NewBou
 	return tx.Commit().Error
 }
 
+// ClaimBountyPayment marks an unpaid bounty as being paid. It reports false
+// when the bounty is paid or another payment of it is under way, so only
+// one payer can send the keysend.
+func (db database) ClaimBountyPayment(id uint) (bool, error) {
+	result := db.db.Model(&NewBounty{}).
+		Where("id = ? AND paid = false AND payment_pending = false", id).
+		Update("payment_pending", true)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseBountyPayment gives up the claim on the payment of an unpaid
+// bounty, recording whether the payment failed.
+func (db database) ReleaseBountyPayment(id uint, failed bool) error {
+	return db.db.Model(&NewBounty{}).
+		Where("id = ? AND paid = false", id).
+		Updates(map[string]interface{}{
+			"payment_pending": false,
+			"payment_failed":  failed,
+		}).Error
+}
+
 func (db database) GetPaymentHistory(workspace_uuid string, r *http.Request) []NewPaymentHistory {
 	payment := []NewPaymentHistory{}
 
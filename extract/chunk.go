@@ -0,0 +1,102 @@
+package extract
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// ChunkSize is the most characters in a chunk.
+	ChunkSize = 2000
+	// ChunkOverlap is how many characters a chunk repeats from the one
+	// before it, so a passage cut in two is still found whole.
+	ChunkOverlap = 200
+)
+
+// Chunk splits text into chunks of at most size characters that overlap by
+// overlap characters. Chunks end at a paragraph, sentence or word break
+// when there is one in the second half of the chunk.
+func Chunk(text string, size, overlap int) []string {
+	if overlap >= size {
+		overlap = 0
+	}
+
+	runes := []rune(strings.TrimSpace(text))
+	var chunks []string
+	for start := 0; start < len(runes); {
+		end := start + size
+		if end >= len(runes) {
+			end = len(runes)
+		} else {
+			end = breakBefore(runes, start+size/2, end)
+		}
+
+		if chunk := strings.TrimSpace(string(runes[start:end])); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		if end == len(runes) {
+			break
+		}
+
+		next := end - overlap
+		if next <= start {
+			next = end
+		}
+		// start the next chunk at a word
+		for next < end && !unicode.IsSpace(runes[next-1]) {
+			next++
+		}
+		start = next
+	}
+	return chunks
+}
+
+// breakBefore finds the best place to end a chunk between min and max.
+func breakBefore(runes []rune, min, max int) int {
+	for _, isBreak := range []func(i int) bool{
+		func(i int) bool { return runes[i] == '\n' && runes[i-1] == '\n' },
+		func(i int) bool { return unicode.IsSpace(runes[i]) && strings.ContainsRune(".!?", runes[i-1]) },
+		func(i int) bool { return unicode.IsSpace(runes[i]) },
+	} {
+		for i := max; i > min; i-- {
+			if isBreak(i) {
+				return i
+			}
+		}
+	}
+	return max
+}
+
+// minOverlap is the shortest repeated text Join treats as overlap rather
+// than chance.
+const minOverlap = 20
+
+// Join puts chunks made by Chunk back together, dropping the text each
+// chunk repeats from the one before it.
+func Join(chunks []string) string {
+	var b strings.Builder
+	for i, chunk := range chunks {
+		if i == 0 {
+			b.WriteString(chunk)
+			continue
+		}
+		joined := b.String()
+		overlap := 0
+		// the overlap is ChunkOverlap characters of up to four bytes each
+		longest := len(chunk)
+		if longest > 4*ChunkOverlap {
+			longest = 4 * ChunkOverlap
+		}
+		for k := longest; k >= minOverlap; k-- {
+			if strings.HasSuffix(joined, chunk[:k]) {
+				overlap = k
+				break
+			}
+		}
+		if overlap == 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(chunk[overlap:])
+	}
+	return b.String()
+}
@@ -0,0 +1,113 @@
+// Package extract pulls plain text out of uploaded documents and splits it
+// into chunks small enough to search and to hand to a workflow as context.
+package extract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// MaxSize is the largest document Text reads.
+const MaxSize = 20 << 20
+
+// ErrUnsupported is returned for documents Text cannot read.
+var ErrUnsupported = errors.New("unsupported file type")
+
+// Supported reports whether Text can read documents of the MIME type.
+func Supported(mimeType string) bool {
+	switch baseType(mimeType) {
+	case "application/pdf", "text/plain", "text/markdown", "text/x-markdown":
+		return true
+	}
+	return false
+}
+
+// Text returns the text of a document read from r.
+func Text(mimeType string, r io.Reader) (string, error) {
+	if !Supported(mimeType) {
+		return "", ErrUnsupported
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > MaxSize {
+		return "", fmt.Errorf("file is larger than %d bytes", MaxSize)
+	}
+
+	if baseType(mimeType) == "application/pdf" {
+		return pdfText(data)
+	}
+	if !utf8.Valid(data) {
+		return "", errors.New("file is not valid UTF-8 text")
+	}
+	return normalize(string(data)), nil
+}
+
+func pdfText(data []byte) (text string, err error) {
+	// the pdf reader panics on some malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to read pdf: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read pdf: %w", err)
+	}
+
+	var b strings.Builder
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		rows, err := page.GetTextByRow()
+		if err != nil {
+			return "", fmt.Errorf("failed to read page %d: %w", i, err)
+		}
+		for _, row := range rows {
+			words := make([]string, 0, len(row.Content))
+			for _, word := range row.Content {
+				words = append(words, word.S)
+			}
+			b.WriteString(strings.Join(words, ""))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	return normalize(b.String()), nil
+}
+
+var (
+	spaces     = regexp.MustCompile(`[ \t\f\v]+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// normalize collapses runs of spaces and blank lines and trims every line,
+// keeping paragraph breaks.
+func normalize(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\x00", "")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spaces.ReplaceAllString(line, " "))
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func baseType(mimeType string) string {
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
@@ -0,0 +1,145 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPDF builds a one page PDF that shows each line with Helvetica.
+func testPDF(lines ...string) []byte {
+	var content strings.Builder
+	content.WriteString("BT /F1 12 Tf 72 720 Td 14 TL\n")
+	for _, line := range lines {
+		fmt.Fprintf(&content, "(%s) Tj T*\n", line)
+	}
+	content.WriteString("ET")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+func TestText(t *testing.T) {
+	t.Run("Plain Text", func(t *testing.T) {
+		text, err := Text("text/plain; charset=utf-8", strings.NewReader("  Hello\t\tworld \r\n\n\n\nSecond  paragraph\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello world\n\nSecond paragraph", text)
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		text, err := Text("text/markdown", strings.NewReader("# Title\n\nBody"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "# Title\n\nBody", text)
+	})
+
+	t.Run("PDF", func(t *testing.T) {
+		text, err := Text("application/pdf", bytes.NewReader(testPDF("Release plan", "Ship on Friday")))
+
+		require.NoError(t, err)
+		assert.Contains(t, text, "Release plan")
+		assert.Contains(t, text, "Ship on Friday")
+	})
+
+	t.Run("Broken PDF", func(t *testing.T) {
+		_, err := Text("application/pdf", strings.NewReader("%PDF-1.4 not really"))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Unsupported Type", func(t *testing.T) {
+		_, err := Text("image/png", strings.NewReader("png"))
+
+		assert.ErrorIs(t, err, ErrUnsupported)
+	})
+
+	t.Run("Binary Text", func(t *testing.T) {
+		_, err := Text("text/plain", bytes.NewReader([]byte{0xff, 0xfe, 0xfd}))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestChunk(t *testing.T) {
+	t.Run("Short Text Is One Chunk", func(t *testing.T) {
+		assert.Equal(t, []string{"short text"}, Chunk(" short text ", 100, 10))
+		assert.Empty(t, Chunk("   ", 100, 10))
+	})
+
+	t.Run("Breaks At Paragraphs", func(t *testing.T) {
+		text := strings.Repeat("a", 60) + "\n\n" + strings.Repeat("b", 60)
+
+		chunks := Chunk(text, 100, 0)
+
+		assert.Equal(t, []string{strings.Repeat("a", 60), strings.Repeat("b", 60)}, chunks)
+	})
+
+	t.Run("Chunks Overlap On Word Boundaries", func(t *testing.T) {
+		words := make([]string, 200)
+		for i := range words {
+			words[i] = fmt.Sprintf("w%03d", i)
+		}
+
+		chunks := Chunk(strings.Join(words, " "), 100, 20)
+
+		require.Greater(t, len(chunks), 1)
+		for i, chunk := range chunks {
+			assert.LessOrEqual(t, len([]rune(chunk)), 100)
+			assert.Regexp(t, `^w\d{3}`, chunk)
+			assert.Regexp(t, `w\d{3}$`, chunk)
+			if i > 0 {
+				last := chunks[i-1][len(chunks[i-1])-4:]
+				assert.Contains(t, chunk, last)
+			}
+		}
+		assert.True(t, strings.HasSuffix(chunks[len(chunks)-1], "w199"))
+	})
+
+	t.Run("Long Words Are Cut", func(t *testing.T) {
+		chunks := Chunk(strings.Repeat("x", 250), 100, 10)
+
+		assert.Len(t, chunks, 3)
+	})
+}
+
+func TestJoin(t *testing.T) {
+	t.Run("Drops Overlap", func(t *testing.T) {
+		words := make([]string, 300)
+		for i := range words {
+			words[i] = fmt.Sprintf("word%03d", i)
+		}
+		text := strings.Join(words, " ")
+
+		assert.Equal(t, text, Join(Chunk(text, 200, 50)))
+	})
+
+	t.Run("Keeps Paragraphs Apart", func(t *testing.T) {
+		assert.Equal(t, "first\n\nsecond", Join([]string{"first", "second"}))
+		assert.Empty(t, Join(nil))
+	})
+}
@@ -1,6 +1,6 @@
 module github.com/stakwork/sphinx-tribes
 
-go 1.2
+go 1.16
 
 require (
 	github.com/DATA-DOG/go-sqlmock v1.5.1
@@ -25,6 +25,7 @@ require (
 	github.com/imroc/req v0.3.2
 	github.com/jinzhu/gorm v1.9.16
 	github.com/joho/godotenv v1.5.1
+	github.com/ledongthuc/pdf v0.0.0-20240201131950-da5b75280b06
 	github.com/lib/pq v1.10.9
 	github.com/nbd-wtf/ln-decodepay v1.11.1
 	github.com/patrickmn/go-cache v2.1.0+incompatible
@@ -67,4 +68,5 @@ require (
 	github.com/xhd2015/xgo/runtime v1.0.52
 	golang.org/x/exp v0.0.0-20230315142452-642cacee5cc0
 	gopkg.in/go-playground/assert.v1 v1.2.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
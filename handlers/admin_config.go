@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/stakwork/sphinx-tribes/config"
+)
+
+type configHandler struct {
+	cfg *config.Config
+}
+
+func NewConfigHandler(cfg *config.Config) *configHandler {
+	return &configHandler{cfg: cfg}
+}
+
+// GetConfig godoc
+//
+//	@Summary		Get runtime configuration
+//	@Description	Get the loaded configuration with every secret redacted
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		SuperAdminAuth
+//	@Success		200	{object}	map[string]interface{}
+//	@Router			/admin/config [get]
+func (ch *configHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ch.cfg.Redacted())
+}
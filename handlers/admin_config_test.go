@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConfig(t *testing.T) {
+	t.Run("Redacts Secrets", func(t *testing.T) {
+		cfg := config.Defaults()
+		cfg.Relay = config.RelayConfig{URL: "https://relay.example.com", AuthKey: "relay-secret"}
+		cfg.Auth.Admins = []string{"admin_pubkey"}
+		ch := NewConfigHandler(cfg)
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+		http.HandlerFunc(ch.GetConfig).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "relay-secret")
+
+		var response map[string]map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "https://relay.example.com", response["relay"]["url"])
+		assert.Equal(t, "[redacted]", response["relay"]["auth_key"])
+		assert.Equal(t, "", response["auth"]["jwt_key"])
+		assert.Equal(t, []interface{}{"admin_pubkey"}, response["auth"]["admins"])
+	})
+}
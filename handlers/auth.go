@@ -15,6 +15,7 @@ import (
 	"github.com/stakwork/sphinx-tribes/db"
 	"github.com/stakwork/sphinx-tribes/logger"
 	"github.com/stakwork/sphinx-tribes/utils"
+	"github.com/stakwork/sphinx-tribes/websocket"
 )
 
 // AuthHandler struct
@@ -23,14 +24,20 @@ type AuthHandler struct {
 	makeConnectionCodeRequest func(inviter_pubkey string, inviter_route_hint string, msats_amount uint64) string
 	decodeJwt                 func(token string) (jwt.MapClaims, error)
 	encodeJwt                 func(pubkey string) (string, error)
+	cfg                       *config.Config
 }
 
 func NewAuthHandler(db db.Database) *AuthHandler {
+	cfg := config.Current()
+	makeConnectionCodeRequest := func(inviter_pubkey string, inviter_route_hint string, msats_amount uint64) string {
+		return MakeConnectionCodeRequest(cfg.V2Bot, inviter_pubkey, inviter_route_hint, msats_amount)
+	}
 	return &AuthHandler{
 		db:                        db,
-		makeConnectionCodeRequest: MakeConnectionCodeRequest,
+		makeConnectionCodeRequest: makeConnectionCodeRequest,
 		decodeJwt:                 auth.DecodeJwt,
 		encodeJwt:                 auth.EncodeJwt,
+		cfg:                       cfg,
 	}
 }
 
@@ -173,8 +180,8 @@ func (ah *AuthHandler) CreateConnectionCode(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode("Codes created successfully")
 }
 
-func MakeConnectionCodeRequest(inviter_pubkey string, inviter_route_hint string, msats_amount uint64) string {
-	url := fmt.Sprintf("%s/invite", config.V2BotUrl)
+func MakeConnectionCodeRequest(bot config.V2BotConfig, inviter_pubkey string, inviter_route_hint string, msats_amount uint64) string {
+	url := fmt.Sprintf("%s/invite", bot.URL)
 	client := http.Client{}
 
 	// Build v2 keysend payment data
@@ -182,7 +189,7 @@ func MakeConnectionCodeRequest(inviter_pubkey string, inviter_route_hint string,
 	jsonBody := []byte(bodyData)
 
 	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
-	req.Header.Set("x-admin-token", config.V2BotToken)
+	req.Header.Set("x-admin-token", bot.Token)
 	req.Header.Set("Content-Type", "application/json")
 
 	res, err := client.Do(req)
@@ -286,7 +293,7 @@ func GetLnurlAuth(w http.ResponseWriter, r *http.Request) {
 //	@Failure		401	{object}	LnAuthResponse	"Unauthorized: Signature verification failed"
 //	@Failure		406	{object}	LnAuthResponse	"Not Acceptable: JWT creation failed"
 //	@Router			/lnauth_login [get]
-func ReceiveLnAuthData(w http.ResponseWriter, r *http.Request) {
+func (ah *AuthHandler) ReceiveLnAuthData(w http.ResponseWriter, r *http.Request) {
 	userKey := r.URL.Query().Get("key")
 	k1 := r.URL.Query().Get("k1")
 	sig := r.URL.Query().Get("sig")
@@ -319,7 +326,7 @@ func ReceiveLnAuthData(w http.ResponseWriter, r *http.Request) {
 		}
 
 		person := db.DB.GetPersonByPubkey(userKey)
-		user := returnUserMap(person)
+		user := returnUserMap(person, ah.cfg.Server.Host)
 
 		socketMsg := make(map[string]interface{})
 
@@ -330,10 +337,7 @@ func ReceiveLnAuthData(w http.ResponseWriter, r *http.Request) {
 		socketMsg["user"] = user
 		socketMsg["msg"] = "lnauth_success"
 
-		socket, err := db.Store.GetSocketConnections(k1[0:20])
-
-		if err == nil {
-			socket.Conn.WriteJSON(socketMsg)
+		if err := websocket.WebsocketPool.SendToSession(k1[0:20], socketMsg); err == nil {
 			db.Store.DeleteCache(k1[0:20])
 		} else {
 			logger.Log.Error("[auth] Socket Error: %v", err)
@@ -403,7 +407,7 @@ func (ah *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		}
 
 		person := ah.db.GetPersonByPubkey(pubkey)
-		user := returnUserMap(person)
+		user := returnUserMap(person, ah.cfg.Server.Host)
 
 		responseData["k1"] = ""
 		responseData["status"] = true
@@ -415,7 +419,7 @@ func (ah *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func returnUserMap(p db.Person) map[string]interface{} {
+func returnUserMap(p db.Person, host string) map[string]interface{} {
 	user := make(map[string]interface{})
 
 	user["id"] = p.ID
@@ -433,7 +437,7 @@ func returnUserMap(p db.Person) map[string]interface{} {
 	user["last_login"] = p.LastLogin
 	user["price_to_meet"] = p.PriceToMeet
 	user["alias"] = p.OwnerAlias
-	user["url"] = config.Host
+	user["url"] = host
 	return user
 }
 
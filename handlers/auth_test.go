@@ -873,7 +873,7 @@ func TestReturnUserMap(t *testing.T) {
 				"last_login":    "2023-10-02T12:00:00Z",
 				"price_to_meet": 100,
 				"alias":         "owner_alias_value",
-				"url":           config.Host,
+				"url":           "https://people.sphinx.chat",
 			},
 		},
 		{
@@ -895,7 +895,7 @@ func TestReturnUserMap(t *testing.T) {
 				"last_login":    "",
 				"price_to_meet": 0,
 				"alias":         "",
-				"url":           config.Host,
+				"url":           "https://people.sphinx.chat",
 			},
 		},
 		{
@@ -919,7 +919,7 @@ func TestReturnUserMap(t *testing.T) {
 				"unique_name":  strings.Repeat("g", 255),
 				"pubkey":       strings.Repeat("b", 255),
 				"alias":        strings.Repeat("c", 255),
-				"url":          config.Host,
+				"url":          "https://people.sphinx.chat",
 			},
 		},
 		{
@@ -941,7 +941,7 @@ func TestReturnUserMap(t *testing.T) {
 				"last_login":    "",
 				"price_to_meet": 0,
 				"alias":         "",
-				"url":           config.Host,
+				"url":           "https://people.sphinx.chat",
 			},
 		},
 		{
@@ -951,7 +951,7 @@ func TestReturnUserMap(t *testing.T) {
 			},
 			expected: map[string]interface{}{
 				"uuid": "12345",
-				"url":  config.Host,
+				"url":  "https://people.sphinx.chat",
 			},
 		},
 		{
@@ -961,7 +961,7 @@ func TestReturnUserMap(t *testing.T) {
 			},
 			expected: map[string]interface{}{
 				"tags": make([]string, 10000),
-				"url":  config.Host,
+				"url":  "https://people.sphinx.chat",
 			},
 		},
 		{
@@ -975,14 +975,14 @@ func TestReturnUserMap(t *testing.T) {
 				"uuid":        "123e4567-e89b-12d3-a456-426614174000",
 				"owner_alias": "owner_alias_!@#$%^&*()",
 				"description": "Description with special characters: !@#$%^&*()",
-				"url":         config.Host,
+				"url":         "https://people.sphinx.chat",
 			},
 		},
 		{
 			name:  "Config Dependency Test",
 			input: db.Person{},
 			expected: map[string]interface{}{
-				"url": config.Host,
+				"url": "https://people.sphinx.chat",
 			},
 		},
 		{
@@ -992,7 +992,7 @@ func TestReturnUserMap(t *testing.T) {
 			},
 			expected: map[string]interface{}{
 				"extras": map[string]interface{}{"key1": nil, "key2": "value"},
-				"url":    config.Host,
+				"url":    "https://people.sphinx.chat",
 			},
 		},
 		{
@@ -1002,14 +1002,14 @@ func TestReturnUserMap(t *testing.T) {
 			},
 			expected: map[string]interface{}{
 				"price_to_meet": -50,
-				"url":           config.Host,
+				"url":           "https://people.sphinx.chat",
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := returnUserMap(tt.input)
+			result := returnUserMap(tt.input, "https://people.sphinx.chat")
 			if expectedUuid, ok := tt.expected["uuid"]; ok {
 				assert.Equal(t, expectedUuid, result["uuid"])
 			}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -22,6 +23,7 @@ import (
 	"github.com/stakwork/sphinx-tribes/db"
 	"github.com/stakwork/sphinx-tribes/logger"
 	"github.com/stakwork/sphinx-tribes/utils"
+	"github.com/stakwork/sphinx-tribes/websocket"
 	"gorm.io/gorm"
 )
 
@@ -40,25 +42,36 @@ type BountyTimingResponse struct {
 type bountyHandler struct {
 	httpClient               HttpClient
 	db                       db.Database
-	getSocketConnections     func(host string) (db.Client, error)
+	sendToSession            func(sessionID string, message interface{}) error
 	generateBountyResponse   func(bounties []db.NewBounty) []db.BountyResponse
 	userHasAccess            func(pubKeyFromAuth string, uuid string, role string) bool
 	getInvoiceStatusByTag    func(tag string) db.V2TagRes
 	getHoursDifference       func(createdDate int64, endDate *time.Time) int64
 	userHasManageBountyRoles func(pubKeyFromAuth string, uuid string) bool
+	getPullRequest           func(owner, repo string, number int) (db.GithubPullRequest, error)
+	cfg                      *config.Config
 	m                        sync.Mutex
 }
 
 func NewBountyHandler(httpClient HttpClient, database db.Database) *bountyHandler {
 	dbConf := db.NewDatabaseConfig(&gorm.DB{})
+	cfg := config.Current()
+	getInvoiceStatusByTag := func(tag string) db.V2TagRes {
+		return GetInvoiceStatusByTag(cfg.V2Bot, tag)
+	}
+	getPullRequest := func(owner, repo string, number int) (db.GithubPullRequest, error) {
+		return GetRepoPullRequest(cfg.Github.Token, owner, repo, number)
+	}
 	return &bountyHandler{
 		httpClient:               httpClient,
 		db:                       database,
-		getSocketConnections:     db.Store.GetSocketConnections,
+		sendToSession:            websocket.WebsocketPool.SendToSession,
 		userHasAccess:            dbConf.UserHasAccess,
-		getInvoiceStatusByTag:    GetInvoiceStatusByTag,
+		getInvoiceStatusByTag:    getInvoiceStatusByTag,
 		getHoursDifference:       utils.GetHoursDifference,
 		userHasManageBountyRoles: dbConf.UserHasManageBountyRoles,
+		getPullRequest:           getPullRequest,
+		cfg:                      cfg,
 	}
 }
 
@@ -298,13 +311,13 @@ func (h *bountyHandler) GetPersonAssignedBounties(w http.ResponseWriter, r *http
 	}
 }
 
-func getContactKey(pubkey string) (*string, error) {
-	url := fmt.Sprintf("%s/get_contact/%s", config.V2BotUrl, pubkey)
+func getContactKey(bot config.V2BotConfig, pubkey string) (*string, error) {
+	url := fmt.Sprintf("%s/get_contact/%s", bot.URL, pubkey)
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
-	req.Header.Set("x-admin-token", config.V2BotToken)
+	req.Header.Set("x-admin-token", bot.Token)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -323,11 +336,11 @@ func getContactKey(pubkey string) (*string, error) {
 	return contactResp.ContactKey, nil
 }
 
-func ProcessWaitingNotifications() {
+func ProcessWaitingNotifications(cfg *config.Config) {
 	notifications := db.DB.GetNotificationsByStatus("WAITING_KEY_EXCHANGE")
 
 	for _, n := range notifications {
-		contactKey, err := getContactKey(n.PubKey)
+		contactKey, err := getContactKey(cfg.V2Bot, n.PubKey)
 		if err != nil {
 			logger.Log.Error("Error checking contact key for pubkey %s: %v", n.PubKey, err)
 			db.DB.IncrementNotificationRetry(n.UUID)
@@ -340,13 +353,13 @@ func ProcessWaitingNotifications() {
 		}
 
 		// Contact key is available, proceed with sending
-		sendRespStatus := sendNotification(n.PubKey, n.Content)
+		sendRespStatus := sendNotification(cfg.V2Bot, n.PubKey, n.Content)
 		db.DB.UpdateNotificationStatus(n.UUID, sendRespStatus)
 	}
 }
 
-func sendNotification(pubkey, content string) string {
-	sendURL := fmt.Sprintf("%s/send", config.V2BotUrl)
+func sendNotification(bot config.V2BotConfig, pubkey, content string) string {
+	sendURL := fmt.Sprintf("%s/send", bot.URL)
 	msgBody, _ := json.Marshal(map[string]interface{}{
 		"dest":     pubkey,
 		"amt_msat": 0,
@@ -361,7 +374,7 @@ func sendNotification(pubkey, content string) string {
 		return "FAILED"
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-admin-token", config.V2BotToken)
+	req.Header.Set("x-admin-token", bot.Token)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -381,8 +394,8 @@ func sendNotification(pubkey, content string) string {
 	return sendResp.Status
 }
 
-func processNotification(pubkey, event, content, alias string, route_hint string) string {
-	contactKey, err := getContactKey(pubkey)
+func processNotification(bot config.V2BotConfig, pubkey, event, content, alias string, route_hint string) string {
+	contactKey, err := getContactKey(bot, pubkey)
 	if err != nil {
 		logger.Log.Error("Error checking contact key: %v", err)
 		return "FAILED"
@@ -392,21 +405,21 @@ func processNotification(pubkey, event, content, alias string, route_hint string
 
 		contact_info := fmt.Sprintf("%s_%s", pubkey, route_hint)
 		logger.Log.Info("Sending contact info: %v", contact_info)
-		addContactURL := fmt.Sprintf("%s/add_contact", config.V2BotUrl)
+		addContactURL := fmt.Sprintf("%s/add_contact", bot.URL)
 		body, _ := json.Marshal(map[string]string{"contact_info": contact_info, "alias": alias})
 		req, _ := http.NewRequest(http.MethodPost, addContactURL, bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("x-admin-token", config.V2BotToken)
+		req.Header.Set("x-admin-token", bot.Token)
 		http.DefaultClient.Do(req)
 
-		contactKey, err = getContactKey(pubkey)
+		contactKey, err = getContactKey(bot, pubkey)
 		if err != nil || contactKey == nil {
 			db.DB.SaveNotification(pubkey, event, content, "WAITING_KEY_EXCHANGE")
 			return "FAILED"
 		}
 	}
 
-	return sendNotification(pubkey, content)
+	return sendNotification(bot, pubkey, content)
 }
 
 // CreateOrEditBounty godoc
@@ -417,8 +430,9 @@ func processNotification(pubkey, event, content, alias string, route_hint string
 //	@Accept			json
 //	@Produce		json
 //	@Security		PubKeyContextAuth
-//	@Param			bounty	body		db.NewBounty	true	"Bounty object"
-//	@Success		200		{object}	db.NewBounty
+//	@Param			bounty		body		db.NewBounty	true	"Bounty object"
+//	@Param			template_id	query		string			false	"Bounty template applied to a new bounty"
+//	@Success		200			{object}	db.NewBounty
 //	@Router			/gobounties [post]
 func (h *bountyHandler) CreateOrEditBounty(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -475,6 +489,16 @@ func (h *bountyHandler) CreateOrEditBounty(w http.ResponseWriter, r *http.Reques
 	//Check if bounty exists
 	bounty.Updated = &now
 
+	if templateID := r.URL.Query().Get("template_id"); templateID != "" && bounty.ID == 0 {
+		template, checkErr := resolveWorkspaceTemplate(h.db, bounty.WorkspaceUuid, db.BountyTemplateKind, templateID)
+		if checkErr != nil {
+			w.WriteHeader(checkErr.status)
+			json.NewEncoder(w).Encode(checkErr.message)
+			return
+		}
+		db.ApplyBountyTemplate(&bounty, template, nil)
+	}
+
 	if bounty.Type == "" {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode("Type is a required field")
@@ -594,7 +618,7 @@ func (h *bountyHandler) CreateOrEditBounty(w http.ResponseWriter, r *http.Reques
 
 		if assigneePubkey != "" {
 			person := db.DB.GetPersonByPubkey(assigneePubkey)
-			processNotification(assigneePubkey, "bounty_assigned", msg, person.OwnerAlias, person.OwnerRouteHint)
+			processNotification(h.cfg.V2Bot, assigneePubkey, "bounty_assigned", msg, person.OwnerAlias, person.OwnerRouteHint)
 		}
 
 	}
@@ -974,285 +998,171 @@ func (h *bountyHandler) MakeBountyPayment(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Get Bounty Assignee
-	assignee := h.db.GetPersonByPubkey(bounty.Assignee)
-
-	memoData := fmt.Sprintf("Payment For: %ss", bounty.Title)
-	memoText := url.QueryEscape(memoData)
-	now := time.Now()
-
-	// If the v2contactkey is present
-	if config.IsV2Payment {
-		url := fmt.Sprintf("%s/pay", config.V2BotUrl)
+	msg := make(map[string]interface{})
+	message, err := h.payBounty(bounty, pubKeyFromAuth)
+	if errors.Is(err, errBountyPaymentClaimed) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode("Bounty payemnt is pending, cannot retry payment")
+		h.m.Unlock()
+		return
+	}
+	if message == "" {
+		logger.Log.Error("[bounty] failed to start the payment of bounty %d: %v", bounty.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		h.m.Unlock()
+		return
+	}
+	if err != nil {
+		logger.Log.Error("[bounty] payment of bounty %d: %v", bounty.ID, err)
+	}
 
-		logger.Log.Info("IS V2 PAYMENT ====")
+	msg["msg"] = message
+	msg["invoice"] = ""
 
-		// Build v2 keysend payment data
-		bodyData := utils.BuildV2KeysendBodyData(amount, assignee.OwnerPubKey, assignee.OwnerRouteHint, memoText)
-		jsonBody := []byte(bodyData)
+	if err := h.sendToSession(request.Websocket_token, msg); err != nil {
+		logger.Log.Info("[bounty] could not notify %s of the payment of bounty %d: %v", request.Websocket_token, bounty.ID, err)
+	}
 
-		log.Println("Payment Body Data", bodyData)
+	h.m.Unlock()
 
-		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
-		req.Header.Set("x-admin-token", config.V2BotToken)
-		req.Header.Set("Content-Type", "application/json")
-		log.Printf("[bounty] Making Bounty V2 Payment: amount: %d, pubkey: %s, route_hint: %s", amount, assignee.OwnerPubKey, assignee.OwnerRouteHint)
+	if message == keysendFailed || message == keysendError {
+		w.WriteHeader(http.StatusBadRequest)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(msg)
+}
 
-		res, err := h.httpClient.Do(req)
+const (
+	keysendSuccess = "keysend_success"
+	keysendPending = "keysend_pending"
+	keysendFailed  = "keysend_failed"
+	keysendError   = "keysend_error"
+)
 
-		if err != nil {
-			log.Printf("[bounty] Request Failed: %s", err)
-			h.m.Unlock()
-			return
-		}
+// errBountyPaymentClaimed is returned when a bounty is already paid or
+// another payment of it is under way.
+var errBountyPaymentClaimed = errors.New("bounty is paid or its payment is pending")
 
-		log.Printf("[bounty] After Making Bounty V2 Payment: amount: %d, pubkey: %s, route_hint: %s", amount, assignee.OwnerPubKey, assignee.OwnerRouteHint)
+// payBounty pays the assignee of a bounty by keysend on behalf of
+// senderPubkey and records the payment. The payment is claimed in the
+// database first, so concurrent payers, on this server or another, cannot
+// pay a bounty twice. It returns the keysend message for the payer, empty
+// when the payment could not start. Only a payment that definitely failed
+// releases the claim; when the keysend may have gone out, the payment is
+// recorded as pending and the claim kept until it is reconciled.
+func (h *bountyHandler) payBounty(bounty db.NewBounty, senderPubkey string) (string, error) {
+	claimed, err := h.db.ClaimBountyPayment(bounty.ID)
+	if err != nil {
+		return "", err
+	}
+	if !claimed {
+		return "", errBountyPaymentClaimed
+	}
 
-		defer res.Body.Close()
-		body, err = io.ReadAll(res.Body)
-		if err != nil {
-			log.Println("[read body failed]", err)
-			w.WriteHeader(http.StatusNotAcceptable)
-			h.m.Unlock()
-			return
+	bounty.WorkspaceUuid = bountyWorkspaceUuid(bounty)
+	assignee := h.db.GetPersonByPubkey(bounty.Assignee)
+	amount := bounty.Price
+	memoText := url.QueryEscape(fmt.Sprintf("Payment For: %ss", bounty.Title))
+	now := time.Now()
+	paymentHistory := db.NewPaymentHistory{
+		Amount:         amount,
+		SenderPubKey:   senderPubkey,
+		ReceiverPubKey: assignee.OwnerPubKey,
+		WorkspaceUuid:  bounty.WorkspaceUuid,
+		BountyId:       bounty.ID,
+		Created:        &now,
+		Updated:        &now,
+		PaymentType:    "payment",
+	}
+
+	failed := func(message, reason string) (string, error) {
+		paymentHistory.Status = false
+		paymentHistory.PaymentStatus = db.PaymentFailed
+		paymentHistory.Error = reason
+		h.db.AddPaymentHistory(paymentHistory)
+		if err := h.db.ReleaseBountyPayment(bounty.ID, true); err != nil {
+			logger.Log.Error("[bounty] failed to release the payment of bounty %d: %v", bounty.ID, err)
 		}
-
-		log.Println("[bounty] After Reading Keysend V2 Payment Body ===")
-
-		msg := make(map[string]interface{})
-		// payment is successful add to payment history
-		// and reduce workspaces budget
-
-		paymentHistory := db.NewPaymentHistory{
-			Amount:         amount,
-			SenderPubKey:   pubKeyFromAuth,
-			ReceiverPubKey: assignee.OwnerPubKey,
-			WorkspaceUuid:  bounty.WorkspaceUuid,
-			BountyId:       id,
-			Created:        &now,
-			Updated:        &now,
-			Status:         false,
-			PaymentType:    "payment",
-			Tag:            "",
-			PaymentStatus:  "FAILED",
+		return message, errors.New(reason)
+	}
+	unknown := func(reason string) (string, error) {
+		paymentHistory.Status = false
+		paymentHistory.PaymentStatus = db.PaymentPending
+		paymentHistory.Error = reason
+		bounty.PaymentPending = true
+		bounty.PaymentFailed = false
+		if err := h.db.ProcessBountyPayment(paymentHistory, bounty); err != nil {
+			return keysendPending, err
 		}
+		return keysendPending, errors.New(reason)
+	}
 
-		if res.StatusCode == 200 {
-			// Unmarshal result
-			v2KeysendRes := db.V2SendOnionRes{}
-			err = json.Unmarshal(body, &v2KeysendRes)
-
-			if err != nil {
-				logger.Log.Error("[Unmarshal failed]: %v", err)
-				w.WriteHeader(http.StatusNotAcceptable)
-				h.m.Unlock()
-				return
-			}
-
-			log.Printf("[bounty] V2 Status After Making Bounty V2 Payment: amount: %d, pubkey: %s, route_hint: %s is : %s", amount, assignee.OwnerPubKey, assignee.OwnerRouteHint, v2KeysendRes.Status)
-
-			// if the payment has a completed status
-			if v2KeysendRes.Status == db.PaymentComplete {
-				bounty.PaymentFailed = false
-				bounty.PaymentPending = false
-				bounty.Paid = true
-				bounty.PaidDate = &now
-				bounty.Completed = true
-				bounty.CompletionDate = &now
-
-				paymentHistory.Status = true
-				paymentHistory.PaymentStatus = db.PaymentComplete
-				paymentHistory.Tag = v2KeysendRes.Tag
-
-				h.db.ProcessBountyPayment(paymentHistory, bounty)
-
-				msg["msg"] = "keysend_success"
-				msg["invoice"] = ""
-
-				socket, err := h.getSocketConnections(request.Websocket_token)
-				if err == nil {
-					socket.Conn.WriteJSON(msg)
-				}
-
-				h.m.Unlock()
-
-				w.WriteHeader(http.StatusOK)
-				json.NewEncoder(w).Encode(msg)
-				return
-			} else if v2KeysendRes.Status == db.PaymentPending {
-				// Send payment status
-				log.Printf("[bounty] V2 Status is pending:  %s", v2KeysendRes.Status)
-				bounty.Paid = false
-				bounty.PaymentFailed = false
-				bounty.PaymentPending = true
-				bounty.PaidDate = &now
-				bounty.Completed = true
-				bounty.CompletionDate = &now
-
-				paymentHistory.Status = true
-				paymentHistory.PaymentStatus = db.PaymentPending
-				paymentHistory.Tag = v2KeysendRes.Tag
-
-				h.db.ProcessBountyPayment(paymentHistory, bounty)
-
-				msg["msg"] = "keysend_pending"
-				msg["invoice"] = ""
-
-				socket, err := h.getSocketConnections(request.Websocket_token)
-				if err == nil {
-					socket.Conn.WriteJSON(msg)
-				}
-
-				h.m.Unlock()
-
-				w.WriteHeader(http.StatusOK)
-				json.NewEncoder(w).Encode(msg)
-				return
-			} else {
-				// Send payment status
-				log.Printf("[bounty] V2 Status Was not completed:  %s", v2KeysendRes.Status)
-
-				bounty.Paid = false
-				bounty.PaymentPending = false
-				bounty.PaymentFailed = true
-
-				// set the error message
-				paymentHistory.Error = v2KeysendRes.Message
-				paymentHistory.PaymentStatus = db.PaymentFailed
-				paymentHistory.Tag = v2KeysendRes.Tag
-
-				h.db.AddPaymentHistory(paymentHistory)
-				h.db.UpdateBounty(bounty)
-
-				log.Println("Keysend payment not completed ===")
-				msg["msg"] = "keysend_failed"
-				msg["invoice"] = ""
-
-				socket, err := h.getSocketConnections(request.Websocket_token)
-				if err == nil {
-					socket.Conn.WriteJSON(msg)
-				}
-
-				h.m.Unlock()
-
-				w.WriteHeader(http.StatusBadRequest)
-				json.NewEncoder(w).Encode(msg)
-				return
-			}
-		} else { // Send Payment error
-			log.Println("Keysend payment error: Failed to send ===")
-			msg["msg"] = "keysend_error"
-			msg["invoice"] = ""
-
-			bounty.Paid = false
-			bounty.PaymentPending = false
-			bounty.PaymentFailed = true
-
-			// set the error message
-			paymentHistory.Error = "Payment Request Failed"
-
-			h.db.AddPaymentHistory(paymentHistory)
-			h.db.UpdateBounty(bounty)
-
-			socket, err := h.getSocketConnections(request.Websocket_token)
-			if err == nil {
-				socket.Conn.WriteJSON(msg)
-			}
-
-			h.m.Unlock()
-
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(msg)
-			return
-		}
-	} else { // Process v1 payment
-		url := fmt.Sprintf("%s/payment", config.RelayUrl)
-
+	var req *http.Request
+	if h.cfg.IsV2Payment() {
+		bodyData := utils.BuildV2KeysendBodyData(amount, assignee.OwnerPubKey, assignee.OwnerRouteHint, memoText)
+		req, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("%s/pay", h.cfg.V2Bot.URL), bytes.NewBuffer([]byte(bodyData)))
+		req.Header.Set("x-admin-token", h.cfg.V2Bot.Token)
+	} else {
 		bodyData := utils.BuildKeysendBodyData(amount, assignee.OwnerPubKey, assignee.OwnerRouteHint, memoText)
-		jsonBody := []byte(bodyData)
-
-		log.Println("Payment Body Data", bodyData)
+		req, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("%s/payment", h.cfg.Relay.URL), bytes.NewBuffer([]byte(bodyData)))
+		req.Header.Set("x-user-token", h.cfg.Relay.AuthKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	log.Printf("[bounty] Making Bounty Payment: amount: %d, pubkey: %s, route_hint: %s", amount, assignee.OwnerPubKey, assignee.OwnerRouteHint)
 
-		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
-		req.Header.Set("x-user-token", config.RelayAuthKey)
-		req.Header.Set("Content-Type", "application/json")
-		log.Printf("[bounty] Making Bounty Payment: amount: %d, pubkey: %s, route_hint: %s", amount, assignee.OwnerPubKey, assignee.OwnerRouteHint)
-		res, err := h.httpClient.Do(req)
+	res, err := h.httpClient.Do(req)
+	if err != nil {
+		log.Printf("[bounty] Request Failed: %s", err)
+		return unknown("Payment Request Failed: " + err.Error())
+	}
+	defer res.Body.Close()
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return failed(keysendError, "Payment Request Failed")
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return unknown("Payment Response Unreadable: " + err.Error())
+	}
 
-		if err != nil {
-			log.Printf("[bounty] Request Failed: %s", err)
-			h.m.Unlock()
-			return
-		}
+	message := keysendSuccess
+	bounty.PaymentFailed = false
+	bounty.PaymentPending = false
+	bounty.Completed = true
+	bounty.CompletionDate = &now
+	paymentHistory.Status = true
 
-		defer res.Body.Close()
-		body, err = io.ReadAll(res.Body)
-		if err != nil {
-			logger.Log.Error("[bounty] Read body error: %v", err)
-			w.WriteHeader(http.StatusNotAcceptable)
-			h.m.Unlock()
-			return
+	if h.cfg.IsV2Payment() {
+		v2KeysendRes := db.V2SendOnionRes{}
+		if err := json.Unmarshal(body, &v2KeysendRes); err != nil {
+			return unknown("Payment Response Unreadable: " + err.Error())
 		}
+		log.Printf("[bounty] V2 Status After Making Bounty V2 Payment: amount: %d, pubkey: %s is : %s", amount, assignee.OwnerPubKey, v2KeysendRes.Status)
 
-		msg := make(map[string]interface{})
-
-		// payment is successful add to payment history
-		// and reduce workspaces budget
-		if res.StatusCode == 200 {
-			// Unmarshal result
-			keysendRes := db.KeysendSuccess{}
-			err = json.Unmarshal(body, &keysendRes)
-
-			if err != nil {
-				logger.Log.Error("[bounty] Unmarshal error: %v", err)
-				w.WriteHeader(http.StatusNotAcceptable)
-				h.m.Unlock()
-				return
-			}
-
-			now := time.Now()
-
-			paymentHistory := db.NewPaymentHistory{
-				Amount:         amount,
-				SenderPubKey:   pubKeyFromAuth,
-				ReceiverPubKey: assignee.OwnerPubKey,
-				WorkspaceUuid:  bounty.WorkspaceUuid,
-				BountyId:       id,
-				Created:        &now,
-				Updated:        &now,
-				Status:         true,
-				PaymentType:    "payment",
-			}
-
+		paymentHistory.Tag = v2KeysendRes.Tag
+		switch v2KeysendRes.Status {
+		case db.PaymentComplete:
 			bounty.Paid = true
 			bounty.PaidDate = &now
-			bounty.Completed = true
-			bounty.CompletionDate = &now
-
-			h.db.ProcessBountyPayment(paymentHistory, bounty)
-
-			msg["msg"] = "keysend_success"
-			msg["invoice"] = ""
-
-			socket, err := h.getSocketConnections(request.Websocket_token)
-			if err == nil {
-				socket.Conn.WriteJSON(msg)
-			}
-			h.m.Unlock()
-			return
-		} else {
-			msg["msg"] = "keysend_error"
-			msg["invoice"] = ""
-
-			socket, err := h.getSocketConnections(request.Websocket_token)
-			if err == nil {
-				socket.Conn.WriteJSON(msg)
-			}
-
-			h.m.Unlock()
-			return
+			paymentHistory.PaymentStatus = db.PaymentComplete
+		case db.PaymentPending:
+			message = keysendPending
+			bounty.PaymentPending = true
+			bounty.PaidDate = &now
+			paymentHistory.PaymentStatus = db.PaymentPending
+		case db.PaymentFailed:
+			return failed(keysendFailed, v2KeysendRes.Message)
+		default:
+			return unknown(fmt.Sprintf("Unknown Payment Status %q", v2KeysendRes.Status))
 		}
+	} else {
+		bounty.Paid = true
+		bounty.PaidDate = &now
 	}
+
+	// the keysend went out, so a failure to record it keeps the claim and
+	// the bounty cannot be paid again
+	return message, h.db.ProcessBountyPayment(paymentHistory, bounty)
 }
 
 // GetBountyPaymentStatus godoc
@@ -1570,7 +1480,7 @@ func formatPayError(errorMsg string) db.InvoicePayError {
 }
 
 func (h *bountyHandler) GetLightningInvoice(payment_request string) (db.InvoiceResult, db.InvoiceError) {
-	if config.IsV2Payment {
+	if h.cfg.IsV2Payment() {
 		return h.GetV2LightningInvoice(payment_request)
 	} else {
 		return h.GetV1LightningInvoice(payment_request)
@@ -1578,11 +1488,11 @@ func (h *bountyHandler) GetLightningInvoice(payment_request string) (db.InvoiceR
 }
 
 func (h *bountyHandler) GetV1LightningInvoice(payment_request string) (db.InvoiceResult, db.InvoiceError) {
-	url := fmt.Sprintf("%s/invoice?payment_request=%s", config.RelayUrl, payment_request)
+	url := fmt.Sprintf("%s/invoice?payment_request=%s", h.cfg.Relay.URL, payment_request)
 
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 
-	req.Header.Set("x-user-token", config.RelayAuthKey)
+	req.Header.Set("x-user-token", h.cfg.Relay.AuthKey)
 	req.Header.Set("Content-Type", "application/json")
 	res, _ := h.httpClient.Do(req)
 
@@ -1626,7 +1536,7 @@ func (h *bountyHandler) GetV1LightningInvoice(payment_request string) (db.Invoic
 }
 
 func (h *bountyHandler) GetV2LightningInvoice(payment_request string) (db.InvoiceResult, db.InvoiceError) {
-	url := fmt.Sprintf("%s/check_invoice", config.V2BotUrl)
+	url := fmt.Sprintf("%s/check_invoice", h.cfg.V2Bot.URL)
 
 	invoiceBody := db.V2InvoiceBody{
 		Bolt11: payment_request,
@@ -1635,7 +1545,7 @@ func (h *bountyHandler) GetV2LightningInvoice(payment_request string) (db.Invoic
 	jsonBody, _ := json.Marshal(invoiceBody)
 
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
-	req.Header.Set("x-admin-token", config.V2BotToken)
+	req.Header.Set("x-admin-token", h.cfg.V2Bot.Token)
 	req.Header.Set("Content-Type", "application/json")
 	res, _ := h.httpClient.Do(req)
 
@@ -1694,7 +1604,7 @@ func (h *bountyHandler) GetV2LightningInvoice(payment_request string) (db.Invoic
 }
 
 func (h *bountyHandler) PayLightningInvoice(payment_request string) (db.InvoicePaySuccess, db.InvoicePayError) {
-	if config.IsV2Payment {
+	if h.cfg.IsV2Payment() {
 		return h.PayV2LightningInvoice(payment_request)
 	} else {
 		return h.PayV1LightningInvoice(payment_request)
@@ -1702,7 +1612,7 @@ func (h *bountyHandler) PayLightningInvoice(payment_request string) (db.InvoiceP
 }
 
 func (h *bountyHandler) PayV1LightningInvoice(payment_request string) (db.InvoicePaySuccess, db.InvoicePayError) {
-	url := fmt.Sprintf("%s/invoices", config.RelayUrl)
+	url := fmt.Sprintf("%s/invoices", h.cfg.Relay.URL)
 	bodyData := fmt.Sprintf(`{"payment_request": "%s"}`, payment_request)
 	jsonBody := []byte(bodyData)
 
@@ -1712,7 +1622,7 @@ func (h *bountyHandler) PayV1LightningInvoice(payment_request string) (db.Invoic
 		log.Printf("Error paying invoice: %s", err)
 	}
 
-	req.Header.Set("x-user-token", config.RelayAuthKey)
+	req.Header.Set("x-user-token", h.cfg.Relay.AuthKey)
 	req.Header.Set("Content-Type", "application/json")
 	res, err := h.httpClient.Do(req)
 
@@ -1753,7 +1663,7 @@ func (h *bountyHandler) PayV1LightningInvoice(payment_request string) (db.Invoic
 }
 
 func (h *bountyHandler) PayV2LightningInvoice(payment_request string) (db.InvoicePaySuccess, db.InvoicePayError) {
-	url := fmt.Sprintf("%s/pay_invoice", config.V2BotUrl)
+	url := fmt.Sprintf("%s/pay_invoice", h.cfg.V2Bot.URL)
 	bodyData := fmt.Sprintf(`{"bolt11": "%s", "wait": true}`, payment_request)
 	jsonBody := []byte(bodyData)
 
@@ -1764,7 +1674,7 @@ func (h *bountyHandler) PayV2LightningInvoice(payment_request string) (db.Invoic
 		return db.InvoicePaySuccess{}, db.InvoicePayError{}
 	}
 
-	req.Header.Set("x-admin-token", config.V2BotToken)
+	req.Header.Set("x-admin-token", h.cfg.V2Bot.Token)
 	req.Header.Set("Content-Type", "application/json")
 
 	res, err := h.httpClient.Do(req)
@@ -2126,7 +2036,7 @@ func (h *bountyHandler) AddProofOfWork(w http.ResponseWriter, r *http.Request) {
 		msg := fmt.Sprintf("%s has submitted PoW on Bounty %s/bounty/%d. %s", assineeAlias, os.Getenv("HOST"), bountyId, bountyTitle)
 
 		if ownerPubKey != "" {
-			processNotification(ownerPubKey, "bounty_assigned", msg, ownerAlias, ownerRouteHint)
+			processNotification(h.cfg.V2Bot, ownerPubKey, "bounty_assigned", msg, ownerAlias, ownerRouteHint)
 		}
 
 	}
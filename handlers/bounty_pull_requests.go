@@ -0,0 +1,561 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/go-github/v39/github"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/utils"
+)
+
+type BountyPullRequestRequest struct {
+	URL          string `json:"url"`
+	AutoComplete *bool  `json:"auto_complete,omitempty"`
+	AutoPay      *bool  `json:"auto_pay,omitempty"`
+}
+
+func bountyWorkspaceUuid(bounty db.NewBounty) string {
+	if bounty.WorkspaceUuid == "" {
+		return bounty.OrgUuid
+	}
+	return bounty.WorkspaceUuid
+}
+
+// bountyForPullRequests returns the bounty of the request if pubkey is its
+// owner, its assignee or a member of its workspace, or writes the error.
+func (h *bountyHandler) bountyForPullRequests(w http.ResponseWriter, r *http.Request, pubkey string) (db.NewBounty, bool) {
+	id, err := utils.ConvertStringToUint(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid bounty ID"})
+		return db.NewBounty{}, false
+	}
+	bounty := h.db.GetBounty(id)
+	if bounty.ID != id {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "bounty not found"})
+		return db.NewBounty{}, false
+	}
+
+	workspaceUuid := bountyWorkspaceUuid(bounty)
+	if pubkey != bounty.OwnerID && pubkey != bounty.Assignee {
+		workspace := h.db.GetWorkspaceByUuid(workspaceUuid)
+		if workspace.OwnerPubKey != pubkey && h.db.GetWorkspaceUser(pubkey, workspaceUuid).OwnerPubKey != pubkey {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": "only the bounty owner, its assignee and workspace members can manage its pull requests"})
+			return db.NewBounty{}, false
+		}
+	}
+	return bounty, true
+}
+
+// GetBountyPullRequests godoc
+//
+//	@Summary		List the pull requests of a bounty
+//	@Tags			Bounties - Proof of Work
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			id	path	string	true	"Bounty ID"
+//	@Success		200	{array}	db.BountyPullRequest
+//	@Router			/gobounties/{id}/pulls [get]
+func (h *bountyHandler) GetBountyPullRequests(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[bounty] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	bounty, ok := h.bountyForPullRequests(w, r, pubKeyFromAuth)
+	if !ok {
+		return
+	}
+
+	pulls, err := h.db.GetBountyPullRequests(bounty.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pulls)
+}
+
+// AddBountyPullRequest godoc
+//
+//	@Summary		Link a pull request to a bounty
+//	@Description	Link a GitHub pull request of one of the workspace repositories to a bounty and submit it as proof of work. The bounty is tracked through the pull request webhook; users with the pay bounty role can set auto_complete to complete the bounty and auto_pay to also queue its payment when the pull request merges.
+//	@Tags			Bounties - Proof of Work
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			id		path		string						true	"Bounty ID"
+//	@Param			pull	body		BountyPullRequestRequest	true	"Pull Request"
+//	@Success		201		{object}	db.BountyPullRequest
+//	@Router			/gobounties/{id}/pulls [post]
+func (h *bountyHandler) AddBountyPullRequest(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[bounty] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	bounty, ok := h.bountyForPullRequests(w, r, pubKeyFromAuth)
+	if !ok {
+		return
+	}
+	workspaceUuid := bountyWorkspaceUuid(bounty)
+
+	var request BountyPullRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error parsing request body"})
+		return
+	}
+	owner, repo, number, err := ParseGithubPullRequestURL(request.URL)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	var repository db.WorkspaceRepositories
+	for _, candidate := range h.db.GetWorkspaceRepositorByWorkspaceUuid(workspaceUuid) {
+		repoOwner, repoName, err := ParseGithubRepoURL(candidate.Url)
+		if err == nil && strings.EqualFold(repoOwner, owner) && strings.EqualFold(repoName, repo) {
+			repository = candidate
+			break
+		}
+	}
+	if repository.Uuid == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "pull request does not belong to a repository of the workspace"})
+		return
+	}
+
+	autoComplete := request.AutoComplete != nil && *request.AutoComplete
+	autoPay := request.AutoPay != nil && *request.AutoPay
+	if (autoComplete || autoPay) && !h.userHasAccess(pubKeyFromAuth, workspaceUuid, db.PayBounty) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only users with the pay bounty role can set auto_complete or auto_pay"})
+		return
+	}
+
+	pr, err := h.getPullRequest(owner, repo, number)
+	if err != nil {
+		logger.Log.Error("[bounty] failed to fetch pull request %s: %v", request.URL, err)
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]string{"error": "failed to fetch the pull request from GitHub"})
+		return
+	}
+
+	now := time.Now()
+	proof := db.ProofOfWork{
+		ID:          uuid.New(),
+		BountyID:    bounty.ID,
+		Description: fmt.Sprintf("Pull request %s/%s#%d: %s\n%s", owner, repo, number, pr.Title, request.URL),
+		Status:      db.NewStatus,
+		CreatedAt:   now,
+		SubmittedAt: now,
+	}
+	pull := db.BountyPullRequest{
+		BountyID:       bounty.ID,
+		WorkspaceUuid:  workspaceUuid,
+		RepositoryUuid: repository.Uuid,
+		Owner:          owner,
+		Repo:           repo,
+		Number:         number,
+		URL:            request.URL,
+		Title:          pr.Title,
+		Author:         pr.Author,
+		State:          db.PullRequestOpen,
+		SubmittedBy:    pubKeyFromAuth,
+		ProofID:        &proof.ID,
+		AutoComplete:   autoComplete || autoPay,
+		AutoPay:        autoPay,
+	}
+	if autoPay {
+		pull.AutoPayBy = pubKeyFromAuth
+	}
+
+	var saved db.BountyPullRequest
+	err = h.db.InTransaction(func(tx db.Database) error {
+		var err error
+		if saved, err = tx.CreateBountyPullRequest(&pull); err != nil {
+			return err
+		}
+		if err := tx.CreateProof(proof); err != nil {
+			return err
+		}
+		return tx.IncrementProofCount(bounty.ID)
+	})
+	if errors.Is(err, db.ErrPullRequestLinked) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.PauseBountyTiming(bounty.ID); err != nil {
+		logger.Log.Error("[bounty_timing] pause_timing failed: %v", err)
+	}
+	if err := h.db.UpdateBountyTimingOnProof(bounty.ID); err != nil {
+		logger.Log.Error("[bounty_timing] update_timing_on_proof failed: %v", err)
+	}
+
+	// the pull request may have moved on before it was linked. Anyone could
+	// link a pull request that already merged or closed, so its proof is
+	// left for the bounty owner to review instead of being accepted.
+	switch pr.State {
+	case db.PullRequestOpen:
+	case db.PullRequestReview:
+		if err := h.applyPullRequestState(&saved, pr); err != nil {
+			logger.Log.Error("[bounty] failed to apply the state of %s: %v", request.URL, err)
+		}
+	default:
+		saved.State = pr.State
+		saved.MergedAt = pr.MergedAt
+		if err := h.db.UpdateBountyPullRequest(&saved); err != nil {
+			logger.Log.Error("[bounty] failed to record the state of %s: %v", request.URL, err)
+		}
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(saved)
+}
+
+// UpdateBountyPullRequest godoc
+//
+//	@Summary		Change what happens when a bounty pull request merges
+//	@Description	Set auto_complete and auto_pay of a pull request linked to a bounty. Requires the pay bounty role.
+//	@Tags			Bounties - Proof of Work
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			id		path		string						true	"Bounty ID"
+//	@Param			pullId	path		string						true	"Pull Request Link ID"
+//	@Param			pull	body		BountyPullRequestRequest	true	"Options"
+//	@Success		200		{object}	db.BountyPullRequest
+//	@Router			/gobounties/{id}/pulls/{pullId} [patch]
+func (h *bountyHandler) UpdateBountyPullRequest(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[bounty] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	bounty, ok := h.bountyForPullRequests(w, r, pubKeyFromAuth)
+	if !ok {
+		return
+	}
+	pull, ok := h.bountyPullRequest(w, r, bounty)
+	if !ok {
+		return
+	}
+
+	var request BountyPullRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error parsing request body"})
+		return
+	}
+	if !h.userHasAccess(pubKeyFromAuth, bountyWorkspaceUuid(bounty), db.PayBounty) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only users with the pay bounty role can set auto_complete or auto_pay"})
+		return
+	}
+
+	if request.AutoComplete != nil {
+		pull.AutoComplete = *request.AutoComplete
+	}
+	if request.AutoPay != nil {
+		pull.AutoPay = *request.AutoPay
+		pull.AutoPayBy = ""
+		if pull.AutoPay {
+			pull.AutoPayBy = pubKeyFromAuth
+		}
+	}
+	// paying completes the bounty
+	if pull.AutoPay {
+		pull.AutoComplete = true
+	}
+
+	if err := h.db.UpdateBountyPullRequest(&pull); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pull)
+}
+
+// DeleteBountyPullRequest godoc
+//
+//	@Summary		Unlink a pull request from a bounty
+//	@Description	Unlink a pull request from a bounty. Its proof of work is kept.
+//	@Tags			Bounties - Proof of Work
+//	@Security		PubKeyContextAuth
+//	@Param			id		path	string	true	"Bounty ID"
+//	@Param			pullId	path	string	true	"Pull Request Link ID"
+//	@Success		204
+//	@Router			/gobounties/{id}/pulls/{pullId} [delete]
+func (h *bountyHandler) DeleteBountyPullRequest(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[bounty] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	bounty, ok := h.bountyForPullRequests(w, r, pubKeyFromAuth)
+	if !ok {
+		return
+	}
+	pull, ok := h.bountyPullRequest(w, r, bounty)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteBountyPullRequest(pull.ID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *bountyHandler) bountyPullRequest(w http.ResponseWriter, r *http.Request, bounty db.NewBounty) (db.BountyPullRequest, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "pullId"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid pull request ID"})
+		return db.BountyPullRequest{}, false
+	}
+	pull, err := h.db.GetBountyPullRequest(id)
+	if err != nil || pull.BountyID != bounty.ID {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": db.ErrPullRequestNotFound.Error()})
+		return db.BountyPullRequest{}, false
+	}
+	return pull, true
+}
+
+// applyPullRequestState records a new state of a linked pull request. A
+// merge accepts its proof of work and, as configured on the link, completes
+// the bounty and queues its payment; a close without a merge rejects the
+// proof. Review only moves forward while the pull request is open.
+func (h *bountyHandler) applyPullRequestState(pull *db.BountyPullRequest, pr db.GithubPullRequest) error {
+	previous := pull.State
+	if pr.Title != "" {
+		pull.Title = pr.Title
+	}
+	if !(pr.State == db.PullRequestOpen && previous == db.PullRequestReview) {
+		pull.State = pr.State
+	}
+
+	if pull.State != previous {
+		switch pull.State {
+		case db.PullRequestMerged:
+			now := time.Now()
+			pull.MergedAt = pr.MergedAt
+			if pull.MergedAt == nil {
+				pull.MergedAt = &now
+			}
+			if pull.ProofID != nil {
+				if err := h.db.UpdateProofStatus(pull.ProofID.String(), db.AcceptedStatus); err != nil {
+					return err
+				}
+			}
+			if err := h.db.CloseBountyTiming(pull.BountyID); err != nil {
+				logger.Log.Error("[bounty_timing] close_timing failed for bounty %d: %v", pull.BountyID, err)
+			}
+
+			if pull.AutoComplete || pull.AutoPay {
+				bounty := h.db.GetBounty(pull.BountyID)
+				if bounty.ID == pull.BountyID && !bounty.Paid && !bounty.Completed {
+					bounty.Completed = true
+					bounty.CompletionDate = &now
+					if _, err := h.db.UpdateBounty(bounty); err != nil {
+						return err
+					}
+				}
+				if pull.AutoPay && !bounty.Paid && pull.PaymentQueuedAt == nil {
+					pull.PaymentQueuedAt = &now
+				}
+			}
+		case db.PullRequestClosed:
+			if pull.ProofID != nil {
+				if err := h.db.UpdateProofStatus(pull.ProofID.String(), db.RejectedStatus); err != nil {
+					return err
+				}
+			}
+			if err := h.db.ResumeBountyTiming(pull.BountyID); err != nil {
+				logger.Log.Error("[bounty_timing] resume_timing failed for bounty %d: %v", pull.BountyID, err)
+			}
+		}
+	}
+
+	return h.db.UpdateBountyPullRequest(pull)
+}
+
+// BountyPullRequestWebhook godoc
+//
+//	@Summary		Receive GitHub pull request events
+//	@Description	Track the state of pull requests linked to bounties. Requests must be signed with the configured webhook secret in X-Hub-Signature-256.
+//	@Tags			Bounties - Proof of Work
+//	@Accept			json
+//	@Produce		json
+//	@Success		200
+//	@Failure		401
+//	@Router			/gobounties/pulls/webhook [post]
+func (h *bountyHandler) BountyPullRequestWebhook(w http.ResponseWriter, r *http.Request) {
+	secret := h.cfg.Github.WebhookSecret
+	if secret == "" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "github webhook is not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxGithubWebhookBytes))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error reading request body"})
+		return
+	}
+	if !validGithubSignature(secret, body, r.Header.Get("X-Hub-Signature-256")) {
+		logger.Log.Info("[bounty] pull request webhook with an invalid signature")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid signature"})
+		return
+	}
+
+	var pr db.GithubPullRequest
+	var repository *github.Repository
+	switch r.Header.Get("X-GitHub-Event") {
+	case "ping":
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "pong"})
+		return
+	case "pull_request":
+		var event github.PullRequestEvent
+		if err := json.Unmarshal(body, &event); err != nil || event.PullRequest == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Error parsing pull request event"})
+			return
+		}
+		pr = githubPullRequestDetails(event.PullRequest)
+		repository = event.Repo
+		if event.GetAction() == "converted_to_draft" && pr.State == db.PullRequestReview {
+			pr.State = db.PullRequestOpen
+		}
+	case "pull_request_review":
+		var event github.PullRequestReviewEvent
+		if err := json.Unmarshal(body, &event); err != nil || event.PullRequest == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Error parsing pull request review event"})
+			return
+		}
+		pr = githubPullRequestDetails(event.PullRequest)
+		repository = event.Repo
+		if pr.State == db.PullRequestOpen {
+			pr.State = db.PullRequestReview
+		}
+	default:
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "event ignored"})
+		return
+	}
+	if repository == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "event has no repository"})
+		return
+	}
+
+	pulls, err := h.db.GetBountyPullRequestsByPull(repository.GetOwner().GetLogin(), repository.GetName(), pr.Number)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	updated := 0
+	for i := range pulls {
+		if err := h.applyPullRequestState(&pulls[i], pr); err != nil {
+			logger.Log.Error("[bounty] failed to update pull request %s of bounty %d: %v", pulls[i].URL, pulls[i].BountyID, err)
+			continue
+		}
+		updated++
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]int{"updated": updated})
+}
+
+// processQueuedBountyPayments pays the bounties whose payment was queued by
+// a merged pull request, on behalf of the user who set auto_pay. A payment
+// is attempted once; failures are left to be retried by hand.
+func (h *bountyHandler) processQueuedBountyPayments() {
+	pulls, err := h.db.GetQueuedBountyPullRequests()
+	if err != nil {
+		logger.Log.Error("[bounty] failed to fetch queued payments: %v", err)
+		return
+	}
+
+	for i := range pulls {
+		pull := &pulls[i]
+		bounty := h.db.GetBounty(pull.BountyID)
+		if bounty.ID != pull.BountyID {
+			continue
+		}
+
+		if !bounty.Paid && !bounty.PaymentPending {
+			if bounty.Assignee == "" {
+				logger.Log.Info("[bounty] queued payment of bounty %d waits for an assignee", bounty.ID)
+				continue
+			}
+			budget := h.db.GetWorkspaceBudget(bountyWorkspaceUuid(bounty))
+			if budget.TotalBudget < bounty.Price {
+				logger.Log.Info("[bounty] queued payment of bounty %d waits for workspace budget", bounty.ID)
+				continue
+			}
+			if !h.userHasAccess(pull.AutoPayBy, bountyWorkspaceUuid(bounty), db.PayBounty) {
+				logger.Log.Info("[bounty] %s can no longer pay bounty %d, dropping its queued payment", pull.AutoPayBy, bounty.ID)
+			} else if _, err := h.payBounty(bounty, pull.AutoPayBy); errors.Is(err, errBountyPaymentClaimed) {
+				logger.Log.Info("[bounty] bounty %d was paid by someone else", bounty.ID)
+			} else if err != nil {
+				logger.Log.Error("[bounty] queued payment of bounty %d failed: %v", bounty.ID, err)
+			}
+		}
+
+		now := time.Now()
+		pull.PaymentProcessedAt = &now
+		if err := h.db.UpdateBountyPullRequest(pull); err != nil {
+			logger.Log.Error("[bounty] failed to update queued payment of bounty %d: %v", bounty.ID, err)
+		}
+	}
+}
+
+// ProcessQueuedBountyPayments is the job that pays bounties whose pull
+// request merged with auto_pay set.
+func ProcessQueuedBountyPayments() {
+	NewBountyHandler(http.DefaultClient, db.DB).processQueuedBountyPayments()
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/handlers/mocks"
+	dbMocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newPullRequestBountyHandler(t *testing.T, canPay bool, pr db.GithubPullRequest) (*bountyHandler, *dbMocks.Database, *mocks.HttpClient) {
+	mockDb := dbMocks.NewDatabase(t)
+	mockHttpClient := mocks.NewHttpClient(t)
+	h := NewBountyHandler(mockHttpClient, mockDb)
+	h.cfg = config.Defaults()
+	h.cfg.Github.WebhookSecret = "hook-secret"
+	h.userHasAccess = func(pubKeyFromAuth string, uuid string, role string) bool { return canPay }
+	h.getPullRequest = func(owner, repo string, number int) (db.GithubPullRequest, error) { return pr, nil }
+	return h, mockDb, mockHttpClient
+}
+
+func TestParseGithubPullRequestURL(t *testing.T) {
+	owner, repo, number, err := ParseGithubPullRequestURL("https://github.com/stakwork/sphinx-tribes/pull/2041/files")
+	require.NoError(t, err)
+	assert.Equal(t, "stakwork", owner)
+	assert.Equal(t, "sphinx-tribes", repo)
+	assert.Equal(t, 2041, number)
+
+	_, _, _, err = ParseGithubPullRequestURL("https://github.com/stakwork/sphinx-tribes/issues/12")
+	assert.Error(t, err)
+	_, _, _, err = ParseGithubPullRequestURL("https://github.com/stakwork/sphinx-tribes/pull/abc")
+	assert.Error(t, err)
+}
+
+func TestAddBountyPullRequest(t *testing.T) {
+	bounty := db.NewBounty{ID: 5, OwnerID: "owner-pubkey", Assignee: "test-pubkey", WorkspaceUuid: "workspace-1", Price: 100}
+	pr := db.GithubPullRequest{Number: 12, Title: "Fix login", State: db.PullRequestOpen, Author: "octocat"}
+	params := map[string]string{"id": "5"}
+	repos := []db.WorkspaceRepositories{{Uuid: "repo-1", Url: "https://github.com/stakwork/sphinx-tribes"}}
+
+	t.Run("Links A Workspace Pull Request", func(t *testing.T) {
+		h, mockDb, _ := newPullRequestBountyHandler(t, false, pr)
+		mockDb.On("GetBounty", uint(5)).Return(bounty)
+		mockDb.On("GetWorkspaceRepositorByWorkspaceUuid", "workspace-1").Return(repos)
+		mockDb.On("InTransaction", mock.Anything).Return(func(fn func(db.Database) error) error { return fn(mockDb) })
+		mockDb.On("CreateBountyPullRequest", mock.AnythingOfType("*db.BountyPullRequest")).Return(func(pull *db.BountyPullRequest) db.BountyPullRequest { return *pull }, nil)
+		var proof db.ProofOfWork
+		mockDb.On("CreateProof", mock.AnythingOfType("db.ProofOfWork")).Run(func(args mock.Arguments) {
+			proof = args.Get(0).(db.ProofOfWork)
+		}).Return(nil)
+		mockDb.On("IncrementProofCount", uint(5)).Return(nil)
+		mockDb.On("PauseBountyTiming", uint(5)).Return(nil)
+		mockDb.On("UpdateBountyTimingOnProof", uint(5)).Return(nil)
+
+		rr := httptest.NewRecorder()
+		h.AddBountyPullRequest(rr, newBranchRequest(http.MethodPost, "/gobounties/5/pulls", `{"url":"https://github.com/Stakwork/sphinx-tribes/pull/12"}`, params))
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var pull db.BountyPullRequest
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&pull))
+		assert.Equal(t, "repo-1", pull.RepositoryUuid)
+		assert.Equal(t, 12, pull.Number)
+		assert.Equal(t, "octocat", pull.Author)
+		assert.False(t, pull.AutoPay)
+		require.NotNil(t, pull.ProofID)
+		assert.Equal(t, proof.ID, *pull.ProofID)
+		assert.Contains(t, proof.Description, "https://github.com/Stakwork/sphinx-tribes/pull/12")
+	})
+
+	t.Run("Merged Pull Request Is Left For Review", func(t *testing.T) {
+		merged := pr
+		merged.State = db.PullRequestMerged
+		h, mockDb, _ := newPullRequestBountyHandler(t, true, merged)
+		mockDb.On("GetBounty", uint(5)).Return(bounty)
+		mockDb.On("GetWorkspaceRepositorByWorkspaceUuid", "workspace-1").Return(repos)
+		mockDb.On("InTransaction", mock.Anything).Return(func(fn func(db.Database) error) error { return fn(mockDb) })
+		mockDb.On("CreateBountyPullRequest", mock.AnythingOfType("*db.BountyPullRequest")).Return(func(pull *db.BountyPullRequest) db.BountyPullRequest { return *pull }, nil)
+		mockDb.On("CreateProof", mock.AnythingOfType("db.ProofOfWork")).Return(nil)
+		mockDb.On("IncrementProofCount", uint(5)).Return(nil)
+		mockDb.On("PauseBountyTiming", uint(5)).Return(nil)
+		mockDb.On("UpdateBountyTimingOnProof", uint(5)).Return(nil)
+		mockDb.On("UpdateBountyPullRequest", mock.MatchedBy(func(p *db.BountyPullRequest) bool {
+			return p.State == db.PullRequestMerged && p.PaymentQueuedAt == nil
+		})).Return(nil)
+
+		rr := httptest.NewRecorder()
+		h.AddBountyPullRequest(rr, newBranchRequest(http.MethodPost, "/gobounties/5/pulls", `{"url":"https://github.com/stakwork/sphinx-tribes/pull/12","auto_pay":true}`, params))
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		mockDb.AssertNotCalled(t, "UpdateProofStatus", mock.Anything, mock.Anything)
+		mockDb.AssertNotCalled(t, "UpdateBounty", mock.Anything)
+	})
+
+	t.Run("Rejects Other Repositories", func(t *testing.T) {
+		h, mockDb, _ := newPullRequestBountyHandler(t, false, pr)
+		mockDb.On("GetBounty", uint(5)).Return(bounty)
+		mockDb.On("GetWorkspaceRepositorByWorkspaceUuid", "workspace-1").Return(repos)
+
+		rr := httptest.NewRecorder()
+		h.AddBountyPullRequest(rr, newBranchRequest(http.MethodPost, "/gobounties/5/pulls", `{"url":"https://github.com/someone/else/pull/3"}`, params))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Auto Pay Needs The Pay Role", func(t *testing.T) {
+		h, mockDb, _ := newPullRequestBountyHandler(t, false, pr)
+		mockDb.On("GetBounty", uint(5)).Return(bounty)
+		mockDb.On("GetWorkspaceRepositorByWorkspaceUuid", "workspace-1").Return(repos)
+
+		rr := httptest.NewRecorder()
+		h.AddBountyPullRequest(rr, newBranchRequest(http.MethodPost, "/gobounties/5/pulls", `{"url":"https://github.com/stakwork/sphinx-tribes/pull/12","auto_pay":true}`, params))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Outsiders Are Forbidden", func(t *testing.T) {
+		h, mockDb, _ := newPullRequestBountyHandler(t, false, pr)
+		other := bounty
+		other.Assignee = "hunter-pubkey"
+		mockDb.On("GetBounty", uint(5)).Return(other)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: "owner-pubkey"})
+		mockDb.On("GetWorkspaceUser", "test-pubkey", "workspace-1").Return(db.WorkspaceUsers{})
+
+		rr := httptest.NewRecorder()
+		h.AddBountyPullRequest(rr, newBranchRequest(http.MethodPost, "/gobounties/5/pulls", `{"url":"https://github.com/stakwork/sphinx-tribes/pull/12"}`, params))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestGetBountyPullRequests(t *testing.T) {
+	h, mockDb, _ := newPullRequestBountyHandler(t, false, db.GithubPullRequest{})
+	mockDb.On("GetBounty", uint(5)).Return(db.NewBounty{ID: 5, OwnerID: "owner-pubkey", WorkspaceUuid: "workspace-1"})
+	mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: "owner-pubkey"})
+	mockDb.On("GetWorkspaceUser", "test-pubkey", "workspace-1").Return(db.WorkspaceUsers{})
+
+	rr := httptest.NewRecorder()
+	h.GetBountyPullRequests(rr, newBranchRequest(http.MethodGet, "/gobounties/5/pulls", "", map[string]string{"id": "5"}))
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	mockDb.AssertNotCalled(t, "GetBountyPullRequests", mock.Anything)
+}
+
+func TestBountyPullRequestWebhook(t *testing.T) {
+	webhook := func(h *bountyHandler, event string, body string, signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/gobounties/pulls/webhook", strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", event)
+		req.Header.Set("X-Hub-Signature-256", signature)
+		rr := httptest.NewRecorder()
+		h.BountyPullRequestWebhook(rr, req)
+		return rr
+	}
+
+	t.Run("Rejects Bad Signatures", func(t *testing.T) {
+		h, _, _ := newPullRequestBountyHandler(t, false, db.GithubPullRequest{})
+		body := `{"action":"closed"}`
+
+		rr := webhook(h, "pull_request", body, signGithubPayload("wrong-secret", []byte(body)))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Merge Completes The Bounty And Queues Payment", func(t *testing.T) {
+		h, mockDb, _ := newPullRequestBountyHandler(t, true, db.GithubPullRequest{})
+		proofID := uuid.New()
+		pull := db.BountyPullRequest{ID: uuid.New(), BountyID: 5, Owner: "stakwork", Repo: "sphinx-tribes", Number: 12, State: db.PullRequestReview, ProofID: &proofID, AutoComplete: true, AutoPay: true, AutoPayBy: "payer-pubkey"}
+		mockDb.On("GetBountyPullRequestsByPull", "stakwork", "sphinx-tribes", 12).Return([]db.BountyPullRequest{pull}, nil)
+		mockDb.On("UpdateProofStatus", proofID.String(), db.AcceptedStatus).Return(nil)
+		mockDb.On("CloseBountyTiming", uint(5)).Return(nil)
+		mockDb.On("GetBounty", uint(5)).Return(db.NewBounty{ID: 5, Assignee: "hunter-pubkey"})
+		mockDb.On("UpdateBounty", mock.MatchedBy(func(b db.NewBounty) bool { return b.Completed && b.CompletionDate != nil })).Return(db.NewBounty{}, nil)
+		var saved db.BountyPullRequest
+		mockDb.On("UpdateBountyPullRequest", mock.AnythingOfType("*db.BountyPullRequest")).Run(func(args mock.Arguments) {
+			saved = *args.Get(0).(*db.BountyPullRequest)
+		}).Return(nil)
+
+		body := `{"action":"closed","pull_request":{"number":12,"title":"Fix login","state":"closed","merged":true,"merged_at":"2026-10-01T10:00:00Z"},"repository":{"name":"sphinx-tribes","owner":{"login":"stakwork"}}}`
+		rr := webhook(h, "pull_request", body, signGithubPayload("hook-secret", []byte(body)))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, db.PullRequestMerged, saved.State)
+		require.NotNil(t, saved.MergedAt)
+		assert.Equal(t, 2026, saved.MergedAt.Year())
+		assert.NotNil(t, saved.PaymentQueuedAt)
+	})
+
+	t.Run("Review Is Not Undone By New Commits", func(t *testing.T) {
+		h, mockDb, _ := newPullRequestBountyHandler(t, true, db.GithubPullRequest{})
+		pull := db.BountyPullRequest{ID: uuid.New(), BountyID: 5, Owner: "stakwork", Repo: "sphinx-tribes", Number: 12, State: db.PullRequestReview}
+		mockDb.On("GetBountyPullRequestsByPull", "stakwork", "sphinx-tribes", 12).Return([]db.BountyPullRequest{pull}, nil)
+		mockDb.On("UpdateBountyPullRequest", mock.MatchedBy(func(p *db.BountyPullRequest) bool { return p.State == db.PullRequestReview })).Return(nil)
+
+		body := `{"action":"synchronize","pull_request":{"number":12,"title":"Fix login","state":"open"},"repository":{"name":"sphinx-tribes","owner":{"login":"stakwork"}}}`
+		rr := webhook(h, "pull_request", body, signGithubPayload("hook-secret", []byte(body)))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestProcessQueuedBountyPayments(t *testing.T) {
+	h, mockDb, mockHttpClient := newPullRequestBountyHandler(t, true, db.GithubPullRequest{})
+	h.cfg.V2Bot = config.V2BotConfig{URL: "http://v2-bot", Token: "bot-token"}
+	pull := db.BountyPullRequest{ID: uuid.New(), BountyID: 5, AutoPay: true, AutoPayBy: "payer-pubkey"}
+	mockDb.On("GetQueuedBountyPullRequests").Return([]db.BountyPullRequest{pull}, nil)
+	mockDb.On("GetBounty", uint(5)).Return(db.NewBounty{ID: 5, Assignee: "hunter-pubkey", WorkspaceUuid: "workspace-1", Price: 100, Title: "Fix login"})
+	mockDb.On("GetWorkspaceBudget", "workspace-1").Return(db.NewBountyBudget{TotalBudget: 1000})
+	mockDb.On("ClaimBountyPayment", uint(5)).Return(true, nil)
+	mockDb.On("GetPersonByPubkey", "hunter-pubkey").Return(db.Person{OwnerPubKey: "hunter-pubkey"})
+	mockHttpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "http://v2-bot/pay" && req.Header.Get("x-admin-token") == "bot-token"
+	})).Return(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"status":"COMPLETE","tag":"tag-1"}`))}, nil)
+	mockDb.On("ProcessBountyPayment", mock.MatchedBy(func(p db.NewPaymentHistory) bool {
+		return p.SenderPubKey == "payer-pubkey" && p.ReceiverPubKey == "hunter-pubkey" && p.Amount == 100 && p.PaymentStatus == db.PaymentComplete
+	}), mock.MatchedBy(func(b db.NewBounty) bool { return b.Paid && b.Completed })).Return(nil)
+	mockDb.On("UpdateBountyPullRequest", mock.MatchedBy(func(p *db.BountyPullRequest) bool { return p.PaymentProcessedAt != nil })).Return(nil)
+
+	h.processQueuedBountyPayments()
+}
+
+func TestProcessQueuedBountyPaymentsPaidElsewhere(t *testing.T) {
+	h, mockDb, mockHttpClient := newPullRequestBountyHandler(t, true, db.GithubPullRequest{})
+	pull := db.BountyPullRequest{ID: uuid.New(), BountyID: 5, AutoPay: true, AutoPayBy: "payer-pubkey"}
+	mockDb.On("GetQueuedBountyPullRequests").Return([]db.BountyPullRequest{pull}, nil)
+	mockDb.On("GetBounty", uint(5)).Return(db.NewBounty{ID: 5, Assignee: "hunter-pubkey", WorkspaceUuid: "workspace-1", Price: 100})
+	mockDb.On("GetWorkspaceBudget", "workspace-1").Return(db.NewBountyBudget{TotalBudget: 1000})
+	// a manual payment claimed the bounty after it was read
+	mockDb.On("ClaimBountyPayment", uint(5)).Return(false, nil)
+	mockDb.On("UpdateBountyPullRequest", mock.MatchedBy(func(p *db.BountyPullRequest) bool { return p.PaymentProcessedAt != nil })).Return(nil)
+
+	h.processQueuedBountyPayments()
+
+	mockHttpClient.AssertNotCalled(t, "Do", mock.Anything)
+}
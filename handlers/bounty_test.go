@@ -32,6 +32,7 @@ import (
 	dbMocks "github.com/stakwork/sphinx-tribes/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 var bountyOwner = db.Person{
@@ -352,7 +353,7 @@ func TestPayLightningInvoice(t *testing.T) {
 	botURL := os.Getenv("V2_BOT_URL")
 	botToken := os.Getenv("V2_BOT_TOKEN")
 
-	expectedUrl := fmt.Sprintf("%s/invoices", config.RelayUrl)
+	expectedUrl := fmt.Sprintf("%s/invoices", config.Current().Relay.URL)
 	expectedBody := `{"payment_request": "req-id"}`
 
 	expectedV2Url := fmt.Sprintf("%s/pay_invoice", botURL)
@@ -371,7 +372,7 @@ func TestPayLightningInvoice(t *testing.T) {
 		} else {
 			mockHttpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
 				bodyByt, _ := io.ReadAll(req.Body)
-				return req.Method == http.MethodPut && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.RelayAuthKey && expectedBody == string(bodyByt)
+				return req.Method == http.MethodPut && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.Current().Relay.AuthKey && expectedBody == string(bodyByt)
 			})).Return(nil, errors.New("some-error")).Once()
 		}
 
@@ -399,7 +400,7 @@ func TestPayLightningInvoice(t *testing.T) {
 		} else {
 			mockHttpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
 				bodyByt, _ := io.ReadAll(req.Body)
-				return req.Method == http.MethodPut && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.RelayAuthKey && expectedBody == string(bodyByt)
+				return req.Method == http.MethodPut && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.Current().Relay.AuthKey && expectedBody == string(bodyByt)
 			})).Return(&http.Response{
 				StatusCode: 500,
 				Body:       r,
@@ -431,7 +432,7 @@ func TestPayLightningInvoice(t *testing.T) {
 		} else {
 			mockHttpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
 				bodyByt, _ := io.ReadAll(req.Body)
-				return req.Method == http.MethodPut && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.RelayAuthKey && expectedBody == string(bodyByt)
+				return req.Method == http.MethodPut && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.Current().Relay.AuthKey && expectedBody == string(bodyByt)
 			})).Return(&http.Response{
 				StatusCode: 500,
 				Body:       r,
@@ -461,7 +462,7 @@ func TestPayLightningInvoice(t *testing.T) {
 		} else {
 			mockHttpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
 				bodyByt, _ := io.ReadAll(req.Body)
-				return req.Method == http.MethodPut && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.RelayAuthKey && expectedBody == string(bodyByt)
+				return req.Method == http.MethodPut && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.Current().Relay.AuthKey && expectedBody == string(bodyByt)
 			})).Return(&http.Response{
 				StatusCode: 200,
 				Body:       r,
@@ -523,7 +524,7 @@ func TestPayLightningInvoice(t *testing.T) {
 		} else {
 			mockHttpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
 				bodyByt, _ := io.ReadAll(req.Body)
-				return req.Method == http.MethodPut && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.RelayAuthKey && expectedBody == string(bodyByt)
+				return req.Method == http.MethodPut && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.Current().Relay.AuthKey && expectedBody == string(bodyByt)
 			})).Return(&http.Response{
 				StatusCode: 200,
 				Body:       r,
@@ -1281,17 +1282,8 @@ func TestMakeBountyPayment(t *testing.T) {
 	mockUserHasAccessFalse := func(pubKeyFromAuth string, uuid string, role string) bool {
 		return false
 	}
-	mockGetSocketConnections := func(host string) (db.Client, error) {
-		s, ws := MockNewWSServer(t)
-		defer s.Close()
-		defer ws.Close()
-
-		mockClient := db.Client{
-			Host: "mocked_host",
-			Conn: ws,
-		}
-
-		return mockClient, nil
+	mockSendToSession := func(sessionID string, message interface{}) error {
+		return nil
 	}
 	bHandler := NewBountyHandler(mockHttpClient, db.TestDB)
 
@@ -1428,13 +1420,13 @@ func TestMakeBountyPayment(t *testing.T) {
 		mockHttpClient := &mocks.HttpClient{}
 
 		bHandler2 := NewBountyHandler(mockHttpClient, db.TestDB)
-		bHandler2.getSocketConnections = mockGetSocketConnections
+		bHandler2.sendToSession = mockSendToSession
 		bHandler2.userHasAccess = mockUserHasAccessTrue
 
 		memoData := fmt.Sprintf("Payment For: %ss", bounty.Title)
 		memoText := url.QueryEscape(memoData)
 
-		expectedUrl := fmt.Sprintf("%s/payment", config.RelayUrl)
+		expectedUrl := fmt.Sprintf("%s/payment", config.Current().Relay.URL)
 		expectedBody := fmt.Sprintf(`{"amount": %d, "destination_key": "%s", "text": "memotext added for notification", "data": "%s"}`, bountyAmount, person.OwnerPubKey, memoText)
 
 		expectedV2Url := fmt.Sprintf("%s/pay", botURL)
@@ -1453,7 +1445,7 @@ func TestMakeBountyPayment(t *testing.T) {
 		} else {
 			mockHttpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
 				bodyByt, _ := io.ReadAll(req.Body)
-				return req.Method == http.MethodPost && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.RelayAuthKey && expectedBody == string(bodyByt)
+				return req.Method == http.MethodPost && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.Current().Relay.AuthKey && expectedBody == string(bodyByt)
 			})).Return(&http.Response{
 				StatusCode: 500,
 				Body:       r,
@@ -1477,13 +1469,13 @@ func TestMakeBountyPayment(t *testing.T) {
 
 	t.Run("Should test that a successful WebSocket message is sent if the payment is successful", func(t *testing.T) {
 
-		bHandler.getSocketConnections = mockGetSocketConnections
+		bHandler.sendToSession = mockSendToSession
 		bHandler.userHasAccess = mockUserHasAccessTrue
 
 		memoData := fmt.Sprintf("Payment For: %ss", bounty.Title)
 		memoText := url.QueryEscape(memoData)
 
-		expectedUrl := fmt.Sprintf("%s/payment", config.RelayUrl)
+		expectedUrl := fmt.Sprintf("%s/payment", config.Current().Relay.URL)
 		expectedBody := fmt.Sprintf(`{"amount": %d, "destination_key": "%s", "text": "memotext added for notification", "data": "%s"}`, bountyAmount, person.OwnerPubKey, memoText)
 
 		expectedV2Url := fmt.Sprintf("%s/pay", botURL)
@@ -1503,7 +1495,7 @@ func TestMakeBountyPayment(t *testing.T) {
 			r := io.NopCloser(bytes.NewReader([]byte(`{"success": true, "response": { "sumAmount": "1"}}`)))
 			mockHttpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
 				bodyByt, _ := io.ReadAll(req.Body)
-				return req.Method == http.MethodPost && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.RelayAuthKey && expectedBody == string(bodyByt)
+				return req.Method == http.MethodPost && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.Current().Relay.AuthKey && expectedBody == string(bodyByt)
 			})).Return(&http.Response{
 				StatusCode: 200,
 				Body:       r,
@@ -1580,6 +1572,125 @@ func TestMakeBountyPayment(t *testing.T) {
 	})
 }
 
+func TestMakeBountyPaymentClaim(t *testing.T) {
+	bounty := db.NewBounty{ID: 5, Assignee: "hunter-pubkey", WorkspaceUuid: "workspace-1", Price: 100, Title: "Fix login"}
+	newHandler := func(t *testing.T, v2 bool) (*bountyHandler, *dbMocks.Database, *mocks.HttpClient) {
+		mockDb := dbMocks.NewDatabase(t)
+		mockHttpClient := mocks.NewHttpClient(t)
+		h := NewBountyHandler(mockHttpClient, mockDb)
+		h.cfg = config.Defaults()
+		h.cfg.Relay = config.RelayConfig{URL: "http://relay", AuthKey: "relay-key"}
+		if v2 {
+			h.cfg.V2Bot = config.V2BotConfig{URL: "http://v2-bot", Token: "bot-token"}
+		}
+		h.userHasAccess = func(pubKeyFromAuth string, uuid string, role string) bool { return true }
+		h.sendToSession = func(sessionID string, message interface{}) error { return errors.New("no socket") }
+		mockDb.On("GetBounty", uint(5)).Return(bounty)
+		mockDb.On("GetWorkspaceBudget", "workspace-1").Return(db.NewBountyBudget{TotalBudget: 1000})
+		return h, mockDb, mockHttpClient
+	}
+	pay := func(h *bountyHandler) (*httptest.ResponseRecorder, map[string]interface{}) {
+		rr := httptest.NewRecorder()
+		h.MakeBountyPayment(rr, newBranchRequest(http.MethodPost, "/gobounties/pay/5", `{"websocket_token":"socket"}`, map[string]string{"id": "5"}))
+		response := map[string]interface{}{}
+		json.NewDecoder(rr.Body).Decode(&response)
+		return rr, response
+	}
+	respond := func(status int, body string) *http.Response {
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}
+	}
+
+	t.Run("Reports The Keysend", func(t *testing.T) {
+		h, mockDb, mockHttpClient := newHandler(t, false)
+		mockDb.On("ClaimBountyPayment", uint(5)).Return(true, nil)
+		mockDb.On("GetPersonByPubkey", "hunter-pubkey").Return(db.Person{OwnerPubKey: "hunter-pubkey"})
+		mockHttpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+			return req.URL.String() == "http://relay/payment" && req.Header.Get("x-user-token") == "relay-key"
+		})).Return(respond(http.StatusOK, `{"success":true}`), nil)
+		mockDb.On("ProcessBountyPayment", mock.MatchedBy(func(p db.NewPaymentHistory) bool {
+			return p.Status && p.SenderPubKey == "test-pubkey" && p.Amount == 100
+		}), mock.MatchedBy(func(b db.NewBounty) bool { return b.Paid && b.Completed && !b.PaymentPending })).Return(nil)
+
+		rr, response := pay(h)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, map[string]interface{}{"msg": keysendSuccess, "invoice": ""}, response)
+	})
+
+	t.Run("Failed Payment Is Recorded And Released", func(t *testing.T) {
+		h, mockDb, mockHttpClient := newHandler(t, true)
+		mockDb.On("ClaimBountyPayment", uint(5)).Return(true, nil)
+		mockDb.On("GetPersonByPubkey", "hunter-pubkey").Return(db.Person{OwnerPubKey: "hunter-pubkey"})
+		mockHttpClient.On("Do", mock.Anything).Return(respond(http.StatusOK, `{"status":"FAILED","message":"no route"}`), nil)
+		mockDb.On("AddPaymentHistory", mock.MatchedBy(func(p db.NewPaymentHistory) bool {
+			return !p.Status && p.PaymentStatus == db.PaymentFailed && p.Error == "no route"
+		})).Return(db.NewPaymentHistory{})
+		mockDb.On("ReleaseBountyPayment", uint(5), true).Return(nil)
+
+		rr, response := pay(h)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, keysendFailed, response["msg"])
+	})
+
+	t.Run("Error Response Is Recorded And Released", func(t *testing.T) {
+		h, mockDb, mockHttpClient := newHandler(t, false)
+		mockDb.On("ClaimBountyPayment", uint(5)).Return(true, nil)
+		mockDb.On("GetPersonByPubkey", "hunter-pubkey").Return(db.Person{OwnerPubKey: "hunter-pubkey"})
+		mockHttpClient.On("Do", mock.Anything).Return(respond(http.StatusInternalServerError, `"internal server error"`), nil)
+		mockDb.On("AddPaymentHistory", mock.MatchedBy(func(p db.NewPaymentHistory) bool {
+			return p.PaymentStatus == db.PaymentFailed
+		})).Return(db.NewPaymentHistory{})
+		mockDb.On("ReleaseBountyPayment", uint(5), true).Return(nil)
+
+		rr, response := pay(h)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, keysendError, response["msg"])
+	})
+
+	keepsClaim := func(t *testing.T, h *bountyHandler, mockDb *dbMocks.Database) {
+		mockDb.On("ProcessBountyPayment", mock.MatchedBy(func(p db.NewPaymentHistory) bool {
+			return !p.Status && p.PaymentStatus == db.PaymentPending && p.Error != ""
+		}), mock.MatchedBy(func(b db.NewBounty) bool { return b.PaymentPending && !b.Paid && !b.PaymentFailed })).Return(nil)
+
+		rr, response := pay(h)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, keysendPending, response["msg"])
+		mockDb.AssertNotCalled(t, "ReleaseBountyPayment", mock.Anything, mock.Anything)
+		mockDb.AssertNotCalled(t, "AddPaymentHistory", mock.Anything)
+	}
+
+	t.Run("Unanswered Request Keeps The Claim", func(t *testing.T) {
+		h, mockDb, mockHttpClient := newHandler(t, false)
+		mockDb.On("ClaimBountyPayment", uint(5)).Return(true, nil)
+		mockDb.On("GetPersonByPubkey", "hunter-pubkey").Return(db.Person{OwnerPubKey: "hunter-pubkey"})
+		mockHttpClient.On("Do", mock.Anything).Return(nil, errors.New("connection reset"))
+
+		keepsClaim(t, h, mockDb)
+	})
+
+	t.Run("Unreadable Response Keeps The Claim", func(t *testing.T) {
+		h, mockDb, mockHttpClient := newHandler(t, true)
+		mockDb.On("ClaimBountyPayment", uint(5)).Return(true, nil)
+		mockDb.On("GetPersonByPubkey", "hunter-pubkey").Return(db.Person{OwnerPubKey: "hunter-pubkey"})
+		mockHttpClient.On("Do", mock.Anything).Return(respond(http.StatusOK, `<html>bad gateway</html>`), nil)
+
+		keepsClaim(t, h, mockDb)
+	})
+
+	t.Run("Claimed Elsewhere", func(t *testing.T) {
+		h, mockDb, mockHttpClient := newHandler(t, false)
+		mockDb.On("ClaimBountyPayment", uint(5)).Return(false, nil)
+
+		rr, _ := pay(h)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockHttpClient.AssertNotCalled(t, "Do", mock.Anything)
+	})
+}
+
 func TestUpdateBountyPaymentStatus(t *testing.T) {
 	ctx := context.Background()
 
@@ -2118,7 +2229,7 @@ func TestPollInvoice(t *testing.T) {
 	})
 
 	t.Run("Should test that a 403 error is returned if there is an invoice error", func(t *testing.T) {
-		expectedUrl := fmt.Sprintf("%s/invoice?payment_request=%s", config.RelayUrl, invoice.PaymentRequest)
+		expectedUrl := fmt.Sprintf("%s/invoice?payment_request=%s", config.Current().Relay.URL, invoice.PaymentRequest)
 
 		expectedV2Url := fmt.Sprintf("%s/check_invoice", botURL)
 
@@ -2133,7 +2244,7 @@ func TestPollInvoice(t *testing.T) {
 			}, nil).Once()
 		} else {
 			mockHttpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-				return req.Method == http.MethodGet && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.RelayAuthKey
+				return req.Method == http.MethodGet && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.Current().Relay.AuthKey
 			})).Return(&http.Response{
 				StatusCode: 500,
 				Body:       r,
@@ -2172,7 +2283,7 @@ func TestPollInvoice(t *testing.T) {
 		mockHttpClient := &mocks.HttpClient{}
 		bHandler := NewBountyHandler(mockHttpClient, db.TestDB)
 		authorizedCtx := context.WithValue(ctx, auth.ContextKey, invoice.OwnerPubkey)
-		expectedUrl := fmt.Sprintf("%s/invoice?payment_request=%s", config.RelayUrl, invoice.PaymentRequest)
+		expectedUrl := fmt.Sprintf("%s/invoice?payment_request=%s", config.Current().Relay.URL, invoice.PaymentRequest)
 		expectedBody := fmt.Sprintf(`{"success": true, "response": { "settled": true, "payment_request": "%s", "payment_hash": "payment_hash", "preimage": "preimage", "Amount": %d}}`, invoice.OwnerPubkey, bountyAmount)
 
 		expectedV2Url := fmt.Sprintf("%s/check_invoice", botURL)
@@ -2190,7 +2301,7 @@ func TestPollInvoice(t *testing.T) {
 			}, nil).Once()
 		} else {
 			mockHttpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-				return req.Method == http.MethodGet && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.RelayAuthKey
+				return req.Method == http.MethodGet && expectedUrl == req.URL.String() && req.Header.Get("x-user-token") == config.Current().Relay.AuthKey
 			})).Return(&http.Response{
 				StatusCode: 200,
 				Body:       r,
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -18,6 +19,8 @@ import (
 	"time"
 
 	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/knowledge"
 	"github.com/stakwork/sphinx-tribes/logger"
 	"gorm.io/gorm"
 
@@ -27,13 +30,23 @@ import (
 
 	"github.com/go-chi/chi"
 	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/extract"
 	"github.com/stakwork/sphinx-tribes/sse"
+	"github.com/stakwork/sphinx-tribes/storage"
 )
 
 // ChatHandler handles chat-related requests
 type ChatHandler struct {
-	httpClient *http.Client
-	db         db.Database
+	httpClient         *http.Client
+	webhookClient      *http.Client
+	db                 db.Database
+	streams            *sse.Broker
+	streamPollInterval time.Duration
+	streamHeartbeat    time.Duration
+	files              storage.BlobStore
+	indexFile          func(asset db.FileAsset)
+	knowledge          *knowledge.Base
+	cfg                *config.Config
 }
 
 // ChatResponse is the response format for chat requests
@@ -89,19 +102,41 @@ type SendMessageRequest struct {
 }
 
 type BuildMessageRequest struct {
-	Question string `json:"question"`
+	Question      string `json:"question"`
+	WorkspaceUUID string `json:"workspaceUUID"`
 }
 
+// ChatResponseRequest is a complete assistant reply. ReplyMessageID is the
+// reply sent with the workflow; requests without one complete the pending
+// reply to MessageID, or add a new reply after it.
 type ChatResponseRequest struct {
 	Value struct {
 		ChatID            string                `json:"chatId"`
 		MessageID         string                `json:"messageId"`
+		ReplyMessageID    string                `json:"replyMessageId,omitempty"`
 		Response          string                `json:"response"`
 		SourceWebsocketID string                `json:"sourceWebsocketId"`
 		Artifacts         []ChatMessageArtifact `json:"artifacts,omitempty"`
 	} `json:"value"`
 }
 
+// ChatStreamChunkRequest is one chunk of a streamed assistant reply.
+// MessageID is the replyMessageId sent with the workflow. Sequence starts at
+// 1 and increases with every chunk of the message; the last request has Done
+// set and may carry the full response and the artifacts.
+type ChatStreamChunkRequest struct {
+	Value struct {
+		ChatID            string                `json:"chatId"`
+		MessageID         string                `json:"messageId"`
+		Sequence          int                   `json:"sequence"`
+		Delta             string                `json:"delta"`
+		Done              bool                  `json:"done"`
+		Response          string                `json:"response,omitempty"`
+		SourceWebsocketID string                `json:"sourceWebsocketId"`
+		Artifacts         []ChatMessageArtifact `json:"artifacts,omitempty"`
+	} `json:"value"`
+}
+
 type ChatMessageArtifact struct {
 	ID      string          `json:"id"`
 	Type    db.ArtifactType `json:"type"`
@@ -129,8 +164,9 @@ type ActionPayload struct {
 }
 
 type CreateOrEditChatRequest struct {
-	WorkspaceID string `json:"workspaceId"`
-	Title       string `json:"title"`
+	WorkspaceID string            `json:"workspaceId"`
+	Title       string            `json:"title"`
+	Visibility  db.ChatVisibility `json:"visibility,omitempty"`
 }
 
 type PaginationResponse struct {
@@ -189,10 +225,19 @@ type ChatStatusWebhookResponse struct {
 }
 
 func NewChatHandler(httpClient *http.Client, database db.Database) *ChatHandler {
-	return &ChatHandler{
-		httpClient: httpClient,
-		db:         database,
+	ch := &ChatHandler{
+		httpClient:         httpClient,
+		webhookClient:      newActionWebhookClient(),
+		db:                 database,
+		streams:            sse.Streams,
+		streamPollInterval: 2 * time.Second,
+		streamHeartbeat:    15 * time.Second,
+		files:              storage.Files,
+		knowledge:          knowledge.Default,
+		cfg:                config.Current(),
 	}
+	ch.indexFile = ch.indexFileAsync
+	return ch
 }
 
 // CreateChat creates a new chat
@@ -229,11 +274,27 @@ func (ch *ChatHandler) CreateChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	visibility := request.Visibility
+	if visibility == "" {
+		visibility = db.WorkspaceChat
+	}
+	if !validChatVisibility(visibility) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "visibility must be private, workspace or shared",
+		})
+		return
+	}
+
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
 	chat := &db.Chat{
 		ID:          xid.New().String(),
 		WorkspaceID: request.WorkspaceID,
 		Title:       request.Title,
 		Status:      "active",
+		OwnerPubKey: pubKeyFromAuth,
+		Visibility:  visibility,
 	}
 
 	createdChat, err := ch.db.AddChat(chat)
@@ -393,19 +454,22 @@ func buildArtifactList(artefacts []db.Artifact) []map[string]string {
 	return formattedArtefacts
 }
 
-func buildVarsPayload(request SendMessageRequest, createdMessage *db.ChatMessage, messageHistory []map[string]string, context interface{}, user *db.Person, codeGraph *db.WorkspaceCodeGraph, codeSpace db.CodeSpaceMap, mode string) map[string]interface{} {
+func (ch *ChatHandler) buildVarsPayload(request SendMessageRequest, createdMessage *db.ChatMessage, messageHistory []map[string]string, context interface{}, user *db.Person, codeGraph *db.WorkspaceCodeGraph, codeSpace db.CodeSpaceMap, mode string) map[string]interface{} {
 	vars := map[string]interface{}{
-		"chatId":            request.ChatID,
-		"messageId":         createdMessage.ID,
-		"message":           request.Message,
-		"history":           messageHistory,
-		"contextTags":       context,
-		"sourceWebsocketId": request.SourceWebsocketID,
-		"webhook_url":       fmt.Sprintf("%s/hivechat/response", os.Getenv("HOST")),
-		"alias":             user.OwnerAlias,
-		"pdf_url":           request.PDFURL,
-		"modelSelection":    request.ModelSelection,
-		"workspaceId":       request.WorkspaceUUID,
+		"chatId":             request.ChatID,
+		"messageId":          createdMessage.ID,
+		"message":            request.Message,
+		"history":            messageHistory,
+		"contextTags":        context,
+		"sourceWebsocketId":  request.SourceWebsocketID,
+		"webhook_url":        fmt.Sprintf("%s/hivechat/response", ch.cfg.Server.Host),
+		"stream_webhook_url": fmt.Sprintf("%s/hivechat/response/stream", ch.cfg.Server.Host),
+		"alias":              user.OwnerAlias,
+		"pdf_url":            request.PDFURL,
+		"modelSelection":     request.ModelSelection,
+		"workspaceId":        request.WorkspaceUUID,
+		"branchId":           createdMessage.BranchID,
+		"parentMessageId":    createdMessage.ID,
 	}
 
 	if codeGraph != nil && codeGraph.Url != "" {
@@ -483,6 +547,24 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	chat, err := ch.db.GetChatByChatID(request.ChatID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat not found",
+		})
+		return
+	}
+	if chat.WorkspaceID != request.WorkspaceUUID {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "workspaceUUID does not match the workspace of the chat",
+		})
+		return
+	}
+
 	context, err := ch.db.GetProductBrief(request.WorkspaceUUID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -493,7 +575,7 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	history, err := ch.db.GetChatMessagesForChatID(request.ChatID)
+	history, err := ch.db.GetChatBranchHistory(request.ChatID, "")
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ChatResponse{
@@ -503,6 +585,39 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	messageHistory := ch.buildMessageHistory(history)
+
+	message := &db.ChatMessage{
+		ID:          xid.New().String(),
+		ChatID:      request.ChatID,
+		Message:     request.Message,
+		PDFURL:      request.PDFURL,
+		Role:        "user",
+		Timestamp:   time.Now(),
+		ContextTags: contextTagsFromRequest(request),
+		Status:      "sending",
+		Source:      "user",
+	}
+
+	createdMessage, err := ch.db.AddChatMessage(message)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to save message: %v", err),
+		})
+		return
+	}
+
+	if !ch.dispatchChatMessage(w, request, user, pubKeyFromAuth, createdMessage, messageHistory, context) {
+		createdMessage.Status = "error"
+		ch.db.UpdateChatMessage(&createdMessage)
+	}
+}
+
+// buildMessageHistory formats the last 20 messages of a conversation, with
+// their artifacts, as the history sent to Stakwork.
+func (ch *ChatHandler) buildMessageHistory(history []db.ChatMessage) []map[string]string {
 	start := 0
 	if len(history) > 20 {
 		start = len(history) - 20
@@ -527,27 +642,13 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	message := &db.ChatMessage{
-		ID:        xid.New().String(),
-		ChatID:    request.ChatID,
-		Message:   request.Message,
-		PDFURL:    request.PDFURL,
-		Role:      "user",
-		Timestamp: time.Now(),
-		Status:    "sending",
-		Source:    "user",
-	}
-
-	createdMessage, err := ch.db.AddChatMessage(message)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ChatResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to save message: %v", err),
-		})
-		return
-	}
+	return messageHistory
+}
 
+// dispatchChatMessage sends a saved user message to Stakwork and writes the
+// response of the request that sent it. It returns false when Stakwork
+// failed to take the message, so the caller can mark it as failed.
+func (ch *ChatHandler) dispatchChatMessage(w http.ResponseWriter, request SendMessageRequest, user db.Person, pubKeyFromAuth string, createdMessage db.ChatMessage, messageHistory []map[string]string, context interface{}) bool {
 	var codeGraph *db.WorkspaceCodeGraph
 	if workspaceID := request.WorkspaceUUID; workspaceID != "" {
 		codeGraphResult, err := ch.db.GetCodeGraphByWorkspaceUuid(workspaceID)
@@ -569,7 +670,16 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	vars := buildVarsPayload(request, &createdMessage, messageHistory, context, &user, codeGraph, codeSpace, mode)
+	vars := ch.buildVarsPayload(request, &createdMessage, messageHistory, context, &user, codeGraph, codeSpace, mode)
+	if request.PDFURL != "" {
+		vars["pdf_url"] = ch.workflowFileURL(request.WorkspaceUUID, request.PDFURL)
+	}
+	if files := ch.fileContext(request.WorkspaceUUID, createdMessage.ContextTags); len(files) > 0 {
+		vars["fileContext"] = files
+	}
+	if items := knowledgeContext(ch.knowledge, request.WorkspaceUUID, request.Message, createdMessage.ContextTags); len(items) > 0 {
+		vars["knowledgeContext"] = items
+	}
 
 	stakworkPayload := StakworkChatPayload{
 		Name:       "Hive Chat Processor",
@@ -601,20 +711,40 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 			Success: false,
 			Message: "environment variable is not set",
 		})
-		return
+		return true
 	}
 
-	projectID, err := ch.sendToStakwork(stakworkPayload, apiKey)
+	// the reply exists before the workflow starts so every chunk and the
+	// response find it, on the branch and after the message it answers
+	reply, err := ch.db.AddChatMessage(&db.ChatMessage{
+		ID:       xid.New().String(),
+		ChatID:   createdMessage.ChatID,
+		Role:     db.AssistantRole,
+		Status:   db.StreamingStatus,
+		Source:   db.AgentSource,
+		ParentID: createdMessage.ID,
+		BranchID: createdMessage.BranchID,
+	})
 	if err != nil {
-		createdMessage.Status = "error"
-		ch.db.UpdateChatMessage(&createdMessage)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to save reply message: %v", err),
+		})
+		return false
+	}
+	vars["replyMessageId"] = reply.ID
 
+	projectID, err := ch.sendToStakwork(stakworkPayload, apiKey)
+	if err != nil {
+		reply.Status = db.ErrorStatus
+		ch.db.UpdateChatMessage(&reply)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ChatResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to process message: %v", err),
 		})
-		return
+		return false
 	}
 
 	projectMsg := websocket.TicketMessage{
@@ -646,6 +776,7 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		Message: "Message sent successfully",
 		Data:    createdMessage,
 	})
+	return true
 }
 
 func (ch *ChatHandler) sendToStakwork(payload StakworkChatPayload, apiKey string) (int64, error) {
@@ -722,6 +853,15 @@ func (ch *ChatHandler) GetChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	visible := chats[:0]
+	for _, chat := range chats {
+		if chat.Visibility != db.PrivateChat || chat.OwnerPubKey == pubKeyFromAuth || isTrustedCaller(pubKeyFromAuth) {
+			visible = append(visible, chat)
+		}
+	}
+	chats = visible
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(ChatResponse{
 		Success: true,
@@ -732,14 +872,16 @@ func (ch *ChatHandler) GetChat(w http.ResponseWriter, r *http.Request) {
 // GetChatHistory retrieves the history of a chat
 //
 //	@Summary		Retrieve chat history
-//	@Description	Retrieve the history of a chat with the given ID
+//	@Description	Retrieve the messages of the active branch of a chat, or of the given branch
 //	@Tags			Hive Chat
 //	@Produce		json
 //	@Security		PubKeyContextAuth
-//	@Param			uuid	path		string	true	"Chat ID"
-//	@Success		200		{object}	HistoryChatResponse
-//	@Failure		400		{object}	ChatResponse
-//	@Failure		500		{object}	ChatResponse
+//	@Param			uuid		path		string	true	"Chat ID"
+//	@Param			branch_id	query		string	false	"Branch ID"
+//	@Success		200			{object}	HistoryChatResponse
+//	@Failure		400			{object}	ChatResponse
+//	@Failure		404			{object}	ChatResponse
+//	@Failure		500			{object}	ChatResponse
 //	@Router			/hivechat/history/{uuid} [get]
 func (ch *ChatHandler) GetChatHistory(w http.ResponseWriter, r *http.Request) {
 	chatID := chi.URLParam(r, "uuid")
@@ -752,9 +894,13 @@ func (ch *ChatHandler) GetChatHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	messages, err := ch.db.GetChatMessagesForChatID(chatID)
+	messages, err := ch.db.GetChatBranchHistory(chatID, r.URL.Query().Get("branch_id"))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if err.Error() == "branch not found" {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(ChatResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to fetch chat history: %v", err),
@@ -802,6 +948,11 @@ func (ch *ChatHandler) ProcessChatResponse(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if request.Value.ReplyMessageID != "" {
+		ch.completeChatReply(w, request.Value.ChatID, request.Value.ReplyMessageID, request.Value.Response, request.Value.SourceWebsocketID, request.Value.Artifacts)
+		return
+	}
+
 	existingMessages, err := ch.db.GetChatMessagesForChatID(request.Value.ChatID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -812,6 +963,35 @@ func (ch *ChatHandler) ProcessChatResponse(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// a response that names no message completes the newest reply still
+	// streaming, so the reply created when the prompt was sent never stays
+	// open beside it
+	if request.Value.MessageID == "" {
+		for i := len(existingMessages) - 1; i >= 0; i-- {
+			msg := existingMessages[i]
+			if msg.Role == db.AssistantRole && msg.Status == db.StreamingStatus {
+				ch.completeChatReply(w, request.Value.ChatID, msg.ID, request.Value.Response, request.Value.SourceWebsocketID, request.Value.Artifacts)
+				return
+			}
+		}
+	}
+
+	// a response that only names the prompt completes the reply created when
+	// it was sent, or lands after the prompt, not on the active branch
+	var prompt *db.ChatMessage
+	for i, msg := range existingMessages {
+		if request.Value.MessageID == "" {
+			break
+		}
+		if msg.ID == request.Value.MessageID {
+			prompt = &existingMessages[i]
+		}
+		if msg.ParentID == request.Value.MessageID && msg.Role == db.AssistantRole && msg.Status == db.StreamingStatus {
+			ch.completeChatReply(w, request.Value.ChatID, msg.ID, request.Value.Response, request.Value.SourceWebsocketID, request.Value.Artifacts)
+			return
+		}
+	}
+
 	for _, msg := range existingMessages {
 		if msg.Role == "assistant" &&
 			msg.Message == request.Value.Response &&
@@ -834,6 +1014,10 @@ func (ch *ChatHandler) ProcessChatResponse(w http.ResponseWriter, r *http.Reques
 		Status:    "sent",
 		Source:    "agent",
 	}
+	if prompt != nil {
+		message.ParentID = prompt.ID
+		message.BranchID = prompt.BranchID
+	}
 
 	createdMessage, err := ch.db.AddChatMessage(message)
 	if err != nil {
@@ -845,35 +1029,7 @@ func (ch *ChatHandler) ProcessChatResponse(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	var artifacts []db.Artifact
-	if len(request.Value.Artifacts) > 0 {
-		for _, artifact := range request.Value.Artifacts {
-			content := db.PropertyMap{}
-			if contentMap, ok := artifact.Content.(map[string]interface{}); ok {
-				content = db.PropertyMap(contentMap)
-			}
-
-			newArtifact := &db.Artifact{
-				ID:        uuid.New(),
-				MessageID: createdMessage.ID,
-				Type:      artifact.Type,
-				Content:   content,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			}
-
-			processedArtifact, err := ch.db.CreateArtifact(newArtifact)
-			if err != nil {
-				log.Printf("Error processing artifact: %v", err)
-				continue
-			}
-			artifacts = append(artifacts, *processedArtifact)
-
-			if artifact.Type == db.SSEArtifact {
-				go HandleSSEConnectionArtifact(ch.db, artifact, request.Value.ChatID)
-			}
-		}
-	}
+	artifacts := ch.saveResponseArtifacts(createdMessage.ID, request.Value.ChatID, request.Value.Artifacts)
 
 	wsMessage := websocket.TicketMessage{
 		BroadcastType:   "direct",
@@ -897,6 +1053,43 @@ func (ch *ChatHandler) ProcessChatResponse(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+// saveResponseArtifacts stores the artifacts of an assistant reply, starting
+// SSE clients for SSE artifacts. Artifacts that fail to save are skipped.
+func (ch *ChatHandler) saveResponseArtifacts(messageID, chatID string, responseArtifacts []ChatMessageArtifact) []db.Artifact {
+	var artifacts []db.Artifact
+	for _, artifact := range responseArtifacts {
+		content := db.PropertyMap{}
+		if contentMap, ok := artifact.Content.(map[string]interface{}); ok {
+			content = db.PropertyMap(contentMap)
+		}
+
+		newArtifact := &db.Artifact{
+			ID:        uuid.New(),
+			MessageID: messageID,
+			Type:      artifact.Type,
+			Content:   content,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := validateActionArtifact(*newArtifact); err != nil {
+			log.Printf("Skipping invalid action artifact: %v", err)
+			continue
+		}
+
+		processedArtifact, err := ch.db.CreateArtifact(newArtifact)
+		if err != nil {
+			log.Printf("Error processing artifact: %v", err)
+			continue
+		}
+		artifacts = append(artifacts, *processedArtifact)
+
+		if artifact.Type == db.SSEArtifact {
+			go HandleSSEConnectionArtifact(ch.db, artifact, chatID)
+		}
+	}
+	return artifacts
+}
+
 // UploadFile uploads a file to a chat
 //
 //	@Summary		Upload a file to a chat
@@ -905,12 +1098,24 @@ func (ch *ChatHandler) ProcessChatResponse(w http.ResponseWriter, r *http.Reques
 //	@Accept			multipart/form-data
 //	@Produce		json
 //	@Security		PubKeyContextAuth
-//	@Param			file	formData	file	true	"File to upload"
+//	@Param			file		formData	file	true	"File to upload"
+//	@Param			workspaceId	query		string	true	"Workspace of the file"
 //	@Success		200		{object}	FileResponse
 //	@Failure		400		{object}	ChatResponse
+//	@Failure		413		{object}	ChatResponse
 //	@Failure		500		{object}	ChatResponse
 //	@Router			/hivechat/upload [post]
 func (ch *ChatHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.URL.Query().Get("workspaceId")
+	if workspaceID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "workspaceId is required",
+		})
+		return
+	}
+
 	file, header, err := r.FormFile("file")
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -964,25 +1169,31 @@ func (ch *ChatHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file.Seek(0, 0)
-
-	uploadFilename := uuid.New().String() + filepath.Ext(header.Filename)
-
-	challenge := GetMemeChallenge()
-	signer := SignChallenge(challenge.Challenge)
-	mErr, mToken := GetMemeToken(challenge.Id, signer.Response.Sig)
+	if status, message := ch.checkStorageQuota(workspaceID, header.Size); status != http.StatusOK {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: message,
+		})
+		return
+	}
 
-	if mErr != "" {
+	if ch.files == nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ChatResponse{
 			Success: false,
-			Message: "Failed to get meme token",
+			Message: "File storage is not configured",
 		})
 		return
 	}
 
-	err, uploadURL := UploadMemeImage(file, mToken.Token, header.Filename)
-	if err != nil {
+	file.Seek(0, 0)
+
+	uploadFilename := uuid.New().String() + filepath.Ext(header.Filename)
+	storageKey := chatFileKey(uploadFilename)
+
+	if err := ch.files.Put(r.Context(), storageKey, file, header.Size, mimeType); err != nil {
+		logger.Log.Error("failed to store upload %s: %v", uploadFilename, err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ChatResponse{
 			Success: false,
@@ -990,6 +1201,7 @@ func (ch *ChatHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	uploadURL := ch.fileBlobURL(uploadFilename)
 
 	asset := &db.FileAsset{
 		OriginFilename: header.Filename,
@@ -1000,11 +1212,16 @@ func (ch *ChatHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 		Status:         db.ActiveFileStatus,
 		UploadedBy:     r.Context().Value("pubkey").(string),
 		StoragePath:    uploadURL,
-		WorkspaceID:    r.URL.Query().Get("workspaceId"),
+		StorageKey:     storageKey,
+		WorkspaceID:    workspaceID,
+	}
+	if extract.Supported(mimeType) {
+		asset.ExtractionStatus = db.PendingExtraction
 	}
 
 	asset, err = ch.db.CreateFileAsset(asset)
 	if err != nil {
+		ch.files.Delete(r.Context(), storageKey)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ChatResponse{
 			Success: false,
@@ -1013,6 +1230,10 @@ func (ch *ChatHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if asset.ExtractionStatus == db.PendingExtraction {
+		ch.indexFile(*asset)
+	}
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(FileResponse{
 		Success:    true,
@@ -1066,10 +1287,21 @@ func (ch *ChatHandler) GetFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	url, err := ch.fileURL(r.Context(), asset)
+	if err != nil {
+		logger.Log.Error("failed to sign url of file %d: %v", asset.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to sign file URL",
+		})
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(FileResponse{
 		Success:    true,
-		URL:        asset.StoragePath,
+		URL:        url,
 		IsExisting: true,
 		Asset:      *asset,
 		UploadTime: asset.UploadTime,
@@ -1293,6 +1525,8 @@ func isAllowedFileType(mimeType string) bool {
 		"image/png":        true,
 		"image/gif":        true,
 		"text/plain":       true,
+		"text/markdown":    true,
+		"text/x-markdown":  true,
 		"application/json": true,
 	}
 	return allowedTypes[mimeType]
@@ -1309,6 +1543,10 @@ func (ch *ChatHandler) CreateArtefact(w http.ResponseWriter, r *http.Request) {
 		jsonErrorResponse(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
+	if err := validateActionArtifact(artifact); err != nil {
+		jsonErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	createdArtifact, err := ch.db.CreateArtifact(&artifact)
 	if err != nil {
@@ -1380,11 +1618,32 @@ func (ch *ChatHandler) GetArtefactsByMessageID(w http.ResponseWriter, r *http.Re
 }
 
 func (ch *ChatHandler) UpdateArtefact(w http.ResponseWriter, r *http.Request) {
+	artifactID, err := uuid.Parse(chi.URLParam(r, "artifactId"))
+	if err != nil {
+		jsonErrorResponse(w, "Invalid artifact ID format", http.StatusBadRequest)
+		return
+	}
+
 	var artifact db.Artifact
 	if err := json.NewDecoder(r.Body).Decode(&artifact); err != nil {
 		jsonErrorResponse(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
+	artifact.ID = artifactID
+
+	existing, err := ch.db.GetArtifactByID(artifactID)
+	if err != nil || existing == nil {
+		jsonErrorResponse(w, "Artifact not found", http.StatusNotFound)
+		return
+	}
+	// the options of an action run on the server, so only the workflow
+	// that offered them may set them
+	if existing.Type == db.ActionArtifact {
+		if changed, err := actionOptionsChanged(*existing, artifact); err != nil || changed {
+			jsonErrorResponse(w, "The options of an action cannot be edited", http.StatusBadRequest)
+			return
+		}
+	}
 
 	updatedArtifact, err := ch.db.UpdateArtifact(&artifact)
 	if err != nil {
@@ -1454,7 +1713,7 @@ func (ch *ChatHandler) SendActionMessage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	history, err := ch.db.GetChatMessagesForChatID(request.ChatID)
+	history, err := ch.db.GetChatBranchHistory(request.ChatID, "")
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ChatResponse{
@@ -1949,61 +2208,44 @@ func SendEventPayloadToWebhook(database db.Database, chatID string, webhookURL s
 		time.Sleep(time.Duration(delayMs) * time.Millisecond)
 	}
 
-	unsentEvents, err := database.GetNewSSEMessageLogsByChatID(chatID)
+	sent, err := sse.Webhooks.Deliver(context.Background(), database, chatID, webhookURL)
 	if err != nil {
-		log.Printf("Error retrieving unsent events for chatID %s: %v", chatID, err)
+		log.Printf("Error sending events for chatID %s to webhook %s after %d events: %v", chatID, webhookURL, sent, err)
 		return
 	}
 
-	sseURL := ""
-	if len(unsentEvents) > 0 {
-		sseURL = unsentEvents[0].From
-	}
-
-	eventsList := make([]map[string]interface{}, len(unsentEvents))
-	for i, event := range unsentEvents {
-		eventsList[i] = map[string]interface{}{
-			"event": event.Event,
-		}
-	}
-
-	payload := map[string]interface{}{
-		"chatID":  chatID,
-		"events":  eventsList,
-		"sse_url": sseURL,
-	}
-
-	payloadJSON, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("Error marshaling webhook payload: %v", err)
-		return
-	}
-
-	resp, err := http.Post(webhookURL, "application/json", bytes.NewBuffer(payloadJSON))
-	if err != nil {
-		log.Printf("Error sending events to webhook %s: %v", webhookURL, err)
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("Webhook returned non-success status %d: %s", resp.StatusCode, string(body))
-		return
-	}
-
-	var eventIDs []uuid.UUID
-	for _, event := range unsentEvents {
-		eventIDs = append(eventIDs, event.ID)
-	}
+	log.Printf("Successfully sent %d events for chatID %s to webhook %s", sent, chatID, webhookURL)
+}
 
-	err = database.UpdateSSEMessageLogStatusBatch(eventIDs)
+// GetSSEClients lists the SSE ingestion clients and their state
+//
+//	@Summary		List SSE clients
+//	@Description	List every SSE ingestion client with its connection state (connecting, connected, retrying, failed or stopped), retry count, last error and last event id
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id	query		string	false	"Only clients of this chat"
+//	@Success		200		{object}	ChatResponse
+//	@Failure		500		{object}	ChatResponse
+//	@Router			/hivechat/sse/clients [get]
+func (ch *ChatHandler) GetSSEClients(w http.ResponseWriter, r *http.Request) {
+	states, err := ch.db.GetSSEClientStates(r.URL.Query().Get("chat_id"))
 	if err != nil {
-		log.Printf("Error updating event status: %v", err)
+		logger.Log.Error("Failed to get SSE client states: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to retrieve SSE clients",
+		})
 		return
 	}
 
-	log.Printf("Successfully sent %d events for chatID %s to webhook %s", len(unsentEvents), chatID, webhookURL)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Message: fmt.Sprintf("Retrieved %d SSE clients", len(states)),
+		Data:    states,
+	})
 }
 
 func (ch *ChatHandler) GetAllSSEMessagesByChatID(w http.ResponseWriter, r *http.Request) {
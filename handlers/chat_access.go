@@ -0,0 +1,747 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/rs/xid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+const (
+	defaultShareLinkTTL = 7 * 24 * time.Hour
+	maxShareLinkTTL     = 90 * 24 * time.Hour
+	maxAccessBodySize   = 20 << 20
+)
+
+// AccessTarget is the chat and workspace a request works on. Either may be
+// empty.
+type AccessTarget struct {
+	ChatID      string
+	WorkspaceID string
+}
+
+// TargetResolver finds the chat and workspace a request refers to. An error
+// means the record the request names does not exist.
+type TargetResolver func(ch *ChatHandler, r *http.Request) (AccessTarget, error)
+
+// ChatParam takes the chat from a URL parameter.
+func ChatParam(name string) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		return AccessTarget{ChatID: chi.URLParam(r, name)}, nil
+	}
+}
+
+// WorkspaceParam takes the workspace from a URL parameter.
+func WorkspaceParam(name string) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		return AccessTarget{WorkspaceID: chi.URLParam(r, name)}, nil
+	}
+}
+
+// WorkspaceQuery takes the workspace from a query parameter.
+func WorkspaceQuery(name string) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		return AccessTarget{WorkspaceID: r.URL.Query().Get(name)}, nil
+	}
+}
+
+// MessageParam takes the chat of the message named by a URL parameter.
+func MessageParam(name string) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		return ch.messageTarget(chi.URLParam(r, name))
+	}
+}
+
+// ArtifactParam takes the chat of the artifact named by a URL parameter.
+func ArtifactParam(name string) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		id, err := uuid.Parse(chi.URLParam(r, name))
+		if err != nil {
+			return AccessTarget{}, nil
+		}
+		artifact, err := ch.db.GetArtifactByID(id)
+		if err != nil {
+			return AccessTarget{}, errors.New("artifact not found")
+		}
+		return ch.messageTarget(artifact.MessageID)
+	}
+}
+
+// StatusParam takes the chat of the workflow status named by a URL
+// parameter.
+func StatusParam(name string) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		id, err := uuid.Parse(chi.URLParam(r, name))
+		if err != nil {
+			return AccessTarget{}, nil
+		}
+		status, err := ch.db.GetChatStatusByUUID(id)
+		if err != nil {
+			return AccessTarget{}, errors.New("chat status not found")
+		}
+		return AccessTarget{ChatID: status.ChatID}, nil
+	}
+}
+
+// FileParam takes the workspace of the file named by a URL parameter.
+func FileParam(name string) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		id, err := strconv.ParseUint(chi.URLParam(r, name), 10, 32)
+		if err != nil {
+			return AccessTarget{}, nil
+		}
+		asset, err := ch.db.GetFileAssetByID(uint(id))
+		if err != nil {
+			return AccessTarget{}, errors.New("file not found")
+		}
+		return AccessTarget{WorkspaceID: asset.WorkspaceID}, nil
+	}
+}
+
+// FileNameParam takes the workspace of the file whose upload filename is a
+// URL parameter.
+func FileNameParam(name string) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		asset, err := ch.db.GetFileAssetByUploadFilename(chi.URLParam(r, name))
+		if err != nil {
+			return AccessTarget{}, errors.New("file not found")
+		}
+		return AccessTarget{WorkspaceID: asset.WorkspaceID}, nil
+	}
+}
+
+// ChatQuery takes the chat from a query parameter.
+func ChatQuery(name string) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		return AccessTarget{ChatID: r.URL.Query().Get(name)}, nil
+	}
+}
+
+// ChatBody takes the chat from a field of the JSON body.
+func ChatBody(field string) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		chatID, err := bodyField(r, field)
+		return AccessTarget{ChatID: chatID}, err
+	}
+}
+
+// WorkspaceBody takes the workspace from a field of the JSON body.
+func WorkspaceBody(field string) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		workspaceID, err := bodyField(r, field)
+		return AccessTarget{WorkspaceID: workspaceID}, err
+	}
+}
+
+// MessageBody takes the chat of the message named by a field of the JSON
+// body.
+func MessageBody(field string) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		messageID, err := bodyField(r, field)
+		if err != nil {
+			return AccessTarget{}, err
+		}
+		return ch.messageTarget(messageID)
+	}
+}
+
+// All combines the targets of several resolvers. Requests whose resolvers
+// name different chats or workspaces are rejected.
+func All(resolvers ...TargetResolver) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		var target AccessTarget
+		for _, resolve := range resolvers {
+			next, err := resolve(ch, r)
+			if err != nil {
+				return AccessTarget{}, err
+			}
+			if target.ChatID, err = mergeTarget(target.ChatID, next.ChatID); err != nil {
+				return AccessTarget{}, err
+			}
+			if target.WorkspaceID, err = mergeTarget(target.WorkspaceID, next.WorkspaceID); err != nil {
+				return AccessTarget{}, err
+			}
+		}
+		return target, nil
+	}
+}
+
+func mergeTarget(current, next string) (string, error) {
+	if current != "" && next != "" && current != next {
+		return "", errTargetConflict
+	}
+	if current == "" {
+		return next, nil
+	}
+	return current, nil
+}
+
+// bodyField reads a string field of the JSON body, matching its name
+// without regard to case as encoding/json does, so the check sees the value
+// the handler decodes. A body that gives the field twice with different
+// values is rejected. The body is left for the handler to read.
+func bodyField(r *http.Request, field string) (string, error) {
+	if r.Body == nil {
+		return "", nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAccessBodySize))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil {
+		return "", nil
+	}
+
+	var values map[string]json.RawMessage
+	if json.Unmarshal(body, &values) != nil {
+		return "", nil
+	}
+	found := ""
+	for key, raw := range values {
+		var value string
+		if !strings.EqualFold(key, field) || json.Unmarshal(raw, &value) != nil || value == "" {
+			continue
+		}
+		if found != "" && found != value {
+			return "", errTargetConflict
+		}
+		found = value
+	}
+	return found, nil
+}
+
+// errTargetConflict is returned for requests that name two different chats
+// or workspaces.
+var errTargetConflict = errors.New("the request names more than one chat or workspace")
+
+// errTargetRequired is returned by Required for requests that name no chat
+// or workspace.
+var errTargetRequired = errors.New("a chat or workspace ID is required")
+
+// Required rejects requests that name no chat or workspace, so listings
+// cannot span every workspace. Trusted callers may still omit them.
+func Required(resolve TargetResolver) TargetResolver {
+	return func(ch *ChatHandler, r *http.Request) (AccessTarget, error) {
+		target, err := resolve(ch, r)
+		if err != nil {
+			return target, err
+		}
+		pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+		if target.ChatID == "" && target.WorkspaceID == "" && !isTrustedCaller(pubKeyFromAuth) {
+			return target, errTargetRequired
+		}
+		return target, nil
+	}
+}
+
+func (ch *ChatHandler) messageTarget(messageID string) (AccessTarget, error) {
+	if messageID == "" {
+		return AccessTarget{}, nil
+	}
+	message, err := ch.db.GetChatMessageByID(messageID)
+	if err != nil {
+		return AccessTarget{}, errors.New("message not found")
+	}
+	return AccessTarget{ChatID: message.ChatID}, nil
+}
+
+// isTrustedCaller reports whether the caller is a super admin or a service
+// authenticated with the API token, which may use every chat.
+func isTrustedCaller(pubKey string) bool {
+	return (config.SWAuth != "" && pubKey == config.SWAuth) || auth.AdminCheck(pubKey)
+}
+
+// isWorkspaceMember reports whether the caller owns or belongs to a
+// workspace.
+func (ch *ChatHandler) isWorkspaceMember(pubKey, workspaceID string) bool {
+	if pubKey == "" {
+		return false
+	}
+	if isTrustedCaller(pubKey) {
+		return true
+	}
+	workspace := ch.db.GetWorkspaceByUuid(workspaceID)
+	if workspace.Uuid == "" {
+		return false
+	}
+	if workspace.OwnerPubKey == pubKey {
+		return true
+	}
+	return ch.db.GetWorkspaceUser(pubKey, workspaceID).OwnerPubKey == pubKey
+}
+
+// canAccessChat reports whether the caller may read and write a chat: its
+// owner always can, and members of its workspace can unless it is private.
+// Chats created before owners were recorded are open to the workspace, and
+// chats with neither an owner nor a workspace only to trusted callers.
+func (ch *ChatHandler) canAccessChat(pubKey string, chat db.Chat) bool {
+	if pubKey == "" {
+		return false
+	}
+	if chat.OwnerPubKey == pubKey || isTrustedCaller(pubKey) {
+		return true
+	}
+	if chat.Visibility == db.PrivateChat {
+		return false
+	}
+	if chat.WorkspaceID == "" {
+		return false
+	}
+	return ch.isWorkspaceMember(pubKey, chat.WorkspaceID)
+}
+
+// canManageChat reports whether the caller may change who can see a chat.
+// Chats without an owner are managed by the owner of their workspace.
+func (ch *ChatHandler) canManageChat(pubKey string, chat db.Chat) bool {
+	if pubKey == "" {
+		return false
+	}
+	if chat.OwnerPubKey == pubKey || isTrustedCaller(pubKey) {
+		return true
+	}
+	if chat.OwnerPubKey == "" && chat.WorkspaceID != "" {
+		return ch.db.GetWorkspaceByUuid(chat.WorkspaceID).OwnerPubKey == pubKey
+	}
+	return false
+}
+
+// RequireChatAccess lets a request through only when the caller can use the
+// chat and the workspace it refers to.
+func (ch *ChatHandler) RequireChatAccess(resolve TargetResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+
+			target, err := resolve(ch, r)
+			if err != nil {
+				status := http.StatusNotFound
+				if err == errTargetRequired || err == errTargetConflict {
+					status = http.StatusBadRequest
+				}
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(ChatResponse{
+					Success: false,
+					Message: err.Error(),
+				})
+				return
+			}
+
+			checkedWorkspace := ""
+			if target.ChatID != "" {
+				chat, err := ch.db.GetChatByChatID(target.ChatID)
+				if err != nil {
+					w.WriteHeader(http.StatusNotFound)
+					json.NewEncoder(w).Encode(ChatResponse{
+						Success: false,
+						Message: "Chat not found",
+					})
+					return
+				}
+				if !ch.canAccessChat(pubKeyFromAuth, chat) {
+					forbidChat(w)
+					return
+				}
+				checkedWorkspace = chat.WorkspaceID
+			}
+
+			if target.WorkspaceID != "" && target.WorkspaceID != checkedWorkspace {
+				if !ch.isWorkspaceMember(pubKeyFromAuth, target.WorkspaceID) {
+					forbidChat(w)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func forbidChat(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: false,
+		Message: "You do not have access to this chat",
+	})
+}
+
+type ChatVisibilityRequest struct {
+	Visibility db.ChatVisibility `json:"visibility"`
+}
+
+type CreateShareLinkRequest struct {
+	ExpiresInHours int `json:"expiresInHours"`
+}
+
+// ShareLinkResponse is a new share link. The token is only returned once.
+type ShareLinkResponse struct {
+	db.ChatShareLink
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// SharedChatResponse is the read only view of a chat opened with a share
+// link.
+type SharedChatResponse struct {
+	Title     string           `json:"title"`
+	CreatedAt time.Time        `json:"createdAt"`
+	ExpiresAt time.Time        `json:"expiresAt"`
+	Messages  []db.ChatMessage `json:"messages"`
+	Artifacts []db.Artifact    `json:"artifacts"`
+}
+
+func validChatVisibility(visibility db.ChatVisibility) bool {
+	switch visibility {
+	case db.PrivateChat, db.WorkspaceChat, db.SharedChat:
+		return true
+	}
+	return false
+}
+
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// managedChat loads the chat of a request and checks that the caller may
+// manage it, writing the error response when not.
+func (ch *ChatHandler) managedChat(w http.ResponseWriter, r *http.Request) (db.Chat, bool) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+
+	chat, err := ch.db.GetChatByChatID(chi.URLParam(r, "chat_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat not found",
+		})
+		return db.Chat{}, false
+	}
+	if !ch.canManageChat(pubKeyFromAuth, chat) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Only the owner of the chat can change who can see it",
+		})
+		return db.Chat{}, false
+	}
+	return chat, true
+}
+
+// SetChatVisibility changes who can see a chat
+//
+//	@Summary		Set chat visibility
+//	@Description	Make a chat private to its owner, open to its workspace, or shared with anyone holding a share link. Only the owner can change it.
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id	path		string					true	"Chat ID"
+//	@Param			request	body		ChatVisibilityRequest	true	"Visibility"
+//	@Success		200		{object}	ChatResponse
+//	@Failure		400		{object}	ChatResponse
+//	@Failure		403		{object}	ChatResponse
+//	@Failure		404		{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/visibility [put]
+func (ch *ChatHandler) SetChatVisibility(w http.ResponseWriter, r *http.Request) {
+	var request ChatVisibilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || !validChatVisibility(request.Visibility) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "visibility must be private, workspace or shared",
+		})
+		return
+	}
+
+	chat, ok := ch.managedChat(w, r)
+	if !ok {
+		return
+	}
+
+	updatedChat, err := ch.db.UpdateChat(&db.Chat{ID: chat.ID, Visibility: request.Visibility})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to update chat: %v", err),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Message: "Chat visibility updated",
+		Data:    updatedChat,
+	})
+}
+
+// CreateChatShareLink creates a read only share link to a chat
+//
+//	@Summary		Share a chat
+//	@Description	Create a read only link to a chat that works without signing in until it expires, by default after 7 days and at most after 90. The chat becomes shared.
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id	path		string					true	"Chat ID"
+//	@Param			request	body		CreateShareLinkRequest	false	"Expiry"
+//	@Success		200		{object}	ShareLinkResponse
+//	@Failure		400		{object}	ChatResponse
+//	@Failure		403		{object}	ChatResponse
+//	@Failure		404		{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/share [post]
+func (ch *ChatHandler) CreateChatShareLink(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+
+	var request CreateShareLinkRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ChatResponse{
+				Success: false,
+				Message: "Invalid request body",
+			})
+			return
+		}
+	}
+	ttl := time.Duration(request.ExpiresInHours) * time.Hour
+	if request.ExpiresInHours == 0 {
+		ttl = defaultShareLinkTTL
+	}
+	if ttl <= 0 || ttl > maxShareLinkTTL {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("expiresInHours must be between 1 and %d", int(maxShareLinkTTL.Hours())),
+		})
+		return
+	}
+
+	chat, ok := ch.managedChat(w, r)
+	if !ok {
+		return
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		logger.Log.Error("[chat share] failed to generate token: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to create share link",
+		})
+		return
+	}
+
+	link, err := ch.db.CreateChatShareLink(&db.ChatShareLink{
+		ID:        xid.New().String(),
+		ChatID:    chat.ID,
+		TokenHash: hashShareToken(token),
+		CreatedBy: pubKeyFromAuth,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		logger.Log.Error("[chat share] failed to create share link for chat %s: %v", chat.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to create share link",
+		})
+		return
+	}
+
+	if chat.Visibility != db.SharedChat {
+		if _, err := ch.db.UpdateChat(&db.Chat{ID: chat.ID, Visibility: db.SharedChat}); err != nil {
+			logger.Log.Error("[chat share] failed to share chat %s: %v", chat.ID, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ChatResponse{
+				Success: false,
+				Message: "Failed to share chat",
+			})
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Message: "Share link created",
+		Data: ShareLinkResponse{
+			ChatShareLink: link,
+			Token:         token,
+			URL:           fmt.Sprintf("%s/hivechat/shared/%s", ch.cfg.Server.Host, token),
+		},
+	})
+}
+
+// GetChatShareLinks lists the share links of a chat
+//
+//	@Summary		List chat share links
+//	@Description	List the share links of a chat, including expired and revoked ones
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id	path		string	true	"Chat ID"
+//	@Success		200		{object}	ChatResponse
+//	@Failure		403		{object}	ChatResponse
+//	@Failure		404		{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/share [get]
+func (ch *ChatHandler) GetChatShareLinks(w http.ResponseWriter, r *http.Request) {
+	chat, ok := ch.managedChat(w, r)
+	if !ok {
+		return
+	}
+
+	links, err := ch.db.GetChatShareLinks(chat.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to fetch share links",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Data:    links,
+	})
+}
+
+// RevokeChatShareLink revokes a share link of a chat
+//
+//	@Summary		Revoke a chat share link
+//	@Description	Stop a share link from opening the chat
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id	path		string	true	"Chat ID"
+//	@Param			link_id	path		string	true	"Share link ID"
+//	@Success		200		{object}	ChatResponse
+//	@Failure		403		{object}	ChatResponse
+//	@Failure		404		{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/share/{link_id} [delete]
+func (ch *ChatHandler) RevokeChatShareLink(w http.ResponseWriter, r *http.Request) {
+	chat, ok := ch.managedChat(w, r)
+	if !ok {
+		return
+	}
+
+	if err := ch.db.RevokeChatShareLink(chat.ID, chi.URLParam(r, "link_id")); err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "share link not found" {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to revoke share link: %v", err),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Message: "Share link revoked",
+	})
+}
+
+// GetSharedChat opens a chat with a share link
+//
+//	@Summary		Open a shared chat
+//	@Description	Read the messages and artifacts of the active branch of a shared chat with an unexpired share link
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Param			token	path		string	true	"Share token"
+//	@Success		200		{object}	SharedChatResponse
+//	@Failure		404		{object}	ChatResponse
+//	@Router			/hivechat/shared/{token} [get]
+func (ch *ChatHandler) GetSharedChat(w http.ResponseWriter, r *http.Request) {
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Shared chat not found or link expired",
+		})
+	}
+
+	link, err := ch.db.GetChatShareLinkByTokenHash(hashShareToken(chi.URLParam(r, "token")))
+	if err != nil || link.RevokedAt != nil || !time.Now().Before(link.ExpiresAt) {
+		notFound()
+		return
+	}
+	chat, err := ch.db.GetChatByChatID(link.ChatID)
+	if err != nil || chat.Visibility != db.SharedChat {
+		notFound()
+		return
+	}
+
+	messages, err := ch.db.GetChatBranchHistory(chat.ID, "")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to fetch chat history",
+		})
+		return
+	}
+	artifacts, err := ch.db.GetAllArtifactsByChatID(chat.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to fetch chat artifacts",
+		})
+		return
+	}
+
+	inHistory := make(map[string]bool, len(messages))
+	for _, message := range messages {
+		inHistory[message.ID] = true
+	}
+	shown := []db.Artifact{}
+	for _, artifact := range artifacts {
+		if inHistory[artifact.MessageID] {
+			shown = append(shown, artifact)
+		}
+	}
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SharedChatResponse{
+		Title:     chat.Title,
+		CreatedAt: chat.CreatedAt,
+		ExpiresAt: link.ExpiresAt,
+		Messages:  messages,
+		Artifacts: shown,
+	})
+}
@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccessRequest(method, target, body, pubKey string, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for key, value := range params {
+		rctx.URLParams.Add(key, value)
+	}
+	ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+	if pubKey != "" {
+		ctx = context.WithValue(ctx, auth.ContextKey, pubKey)
+	}
+	return httptest.NewRequest(method, target, strings.NewReader(body)).WithContext(ctx)
+}
+
+func TestRequireChatAccess(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	serve := func(ch *ChatHandler, resolve TargetResolver, r *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		ch.RequireChatAccess(resolve)(next).ServeHTTP(rr, r)
+		return rr
+	}
+	newHandler := func(t *testing.T) (*ChatHandler, *datamocks.Database) {
+		mockDb := datamocks.NewDatabase(t)
+		return NewChatHandler(&http.Client{}, mockDb), mockDb
+	}
+	workspace := db.Workspace{Uuid: "ws-1", OwnerPubKey: "ws-owner"}
+
+	t.Run("Workspace Member Can Use Chat", func(t *testing.T) {
+		ch, mockDb := newHandler(t)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1", WorkspaceID: "ws-1", OwnerPubKey: "alice", Visibility: db.WorkspaceChat}, nil)
+		mockDb.On("GetWorkspaceByUuid", "ws-1").Return(workspace)
+		mockDb.On("GetWorkspaceUser", "bob", "ws-1").Return(db.WorkspaceUsers{OwnerPubKey: "bob"})
+
+		rr := serve(ch, ChatParam("chat_id"), newAccessRequest(http.MethodGet, "/", "", "bob", map[string]string{"chat_id": "chat-1"}))
+
+		assert.Equal(t, http.StatusTeapot, rr.Code)
+	})
+
+	t.Run("Non Member Is Forbidden", func(t *testing.T) {
+		ch, mockDb := newHandler(t)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1", WorkspaceID: "ws-1", OwnerPubKey: "alice", Visibility: db.WorkspaceChat}, nil)
+		mockDb.On("GetWorkspaceByUuid", "ws-1").Return(workspace)
+		mockDb.On("GetWorkspaceUser", "mallory", "ws-1").Return(db.WorkspaceUsers{})
+
+		rr := serve(ch, ChatParam("chat_id"), newAccessRequest(http.MethodGet, "/", "", "mallory", map[string]string{"chat_id": "chat-1"}))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Private Chat Is Only Open To Its Owner", func(t *testing.T) {
+		ch, mockDb := newHandler(t)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1", WorkspaceID: "ws-1", OwnerPubKey: "alice", Visibility: db.PrivateChat}, nil)
+
+		rr := serve(ch, ChatParam("chat_id"), newAccessRequest(http.MethodGet, "/", "", "ws-owner", map[string]string{"chat_id": "chat-1"}))
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		rr = serve(ch, ChatParam("chat_id"), newAccessRequest(http.MethodGet, "/", "", "alice", map[string]string{"chat_id": "chat-1"}))
+		assert.Equal(t, http.StatusTeapot, rr.Code)
+	})
+
+	t.Run("Trusted Caller Can Use Any Chat", func(t *testing.T) {
+		originalAuth := config.SWAuth
+		config.SWAuth = "service-token"
+		defer func() { config.SWAuth = originalAuth }()
+
+		ch, mockDb := newHandler(t)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1", WorkspaceID: "ws-1", OwnerPubKey: "alice", Visibility: db.PrivateChat}, nil)
+
+		rr := serve(ch, ChatParam("chat_id"), newAccessRequest(http.MethodGet, "/", "", "service-token", map[string]string{"chat_id": "chat-1"}))
+
+		assert.Equal(t, http.StatusTeapot, rr.Code)
+	})
+
+	t.Run("Unknown Chat", func(t *testing.T) {
+		ch, mockDb := newHandler(t)
+		mockDb.On("GetChatByChatID", "missing").Return(db.Chat{}, errors.New("chat not found"))
+
+		rr := serve(ch, ChatParam("chat_id"), newAccessRequest(http.MethodGet, "/", "", "alice", map[string]string{"chat_id": "missing"}))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Request Body Names Another Workspace", func(t *testing.T) {
+		ch, mockDb := newHandler(t)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1", WorkspaceID: "ws-1", OwnerPubKey: "alice"}, nil)
+		mockDb.On("GetWorkspaceByUuid", "ws-2").Return(db.Workspace{Uuid: "ws-2", OwnerPubKey: "someone"})
+		mockDb.On("GetWorkspaceUser", "alice", "ws-2").Return(db.WorkspaceUsers{})
+
+		body := `{"chat_id": "chat-1", "workspaceUUID": "ws-2", "message": "hi"}`
+		var seen string
+		handler := ch.RequireChatAccess(All(ChatBody("chat_id"), WorkspaceBody("workspaceUUID")))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload map[string]string
+			json.NewDecoder(r.Body).Decode(&payload)
+			seen = payload["message"]
+		}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, newAccessRequest(http.MethodPost, "/send", body, "alice", nil))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Empty(t, seen)
+	})
+
+	t.Run("Body Field Matches Without Case Like The Handler", func(t *testing.T) {
+		ch, mockDb := newHandler(t)
+		mockDb.On("GetWorkspaceByUuid", "ws-2").Return(db.Workspace{Uuid: "ws-2", OwnerPubKey: "someone"})
+		mockDb.On("GetWorkspaceUser", "alice", "ws-2").Return(db.WorkspaceUsers{})
+
+		body := `{"WorkspaceUuid": "ws-2", "question": "hi"}`
+		rr := serve(ch, Required(WorkspaceBody("workspaceUUID")), newAccessRequest(http.MethodPost, "/send/build", body, "alice", nil))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Body Field Given Twice", func(t *testing.T) {
+		ch, _ := newHandler(t)
+
+		body := `{"chat_id": "chat-1", "workspaceUUID": "ws-1", "WORKSPACEUUID": "ws-2"}`
+		rr := serve(ch, WorkspaceBody("workspaceUUID"), newAccessRequest(http.MethodPost, "/send", body, "alice", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Message Of Another Chat", func(t *testing.T) {
+		ch, mockDb := newHandler(t)
+		mockDb.On("GetChatMessageByID", "msg-2").Return(db.ChatMessage{ID: "msg-2", ChatID: "chat-2"}, nil)
+
+		body := `{"chatId": "chat-1", "messageId": "msg-2"}`
+		rr := serve(ch, All(ChatBody("chatId"), MessageBody("messageId")), newAccessRequest(http.MethodPost, "/send/action", body, "alice", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Fields The Handler Does Not Read Are Ignored", func(t *testing.T) {
+		ch, _ := newHandler(t)
+
+		body := `{"chatId": "chat-1", "workspaceId": "ws-1"}`
+		rr := serve(ch, Required(ChatBody("chat_id")), newAccessRequest(http.MethodPost, "/status", body, "alice", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Request Body Is Kept For The Handler", func(t *testing.T) {
+		ch, mockDb := newHandler(t)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1", WorkspaceID: "ws-1", OwnerPubKey: "alice"}, nil)
+
+		body := `{"chatId": "chat-1", "workspaceId": "ws-1", "message": "hi"}`
+		var seen string
+		handler := ch.RequireChatAccess(All(ChatBody("chatId"), WorkspaceBody("workspaceId")))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload map[string]string
+			json.NewDecoder(r.Body).Decode(&payload)
+			seen = payload["message"]
+		}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, newAccessRequest(http.MethodPost, "/send", body, "alice", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "hi", seen)
+	})
+
+	t.Run("Chat Without Owner Or Workspace Is Closed", func(t *testing.T) {
+		ch, mockDb := newHandler(t)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1"}, nil)
+
+		rr := serve(ch, ChatParam("chat_id"), newAccessRequest(http.MethodGet, "/", "", "alice", map[string]string{"chat_id": "chat-1"}))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Listing Needs A Workspace", func(t *testing.T) {
+		ch, _ := newHandler(t)
+
+		rr := serve(ch, Required(WorkspaceQuery("workspaceId")), newAccessRequest(http.MethodGet, "/file/all", "", "alice", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("File Links Are Only Open To Members", func(t *testing.T) {
+		ch, mockDb := newHandler(t)
+		mockDb.On("GetFileAssetByUploadFilename", "a.pdf").Return(&db.FileAsset{ID: 1, WorkspaceID: "ws-1"}, nil)
+		mockDb.On("GetWorkspaceByUuid", "ws-1").Return(workspace)
+		mockDb.On("GetWorkspaceUser", "mallory", "ws-1").Return(db.WorkspaceUsers{})
+
+		rr := serve(ch, FileNameParam("name"), newAccessRequest(http.MethodGet, "/file/blob/a.pdf", "", "mallory", map[string]string{"name": "a.pdf"}))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestSendMessageChecksChatWorkspace(t *testing.T) {
+	mockDb := datamocks.NewDatabase(t)
+	ch := NewChatHandler(&http.Client{}, mockDb)
+	mockDb.On("GetPersonByPubkey", "test-pubkey").Return(db.Person{OwnerPubKey: "test-pubkey"})
+	mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1", WorkspaceID: "ws-1"}, nil)
+
+	rr := httptest.NewRecorder()
+	ch.SendMessage(rr, newBranchRequest(http.MethodPost, "/hivechat/send", `{"chat_id": "chat-1", "workspaceUUID": "ws-2", "message": "hi"}`, nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockDb.AssertNotCalled(t, "GetProductBrief", mock.Anything)
+}
+
+func TestSetChatVisibility(t *testing.T) {
+	t.Run("Owner Makes Chat Private", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		chat := db.Chat{ID: "chat-1", WorkspaceID: "ws-1", OwnerPubKey: "test-pubkey", Visibility: db.WorkspaceChat}
+		mockDb.On("GetChatByChatID", "chat-1").Return(chat, nil)
+		mockDb.On("UpdateChat", &db.Chat{ID: "chat-1", Visibility: db.PrivateChat}).Return(db.Chat{ID: "chat-1", Visibility: db.PrivateChat}, nil)
+
+		rr := httptest.NewRecorder()
+		ch.SetChatVisibility(rr, newBranchRequest(http.MethodPut, "/hivechat/chat-1/visibility", `{"visibility": "private"}`, map[string]string{"chat_id": "chat-1"}))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Other Members Cannot Change It", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1", WorkspaceID: "ws-1", OwnerPubKey: "alice"}, nil)
+
+		rr := httptest.NewRecorder()
+		ch.SetChatVisibility(rr, newBranchRequest(http.MethodPut, "/hivechat/chat-1/visibility", `{"visibility": "shared"}`, map[string]string{"chat_id": "chat-1"}))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Invalid Visibility", func(t *testing.T) {
+		ch := NewChatHandler(&http.Client{}, datamocks.NewDatabase(t))
+
+		rr := httptest.NewRecorder()
+		ch.SetChatVisibility(rr, newBranchRequest(http.MethodPut, "/hivechat/chat-1/visibility", `{"visibility": "public"}`, map[string]string{"chat_id": "chat-1"}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestChatShareLinks(t *testing.T) {
+	chat := db.Chat{ID: "chat-1", WorkspaceID: "ws-1", OwnerPubKey: "test-pubkey", Title: "Plan", Visibility: db.WorkspaceChat}
+
+	t.Run("Create Link Shares The Chat", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetChatByChatID", "chat-1").Return(chat, nil)
+		var stored *db.ChatShareLink
+		mockDb.On("CreateChatShareLink", mock.AnythingOfType("*db.ChatShareLink")).Return(func(link *db.ChatShareLink) db.ChatShareLink {
+			stored = link
+			return *link
+		}, nil)
+		mockDb.On("UpdateChat", &db.Chat{ID: "chat-1", Visibility: db.SharedChat}).Return(db.Chat{}, nil)
+
+		rr := httptest.NewRecorder()
+		ch.CreateChatShareLink(rr, newBranchRequest(http.MethodPost, "/hivechat/chat-1/share", `{"expiresInHours": 2}`, map[string]string{"chat_id": "chat-1"}))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response struct {
+			Data ShareLinkResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		require.NotEmpty(t, response.Data.Token)
+		assert.Equal(t, ch.cfg.Server.Host+"/hivechat/shared/"+response.Data.Token, response.Data.URL)
+		assert.Equal(t, hashShareToken(response.Data.Token), stored.TokenHash)
+		assert.NotContains(t, rr.Body.String(), stored.TokenHash)
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), stored.ExpiresAt, time.Minute)
+	})
+
+	t.Run("Expiry Is Limited", func(t *testing.T) {
+		ch := NewChatHandler(&http.Client{}, datamocks.NewDatabase(t))
+
+		rr := httptest.NewRecorder()
+		ch.CreateChatShareLink(rr, newBranchRequest(http.MethodPost, "/hivechat/chat-1/share", `{"expiresInHours": 10000}`, map[string]string{"chat_id": "chat-1"}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Revoke Link", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetChatByChatID", "chat-1").Return(chat, nil)
+		mockDb.On("RevokeChatShareLink", "chat-1", "link-1").Return(nil).Once()
+		mockDb.On("RevokeChatShareLink", "chat-1", "link-2").Return(errors.New("share link not found")).Once()
+
+		rr := httptest.NewRecorder()
+		ch.RevokeChatShareLink(rr, newBranchRequest(http.MethodDelete, "/hivechat/chat-1/share/link-1", "", map[string]string{"chat_id": "chat-1", "link_id": "link-1"}))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		rr = httptest.NewRecorder()
+		ch.RevokeChatShareLink(rr, newBranchRequest(http.MethodDelete, "/hivechat/chat-1/share/link-2", "", map[string]string{"chat_id": "chat-1", "link_id": "link-2"}))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestGetSharedChat(t *testing.T) {
+	token := "share-token"
+	sharedChat := db.Chat{ID: "chat-1", Title: "Plan", Visibility: db.SharedChat}
+	link := db.ChatShareLink{ID: "link-1", ChatID: "chat-1", TokenHash: hashShareToken(token), ExpiresAt: time.Now().Add(time.Hour)}
+	request := func() *http.Request {
+		return newAccessRequest(http.MethodGet, "/hivechat/shared/"+token, "", "", map[string]string{"token": token})
+	}
+
+	t.Run("Returns Active Branch", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetChatShareLinkByTokenHash", hashShareToken(token)).Return(link, nil)
+		mockDb.On("GetChatByChatID", "chat-1").Return(sharedChat, nil)
+		mockDb.On("GetChatBranchHistory", "chat-1", "").Return([]db.ChatMessage{{ID: "m1", ChatID: "chat-1", Message: "hello"}}, nil)
+		mockDb.On("GetAllArtifactsByChatID", "chat-1").Return([]db.Artifact{{MessageID: "m1"}, {MessageID: "other-branch"}}, nil)
+
+		rr := httptest.NewRecorder()
+		ch.GetSharedChat(rr, request())
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response SharedChatResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, "Plan", response.Title)
+		assert.Len(t, response.Messages, 1)
+		require.Len(t, response.Artifacts, 1)
+		assert.Equal(t, "m1", response.Artifacts[0].MessageID)
+	})
+
+	t.Run("Expired Or Revoked Link", func(t *testing.T) {
+		revokedAt := time.Now()
+		for _, l := range []db.ChatShareLink{
+			{ChatID: "chat-1", ExpiresAt: time.Now().Add(-time.Minute)},
+			{ChatID: "chat-1", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt},
+		} {
+			mockDb := datamocks.NewDatabase(t)
+			ch := NewChatHandler(&http.Client{}, mockDb)
+			mockDb.On("GetChatShareLinkByTokenHash", hashShareToken(token)).Return(l, nil)
+
+			rr := httptest.NewRecorder()
+			ch.GetSharedChat(rr, request())
+
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("Chat No Longer Shared", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetChatShareLinkByTokenHash", hashShareToken(token)).Return(link, nil)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1", Visibility: db.PrivateChat}, nil)
+
+		rr := httptest.NewRecorder()
+		ch.GetSharedChat(rr, request())
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,505 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+const (
+	defaultActionBountyPrice = 21
+	actionWebhookTimeout     = 15 * time.Second
+)
+
+// errWebhookAddress is returned when a webhook resolves to an address the
+// server must not call.
+var errWebhookAddress = errors.New("webhook address is not allowed")
+
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// publicWebhookIP reports whether ip is a public unicast address. Loopback,
+// private, link local (cloud metadata) and shared addresses are refused so
+// action webhooks cannot reach the internal network.
+func publicWebhookIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() ||
+		sharedAddressSpace.Contains(ip))
+}
+
+// newActionWebhookClient returns the client that calls action webhooks. It
+// checks the address of every connection after DNS resolution, redirects
+// included, and gives up after actionWebhookTimeout.
+func newActionWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: actionWebhookTimeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicWebhookIP(ip) {
+				return errWebhookAddress
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: actionWebhookTimeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: actionWebhookTimeout,
+		},
+	}
+}
+
+type ExecuteActionRequest struct {
+	OptionIndex int `json:"option_index"`
+}
+
+// actionRequest is a chosen option of an action artifact together with the
+// chat it was offered in.
+type actionRequest struct {
+	Artifact    db.Artifact
+	OptionIndex int
+	Option      db.Option
+	Chat        db.Chat
+	PubKey      string
+}
+
+// actionExecutor runs an option of a kind and returns its result.
+type actionExecutor func(ch *ChatHandler, action actionRequest) (db.PropertyMap, error)
+
+var actionExecutors = map[db.ActionKind]actionExecutor{
+	db.CreateTicketAction:       createTicketAction,
+	db.CreateBountyAction:       createBountyAction,
+	db.UpdateFeatureBriefAction: updateFeatureBriefAction,
+	db.OpenCodespaceAction:      openCodespaceAction,
+	db.CallWebhookAction:        callWebhookAction,
+}
+
+// requiredActionParams are the params each kind must declare.
+var requiredActionParams = map[db.ActionKind][]string{
+	db.CreateTicketAction:       {"feature_uuid", "name"},
+	db.CreateBountyAction:       {"title"},
+	db.UpdateFeatureBriefAction: {"feature_uuid", "brief"},
+}
+
+// actionKind returns the kind of an option. Options from before kinds
+// existed that only carry a webhook are webhook calls.
+func actionKind(option db.Option) db.ActionKind {
+	if option.Kind == "" && option.Webhook != "" {
+		return db.CallWebhookAction
+	}
+	return option.Kind
+}
+
+func actionContent(artifact db.Artifact) (db.ActionContent, error) {
+	var content db.ActionContent
+	raw, err := json.Marshal(artifact.Content)
+	if err != nil {
+		return content, err
+	}
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return content, fmt.Errorf("invalid action content: %w", err)
+	}
+	return content, nil
+}
+
+// validateActionOption checks that an option declares a known kind and the
+// params that kind needs.
+func validateActionOption(option db.Option) error {
+	kind := actionKind(option)
+	if kind == "" {
+		return errors.New("option has no action kind")
+	}
+	if _, ok := actionExecutors[kind]; !ok {
+		return fmt.Errorf("unknown action kind %q", kind)
+	}
+	for _, key := range requiredActionParams[kind] {
+		if actionParam(option.Params, key) == "" {
+			return fmt.Errorf("%s action requires %s", kind, key)
+		}
+	}
+	if kind == db.CallWebhookAction {
+		parsed, err := url.Parse(option.Webhook)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.New("call_webhook action requires an http or https webhook")
+		}
+	}
+	return nil
+}
+
+// validateActionArtifact checks the options of action artifacts. Options
+// without a kind or webhook only answer the chat and are left alone.
+func validateActionArtifact(artifact db.Artifact) error {
+	if artifact.Type != db.ActionArtifact {
+		return nil
+	}
+	content, err := actionContent(artifact)
+	if err != nil {
+		return err
+	}
+	for i, option := range content.Options {
+		if option.Kind == "" && option.Webhook == "" {
+			continue
+		}
+		if err := validateActionOption(option); err != nil {
+			return fmt.Errorf("option %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// actionOptionsChanged reports whether an update of an action artifact
+// changes its options.
+func actionOptionsChanged(existing, updated db.Artifact) (bool, error) {
+	updated.Type = existing.Type
+	before, err := actionContent(existing)
+	if err != nil {
+		return false, err
+	}
+	after, err := actionContent(updated)
+	if err != nil {
+		return false, err
+	}
+	a, _ := json.Marshal(before.Options)
+	b, _ := json.Marshal(after.Options)
+	return !bytes.Equal(a, b), nil
+}
+
+func actionParam(params db.PropertyMap, key string) string {
+	value, ok := params[key]
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return fmt.Sprint(value)
+}
+
+// workspaceFeature returns a feature of the workspace of the chat.
+func (ch *ChatHandler) workspaceFeature(action actionRequest) (db.WorkspaceFeatures, error) {
+	featureUUID := actionParam(action.Option.Params, "feature_uuid")
+	feature := ch.db.GetFeatureByUuid(featureUUID)
+	if feature.Uuid == "" || feature.WorkspaceUuid != action.Chat.WorkspaceID {
+		return db.WorkspaceFeatures{}, fmt.Errorf("feature %s not found in the workspace of the chat", featureUUID)
+	}
+	return feature, nil
+}
+
+func createTicketAction(ch *ChatHandler, action actionRequest) (db.PropertyMap, error) {
+	feature, err := ch.workspaceFeature(action)
+	if err != nil {
+		return nil, err
+	}
+
+	params := action.Option.Params
+	phaseUUID := actionParam(params, "phase_uuid")
+	if phaseUUID != "" {
+		if _, err := ch.db.GetFeaturePhaseByUuid(feature.Uuid, phaseUUID); err != nil {
+			return nil, fmt.Errorf("phase %s not found in feature %s", phaseUUID, feature.Uuid)
+		}
+	}
+
+	ticketUUID := uuid.New()
+	author := db.AgentAuthor
+	ticket, err := ch.db.CreateOrEditTicket(&db.Tickets{
+		UUID:          ticketUUID,
+		TicketGroup:   &ticketUUID,
+		WorkspaceUuid: feature.WorkspaceUuid,
+		FeatureUUID:   feature.Uuid,
+		PhaseUUID:     phaseUUID,
+		Name:          actionParam(params, "name"),
+		Description:   actionParam(params, "description"),
+		Status:        db.DraftTicket,
+		Author:        &author,
+		AuthorID:      &action.PubKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db.PropertyMap{"ticket_uuid": ticket.UUID.String()}, nil
+}
+
+func createBountyAction(ch *ChatHandler, action actionRequest) (db.PropertyMap, error) {
+	if action.Chat.WorkspaceID == "" {
+		return nil, errors.New("the chat has no workspace")
+	}
+
+	params := action.Option.Params
+	price := uint(defaultActionBountyPrice)
+	if value, ok := params["price"].(float64); ok {
+		if value < 0 {
+			return nil, errors.New("price must not be negative")
+		}
+		price = uint(value)
+	}
+
+	featureUUID := actionParam(params, "feature_uuid")
+	if featureUUID != "" {
+		if _, err := ch.workspaceFeature(action); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	bounty, err := ch.db.CreateOrEditBounty(db.NewBounty{
+		Title:           actionParam(params, "title"),
+		Description:     actionParam(params, "description"),
+		WorkspaceUuid:   action.Chat.WorkspaceID,
+		FeatureUuid:     featureUUID,
+		PhaseUuid:       actionParam(params, "phase_uuid"),
+		OwnerID:         action.PubKey,
+		Type:            "freelance_job_request",
+		WantedType:      "Other",
+		Price:           price,
+		Created:         now.Unix(),
+		Updated:         &now,
+		Show:            true,
+		CodingLanguages: pq.StringArray{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db.PropertyMap{"bounty_id": bounty.ID}, nil
+}
+
+func updateFeatureBriefAction(ch *ChatHandler, action actionRequest) (db.PropertyMap, error) {
+	feature, err := ch.workspaceFeature(action)
+	if err != nil {
+		return nil, err
+	}
+
+	feature.Brief = actionParam(action.Option.Params, "brief")
+	feature.UpdatedBy = action.PubKey
+	updated, err := ch.db.CreateOrEditFeature(feature)
+	if err != nil {
+		return nil, err
+	}
+	return db.PropertyMap{"feature_uuid": updated.Uuid}, nil
+}
+
+func openCodespaceAction(ch *ChatHandler, action actionRequest) (db.PropertyMap, error) {
+	if action.Chat.WorkspaceID == "" {
+		return nil, errors.New("the chat has no workspace")
+	}
+	codeSpace, err := ch.db.GetCodeSpaceMapByWorkspaceAndUser(action.Chat.WorkspaceID, action.PubKey)
+	if err != nil || codeSpace.CodeSpaceURL == "" {
+		return nil, errors.New("no codespace is set up for you in this workspace")
+	}
+	return db.PropertyMap{"url": codeSpace.CodeSpaceURL}, nil
+}
+
+func callWebhookAction(ch *ChatHandler, action actionRequest) (db.PropertyMap, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"chatId":         action.Chat.ID,
+		"messageId":      action.Artifact.MessageID,
+		"artifactId":     action.Artifact.ID,
+		"optionIndex":    action.OptionIndex,
+		"optionLabel":    action.Option.OptionLabel,
+		"optionResponse": action.Option.OptionResponse,
+		"params":         action.Option.Params,
+		"pubkey":         action.PubKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, action.Option.Webhook, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := ch.webhookClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return db.PropertyMap{"status_code": resp.StatusCode}, nil
+}
+
+// ExecuteAction runs an option of an action artifact
+//
+//	@Summary		Execute an action
+//	@Description	Run an option of an action artifact on the server: create a ticket or bounty, update a feature brief, open a codespace or call a webhook. The outcome is stored as an action_result artifact on the same message. An option runs once; only failed executions can be retried.
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			artifactId	path		string					true	"Artifact ID"
+//	@Param			request		body		ExecuteActionRequest	true	"Option"
+//	@Success		200			{object}	db.ActionExecution
+//	@Failure		400			{object}	map[string]string
+//	@Failure		404			{object}	map[string]string
+//	@Failure		409			{object}	map[string]string
+//	@Failure		502			{object}	db.ActionExecution
+//	@Router			/hivechat/artefacts/{artifactId}/execute [post]
+func (ch *ChatHandler) ExecuteAction(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		jsonErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	artifactID, err := uuid.Parse(chi.URLParam(r, "artifactId"))
+	if err != nil {
+		jsonErrorResponse(w, "Invalid artifact ID format", http.StatusBadRequest)
+		return
+	}
+
+	var request ExecuteActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		jsonErrorResponse(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	artifact, err := ch.db.GetArtifactByID(artifactID)
+	if err != nil || artifact == nil {
+		jsonErrorResponse(w, "Artifact not found", http.StatusNotFound)
+		return
+	}
+	if artifact.Type != db.ActionArtifact {
+		jsonErrorResponse(w, "Artifact is not an action", http.StatusBadRequest)
+		return
+	}
+
+	content, err := actionContent(*artifact)
+	if err != nil {
+		jsonErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.OptionIndex < 0 || request.OptionIndex >= len(content.Options) {
+		jsonErrorResponse(w, "Invalid option index", http.StatusBadRequest)
+		return
+	}
+	option := content.Options[request.OptionIndex]
+	if err := validateActionOption(option); err != nil {
+		jsonErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	message, err := ch.db.GetChatMessageByID(artifact.MessageID)
+	if err != nil {
+		jsonErrorResponse(w, "Message of the artifact not found", http.StatusNotFound)
+		return
+	}
+	chat, err := ch.db.GetChatByChatID(message.ChatID)
+	if err != nil {
+		jsonErrorResponse(w, "Chat not found", http.StatusNotFound)
+		return
+	}
+
+	kind := actionKind(option)
+	execution, err := ch.db.ClaimActionExecution(&db.ActionExecution{
+		ArtifactID:  artifact.ID,
+		OptionIndex: request.OptionIndex,
+		ChatID:      chat.ID,
+		Kind:        kind,
+		ExecutedBy:  pubKeyFromAuth,
+	})
+	if errors.Is(err, db.ErrActionAlreadyExecuted) {
+		jsonErrorResponse(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		logger.Log.Error("[chat actions] failed to claim option %d of artifact %s: %v", request.OptionIndex, artifact.ID, err)
+		jsonErrorResponse(w, "Failed to start action", http.StatusInternalServerError)
+		return
+	}
+
+	result, execErr := actionExecutors[kind](ch, actionRequest{
+		Artifact:    *artifact,
+		OptionIndex: request.OptionIndex,
+		Option:      option,
+		Chat:        chat,
+		PubKey:      pubKeyFromAuth,
+	})
+	execution.Status = db.ActionSucceeded
+	execution.Result = result
+	if execErr != nil {
+		logger.Log.Error("[chat actions] %s action of artifact %s failed: %v", kind, artifact.ID, execErr)
+		execution.Status = db.ActionFailed
+		execution.Error = execErr.Error()
+	}
+
+	resultArtifact, err := ch.db.CreateArtifact(&db.Artifact{
+		ID:        uuid.New(),
+		MessageID: artifact.MessageID,
+		Type:      db.ActionResultArtifact,
+		Content: db.PropertyMap{
+			"action_artifact_id": artifact.ID.String(),
+			"execution_id":       execution.ID.String(),
+			"option_index":       request.OptionIndex,
+			"option_label":       option.OptionLabel,
+			"kind":               kind,
+			"status":             execution.Status,
+			"result":             execution.Result,
+			"error":              execution.Error,
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		logger.Log.Error("[chat actions] failed to store result of artifact %s: %v", artifact.ID, err)
+	} else {
+		execution.ResultArtifactID = &resultArtifact.ID
+	}
+
+	completed, err := ch.db.CompleteActionExecution(&execution)
+	if err != nil {
+		logger.Log.Error("[chat actions] failed to complete execution %s: %v", execution.ID, err)
+		jsonErrorResponse(w, "Failed to record action result", http.StatusInternalServerError)
+		return
+	}
+
+	if completed.Status == db.ActionFailed {
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	json.NewEncoder(w).Encode(completed)
+}
+
+// GetActionExecutions lists the executions of an action artifact
+//
+//	@Summary		List action executions
+//	@Description	List the executed options of an action artifact with their status and result
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			artifactId	path	string	true	"Artifact ID"
+//	@Success		200			{array}	db.ActionExecution
+//	@Router			/hivechat/artefacts/{artifactId}/executions [get]
+func (ch *ChatHandler) GetActionExecutions(w http.ResponseWriter, r *http.Request) {
+	artifactID, err := uuid.Parse(chi.URLParam(r, "artifactId"))
+	if err != nil {
+		jsonErrorResponse(w, "Invalid artifact ID format", http.StatusBadRequest)
+		return
+	}
+
+	executions, err := ch.db.GetActionExecutions(artifactID)
+	if err != nil {
+		jsonErrorResponse(w, fmt.Sprintf("Failed to fetch action executions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(executions)
+}
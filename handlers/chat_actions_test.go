@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newActionArtifact(options ...map[string]interface{}) *db.Artifact {
+	list := make([]interface{}, len(options))
+	for i, option := range options {
+		list[i] = option
+	}
+	return &db.Artifact{
+		ID:        uuid.New(),
+		MessageID: "message-1",
+		Type:      db.ActionArtifact,
+		Content: db.PropertyMap{
+			"action_text": "What next?",
+			"options":     list,
+		},
+	}
+}
+
+func TestValidateActionArtifact(t *testing.T) {
+	t.Run("Known Kinds With Params", func(t *testing.T) {
+		artifact := newActionArtifact(
+			map[string]interface{}{"option_label": "Ticket", "kind": "create_ticket", "params": map[string]interface{}{"feature_uuid": "f-1", "name": "Fix it"}},
+			map[string]interface{}{"option_label": "Legacy", "webhook": "https://example.com/hook"},
+			map[string]interface{}{"option_label": "Reply", "option_response": "ok"},
+		)
+		assert.NoError(t, validateActionArtifact(*artifact))
+	})
+
+	t.Run("Unknown Kind", func(t *testing.T) {
+		artifact := newActionArtifact(map[string]interface{}{"kind": "delete_workspace"})
+		assert.EqualError(t, validateActionArtifact(*artifact), `option 0: unknown action kind "delete_workspace"`)
+	})
+
+	t.Run("Missing Param", func(t *testing.T) {
+		artifact := newActionArtifact(map[string]interface{}{"kind": "update_feature_brief", "params": map[string]interface{}{"feature_uuid": "f-1"}})
+		assert.EqualError(t, validateActionArtifact(*artifact), "option 0: update_feature_brief action requires brief")
+	})
+
+	t.Run("Webhook Must Be HTTP", func(t *testing.T) {
+		artifact := newActionArtifact(map[string]interface{}{"kind": "call_webhook", "webhook": "file:///etc/passwd"})
+		assert.Error(t, validateActionArtifact(*artifact))
+	})
+}
+
+func TestExecuteAction(t *testing.T) {
+	chat := db.Chat{ID: "chat-1", WorkspaceID: "ws-1"}
+	execute := func(ch *ChatHandler, artifact *db.Artifact, optionIndex int) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		body, _ := json.Marshal(ExecuteActionRequest{OptionIndex: optionIndex})
+		ch.ExecuteAction(rr, newBranchRequest(http.MethodPost, "/hivechat/artefacts/"+artifact.ID.String()+"/execute", string(body), map[string]string{"artifactId": artifact.ID.String()}))
+		return rr
+	}
+	expectChat := func(mockDb *datamocks.Database, artifact *db.Artifact) {
+		mockDb.On("GetArtifactByID", artifact.ID).Return(artifact, nil)
+		mockDb.On("GetChatMessageByID", "message-1").Return(db.ChatMessage{ID: "message-1", ChatID: "chat-1"}, nil)
+		mockDb.On("GetChatByChatID", "chat-1").Return(chat, nil)
+	}
+	claim := func(mockDb *datamocks.Database, kind db.ActionKind) {
+		mockDb.On("ClaimActionExecution", mock.MatchedBy(func(e *db.ActionExecution) bool {
+			return e.Kind == kind && e.ExecutedBy == "test-pubkey" && e.ChatID == "chat-1"
+		})).Return(func(e *db.ActionExecution) db.ActionExecution {
+			e.ID = uuid.New()
+			e.Status = db.ActionRunning
+			return *e
+		}, nil).Once()
+	}
+	complete := func(mockDb *datamocks.Database) {
+		mockDb.On("CreateArtifact", mock.MatchedBy(func(a *db.Artifact) bool {
+			return a.Type == db.ActionResultArtifact && a.MessageID == "message-1"
+		})).Return(func(a *db.Artifact) *db.Artifact { return a }, nil).Once()
+		mockDb.On("CompleteActionExecution", mock.AnythingOfType("*db.ActionExecution")).Return(func(e *db.ActionExecution) db.ActionExecution { return *e }, nil).Once()
+	}
+
+	t.Run("Creates Ticket In Chat Workspace", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		artifact := newActionArtifact(map[string]interface{}{
+			"option_label": "Create ticket",
+			"kind":         "create_ticket",
+			"params":       map[string]interface{}{"feature_uuid": "f-1", "name": "Add login", "description": "Use LNURL"},
+		})
+		expectChat(mockDb, artifact)
+		claim(mockDb, db.CreateTicketAction)
+		mockDb.On("GetFeatureByUuid", "f-1").Return(db.WorkspaceFeatures{Uuid: "f-1", WorkspaceUuid: "ws-1"})
+		mockDb.On("CreateOrEditTicket", mock.MatchedBy(func(ticket *db.Tickets) bool {
+			return ticket.Name == "Add login" && ticket.Description == "Use LNURL" && ticket.WorkspaceUuid == "ws-1" && *ticket.TicketGroup == ticket.UUID
+		})).Return(func(ticket *db.Tickets) db.Tickets { return *ticket }, nil)
+		complete(mockDb)
+
+		rr := execute(ch, artifact, 0)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var execution db.ActionExecution
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&execution))
+		assert.Equal(t, db.ActionSucceeded, execution.Status)
+		assert.NotEmpty(t, execution.Result["ticket_uuid"])
+		assert.NotNil(t, execution.ResultArtifactID)
+	})
+
+	t.Run("Feature Of Another Workspace Fails", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		artifact := newActionArtifact(map[string]interface{}{
+			"kind":   "update_feature_brief",
+			"params": map[string]interface{}{"feature_uuid": "f-2", "brief": "New brief"},
+		})
+		expectChat(mockDb, artifact)
+		claim(mockDb, db.UpdateFeatureBriefAction)
+		mockDb.On("GetFeatureByUuid", "f-2").Return(db.WorkspaceFeatures{Uuid: "f-2", WorkspaceUuid: "ws-2"})
+		complete(mockDb)
+
+		rr := execute(ch, artifact, 0)
+
+		require.Equal(t, http.StatusBadGateway, rr.Code)
+		var execution db.ActionExecution
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&execution))
+		assert.Equal(t, db.ActionFailed, execution.Status)
+		assert.Contains(t, execution.Error, "not found in the workspace")
+	})
+
+	t.Run("Calls Webhook", func(t *testing.T) {
+		var received map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&received)
+		}))
+		defer server.Close()
+
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		ch.webhookClient = server.Client()
+		artifact := newActionArtifact(
+			map[string]interface{}{"option_label": "Reply", "option_response": "ok"},
+			map[string]interface{}{"option_label": "Deploy", "webhook": server.URL, "params": map[string]interface{}{"env": "staging"}},
+		)
+		expectChat(mockDb, artifact)
+		claim(mockDb, db.CallWebhookAction)
+		complete(mockDb)
+
+		rr := execute(ch, artifact, 1)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "Deploy", received["optionLabel"])
+		assert.Equal(t, map[string]interface{}{"env": "staging"}, received["params"])
+	})
+
+	t.Run("Webhook On The Internal Network Fails", func(t *testing.T) {
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		artifact := newActionArtifact(map[string]interface{}{"option_label": "Deploy", "webhook": server.URL})
+		expectChat(mockDb, artifact)
+		claim(mockDb, db.CallWebhookAction)
+		complete(mockDb)
+
+		rr := execute(ch, artifact, 0)
+
+		require.Equal(t, http.StatusBadGateway, rr.Code)
+		assert.False(t, called)
+		var execution db.ActionExecution
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&execution))
+		assert.Contains(t, execution.Error, errWebhookAddress.Error())
+	})
+
+	t.Run("Option Runs Once", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		artifact := newActionArtifact(map[string]interface{}{"kind": "open_codespace"})
+		expectChat(mockDb, artifact)
+		mockDb.On("ClaimActionExecution", mock.Anything).Return(db.ActionExecution{}, db.ErrActionAlreadyExecuted)
+
+		rr := execute(ch, artifact, 0)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Option Without Kind", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		artifact := newActionArtifact(map[string]interface{}{"option_label": "Reply", "option_response": "ok"})
+		mockDb.On("GetArtifactByID", artifact.ID).Return(artifact, nil)
+
+		rr := execute(ch, artifact, 0)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid Option Index", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		artifact := newActionArtifact(map[string]interface{}{"kind": "open_codespace"})
+		mockDb.On("GetArtifactByID", artifact.ID).Return(artifact, nil)
+
+		rr := execute(ch, artifact, 3)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Not An Action", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		artifact := &db.Artifact{ID: uuid.New(), MessageID: "message-1", Type: db.TextArtifact}
+		mockDb.On("GetArtifactByID", artifact.ID).Return(artifact, nil)
+
+		rr := execute(ch, artifact, 0)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unknown Artifact", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		artifact := newActionArtifact()
+		mockDb.On("GetArtifactByID", artifact.ID).Return(nil, errors.New("not found"))
+
+		rr := execute(ch, artifact, 0)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestPublicWebhookIP(t *testing.T) {
+	for _, address := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fd00::1", "fe80::1"} {
+		assert.False(t, publicWebhookIP(net.ParseIP(address)), address)
+	}
+	assert.True(t, publicWebhookIP(net.ParseIP("93.184.216.34")))
+	assert.True(t, publicWebhookIP(net.ParseIP("2606:2800:220:1::")))
+}
+
+func TestUpdateActionArtefact(t *testing.T) {
+	artifact := newActionArtifact(map[string]interface{}{"option_label": "Deploy", "webhook": "https://example.com/hook"})
+	update := func(mockDb *datamocks.Database, content string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		NewChatHandler(&http.Client{}, mockDb).UpdateArtefact(rr, newBranchRequest(http.MethodPut, "/hivechat/artefacts/"+artifact.ID.String(), content, map[string]string{"artifactId": artifact.ID.String()}))
+		return rr
+	}
+
+	t.Run("Options Cannot Change", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetArtifactByID", artifact.ID).Return(artifact, nil)
+
+		rr := update(mockDb, `{"content": {"action_text": "What next?", "options": [{"option_label": "Deploy", "webhook": "http://169.254.169.254/"}]}}`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockDb.AssertNotCalled(t, "UpdateArtifact", mock.Anything)
+	})
+
+	t.Run("Text Can Change", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetArtifactByID", artifact.ID).Return(artifact, nil)
+		mockDb.On("UpdateArtifact", mock.MatchedBy(func(a *db.Artifact) bool {
+			return a.ID == artifact.ID && a.Content["action_text"] == "Ship it?"
+		})).Return(func(a *db.Artifact) *db.Artifact { return a }, nil)
+
+		rr := update(mockDb, `{"id": "`+uuid.NewString()+`", "content": {"action_text": "Ship it?", "options": [{"option_label": "Deploy", "webhook": "https://example.com/hook"}]}}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
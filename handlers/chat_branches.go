@@ -0,0 +1,436 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/rs/xid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type SwitchChatBranchRequest struct {
+	BranchID string `json:"branchId"`
+}
+
+type ChatBranchesResponse struct {
+	ActiveBranchID string          `json:"activeBranchId"`
+	Branches       []db.ChatBranch `json:"branches"`
+}
+
+// findChatMessage returns the message of the chat with the given id.
+func (ch *ChatHandler) findChatMessage(chatID, messageID string) (db.ChatMessage, bool, error) {
+	messages, err := ch.db.GetChatMessagesForChatID(chatID)
+	if err != nil {
+		return db.ChatMessage{}, false, err
+	}
+	for _, message := range messages {
+		if message.ID == messageID {
+			return message, true, nil
+		}
+	}
+	return db.ChatMessage{}, false, nil
+}
+
+// branchRequestUser returns the person making the request, writing the
+// error response when there is none.
+func (ch *ChatHandler) branchRequestUser(w http.ResponseWriter, r *http.Request) (db.Person, string, bool) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return db.Person{}, "", false
+	}
+
+	user := ch.db.GetPersonByPubkey(pubKeyFromAuth)
+	if user.OwnerPubKey != pubKeyFromAuth {
+		logger.Log.Info("Person not exists")
+		w.WriteHeader(http.StatusBadRequest)
+		return db.Person{}, "", false
+	}
+
+	return user, pubKeyFromAuth, true
+}
+
+// EditChatMessage edits a user message on a new branch
+//
+//	@Summary		Edit a chat message
+//	@Description	Edit a past user message. The edit starts a new branch from the message before it, becomes the active branch and is sent to Stakwork with the history of that branch.
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id		path		string				true	"Chat ID"
+//	@Param			message_id	path		string				true	"Message ID"
+//	@Param			request		body		SendMessageRequest	true	"Edited message"
+//	@Success		200			{object}	ChatResponse
+//	@Failure		400			{object}	ChatResponse
+//	@Failure		401			{object}	ChatResponse
+//	@Failure		404			{object}	ChatResponse
+//	@Failure		500			{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/messages/{message_id} [put]
+func (ch *ChatHandler) EditChatMessage(w http.ResponseWriter, r *http.Request) {
+	user, pubKeyFromAuth, ok := ch.branchRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	chatID := chi.URLParam(r, "chat_id")
+	messageID := chi.URLParam(r, "message_id")
+
+	var request SendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+		return
+	}
+	request.ChatID = chatID
+
+	if request.WorkspaceUUID == "" || request.Message == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "workspaceUUID and message are required",
+		})
+		return
+	}
+
+	original, found, err := ch.findChatMessage(chatID, messageID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to fetch chat messages: %v", err),
+		})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Message not found",
+		})
+		return
+	}
+	if original.Role != db.UserRole {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Only user messages can be edited",
+		})
+		return
+	}
+
+	context, err := ch.db.GetProductBrief(request.WorkspaceUUID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Error retrieving product brief",
+		})
+		return
+	}
+
+	branch, err := ch.db.CreateChatBranch(chatID, original.ParentID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to create branch: %v", err),
+		})
+		return
+	}
+
+	history, err := ch.db.GetChatBranchHistory(chatID, branch.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to fetch chat history: %v", err),
+		})
+		return
+	}
+
+	createdMessage, err := ch.db.AddChatMessage(&db.ChatMessage{
+		ID:          xid.New().String(),
+		ChatID:      chatID,
+		Message:     request.Message,
+		PDFURL:      request.PDFURL,
+		Role:        db.UserRole,
+		Timestamp:   time.Now(),
+		ContextTags: contextTagsFromRequest(request),
+		Status:      db.SendingStatus,
+		Source:      db.UserSource,
+		ParentID:    original.ParentID,
+		BranchID:    branch.ID,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to save message: %v", err),
+		})
+		return
+	}
+
+	if _, err := ch.db.SetActiveChatBranch(chatID, branch.ID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to switch branch: %v", err),
+		})
+		return
+	}
+
+	if !ch.dispatchChatMessage(w, request, user, pubKeyFromAuth, createdMessage, ch.buildMessageHistory(history), context) {
+		createdMessage.Status = db.ErrorStatus
+		ch.db.UpdateChatMessage(&createdMessage)
+	}
+}
+
+// RegenerateChatMessage asks for a new assistant reply on a new branch
+//
+//	@Summary		Regenerate an assistant reply
+//	@Description	Ask Stakwork again for the reply to the user message before an assistant message. The new reply goes on a new branch that becomes the active branch; the old reply is kept on its branch.
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id		path		string				true	"Chat ID"
+//	@Param			message_id	path		string				true	"Assistant message ID"
+//	@Param			request		body		SendMessageRequest	true	"Workspace and model selection"
+//	@Success		200			{object}	ChatResponse
+//	@Failure		400			{object}	ChatResponse
+//	@Failure		401			{object}	ChatResponse
+//	@Failure		404			{object}	ChatResponse
+//	@Failure		500			{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/messages/{message_id}/regenerate [post]
+func (ch *ChatHandler) RegenerateChatMessage(w http.ResponseWriter, r *http.Request) {
+	user, pubKeyFromAuth, ok := ch.branchRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	chatID := chi.URLParam(r, "chat_id")
+	messageID := chi.URLParam(r, "message_id")
+
+	var request SendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+		return
+	}
+	request.ChatID = chatID
+
+	if request.WorkspaceUUID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "workspaceUUID is required",
+		})
+		return
+	}
+
+	reply, found, err := ch.findChatMessage(chatID, messageID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to fetch chat messages: %v", err),
+		})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Message not found",
+		})
+		return
+	}
+	if reply.Role != db.AssistantRole || reply.ParentID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Only replies to a user message can be regenerated",
+		})
+		return
+	}
+
+	prompt, found, err := ch.findChatMessage(chatID, reply.ParentID)
+	if err != nil || !found || prompt.Role != db.UserRole {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Only replies to a user message can be regenerated",
+		})
+		return
+	}
+
+	context, err := ch.db.GetProductBrief(request.WorkspaceUUID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Error retrieving product brief",
+		})
+		return
+	}
+
+	branch, err := ch.db.CreateChatBranch(chatID, prompt.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to create branch: %v", err),
+		})
+		return
+	}
+
+	history, err := ch.db.GetChatBranchHistory(chatID, branch.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to fetch chat history: %v", err),
+		})
+		return
+	}
+	// the new branch ends at the prompt, which is sent as the message
+	if len(history) > 0 {
+		history = history[:len(history)-1]
+	}
+
+	if _, err := ch.db.SetActiveChatBranch(chatID, branch.ID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to switch branch: %v", err),
+		})
+		return
+	}
+
+	request.Message = prompt.Message
+	request.PDFURL = prompt.PDFURL
+	prompt.BranchID = branch.ID
+
+	ch.dispatchChatMessage(w, request, user, pubKeyFromAuth, prompt, ch.buildMessageHistory(history), context)
+}
+
+// GetChatBranches lists the branches of a chat
+//
+//	@Summary		List chat branches
+//	@Description	List the branches of a chat and the active one. The main branch is always present and is not listed.
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id	path		string	true	"Chat ID"
+//	@Success		200		{object}	ChatResponse{data=ChatBranchesResponse}
+//	@Failure		404		{object}	ChatResponse
+//	@Failure		500		{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/branches [get]
+func (ch *ChatHandler) GetChatBranches(w http.ResponseWriter, r *http.Request) {
+	chatID := chi.URLParam(r, "chat_id")
+
+	chat, err := ch.db.GetChatByChatID(chatID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat not found",
+		})
+		return
+	}
+
+	branches, err := ch.db.GetChatBranches(chatID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to fetch branches: %v", err),
+		})
+		return
+	}
+
+	activeBranchID := chat.ActiveBranchID
+	if activeBranchID == "" {
+		activeBranchID = db.MainChatBranch
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Data: ChatBranchesResponse{
+			ActiveBranchID: activeBranchID,
+			Branches:       branches,
+		},
+	})
+}
+
+// SwitchChatBranch changes the active branch of a chat
+//
+//	@Summary		Switch chat branch
+//	@Description	Make the given branch the active branch of a chat and return its history
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id	path		string					true	"Chat ID"
+//	@Param			request	body		SwitchChatBranchRequest	true	"Branch to switch to"
+//	@Success		200		{object}	HistoryChatResponse
+//	@Failure		400		{object}	ChatResponse
+//	@Failure		404		{object}	ChatResponse
+//	@Failure		500		{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/branch [put]
+func (ch *ChatHandler) SwitchChatBranch(w http.ResponseWriter, r *http.Request) {
+	chatID := chi.URLParam(r, "chat_id")
+
+	var request SwitchChatBranchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.BranchID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "branchId is required",
+		})
+		return
+	}
+
+	if _, err := ch.db.SetActiveChatBranch(chatID, request.BranchID); err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "branch not found" || err.Error() == "chat not found" {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to switch branch: %v", err),
+		})
+		return
+	}
+
+	messages, err := ch.db.GetChatBranchHistory(chatID, request.BranchID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to fetch chat history: %v", err),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(HistoryChatResponse{
+		Success: true,
+		Data:    messages,
+	})
+}
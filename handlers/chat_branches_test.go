@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/knowledge"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stakwork/sphinx-tribes/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newBranchRequest(method, target, body string, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for key, value := range params {
+		rctx.URLParams.Add(key, value)
+	}
+	ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, auth.ContextKey, "test-pubkey")
+	return httptest.NewRequest(method, target, strings.NewReader(body)).WithContext(ctx)
+}
+
+// newBranchDispatchHandler returns a chat handler whose Stakwork requests
+// are captured into vars.
+func newBranchDispatchHandler(t *testing.T, vars *map[string]interface{}) (*ChatHandler, *datamocks.Database) {
+	originalKey := os.Getenv("SWWFKEY")
+	os.Setenv("SWWFKEY", "test-key")
+	t.Cleanup(func() { os.Setenv("SWWFKEY", originalKey) })
+
+	websocket.WebsocketPool = &websocket.Pool{
+		Clients: make(map[string]*websocket.ClientData),
+	}
+
+	client := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			var payload StakworkChatPayload
+			json.NewDecoder(req.Body).Decode(&payload)
+			setVar := payload.WorkflowParams["set_var"].(map[string]interface{})
+			attributes := setVar["attributes"].(map[string]interface{})
+			*vars = attributes["vars"].(map[string]interface{})
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"success": true, "data": {"project_id": 1}}`)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	mockDb := datamocks.NewDatabase(t)
+	mockDb.On("GetPersonByPubkey", "test-pubkey").Return(db.Person{OwnerPubKey: "test-pubkey", OwnerAlias: "alias"})
+	return NewChatHandler(client, mockDb), mockDb
+}
+
+// expectReply expects the streaming assistant reply a dispatch creates on
+// the branch of its prompt. An empty promptID accepts any prompt.
+func expectReply(mockDb *datamocks.Database, promptID, branchID string) {
+	mockDb.On("AddChatMessage", mock.MatchedBy(func(message *db.ChatMessage) bool {
+		return message.Role == db.AssistantRole && message.Status == db.StreamingStatus &&
+			message.ParentID != "" && (promptID == "" || message.ParentID == promptID) && message.BranchID == branchID
+	})).Return(func(message *db.ChatMessage) (db.ChatMessage, error) {
+		return *message, nil
+	}).Once()
+}
+
+func TestEditChatMessage(t *testing.T) {
+	params := map[string]string{"chat_id": "chat-1", "message_id": "user-2"}
+	messages := []db.ChatMessage{
+		{ID: "user-1", ChatID: "chat-1", Role: db.UserRole, Message: "hi", BranchID: db.MainChatBranch},
+		{ID: "reply-1", ChatID: "chat-1", Role: db.AssistantRole, Message: "hello", ParentID: "user-1", BranchID: db.MainChatBranch},
+		{ID: "user-2", ChatID: "chat-1", Role: db.UserRole, Message: "typo", ParentID: "reply-1", BranchID: db.MainChatBranch},
+	}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		ch := NewChatHandler(&http.Client{}, datamocks.NewDatabase(t))
+		rr := httptest.NewRecorder()
+
+		ch.EditChatMessage(rr, httptest.NewRequest(http.MethodPut, "/hivechat/chat-1/messages/user-2", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Message Not Found", func(t *testing.T) {
+		var vars map[string]interface{}
+		ch, mockDb := newBranchDispatchHandler(t, &vars)
+		mockDb.On("GetChatMessagesForChatID", "chat-1").Return(messages, nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.EditChatMessage(rr, newBranchRequest(http.MethodPut, "/", `{"message":"fixed","workspaceUUID":"ws"}`,
+			map[string]string{"chat_id": "chat-1", "message_id": "missing"}))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Assistant Message", func(t *testing.T) {
+		var vars map[string]interface{}
+		ch, mockDb := newBranchDispatchHandler(t, &vars)
+		mockDb.On("GetChatMessagesForChatID", "chat-1").Return(messages, nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.EditChatMessage(rr, newBranchRequest(http.MethodPut, "/", `{"message":"fixed","workspaceUUID":"ws"}`,
+			map[string]string{"chat_id": "chat-1", "message_id": "reply-1"}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Creates Branch And Sends Branch History", func(t *testing.T) {
+		var vars map[string]interface{}
+		ch, mockDb := newBranchDispatchHandler(t, &vars)
+		mockDb.On("GetChatMessagesForChatID", "chat-1").Return(messages, nil).Once()
+		mockDb.On("GetProductBrief", "ws").Return("brief", nil).Once()
+		mockDb.On("CreateChatBranch", "chat-1", "reply-1").Return(db.ChatBranch{ID: "branch-1", ChatID: "chat-1", ParentMessageID: "reply-1"}, nil).Once()
+		mockDb.On("GetChatBranchHistory", "chat-1", "branch-1").Return(messages[:2], nil).Once()
+		mockDb.On("GetArtifactsByMessageID", mock.Anything).Return([]db.Artifact{}, nil).Twice()
+		mockDb.On("AddChatMessage", mock.MatchedBy(func(message *db.ChatMessage) bool {
+			return message.Message == "fixed" && message.ParentID == "reply-1" && message.BranchID == "branch-1"
+		})).Return(func(message *db.ChatMessage) (db.ChatMessage, error) {
+			return *message, nil
+		}).Once()
+		mockDb.On("SetActiveChatBranch", "chat-1", "branch-1").Return(db.Chat{ID: "chat-1", ActiveBranchID: "branch-1"}, nil).Once()
+		expectReply(mockDb, "", "branch-1")
+		mockDb.On("GetCodeGraphByWorkspaceUuid", "ws").Return(db.WorkspaceCodeGraph{}, errors.New("not found")).Once()
+		mockDb.On("GetCodeSpaceMapByWorkspaceAndUser", "ws", "test-pubkey").Return(db.CodeSpaceMap{}, errors.New("not found")).Once()
+		rr := httptest.NewRecorder()
+
+		ch.EditChatMessage(rr, newBranchRequest(http.MethodPut, "/", `{"message":"fixed","workspaceUUID":"ws"}`, params))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "fixed", vars["message"])
+		assert.Equal(t, "branch-1", vars["branchId"])
+		assert.Equal(t, vars["messageId"], vars["parentMessageId"])
+		assert.NotEmpty(t, vars["replyMessageId"])
+		assert.Len(t, vars["history"], 2)
+	})
+
+	t.Run("Sends The Text Of Tagged Files", func(t *testing.T) {
+		var vars map[string]interface{}
+		ch, mockDb := newBranchDispatchHandler(t, &vars)
+		mockDb.On("GetChatMessagesForChatID", "chat-1").Return(messages, nil).Once()
+		mockDb.On("GetProductBrief", "ws").Return("brief", nil).Once()
+		mockDb.On("CreateChatBranch", "chat-1", "reply-1").Return(db.ChatBranch{ID: "branch-1", ChatID: "chat-1", ParentMessageID: "reply-1"}, nil).Once()
+		mockDb.On("GetChatBranchHistory", "chat-1", "branch-1").Return(messages[:2], nil).Once()
+		mockDb.On("GetArtifactsByMessageID", mock.Anything).Return([]db.Artifact{}, nil).Twice()
+		mockDb.On("AddChatMessage", mock.MatchedBy(func(message *db.ChatMessage) bool {
+			return len(message.ContextTags) == 1 && message.ContextTags[0].Type == db.FileContext
+		})).Return(func(message *db.ChatMessage) (db.ChatMessage, error) {
+			return *message, nil
+		}).Once()
+		mockDb.On("SetActiveChatBranch", "chat-1", "branch-1").Return(db.Chat{ID: "chat-1", ActiveBranchID: "branch-1"}, nil).Once()
+		expectReply(mockDb, "", "branch-1")
+		mockDb.On("GetCodeGraphByWorkspaceUuid", "ws").Return(db.WorkspaceCodeGraph{}, errors.New("not found")).Once()
+		mockDb.On("GetCodeSpaceMapByWorkspaceAndUser", "ws", "test-pubkey").Return(db.CodeSpaceMap{}, errors.New("not found")).Once()
+		mockDb.On("GetFileAssetByID", uint(5)).Return(&db.FileAsset{ID: 5, WorkspaceID: "ws", OriginFilename: "spec.pdf"}, nil).Once()
+		mockDb.On("GetFileChunks", uint(5)).Return([]db.FileChunk{{FileID: 5, Content: "the spec"}}, nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.EditChatMessage(rr, newBranchRequest(http.MethodPut, "/",
+			`{"message":"fixed","workspaceUUID":"ws","contextTags":[{"type":"file","id":"5"}]}`, params))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		files := vars["fileContext"].([]interface{})
+		assert.Len(t, files, 1)
+		assert.Equal(t, "the spec", files[0].(map[string]interface{})["content"])
+	})
+
+	t.Run("Sends Workspace Knowledge", func(t *testing.T) {
+		var vars map[string]interface{}
+		ch, mockDb := newBranchDispatchHandler(t, &vars)
+		ch.knowledge = knowledge.New(mockDb, config.Defaults().Knowledge, nil)
+		items := []db.KnowledgeItem{
+			{ID: 3, WorkspaceUUID: "ws", SourceType: db.KnowledgeSnippet, SourceID: "1", Title: "Tone", Content: "Be brief"},
+			{ID: 4, WorkspaceUUID: "ws", SourceType: db.KnowledgeFeatureBrief, SourceID: "f-1", Title: "Payments brief", Content: "Fixed invoices are paid over lightning"},
+		}
+		mockDb.On("GetChatMessagesForChatID", "chat-1").Return(messages, nil).Once()
+		mockDb.On("GetProductBrief", "ws").Return("brief", nil).Once()
+		mockDb.On("CreateChatBranch", "chat-1", "reply-1").Return(db.ChatBranch{ID: "branch-1", ChatID: "chat-1", ParentMessageID: "reply-1"}, nil).Once()
+		mockDb.On("GetChatBranchHistory", "chat-1", "branch-1").Return(messages[:2], nil).Once()
+		mockDb.On("GetArtifactsByMessageID", mock.Anything).Return([]db.Artifact{}, nil).Twice()
+		mockDb.On("AddChatMessage", mock.Anything).Return(func(message *db.ChatMessage) (db.ChatMessage, error) {
+			return *message, nil
+		}).Once()
+		mockDb.On("SetActiveChatBranch", "chat-1", "branch-1").Return(db.Chat{ID: "chat-1", ActiveBranchID: "branch-1"}, nil).Once()
+		expectReply(mockDb, "", "branch-1")
+		mockDb.On("GetCodeGraphByWorkspaceUuid", "ws").Return(db.WorkspaceCodeGraph{}, errors.New("not found")).Once()
+		mockDb.On("GetCodeSpaceMapByWorkspaceAndUser", "ws", "test-pubkey").Return(db.CodeSpaceMap{}, errors.New("not found")).Once()
+		mockDb.On("GetKnowledgeItemByID", uint(3)).Return(&items[0], nil).Once()
+		mockDb.On("GetKnowledgeSources", "ws").Return(items, nil).Once()
+		mockDb.On("SyncKnowledgeItems", "ws", items).Return(nil).Once()
+		mockDb.On("GetKnowledgeItems", "ws").Return(items, nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.EditChatMessage(rr, newBranchRequest(http.MethodPut, "/",
+			`{"message":"fixed invoices","workspaceUUID":"ws","contextTags":[{"type":"knowledge","id":"3"}]}`, params))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		knowledgeItems := vars["knowledgeContext"].([]interface{})
+		require.Len(t, knowledgeItems, 2)
+		assert.Equal(t, "Be brief", knowledgeItems[0].(map[string]interface{})["content"])
+		assert.Equal(t, true, knowledgeItems[0].(map[string]interface{})["pinned"])
+		assert.Equal(t, "featureBrief", knowledgeItems[1].(map[string]interface{})["sourceType"])
+	})
+}
+
+func TestRegenerateChatMessage(t *testing.T) {
+	messages := []db.ChatMessage{
+		{ID: "user-1", ChatID: "chat-1", Role: db.UserRole, Message: "hi", BranchID: db.MainChatBranch},
+		{ID: "reply-1", ChatID: "chat-1", Role: db.AssistantRole, Message: "hello", ParentID: "user-1", BranchID: db.MainChatBranch},
+		{ID: "user-2", ChatID: "chat-1", Role: db.UserRole, Message: "explain", ParentID: "reply-1", BranchID: db.MainChatBranch},
+		{ID: "reply-2", ChatID: "chat-1", Role: db.AssistantRole, Message: "meh", ParentID: "user-2", BranchID: db.MainChatBranch},
+	}
+
+	t.Run("User Message", func(t *testing.T) {
+		var vars map[string]interface{}
+		ch, mockDb := newBranchDispatchHandler(t, &vars)
+		mockDb.On("GetChatMessagesForChatID", "chat-1").Return(messages, nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.RegenerateChatMessage(rr, newBranchRequest(http.MethodPost, "/", `{"workspaceUUID":"ws"}`,
+			map[string]string{"chat_id": "chat-1", "message_id": "user-2"}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Resends Prompt On New Branch", func(t *testing.T) {
+		var vars map[string]interface{}
+		ch, mockDb := newBranchDispatchHandler(t, &vars)
+		mockDb.On("GetChatMessagesForChatID", "chat-1").Return(messages, nil).Twice()
+		mockDb.On("GetProductBrief", "ws").Return("brief", nil).Once()
+		mockDb.On("CreateChatBranch", "chat-1", "user-2").Return(db.ChatBranch{ID: "branch-1", ChatID: "chat-1", ParentMessageID: "user-2"}, nil).Once()
+		mockDb.On("GetChatBranchHistory", "chat-1", "branch-1").Return(messages[:3], nil).Once()
+		mockDb.On("GetArtifactsByMessageID", mock.Anything).Return([]db.Artifact{}, nil).Twice()
+		mockDb.On("SetActiveChatBranch", "chat-1", "branch-1").Return(db.Chat{ID: "chat-1", ActiveBranchID: "branch-1"}, nil).Once()
+		expectReply(mockDb, "", "branch-1")
+		mockDb.On("GetCodeGraphByWorkspaceUuid", "ws").Return(db.WorkspaceCodeGraph{}, errors.New("not found")).Once()
+		mockDb.On("GetCodeSpaceMapByWorkspaceAndUser", "ws", "test-pubkey").Return(db.CodeSpaceMap{}, errors.New("not found")).Once()
+		rr := httptest.NewRecorder()
+
+		ch.RegenerateChatMessage(rr, newBranchRequest(http.MethodPost, "/", `{"workspaceUUID":"ws"}`,
+			map[string]string{"chat_id": "chat-1", "message_id": "reply-2"}))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "explain", vars["message"])
+		assert.Equal(t, "user-2", vars["messageId"])
+		assert.Equal(t, "branch-1", vars["branchId"])
+		assert.Equal(t, "user-2", vars["parentMessageId"])
+		assert.Len(t, vars["history"], 2)
+	})
+}
+
+func TestGetChatBranches(t *testing.T) {
+	t.Run("Lists Branches", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1", ActiveBranchID: "branch-1"}, nil).Once()
+		mockDb.On("GetChatBranches", "chat-1").Return([]db.ChatBranch{{ID: "branch-1", ChatID: "chat-1", ParentMessageID: "user-1"}}, nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.GetChatBranches(rr, newBranchRequest(http.MethodGet, "/", "", map[string]string{"chat_id": "chat-1"}))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"activeBranchId":"branch-1"`)
+		assert.Contains(t, rr.Body.String(), `"parentMessageId":"user-1"`)
+	})
+
+	t.Run("Chat Not Found", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{}, errors.New("chat not found")).Once()
+		rr := httptest.NewRecorder()
+
+		ch.GetChatBranches(rr, newBranchRequest(http.MethodGet, "/", "", map[string]string{"chat_id": "chat-1"}))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestSwitchChatBranch(t *testing.T) {
+	params := map[string]string{"chat_id": "chat-1"}
+
+	t.Run("Missing Branch", func(t *testing.T) {
+		ch := NewChatHandler(&http.Client{}, datamocks.NewDatabase(t))
+		rr := httptest.NewRecorder()
+
+		ch.SwitchChatBranch(rr, newBranchRequest(http.MethodPut, "/", `{}`, params))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unknown Branch", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("SetActiveChatBranch", "chat-1", "nope").Return(db.Chat{}, errors.New("branch not found")).Once()
+		rr := httptest.NewRecorder()
+
+		ch.SwitchChatBranch(rr, newBranchRequest(http.MethodPut, "/", `{"branchId":"nope"}`, params))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Returns Branch History", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("SetActiveChatBranch", "chat-1", db.MainChatBranch).Return(db.Chat{ID: "chat-1", ActiveBranchID: db.MainChatBranch}, nil).Once()
+		mockDb.On("GetChatBranchHistory", "chat-1", db.MainChatBranch).Return([]db.ChatMessage{
+			{ID: "user-1", ChatID: "chat-1", Message: "hi", BranchID: db.MainChatBranch},
+		}, nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.SwitchChatBranch(rr, newBranchRequest(http.MethodPut, "/", `{"branchId":"main"}`, params))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"id":"user-1"`)
+	})
+}
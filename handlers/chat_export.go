@@ -0,0 +1,515 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/rs/xid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+const (
+	chatExportVersion = 1
+	maxChatImportSize = 20 << 20
+)
+
+// ChatExportBundle is a chat with everything needed to read it elsewhere or
+// import it into another workspace.
+type ChatExportBundle struct {
+	Version    int              `json:"version"`
+	ExportedAt time.Time        `json:"exportedAt"`
+	Chat       db.Chat          `json:"chat"`
+	Branches   []db.ChatBranch  `json:"branches"`
+	Messages   []db.ChatMessage `json:"messages"`
+	Artifacts  []db.Artifact    `json:"artifacts"`
+	Files      []db.FileAsset   `json:"files"`
+}
+
+func (ch *ChatHandler) buildChatExport(chatID string) (ChatExportBundle, error) {
+	chat, err := ch.db.GetChatByChatID(chatID)
+	if err != nil {
+		return ChatExportBundle{}, err
+	}
+
+	messages, err := ch.db.GetChatMessagesForChatID(chatID)
+	if err != nil {
+		return ChatExportBundle{}, err
+	}
+
+	branches, err := ch.db.GetChatBranches(chatID)
+	if err != nil {
+		return ChatExportBundle{}, err
+	}
+
+	artifacts, err := ch.db.GetAllArtifactsByChatID(chatID)
+	if err != nil {
+		return ChatExportBundle{}, err
+	}
+	sort.SliceStable(artifacts, func(i, j int) bool {
+		return artifacts[i].CreatedAt.Before(artifacts[j].CreatedAt)
+	})
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, message := range messages {
+		if message.PDFURL != "" && !seen[message.PDFURL] {
+			seen[message.PDFURL] = true
+			paths = append(paths, message.PDFURL)
+		}
+	}
+	files, err := ch.db.GetFileAssetsByStoragePaths(paths)
+	if err != nil {
+		return ChatExportBundle{}, err
+	}
+
+	if chat.ActiveBranchID == "" {
+		chat.ActiveBranchID = db.MainChatBranch
+	}
+
+	return ChatExportBundle{
+		Version:    chatExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Chat:       chat,
+		Branches:   branches,
+		Messages:   messages,
+		Artifacts:  artifacts,
+		Files:      files,
+	}, nil
+}
+
+// ExportChat exports a chat as a Markdown document or a JSON bundle
+//
+//	@Summary		Export a chat
+//	@Description	Export a chat with its messages, branches, context tags, artifacts and attached file metadata. The JSON bundle can be imported into another workspace.
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Produce		text/markdown
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id	path		string	true	"Chat ID"
+//	@Param			format	query		string	false	"md or json, json by default"
+//	@Success		200		{object}	ChatExportBundle
+//	@Failure		400		{object}	ChatResponse
+//	@Failure		404		{object}	ChatResponse
+//	@Failure		500		{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/export [get]
+func (ch *ChatHandler) ExportChat(w http.ResponseWriter, r *http.Request) {
+	chatID := chi.URLParam(r, "chat_id")
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "md" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "format must be md or json",
+		})
+		return
+	}
+
+	bundle, err := ch.buildChatExport(chatID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "chat not found" {
+			status = http.StatusNotFound
+		}
+		logger.Log.Error("[chat export] failed to export chat %s: %v", chatID, err)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to export chat: %v", err),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"chat-%s.%s\"", chatID, format))
+	if format == "md" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(renderChatMarkdown(bundle)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(bundle)
+}
+
+// ImportChat recreates an exported chat in a workspace
+//
+//	@Summary		Import a chat
+//	@Description	Recreate a chat from a JSON export bundle in the given workspace. Messages, branches and artifacts get new ids; their order, branches and artifact links are kept.
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_id	query		string				true	"Workspace to import into"
+//	@Param			bundle			body		ChatExportBundle	true	"Exported chat"
+//	@Success		200				{object}	ChatResponse
+//	@Failure		400				{object}	ChatResponse
+//	@Failure		404				{object}	ChatResponse
+//	@Failure		500				{object}	ChatResponse
+//	@Router			/hivechat/import [post]
+func (ch *ChatHandler) ImportChat(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.URL.Query().Get("workspace_id")
+	if workspaceID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "workspace_id query parameter is required",
+		})
+		return
+	}
+
+	var bundle ChatExportBundle
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxChatImportSize)).Decode(&bundle); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Invalid chat bundle",
+		})
+		return
+	}
+
+	if bundle.Version != chatExportVersion {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Unsupported bundle version %d", bundle.Version),
+		})
+		return
+	}
+
+	workspace := ch.db.GetWorkspaceByUuid(workspaceID)
+	if workspace.Uuid != workspaceID {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Workspace not found",
+		})
+		return
+	}
+
+	chat, branches, messages, artifacts, err := remapChatBundle(bundle, workspaceID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid chat bundle: %v", err),
+		})
+		return
+	}
+
+	chat.OwnerPubKey, _ = r.Context().Value(auth.ContextKey).(string)
+	imported, err := ch.db.ImportChat(chat, branches, messages, artifacts)
+	if err != nil {
+		logger.Log.Error("[chat import] failed to import chat into workspace %s: %v", workspaceID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to import chat: %v", err),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Message: fmt.Sprintf("Imported %d messages", len(messages)),
+		Data:    imported,
+	})
+}
+
+// remapChatBundle gives the chat, its branches, messages and artifacts new
+// ids for the target workspace and rewrites the links between them. Links to
+// anything outside the bundle are rejected.
+func remapChatBundle(bundle ChatExportBundle, workspaceID string) (db.Chat, []db.ChatBranch, []db.ChatMessage, []db.Artifact, error) {
+	chatID := uuid.New().String()
+
+	branchIDs := map[string]string{db.MainChatBranch: db.MainChatBranch}
+	for _, branch := range bundle.Branches {
+		branchIDs[branch.ID] = uuid.New().String()
+	}
+
+	messageIDs := make(map[string]string, len(bundle.Messages))
+	for _, message := range bundle.Messages {
+		if message.ID == "" {
+			return db.Chat{}, nil, nil, nil, fmt.Errorf("message without id")
+		}
+		if _, exists := messageIDs[message.ID]; exists {
+			return db.Chat{}, nil, nil, nil, fmt.Errorf("duplicate message %s", message.ID)
+		}
+		messageIDs[message.ID] = xid.New().String()
+	}
+
+	branches := make([]db.ChatBranch, 0, len(bundle.Branches))
+	for _, branch := range bundle.Branches {
+		parentID := ""
+		if branch.ParentMessageID != "" {
+			var ok bool
+			if parentID, ok = messageIDs[branch.ParentMessageID]; !ok {
+				return db.Chat{}, nil, nil, nil, fmt.Errorf("branch %s continues from unknown message %s", branch.ID, branch.ParentMessageID)
+			}
+		}
+		branches = append(branches, db.ChatBranch{
+			ID:              branchIDs[branch.ID],
+			ChatID:          chatID,
+			ParentMessageID: parentID,
+			CreatedAt:       branch.CreatedAt,
+		})
+	}
+
+	messages := make([]db.ChatMessage, 0, len(bundle.Messages))
+	for _, message := range bundle.Messages {
+		imported := message
+		imported.ID = messageIDs[message.ID]
+		imported.ChatID = chatID
+		imported.StreamSequence = 0
+
+		if message.BranchID == "" {
+			imported.BranchID = db.MainChatBranch
+		} else if branchID, ok := branchIDs[message.BranchID]; ok {
+			imported.BranchID = branchID
+		} else {
+			return db.Chat{}, nil, nil, nil, fmt.Errorf("message %s is on unknown branch %s", message.ID, message.BranchID)
+		}
+
+		if message.ParentID != "" {
+			parentID, ok := messageIDs[message.ParentID]
+			if !ok {
+				return db.Chat{}, nil, nil, nil, fmt.Errorf("message %s follows unknown message %s", message.ID, message.ParentID)
+			}
+			imported.ParentID = parentID
+		}
+		if imported.Status == db.StreamingStatus || imported.Status == db.SendingStatus {
+			imported.Status = db.ErrorStatus
+		}
+
+		messages = append(messages, imported)
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Timestamp.Before(messages[j].Timestamp)
+	})
+
+	artifacts := make([]db.Artifact, 0, len(bundle.Artifacts))
+	for _, artifact := range bundle.Artifacts {
+		messageID, ok := messageIDs[artifact.MessageID]
+		if !ok {
+			return db.Chat{}, nil, nil, nil, fmt.Errorf("artifact %s belongs to unknown message %s", artifact.ID, artifact.MessageID)
+		}
+		switch artifact.Type {
+		case db.TextArtifact, db.VisualArtifact, db.ActionArtifact, db.SSEArtifact:
+		default:
+			return db.Chat{}, nil, nil, nil, fmt.Errorf("invalid artifact type: %s", artifact.Type)
+		}
+
+		imported := artifact
+		imported.ID = uuid.New()
+		imported.MessageID = messageID
+		if imported.Content == nil {
+			imported.Content = db.PropertyMap{}
+		}
+		artifacts = append(artifacts, imported)
+	}
+
+	activeBranchID, ok := branchIDs[bundle.Chat.ActiveBranchID]
+	if !ok {
+		activeBranchID = db.MainChatBranch
+	}
+
+	chat := db.Chat{
+		ID:             chatID,
+		WorkspaceID:    workspaceID,
+		Title:          bundle.Chat.Title,
+		Status:         db.ActiveStatus,
+		ActiveBranchID: activeBranchID,
+		CreatedAt:      bundle.Chat.CreatedAt,
+		Visibility:     db.WorkspaceChat,
+	}
+	if bundle.Chat.Visibility == db.PrivateChat {
+		chat.Visibility = db.PrivateChat
+	}
+
+	return chat, branches, messages, artifacts, nil
+}
+
+// renderChatMarkdown writes the active branch of a chat as a conversation,
+// followed by the other branches and the attached files.
+func renderChatMarkdown(bundle ChatExportBundle) string {
+	var b strings.Builder
+
+	title := bundle.Chat.Title
+	if title == "" {
+		title = "Chat " + bundle.Chat.ID
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "_Exported %s from workspace %s_\n\n", bundle.ExportedAt.Format(time.RFC3339), bundle.Chat.WorkspaceID)
+
+	byID := make(map[string]db.ChatMessage, len(bundle.Messages))
+	for _, message := range bundle.Messages {
+		byID[message.ID] = message
+	}
+	artifacts := make(map[string][]db.Artifact)
+	for _, artifact := range bundle.Artifacts {
+		artifacts[artifact.MessageID] = append(artifacts[artifact.MessageID], artifact)
+	}
+
+	active := activeChatPath(bundle.Messages, byID, bundle.Chat.ActiveBranchID, bundle.Branches)
+	onActive := make(map[string]bool, len(active))
+	for _, message := range active {
+		onActive[message.ID] = true
+		writeMarkdownMessage(&b, message, artifacts[message.ID])
+	}
+
+	branchIDs := []string{db.MainChatBranch}
+	parents := map[string]string{}
+	for _, branch := range bundle.Branches {
+		branchIDs = append(branchIDs, branch.ID)
+		parents[branch.ID] = branch.ParentMessageID
+	}
+	for _, branchID := range branchIDs {
+		var rest []db.ChatMessage
+		for _, message := range bundle.Messages {
+			if !onActive[message.ID] && (message.BranchID == branchID || (branchID == db.MainChatBranch && message.BranchID == "")) {
+				rest = append(rest, message)
+			}
+		}
+		if len(rest) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "## Branch %s\n\n", branchID)
+		if parentID := parents[branchID]; parentID != "" {
+			fmt.Fprintf(&b, "_Continues from message %s_\n\n", parentID)
+		}
+		for _, message := range rest {
+			writeMarkdownMessage(&b, message, artifacts[message.ID])
+		}
+	}
+
+	if len(bundle.Files) > 0 {
+		b.WriteString("## Files\n\n")
+		for _, file := range bundle.Files {
+			fmt.Fprintf(&b, "- [%s](%s) %s, %d bytes\n", file.OriginFilename, file.StoragePath, file.MimeType, file.FileSize)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// activeChatPath follows parent links back from the end of the active branch.
+func activeChatPath(messages []db.ChatMessage, byID map[string]db.ChatMessage, activeBranchID string, branches []db.ChatBranch) []db.ChatMessage {
+	if activeBranchID == "" {
+		activeBranchID = db.MainChatBranch
+	}
+
+	leafID := ""
+	for _, message := range messages {
+		branchID := message.BranchID
+		if branchID == "" {
+			branchID = db.MainChatBranch
+		}
+		if branchID == activeBranchID {
+			leafID = message.ID
+		}
+	}
+	if leafID == "" {
+		for _, branch := range branches {
+			if branch.ID == activeBranchID {
+				leafID = branch.ParentMessageID
+			}
+		}
+	}
+
+	var path []db.ChatMessage
+	seen := make(map[string]bool)
+	for id := leafID; id != "" && !seen[id]; {
+		message, ok := byID[id]
+		if !ok {
+			break
+		}
+		seen[id] = true
+		path = append([]db.ChatMessage{message}, path...)
+		id = message.ParentID
+	}
+	return path
+}
+
+func writeMarkdownMessage(b *strings.Builder, message db.ChatMessage, artifacts []db.Artifact) {
+	role := "User"
+	if message.Role == db.AssistantRole {
+		role = "Assistant"
+	}
+	fmt.Fprintf(b, "### %s · %s\n\n", role, message.Timestamp.UTC().Format("2006-01-02 15:04 MST"))
+
+	if message.Message != "" {
+		b.WriteString(message.Message)
+		b.WriteString("\n\n")
+	}
+	if message.PDFURL != "" {
+		fmt.Fprintf(b, "Attachment: <%s>\n\n", message.PDFURL)
+	}
+	if len(message.ContextTags) > 0 {
+		tags := make([]string, len(message.ContextTags))
+		for i, tag := range message.ContextTags {
+			tags[i] = fmt.Sprintf("%s:%s", tag.Type, tag.ID)
+		}
+		fmt.Fprintf(b, "Context: %s\n\n", strings.Join(tags, ", "))
+	}
+
+	for _, artifact := range artifacts {
+		writeMarkdownArtifact(b, artifact)
+	}
+}
+
+func writeMarkdownArtifact(b *strings.Builder, artifact db.Artifact) {
+	content := artifact.Content
+	text := func(key string) string {
+		value, _ := content[key].(string)
+		return value
+	}
+
+	switch artifact.Type {
+	case db.TextArtifact:
+		if text("text_type") == "code" {
+			fmt.Fprintf(b, "```\n%s\n```\n\n", text("content"))
+		} else {
+			fmt.Fprintf(b, "%s\n\n", text("content"))
+		}
+	case db.VisualArtifact:
+		if url := text("url"); url != "" {
+			fmt.Fprintf(b, "![visual](%s)\n\n", url)
+		}
+		if examples, ok := content["examples"].([]interface{}); ok {
+			for _, example := range examples {
+				if example, ok := example.(map[string]interface{}); ok {
+					fmt.Fprintf(b, "- %v: %v\n", example["type"], example["url"])
+				}
+			}
+			b.WriteString("\n")
+		}
+	case db.ActionArtifact:
+		fmt.Fprintf(b, "**Action:** %s\n\n", text("action_text"))
+		if options, ok := content["options"].([]interface{}); ok {
+			for _, option := range options {
+				if option, ok := option.(map[string]interface{}); ok {
+					fmt.Fprintf(b, "- %v\n", option["option_label"])
+				}
+			}
+			b.WriteString("\n")
+		}
+	default:
+		encoded, _ := json.MarshalIndent(content, "", "  ")
+		fmt.Fprintf(b, "%s artifact:\n\n```json\n%s\n```\n\n", artifact.Type, encoded)
+	}
+}
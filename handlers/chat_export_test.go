@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestChatBundle() ChatExportBundle {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return ChatExportBundle{
+		Version: chatExportVersion,
+		Chat:    db.Chat{ID: "chat-1", WorkspaceID: "ws-1", Title: "Planning", ActiveBranchID: "branch-1"},
+		Branches: []db.ChatBranch{
+			{ID: "branch-1", ChatID: "chat-1", ParentMessageID: "user-1"},
+		},
+		Messages: []db.ChatMessage{
+			{ID: "user-1", ChatID: "chat-1", Role: db.UserRole, Message: "Plan the release", BranchID: db.MainChatBranch, Timestamp: start,
+				PDFURL: "https://files.example.com/spec.pdf", ContextTags: []db.ContextTag{{Type: db.ProductBriefContext, ID: "brief-1"}}},
+			{ID: "reply-1", ChatID: "chat-1", Role: db.AssistantRole, Message: "First plan", ParentID: "user-1", BranchID: db.MainChatBranch, Timestamp: start.Add(time.Minute)},
+			{ID: "reply-2", ChatID: "chat-1", Role: db.AssistantRole, Message: "Second plan", ParentID: "user-1", BranchID: "branch-1", Timestamp: start.Add(2 * time.Minute)},
+		},
+		Artifacts: []db.Artifact{
+			{ID: uuid.New(), MessageID: "reply-2", Type: db.TextArtifact, Content: db.PropertyMap{"text_type": "code", "content": "make release"}},
+			{ID: uuid.New(), MessageID: "reply-2", Type: db.ActionArtifact, Content: db.PropertyMap{
+				"action_text": "Ship it?",
+				"options":     []interface{}{map[string]interface{}{"option_label": "Yes"}},
+			}},
+		},
+		Files: []db.FileAsset{
+			{ID: 1, OriginFilename: "spec.pdf", StoragePath: "https://files.example.com/spec.pdf", MimeType: "application/pdf", FileSize: 42},
+		},
+	}
+}
+
+func TestExportChat(t *testing.T) {
+	params := map[string]string{"chat_id": "chat-1"}
+
+	expectExport := func(mockDb *datamocks.Database, bundle ChatExportBundle) {
+		mockDb.On("GetChatByChatID", "chat-1").Return(bundle.Chat, nil).Once()
+		mockDb.On("GetChatMessagesForChatID", "chat-1").Return(bundle.Messages, nil).Once()
+		mockDb.On("GetChatBranches", "chat-1").Return(bundle.Branches, nil).Once()
+		mockDb.On("GetAllArtifactsByChatID", "chat-1").Return(bundle.Artifacts, nil).Once()
+		mockDb.On("GetFileAssetsByStoragePaths", []string{"https://files.example.com/spec.pdf"}).Return(bundle.Files, nil).Once()
+	}
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		ch := NewChatHandler(&http.Client{}, datamocks.NewDatabase(t))
+		rr := httptest.NewRecorder()
+
+		ch.ExportChat(rr, newBranchRequest(http.MethodGet, "/hivechat/chat-1/export?format=pdf", "", params))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Chat Not Found", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{}, errors.New("chat not found")).Once()
+		rr := httptest.NewRecorder()
+
+		ch.ExportChat(rr, newBranchRequest(http.MethodGet, "/hivechat/chat-1/export", "", params))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("JSON Bundle", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		expectExport(mockDb, newTestChatBundle())
+		rr := httptest.NewRecorder()
+
+		ch.ExportChat(rr, newBranchRequest(http.MethodGet, "/hivechat/chat-1/export?format=json", "", params))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "chat-chat-1.json")
+		var bundle ChatExportBundle
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &bundle))
+		assert.Equal(t, chatExportVersion, bundle.Version)
+		assert.Len(t, bundle.Messages, 3)
+		assert.Len(t, bundle.Artifacts, 2)
+		assert.Len(t, bundle.Files, 1)
+		assert.Equal(t, "brief-1", bundle.Messages[0].ContextTags[0].ID)
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		expectExport(mockDb, newTestChatBundle())
+		rr := httptest.NewRecorder()
+
+		ch.ExportChat(rr, newBranchRequest(http.MethodGet, "/hivechat/chat-1/export?format=md", "", params))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/markdown; charset=utf-8", rr.Header().Get("Content-Type"))
+		body := rr.Body.String()
+		assert.Contains(t, body, "# Planning\n")
+		assert.Contains(t, body, "### User · 2024-05-01 10:00 UTC\n\nPlan the release")
+		assert.Contains(t, body, "Context: productBrief:brief-1")
+		assert.Contains(t, body, "```\nmake release\n```")
+		assert.Contains(t, body, "**Action:** Ship it?\n\n- Yes")
+		assert.Contains(t, body, "## Branch main\n\n### Assistant · 2024-05-01 10:01 UTC\n\nFirst plan")
+		assert.Contains(t, body, "- [spec.pdf](https://files.example.com/spec.pdf)")
+		assert.Less(t, strings.Index(body, "Second plan"), strings.Index(body, "## Branch main"))
+	})
+}
+
+func TestImportChat(t *testing.T) {
+	post := func(ch *ChatHandler, target string, bundle interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(bundle)
+		rr := httptest.NewRecorder()
+		ch.ImportChat(rr, newBranchRequest(http.MethodPost, target, string(body), nil))
+		return rr
+	}
+
+	t.Run("Missing Workspace", func(t *testing.T) {
+		ch := NewChatHandler(&http.Client{}, datamocks.NewDatabase(t))
+
+		rr := post(ch, "/hivechat/import", newTestChatBundle())
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unsupported Version", func(t *testing.T) {
+		ch := NewChatHandler(&http.Client{}, datamocks.NewDatabase(t))
+		bundle := newTestChatBundle()
+		bundle.Version = 99
+
+		rr := post(ch, "/hivechat/import?workspace_id=ws-2", bundle)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unknown Workspace", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetWorkspaceByUuid", "ws-2").Return(db.Workspace{}).Once()
+
+		rr := post(ch, "/hivechat/import?workspace_id=ws-2", newTestChatBundle())
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Dangling Link", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetWorkspaceByUuid", "ws-2").Return(db.Workspace{Uuid: "ws-2"}).Once()
+		bundle := newTestChatBundle()
+		bundle.Artifacts[0].MessageID = "missing"
+
+		rr := post(ch, "/hivechat/import?workspace_id=ws-2", bundle)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "unknown message missing")
+	})
+
+	t.Run("Recreates Chat With New IDs", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetWorkspaceByUuid", "ws-2").Return(db.Workspace{Uuid: "ws-2"}).Once()
+
+		var imported []db.ChatMessage
+		var importedBranches []db.ChatBranch
+		var importedArtifacts []db.Artifact
+		mockDb.On("ImportChat", mock.MatchedBy(func(chat db.Chat) bool {
+			return chat.WorkspaceID == "ws-2" && chat.ID != "chat-1" && chat.Title == "Planning"
+		}), mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			importedBranches = args.Get(1).([]db.ChatBranch)
+			imported = args.Get(2).([]db.ChatMessage)
+			importedArtifacts = args.Get(3).([]db.Artifact)
+		}).Return(func(chat db.Chat, _ []db.ChatBranch, _ []db.ChatMessage, _ []db.Artifact) (db.Chat, error) {
+			return chat, nil
+		}).Once()
+
+		rr := post(ch, "/hivechat/import?workspace_id=ws-2", newTestChatBundle())
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Len(t, imported, 3)
+		assert.Equal(t, []string{"Plan the release", "First plan", "Second plan"},
+			[]string{imported[0].Message, imported[1].Message, imported[2].Message})
+		assert.NotEqual(t, "user-1", imported[0].ID)
+		assert.Equal(t, imported[0].ID, imported[1].ParentID)
+		assert.Equal(t, imported[0].ID, imported[2].ParentID)
+		assert.Equal(t, importedBranches[0].ID, imported[2].BranchID)
+		assert.Equal(t, imported[0].ID, importedBranches[0].ParentMessageID)
+		assert.Equal(t, imported[2].ID, importedArtifacts[0].MessageID)
+		assert.Equal(t, imported[0].ChatID, importedBranches[0].ChatID)
+	})
+}
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/websocket"
+)
+
+const chatStreamBatchSize = 100
+
+// StreamChat streams chat events to the browser
+//
+//	@Summary		Stream chat events
+//	@Description	Server-sent events stream of new messages, workflow status updates and artifacts of a chat. Each event carries its id; reconnecting with Last-Event-ID (or the lastEventId query parameter) replays everything after it.
+//	@Tags			Hive Chat
+//	@Produce		text/event-stream
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id			path	string	true	"Chat ID"
+//	@Param			Last-Event-ID	header	string	false	"Last event id received"
+//	@Param			lastEventId		query	string	false	"Last event id received, for clients that cannot set headers"
+//	@Success		200
+//	@Failure		400	{object}	ChatResponse
+//	@Failure		401	{object}	ChatResponse
+//	@Failure		404	{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/stream [get]
+func (ch *ChatHandler) StreamChat(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Unauthorized",
+		})
+		return
+	}
+
+	chatID := chi.URLParam(r, "chat_id")
+	if chatID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat ID is required",
+		})
+		return
+	}
+
+	lastEventID, err := parseLastEventID(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Invalid Last-Event-ID",
+		})
+		return
+	}
+
+	if _, err := ch.db.GetChatByChatID(chatID); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat not found",
+		})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Streaming is not supported",
+		})
+		return
+	}
+
+	// subscribe before the first read so no event falls between the two
+	notify, unsubscribe := ch.streams.Subscribe(chatID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", ch.streamPollInterval.Milliseconds())
+	flusher.Flush()
+
+	poll := time.NewTicker(ch.streamPollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(ch.streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		lastEventID, err = ch.writeChatEvents(w, chatID, lastEventID)
+		if err != nil {
+			logger.Log.Error("[chat stream] chat %s: %v", chatID, err)
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-ctx.Done():
+			return
+		case _, open := <-notify:
+			if !open {
+				return
+			}
+		case <-poll.C:
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeChatEvents writes every event after lastEventID and returns the id of
+// the last one written.
+func (ch *ChatHandler) writeChatEvents(w http.ResponseWriter, chatID string, lastEventID uint64) (uint64, error) {
+	for {
+		events, err := ch.db.GetChatEventsAfter(chatID, lastEventID, chatStreamBatchSize)
+		if err != nil {
+			return lastEventID, err
+		}
+
+		for _, event := range events {
+			data, err := json.Marshal(event.Payload)
+			if err != nil {
+				return lastEventID, fmt.Errorf("failed to encode event %d: %w", event.ID, err)
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return lastEventID, err
+			}
+			lastEventID = event.ID
+		}
+
+		if len(events) < chatStreamBatchSize {
+			return lastEventID, nil
+		}
+	}
+}
+
+func parseLastEventID(r *http.Request) (uint64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// ProcessChatStreamChunk stores a chunk of a streamed assistant reply
+//
+//	@Summary		Process a streamed chat response chunk
+//	@Description	Append a chunk to the assistant reply named by the replyMessageId sent with the workflow and fan it out to connected clients. Chunks are applied in sequence order, early ones wait for the chunks before them. The request with done set finalizes the reply and stores its artifacts once. Chunks that were already applied are acknowledged and ignored.
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		ChatStreamChunkRequest	true	"Response chunk"
+//	@Success		200		{object}	ChatResponse
+//	@Failure		400		{object}	ChatResponse
+//	@Failure		500		{object}	ChatResponse
+//	@Router			/hivechat/response/stream [post]
+func (ch *ChatHandler) ProcessChatStreamChunk(w http.ResponseWriter, r *http.Request) {
+	var request ChatStreamChunkRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	chunk := request.Value
+	if chunk.ChatID == "" || chunk.MessageID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "chatId and messageId are required",
+		})
+		return
+	}
+	if chunk.Sequence < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "sequence must not be negative",
+		})
+		return
+	}
+
+	if chunk.Delta != "" {
+		message, applied, err := ch.db.AppendChatMessageChunk(chunk.ChatID, chunk.MessageID, chunk.Sequence, chunk.Delta)
+		if errors.Is(err, db.ErrNotStreamingReply) {
+			rejectStreamTarget(w)
+			return
+		}
+		if err != nil {
+			logger.Log.Error("[chat stream] failed to append chunk %d of message %s: %v", chunk.Sequence, chunk.MessageID, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ChatResponse{
+				Success: false,
+				Message: "Failed to save response chunk",
+			})
+			return
+		}
+
+		for _, delta := range applied {
+			wsMessage := websocket.TicketMessage{
+				BroadcastType:   "direct",
+				SourceSessionID: chunk.SourceWebsocketID,
+				Message:         delta.Delta,
+				Action:          "message_delta",
+				ChatMessage:     message,
+			}
+			if err := websocket.WebsocketPool.SendTicketMessage(wsMessage); err != nil {
+				logger.Log.Info("[chat stream] failed to send chunk to websocket: %v", err)
+			}
+		}
+	}
+
+	if !chunk.Done {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: true,
+			Message: "Chunk processed successfully",
+		})
+		return
+	}
+
+	ch.completeChatReply(w, chunk.ChatID, chunk.MessageID, chunk.Response, chunk.SourceWebsocketID, chunk.Artifacts)
+}
+
+// completeChatReply finalizes an assistant reply and stores its artifacts.
+// A reply that was already finalized is returned with the artifacts it got
+// then, so a repeated completion saves nothing twice.
+func (ch *ChatHandler) completeChatReply(w http.ResponseWriter, chatID, messageID, response, sourceWebsocketID string, responseArtifacts []ChatMessageArtifact) {
+	message, finalized, err := ch.db.FinalizeChatMessage(chatID, messageID, response)
+	if errors.Is(err, db.ErrNotStreamingReply) {
+		rejectStreamTarget(w)
+		return
+	}
+	if err != nil {
+		logger.Log.Error("[chat stream] failed to finalize message %s: %v", messageID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to finalize response message",
+		})
+		return
+	}
+
+	if !finalized {
+		artifacts, err := ch.db.GetArtifactsByMessageID(message.ID)
+		if err != nil {
+			logger.Log.Error("[chat stream] failed to fetch artifacts of message %s: %v", message.ID, err)
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success:   true,
+			Message:   "Response already processed",
+			Data:      message,
+			Artifacts: artifacts,
+		})
+		return
+	}
+
+	artifacts := ch.saveResponseArtifacts(message.ID, chatID, responseArtifacts)
+
+	wsMessage := websocket.TicketMessage{
+		BroadcastType:   "direct",
+		SourceSessionID: sourceWebsocketID,
+		Message:         "Response received",
+		Action:          "message",
+		ChatMessage:     message,
+		Artifacts:       artifacts,
+	}
+	if err := websocket.WebsocketPool.SendTicketMessage(wsMessage); err != nil {
+		logger.Log.Info("[chat stream] failed to send response to websocket: %v", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success:   true,
+		Message:   "Response processed successfully",
+		Data:      message,
+		Artifacts: artifacts,
+	})
+}
+
+func rejectStreamTarget(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: false,
+		Message: "messageId is not a streaming assistant reply of the chat",
+	})
+}
+
+// streamingReplyTimeout is how long an assistant reply may stay streaming
+// before the workflow answering it is taken to have failed.
+const streamingReplyTimeout = 30 * time.Minute
+
+// ExpireStreamingChatReplies fails assistant replies whose workflow never
+// completed them, so clients stop waiting on them.
+func ExpireStreamingChatReplies() {
+	expired, err := db.DB.ExpireStreamingChatMessages(time.Now().Add(-streamingReplyTimeout))
+	if err != nil {
+		logger.Log.Error("[chat stream] failed to expire streaming replies: %v", err)
+		return
+	}
+	if len(expired) > 0 {
+		logger.Log.Info("[chat stream] expired %d streaming replies", len(expired))
+	}
+}
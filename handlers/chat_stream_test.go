@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stakwork/sphinx-tribes/sse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStreamChat(t *testing.T) {
+	newStreamHandler := func(t *testing.T) (*ChatHandler, *datamocks.Database, *sse.Broker) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		ch.streams = sse.NewBroker()
+		ch.streamPollInterval = time.Hour
+		ch.streamHeartbeat = time.Hour
+		return ch, mockDb, ch.streams
+	}
+
+	newStreamRequest := func(ctx context.Context, pubKey string) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("chat_id", "chat-1")
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		if pubKey != "" {
+			ctx = context.WithValue(ctx, auth.ContextKey, pubKey)
+		}
+		return httptest.NewRequest(http.MethodGet, "/hivechat/chat-1/stream", nil).WithContext(ctx)
+	}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		ch, _, _ := newStreamHandler(t)
+		rr := httptest.NewRecorder()
+
+		ch.StreamChat(rr, newStreamRequest(context.Background(), ""))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Invalid Last Event ID", func(t *testing.T) {
+		ch, _, _ := newStreamHandler(t)
+		rr := httptest.NewRecorder()
+		req := newStreamRequest(context.Background(), "pubkey")
+		req.Header.Set("Last-Event-ID", "abc")
+
+		ch.StreamChat(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Chat Not Found", func(t *testing.T) {
+		ch, mockDb, _ := newStreamHandler(t)
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{}, errors.New("chat not found")).Once()
+		rr := httptest.NewRecorder()
+
+		ch.StreamChat(rr, newStreamRequest(context.Background(), "pubkey"))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Replays Events After Last Event ID", func(t *testing.T) {
+		ch, mockDb, _ := newStreamHandler(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1"}, nil).Once()
+		mockDb.On("GetChatEventsAfter", "chat-1", uint64(5), chatStreamBatchSize).Return([]db.ChatEvent{
+			{ID: 6, ChatID: "chat-1", Type: db.ChatMessageEvent, Payload: db.PropertyMap{"message": "hello"}},
+			{ID: 7, ChatID: "chat-1", Type: db.ChatWorkflowStatusEvent, Payload: db.PropertyMap{"status": "success"}},
+		}, nil).Once().Run(func(args mock.Arguments) { cancel() })
+
+		rr := httptest.NewRecorder()
+		req := newStreamRequest(ctx, "pubkey")
+		req.Header.Set("Last-Event-ID", "5")
+
+		ch.StreamChat(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Body.String(), "id: 6\nevent: message\ndata: {\"message\":\"hello\"}\n\n")
+		assert.Contains(t, rr.Body.String(), "id: 7\nevent: workflow_status\ndata: {\"status\":\"success\"}\n\n")
+	})
+
+	t.Run("Delivers Events Recorded While Connected", func(t *testing.T) {
+		ch, mockDb, broker := newStreamHandler(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1"}, nil).Once()
+		mockDb.On("GetChatEventsAfter", "chat-1", uint64(0), chatStreamBatchSize).Return([]db.ChatEvent{}, nil).Once().
+			Run(func(args mock.Arguments) { broker.Notify(db.ChatEvent{ID: 1, ChatID: "chat-1"}) })
+		mockDb.On("GetChatEventsAfter", "chat-1", uint64(0), chatStreamBatchSize).Return([]db.ChatEvent{
+			{ID: 1, ChatID: "chat-1", Type: db.ChatArtifactEvent, Payload: db.PropertyMap{"type": "text"}},
+		}, nil).Once().Run(func(args mock.Arguments) { cancel() })
+
+		rr := httptest.NewRecorder()
+		ch.StreamChat(rr, newStreamRequest(ctx, "pubkey"))
+
+		assert.Contains(t, rr.Body.String(), "id: 1\nevent: artifact\ndata: {\"type\":\"text\"}\n\n")
+	})
+
+	t.Run("Ends On Shutdown", func(t *testing.T) {
+		ch, mockDb, broker := newStreamHandler(t)
+
+		mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1"}, nil).Once()
+		mockDb.On("GetChatEventsAfter", "chat-1", uint64(0), chatStreamBatchSize).Return([]db.ChatEvent{}, nil).Once().
+			Run(func(args mock.Arguments) { broker.Shutdown(context.Background()) })
+
+		done := make(chan struct{})
+		go func() {
+			ch.StreamChat(httptest.NewRecorder(), newStreamRequest(context.Background(), "pubkey"))
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("stream did not end on shutdown")
+		}
+	})
+}
+
+func TestProcessChatStreamChunk(t *testing.T) {
+	post := func(ch *ChatHandler, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/hivechat/response/stream", strings.NewReader(body))
+		ch.ProcessChatStreamChunk(rr, req)
+		return rr
+	}
+
+	t.Run("Invalid Body", func(t *testing.T) {
+		ch := NewChatHandler(&http.Client{}, datamocks.NewDatabase(t))
+
+		rr := post(ch, `{"value":`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Missing Message ID", func(t *testing.T) {
+		ch := NewChatHandler(&http.Client{}, datamocks.NewDatabase(t))
+
+		rr := post(ch, `{"value":{"chatId":"chat-1","delta":"Hel"}}`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Appends Chunk", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("AppendChatMessageChunk", "chat-1", "msg-1", 1, "Hel").Return(db.ChatMessage{
+			ID: "msg-1", ChatID: "chat-1", Message: "Hel", Status: db.StreamingStatus,
+		}, []db.ChatMessageDelta{{MessageID: "msg-1", ChatID: "chat-1", Sequence: 1, Delta: "Hel"}}, nil).Once()
+
+		rr := post(ch, `{"value":{"chatId":"chat-1","messageId":"msg-1","sequence":1,"delta":"Hel"}}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Chunk processed successfully")
+	})
+
+	t.Run("Append Failure", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("AppendChatMessageChunk", "chat-1", "msg-1", 2, "lo").Return(db.ChatMessage{}, nil, errors.New("db down")).Once()
+
+		rr := post(ch, `{"value":{"chatId":"chat-1","messageId":"msg-1","sequence":2,"delta":"lo"}}`)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("Finalizes With Last Chunk And Artifacts", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("AppendChatMessageChunk", "chat-1", "msg-1", 3, "!").Return(db.ChatMessage{
+			ID: "msg-1", ChatID: "chat-1", Message: "Hello!", Status: db.StreamingStatus,
+		}, []db.ChatMessageDelta{{MessageID: "msg-1", ChatID: "chat-1", Sequence: 3, Delta: "!"}}, nil).Once()
+		mockDb.On("FinalizeChatMessage", "chat-1", "msg-1", "").Return(db.ChatMessage{
+			ID: "msg-1", ChatID: "chat-1", Message: "Hello!", Status: db.SentStatus,
+		}, true, nil).Once()
+		mockDb.On("CreateArtifact", mock.MatchedBy(func(artifact *db.Artifact) bool {
+			return artifact.MessageID == "msg-1" && artifact.Type == db.TextArtifact
+		})).Return(func(artifact *db.Artifact) (*db.Artifact, error) {
+			return artifact, nil
+		}).Once()
+
+		rr := post(ch, `{"value":{"chatId":"chat-1","messageId":"msg-1","sequence":3,"delta":"!","done":true,
+			"artifacts":[{"type":"text","content":{"text_type":"markdown","content":"notes"}}]}}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response ChatResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.True(t, response.Success)
+		assert.Len(t, response.Artifacts, 1)
+	})
+
+	t.Run("Completion Without Chunks", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("FinalizeChatMessage", "chat-1", "msg-2", "Full answer").Return(db.ChatMessage{
+			ID: "msg-2", ChatID: "chat-1", Message: "Full answer", Status: db.SentStatus,
+		}, true, nil).Once()
+
+		rr := post(ch, `{"value":{"chatId":"chat-1","messageId":"msg-2","done":true,"response":"Full answer"}}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Rejects Messages That Are Not Streaming Replies", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("AppendChatMessageChunk", "chat-1", "user-1", 1, "Hel").Return(db.ChatMessage{}, nil, db.ErrNotStreamingReply).Once()
+
+		rr := post(ch, `{"value":{"chatId":"chat-1","messageId":"user-1","sequence":1,"delta":"Hel","done":true}}`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockDb.AssertNotCalled(t, "FinalizeChatMessage", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Early Chunk Is Held Back", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("AppendChatMessageChunk", "chat-1", "msg-1", 3, "!").Return(db.ChatMessage{
+			ID: "msg-1", ChatID: "chat-1", Message: "Hel", Status: db.StreamingStatus, StreamSequence: 1,
+		}, nil, nil).Once()
+
+		rr := post(ch, `{"value":{"chatId":"chat-1","messageId":"msg-1","sequence":3,"delta":"!"}}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Repeated Completion Saves Nothing", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		message := db.ChatMessage{ID: "msg-1", ChatID: "chat-1", Message: "Hello!", Status: db.SentStatus}
+		mockDb.On("FinalizeChatMessage", "chat-1", "msg-1", "").Return(message, false, nil).Once()
+		mockDb.On("GetArtifactsByMessageID", "msg-1").Return([]db.Artifact{{MessageID: "msg-1", Type: db.TextArtifact}}, nil).Once()
+
+		rr := post(ch, `{"value":{"chatId":"chat-1","messageId":"msg-1","done":true,
+			"artifacts":[{"type":"text","content":{"text_type":"markdown","content":"notes"}}]}}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response ChatResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Len(t, response.Artifacts, 1)
+		mockDb.AssertNotCalled(t, "CreateArtifact", mock.Anything)
+	})
+}
+
+func TestGetSSEClients(t *testing.T) {
+	t.Run("Lists Client States", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetSSEClientStates", "chat-1").Return([]db.SSEClientState{
+			{ChatID: "chat-1", URL: "https://source.com/sse", Status: db.SSEClientRetrying, RetryCount: 2},
+		}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		ch.GetSSEClients(rr, httptest.NewRequest(http.MethodGet, "/hivechat/sse/clients?chat_id=chat-1", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"status":"retrying"`)
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetSSEClientStates", "").Return(nil, errors.New("db down")).Once()
+
+		rr := httptest.NewRecorder()
+		ch.GetSSEClients(rr, httptest.NewRequest(http.MethodGet, "/hivechat/sse/clients", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestProcessChatResponseCompletesReply(t *testing.T) {
+	mockDb := datamocks.NewDatabase(t)
+	ch := NewChatHandler(&http.Client{}, mockDb)
+	mockDb.On("FinalizeChatMessage", "chat-1", "reply-1", "Full answer").Return(db.ChatMessage{
+		ID: "reply-1", ChatID: "chat-1", Message: "Full answer", Status: db.SentStatus,
+	}, true, nil).Once()
+
+	rr := httptest.NewRecorder()
+	ch.ProcessChatResponse(rr, httptest.NewRequest(http.MethodPost, "/hivechat/response",
+		strings.NewReader(`{"value":{"chatId":"chat-1","messageId":"user-1","replyMessageId":"reply-1","response":"Full answer"}}`)))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockDb.AssertNotCalled(t, "AddChatMessage", mock.Anything)
+}
+
+func TestProcessChatResponseWithoutReplyID(t *testing.T) {
+	t.Run("Completes The Pending Reply To The Prompt", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetChatMessagesForChatID", "chat-1").Return([]db.ChatMessage{
+			{ID: "user-1", ChatID: "chat-1", Role: db.UserRole, BranchID: "branch-1"},
+			{ID: "reply-1", ChatID: "chat-1", Role: db.AssistantRole, Status: db.StreamingStatus, ParentID: "user-1", BranchID: "branch-1"},
+		}, nil).Once()
+		mockDb.On("FinalizeChatMessage", "chat-1", "reply-1", "Full answer").Return(db.ChatMessage{
+			ID: "reply-1", ChatID: "chat-1", Message: "Full answer", Status: db.SentStatus,
+		}, true, nil).Once()
+
+		rr := httptest.NewRecorder()
+		ch.ProcessChatResponse(rr, httptest.NewRequest(http.MethodPost, "/hivechat/response",
+			strings.NewReader(`{"value":{"chatId":"chat-1","messageId":"user-1","response":"Full answer"}}`)))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockDb.AssertNotCalled(t, "AddChatMessage", mock.Anything)
+	})
+
+	t.Run("Adds The Reply After The Prompt", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetChatMessagesForChatID", "chat-1").Return([]db.ChatMessage{
+			{ID: "user-1", ChatID: "chat-1", Role: db.UserRole, BranchID: "branch-1"},
+		}, nil).Once()
+		mockDb.On("AddChatMessage", mock.MatchedBy(func(message *db.ChatMessage) bool {
+			return message.ParentID == "user-1" && message.BranchID == "branch-1"
+		})).Return(func(message *db.ChatMessage) (db.ChatMessage, error) {
+			return *message, nil
+		}).Once()
+
+		rr := httptest.NewRecorder()
+		ch.ProcessChatResponse(rr, httptest.NewRequest(http.MethodPost, "/hivechat/response",
+			strings.NewReader(`{"value":{"chatId":"chat-1","messageId":"user-1","response":"Full answer"}}`)))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Completes The Newest Pending Reply Without Any ID", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		ch := NewChatHandler(&http.Client{}, mockDb)
+		mockDb.On("GetChatMessagesForChatID", "chat-1").Return([]db.ChatMessage{
+			{ID: "user-1", ChatID: "chat-1", Role: db.UserRole},
+			{ID: "reply-1", ChatID: "chat-1", Role: db.AssistantRole, Status: db.SentStatus, ParentID: "user-1"},
+			{ID: "user-2", ChatID: "chat-1", Role: db.UserRole},
+			{ID: "reply-2", ChatID: "chat-1", Role: db.AssistantRole, Status: db.StreamingStatus, ParentID: "user-2"},
+		}, nil).Once()
+		mockDb.On("FinalizeChatMessage", "chat-1", "reply-2", "Full answer").Return(db.ChatMessage{
+			ID: "reply-2", ChatID: "chat-1", Message: "Full answer", Status: db.SentStatus,
+		}, true, nil).Once()
+
+		rr := httptest.NewRecorder()
+		ch.ProcessChatResponse(rr, httptest.NewRequest(http.MethodPost, "/hivechat/response",
+			strings.NewReader(`{"value":{"chatId":"chat-1","response":"Full answer"}}`)))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockDb.AssertNotCalled(t, "AddChatMessage", mock.Anything)
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stakwork/sphinx-tribes/auth"
 	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/storage"
 	"github.com/stakwork/sphinx-tribes/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1687,47 +1688,24 @@ func TestArchiveChat(t *testing.T) {
 	})
 }
 
+type failingBlobStore struct {
+	storage.BlobStore
+}
+
+func (failingBlobStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return errors.New("storage service error")
+}
+
 func TestUploadFile(t *testing.T) {
 	teardownSuite := SetupSuite(t)
 	defer teardownSuite(t)
 
 	db.CleanTestData()
 
-	mockStorage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			t.Error("Expected POST request")
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		if err := r.ParseMultipartForm(32 << 20); err != nil {
-			t.Error("Failed to parse multipart form")
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		file, _, err := r.FormFile("file")
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer file.Close()
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		response := map[string]interface{}{
-			"success": true,
-			"url":     "https://meme.sphinx.chat/public/test123.txt",
-		}
-		json.NewEncoder(w).Encode(response)
-	}))
-	defer mockStorage.Close()
-
-	originalURL := os.Getenv("MEME_SERVER_URL")
-	os.Setenv("MEME_SERVER_URL", mockStorage.URL)
-	defer os.Setenv("MEME_SERVER_URL", originalURL)
-
+	store, err := storage.NewLocalStore(t.TempDir(), "https://api.example.com"+storage.SignedPath, []byte("secret"))
+	require.NoError(t, err)
 	chatHandler := NewChatHandler(&http.Client{}, db.TestDB)
+	chatHandler.files = store
 
 	createUploadRequest := func(filename, contentType string, content []byte, workspaceID string) (*http.Request, *httptest.ResponseRecorder) {
 		body := &bytes.Buffer{}
@@ -1779,7 +1757,7 @@ func TestUploadFile(t *testing.T) {
 
 		assert.True(t, response.Success)
 		assert.False(t, response.IsExisting)
-		assert.Equal(t, "https://meme.sphinx.chat/public/test123.txt", response.URL)
+		assert.Equal(t, chatHandler.fileBlobURL(response.Asset.UploadFilename), response.URL)
 
 		assert.NotZero(t, response.Asset.ID)
 		assert.Equal(t, "test.txt", response.Asset.OriginFilename)
@@ -1792,7 +1770,8 @@ func TestUploadFile(t *testing.T) {
 		assert.Equal(t, int64(len(fileContent)), response.Asset.FileSize)
 		assert.NotZero(t, response.Asset.UploadTime)
 		assert.NotZero(t, response.Asset.LastReferenced)
-		assert.Equal(t, "https://meme.sphinx.chat/public/test123.txt", response.Asset.StoragePath)
+		assert.Equal(t, response.URL, response.Asset.StoragePath)
+		assert.Equal(t, chatFileKey(response.Asset.UploadFilename), response.Asset.StorageKey)
 
 		storedAsset, err := db.TestDB.GetFileAssetByID(response.Asset.ID)
 		require.NoError(t, err)
@@ -1835,18 +1814,8 @@ func TestUploadFile(t *testing.T) {
 	})
 
 	t.Run("should handle storage service failure", func(t *testing.T) {
-		failingStorage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"error":   "Storage service error",
-			})
-		}))
-		defer failingStorage.Close()
-
 		failingHandler := NewChatHandler(&http.Client{}, db.TestDB)
-		os.Setenv("MEME_SERVER_URL", failingStorage.URL)
+		failingHandler.files = failingBlobStore{}
 
 		req, rr := createUploadRequest(
 			"test.txt",
@@ -1863,8 +1832,6 @@ func TestUploadFile(t *testing.T) {
 		require.NoError(t, err)
 		assert.False(t, response.Success)
 		assert.Contains(t, response.Message, "Failed to upload file")
-
-		os.Setenv("MEME_SERVER_URL", mockStorage.URL)
 	})
 
 	t.Run("should handle supported image types", func(t *testing.T) {
@@ -1881,18 +1848,8 @@ func TestUploadFile(t *testing.T) {
 		for _, img := range imageTypes {
 			t.Run(img.ext, func(t *testing.T) {
 
-				imgStorage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					w.Header().Set("Content-Type", "application/json")
-					w.WriteHeader(http.StatusOK)
-					json.NewEncoder(w).Encode(map[string]interface{}{
-						"success": true,
-						"url":     fmt.Sprintf("https://meme.sphinx.chat/public/test.%s", img.ext),
-					})
-				}))
-				defer imgStorage.Close()
-
-				os.Setenv("MEME_SERVER_URL", imgStorage.URL)
 				imgHandler := NewChatHandler(&http.Client{}, db.TestDB)
+				imgHandler.files = store
 
 				req, rr := createUploadRequest(
 					fmt.Sprintf("test.%s", img.ext),
@@ -1911,8 +1868,6 @@ func TestUploadFile(t *testing.T) {
 				assert.Equal(t, img.contentType, response.Asset.MimeType)
 			})
 		}
-
-		os.Setenv("MEME_SERVER_URL", mockStorage.URL)
 	})
 }
 
@@ -2475,10 +2430,13 @@ func TestSendMessage(t *testing.T) {
 
 		messages, err := db.TestDB.GetChatMessagesForChatID(chatID)
 		require.NoError(t, err)
-		assert.Equal(t, 1, len(messages))
+		assert.Equal(t, 2, len(messages))
 		assert.Equal(t, "Test message with PDF", messages[0].Message)
 		assert.Equal(t, db.UserRole, messages[0].Role)
 		assert.Equal(t, db.SendingStatus, messages[0].Status)
+		assert.Equal(t, db.AssistantRole, messages[1].Role)
+		assert.Equal(t, db.StreamingStatus, messages[1].Status)
+		assert.Equal(t, messages[0].ID, messages[1].ParentID)
 	})
 
 	t.Run("should handle unauthorized request", func(t *testing.T) {
@@ -2628,10 +2586,13 @@ func TestSendMessage(t *testing.T) {
 
 		messages, err := db.TestDB.GetChatMessagesForChatID(chat.ID)
 		require.NoError(t, err)
-		assert.Equal(t, 1, len(messages))
+		assert.Equal(t, 2, len(messages))
 		assert.Equal(t, "Test message without PDF", messages[0].Message)
 		assert.Equal(t, db.UserRole, messages[0].Role)
 		assert.Equal(t, db.SendingStatus, messages[0].Status)
+		assert.Equal(t, db.AssistantRole, messages[1].Role)
+		assert.Equal(t, db.StreamingStatus, messages[1].Status)
+		assert.Equal(t, messages[0].ID, messages[1].ParentID)
 	})
 
 	t.Run("should successfully send message with the model selection", func(t *testing.T) {
@@ -2695,10 +2656,13 @@ func TestSendMessage(t *testing.T) {
 
 		messages, err := db.TestDB.GetChatMessagesForChatID(chat.ID)
 		require.NoError(t, err)
-		assert.Equal(t, 1, len(messages))
+		assert.Equal(t, 2, len(messages))
 		assert.Equal(t, "Test message with model selection", messages[0].Message)
 		assert.Equal(t, db.UserRole, messages[0].Role)
 		assert.Equal(t, db.SendingStatus, messages[0].Status)
+		assert.Equal(t, db.AssistantRole, messages[1].Role)
+		assert.Equal(t, db.StreamingStatus, messages[1].Status)
+		assert.Equal(t, messages[0].ID, messages[1].ParentID)
 	})
 
 	t.Run("should successfully send message with PDF URL and model selection", func(t *testing.T) {
@@ -2763,10 +2727,13 @@ func TestSendMessage(t *testing.T) {
 
 		messages, err := db.TestDB.GetChatMessagesForChatID(chat.ID)
 		require.NoError(t, err)
-		assert.Equal(t, 1, len(messages))
+		assert.Equal(t, 2, len(messages))
 		assert.Equal(t, "Test message with PDF and model", messages[0].Message)
 		assert.Equal(t, db.UserRole, messages[0].Role)
 		assert.Equal(t, db.SendingStatus, messages[0].Status)
+		assert.Equal(t, db.AssistantRole, messages[1].Role)
+		assert.Equal(t, db.StreamingStatus, messages[1].Status)
+		assert.Equal(t, messages[0].ID, messages[1].ParentID)
 	})
 
 	t.Run("should successfully send message with code graph and workspaceId", func(t *testing.T) {
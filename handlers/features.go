@@ -18,6 +18,7 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/rs/xid"
 	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
 	"github.com/stakwork/sphinx-tribes/db"
 	"github.com/stakwork/sphinx-tribes/logger"
 )
@@ -55,6 +56,7 @@ type AudioBriefPostData struct {
 type featureHandler struct {
 	db                    db.Database
 	generateBountyHandler func(bounties []db.NewBounty) []db.BountyResponse
+	cfg                   *config.Config
 }
 
 func NewFeatureHandler(database db.Database) *featureHandler {
@@ -62,6 +64,7 @@ func NewFeatureHandler(database db.Database) *featureHandler {
 	return &featureHandler{
 		db:                    database,
 		generateBountyHandler: bHandler.GenerateBountyResponse,
+		cfg:                   bHandler.cfg,
 	}
 }
 
@@ -113,6 +116,24 @@ func (oh *featureHandler) CreateOrEditFeatures(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	setStart, setTarget := roadmapDateFields(body)
+	startDate, targetDate := features.StartDate, features.TargetDate
+	if setStart || setTarget {
+		if existing := oh.db.GetFeatureByUuid(features.Uuid); existing.Uuid != "" {
+			if !setStart {
+				startDate = existing.StartDate
+			}
+			if !setTarget {
+				targetDate = existing.TargetDate
+			}
+		}
+		if err := db.ValidateRoadmapDates(startDate, targetDate); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(err.Error())
+			return
+		}
+	}
+
 	// Check if workspace exists
 	workpace := oh.db.GetWorkspaceByUuid(features.WorkspaceUuid)
 	if workpace.Uuid != features.WorkspaceUuid {
@@ -126,11 +147,30 @@ func (oh *featureHandler) CreateOrEditFeatures(w http.ResponseWriter, r *http.Re
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	if setStart || setTarget {
+		p, err = oh.db.SetFeatureRoadmapDates(p.Uuid, startDate, targetDate)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(p)
 }
 
+// roadmapDateFields reports which of start_date and target_date a request
+// body sets, so a null date clears it while a missing one is kept.
+func roadmapDateFields(body []byte) (start, target bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return false, false
+	}
+	_, start = fields["start_date"]
+	_, target = fields["target_date"]
+	return start, target
+}
+
 // DeleteFeature godoc
 //
 //	@Summary		Delete Feature
@@ -358,8 +398,9 @@ func (oh *featureHandler) CreateOrEditFeaturePhase(w http.ResponseWriter, r *htt
 	}
 
 	newPhase := db.FeaturePhase{}
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&newPhase)
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	err := json.Unmarshal(body, &newPhase)
 	if err != nil {
 		w.WriteHeader(http.StatusNotAcceptable)
 		fmt.Fprintf(w, "Error decoding request body: %v", err)
@@ -378,6 +419,20 @@ func (oh *featureHandler) CreateOrEditFeaturePhase(w http.ResponseWriter, r *htt
 
 	newPhase.UpdatedBy = pubKeyFromAuth
 
+	setStart, setTarget := roadmapDateFields(body)
+	startDate, targetDate := newPhase.StartDate, newPhase.TargetDate
+	if !setStart {
+		startDate = existingPhase.StartDate
+	}
+	if !setTarget {
+		targetDate = existingPhase.TargetDate
+	}
+	if err := db.ValidateRoadmapDates(startDate, targetDate); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(err.Error())
+		return
+	}
+
 	// Check if feature exists
 	feature := oh.db.GetFeatureByUuid(newPhase.FeatureUuid)
 	if feature.Uuid != newPhase.FeatureUuid {
@@ -392,6 +447,14 @@ func (oh *featureHandler) CreateOrEditFeaturePhase(w http.ResponseWriter, r *htt
 		fmt.Fprintf(w, "Error creating feature phase: %v", err)
 		return
 	}
+	if setStart || setTarget {
+		phase, err = oh.db.SetFeaturePhaseRoadmapDates(phase.Uuid, startDate, targetDate)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "Error creating feature phase: %v", err)
+			return
+		}
+	}
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(phase)
@@ -1227,6 +1290,20 @@ func (oh *featureHandler) GetQuickTickets(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	groups := make([]string, 0, len(latestTickets))
+	for group := range latestTickets {
+		groups = append(groups, group)
+	}
+	counts, err := oh.db.GetTicketCommentCounts(db.TicketCommentTarget, groups)
+	if err != nil {
+		logger.Log.Error("failed to count ticket comments: %v", err)
+	}
+	for group, item := range latestTickets {
+		item.CommentCount = counts[group].Total
+		item.UnresolvedCommentCount = counts[group].Unresolved
+		latestTickets[group] = item
+	}
+
 	for _, item := range latestTickets {
 		if item.PhaseID != nil {
 			response.Phases[*item.PhaseID] = append(response.Phases[*item.PhaseID], item)
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/extract"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// maxFileContextChars caps the file text added to a workflow payload.
+const maxFileContextChars = 60000
+
+const fileIndexTimeout = 2 * time.Minute
+
+// FileContext is the text of a file attached to a message with a file
+// context tag, as sent to Stakwork.
+type FileContext struct {
+	ID        uint   `json:"id"`
+	Filename  string `json:"filename"`
+	URL       string `json:"url"`
+	Content   string `json:"content"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// indexFileAsync indexes a new upload without holding up the request.
+func (ch *ChatHandler) indexFileAsync(asset db.FileAsset) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), fileIndexTimeout)
+		defer cancel()
+		if err := ch.IndexFileAsset(ctx, asset); err != nil {
+			logger.Log.Error("failed to index file %d: %v", asset.ID, err)
+		}
+	}()
+}
+
+// IndexFileAsset extracts the text of a file, splits it into chunks and
+// stores them for search. Failures are recorded on the file.
+func (ch *ChatHandler) IndexFileAsset(ctx context.Context, asset db.FileAsset) error {
+	if !extract.Supported(asset.MimeType) {
+		return extract.ErrUnsupported
+	}
+
+	err := ch.indexFileAsset(ctx, asset)
+	if err != nil {
+		if statusErr := ch.db.SetFileAssetExtractionStatus(asset.ID, db.FailedExtraction); statusErr != nil {
+			logger.Log.Error("failed to mark file %d as failed: %v", asset.ID, statusErr)
+		}
+	}
+	return err
+}
+
+func (ch *ChatHandler) indexFileAsset(ctx context.Context, asset db.FileAsset) error {
+	file, err := ch.openFileAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	text, err := extract.Text(asset.MimeType, file)
+	if err != nil {
+		return err
+	}
+
+	contents := extract.Chunk(text, extract.ChunkSize, extract.ChunkOverlap)
+	chunks := make([]db.FileChunk, len(contents))
+	for i, content := range contents {
+		chunks[i] = db.FileChunk{
+			WorkspaceID: asset.WorkspaceID,
+			ChunkIndex:  i,
+			Content:     content,
+		}
+	}
+	return ch.db.ReplaceFileChunks(asset.ID, chunks)
+}
+
+// openFileAsset reads a file from the blob store, or downloads it for files
+// uploaded before the blob store existed.
+func (ch *ChatHandler) openFileAsset(ctx context.Context, asset db.FileAsset) (io.ReadCloser, error) {
+	if asset.StorageKey != "" && ch.files != nil {
+		return ch.files.Open(ctx, asset.StorageKey)
+	}
+	if asset.StoragePath == "" {
+		return nil, fmt.Errorf("file %d has no content", asset.ID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.StoragePath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ch.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// contextTagsFromRequest keeps the context tags of a message request.
+func contextTagsFromRequest(request SendMessageRequest) db.ContextTags {
+	var tags db.ContextTags
+	for _, tag := range request.ContextTags {
+		if tag.Type == "" || tag.ID == "" {
+			continue
+		}
+		tags = append(tags, db.ContextTag{Type: db.ContextTagType(tag.Type), ID: tag.ID})
+	}
+	return tags
+}
+
+// fileContext returns the indexed text of the files tagged on a message.
+// Files of other workspaces and files that are not indexed are skipped.
+func (ch *ChatHandler) fileContext(workspaceID string, tags db.ContextTags) []FileContext {
+	files := []FileContext{}
+	remaining := maxFileContextChars
+	seen := map[uint]bool{}
+
+	for _, tag := range tags {
+		if tag.Type != db.FileContext || remaining <= 0 {
+			continue
+		}
+		id, err := strconv.ParseUint(tag.ID, 10, 32)
+		if err != nil || seen[uint(id)] {
+			continue
+		}
+		seen[uint(id)] = true
+
+		asset, err := ch.db.GetFileAssetByID(uint(id))
+		if err != nil || asset.Status == db.DeletedFileStatus || asset.WorkspaceID != workspaceID {
+			continue
+		}
+		chunks, err := ch.db.GetFileChunks(asset.ID)
+		if err != nil || len(chunks) == 0 {
+			continue
+		}
+
+		contents := make([]string, len(chunks))
+		for i, chunk := range chunks {
+			contents[i] = chunk.Content
+		}
+		content := []rune(extract.Join(contents))
+		truncated := len(content) > remaining
+		if truncated {
+			content = content[:remaining]
+		}
+		remaining -= len(content)
+
+		files = append(files, FileContext{
+			ID:        asset.ID,
+			Filename:  asset.OriginFilename,
+			URL:       asset.StoragePath,
+			Content:   string(content),
+			Truncated: truncated,
+		})
+	}
+	return files
+}
+
+// IndexFile extracts and indexes the text of a file again
+//
+//	@Summary		Index the text of a file
+//	@Description	Extract the text of a PDF, Markdown or plain text file and store it in chunks for search and chat context
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			id	path		string	true	"File ID"
+//	@Success		200	{object}	ChatResponse
+//	@Failure		400	{object}	ChatResponse
+//	@Failure		404	{object}	ChatResponse
+//	@Failure		422	{object}	ChatResponse
+//	@Router			/hivechat/file/{id}/index [post]
+func (ch *ChatHandler) IndexFile(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Invalid file ID",
+		})
+		return
+	}
+
+	asset, err := ch.db.GetFileAssetByID(uint(id))
+	if err != nil || asset.Status == db.DeletedFileStatus {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "File not found",
+		})
+		return
+	}
+	if !extract.Supported(asset.MimeType) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Text cannot be extracted from this file type",
+		})
+		return
+	}
+
+	if err := ch.IndexFileAsset(r.Context(), *asset); err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to index file: %v", err),
+		})
+		return
+	}
+
+	chunks, err := ch.db.GetFileChunks(asset.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to fetch file chunks",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Message: "File indexed",
+		Data:    chunks,
+	})
+}
+
+// SearchFiles searches the text of the files of a workspace
+//
+//	@Summary		Search file contents
+//	@Description	Full text search over the extracted text of the files of a workspace, best matches first
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspaceId	query		string	true	"Workspace ID"
+//	@Param			q			query		string	true	"Search terms"
+//	@Param			limit		query		int		false	"Number of results (default and max 50)"
+//	@Success		200			{object}	ChatResponse
+//	@Failure		400			{object}	ChatResponse
+//	@Router			/hivechat/file/search [get]
+func (ch *ChatHandler) SearchFiles(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	workspaceID := query.Get("workspaceId")
+	terms := query.Get("q")
+	if workspaceID == "" || terms == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "workspaceId and q are required",
+		})
+		return
+	}
+
+	limit := 0
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ChatResponse{
+				Success: false,
+				Message: "Invalid limit",
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	matches, err := ch.db.SearchFileChunks(workspaceID, terms, limit)
+	if err != nil {
+		logger.Log.Error("failed to search files of %s: %v", workspaceID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to search files",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Data:    matches,
+	})
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexFileAsset(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Stores Chunks", func(t *testing.T) {
+		ch, mockDb, store := newStorageChatHandler(t)
+		text := strings.Repeat("The release ships on Friday. ", 200)
+		require.NoError(t, store.Put(ctx, "chat/plan.md", strings.NewReader(text), int64(len(text)), "text/markdown"))
+		asset := db.FileAsset{ID: 7, WorkspaceID: "ws-1", MimeType: "text/markdown", StorageKey: "chat/plan.md"}
+
+		var chunks []db.FileChunk
+		mockDb.On("ReplaceFileChunks", uint(7), mock.Anything).Run(func(args mock.Arguments) {
+			chunks = args.Get(1).([]db.FileChunk)
+		}).Return(nil).Once()
+
+		err := ch.IndexFileAsset(ctx, asset)
+
+		assert.NoError(t, err)
+		require.Greater(t, len(chunks), 1)
+		for i, chunk := range chunks {
+			assert.Equal(t, i, chunk.ChunkIndex)
+			assert.Equal(t, "ws-1", chunk.WorkspaceID)
+			assert.Contains(t, chunk.Content, "The release ships on Friday.")
+		}
+	})
+
+	t.Run("Marks Failures", func(t *testing.T) {
+		ch, mockDb, _ := newStorageChatHandler(t)
+		mockDb.On("SetFileAssetExtractionStatus", uint(8), db.FailedExtraction).Return(nil).Once()
+
+		err := ch.IndexFileAsset(ctx, db.FileAsset{ID: 8, MimeType: "application/pdf", StorageKey: "chat/missing.pdf"})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Downloads Legacy Files", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("legacy notes"))
+		}))
+		defer server.Close()
+		ch, mockDb, _ := newStorageChatHandler(t)
+		mockDb.On("ReplaceFileChunks", uint(9), []db.FileChunk{{WorkspaceID: "ws-1", Content: "legacy notes"}}).Return(nil).Once()
+
+		err := ch.IndexFileAsset(ctx, db.FileAsset{ID: 9, WorkspaceID: "ws-1", MimeType: "text/plain", StoragePath: server.URL + "/notes.txt"})
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestIndexFile(t *testing.T) {
+	t.Run("Unsupported Type", func(t *testing.T) {
+		ch, mockDb, _ := newStorageChatHandler(t)
+		mockDb.On("GetFileAssetByID", uint(3)).Return(&db.FileAsset{ID: 3, MimeType: "image/png"}, nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.IndexFile(rr, newBranchRequest(http.MethodPost, "/hivechat/file/3/index", "", map[string]string{"id": "3"}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		ch, mockDb, _ := newStorageChatHandler(t)
+		mockDb.On("GetFileAssetByID", uint(3)).Return(nil, errors.New("record not found")).Once()
+		rr := httptest.NewRecorder()
+
+		ch.IndexFile(rr, newBranchRequest(http.MethodPost, "/hivechat/file/3/index", "", map[string]string{"id": "3"}))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Indexes The File", func(t *testing.T) {
+		ch, mockDb, store := newStorageChatHandler(t)
+		require.NoError(t, store.Put(context.Background(), "chat/a.txt", strings.NewReader("notes"), 5, "text/plain"))
+		mockDb.On("GetFileAssetByID", uint(3)).Return(&db.FileAsset{ID: 3, MimeType: "text/plain", StorageKey: "chat/a.txt"}, nil).Once()
+		mockDb.On("ReplaceFileChunks", uint(3), mock.Anything).Return(nil).Once()
+		mockDb.On("GetFileChunks", uint(3)).Return([]db.FileChunk{{FileID: 3, Content: "notes"}}, nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.IndexFile(rr, newBranchRequest(http.MethodPost, "/hivechat/file/3/index", "", map[string]string{"id": "3"}))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"content":"notes"`)
+	})
+}
+
+func TestSearchFiles(t *testing.T) {
+	t.Run("Requires Workspace And Query", func(t *testing.T) {
+		ch, _, _ := newStorageChatHandler(t)
+		rr := httptest.NewRecorder()
+
+		ch.SearchFiles(rr, newBranchRequest(http.MethodGet, "/hivechat/file/search?workspaceId=ws-1", "", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Returns Matches", func(t *testing.T) {
+		ch, mockDb, _ := newStorageChatHandler(t)
+		mockDb.On("SearchFileChunks", "ws-1", "release date", 5).Return([]db.FileChunkMatch{
+			{FileChunk: db.FileChunk{FileID: 3, Content: "the release date is Friday"}, OriginFilename: "plan.md", Rank: 0.5},
+		}, nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.SearchFiles(rr, newBranchRequest(http.MethodGet, "/hivechat/file/search?workspaceId=ws-1&q=release+date&limit=5", "", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"originFilename":"plan.md"`)
+	})
+}
+
+func TestFileContext(t *testing.T) {
+	ch, mockDb, _ := newStorageChatHandler(t)
+	mockDb.On("GetFileAssetByID", uint(1)).Return(&db.FileAsset{ID: 1, WorkspaceID: "ws-1", OriginFilename: "plan.md", StoragePath: "https://api.example.com/hivechat/file/blob/a.md"}, nil).Once()
+	mockDb.On("GetFileChunks", uint(1)).Return([]db.FileChunk{{Content: "first part"}, {Content: "second part"}}, nil).Once()
+	mockDb.On("GetFileAssetByID", uint(2)).Return(&db.FileAsset{ID: 2, WorkspaceID: "ws-other"}, nil).Once()
+
+	files := ch.fileContext("ws-1", db.ContextTags{
+		{Type: db.ProductBriefContext, ID: "brief"},
+		{Type: db.FileContext, ID: "1"},
+		{Type: db.FileContext, ID: "1"},
+		{Type: db.FileContext, ID: "2"},
+		{Type: db.FileContext, ID: "not-a-number"},
+	})
+
+	require.Len(t, files, 1)
+	assert.Equal(t, "plan.md", files[0].Filename)
+	assert.Equal(t, "first part\n\nsecond part", files[0].Content)
+	assert.False(t, files[0].Truncated)
+}
+
+func TestContextTagsFromRequest(t *testing.T) {
+	var request SendMessageRequest
+	request.ContextTags = append(request.ContextTags,
+		struct {
+			Type string `json:"type"`
+			ID   string `json:"id"`
+		}{Type: "file", ID: "4"},
+		struct {
+			Type string `json:"type"`
+			ID   string `json:"id"`
+		}{Type: "file"},
+	)
+
+	assert.Equal(t, db.ContextTags{{Type: db.FileContext, ID: "4"}}, contextTagsFromRequest(request))
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/storage"
+)
+
+const megabyte = 1 << 20
+
+// StorageUsageResponse reports how much of its quota a workspace uses. A
+// quota of zero means there is no limit.
+type StorageUsageResponse struct {
+	WorkspaceID string `json:"workspaceId"`
+	UsedBytes   int64  `json:"usedBytes"`
+	QuotaBytes  int64  `json:"quotaBytes"`
+}
+
+type SetStorageQuotaRequest struct {
+	QuotaBytes int64 `json:"quotaBytes"`
+}
+
+func chatFileKey(uploadFilename string) string {
+	return "chat/" + uploadFilename
+}
+
+// fileBlobURL is the stable link stored on messages. It redirects members of
+// the workspace of the file to a freshly signed URL every time it is followed.
+func (ch *ChatHandler) fileBlobURL(uploadFilename string) string {
+	return fmt.Sprintf("%s/hivechat/file/blob/%s", ch.cfg.Server.Host, uploadFilename)
+}
+
+func (ch *ChatHandler) signedURLTTL() time.Duration {
+	return time.Duration(ch.cfg.Storage.SignedURLTTL) * time.Second
+}
+
+// fileURL returns a signed download URL for files in the blob store and the
+// stored URL for files uploaded before it existed.
+func (ch *ChatHandler) fileURL(ctx context.Context, asset *db.FileAsset) (string, error) {
+	if asset.StorageKey == "" || ch.files == nil {
+		return asset.StoragePath, nil
+	}
+	return ch.files.SignedURL(ctx, asset.StorageKey, ch.signedURLTTL())
+}
+
+// workflowFileURL swaps the blob link of a file of the workspace for a signed
+// URL, as Stakwork cannot sign in to follow the link. Other links are kept.
+func (ch *ChatHandler) workflowFileURL(workspaceID string, link string) string {
+	if !strings.HasPrefix(link, ch.fileBlobURL("")) {
+		return link
+	}
+	assets, err := ch.db.GetFileAssetsByStoragePaths([]string{link})
+	if err != nil || len(assets) == 0 || assets[0].WorkspaceID != workspaceID {
+		return link
+	}
+	url, err := ch.fileURL(context.Background(), &assets[0])
+	if err != nil || url == "" {
+		logger.Log.Error("failed to sign url of file %d: %v", assets[0].ID, err)
+		return link
+	}
+	return url
+}
+
+func (ch *ChatHandler) storageQuota(workspaceID string) (int64, error) {
+	quota, err := ch.db.GetWorkspaceStorageQuota(workspaceID)
+	if err != nil {
+		return 0, err
+	}
+	if quota != nil {
+		return quota.QuotaBytes, nil
+	}
+	return int64(ch.cfg.Storage.WorkspaceQuotaMB) * megabyte, nil
+}
+
+// checkStorageQuota returns http.StatusOK when the workspace has room for
+// size more bytes, and the status and message to reply with when it does not.
+// Files are always stored for a workspace.
+func (ch *ChatHandler) checkStorageQuota(workspaceID string, size int64) (int, string) {
+	if workspaceID == "" {
+		return http.StatusBadRequest, "workspaceId is required"
+	}
+
+	quota, err := ch.storageQuota(workspaceID)
+	if err != nil {
+		logger.Log.Error("failed to fetch storage quota of %s: %v", workspaceID, err)
+		return http.StatusInternalServerError, "Failed to check storage quota"
+	}
+	if quota == 0 {
+		return http.StatusOK, ""
+	}
+
+	used, err := ch.db.GetWorkspaceStorageUsage(workspaceID)
+	if err != nil {
+		logger.Log.Error("failed to fetch storage usage of %s: %v", workspaceID, err)
+		return http.StatusInternalServerError, "Failed to check storage quota"
+	}
+	if used+size > quota {
+		return http.StatusRequestEntityTooLarge, "Workspace storage quota exceeded"
+	}
+	return http.StatusOK, ""
+}
+
+// GetFileBlob redirects to a signed URL of an uploaded file
+//
+//	@Summary		Download an uploaded file
+//	@Description	Redirect a member of the workspace of a file to a short lived signed URL of it and mark the file as referenced
+//	@Tags			Hive Chat
+//	@Security		PubKeyContextAuth
+//	@Param			name	path	string	true	"Upload filename"
+//	@Success		302
+//	@Failure		403	{object}	ChatResponse
+//	@Failure		404	{object}	ChatResponse
+//	@Router			/hivechat/file/blob/{name} [get]
+func (ch *ChatHandler) GetFileBlob(w http.ResponseWriter, r *http.Request) {
+	asset, err := ch.db.GetFileAssetByUploadFilename(chi.URLParam(r, "name"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "File not found",
+		})
+		return
+	}
+
+	if err := ch.db.UpdateFileAssetReference(asset.ID); err != nil {
+		logger.Log.Error("failed to update reference of file %d: %v", asset.ID, err)
+	}
+
+	url, err := ch.fileURL(r.Context(), asset)
+	if err != nil || url == "" {
+		logger.Log.Error("failed to sign url of file %d: %v", asset.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to sign file URL",
+		})
+		return
+	}
+
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// ServeSignedFile streams a file of the local store to the holder of a
+// signed URL
+//
+//	@Summary		Serve a signed file
+//	@Description	Stream a file from local storage after checking the URL signature
+//	@Tags			Hive Chat
+//	@Param			expires		query	string	true	"Expiry as a unix timestamp"
+//	@Param			signature	query	string	true	"URL signature"
+//	@Success		200
+//	@Failure		403	{object}	ChatResponse
+//	@Failure		404	{object}	ChatResponse
+//	@Router			/hivechat/file/signed/{key} [get]
+func (ch *ChatHandler) ServeSignedFile(w http.ResponseWriter, r *http.Request) {
+	local, ok := ch.files.(*storage.LocalStore)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "File not found",
+		})
+		return
+	}
+
+	key := chi.URLParam(r, "*")
+	query := r.URL.Query()
+	if err := local.Verify(key, query.Get("expires"), query.Get("signature")); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	blob, err := local.Open(r.Context(), key)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrNotFound) {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "File not found",
+		})
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Cache-Control", "private, max-age=60")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if asset, err := ch.db.GetFileAssetByUploadFilename(path.Base(key)); err == nil {
+		w.Header().Set("Content-Type", asset.MimeType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", asset.OriginFilename))
+	}
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, blob)
+}
+
+// GetStorageUsage returns the storage used by a workspace
+//
+//	@Summary		Get workspace storage usage
+//	@Description	Get the bytes used by the files of a workspace and its quota
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspaceId	query		string	true	"Workspace ID"
+//	@Success		200			{object}	StorageUsageResponse
+//	@Failure		400			{object}	ChatResponse
+//	@Router			/hivechat/file/usage [get]
+func (ch *ChatHandler) GetStorageUsage(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.URL.Query().Get("workspaceId")
+	if workspaceID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "workspaceId is required",
+		})
+		return
+	}
+
+	used, err := ch.db.GetWorkspaceStorageUsage(workspaceID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to fetch storage usage",
+		})
+		return
+	}
+	quota, err := ch.storageQuota(workspaceID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to fetch storage quota",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Data: StorageUsageResponse{
+			WorkspaceID: workspaceID,
+			UsedBytes:   used,
+			QuotaBytes:  quota,
+		},
+	})
+}
+
+type storageHandler struct {
+	db db.Database
+}
+
+func NewStorageHandler(db db.Database) *storageHandler {
+	return &storageHandler{db: db}
+}
+
+// SetWorkspaceStorageQuota godoc
+//
+//	@Summary		Set a workspace storage quota
+//	@Description	Override the default upload quota of a workspace. Zero removes the limit.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		SuperAdminAuth
+//	@Param			workspace_id	path		string					true	"Workspace ID"
+//	@Param			quota			body		SetStorageQuotaRequest	true	"Quota"
+//	@Success		200				{object}	db.WorkspaceStorageQuota
+//	@Router			/admin/storage/quota/{workspace_id} [put]
+func (sh *storageHandler) SetWorkspaceStorageQuota(w http.ResponseWriter, r *http.Request) {
+	workspaceID := chi.URLParam(r, "workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var request SetStorageQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.QuotaBytes < 0 {
+		http.Error(w, "quotaBytes must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	if workspace := sh.db.GetWorkspaceByUuid(workspaceID); workspace.Uuid == "" {
+		http.Error(w, "Workspace not found", http.StatusNotFound)
+		return
+	}
+
+	quota := &db.WorkspaceStorageQuota{WorkspaceID: workspaceID, QuotaBytes: request.QuotaBytes}
+	if err := sh.db.SetWorkspaceStorageQuota(quota); err != nil {
+		logger.Log.Error(fmt.Sprintf("[storage] Failed to set quota of %s: %v", workspaceID, err))
+		http.Error(w, "Failed to set storage quota", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(quota)
+}
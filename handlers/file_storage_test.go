@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stakwork/sphinx-tribes/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newStorageChatHandler(t *testing.T) (*ChatHandler, *datamocks.Database, *storage.LocalStore) {
+	store, err := storage.NewLocalStore(t.TempDir(), "https://api.example.com"+storage.SignedPath, []byte("secret"))
+	require.NoError(t, err)
+	mockDb := datamocks.NewDatabase(t)
+	ch := NewChatHandler(&http.Client{}, mockDb)
+	ch.files = store
+	ch.indexFile = func(db.FileAsset) {}
+	return ch, mockDb, store
+}
+
+func newUploadRequest(t *testing.T, filename, contentType string, content []byte, workspaceID string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, filename))
+	h.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(h)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/hivechat/upload?workspaceId="+workspaceID, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req.WithContext(context.WithValue(req.Context(), "pubkey", "test-pubkey"))
+}
+
+func TestUploadFileToBlobStore(t *testing.T) {
+	t.Run("Stores The File", func(t *testing.T) {
+		ch, mockDb, store := newStorageChatHandler(t)
+		var indexed []db.FileAsset
+		ch.indexFile = func(asset db.FileAsset) { indexed = append(indexed, asset) }
+		mockDb.On("GetFileAssetByHash", mock.Anything).Return(nil, errors.New("not found")).Once()
+		mockDb.On("GetWorkspaceStorageQuota", "ws-1").Return(nil, nil).Once()
+		mockDb.On("CreateFileAsset", mock.Anything).Return(func(asset *db.FileAsset) (*db.FileAsset, error) {
+			return asset, nil
+		}).Once()
+		rr := httptest.NewRecorder()
+
+		ch.UploadFile(rr, newUploadRequest(t, "notes.txt", "text/plain", []byte("hello"), "ws-1"))
+
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var response FileResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, chatFileKey(response.Asset.UploadFilename), response.Asset.StorageKey)
+		assert.True(t, strings.HasSuffix(response.URL, "/hivechat/file/blob/"+response.Asset.UploadFilename))
+		assert.Equal(t, response.URL, response.Asset.StoragePath)
+
+		blob, err := store.Open(context.Background(), response.Asset.StorageKey)
+		require.NoError(t, err)
+		defer blob.Close()
+		content, _ := io.ReadAll(blob)
+		assert.Equal(t, "hello", string(content))
+
+		require.Len(t, indexed, 1)
+		assert.Equal(t, db.PendingExtraction, indexed[0].ExtractionStatus)
+	})
+
+	t.Run("Quota Exceeded", func(t *testing.T) {
+		ch, mockDb, _ := newStorageChatHandler(t)
+		mockDb.On("GetFileAssetByHash", mock.Anything).Return(nil, errors.New("not found")).Once()
+		mockDb.On("GetWorkspaceStorageQuota", "ws-1").Return(&db.WorkspaceStorageQuota{WorkspaceID: "ws-1", QuotaBytes: 10}, nil).Once()
+		mockDb.On("GetWorkspaceStorageUsage", "ws-1").Return(int64(8), nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.UploadFile(rr, newUploadRequest(t, "notes.txt", "text/plain", []byte("hello"), "ws-1"))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Workspace storage quota exceeded")
+	})
+
+	t.Run("Requires A Workspace", func(t *testing.T) {
+		ch, _, _ := newStorageChatHandler(t)
+		rr := httptest.NewRecorder()
+
+		ch.UploadFile(rr, newUploadRequest(t, "notes.txt", "text/plain", []byte("hello"), ""))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "workspaceId is required")
+	})
+
+	t.Run("Duplicate Skips The Quota", func(t *testing.T) {
+		ch, mockDb, _ := newStorageChatHandler(t)
+		existing := &db.FileAsset{ID: 4, StoragePath: "https://api.example.com/hivechat/file/blob/a.txt"}
+		mockDb.On("GetFileAssetByHash", mock.Anything).Return(existing, nil).Once()
+		mockDb.On("UpdateFileAssetReference", uint(4)).Return(nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.UploadFile(rr, newUploadRequest(t, "notes.txt", "text/plain", []byte("hello"), "ws-1"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"isExisting":true`)
+	})
+}
+
+func TestWorkflowFileURL(t *testing.T) {
+	ch, mockDb, _ := newStorageChatHandler(t)
+	cfg := *ch.cfg
+	cfg.Server.Host = "https://api.example.com"
+	ch.cfg = &cfg
+	link := ch.fileBlobURL("a.pdf")
+	mockDb.On("GetFileAssetsByStoragePaths", []string{link}).Return([]db.FileAsset{{ID: 1, WorkspaceID: "ws-1", StoragePath: link, StorageKey: "chat/a.pdf"}}, nil)
+
+	signed := ch.workflowFileURL("ws-1", link)
+	assert.True(t, strings.HasPrefix(signed, "https://api.example.com"+storage.SignedPath), signed)
+
+	assert.Equal(t, link, ch.workflowFileURL("ws-2", link))
+	assert.Equal(t, "https://example.com/spec.pdf", ch.workflowFileURL("ws-1", "https://example.com/spec.pdf"))
+}
+
+func TestGetFileBlob(t *testing.T) {
+	t.Run("Redirects To A Signed URL", func(t *testing.T) {
+		ch, mockDb, _ := newStorageChatHandler(t)
+		mockDb.On("GetFileAssetByUploadFilename", "a.txt").Return(&db.FileAsset{ID: 4, StorageKey: "chat/a.txt"}, nil).Once()
+		mockDb.On("UpdateFileAssetReference", uint(4)).Return(nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.GetFileBlob(rr, newBranchRequest(http.MethodGet, "/hivechat/file/blob/a.txt", "", map[string]string{"name": "a.txt"}))
+
+		assert.Equal(t, http.StatusFound, rr.Code)
+		location, err := url.Parse(rr.Header().Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, "/hivechat/file/signed/chat/a.txt", location.Path)
+		assert.NotEmpty(t, location.Query().Get("signature"))
+	})
+
+	t.Run("Legacy File", func(t *testing.T) {
+		ch, mockDb, _ := newStorageChatHandler(t)
+		mockDb.On("GetFileAssetByUploadFilename", "b.txt").Return(&db.FileAsset{ID: 5, StoragePath: "https://meme.sphinx.chat/public/b.txt"}, nil).Once()
+		mockDb.On("UpdateFileAssetReference", uint(5)).Return(nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.GetFileBlob(rr, newBranchRequest(http.MethodGet, "/hivechat/file/blob/b.txt", "", map[string]string{"name": "b.txt"}))
+
+		assert.Equal(t, http.StatusFound, rr.Code)
+		assert.Equal(t, "https://meme.sphinx.chat/public/b.txt", rr.Header().Get("Location"))
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		ch, mockDb, _ := newStorageChatHandler(t)
+		mockDb.On("GetFileAssetByUploadFilename", "missing.txt").Return(nil, errors.New("record not found")).Once()
+		rr := httptest.NewRecorder()
+
+		ch.GetFileBlob(rr, newBranchRequest(http.MethodGet, "/hivechat/file/blob/missing.txt", "", map[string]string{"name": "missing.txt"}))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestServeSignedFile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Streams The File", func(t *testing.T) {
+		ch, mockDb, store := newStorageChatHandler(t)
+		require.NoError(t, store.Put(ctx, "chat/a.txt", strings.NewReader("hello"), 5, "text/plain"))
+		mockDb.On("GetFileAssetByUploadFilename", "a.txt").Return(&db.FileAsset{OriginFilename: "notes.txt", MimeType: "text/plain"}, nil).Once()
+		signed, err := store.SignedURL(ctx, "chat/a.txt", ch.signedURLTTL())
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		ch.ServeSignedFile(rr, newBranchRequest(http.MethodGet, signed, "", map[string]string{"*": "chat/a.txt"}))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "hello", rr.Body.String())
+		assert.Equal(t, "text/plain", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "notes.txt")
+	})
+
+	t.Run("Bad Signature", func(t *testing.T) {
+		ch, _, store := newStorageChatHandler(t)
+		signed, err := store.SignedURL(ctx, "chat/a.txt", ch.signedURLTTL())
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		ch.ServeSignedFile(rr, newBranchRequest(http.MethodGet, signed, "", map[string]string{"*": "chat/other.txt"}))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestGetStorageUsage(t *testing.T) {
+	t.Run("Missing Workspace", func(t *testing.T) {
+		ch, _, _ := newStorageChatHandler(t)
+		rr := httptest.NewRecorder()
+
+		ch.GetStorageUsage(rr, newBranchRequest(http.MethodGet, "/hivechat/file/usage", "", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Reports Usage And Quota", func(t *testing.T) {
+		ch, mockDb, _ := newStorageChatHandler(t)
+		mockDb.On("GetWorkspaceStorageUsage", "ws-1").Return(int64(2048), nil).Once()
+		mockDb.On("GetWorkspaceStorageQuota", "ws-1").Return(&db.WorkspaceStorageQuota{WorkspaceID: "ws-1", QuotaBytes: 4096}, nil).Once()
+		rr := httptest.NewRecorder()
+
+		ch.GetStorageUsage(rr, newBranchRequest(http.MethodGet, "/hivechat/file/usage?workspaceId=ws-1", "", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"usedBytes":2048,"quotaBytes":4096`)
+	})
+}
+
+func TestSetWorkspaceStorageQuota(t *testing.T) {
+	params := map[string]string{"workspace_id": "ws-1"}
+
+	t.Run("Negative Quota", func(t *testing.T) {
+		sh := NewStorageHandler(datamocks.NewDatabase(t))
+		rr := httptest.NewRecorder()
+
+		sh.SetWorkspaceStorageQuota(rr, newBranchRequest(http.MethodPut, "/admin/storage/quota/ws-1", `{"quotaBytes":-1}`, params))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unknown Workspace", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		sh := NewStorageHandler(mockDb)
+		mockDb.On("GetWorkspaceByUuid", "ws-1").Return(db.Workspace{}).Once()
+		rr := httptest.NewRecorder()
+
+		sh.SetWorkspaceStorageQuota(rr, newBranchRequest(http.MethodPut, "/admin/storage/quota/ws-1", `{"quotaBytes":100}`, params))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Saves Quota", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		sh := NewStorageHandler(mockDb)
+		mockDb.On("GetWorkspaceByUuid", "ws-1").Return(db.Workspace{Uuid: "ws-1"}).Once()
+		mockDb.On("SetWorkspaceStorageQuota", &db.WorkspaceStorageQuota{WorkspaceID: "ws-1", QuotaBytes: 100}).Return(nil).Once()
+		rr := httptest.NewRecorder()
+
+		sh.SetWorkspaceStorageQuota(rr, newBranchRequest(http.MethodPut, "/admin/storage/quota/ws-1", `{"quotaBytes":100}`, params))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
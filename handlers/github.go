@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-	"os"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/google/go-github/v39/github"
@@ -29,7 +31,7 @@ import (
 //	@Param			issue	path		int		true	"Issue Number"
 //	@Success		200		{object}	db.GithubIssue
 //	@Router			/github_issues/{owner}/{repo}/{issue} [get]
-func GetGithubIssue(w http.ResponseWriter, r *http.Request) {
+func (gh *githubIssueSyncHandler) GetGithubIssue(w http.ResponseWriter, r *http.Request) {
 	owner := chi.URLParam(r, "owner")
 	repo := chi.URLParam(r, "repo")
 	issueString := chi.URLParam(r, "issue")
@@ -38,7 +40,7 @@ func GetGithubIssue(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotAcceptable)
 		return
 	}
-	issue, err := GetIssue(owner, repo, issueNum)
+	issue, err := GetIssue(gh.token, owner, repo, issueNum)
 	if err != nil {
 		logger.Log.Error("Github error: %v", err)
 		w.WriteHeader(http.StatusNotFound)
@@ -65,8 +67,7 @@ func GetOpenGithubIssues(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(issue_count)
 }
 
-func githubClient() *github.Client {
-	gh_token := os.Getenv("GITHUB_TOKEN")
+func githubClient(gh_token string) *github.Client {
 	ctx := context.Background()
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: gh_token},
@@ -76,8 +77,8 @@ func githubClient() *github.Client {
 	return gc
 }
 
-func GetRepoIssues(owner string, repo string) ([]db.GithubIssue, error) {
-	client := githubClient()
+func GetRepoIssues(token string, owner string, repo string) ([]db.GithubIssue, error) {
+	client := githubClient(token)
 	issues, _, err := client.Issues.ListByRepo(context.Background(), owner, repo, nil)
 	ret := []db.GithubIssue{}
 	if err == nil {
@@ -96,8 +97,8 @@ func GetRepoIssues(owner string, repo string) ([]db.GithubIssue, error) {
 	return ret, err
 }
 
-func GetIssue(owner string, repo string, id int) (db.GithubIssue, error) {
-	client := githubClient()
+func GetIssue(token string, owner string, repo string, id int) (db.GithubIssue, error) {
+	client := githubClient(token)
 	iss, _, err := client.Issues.Get(context.Background(), owner, repo, id)
 	issue := db.GithubIssue{}
 	if err == nil && iss != nil {
@@ -115,8 +116,144 @@ func GetIssue(owner string, repo string, id int) (db.GithubIssue, error) {
 	return issue, err
 }
 
-func PubkeyForGithubUser(owner string) (string, error) {
-	client := githubClient()
+// ParseGithubRepoURL returns the owner and name of the GitHub repository at
+// rawURL, which may be an https, ssh or scheme-less github.com URL.
+func ParseGithubRepoURL(rawURL string) (string, string, error) {
+	trimmed := strings.TrimSpace(rawURL)
+	if strings.HasPrefix(trimmed, "git@github.com:") {
+		trimmed = "https://github.com/" + strings.TrimPrefix(trimmed, "git@github.com:")
+	} else if !strings.Contains(trimmed, "://") {
+		trimmed = "https://" + trimmed
+	}
+
+	parsed, err := url.Parse(trimmed)
+	if err != nil || !strings.EqualFold(strings.TrimPrefix(parsed.Host, "www."), "github.com") {
+		return "", "", fmt.Errorf("%q is not a GitHub repository URL", rawURL)
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("%q is not a GitHub repository URL", rawURL)
+	}
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), nil
+}
+
+func githubIssueDetails(iss *github.Issue) db.GithubIssueDetails {
+	labels := []string{}
+	for _, label := range iss.Labels {
+		labels = append(labels, label.GetName())
+	}
+	return db.GithubIssueDetails{
+		Number:    iss.GetNumber(),
+		Title:     iss.GetTitle(),
+		Body:      iss.GetBody(),
+		State:     iss.GetState(),
+		Labels:    labels,
+		URL:       iss.GetHTMLURL(),
+		UpdatedAt: iss.GetUpdatedAt(),
+	}
+}
+
+// ListRepoIssues lists the issues of a repository in state (open, closed or
+// all), updated after since when it is set. Pull requests are left out.
+func ListRepoIssues(token string, owner string, repo string, state string, since *time.Time) ([]db.GithubIssueDetails, error) {
+	client := githubClient(token)
+	opts := &github.IssueListByRepoOptions{
+		State:       state,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	if since != nil {
+		opts.Since = *since
+	}
+
+	ret := []db.GithubIssueDetails{}
+	for {
+		issues, resp, err := client.Issues.ListByRepo(context.Background(), owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, iss := range issues {
+			if iss.IsPullRequest() {
+				continue
+			}
+			ret = append(ret, githubIssueDetails(iss))
+		}
+		if resp.NextPage == 0 {
+			return ret, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func GetRepoIssue(token string, owner string, repo string, number int) (db.GithubIssueDetails, error) {
+	client := githubClient(token)
+	iss, _, err := client.Issues.Get(context.Background(), owner, repo, number)
+	if err != nil {
+		return db.GithubIssueDetails{}, err
+	}
+	if iss.IsPullRequest() {
+		return db.GithubIssueDetails{}, fmt.Errorf("#%d is a pull request", number)
+	}
+	return githubIssueDetails(iss), nil
+}
+
+// ParseGithubPullRequestURL returns the owner, repository and number of the
+// pull request at rawURL, such as https://github.com/owner/repo/pull/12.
+func ParseGithubPullRequestURL(rawURL string) (string, string, int, error) {
+	owner, repo, err := ParseGithubRepoURL(rawURL)
+	if err != nil {
+		return "", "", 0, err
+	}
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", "", 0, err
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) < 4 || parts[2] != "pull" {
+		return "", "", 0, fmt.Errorf("%q is not a GitHub pull request URL", rawURL)
+	}
+	number, err := strconv.Atoi(parts[3])
+	if err != nil || number < 1 {
+		return "", "", 0, fmt.Errorf("%q is not a GitHub pull request URL", rawURL)
+	}
+	return owner, repo, number, nil
+}
+
+// githubPullRequestState maps a pull request to the state tracked on
+// bounties. Open pull requests with requested reviewers are in review.
+func githubPullRequestState(pr *github.PullRequest) db.PullRequestState {
+	switch {
+	case pr.GetMerged() || pr.MergedAt != nil:
+		return db.PullRequestMerged
+	case pr.GetState() == "closed":
+		return db.PullRequestClosed
+	case len(pr.RequestedReviewers) > 0 || len(pr.RequestedTeams) > 0:
+		return db.PullRequestReview
+	}
+	return db.PullRequestOpen
+}
+
+func githubPullRequestDetails(pr *github.PullRequest) db.GithubPullRequest {
+	return db.GithubPullRequest{
+		Number:   pr.GetNumber(),
+		Title:    pr.GetTitle(),
+		State:    githubPullRequestState(pr),
+		Author:   pr.GetUser().GetLogin(),
+		URL:      pr.GetHTMLURL(),
+		MergedAt: pr.MergedAt,
+	}
+}
+
+func GetRepoPullRequest(token string, owner string, repo string, number int) (db.GithubPullRequest, error) {
+	client := githubClient(token)
+	pr, _, err := client.PullRequests.Get(context.Background(), owner, repo, number)
+	if err != nil {
+		return db.GithubPullRequest{}, err
+	}
+	return githubPullRequestDetails(pr), nil
+}
+
+func PubkeyForGithubUser(token string, owner string) (string, error) {
+	client := githubClient(token)
 	gs, _, err := client.Gists.List(context.Background(), owner, nil)
 	if err == nil && gs != nil {
 		for _, g := range gs {
@@ -0,0 +1,501 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/go-github/v39/github"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+const (
+	githubSyncAuthor      = "github"
+	maxGithubWebhookBytes = 5 << 20
+)
+
+type githubIssueSyncHandler struct {
+	db            db.Database
+	listIssues    func(owner, repo, state string, since *time.Time) ([]db.GithubIssueDetails, error)
+	getIssue      func(owner, repo string, number int) (db.GithubIssueDetails, error)
+	webhookSecret string
+	token         string
+}
+
+func NewGithubIssueSyncHandler(database db.Database) *githubIssueSyncHandler {
+	cfg := config.Current().Github
+	listIssues := func(owner, repo, state string, since *time.Time) ([]db.GithubIssueDetails, error) {
+		return ListRepoIssues(cfg.Token, owner, repo, state, since)
+	}
+	getIssue := func(owner, repo string, number int) (db.GithubIssueDetails, error) {
+		return GetRepoIssue(cfg.Token, owner, repo, number)
+	}
+	return &githubIssueSyncHandler{
+		db:            database,
+		listIssues:    listIssues,
+		getIssue:      getIssue,
+		webhookSecret: cfg.WebhookSecret,
+		token:         cfg.Token,
+	}
+}
+
+type GithubIssueImportRequest struct {
+	RepositoryUuid string `json:"repository_uuid"`
+	// Numbers picks the issues to import; without it every issue in State is.
+	Numbers []int `json:"numbers,omitempty"`
+	// State is open (the default), closed or all.
+	State string `json:"state,omitempty"`
+}
+
+type GithubIssueSkip struct {
+	Number int    `json:"number"`
+	URL    string `json:"url,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type GithubIssueImportResponse struct {
+	Imported []db.Tickets      `json:"imported"`
+	Skipped  []GithubIssueSkip `json:"skipped"`
+}
+
+// ImportGithubIssues godoc
+//
+//	@Summary		Import GitHub issues as tickets
+//	@Description	Import issues of a workspace repository into a phase as tickets. Imported tickets are kept in sync with their issue; closed issues are imported as COMPLETED tickets and issues that are already linked to a ticket of the workspace are skipped.
+//	@Tags			Bounty Tickets
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			feature_uuid	path		string						true	"Feature UUID"
+//	@Param			phase_uuid		path		string						true	"Phase UUID"
+//	@Param			request			body		GithubIssueImportRequest	true	"Import Request"
+//	@Success		200				{object}	GithubIssueImportResponse
+//	@Router			/bounties/ticket/feature/{feature_uuid}/phase/{phase_uuid}/github/import [post]
+func (gh *githubIssueSyncHandler) ImportGithubIssues(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[github] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	featureUuid := chi.URLParam(r, "feature_uuid")
+	phaseUuid := chi.URLParam(r, "phase_uuid")
+
+	var request GithubIssueImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error parsing request body"})
+		return
+	}
+	if request.State == "" {
+		request.State = "open"
+	}
+	if request.State != "open" && request.State != "closed" && request.State != "all" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "state must be open, closed or all"})
+		return
+	}
+
+	feature := gh.db.GetFeatureByUuid(featureUuid)
+	if feature.Uuid == "" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "feature not found"})
+		return
+	}
+	if _, err := gh.db.GetFeaturePhaseByUuid(featureUuid, phaseUuid); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "phase not found"})
+		return
+	}
+
+	workspace := gh.db.GetWorkspaceByUuid(feature.WorkspaceUuid)
+	if workspace.OwnerPubKey != pubKeyFromAuth && gh.db.GetWorkspaceUser(pubKeyFromAuth, feature.WorkspaceUuid).OwnerPubKey != pubKeyFromAuth {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only workspace members can import issues"})
+		return
+	}
+
+	repository, err := gh.db.GetWorkspaceRepoByWorkspaceUuidAndRepoUuid(feature.WorkspaceUuid, request.RepositoryUuid)
+	if err != nil || repository.Uuid == "" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "repository not found"})
+		return
+	}
+	owner, repo, err := ParseGithubRepoURL(repository.Url)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	response := GithubIssueImportResponse{Imported: []db.Tickets{}, Skipped: []GithubIssueSkip{}}
+
+	var issues []db.GithubIssueDetails
+	if len(request.Numbers) > 0 {
+		for _, number := range request.Numbers {
+			issue, err := gh.getIssue(owner, repo, number)
+			if err != nil {
+				response.Skipped = append(response.Skipped, GithubIssueSkip{Number: number, Reason: err.Error()})
+				continue
+			}
+			issues = append(issues, issue)
+		}
+	} else {
+		issues, err = gh.listIssues(owner, repo, request.State, nil)
+		if err != nil {
+			logger.Log.Error("[github] failed to list issues of %s/%s: %v", owner, repo, err)
+			w.WriteHeader(http.StatusBadGateway)
+			json.NewEncoder(w).Encode(map[string]string{"error": "failed to list GitHub issues"})
+			return
+		}
+	}
+
+	links, err := gh.db.GetTicketGithubIssuesByRepo(owner, repo)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	linked := map[int]bool{}
+	for _, link := range links {
+		if link.WorkspaceUuid == feature.WorkspaceUuid {
+			linked[link.Number] = true
+		}
+	}
+
+	for _, issue := range issues {
+		if linked[issue.Number] {
+			response.Skipped = append(response.Skipped, GithubIssueSkip{Number: issue.Number, URL: issue.URL, Reason: "already imported"})
+			continue
+		}
+
+		ticket, err := gh.importIssue(feature, phaseUuid, repository.Uuid, owner, repo, issue, pubKeyFromAuth)
+		if err != nil {
+			response.Skipped = append(response.Skipped, GithubIssueSkip{Number: issue.Number, URL: issue.URL, Reason: err.Error()})
+			continue
+		}
+		linked[issue.Number] = true
+		response.Imported = append(response.Imported, ticket)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// importIssue creates the ticket of an issue and its link in one
+// transaction. Open issues enter the workspace workflow like any new ticket.
+func (gh *githubIssueSyncHandler) importIssue(feature db.WorkspaceFeatures, phaseUuid, repositoryUuid, owner, repo string, issue db.GithubIssueDetails, pubkey string) (db.Tickets, error) {
+	group := uuid.New()
+	author := db.HumanAuthor
+	ticket := db.Tickets{
+		UUID:           group,
+		TicketGroup:    &group,
+		FeatureUUID:    feature.Uuid,
+		PhaseUUID:      phaseUuid,
+		Name:           issue.Title,
+		Description:    issue.Body,
+		Version:        1,
+		Author:         &author,
+		AuthorID:       &pubkey,
+		GithubIssueURL: issue.URL,
+		RevisionNote:   fmt.Sprintf("imported from %s/%s#%d", owner, repo, issue.Number),
+	}
+
+	var saved db.Tickets
+	err := gh.db.InTransaction(func(tx db.Database) error {
+		if issue.State == "closed" {
+			done, err := closedIssueStatus(tx, ticket)
+			if err != nil {
+				return err
+			}
+			ticket.Status = done
+		}
+		if checkErr := ticketWorkflowError(tx, &ticket, ""); checkErr != nil {
+			return checkErr
+		}
+
+		var err error
+		saved, err = tx.CreateOrEditTicket(&ticket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateTicketGithubIssue(&db.TicketGithubIssue{
+			TicketGroup:    group,
+			WorkspaceUuid:  feature.WorkspaceUuid,
+			RepositoryUuid: repositoryUuid,
+			Owner:          owner,
+			Repo:           repo,
+			Number:         issue.Number,
+			URL:            issue.URL,
+			State:          issue.State,
+			Labels:         pq.StringArray(issue.Labels),
+			LastSyncedAt:   time.Now(),
+		})
+		return err
+	})
+	if err != nil {
+		return db.Tickets{}, err
+	}
+	return saved, nil
+}
+
+// closedIssueStatus is the status of a ticket whose issue is closed:
+// COMPLETED, or the last column of workflows without a COMPLETED column.
+func closedIssueStatus(database db.Database, ticket db.Tickets) (db.TicketStatus, error) {
+	workspaceUuid := ticketWorkspace(database, ticket)
+	if workspaceUuid == "" {
+		return db.CompletedTicket, nil
+	}
+	workflow, err := database.GetTicketWorkflow(workspaceUuid)
+	if err != nil {
+		return "", err
+	}
+	return workflow.DoneStatus(), nil
+}
+
+// syncTicketFromIssue brings the ticket linked to an issue up to date with
+// it as of syncedAt. Title, body and a close are saved as a new version of
+// the ticket; labels and state are kept on the link. A closed issue moves
+// the ticket to its done column when the workflow allows the move, and a
+// reopened issue leaves the status alone. It reports whether a new version
+// was saved.
+func syncTicketFromIssue(database db.Database, link db.TicketGithubIssue, issue db.GithubIssueDetails, syncedAt time.Time) (bool, error) {
+	changed := false
+	err := database.InTransaction(func(tx db.Database) error {
+		latest, err := tx.GetLatestTicketByGroup(link.TicketGroup)
+		if err != nil {
+			return err
+		}
+
+		next := latest
+		if issue.Title != "" && issue.Title != latest.Name {
+			next.Name = issue.Title
+			changed = true
+		}
+		if issue.Body != latest.Description {
+			next.Description = issue.Body
+			changed = true
+		}
+		if issue.State == "closed" {
+			done, err := closedIssueStatus(tx, latest)
+			if err != nil {
+				return err
+			}
+			if latest.Status != done {
+				next.Status = done
+				if checkErr := ticketWorkflowError(tx, &next, latest.Status); checkErr != nil {
+					logger.Log.Info("[github] ticket group %s stays in %s: %s", link.TicketGroup, latest.Status, checkErr.message)
+					next.Status = latest.Status
+				} else {
+					changed = true
+				}
+			}
+		}
+
+		if changed {
+			author := db.HumanAuthor
+			authorID := githubSyncAuthor
+			next.UUID = uuid.New()
+			next.Features = db.WorkspaceFeatures{}
+			next.FeaturePhase = db.FeaturePhase{}
+			next.Version = latest.Version + 1
+			next.Author = &author
+			next.AuthorID = &authorID
+			next.RevisionNote = "github sync"
+			if issue.URL != "" {
+				next.GithubIssueURL = issue.URL
+			}
+			if _, err := tx.CreateOrEditTicket(&next); err != nil {
+				return err
+			}
+		}
+
+		link.State = issue.State
+		link.Labels = pq.StringArray(issue.Labels)
+		if issue.URL != "" {
+			link.URL = issue.URL
+		}
+		link.LastSyncedAt = syncedAt
+		return tx.UpdateTicketGithubIssue(&link)
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+// GithubIssueWebhook godoc
+//
+//	@Summary		Receive GitHub issue events
+//	@Description	Sync tickets imported from GitHub issues when their issue changes. Requests must be signed with the configured webhook secret in X-Hub-Signature-256.
+//	@Tags			Github
+//	@Accept			json
+//	@Produce		json
+//	@Success		200
+//	@Failure		401
+//	@Router			/github_issue/webhook [post]
+func (gh *githubIssueSyncHandler) GithubIssueWebhook(w http.ResponseWriter, r *http.Request) {
+	if gh.webhookSecret == "" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "github webhook is not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxGithubWebhookBytes))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error reading request body"})
+		return
+	}
+	if !validGithubSignature(gh.webhookSecret, body, r.Header.Get("X-Hub-Signature-256")) {
+		logger.Log.Info("[github] webhook with an invalid signature")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid signature"})
+		return
+	}
+
+	switch r.Header.Get("X-GitHub-Event") {
+	case "ping":
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "pong"})
+		return
+	case "issues":
+	default:
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "event ignored"})
+		return
+	}
+
+	var event github.IssuesEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.Issue == nil || event.Repo == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error parsing issue event"})
+		return
+	}
+
+	links, err := gh.db.GetTicketGithubIssuesByRepo(event.Repo.GetOwner().GetLogin(), event.Repo.GetName())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	issue := githubIssueDetails(event.Issue)
+	synced := 0
+	for _, link := range links {
+		if link.Number != issue.Number {
+			continue
+		}
+		// the ticket outlives its issue, only the link goes
+		if event.GetAction() == "deleted" || event.GetAction() == "transferred" {
+			if err := gh.db.DeleteTicketGithubIssue(link.ID); err != nil {
+				logger.Log.Error("[github] failed to unlink %s: %v", link.URL, err)
+			}
+			continue
+		}
+		if _, err := syncTicketFromIssue(gh.db, link, issue, time.Now()); err != nil {
+			logger.Log.Error("[github] failed to sync ticket group %s: %v", link.TicketGroup, err)
+			continue
+		}
+		synced++
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]int{"synced": synced})
+}
+
+func validGithubSignature(secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(strings.TrimPrefix(signature, "sha256=")))
+}
+
+// SyncIssues polls GitHub for the issues linked to tickets, one request per
+// repository for the issues updated since its oldest sync. Every link of a
+// polled repository is marked synced as of the poll, so the next one starts
+// there; a link that failed to sync keeps its time and is polled again.
+func (gh *githubIssueSyncHandler) SyncIssues() error {
+	links, err := gh.db.GetAllTicketGithubIssues()
+	if err != nil {
+		return err
+	}
+
+	type repoKey struct{ owner, repo string }
+	byRepo := map[repoKey][]db.TicketGithubIssue{}
+	var repos []repoKey
+	for _, link := range links {
+		key := repoKey{link.Owner, link.Repo}
+		if _, ok := byRepo[key]; !ok {
+			repos = append(repos, key)
+		}
+		byRepo[key] = append(byRepo[key], link)
+	}
+
+	var failures []string
+	for _, key := range repos {
+		repoLinks := byRepo[key]
+		since := repoLinks[0].LastSyncedAt
+		for _, link := range repoLinks[1:] {
+			if link.LastSyncedAt.Before(since) {
+				since = link.LastSyncedAt
+			}
+		}
+
+		polledAt := time.Now()
+		issues, err := gh.listIssues(key.owner, key.repo, "all", &since)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s/%s: %v", key.owner, key.repo, err))
+			continue
+		}
+		byNumber := map[int]db.GithubIssueDetails{}
+		for _, issue := range issues {
+			byNumber[issue.Number] = issue
+		}
+
+		var unchanged []uuid.UUID
+		for _, link := range repoLinks {
+			issue, ok := byNumber[link.Number]
+			if !ok {
+				unchanged = append(unchanged, link.ID)
+				continue
+			}
+			if _, err := syncTicketFromIssue(gh.db, link, issue, polledAt); err != nil {
+				failures = append(failures, fmt.Sprintf("%s/%s#%d: %v", key.owner, key.repo, link.Number, err))
+			}
+		}
+		if err := gh.db.MarkTicketGithubIssuesSynced(unchanged, polledAt); err != nil {
+			failures = append(failures, fmt.Sprintf("%s/%s: %v", key.owner, key.repo, err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// SyncGithubIssues is the polling job that keeps imported tickets in sync
+// with their GitHub issues.
+func SyncGithubIssues() {
+	if err := NewGithubIssueSyncHandler(db.DB).SyncIssues(); err != nil {
+		logger.Log.Error("[github] issue sync failed: %v", err)
+	}
+}
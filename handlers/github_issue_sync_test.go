@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newGithubIssueSyncHandler(mockDb *datamocks.Database, issues []db.GithubIssueDetails) *githubIssueSyncHandler {
+	gh := NewGithubIssueSyncHandler(mockDb)
+	gh.webhookSecret = "hook-secret"
+	gh.listIssues = func(owner, repo, state string, since *time.Time) ([]db.GithubIssueDetails, error) {
+		return issues, nil
+	}
+	return gh
+}
+
+func signGithubPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseGithubRepoURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		owner string
+		repo  string
+		valid bool
+	}{
+		{"https://github.com/stakwork/sphinx-tribes", "stakwork", "sphinx-tribes", true},
+		{"https://github.com/stakwork/sphinx-tribes.git", "stakwork", "sphinx-tribes", true},
+		{"git@github.com:stakwork/sphinx-tribes.git", "stakwork", "sphinx-tribes", true},
+		{"github.com/stakwork/sphinx-tribes/issues", "stakwork", "sphinx-tribes", true},
+		{"https://gitlab.com/stakwork/sphinx-tribes", "", "", false},
+		{"https://github.com/stakwork", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			owner, repo, err := ParseGithubRepoURL(tt.url)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.owner, owner)
+			assert.Equal(t, tt.repo, repo)
+		})
+	}
+}
+
+func TestImportGithubIssues(t *testing.T) {
+	params := map[string]string{"feature_uuid": "feature-1", "phase_uuid": "phase-1"}
+	mockImport := func(mockDb *datamocks.Database, owner string) {
+		mockDb.On("GetFeatureByUuid", "feature-1").Return(db.WorkspaceFeatures{Uuid: "feature-1", WorkspaceUuid: "workspace-1"})
+		mockDb.On("GetFeaturePhaseByUuid", "feature-1", "phase-1").Return(db.FeaturePhase{Uuid: "phase-1"}, nil)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: owner})
+	}
+
+	t.Run("Imports Issues Once", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		gh := newGithubIssueSyncHandler(mockDb, []db.GithubIssueDetails{
+			{Number: 1, Title: "Fix login", Body: "It fails", State: "open", Labels: []string{"bug"}, URL: "https://github.com/stakwork/sphinx-tribes/issues/1"},
+			{Number: 2, Title: "Old task", State: "closed", URL: "https://github.com/stakwork/sphinx-tribes/issues/2"},
+			{Number: 3, Title: "Linked", State: "open", URL: "https://github.com/stakwork/sphinx-tribes/issues/3"},
+		})
+		mockImport(mockDb, "test-pubkey")
+		mockDb.On("GetTicketWorkflow", "workspace-1").Return(db.DefaultTicketWorkflow("workspace-1"), nil)
+		mockDb.On("GetWorkspaceRepoByWorkspaceUuidAndRepoUuid", "workspace-1", "repo-1").Return(db.WorkspaceRepositories{Uuid: "repo-1", Url: "https://github.com/stakwork/sphinx-tribes"}, nil)
+		mockDb.On("GetTicketGithubIssuesByRepo", "stakwork", "sphinx-tribes").Return([]db.TicketGithubIssue{{WorkspaceUuid: "workspace-1", Number: 3}}, nil)
+		mockDb.On("InTransaction", mock.Anything).Return(func(fn func(db.Database) error) error { return fn(mockDb) })
+		mockDb.On("CreateOrEditTicket", mock.AnythingOfType("*db.Tickets")).Return(func(ticket *db.Tickets) db.Tickets { return *ticket }, nil)
+		var links []db.TicketGithubIssue
+		mockDb.On("CreateTicketGithubIssue", mock.AnythingOfType("*db.TicketGithubIssue")).Return(func(link *db.TicketGithubIssue) db.TicketGithubIssue {
+			links = append(links, *link)
+			return *link
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		gh.ImportGithubIssues(rr, newBranchRequest(http.MethodPost, "/github/import", `{"repository_uuid":"repo-1"}`, params))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response GithubIssueImportResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		require.Len(t, response.Imported, 2)
+		assert.Equal(t, "Fix login", response.Imported[0].Name)
+		assert.Equal(t, db.DraftTicket, response.Imported[0].Status)
+		assert.Equal(t, "https://github.com/stakwork/sphinx-tribes/issues/1", response.Imported[0].GithubIssueURL)
+		assert.Equal(t, db.CompletedTicket, response.Imported[1].Status)
+		require.Len(t, response.Skipped, 1)
+		assert.Equal(t, 3, response.Skipped[0].Number)
+		assert.Equal(t, "already imported", response.Skipped[0].Reason)
+
+		require.Len(t, links, 2)
+		assert.Equal(t, *response.Imported[0].TicketGroup, links[0].TicketGroup)
+		assert.Equal(t, pq.StringArray{"bug"}, links[0].Labels)
+		assert.Equal(t, "repo-1", links[0].RepositoryUuid)
+	})
+
+	t.Run("Members Only", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		gh := newGithubIssueSyncHandler(mockDb, nil)
+		mockImport(mockDb, "owner-pubkey")
+		mockDb.On("GetWorkspaceUser", "test-pubkey", "workspace-1").Return(db.WorkspaceUsers{})
+
+		rr := httptest.NewRecorder()
+		gh.ImportGithubIssues(rr, newBranchRequest(http.MethodPost, "/github/import", `{"repository_uuid":"repo-1"}`, params))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestGithubIssueWebhook(t *testing.T) {
+	webhook := func(gh *githubIssueSyncHandler, event string, body []byte, signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/github_issue/webhook", strings.NewReader(string(body)))
+		req.Header.Set("X-GitHub-Event", event)
+		req.Header.Set("X-Hub-Signature-256", signature)
+		rr := httptest.NewRecorder()
+		gh.GithubIssueWebhook(rr, req)
+		return rr
+	}
+
+	t.Run("Rejects Bad Signatures", func(t *testing.T) {
+		gh := newGithubIssueSyncHandler(datamocks.NewDatabase(t), nil)
+		body := []byte(`{"zen":"hi"}`)
+
+		rr := webhook(gh, "ping", body, signGithubPayload("wrong-secret", body))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		gh := newGithubIssueSyncHandler(datamocks.NewDatabase(t), nil)
+		body := []byte(`{"zen":"hi"}`)
+
+		rr := webhook(gh, "ping", body, signGithubPayload("hook-secret", body))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	closeIssue := func(t *testing.T, workflow db.TicketWorkflow) db.Tickets {
+		mockDb := datamocks.NewDatabase(t)
+		gh := newGithubIssueSyncHandler(mockDb, nil)
+		ticket := newGraphTicket(db.InProgressTicket)
+		ticket.Name = "Fix login"
+		link := db.TicketGithubIssue{ID: uuid.New(), TicketGroup: *ticket.TicketGroup, Owner: "stakwork", Repo: "sphinx-tribes", Number: 7, State: "open"}
+		mockDb.On("GetTicketGithubIssuesByRepo", "Stakwork", "sphinx-tribes").Return([]db.TicketGithubIssue{link}, nil)
+		mockDb.On("InTransaction", mock.Anything).Return(func(fn func(db.Database) error) error { return fn(mockDb) })
+		mockDb.On("GetLatestTicketByGroup", *ticket.TicketGroup).Return(ticket, nil)
+		mockDb.On("GetFeatureByUuid", "feature-1").Return(db.WorkspaceFeatures{Uuid: "feature-1", WorkspaceUuid: "workspace-1"})
+		mockDb.On("GetTicketWorkflow", "workspace-1").Return(workflow, nil)
+		var saved db.Tickets
+		mockDb.On("CreateOrEditTicket", mock.AnythingOfType("*db.Tickets")).Return(func(t *db.Tickets) db.Tickets {
+			saved = *t
+			return *t
+		}, nil)
+		mockDb.On("UpdateTicketGithubIssue", mock.MatchedBy(func(l *db.TicketGithubIssue) bool {
+			return l.ID == link.ID && l.State == "closed" && len(l.Labels) == 1 && l.Labels[0] == "done"
+		})).Return(nil)
+
+		body := []byte(`{"action":"closed","issue":{"number":7,"title":"Fix login","body":"Fixed","state":"closed","labels":[{"name":"done"}]},"repository":{"name":"sphinx-tribes","owner":{"login":"Stakwork"}}}`)
+		rr := webhook(gh, "issues", body, signGithubPayload("hook-secret", body))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "Fixed", saved.Description)
+		assert.Equal(t, 2, saved.Version)
+		assert.Equal(t, "github", *saved.AuthorID)
+		assert.NotEqual(t, ticket.UUID, saved.UUID)
+		return saved
+	}
+
+	t.Run("Closed Issue Completes Ticket", func(t *testing.T) {
+		saved := closeIssue(t, db.DefaultTicketWorkflow("workspace-1"))
+
+		assert.Equal(t, db.CompletedTicket, saved.Status)
+	})
+
+	t.Run("Closed Issue Moves To The Last Column Of Custom Workflows", func(t *testing.T) {
+		saved := closeIssue(t, db.TicketWorkflow{
+			WorkspaceUuid: "workspace-1",
+			Columns:       db.WorkflowColumns{{Status: db.InProgressTicket}, {Status: "SHIPPED"}},
+		})
+
+		assert.Equal(t, db.TicketStatus("SHIPPED"), saved.Status)
+	})
+
+	t.Run("Closed Issue Keeps Moves The Workflow Forbids", func(t *testing.T) {
+		saved := closeIssue(t, db.TicketWorkflow{
+			WorkspaceUuid: "workspace-1",
+			Columns:       db.WorkflowColumns{{Status: db.InProgressTicket}, {Status: db.TestTicket}, {Status: db.CompletedTicket}},
+			Transitions:   db.WorkflowTransitions{{From: db.InProgressTicket, To: db.TestTicket}, {From: db.TestTicket, To: db.CompletedTicket}},
+		})
+
+		assert.Equal(t, db.InProgressTicket, saved.Status)
+	})
+}
+
+func TestSyncGithubIssues(t *testing.T) {
+	mockDb := datamocks.NewDatabase(t)
+	older := time.Now().Add(-time.Hour)
+	ticket := newGraphTicket(db.DraftTicket)
+	ticket.Name = "Same title"
+	links := []db.TicketGithubIssue{
+		{ID: uuid.New(), TicketGroup: *ticket.TicketGroup, Owner: "stakwork", Repo: "sphinx-tribes", Number: 1, LastSyncedAt: time.Now()},
+		{ID: uuid.New(), TicketGroup: uuid.New(), Owner: "stakwork", Repo: "sphinx-tribes", Number: 2, LastSyncedAt: older},
+	}
+	mockDb.On("GetAllTicketGithubIssues").Return(links, nil)
+	mockDb.On("InTransaction", mock.Anything).Return(func(fn func(db.Database) error) error { return fn(mockDb) })
+	mockDb.On("GetLatestTicketByGroup", *ticket.TicketGroup).Return(ticket, nil)
+	var synced time.Time
+	mockDb.On("UpdateTicketGithubIssue", mock.AnythingOfType("*db.TicketGithubIssue")).Run(func(args mock.Arguments) {
+		synced = args.Get(0).(*db.TicketGithubIssue).LastSyncedAt
+	}).Return(nil)
+	var marked time.Time
+	mockDb.On("MarkTicketGithubIssuesSynced", []uuid.UUID{links[1].ID}, mock.AnythingOfType("time.Time")).Run(func(args mock.Arguments) {
+		marked = args.Get(1).(time.Time)
+	}).Return(nil)
+
+	gh := NewGithubIssueSyncHandler(mockDb)
+	var listedSince time.Time
+	beforePoll := time.Now()
+	gh.listIssues = func(owner, repo, state string, since *time.Time) ([]db.GithubIssueDetails, error) {
+		listedSince = *since
+		assert.Equal(t, "all", state)
+		return []db.GithubIssueDetails{{Number: 1, Title: "Same title", State: "open", Labels: []string{"triage"}}}, nil
+	}
+
+	require.NoError(t, gh.SyncIssues())
+
+	assert.Equal(t, older, listedSince)
+	mockDb.AssertNotCalled(t, "CreateOrEditTicket", mock.Anything)
+	mockDb.AssertNumberOfCalls(t, "UpdateTicketGithubIssue", 1)
+	// the link whose issue did not change moves forward with the one that did
+	assert.False(t, marked.Before(beforePoll))
+	assert.Equal(t, synced, marked)
+}
@@ -14,10 +14,11 @@ import (
 	"github.com/stakwork/sphinx-tribes/config"
 	"github.com/stakwork/sphinx-tribes/db"
 	"github.com/stakwork/sphinx-tribes/utils"
+	"github.com/stakwork/sphinx-tribes/websocket"
 )
 
 // Keep for future reference
-func InitInvoiceCron() {
+func InitInvoiceCron(relay config.RelayConfig) {
 	s := gocron.NewScheduler(time.UTC)
 	msg := make(map[string]interface{})
 
@@ -27,12 +28,12 @@ func InitInvoiceCron() {
 
 		if invoiceCount > 0 {
 			for index, inv := range invoiceList {
-				url := fmt.Sprintf("%s/invoice?payment_request=%s", config.RelayUrl, inv.Invoice)
+				url := fmt.Sprintf("%s/invoice?payment_request=%s", relay.URL, inv.Invoice)
 
 				client := &http.Client{}
 				req, err := http.NewRequest(http.MethodGet, url, nil)
 
-				req.Header.Set("x-user-token", config.RelayAuthKey)
+				req.Header.Set("x-user-token", relay.AuthKey)
 				req.Header.Set("Content-Type", "application/json")
 				res, _ := client.Do(req)
 
@@ -64,14 +65,10 @@ func InitInvoiceCron() {
 						msg["msg"] = "invoice_success"
 						msg["invoice"] = inv.Invoice
 
-						socket, err := db.Store.GetSocketConnections(inv.Host)
-
-						if err == nil {
-							socket.Conn.WriteJSON(msg)
-						}
+						websocket.WebsocketPool.SendToSession(inv.Host, msg)
 
 						if inv.Type == "KEYSEND" {
-							url := fmt.Sprintf("%s/payment", config.RelayUrl)
+							url := fmt.Sprintf("%s/payment", relay.URL)
 
 							amount, _ := utils.ConvertStringToUint(inv.Amount)
 
@@ -82,7 +79,7 @@ func InitInvoiceCron() {
 							client := &http.Client{}
 							req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
 
-							req.Header.Set("x-user-token", config.RelayAuthKey)
+							req.Header.Set("x-user-token", relay.AuthKey)
 							req.Header.Set("Content-Type", "application/json")
 							res, _ := client.Do(req)
 
@@ -115,10 +112,7 @@ func InitInvoiceCron() {
 								msg["msg"] = "keysend_success"
 								msg["invoice"] = inv.Invoice
 
-								socket, err := db.Store.GetSocketConnections(inv.Host)
-								if err == nil {
-									socket.Conn.WriteJSON(msg)
-								}
+								websocket.WebsocketPool.SendToSession(inv.Host, msg)
 							} else {
 								// Unmarshal result
 								keysendError := db.KeysendError{}
@@ -128,11 +122,7 @@ func InitInvoiceCron() {
 								msg["msg"] = "keysend_error"
 								msg["invoice"] = inv.Invoice
 
-								socket, err := db.Store.GetSocketConnections(inv.Host)
-
-								if err == nil {
-									socket.Conn.WriteJSON(msg)
-								}
+								websocket.WebsocketPool.SendToSession(inv.Host, msg)
 
 								updateInvoiceCache(invoiceList, index)
 							}
@@ -161,10 +151,7 @@ func InitInvoiceCron() {
 							msg["msg"] = "assign_success"
 							msg["invoice"] = inv.Invoice
 
-							socket, err := db.Store.GetSocketConnections(inv.Host)
-							if err == nil {
-								socket.Conn.WriteJSON(msg)
-							}
+							websocket.WebsocketPool.SendToSession(inv.Host, msg)
 						}
 					}
 				}
@@ -178,12 +165,12 @@ func InitInvoiceCron() {
 
 		if invoiceCount > 0 {
 			for index, inv := range invoiceList {
-				url := fmt.Sprintf("%s/invoice?payment_request=%s", config.RelayUrl, inv.Invoice)
+				url := fmt.Sprintf("%s/invoice?payment_request=%s", relay.URL, inv.Invoice)
 
 				client := &http.Client{}
 				req, err := http.NewRequest(http.MethodGet, url, nil)
 
-				req.Header.Set("x-user-token", config.RelayAuthKey)
+				req.Header.Set("x-user-token", relay.AuthKey)
 				req.Header.Set("Content-Type", "application/json")
 				res, _ := client.Do(req)
 
@@ -215,11 +202,7 @@ func InitInvoiceCron() {
 						msg["msg"] = "budget_success"
 						msg["invoice"] = inv.Invoice
 
-						socket, err := db.Store.GetSocketConnections(inv.Host)
-
-						if err == nil {
-							socket.Conn.WriteJSON(msg)
-						}
+						websocket.WebsocketPool.SendToSession(inv.Host, msg)
 
 						// db.DB.AddAndUpdateBudget(inv)
 						updateBudgetInvoiceCache(invoiceList, index)
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type jobHandler struct {
+	db db.Database
+}
+
+func NewJobHandler(db db.Database) *jobHandler {
+	return &jobHandler{db: db}
+}
+
+// GetJobStatuses godoc
+//
+//	@Summary		Get background job statuses
+//	@Description	Get the latest run and last successful run of every cron job and background loop
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		SuperAdminAuth
+//	@Success		200	{array}	db.JobStatus
+//	@Router			/admin/jobs [get]
+func (jh *jobHandler) GetJobStatuses(w http.ResponseWriter, r *http.Request) {
+	statuses, err := jh.db.GetJobStatuses()
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("[jobs] Failed to fetch job statuses: %v", err))
+		http.Error(w, "Failed to fetch job statuses", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(statuses)
+}
+
+// GetJobRuns godoc
+//
+//	@Summary		Get background job run history
+//	@Description	Get the most recent runs of a job, newest first
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		SuperAdminAuth
+//	@Param			name	path	string	true	"Job name"
+//	@Param			limit	query	int		false	"Number of runs to return (default 50)"
+//	@Success		200		{array}	db.JobRun
+//	@Router			/admin/jobs/{name}/runs [get]
+func (jh *jobHandler) GetJobRuns(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		http.Error(w, "job name is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := jh.db.GetJobRuns(name, limit)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("[jobs] Failed to fetch runs of %s: %v", name, err))
+		http.Error(w, "Failed to fetch job runs", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(runs)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJobStatuses(t *testing.T) {
+	t.Run("Returns Job Statuses", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		jh := NewJobHandler(mockDb)
+
+		now := time.Now()
+		statuses := []db.JobStatus{
+			{JobName: "v2 payments", LastRunAt: now, LastStatus: db.JobRunSuccess, LastSuccessAt: &now},
+		}
+		mockDb.On("GetJobStatuses").Return(statuses, nil).Once()
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+		http.HandlerFunc(jh.GetJobStatuses).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response []db.JobStatus
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "v2 payments", response[0].JobName)
+		assert.Equal(t, db.JobRunSuccess, response[0].LastStatus)
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		jh := NewJobHandler(mockDb)
+		mockDb.On("GetJobStatuses").Return(nil, errors.New("db down")).Once()
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+		http.HandlerFunc(jh.GetJobStatuses).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestGetJobRuns(t *testing.T) {
+	tests := []struct {
+		name           string
+		jobName        string
+		limit          string
+		mockSetup      func(mockDb *datamocks.Database)
+		expectedStatus int
+	}{
+		{
+			name:    "Default Limit",
+			jobName: "v2 payments",
+			mockSetup: func(mockDb *datamocks.Database) {
+				mockDb.On("GetJobRuns", "v2 payments", 0).Return([]db.JobRun{{ID: 1, JobName: "v2 payments"}}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "Custom Limit",
+			jobName: "v2 payments",
+			limit:   "5",
+			mockSetup: func(mockDb *datamocks.Database) {
+				mockDb.On("GetJobRuns", "v2 payments", 5).Return([]db.JobRun{}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid Limit",
+			jobName:        "v2 payments",
+			limit:          "abc",
+			mockSetup:      func(mockDb *datamocks.Database) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "Database Error",
+			jobName: "v2 payments",
+			mockSetup: func(mockDb *datamocks.Database) {
+				mockDb.On("GetJobRuns", "v2 payments", 0).Return(nil, errors.New("db down")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDb := datamocks.NewDatabase(t)
+			jh := NewJobHandler(mockDb)
+			tt.mockSetup(mockDb)
+
+			url := "/admin/jobs/" + neturl.PathEscape(tt.jobName) + "/runs"
+			if tt.limit != "" {
+				url += "?limit=" + tt.limit
+			}
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("name", tt.jobName)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(jh.GetJobRuns).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/knowledge"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+const knowledgeContextTimeout = 10 * time.Second
+
+// knowledgeContext returns the workspace knowledge to attach to a payload:
+// the items pinned with knowledge context tags and the items most relevant to
+// query. It returns nothing when the knowledge base is not set up.
+func knowledgeContext(base *knowledge.Base, workspaceUUID, query string, tags db.ContextTags) []knowledge.Match {
+	if base == nil || workspaceUUID == "" {
+		return nil
+	}
+
+	var pinned []uint
+	for _, tag := range tags {
+		if tag.Type != db.KnowledgeContext {
+			continue
+		}
+		if id, err := strconv.ParseUint(tag.ID, 10, 32); err == nil {
+			pinned = append(pinned, uint(id))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), knowledgeContextTimeout)
+	defer cancel()
+	return base.Context(ctx, workspaceUUID, query, pinned)
+}
+
+type knowledgeHandler struct {
+	db   db.Database
+	base *knowledge.Base
+}
+
+func NewKnowledgeHandler(database db.Database) *knowledgeHandler {
+	base := knowledge.Default
+	if base == nil {
+		base = knowledge.New(database, config.Current().Knowledge, &http.Client{Timeout: 30 * time.Second})
+	}
+	return &knowledgeHandler{
+		db:   database,
+		base: base,
+	}
+}
+
+// GetWorkspaceKnowledge godoc
+//
+//	@Summary		Get workspace knowledge
+//	@Description	List the knowledge items of a workspace, or the items most relevant to q, best first
+//	@Tags			Knowledge
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Param			q				query		string	false	"Search terms"
+//	@Param			limit			query		int		false	"Number of results when searching"
+//	@Success		200				{array}		knowledge.Match
+//	@Router			/knowledge/workspace/{workspace_uuid} [get]
+func (kh *knowledgeHandler) GetWorkspaceKnowledge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[knowledge] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	workspaceUUID := chi.URLParam(r, "workspace_uuid")
+	if workspaceUUID == "" {
+		http.Error(w, "workspace_uuid is required", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := 10
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	if terms := query.Get("q"); terms != "" {
+		matches, err := kh.base.Search(ctx, workspaceUUID, terms, limit)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("[knowledge] Failed to search knowledge of %s: %v", workspaceUUID, err))
+			http.Error(w, "Failed to search knowledge", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(matches)
+		return
+	}
+
+	items, err := kh.db.GetKnowledgeItems(workspaceUUID)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("[knowledge] Failed to fetch knowledge of %s: %v", workspaceUUID, err))
+		http.Error(w, "Failed to fetch knowledge", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(items)
+}
+
+// IndexWorkspaceKnowledge godoc
+//
+//	@Summary		Index workspace knowledge
+//	@Description	Rebuild the knowledge items of a workspace from its mission, tactics, features, phases, stories and snippets
+//	@Tags			Knowledge
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Success		200				{object}	map[string]int
+//	@Router			/knowledge/workspace/{workspace_uuid}/index [post]
+func (kh *knowledgeHandler) IndexWorkspaceKnowledge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[knowledge] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	workspaceUUID := chi.URLParam(r, "workspace_uuid")
+	if workspace := kh.db.GetWorkspaceByUuid(workspaceUUID); workspace.Uuid == "" {
+		http.Error(w, "Workspace not found", http.StatusNotFound)
+		return
+	}
+
+	count, err := kh.base.Index(workspaceUUID)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("[knowledge] Failed to index knowledge of %s: %v", workspaceUUID, err))
+		http.Error(w, "Failed to index knowledge", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]int{"items": count})
+}
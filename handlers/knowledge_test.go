@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/knowledge"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestKnowledgeHandler(t *testing.T) (*knowledgeHandler, *datamocks.Database) {
+	mockDb := datamocks.NewDatabase(t)
+	kh := NewKnowledgeHandler(mockDb)
+	kh.base = knowledge.New(mockDb, config.Defaults().Knowledge, nil)
+	return kh, mockDb
+}
+
+func TestGetWorkspaceKnowledge(t *testing.T) {
+	params := map[string]string{"workspace_uuid": "ws-1"}
+	items := []db.KnowledgeItem{
+		{ID: 1, WorkspaceUUID: "ws-1", SourceType: db.KnowledgeMission, SourceID: "ws-1", Title: "Mission", Content: "Ship software with bounties"},
+		{ID: 2, WorkspaceUUID: "ws-1", SourceType: db.KnowledgeSnippet, SourceID: "4", Title: "Tone", Content: "Be brief"},
+	}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		kh, _ := newTestKnowledgeHandler(t)
+		req := httptest.NewRequest(http.MethodGet, "/knowledge/workspace/ws-1", nil)
+		rr := httptest.NewRecorder()
+
+		kh.GetWorkspaceKnowledge(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Lists Items", func(t *testing.T) {
+		kh, mockDb := newTestKnowledgeHandler(t)
+		mockDb.On("GetKnowledgeItems", "ws-1").Return(items, nil).Once()
+		rr := httptest.NewRecorder()
+
+		kh.GetWorkspaceKnowledge(rr, newBranchRequest(http.MethodGet, "/knowledge/workspace/ws-1", "", params))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"sourceType":"snippet"`)
+	})
+
+	t.Run("Searches Items", func(t *testing.T) {
+		kh, mockDb := newTestKnowledgeHandler(t)
+		mockDb.On("GetKnowledgeSources", "ws-1").Return(items, nil).Once()
+		mockDb.On("SyncKnowledgeItems", "ws-1", items).Return(nil).Once()
+		mockDb.On("GetKnowledgeItems", "ws-1").Return(items, nil).Once()
+		rr := httptest.NewRecorder()
+
+		kh.GetWorkspaceKnowledge(rr, newBranchRequest(http.MethodGet, "/knowledge/workspace/ws-1?q=bounties", "", params))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"title":"Mission"`)
+		assert.NotContains(t, rr.Body.String(), `"title":"Tone"`)
+	})
+
+	t.Run("Invalid Limit", func(t *testing.T) {
+		kh, _ := newTestKnowledgeHandler(t)
+		rr := httptest.NewRecorder()
+
+		kh.GetWorkspaceKnowledge(rr, newBranchRequest(http.MethodGet, "/knowledge/workspace/ws-1?q=x&limit=-1", "", params))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestIndexWorkspaceKnowledge(t *testing.T) {
+	params := map[string]string{"workspace_uuid": "ws-1"}
+
+	t.Run("Unknown Workspace", func(t *testing.T) {
+		kh, mockDb := newTestKnowledgeHandler(t)
+		mockDb.On("GetWorkspaceByUuid", "ws-1").Return(db.Workspace{}).Once()
+		rr := httptest.NewRecorder()
+
+		kh.IndexWorkspaceKnowledge(rr, newBranchRequest(http.MethodPost, "/knowledge/workspace/ws-1/index", "", params))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Indexes The Workspace", func(t *testing.T) {
+		kh, mockDb := newTestKnowledgeHandler(t)
+		mockDb.On("GetWorkspaceByUuid", "ws-1").Return(db.Workspace{Uuid: "ws-1"}).Once()
+		mockDb.On("GetKnowledgeSources", "ws-1").Return([]db.KnowledgeItem{{SourceType: db.KnowledgeMission, SourceID: "ws-1"}}, nil).Once()
+		mockDb.On("SyncKnowledgeItems", "ws-1", mock.Anything).Return(nil).Once()
+		rr := httptest.NewRecorder()
+
+		kh.IndexWorkspaceKnowledge(rr, newBranchRequest(http.MethodPost, "/knowledge/workspace/ws-1/index", "", params))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"items":1}`, rr.Body.String())
+	})
+}
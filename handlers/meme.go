@@ -19,6 +19,14 @@ import (
 	"github.com/stakwork/sphinx-tribes/logger"
 )
 
+type memeHandler struct {
+	cfg *config.Config
+}
+
+func NewMemeHandler(cfg *config.Config) *memeHandler {
+	return &memeHandler{cfg: cfg}
+}
+
 // MemeImageUpload godoc
 //
 //	@Summary		Meme Image Upload
@@ -30,7 +38,7 @@ import (
 //	@Param			file	formData	file	true	"Meme Image File"
 //	@Success		200		{string}	string	"Meme image URL"
 //	@Router			/meme_upload [post]
-func MemeImageUpload(w http.ResponseWriter, r *http.Request) {
+func (mh *memeHandler) MemeImageUpload(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
 
@@ -67,9 +75,9 @@ func MemeImageUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	challenge := GetMemeChallenge()
-	signer := SignChallenge(challenge.Challenge)
-	mErr, mToken := GetMemeToken(challenge.Id, signer.Response.Sig)
+	challenge := mh.GetMemeChallenge()
+	signer := mh.SignChallenge(challenge.Challenge)
+	mErr, mToken := mh.GetMemeToken(challenge.Id, signer.Response.Sig)
 
 	if mErr != "" {
 		msg := "Could not get meme token"
@@ -77,7 +85,7 @@ func MemeImageUpload(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
 		json.NewEncoder(w).Encode(msg)
 	} else {
-		err, memeImgUrl := UploadMemeImage(file, mToken.Token, header.Filename)
+		err, memeImgUrl := mh.UploadMemeImage(file, mToken.Token, header.Filename)
 		if err == nil {
 			w.WriteHeader(http.StatusOK)
 			json.NewEncoder(w).Encode(memeImgUrl)
@@ -91,10 +99,10 @@ func MemeImageUpload(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func GetMemeChallenge() db.MemeChallenge {
+func (mh *memeHandler) GetMemeChallenge() db.MemeChallenge {
 	memeChallenge := db.MemeChallenge{}
 
-	url := fmt.Sprintf("%s/ask", config.MemeUrl)
+	url := fmt.Sprintf("%s/ask", mh.cfg.Meme.URL)
 
 	client := &http.Client{}
 	req, err := http.NewRequest(http.MethodGet, url, nil)
@@ -128,10 +136,10 @@ type V2SignChallengeResponse struct {
 	Sig string `json:"sig"`
 }
 
-func SignChallenge(challenge string) db.RelaySignerResponse {
+func (mh *memeHandler) SignChallenge(challenge string) db.RelaySignerResponse {
 
-	if config.V2BotUrl != "" {
-		url := fmt.Sprintf("%s/sign_base64", config.V2BotUrl)
+	if mh.cfg.V2Bot.URL != "" {
+		url := fmt.Sprintf("%s/sign_base64", mh.cfg.V2Bot.URL)
 		client := &http.Client{}
 
 		challengeBody := SignChallengeBody{
@@ -141,7 +149,7 @@ func SignChallenge(challenge string) db.RelaySignerResponse {
 		jsonBody, _ := json.Marshal(challengeBody)
 
 		req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
-		req.Header.Set("x-admin-token", config.V2BotToken)
+		req.Header.Set("x-admin-token", mh.cfg.V2Bot.Token)
 		req.Header.Set("Content-Type", "application/json")
 		res, _ := client.Do(req)
 
@@ -187,12 +195,12 @@ func SignChallenge(challenge string) db.RelaySignerResponse {
 
 	}
 
-	url := fmt.Sprintf("%s/signer/%s", config.RelayUrl, challenge)
+	url := fmt.Sprintf("%s/signer/%s", mh.cfg.Relay.URL, challenge)
 
 	client := &http.Client{}
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 
-	req.Header.Set("x-user-token", config.RelayAuthKey)
+	req.Header.Set("x-user-token", mh.cfg.Relay.AuthKey)
 	req.Header.Set("Content-Type", "application/json")
 	res, _ := client.Do(req)
 
@@ -216,15 +224,15 @@ func SignChallenge(challenge string) db.RelaySignerResponse {
 	return signerResponse
 }
 
-func GetMemeToken(id string, sig string) (string, db.MemeTokenSuccess) {
-	memeUrl := fmt.Sprintf("%s/verify", config.MemeUrl)
+func (mh *memeHandler) GetMemeToken(id string, sig string) (string, db.MemeTokenSuccess) {
+	memeUrl := fmt.Sprintf("%s/verify", mh.cfg.Meme.URL)
 
 	var pubkey string
 
-	if config.V2BotUrl != "" {
-		pubkey = config.GetV2ContactKey()
+	if mh.cfg.V2Bot.URL != "" {
+		pubkey = config.GetV2ContactKey(mh.cfg.V2Bot)
 	} else {
-		pubkey = config.RelayNodeKey
+		pubkey = config.GetNodePubKey(mh.cfg.Relay)
 	}
 
 	formData := url.Values{
@@ -268,8 +276,8 @@ func GetMemeToken(id string, sig string) (string, db.MemeTokenSuccess) {
 	}
 }
 
-func UploadMemeImage(file multipart.File, token string, fileName string) (error, string) {
-	url := fmt.Sprintf("%s/public", config.MemeUrl)
+func (mh *memeHandler) UploadMemeImage(file multipart.File, token string, fileName string) (error, string) {
+	url := fmt.Sprintf("%s/public", mh.cfg.Meme.URL)
 	filePath := path.Join("./uploads", fileName)
 	fileW, _ := os.Open(filePath)
 	defer file.Close()
@@ -304,7 +312,7 @@ func UploadMemeImage(file multipart.File, token string, fileName string) (error,
 		if err != nil {
 			log.Printf("Reading meme error body failed: %s", err)
 		} else {
-			return nil, config.MemeUrl + "/public/" + memeSuccess.Muid
+			return nil, mh.cfg.Meme.URL + "/public/" + memeSuccess.Muid
 		}
 	}
 
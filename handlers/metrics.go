@@ -24,11 +24,18 @@ import (
 )
 
 type metricHandler struct {
-	db db.Database
+	db            db.Database
+	cfg           *config.Config
+	presignClient *s3.PresignClient
 }
 
 func NewMetricHandler(db db.Database) *metricHandler {
-	return &metricHandler{db: db}
+	cfg := config.Current()
+	return &metricHandler{
+		db:            db,
+		cfg:           cfg,
+		presignClient: config.NewPresignClient(cfg.S3),
+	}
 }
 
 // PaymentMetrics godoc
@@ -378,7 +385,7 @@ func (mh *metricHandler) MetricsBountiesProviders(w http.ResponseWriter, r *http
 //	@Param			request	body		db.PaymentDateRange	true	"Payment Date Range"
 //	@Success		200		{string}	string				"CSV file URL"
 //	@Router			/metrics/csv [post]
-func MetricsCsv(w http.ResponseWriter, r *http.Request) {
+func (mh *metricHandler) MetricsCsv(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
 
@@ -406,7 +413,7 @@ func MetricsCsv(w http.ResponseWriter, r *http.Request) {
 	resultLength := len(result)
 
 	if resultLength > 0 {
-		err, url := UploadMetricsCsv(result, request)
+		err, url := mh.UploadMetricsCsv(result, request)
 
 		if err != nil {
 			logger.Log.Error("Error uploading csv: %v", err)
@@ -526,7 +533,7 @@ func ConvertMetricsToCSV(metricBountiesData []db.MetricsBountyCsv) [][]string {
 	return result
 }
 
-func UploadMetricsCsv(data [][]string, request db.PaymentDateRange) (error, string) {
+func (mh *metricHandler) UploadMetricsCsv(data [][]string, request db.PaymentDateRange) (error, string) {
 	dirName := "uploads"
 	CreateUploadsDirectory(dirName)
 
@@ -553,9 +560,9 @@ func UploadMetricsCsv(data [][]string, request db.PaymentDateRange) (error, stri
 	upFile.Read(fileBuffer)
 
 	key := fmt.Sprintf("metrics%s-%s.csv", request.StartDate, request.EndDate)
-	path := fmt.Sprintf("%s/%s", config.S3FolderName, key)
+	path := fmt.Sprintf("%s/%s", mh.cfg.S3.FolderName, key)
 
-	err, postPresignedUrl := createPresignedUrl(path)
+	err, postPresignedUrl := mh.createPresignedUrl(path)
 
 	if err != nil {
 		logger.Log.Error("Presigned Error: %v", err)
@@ -576,15 +583,15 @@ func UploadMetricsCsv(data [][]string, request db.PaymentDateRange) (error, stri
 	// Delete image from uploads folder
 	DeleteFileFromUploadsFolder(filePath)
 
-	err, presignedUrlGet := getPresignedUrl(path)
+	err, presignedUrlGet := mh.getPresignedUrl(path)
 
 	return err, presignedUrlGet
 }
 
-func createPresignedUrl(path string) (error, string) {
-	presignedUrl, err := config.PresignClient.PresignPutObject(context.Background(),
+func (mh *metricHandler) createPresignedUrl(path string) (error, string) {
+	presignedUrl, err := mh.presignClient.PresignPutObject(context.Background(),
 		&s3.PutObjectInput{
-			Bucket: aws.String(config.S3BucketName),
+			Bucket: aws.String(mh.cfg.S3.BucketName),
 			Key:    aws.String(path),
 		},
 		s3.WithPresignExpires(time.Minute*15),
@@ -597,10 +604,10 @@ func createPresignedUrl(path string) (error, string) {
 	return nil, presignedUrl.URL
 }
 
-func getPresignedUrl(path string) (error, string) {
-	presignedUrl, err := config.PresignClient.PresignGetObject(context.Background(),
+func (mh *metricHandler) getPresignedUrl(path string) (error, string) {
+	presignedUrl, err := mh.presignClient.PresignGetObject(context.Background(),
 		&s3.GetObjectInput{
-			Bucket:                     aws.String(config.S3BucketName),
+			Bucket:                     aws.String(mh.cfg.S3.BucketName),
 			Key:                        aws.String(path),
 			ResponseContentDisposition: aws.String("attachment"),
 		},
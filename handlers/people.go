@@ -16,6 +16,7 @@ import (
 	"github.com/rs/xid"
 	"github.com/stakwork/sphinx-tribes/auth"
 	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/lifecycle"
 	"github.com/stakwork/sphinx-tribes/logger"
 	"github.com/stakwork/sphinx-tribes/utils"
 )
@@ -418,6 +419,17 @@ func ProcessTwitterConfirmationsLoop() {
 	if twitterToken == "" {
 		return
 	}
+	for {
+		lifecycle.Default.RunExclusive("twitter confirmations", 30*time.Second, processTwitterConfirmations)
+		select {
+		case <-lifecycle.Default.Done():
+			return
+		case <-time.After(30 * time.Second):
+		}
+	}
+}
+
+func processTwitterConfirmations() {
 	peeps := db.DB.GetUnconfirmedTwitter()
 	for _, p := range peeps {
 		twitArray, ok := p.Extras["twitter"].([]interface{})
@@ -439,11 +451,20 @@ func ProcessTwitterConfirmationsLoop() {
 			}
 		}
 	}
-	time.Sleep(30 * time.Second)
-	ProcessTwitterConfirmationsLoop()
 }
 
-func ProcessGithubIssuesLoop() {
+func ProcessGithubIssuesLoop(token string) {
+	for {
+		lifecycle.Default.RunExclusive("github issues", time.Minute, func() { processGithubIssues(token) })
+		select {
+		case <-lifecycle.Default.Done():
+			return
+		case <-time.After(1 * time.Minute):
+		}
+	}
+}
+
+func processGithubIssues(token string) {
 	peeps := db.DB.GetListedPeople(nil)
 
 	for _, p := range peeps {
@@ -471,7 +492,7 @@ func ProcessGithubIssuesLoop() {
 			if issint < 1 || err != nil {
 				continue
 			}
-			issue, issueErr := GetIssue(owner, reponame, issint)
+			issue, issueErr := GetIssue(token, owner, reponame, issint)
 			if issueErr != nil {
 				continue
 			}
@@ -506,11 +527,9 @@ func ProcessGithubIssuesLoop() {
 			db.DB.UpdateGithubIssues(p.ID, clonedGithubIssues)
 		}
 	}
-	time.Sleep(1 * time.Minute)
-	ProcessGithubIssuesLoop()
 }
 
-func processGithubConfirmationsLoop() {
+func processGithubConfirmationsLoop(token string) {
 	peeps := db.DB.GetUnconfirmedGithub()
 	for _, p := range peeps {
 		gitArray, ok := p.Extras["twitter"].([]interface{})
@@ -520,7 +539,7 @@ func processGithubConfirmationsLoop() {
 				if ok2 {
 					username, _ := gitValue["value"].(string)
 					if username != "" {
-						pubkey, err := PubkeyForGithubUser(username)
+						pubkey, err := PubkeyForGithubUser(token, username)
 						if err == nil && pubkey != "" {
 							if p.OwnerPubKey == pubkey {
 								db.DB.UpdateGithubConfirmed(p.ID, true)
@@ -532,7 +551,7 @@ func processGithubConfirmationsLoop() {
 		}
 	}
 	time.Sleep(30 * time.Second)
-	processGithubConfirmationsLoop()
+	processGithubConfirmationsLoop(token)
 }
 
 // GetPersonByPubkey godoc
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+func roadmapCalendarURL(host, workspaceUuid, token string) string {
+	return fmt.Sprintf("%s/features/roadmap/%s/calendar.ics?token=%s",
+		strings.TrimSuffix(host, "/"), url.PathEscape(workspaceUuid), url.QueryEscape(token))
+}
+
+// roadmapWorkspace loads the workspace of a roadmap request and checks that
+// the caller is a member, writing the error response when not.
+func (oh *featureHandler) roadmapWorkspace(w http.ResponseWriter, r *http.Request) (db.Workspace, string, bool) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return db.Workspace{}, "", false
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	workspace := oh.db.GetWorkspaceByUuid(workspaceUuid)
+	if workspace.Uuid == "" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "workspace not found"})
+		return db.Workspace{}, "", false
+	}
+
+	if workspace.OwnerPubKey != pubKeyFromAuth && oh.db.GetWorkspaceUser(pubKeyFromAuth, workspaceUuid).OwnerPubKey != pubKeyFromAuth {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "not a member of the workspace"})
+		return db.Workspace{}, "", false
+	}
+	return workspace, pubKeyFromAuth, true
+}
+
+// workspaceRoadmap builds the roadmap of the features of a workspace with
+// the given feature status, or all of them when status is empty.
+func (oh *featureHandler) workspaceRoadmap(workspaceUuid, status string, now time.Time) (db.WorkspaceRoadmap, error) {
+	// only the status filter is passed on, the roadmap has every feature in
+	// date order whatever the paging or sorting of the request
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	featuresRequest := &http.Request{URL: &url.URL{RawQuery: query.Encode()}}
+
+	var features []db.RoadmapFeature
+	for _, feature := range oh.db.GetFeaturesByWorkspaceUuid(workspaceUuid, featuresRequest) {
+		tickets, err := oh.db.GetTicketsByFeatureUUID(feature.Uuid)
+		if err != nil {
+			return db.WorkspaceRoadmap{}, err
+		}
+		bounties, err := oh.db.GetBountiesByFeatureUuid(feature.Uuid)
+		if err != nil {
+			return db.WorkspaceRoadmap{}, err
+		}
+		phases := oh.db.GetPhasesByFeatureUuid(feature.Uuid)
+		features = append(features, db.FeatureRoadmap(feature, phases, tickets, bounties, now))
+	}
+	return db.BuildWorkspaceRoadmap(workspaceUuid, features), nil
+}
+
+// GetWorkspaceRoadmap godoc
+//
+//	@Summary		Get Workspace Roadmap
+//	@Description	Get the features and phases of a workspace on a timeline, with their progress and slipped target dates. Only members of the workspace can read it.
+//	@Tags			Features
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Param			status			query		string	false	"Feature status"
+//	@Success		200				{object}	db.WorkspaceRoadmap
+//	@Router			/features/roadmap/{workspace_uuid} [get]
+func (oh *featureHandler) GetWorkspaceRoadmap(w http.ResponseWriter, r *http.Request) {
+	workspace, _, ok := oh.roadmapWorkspace(w, r)
+	if !ok {
+		return
+	}
+
+	roadmap, err := oh.workspaceRoadmap(workspace.Uuid, r.URL.Query().Get("status"), time.Now())
+	if err != nil {
+		logger.Log.Error("[roadmap] failed to build roadmap of %s: %v", workspace.Uuid, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	if calendar, err := oh.db.GetRoadmapCalendar(workspace.Uuid); err == nil {
+		roadmap.CalendarCreatedAt = &calendar.CreatedAt
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(roadmap)
+}
+
+// CreateRoadmapCalendar godoc
+//
+//	@Summary		Create Workspace Roadmap Calendar
+//	@Description	Create the iCalendar feed URL of a workspace roadmap. The token is only returned here; creating a new feed stops the old URL from working.
+//	@Tags			Features
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Success		200				{object}	map[string]string
+//	@Router			/features/roadmap/{workspace_uuid}/calendar [post]
+func (oh *featureHandler) CreateRoadmapCalendar(w http.ResponseWriter, r *http.Request) {
+	workspace, pubKeyFromAuth, ok := oh.roadmapWorkspace(w, r)
+	if !ok {
+		return
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		logger.Log.Error("[roadmap] failed to generate calendar token: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "failed to create calendar"})
+		return
+	}
+
+	if _, err := oh.db.SaveRoadmapCalendar(&db.RoadmapCalendar{
+		WorkspaceUuid: workspace.Uuid,
+		TokenHash:     hashShareToken(token),
+		CreatedBy:     pubKeyFromAuth,
+	}); err != nil {
+		logger.Log.Error("[roadmap] failed to save calendar of %s: %v", workspace.Uuid, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "failed to create calendar"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"calendar_url": roadmapCalendarURL(oh.cfg.Server.Host, workspace.Uuid, token)})
+}
+
+// DeleteRoadmapCalendar godoc
+//
+//	@Summary		Delete Workspace Roadmap Calendar
+//	@Description	Revoke the iCalendar feed URL of a workspace roadmap
+//	@Tags			Features
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path	string	true	"Workspace UUID"
+//	@Success		204
+//	@Router			/features/roadmap/{workspace_uuid}/calendar [delete]
+func (oh *featureHandler) DeleteRoadmapCalendar(w http.ResponseWriter, r *http.Request) {
+	workspace, _, ok := oh.roadmapWorkspace(w, r)
+	if !ok {
+		return
+	}
+
+	if err := oh.db.DeleteRoadmapCalendar(workspace.Uuid); err != nil {
+		logger.Log.Error("[roadmap] failed to delete calendar of %s: %v", workspace.Uuid, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWorkspaceRoadmapCalendar godoc
+//
+//	@Summary		Get Workspace Roadmap Calendar
+//	@Description	iCalendar feed of the phase target dates of a workspace, at the URL made with its calendar token
+//	@Tags			Features
+//	@Produce		text/calendar
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Param			token			query		string	true	"Calendar token"
+//	@Success		200				{string}	string
+//	@Router			/features/roadmap/{workspace_uuid}/calendar.ics [get]
+func (oh *featureHandler) GetWorkspaceRoadmapCalendar(w http.ResponseWriter, r *http.Request) {
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	calendar, err := oh.db.GetRoadmapCalendar(workspaceUuid)
+	if err != nil || !hmac.Equal([]byte(hashShareToken(token)), []byte(calendar.TokenHash)) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspace := oh.db.GetWorkspaceByUuid(workspaceUuid)
+	if workspace.Uuid == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	now := time.Now()
+	roadmap, err := oh.workspaceRoadmap(workspaceUuid, "", now)
+	if err != nil {
+		logger.Log.Error("[roadmap] failed to build calendar of %s: %v", workspaceUuid, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="roadmap.ics"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(roadmapCalendar(workspace.Name, roadmap, now)))
+}
+
+// roadmapCalendar renders the phase target dates of a roadmap as all-day
+// iCalendar events.
+func roadmapCalendar(workspaceName string, roadmap db.WorkspaceRoadmap, now time.Time) string {
+	var b strings.Builder
+	line := func(content string) {
+		b.WriteString(foldICalLine(content))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Sphinx Tribes//Roadmap//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeICalText(workspaceName+" roadmap"))
+	for _, feature := range roadmap.Features {
+		for _, phase := range feature.Phases {
+			if phase.TargetDate == nil {
+				continue
+			}
+			status := fmt.Sprintf("%d%% complete", phase.Progress.PercentComplete)
+			if phase.Slipped {
+				status += fmt.Sprintf(", %d days late", phase.DaysLate)
+			}
+			line("BEGIN:VEVENT")
+			line(fmt.Sprintf("UID:phase-%s@%s", phase.Uuid, roadmap.WorkspaceUuid))
+			line("DTSTAMP:" + now.UTC().Format("20060102T150405Z"))
+			line("DTSTART;VALUE=DATE:" + phase.TargetDate.Format("20060102"))
+			line("DTEND;VALUE=DATE:" + phase.TargetDate.AddDate(0, 0, 1).Format("20060102"))
+			line("SUMMARY:" + escapeICalText(fmt.Sprintf("%s: %s due", feature.Name, phase.Name)))
+			line("DESCRIPTION:" + escapeICalText(status))
+			line("END:VEVENT")
+		}
+	}
+	line("END:VCALENDAR")
+	return b.String()
+}
+
+var iCalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICalText(text string) string {
+	return iCalTextEscaper.Replace(text)
+}
+
+// foldICalLine splits content lines longer than 75 octets, without
+// breaking UTF-8 characters, as RFC 5545 asks.
+func foldICalLine(content string) string {
+	var b strings.Builder
+	length := 0
+	for _, r := range content {
+		size := len(string(r))
+		if length+size > 75 {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+		b.WriteRune(r)
+		length += size
+	}
+	return b.String()
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func mockWorkspaceRoadmap(mockDb *datamocks.Database, target time.Time) {
+	start := target.AddDate(0, 0, -14)
+	mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", Name: "Sphinx, Inc", OwnerPubKey: "test-pubkey"})
+	mockDb.On("GetFeaturesByWorkspaceUuid", "workspace-1", mock.MatchedBy(func(r *http.Request) bool {
+		// paging and sorting of the request never reach the query
+		return r.URL.Query().Get("limit") == "" && r.URL.Query().Get("sortBy") == ""
+	})).Return([]db.WorkspaceFeatures{
+		{Uuid: "feature-1", Name: "Payments", WorkspaceUuid: "workspace-1"},
+	})
+	ticket := newGraphTicket(db.CompletedTicket)
+	mockDb.On("GetTicketsByFeatureUUID", "feature-1").Return([]db.Tickets{ticket}, nil)
+	mockDb.On("GetBountiesByFeatureUuid", "feature-1").Return([]db.NewBounty{{PhaseUuid: "phase-1"}}, nil)
+	mockDb.On("GetPhasesByFeatureUuid", "feature-1").Return([]db.FeaturePhase{
+		{Uuid: "phase-1", FeatureUuid: "feature-1", Name: "Invoices", StartDate: &start, TargetDate: &target},
+		{Uuid: "phase-2", FeatureUuid: "feature-1", Name: "Refunds"},
+	})
+}
+
+func TestGetWorkspaceRoadmap(t *testing.T) {
+	t.Run("Rolls Up Progress", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		target := time.Now().AddDate(0, 0, -3)
+		mockWorkspaceRoadmap(mockDb, target)
+		created := time.Now()
+		mockDb.On("GetRoadmapCalendar", "workspace-1").Return(db.RoadmapCalendar{WorkspaceUuid: "workspace-1", CreatedAt: created}, nil)
+
+		rr := httptest.NewRecorder()
+		NewFeatureHandler(mockDb).GetWorkspaceRoadmap(rr, newBranchRequest(http.MethodGet, "/features/roadmap/workspace-1?limit=1&sortBy=1;DROP", "", map[string]string{"workspace_uuid": "workspace-1"}))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var roadmap db.WorkspaceRoadmap
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&roadmap))
+		require.Len(t, roadmap.Features, 1)
+		feature := roadmap.Features[0]
+		assert.Equal(t, 50, feature.Progress.PercentComplete)
+		assert.Equal(t, 1, feature.SlippedPhases)
+		require.Len(t, feature.Phases, 2)
+		assert.Equal(t, "phase-1", feature.Phases[0].Uuid)
+		assert.Equal(t, 50, feature.Phases[0].Progress.PercentComplete)
+		assert.True(t, feature.Phases[0].Slipped)
+		assert.Equal(t, 3, feature.Phases[0].DaysLate)
+		assert.False(t, feature.Phases[1].Slipped)
+		require.NotNil(t, roadmap.CalendarCreatedAt)
+	})
+
+	t.Run("Only Members Read It", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: "owner-pubkey"})
+		mockDb.On("GetWorkspaceUser", "test-pubkey", "workspace-1").Return(db.WorkspaceUsers{})
+
+		rr := httptest.NewRecorder()
+		NewFeatureHandler(mockDb).GetWorkspaceRoadmap(rr, newBranchRequest(http.MethodGet, "/features/roadmap/workspace-1", "", map[string]string{"workspace_uuid": "workspace-1"}))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Workspace Not Found", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetWorkspaceByUuid", "missing").Return(db.Workspace{})
+
+		rr := httptest.NewRecorder()
+		NewFeatureHandler(mockDb).GetWorkspaceRoadmap(rr, newBranchRequest(http.MethodGet, "/features/roadmap/missing", "", map[string]string{"workspace_uuid": "missing"}))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestGetWorkspaceRoadmapCalendar(t *testing.T) {
+	calendar := func(handler *featureHandler, token string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.GetWorkspaceRoadmapCalendar(rr, newBranchRequest(http.MethodGet, "/features/roadmap/workspace-1/calendar.ics?token="+token, "", map[string]string{"workspace_uuid": "workspace-1"}))
+		return rr
+	}
+
+	t.Run("Rejects Bad Tokens", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetRoadmapCalendar", "workspace-1").Return(db.RoadmapCalendar{WorkspaceUuid: "workspace-1", TokenHash: hashShareToken("the-token")}, nil)
+
+		rr := calendar(NewFeatureHandler(mockDb), "not-the-token")
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Revoked Calendar", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetRoadmapCalendar", "workspace-1").Return(db.RoadmapCalendar{}, errors.New("roadmap calendar not found"))
+
+		rr := calendar(NewFeatureHandler(mockDb), "the-token")
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Lists Phase Deadlines", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		target := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+		mockWorkspaceRoadmap(mockDb, target)
+		mockDb.On("GetRoadmapCalendar", "workspace-1").Return(db.RoadmapCalendar{WorkspaceUuid: "workspace-1", TokenHash: hashShareToken("the-token")}, nil)
+
+		rr := calendar(NewFeatureHandler(mockDb), "the-token")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/calendar; charset=utf-8", rr.Header().Get("Content-Type"))
+		body := rr.Body.String()
+		assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+		assert.True(t, strings.HasSuffix(body, "END:VCALENDAR\r\n"))
+		assert.Contains(t, body, "X-WR-CALNAME:Sphinx\\, Inc roadmap\r\n")
+		assert.Equal(t, 1, strings.Count(body, "BEGIN:VEVENT"))
+		assert.Contains(t, body, "UID:phase-phase-1@workspace-1\r\n")
+		assert.Contains(t, body, "DTSTART;VALUE=DATE:20260320\r\n")
+		assert.Contains(t, body, "DTEND;VALUE=DATE:20260321\r\n")
+		assert.Contains(t, body, "SUMMARY:Payments: Invoices due\r\n")
+		assert.Contains(t, body, "DESCRIPTION:50% complete")
+	})
+}
+
+func TestCreateRoadmapCalendar(t *testing.T) {
+	params := map[string]string{"workspace_uuid": "workspace-1"}
+
+	t.Run("Replaces The Token", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: "owner-pubkey"})
+		mockDb.On("GetWorkspaceUser", "test-pubkey", "workspace-1").Return(db.WorkspaceUsers{OwnerPubKey: "test-pubkey"})
+		var saved db.RoadmapCalendar
+		mockDb.On("SaveRoadmapCalendar", mock.AnythingOfType("*db.RoadmapCalendar")).Run(func(args mock.Arguments) {
+			saved = *args.Get(0).(*db.RoadmapCalendar)
+		}).Return(db.RoadmapCalendar{}, nil)
+
+		rr := httptest.NewRecorder()
+		NewFeatureHandler(mockDb).CreateRoadmapCalendar(rr, newBranchRequest(http.MethodPost, "/features/roadmap/workspace-1/calendar", "", params))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response map[string]string
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		parsed, err := url.Parse(response["calendar_url"])
+		require.NoError(t, err)
+		assert.Equal(t, "/features/roadmap/workspace-1/calendar.ics", parsed.Path)
+		token := parsed.Query().Get("token")
+		require.NotEmpty(t, token)
+		assert.Equal(t, hashShareToken(token), saved.TokenHash)
+		assert.Equal(t, "test-pubkey", saved.CreatedBy)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: "test-pubkey"})
+		mockDb.On("DeleteRoadmapCalendar", "workspace-1").Return(nil)
+
+		rr := httptest.NewRecorder()
+		NewFeatureHandler(mockDb).DeleteRoadmapCalendar(rr, newBranchRequest(http.MethodDelete, "/features/roadmap/workspace-1/calendar", "", params))
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Only Members", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: "owner-pubkey"})
+		mockDb.On("GetWorkspaceUser", "test-pubkey", "workspace-1").Return(db.WorkspaceUsers{})
+
+		rr := httptest.NewRecorder()
+		NewFeatureHandler(mockDb).CreateRoadmapCalendar(rr, newBranchRequest(http.MethodPost, "/features/roadmap/workspace-1/calendar", "", params))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestFoldICalLine(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("é", 40)
+
+	folded := foldICalLine(line)
+
+	parts := strings.Split(folded, "\r\n ")
+	require.Len(t, parts, 2)
+	assert.LessOrEqual(t, len(parts[0]), 75)
+	assert.Equal(t, line, strings.Join(parts, ""))
+	assert.Equal(t, "SUMMARY:short", foldICalLine("SUMMARY:short"))
+}
+
+func TestCreateOrEditFeaturePhaseDates(t *testing.T) {
+	mockDb := datamocks.NewDatabase(t)
+	mockDb.On("GetFeaturePhaseByUuid", "feature-1", "phase-1").Return(db.FeaturePhase{}, nil)
+
+	rr := httptest.NewRecorder()
+	body := `{"uuid":"phase-1","feature_uuid":"feature-1","name":"Invoices","start_date":"2026-03-20T00:00:00Z","target_date":"2026-03-01T00:00:00Z"}`
+	NewFeatureHandler(mockDb).CreateOrEditFeaturePhase(rr, newBranchRequest(http.MethodPost, "/features/phase", body, nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockDb.AssertNotCalled(t, "CreateOrEditFeaturePhase", mock.Anything)
+}
+
+func TestCreateOrEditFeaturePhaseClearsDates(t *testing.T) {
+	mockDb := datamocks.NewDatabase(t)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	target := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	existing := db.FeaturePhase{Uuid: "phase-1", FeatureUuid: "feature-1", Name: "Invoices", CreatedBy: "test-pubkey", StartDate: &start, TargetDate: &target}
+	mockDb.On("GetFeaturePhaseByUuid", "feature-1", "phase-1").Return(existing, nil)
+	mockDb.On("GetFeatureByUuid", "feature-1").Return(db.WorkspaceFeatures{Uuid: "feature-1"})
+	mockDb.On("CreateOrEditFeaturePhase", mock.Anything).Return(existing, nil)
+	mockDb.On("SetFeaturePhaseRoadmapDates", "phase-1", &start, (*time.Time)(nil)).Return(db.FeaturePhase{Uuid: "phase-1", StartDate: &start}, nil)
+
+	rr := httptest.NewRecorder()
+	body := `{"uuid":"phase-1","feature_uuid":"feature-1","name":"Invoices","target_date":null}`
+	NewFeatureHandler(mockDb).CreateOrEditFeaturePhase(rr, newBranchRequest(http.MethodPost, "/features/phase", body, nil))
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var phase db.FeaturePhase
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&phase))
+	assert.Nil(t, phase.TargetDate)
+}
+
+func TestRoadmapDateFields(t *testing.T) {
+	start, target := roadmapDateFields([]byte(`{"name":"x","target_date":null}`))
+	assert.False(t, start)
+	assert.True(t, target)
+
+	start, target = roadmapDateFields([]byte(`{"name":"x"}`))
+	assert.False(t, start)
+	assert.False(t, target)
+}
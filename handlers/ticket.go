@@ -13,7 +13,9 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/google/uuid"
 	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
 	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/knowledge"
 	"github.com/stakwork/sphinx-tribes/logger"
 	"github.com/stakwork/sphinx-tribes/utils"
 	"github.com/stakwork/sphinx-tribes/websocket"
@@ -22,6 +24,8 @@ import (
 type ticketHandler struct {
 	httpClient HttpClient
 	db         db.Database
+	knowledge  *knowledge.Base
+	notify     func(pubkey, event, content, alias, routeHint string) string
 }
 
 type TicketResponse struct {
@@ -39,9 +43,15 @@ type StakworkResponse struct {
 }
 
 func NewTicketHandler(httpClient HttpClient, database db.Database) *ticketHandler {
+	bot := config.Current().V2Bot
+	notify := func(pubkey, event, content, alias, routeHint string) string {
+		return processNotification(bot, pubkey, event, content, alias, routeHint)
+	}
 	return &ticketHandler{
 		httpClient: httpClient,
 		db:         database,
+		knowledge:  knowledge.Default,
+		notify:     notify,
 	}
 }
 
@@ -51,6 +61,9 @@ type UpdateTicketRequest struct {
 		ID     string `json:"id"`
 	} `json:"metadata"`
 	Ticket *db.Tickets `json:"ticket"`
+	// TemplateID names a ticket template applied when the ticket is created.
+	TemplateID string            `json:"template_id,omitempty"`
+	Variables  map[string]string `json:"variables,omitempty"`
 }
 
 type UpdateTicketSequenceRequest struct {
@@ -65,10 +78,14 @@ type CreateBountyResponse struct {
 
 type BulkTicketToBountyRequest struct {
 	TicketsToBounties []TicketToBountyItem `json:"tickets_to_bounties"`
+	// TemplateID names the bounty template of every item that does not name
+	// its own; the workspace default is used otherwise.
+	TemplateID string `json:"template_id,omitempty"`
 }
 
 type TicketToBountyItem struct {
 	TicketUUID string `json:"ticketUUID"`
+	TemplateID string `json:"template_id,omitempty"`
 }
 
 type BulkConversionResult struct {
@@ -87,6 +104,9 @@ type CreateOrEditTicket struct {
 	Name        string          `json:"name"`
 	Description string          `json:"description"`
 	Status      db.TicketStatus `json:"status,omitempty"`
+	// TemplateID names a ticket template applied when the draft is created.
+	TemplateID string            `json:"template_id,omitempty"`
+	Variables  map[string]string `json:"variables,omitempty"`
 }
 
 // GetTicket godoc
@@ -200,6 +220,21 @@ func (th *ticketHandler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 			Version:     1,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
+			DependsOn:   updateRequest.Ticket.DependsOn,
+			Assignee:    updateRequest.Ticket.Assignee,
+		}
+		if updateRequest.TemplateID != "" {
+			feature := th.db.GetFeatureByUuid(newTicket.FeatureUUID)
+			template, checkErr := resolveWorkspaceTemplate(th.db, feature.WorkspaceUuid, db.TicketTemplateKind, updateRequest.TemplateID)
+			if checkErr != nil {
+				checkErr.write(w)
+				return
+			}
+			vars := map[string]string{"feature": feature.Name}
+			for key, value := range updateRequest.Variables {
+				vars[key] = value
+			}
+			db.ApplyTicketTemplate(&newTicket, template, vars)
 		}
 	} else {
 
@@ -220,10 +255,52 @@ func (th *ticketHandler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 			AuthorID:    updateRequest.Ticket.AuthorID,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
+			DependsOn:   updateRequest.Ticket.DependsOn,
+			Assignee:    updateRequest.Ticket.Assignee,
+		}
+		// dependencies only point at tickets of the same phase, so a moved
+		// ticket starts without any; otherwise clients that do not send
+		// dependencies keep the existing ones
+		if movedTicket(existingTicket, newTicket) {
+			newTicket.DependsOn = nil
+		} else if newTicket.DependsOn == nil {
+			newTicket.DependsOn = existingTicket.DependsOn
 		}
+		if newTicket.Assignee == "" {
+			newTicket.Assignee = existingTicket.Assignee
+		}
+		newTicket.GithubIssueURL = existingTicket.GithubIssueURL
 	}
 
-	createdTicket, err := th.db.CreateOrEditTicket(&newTicket)
+	if newTicket.Author == nil {
+		author := db.HumanAuthor
+		newTicket.Author = &author
+		newTicket.AuthorID = &pubKeyFromAuth
+	}
+
+	if !th.checkTicketDependencies(w, &newTicket) {
+		return
+	}
+	if !th.checkTicketWorkflow(w, &newTicket, existingTicket.Status) {
+		return
+	}
+
+	var createdTicket db.Tickets
+	save := func(tx db.Database) (err error) {
+		createdTicket, err = tx.CreateOrEditTicket(&newTicket)
+		return err
+	}
+	if existingTicket.UUID != uuid.Nil && movedTicket(existingTicket, newTicket) {
+		// tickets left behind must stop depending on the moved one
+		err = th.db.InTransaction(func(tx db.Database) error {
+			if err := save(tx); err != nil {
+				return err
+			}
+			return releaseTicketGroup(tx, existingTicket, pubKeyFromAuth, "blocker left the phase")
+		})
+	} else {
+		err = save(th.db)
+	}
 	if err != nil {
 		if err.Error() == "feature_uuid, phase_uuid, and name are required" {
 			w.WriteHeader(http.StatusBadRequest)
@@ -382,7 +459,11 @@ func (th *ticketHandler) DeleteTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := th.db.DeleteTicketGroup(*ticket.TicketGroup); err != nil {
+	if ticket.TicketGroup == nil {
+		ticket.TicketGroup = &ticket.UUID
+	}
+
+	if err := deleteTicketGroup(th.db, ticket, pubKeyFromAuth, "blocker was deleted"); err != nil {
 		logger.Log.Error("failed to delete ticket group",
 			"error", err,
 			"ticket_group", ticket.TicketGroup)
@@ -576,6 +657,8 @@ func (th *ticketHandler) PostTicketDataToStakwork(w http.ResponseWriter, r *http
 		return
 	}
 
+	knowledgeItems := knowledgeContext(th.knowledge, feature.WorkspaceUuid, ticket.Name+"\n"+ticket.Description, nil)
+
 	mode := "thinking"
    if ticketRequest.Ticket.Mode != "" {
       mode = ticketRequest.Ticket.Mode
@@ -608,6 +691,7 @@ func (th *ticketHandler) PostTicketDataToStakwork(w http.ResponseWriter, r *http
 						"codeGraphAlias":      codeGraphAlias,
 						"alias":               user.OwnerAlias,
 						"mode":                mode,
+						"knowledgeContext":    knowledgeItems,
 					},
 				},
 			},
@@ -808,7 +892,13 @@ func (th *ticketHandler) ProcessTicketReview(w http.ResponseWriter, r *http.Requ
 		Category:    existingTicket.Category,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
+		DependsOn:   existingTicket.DependsOn,
+		Assignee:    existingTicket.Assignee,
 	}
+	newTicket.GithubIssueURL = existingTicket.GithubIssueURL
+	agent := db.AgentAuthor
+	newTicket.Author = &agent
+	newTicket.RevisionNote = "agent review"
 
 	if reviewReq.Value.TicketName == "" {
 		newTicket.Name = existingTicket.Name
@@ -907,6 +997,7 @@ func (th *ticketHandler) GetTicketsByPhaseUUID(w http.ResponseWriter, r *http.Re
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
+	th.addCommentCounts(tickets)
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(tickets)
@@ -950,11 +1041,17 @@ func (th *ticketHandler) TicketToBounty(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	template, checkErr := resolveWorkspaceTemplate(th.db, ticketWorkspace(th.db, ticket), db.BountyTemplateKind, r.URL.Query().Get("template_id"))
+	if checkErr != nil {
+		http.Error(w, checkErr.message, checkErr.status)
+		return
+	}
+
 	logger.Log.Info("creating bounty from ticket",
 		"ticket_uuid", ticketUUID,
 		"pubkey", pubKeyFromAuth)
 
-	bounty, err := th.db.CreateBountyFromTicket(ticket, pubKeyFromAuth)
+	bounty, err := th.db.CreateBountyFromTicket(ticket, pubKeyFromAuth, template)
 	if err != nil {
 		logger.Log.Error("failed to create bounty",
 			"error", err,
@@ -1100,6 +1197,15 @@ func (th *ticketHandler) CreateWorkspaceDraftTicket(w http.ResponseWriter, r *ht
 		Status:        db.DraftTicket,
 	}
 
+	if ticketRequest.TemplateID != "" {
+		template, checkErr := resolveWorkspaceTemplate(th.db, workspaceUuid, db.TicketTemplateKind, ticketRequest.TemplateID)
+		if checkErr != nil {
+			checkErr.write(w)
+			return
+		}
+		db.ApplyTicketTemplate(ticket, template, ticketRequest.Variables)
+	}
+
 	createdTicket, err := th.db.CreateWorkspaceDraftTicket(ticket)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -1219,12 +1325,11 @@ func (th *ticketHandler) UpdateWorkspaceDraftTicket(w http.ResponseWriter, r *ht
 		existingTicket.Description = ticketRequest.Description
 	}
 	if ticketRequest.Status != "" {
-		if !db.IsValidTicketStatus(ticketRequest.Status) {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]string{"error": "invalid ticket status"})
+		previousStatus := existingTicket.Status
+		existingTicket.Status = ticketRequest.Status
+		if !th.checkTicketWorkflow(w, &existingTicket, previousStatus) {
 			return
 		}
-		existingTicket.Status = ticketRequest.Status
 	}
 
 	updatedTicket, err := th.db.UpdateWorkspaceDraftTicket(&existingTicket)
@@ -1377,7 +1482,18 @@ func (th *ticketHandler) TicketsToBounties(w http.ResponseWriter, r *http.Reques
 			continue
 		}
 
-		bounty, err := th.db.CreateBountyFromTicket(ticket, pubKeyFromAuth)
+		templateID := item.TemplateID
+		if templateID == "" {
+			templateID = req.TemplateID
+		}
+		template, checkErr := resolveWorkspaceTemplate(th.db, ticketWorkspace(th.db, ticket), db.BountyTemplateKind, templateID)
+		if checkErr != nil {
+			result.Message = fmt.Sprintf("Failed to load bounty template: %s", checkErr.message)
+			results = append(results, result)
+			continue
+		}
+
+		bounty, err := th.db.CreateBountyFromTicket(ticket, pubKeyFromAuth, template)
 		if err != nil {
 			result.Message = fmt.Sprintf("Failed to create bounty: %v", err)
 			results = append(results, result)
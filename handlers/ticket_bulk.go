@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// maxBulkTicketOperations caps the operations of a single bulk request.
+const maxBulkTicketOperations = 200
+
+type BulkTicketAction string
+
+const (
+	BulkUpdateTicket   BulkTicketAction = "update"
+	BulkTicketStatus   BulkTicketAction = "status"
+	BulkMoveTicket     BulkTicketAction = "move"
+	BulkReassignTicket BulkTicketAction = "reassign"
+	BulkDeleteTicket   BulkTicketAction = "delete"
+	BulkTicketToBounty BulkTicketAction = "to_bounty"
+)
+
+// BulkTicketOperation is one change to a ticket. Only the fields of its
+// action are read; omitted fields keep their value.
+type BulkTicketOperation struct {
+	TicketUUID  string           `json:"ticket_uuid"`
+	Action      BulkTicketAction `json:"action"`
+	Name        *string          `json:"name,omitempty"`
+	Description *string          `json:"description,omitempty"`
+	Amount      *int64           `json:"amount,omitempty"`
+	Category    *db.Category     `json:"category,omitempty"`
+	Status      db.TicketStatus  `json:"status,omitempty"`
+	FeatureUUID string           `json:"feature_uuid,omitempty"`
+	PhaseUUID   string           `json:"phase_uuid,omitempty"`
+	Assignee    *string          `json:"assignee,omitempty"`
+	// TemplateID names the bounty template of a to_bounty operation.
+	TemplateID string `json:"template_id,omitempty"`
+}
+
+type BulkTicketRequest struct {
+	Operations []BulkTicketOperation `json:"operations"`
+	// Atomic applies every operation or none of them.
+	Atomic bool `json:"atomic"`
+}
+
+type BulkTicketResult struct {
+	Index      int              `json:"index"`
+	TicketUUID string           `json:"ticket_uuid"`
+	Action     BulkTicketAction `json:"action"`
+	Success    bool             `json:"success"`
+	Error      string           `json:"error,omitempty"`
+	Ticket     *db.Tickets      `json:"ticket,omitempty"`
+	BountyID   uint             `json:"bounty_id,omitempty"`
+}
+
+type BulkTicketResponse struct {
+	Success    bool               `json:"success"`
+	RolledBack bool               `json:"rolled_back"`
+	Results    []BulkTicketResult `json:"results"`
+}
+
+// BulkTicketOperations godoc
+//
+//	@Summary		Apply operations to many tickets
+//	@Description	Update, change the status of, move, reassign, delete or convert tickets to bounties in one request. With atomic set, every operation is applied in one transaction that is rolled back when any of them fails.
+//	@Tags			Bounty Tickets
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			request	body		BulkTicketRequest	true	"Bulk Ticket Request"
+//	@Success		200		{object}	BulkTicketResponse
+//	@Failure		403		{object}	map[string]string
+//	@Failure		422		{object}	BulkTicketResponse
+//	@Router			/bounties/ticket/bulk [post]
+func (th *ticketHandler) BulkTicketOperations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[ticket] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	var req BulkTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error parsing request body"})
+		return
+	}
+	if len(req.Operations) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "at least one operation is required"})
+		return
+	}
+	if len(req.Operations) > maxBulkTicketOperations {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("at most %d operations are allowed", maxBulkTicketOperations)})
+		return
+	}
+
+	if workspaceUuid, ok := bulkTicketWorkspacesAllowed(th.db, req.Operations, pubKeyFromAuth); !ok {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("only members of workspace %s can change its tickets", workspaceUuid)})
+		return
+	}
+
+	results := make([]BulkTicketResult, len(req.Operations))
+	for i, op := range req.Operations {
+		results[i] = BulkTicketResult{Index: i, TicketUUID: op.TicketUUID, Action: op.Action}
+	}
+
+	response := BulkTicketResponse{Results: results}
+	if req.Atomic {
+		failed := -1
+		err := th.db.InTransaction(func(tx db.Database) error {
+			for i, op := range req.Operations {
+				if err := applyBulkTicketOperation(tx, op, pubKeyFromAuth, &results[i]); err != nil {
+					failed = i
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			if failed < 0 {
+				logger.Log.Error("[ticket] bulk transaction failed: %v", err)
+			}
+			for i := range results {
+				switch {
+				case failed < 0 || i == failed:
+					results[i].Error = err.Error()
+				case i < failed:
+					results[i].Error = "rolled back"
+				default:
+					results[i].Error = "not applied"
+				}
+				results[i].Success = false
+				results[i].Ticket = nil
+				results[i].BountyID = 0
+			}
+			response.RolledBack = true
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		response.Success = true
+	} else {
+		response.Success = true
+		for i, op := range req.Operations {
+			if err := applyBulkTicketOperation(th.db, op, pubKeyFromAuth, &results[i]); err != nil {
+				results[i].Error = err.Error()
+				response.Success = false
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// bulkTicketWorkspacesAllowed checks that pubkey is a member of the workspace
+// of every ticket the operations touch, and of the feature a ticket is moved
+// to. It returns the first workspace pubkey may not change. Tickets that
+// cannot be loaded are left to fail with their operation.
+func bulkTicketWorkspacesAllowed(database db.Database, ops []BulkTicketOperation, pubkey string) (string, bool) {
+	checked := map[string]bool{}
+	allowed := func(workspaceUuid string) bool {
+		if member, ok := checked[workspaceUuid]; ok {
+			return member
+		}
+		workspace := database.GetWorkspaceByUuid(workspaceUuid)
+		member := workspace.OwnerPubKey == pubkey || database.GetWorkspaceUser(pubkey, workspaceUuid).OwnerPubKey == pubkey
+		checked[workspaceUuid] = member
+		return member
+	}
+
+	for _, op := range ops {
+		if _, err := uuid.Parse(op.TicketUUID); err != nil {
+			continue
+		}
+		ticket, err := database.GetTicket(op.TicketUUID)
+		if err != nil {
+			continue
+		}
+		workspaces := []string{ticketWorkspace(database, ticket)}
+		if op.Action == BulkMoveTicket && op.FeatureUUID != "" && op.FeatureUUID != ticket.FeatureUUID {
+			workspaces = append(workspaces, ticketWorkspace(database, db.Tickets{FeatureUUID: op.FeatureUUID}))
+		}
+		for _, workspaceUuid := range workspaces {
+			if !allowed(workspaceUuid) {
+				return workspaceUuid, false
+			}
+		}
+	}
+	return "", true
+}
+
+// applyBulkTicketOperation applies one operation and records its outcome in
+// result. Edits are saved as a new version of the ticket and go through the
+// same dependency and workflow checks as UpdateTicket.
+func applyBulkTicketOperation(database db.Database, op BulkTicketOperation, pubkey string, result *BulkTicketResult) error {
+	if _, err := uuid.Parse(op.TicketUUID); err != nil {
+		return errors.New("invalid ticket UUID format")
+	}
+	ticket, err := database.GetTicket(op.TicketUUID)
+	if err != nil {
+		return errors.New("ticket not found")
+	}
+	if ticket.TicketGroup == nil {
+		ticket.TicketGroup = &ticket.UUID
+	}
+
+	switch op.Action {
+	case BulkDeleteTicket:
+		if err := deleteTicketGroup(database, ticket, pubkey, "bulk delete: blocker was deleted"); err != nil {
+			return err
+		}
+		result.Success = true
+		return nil
+	case BulkTicketToBounty:
+		template, checkErr := resolveWorkspaceTemplate(database, ticketWorkspace(database, ticket), db.BountyTemplateKind, op.TemplateID)
+		if checkErr != nil {
+			return checkErr
+		}
+		bounty, err := database.CreateBountyFromTicket(ticket, pubkey, template)
+		if err != nil {
+			return err
+		}
+		result.BountyID = bounty.ID
+		if err := deleteTicketGroup(database, ticket, pubkey, "bulk to_bounty: blocker became a bounty"); err != nil {
+			return err
+		}
+		result.Success = true
+		return nil
+	}
+
+	next := ticket
+	next.UUID = uuid.New()
+	next.Features = db.WorkspaceFeatures{}
+	next.FeaturePhase = db.FeaturePhase{}
+	next.Version = ticket.Version + 1
+	next.CreatedAt = time.Now()
+	next.UpdatedAt = time.Now()
+	next.RevisionNote = fmt.Sprintf("bulk %s", op.Action)
+	author := db.HumanAuthor
+	next.Author = &author
+	next.AuthorID = &pubkey
+
+	switch op.Action {
+	case BulkUpdateTicket:
+		if op.Name == nil && op.Description == nil && op.Amount == nil && op.Category == nil {
+			return errors.New("update needs at least one of name, description, amount or category")
+		}
+		if op.Name != nil {
+			if strings.TrimSpace(*op.Name) == "" {
+				return errors.New("name cannot be empty")
+			}
+			next.Name = *op.Name
+		}
+		if op.Description != nil {
+			next.Description = *op.Description
+		}
+		if op.Amount != nil {
+			next.Amount = op.Amount
+		}
+		if op.Category != nil {
+			next.Category = op.Category
+		}
+	case BulkTicketStatus:
+		if op.Status == "" {
+			return errors.New("status is required")
+		}
+		next.Status = op.Status
+	case BulkMoveTicket:
+		if op.PhaseUUID == "" {
+			return errors.New("phase_uuid is required")
+		}
+		if op.FeatureUUID != "" {
+			next.FeatureUUID = op.FeatureUUID
+		}
+		if feature := database.GetFeatureByUuid(next.FeatureUUID); feature.Uuid == "" {
+			return errors.New("feature not found")
+		}
+		if _, err := database.GetFeaturePhaseByUuid(next.FeatureUUID, op.PhaseUUID); err != nil {
+			return errors.New("phase not found")
+		}
+		// dependencies only point at tickets of the same phase
+		if next.FeatureUUID != ticket.FeatureUUID || op.PhaseUUID != ticket.PhaseUUID {
+			next.DependsOn = nil
+		}
+		next.PhaseUUID = op.PhaseUUID
+	case BulkReassignTicket:
+		if op.Assignee == nil {
+			return errors.New("assignee is required")
+		}
+		if *op.Assignee != "" {
+			if person := database.GetPersonByPubkey(*op.Assignee); person.OwnerPubKey == "" {
+				return errors.New("assignee not found")
+			}
+		}
+		next.Assignee = *op.Assignee
+	default:
+		return fmt.Errorf("unknown action %q", op.Action)
+	}
+
+	if err := ticketDependencyError(database, &next); err != nil {
+		return err
+	}
+	if err := ticketWorkflowError(database, &next, ticket.Status); err != nil {
+		return err
+	}
+
+	var saved db.Tickets
+	save := func(tx db.Database) (err error) {
+		saved, err = tx.CreateOrEditTicket(&next)
+		return err
+	}
+	if movedTicket(ticket, next) {
+		// tickets left behind must stop depending on the moved one
+		err = database.InTransaction(func(tx db.Database) error {
+			if err := save(tx); err != nil {
+				return err
+			}
+			return releaseTicketGroup(tx, ticket, pubkey, "bulk move: blocker left the phase")
+		})
+	} else {
+		err = save(database)
+	}
+	if err != nil {
+		return err
+	}
+	result.Ticket = &saved
+	result.Success = true
+	return nil
+}
+
+// movedTicket reports whether next belongs to another phase than ticket.
+func movedTicket(ticket db.Tickets, next db.Tickets) bool {
+	return next.FeatureUUID != ticket.FeatureUUID || next.PhaseUUID != ticket.PhaseUUID
+}
+
+// deleteTicketGroup deletes every version of ticket and drops it from the
+// dependencies of the rest of its phase.
+func deleteTicketGroup(database db.Database, ticket db.Tickets, pubkey string, note string) error {
+	return database.InTransaction(func(tx db.Database) error {
+		if err := tx.DeleteTicketGroup(*ticket.TicketGroup); err != nil {
+			return err
+		}
+		return releaseTicketGroup(tx, ticket, pubkey, note)
+	})
+}
+
+// releaseTicketGroup saves a new version of every ticket of the phase of
+// released that depended on it, without that dependency. It is called when
+// released is moved out of the phase or deleted.
+func releaseTicketGroup(database db.Database, released db.Tickets, pubkey string, note string) error {
+	tickets, err := database.GetTicketsByPhaseUUID(released.FeatureUUID, released.PhaseUUID)
+	if err != nil {
+		return err
+	}
+	group := db.TicketGroupID(released)
+	for _, ticket := range db.LatestTickets(tickets) {
+		if db.TicketGroupID(ticket) == group {
+			continue
+		}
+		dependsOn := make(pq.StringArray, 0, len(ticket.DependsOn))
+		for _, dep := range ticket.DependsOn {
+			if dep != group {
+				dependsOn = append(dependsOn, dep)
+			}
+		}
+		if len(dependsOn) == len(ticket.DependsOn) {
+			continue
+		}
+
+		next := ticket
+		next.UUID = uuid.New()
+		next.Features = db.WorkspaceFeatures{}
+		next.FeaturePhase = db.FeaturePhase{}
+		next.DependsOn = dependsOn
+		next.Version = ticket.Version + 1
+		next.CreatedAt = time.Now()
+		next.UpdatedAt = time.Now()
+		next.RevisionNote = note
+		author := db.HumanAuthor
+		next.Author = &author
+		next.AuthorID = &pubkey
+		if _, err := database.CreateOrEditTicket(&next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
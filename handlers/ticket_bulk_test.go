@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkTicketOperations(t *testing.T) {
+	bulk := func(th *ticketHandler, req BulkTicketRequest) (*httptest.ResponseRecorder, BulkTicketResponse) {
+		body, _ := json.Marshal(req)
+		rr := httptest.NewRecorder()
+		th.BulkTicketOperations(rr, newBranchRequest(http.MethodPost, "/bounties/ticket/bulk", string(body), nil))
+		var response BulkTicketResponse
+		json.NewDecoder(rr.Body).Decode(&response)
+		return rr, response
+	}
+	inTransaction := func(mockDb *datamocks.Database) {
+		mockDb.On("InTransaction", mock.Anything).Return(func(fn func(db.Database) error) error { return fn(mockDb) })
+	}
+	// the caller owns workspace-1, the workspace of every ticket
+	member := func(mockDb *datamocks.Database) {
+		mockDb.On("GetFeatureByUuid", "feature-1").Return(db.WorkspaceFeatures{Uuid: "feature-1", WorkspaceUuid: "workspace-1"}).Maybe()
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: "test-pubkey"})
+	}
+	saveTicket := func(mockDb *datamocks.Database) {
+		mockDb.On("CreateOrEditTicket", mock.AnythingOfType("*db.Tickets")).Return(func(ticket *db.Tickets) db.Tickets { return *ticket }, nil)
+	}
+
+	t.Run("Empty Request", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+
+		rr, _ := bulk(th, BulkTicketRequest{})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Reports Each Item", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		member(mockDb)
+		ticket := newGraphTicket(db.DraftTicket)
+		missing := newGraphTicket(db.DraftTicket)
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockDb.On("GetTicket", missing.UUID.String()).Return(db.Tickets{}, errors.New("ticket not found"))
+		mockTicketWorkflow(mockDb, db.DefaultTicketWorkflow("workspace-1"))
+		saveTicket(mockDb)
+
+		rr, response := bulk(th, BulkTicketRequest{Operations: []BulkTicketOperation{
+			{TicketUUID: ticket.UUID.String(), Action: BulkTicketStatus, Status: db.ReadyTicket},
+			{TicketUUID: missing.UUID.String(), Action: BulkDeleteTicket},
+		}})
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.False(t, response.Success)
+		require.Len(t, response.Results, 2)
+		assert.True(t, response.Results[0].Success)
+		assert.Equal(t, db.ReadyTicket, response.Results[0].Ticket.Status)
+		assert.Equal(t, 2, response.Results[0].Ticket.Version)
+		assert.Equal(t, *ticket.TicketGroup, *response.Results[0].Ticket.TicketGroup)
+		assert.False(t, response.Results[1].Success)
+		assert.Equal(t, "ticket not found", response.Results[1].Error)
+	})
+
+	t.Run("Atomic Rolls Back On Failure", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		member(mockDb)
+		first := newGraphTicket("BACKLOG")
+		second := newGraphTicket("BACKLOG")
+		name := "Renamed"
+		inTransaction(mockDb)
+		mockDb.On("GetTicket", first.UUID.String()).Return(first, nil)
+		mockDb.On("GetTicket", second.UUID.String()).Return(second, nil)
+		mockTicketWorkflow(mockDb, kanbanWorkflow())
+		saveTicket(mockDb)
+
+		rr, response := bulk(th, BulkTicketRequest{Atomic: true, Operations: []BulkTicketOperation{
+			{TicketUUID: first.UUID.String(), Action: BulkUpdateTicket, Name: &name},
+			{TicketUUID: second.UUID.String(), Action: BulkTicketStatus, Status: "DONE"},
+			{TicketUUID: first.UUID.String(), Action: BulkDeleteTicket},
+		}})
+
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.True(t, response.RolledBack)
+		assert.False(t, response.Success)
+		require.Len(t, response.Results, 3)
+		assert.Equal(t, "rolled back", response.Results[0].Error)
+		assert.Nil(t, response.Results[0].Ticket)
+		assert.Equal(t, "ticket cannot move from BACKLOG to DONE", response.Results[1].Error)
+		assert.Equal(t, "not applied", response.Results[2].Error)
+		mockDb.AssertNotCalled(t, "DeleteTicketGroup", mock.Anything)
+	})
+
+	t.Run("Atomic Reassign Move And Convert", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		member(mockDb)
+		blocker := newGraphTicket(db.DraftTicket)
+		moved := newGraphTicket(db.DraftTicket, blocker.TicketGroup.String())
+		converted := newGraphTicket(db.ReadyTicket)
+		assignee := "dev-pubkey"
+		inTransaction(mockDb)
+		mockDb.On("GetTicket", moved.UUID.String()).Return(moved, nil)
+		mockDb.On("GetTicket", converted.UUID.String()).Return(converted, nil)
+		mockDb.On("GetPersonByPubkey", assignee).Return(db.Person{OwnerPubKey: assignee})
+		mockDb.On("GetFeaturePhaseByUuid", "feature-1", "phase-2").Return(db.FeaturePhase{Uuid: "phase-2"}, nil)
+		mockDb.On("GetTicketsByPhaseUUID", "feature-1", "phase-1").Return([]db.Tickets{blocker, moved}, nil)
+		mockTicketWorkflow(mockDb, db.DefaultTicketWorkflow("workspace-1"))
+		saveTicket(mockDb)
+		mockDb.On("GetDefaultWorkspaceTemplate", "workspace-1", db.BountyTemplateKind).Return(db.BuiltinWorkspaceTemplate("workspace-1", db.BountyTemplateKind), nil)
+		mockDb.On("CreateBountyFromTicket", converted, "test-pubkey", db.BuiltinWorkspaceTemplate("workspace-1", db.BountyTemplateKind)).Return(&db.NewBounty{ID: 42}, nil)
+		mockDb.On("DeleteTicketGroup", *converted.TicketGroup).Return(nil)
+
+		rr, response := bulk(th, BulkTicketRequest{Atomic: true, Operations: []BulkTicketOperation{
+			{TicketUUID: moved.UUID.String(), Action: BulkReassignTicket, Assignee: &assignee},
+			{TicketUUID: moved.UUID.String(), Action: BulkMoveTicket, PhaseUUID: "phase-2"},
+			{TicketUUID: converted.UUID.String(), Action: BulkTicketToBounty},
+		}})
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.True(t, response.Success)
+		assert.False(t, response.RolledBack)
+		assert.Equal(t, assignee, response.Results[0].Ticket.Assignee)
+		assert.Equal(t, "phase-2", response.Results[1].Ticket.PhaseUUID)
+		assert.Empty(t, response.Results[1].Ticket.DependsOn)
+		assert.Equal(t, uint(42), response.Results[2].BountyID)
+	})
+
+	t.Run("Move Releases Dependents Of The Old Phase", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		member(mockDb)
+		moved := newGraphTicket(db.DraftTicket)
+		other := newGraphTicket(db.DraftTicket)
+		dependent := newGraphTicket(db.DraftTicket, moved.TicketGroup.String(), other.TicketGroup.String())
+		inTransaction(mockDb)
+		mockDb.On("GetTicket", moved.UUID.String()).Return(moved, nil)
+		mockDb.On("GetFeaturePhaseByUuid", "feature-1", "phase-2").Return(db.FeaturePhase{Uuid: "phase-2"}, nil)
+		mockDb.On("GetTicketsByPhaseUUID", "feature-1", "phase-1").Return([]db.Tickets{moved, other, dependent}, nil)
+		mockTicketWorkflow(mockDb, db.DefaultTicketWorkflow("workspace-1"))
+		var saved []db.Tickets
+		mockDb.On("CreateOrEditTicket", mock.AnythingOfType("*db.Tickets")).Return(func(ticket *db.Tickets) db.Tickets {
+			saved = append(saved, *ticket)
+			return *ticket
+		}, nil)
+
+		rr, response := bulk(th, BulkTicketRequest{Operations: []BulkTicketOperation{
+			{TicketUUID: moved.UUID.String(), Action: BulkMoveTicket, PhaseUUID: "phase-2"},
+		}})
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.True(t, response.Success)
+		require.Len(t, saved, 2)
+		assert.Equal(t, "phase-2", saved[0].PhaseUUID)
+		assert.Equal(t, *dependent.TicketGroup, *saved[1].TicketGroup)
+		assert.Equal(t, []string{other.TicketGroup.String()}, []string(saved[1].DependsOn))
+		assert.Equal(t, dependent.Version+1, saved[1].Version)
+	})
+
+	t.Run("Delete Releases Dependents", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		member(mockDb)
+		deleted := newGraphTicket(db.DraftTicket)
+		dependent := newGraphTicket(db.DraftTicket, deleted.TicketGroup.String())
+		inTransaction(mockDb)
+		mockDb.On("GetTicket", deleted.UUID.String()).Return(deleted, nil)
+		mockDb.On("DeleteTicketGroup", *deleted.TicketGroup).Return(nil)
+		mockDb.On("GetTicketsByPhaseUUID", "feature-1", "phase-1").Return([]db.Tickets{dependent}, nil)
+		mockDb.On("CreateOrEditTicket", mock.MatchedBy(func(saved *db.Tickets) bool {
+			return *saved.TicketGroup == *dependent.TicketGroup && len(saved.DependsOn) == 0
+		})).Return(func(saved *db.Tickets) db.Tickets { return *saved }, nil)
+
+		rr, response := bulk(th, BulkTicketRequest{Operations: []BulkTicketOperation{
+			{TicketUUID: deleted.UUID.String(), Action: BulkDeleteTicket},
+		}})
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.True(t, response.Success)
+	})
+
+	t.Run("Rejects Tickets Of Other Workspaces", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		mine := newGraphTicket(db.DraftTicket)
+		theirs := newGraphTicket(db.DraftTicket)
+		theirs.FeatureUUID = "feature-2"
+		member(mockDb)
+		mockDb.On("GetFeatureByUuid", "feature-2").Return(db.WorkspaceFeatures{Uuid: "feature-2", WorkspaceUuid: "workspace-2"})
+		mockDb.On("GetWorkspaceByUuid", "workspace-2").Return(db.Workspace{Uuid: "workspace-2", OwnerPubKey: "other-pubkey"})
+		mockDb.On("GetWorkspaceUser", "test-pubkey", "workspace-2").Return(db.WorkspaceUsers{})
+		mockDb.On("GetTicket", mine.UUID.String()).Return(mine, nil)
+		mockDb.On("GetTicket", theirs.UUID.String()).Return(theirs, nil)
+
+		rr, _ := bulk(th, BulkTicketRequest{Operations: []BulkTicketOperation{
+			{TicketUUID: mine.UUID.String(), Action: BulkDeleteTicket},
+			{TicketUUID: theirs.UUID.String(), Action: BulkDeleteTicket},
+		}})
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockDb.AssertNotCalled(t, "DeleteTicketGroup", mock.Anything)
+	})
+
+	t.Run("Rejects Moves Into Other Workspaces", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		ticket := newGraphTicket(db.DraftTicket)
+		member(mockDb)
+		mockDb.On("GetFeatureByUuid", "feature-2").Return(db.WorkspaceFeatures{Uuid: "feature-2", WorkspaceUuid: "workspace-2"})
+		mockDb.On("GetWorkspaceByUuid", "workspace-2").Return(db.Workspace{Uuid: "workspace-2", OwnerPubKey: "other-pubkey"})
+		mockDb.On("GetWorkspaceUser", "test-pubkey", "workspace-2").Return(db.WorkspaceUsers{})
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+
+		rr, _ := bulk(th, BulkTicketRequest{Operations: []BulkTicketOperation{
+			{TicketUUID: ticket.UUID.String(), Action: BulkMoveTicket, FeatureUUID: "feature-2", PhaseUUID: "phase-9"},
+		}})
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Unknown Assignee", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		member(mockDb)
+		ticket := newGraphTicket(db.DraftTicket)
+		assignee := "nobody"
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockDb.On("GetPersonByPubkey", assignee).Return(db.Person{})
+
+		rr, response := bulk(th, BulkTicketRequest{Operations: []BulkTicketOperation{
+			{TicketUUID: ticket.UUID.String(), Action: BulkReassignTicket, Assignee: &assignee},
+		}})
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "assignee not found", response.Results[0].Error)
+	})
+}
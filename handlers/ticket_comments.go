@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/lifecycle"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type TicketCommentRequest struct {
+	Body     string     `json:"body"`
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+}
+
+type ResolveTicketCommentRequest struct {
+	Resolved bool `json:"resolved"`
+}
+
+// commentTarget is a ticket group or ticket plan that comments are made on.
+type commentTarget struct {
+	Type      db.CommentTargetType
+	UUID      string
+	Name      string
+	Workspace string
+}
+
+func (t commentTarget) label() string {
+	if t.Type == db.TicketPlanCommentTarget {
+		return "ticket plan"
+	}
+	return "ticket"
+}
+
+// addCommentCounts sets the comment counts of the ticket groups of tickets.
+// Tickets are still listed when counting fails.
+func (th *ticketHandler) addCommentCounts(tickets []db.Tickets) {
+	if len(tickets) == 0 {
+		return
+	}
+	groups := make([]string, len(tickets))
+	for i, ticket := range tickets {
+		groups[i] = db.TicketGroupID(ticket)
+	}
+	counts, err := th.db.GetTicketCommentCounts(db.TicketCommentTarget, groups)
+	if err != nil {
+		logger.Log.Error("failed to count ticket comments: %v", err)
+		return
+	}
+	for i := range tickets {
+		count := counts[groups[i]]
+		tickets[i].Comments = &count
+	}
+}
+
+// ticketCommentTarget resolves the ticket group of a request, writing the
+// error response when the ticket does not exist.
+func (th *ticketHandler) ticketCommentTarget(w http.ResponseWriter, r *http.Request) (commentTarget, bool) {
+	group, ok := ticketGroupParam(w, r)
+	if !ok {
+		return commentTarget{}, false
+	}
+	ticket, err := th.db.GetLatestTicketByGroup(group)
+	if err != nil {
+		ticket, err = th.db.GetTicket(group.String())
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Ticket not found"})
+		return commentTarget{}, false
+	}
+	return commentTarget{Type: db.TicketCommentTarget, UUID: group.String(), Name: ticket.Name, Workspace: ticket.WorkspaceUuid}, true
+}
+
+// planCommentTarget resolves the ticket plan of a request, writing the error
+// response when the plan does not exist.
+func (th *ticketHandler) planCommentTarget(w http.ResponseWriter, r *http.Request) (commentTarget, bool) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return commentTarget{}, false
+	}
+	plan, err := th.db.GetTicketPlan(chi.URLParam(r, "uuid"))
+	if err != nil || plan == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Plan not found"})
+		return commentTarget{}, false
+	}
+	return commentTarget{Type: db.TicketPlanCommentTarget, UUID: plan.UUID.String(), Name: plan.Name, Workspace: plan.WorkspaceUuid}, true
+}
+
+func (th *ticketHandler) listComments(w http.ResponseWriter, target commentTarget) {
+	comments, err := th.db.GetTicketComments(target.Type, target.UUID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(db.BuildTicketCommentThreads(comments))
+}
+
+func (th *ticketHandler) createComment(w http.ResponseWriter, r *http.Request, target commentTarget) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+
+	var request TicketCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error parsing request body"})
+		return
+	}
+
+	author := th.db.GetPersonByPubkey(pubKeyFromAuth)
+	mentioned := th.commentMentions(request.Body, target)
+
+	comment := db.TicketComment{
+		TargetType:   target.Type,
+		TargetUUID:   target.UUID,
+		ParentID:     request.ParentID,
+		Body:         request.Body,
+		AuthorPubKey: pubKeyFromAuth,
+		AuthorAlias:  author.OwnerAlias,
+		Mentions:     mentionedPubKeys(mentioned),
+	}
+	created, err := th.db.CreateTicketComment(&comment)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	th.notifyMentions(created, mentioned, nil, target)
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// commentMentions resolves the people mentioned in a comment body. Only
+// members of the workspace of the target can be mentioned.
+func (th *ticketHandler) commentMentions(body string, target commentTarget) []db.Person {
+	people, err := th.db.GetPeopleByMentions(db.ParseMentions(body))
+	if err != nil {
+		logger.Log.Error("failed to resolve comment mentions: %v", err)
+		return nil
+	}
+	if len(people) == 0 || target.Workspace == "" {
+		return nil
+	}
+
+	workspace := th.db.GetWorkspaceByUuid(target.Workspace)
+	members := []db.Person{}
+	for _, person := range people {
+		if person.OwnerPubKey == "" {
+			continue
+		}
+		if person.OwnerPubKey == workspace.OwnerPubKey ||
+			th.db.GetWorkspaceUser(person.OwnerPubKey, target.Workspace).OwnerPubKey == person.OwnerPubKey {
+			members = append(members, person)
+		}
+	}
+	return members
+}
+
+func mentionedPubKeys(people []db.Person) []string {
+	pubKeys := []string{}
+	seen := map[string]bool{}
+	for _, person := range people {
+		if person.OwnerPubKey == "" || seen[person.OwnerPubKey] {
+			continue
+		}
+		seen[person.OwnerPubKey] = true
+		pubKeys = append(pubKeys, person.OwnerPubKey)
+	}
+	return pubKeys
+}
+
+// notifyMentions notifies the people mentioned in a comment, except its
+// author and those notified for an earlier version of the comment.
+func (th *ticketHandler) notifyMentions(comment db.TicketComment, mentioned []db.Person, notified []string, target commentTarget) {
+	skip := map[string]bool{comment.AuthorPubKey: true}
+	for _, pubKey := range notified {
+		skip[pubKey] = true
+	}
+
+	author := comment.AuthorAlias
+	if author == "" {
+		author = "Someone"
+	}
+	snippet := comment.Body
+	if runes := []rune(snippet); len(runes) > 200 {
+		snippet = string(runes[:200]) + "..."
+	}
+	content := fmt.Sprintf("%s mentioned you on %s %q: %s", author, target.label(), target.Name, snippet)
+
+	recipients := []db.Person{}
+	for _, person := range mentioned {
+		if person.OwnerPubKey == "" || skip[person.OwnerPubKey] {
+			continue
+		}
+		skip[person.OwnerPubKey] = true
+		recipients = append(recipients, person)
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	// sending waits on the bot for every person, the comment is saved already
+	go lifecycle.Default.RunJob("comment mentions", func() {
+		for _, person := range recipients {
+			th.notify(person.OwnerPubKey, "ticket_mention", content, person.OwnerAlias, person.OwnerRouteHint)
+		}
+	})
+}
+
+// GetTicketComments godoc
+//
+//	@Summary		List ticket comments
+//	@Description	List the comment threads of a ticket group, oldest first
+//	@Tags			Bounty Tickets
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			group_uuid	path	string	true	"Ticket group UUID"
+//	@Success		200			{array}	db.TicketCommentThread
+//	@Router			/bounties/ticket/group/{group_uuid}/comments [get]
+func (th *ticketHandler) GetTicketComments(w http.ResponseWriter, r *http.Request) {
+	target, ok := th.ticketCommentTarget(w, r)
+	if !ok {
+		return
+	}
+	th.listComments(w, target)
+}
+
+// CreateTicketComment godoc
+//
+//	@Summary		Comment on a ticket
+//	@Description	Add a Markdown comment or reply to a ticket group and notify the people it mentions
+//	@Tags			Bounty Tickets
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			group_uuid	path		string					true	"Ticket group UUID"
+//	@Param			comment		body		TicketCommentRequest	true	"Comment"
+//	@Success		201			{object}	db.TicketComment
+//	@Router			/bounties/ticket/group/{group_uuid}/comments [post]
+func (th *ticketHandler) CreateTicketComment(w http.ResponseWriter, r *http.Request) {
+	target, ok := th.ticketCommentTarget(w, r)
+	if !ok {
+		return
+	}
+	th.createComment(w, r, target)
+}
+
+// GetTicketPlanComments godoc
+//
+//	@Summary		List ticket plan comments
+//	@Description	List the comment threads of a ticket plan, oldest first
+//	@Tags			Ticket Plans
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			uuid	path	string	true	"Plan UUID"
+//	@Success		200		{array}	db.TicketCommentThread
+//	@Router			/bounties/ticket/plan/{uuid}/comments [get]
+func (th *ticketHandler) GetTicketPlanComments(w http.ResponseWriter, r *http.Request) {
+	target, ok := th.planCommentTarget(w, r)
+	if !ok {
+		return
+	}
+	th.listComments(w, target)
+}
+
+// CreateTicketPlanComment godoc
+//
+//	@Summary		Comment on a ticket plan
+//	@Description	Add a Markdown comment or reply to a ticket plan and notify the people it mentions
+//	@Tags			Ticket Plans
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			uuid	path		string					true	"Plan UUID"
+//	@Param			comment	body		TicketCommentRequest	true	"Comment"
+//	@Success		201		{object}	db.TicketComment
+//	@Router			/bounties/ticket/plan/{uuid}/comments [post]
+func (th *ticketHandler) CreateTicketPlanComment(w http.ResponseWriter, r *http.Request) {
+	target, ok := th.planCommentTarget(w, r)
+	if !ok {
+		return
+	}
+	th.createComment(w, r, target)
+}
+
+// commentParam loads the comment of a request, writing the error response
+// when it is missing.
+func (th *ticketHandler) commentParam(w http.ResponseWriter, r *http.Request) (db.TicketComment, bool) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return db.TicketComment{}, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "comment_id"))
+	if err != nil {
+		http.Error(w, "invalid comment ID format", http.StatusBadRequest)
+		return db.TicketComment{}, false
+	}
+	comment, err := th.db.GetTicketComment(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return db.TicketComment{}, false
+	}
+	return comment, true
+}
+
+// UpdateTicketComment godoc
+//
+//	@Summary		Edit a comment
+//	@Description	Edit the body of a comment and notify people newly mentioned in it. Only the author can edit a comment.
+//	@Tags			Bounty Tickets
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			comment_id	path		string					true	"Comment ID"
+//	@Param			comment		body		TicketCommentRequest	true	"Comment"
+//	@Success		200			{object}	db.TicketComment
+//	@Router			/bounties/ticket/comments/{comment_id} [patch]
+func (th *ticketHandler) UpdateTicketComment(w http.ResponseWriter, r *http.Request) {
+	comment, ok := th.commentParam(w, r)
+	if !ok {
+		return
+	}
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if comment.AuthorPubKey != pubKeyFromAuth {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only the author can edit a comment"})
+		return
+	}
+
+	var request TicketCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error parsing request body"})
+		return
+	}
+
+	target := commentTarget{Type: comment.TargetType, UUID: comment.TargetUUID}
+	if comment.TargetType == db.TicketPlanCommentTarget {
+		if plan, err := th.db.GetTicketPlan(comment.TargetUUID); err == nil && plan != nil {
+			target.Name = plan.Name
+			target.Workspace = plan.WorkspaceUuid
+		}
+	} else if group, err := uuid.Parse(comment.TargetUUID); err == nil {
+		if ticket, err := th.db.GetLatestTicketByGroup(group); err == nil {
+			target.Name = ticket.Name
+			target.Workspace = ticket.WorkspaceUuid
+		}
+	}
+
+	mentioned := th.commentMentions(request.Body, target)
+	updated, err := th.db.UpdateTicketCommentBody(comment.ID, request.Body, mentionedPubKeys(mentioned))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	th.notifyMentions(updated, mentioned, comment.Mentions, target)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// ResolveTicketComment godoc
+//
+//	@Summary		Resolve a comment thread
+//	@Description	Mark the thread of a comment as resolved, or reopen it
+//	@Tags			Bounty Tickets
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			comment_id	path		string						true	"Comment ID"
+//	@Param			resolve		body		ResolveTicketCommentRequest	true	"Resolved state"
+//	@Success		200			{object}	db.TicketComment
+//	@Router			/bounties/ticket/comments/{comment_id}/resolve [post]
+func (th *ticketHandler) ResolveTicketComment(w http.ResponseWriter, r *http.Request) {
+	comment, ok := th.commentParam(w, r)
+	if !ok {
+		return
+	}
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+
+	var request ResolveTicketCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error parsing request body"})
+		return
+	}
+
+	thread, err := th.db.ResolveTicketCommentThread(comment.ID, request.Resolved, pubKeyFromAuth)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(thread)
+}
+
+// DeleteTicketComment godoc
+//
+//	@Summary		Delete a comment
+//	@Description	Delete a comment, with its replies when it starts a thread. Only the author can delete a comment.
+//	@Tags			Bounty Tickets
+//	@Security		PubKeyContextAuth
+//	@Param			comment_id	path	string	true	"Comment ID"
+//	@Success		204
+//	@Router			/bounties/ticket/comments/{comment_id} [delete]
+func (th *ticketHandler) DeleteTicketComment(w http.ResponseWriter, r *http.Request) {
+	comment, ok := th.commentParam(w, r)
+	if !ok {
+		return
+	}
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if comment.AuthorPubKey != pubKeyFromAuth {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only the author can delete a comment"})
+		return
+	}
+
+	if err := th.db.DeleteTicketComment(comment.ID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type sentNotification struct {
+	pubKey  string
+	event   string
+	content string
+}
+
+func newCommentHandler(t *testing.T) (*ticketHandler, *datamocks.Database, chan sentNotification) {
+	mockDb := datamocks.NewDatabase(t)
+	th := NewTicketHandler(&http.Client{}, mockDb)
+	sent := make(chan sentNotification, 10)
+	th.notify = func(pubkey, event, content, alias, routeHint string) string {
+		sent <- sentNotification{pubKey: pubkey, event: event, content: content}
+		return "COMPLETE"
+	}
+	return th, mockDb, sent
+}
+
+// nextNotification waits for a notification sent in the background.
+func nextNotification(t *testing.T, sent chan sentNotification) sentNotification {
+	select {
+	case notification := <-sent:
+		return notification
+	case <-time.After(time.Second):
+		t.Fatal("no notification was sent")
+		return sentNotification{}
+	}
+}
+
+func TestTicketComments(t *testing.T) {
+	group := uuid.New()
+	ticket := db.Tickets{UUID: uuid.New(), TicketGroup: &group, Name: "Login page", FeatureUUID: "feature-1", PhaseUUID: "phase-1", WorkspaceUuid: "ws-1"}
+	workspace := db.Workspace{Uuid: "ws-1", OwnerPubKey: "test-pubkey"}
+	params := map[string]string{"group_uuid": group.String()}
+	alice := db.Person{OwnerPubKey: "alice-pubkey", OwnerAlias: "alice"}
+	author := db.Person{OwnerPubKey: "test-pubkey", OwnerAlias: "bob"}
+
+	t.Run("Create Notifies Mentions", func(t *testing.T) {
+		th, mockDb, sent := newCommentHandler(t)
+		mockDb.On("GetLatestTicketByGroup", group).Return(ticket, nil)
+		mockDb.On("GetPersonByPubkey", "test-pubkey").Return(author)
+		mockDb.On("GetPeopleByMentions", []string{"alice", "bob"}).Return([]db.Person{alice, author}, nil)
+		mockDb.On("GetWorkspaceByUuid", "ws-1").Return(workspace)
+		mockDb.On("GetWorkspaceUser", "alice-pubkey", "ws-1").Return(db.WorkspaceUsers{OwnerPubKey: "alice-pubkey"})
+		mockDb.On("CreateTicketComment", mock.MatchedBy(func(comment *db.TicketComment) bool {
+			return comment.TargetType == db.TicketCommentTarget && comment.TargetUUID == group.String() &&
+				comment.AuthorAlias == "bob" && len(comment.Mentions) == 2
+		})).Return(func(comment *db.TicketComment) db.TicketComment { return *comment }, nil)
+
+		rr := httptest.NewRecorder()
+		th.CreateTicketComment(rr, newBranchRequest(http.MethodPost, "/comments", `{"body":"@alice please review, cc @bob"}`, params))
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		notification := nextNotification(t, sent)
+		assert.Equal(t, "alice-pubkey", notification.pubKey)
+		assert.Equal(t, "ticket_mention", notification.event)
+		assert.Contains(t, notification.content, `bob mentioned you on ticket "Login page"`)
+		assert.Empty(t, sent)
+	})
+
+	t.Run("Only Members Can Be Mentioned", func(t *testing.T) {
+		th, mockDb, sent := newCommentHandler(t)
+		mallory := db.Person{OwnerPubKey: "mallory-pubkey", OwnerAlias: "mallory"}
+		mockDb.On("GetLatestTicketByGroup", group).Return(ticket, nil)
+		mockDb.On("GetPersonByPubkey", "test-pubkey").Return(author)
+		mockDb.On("GetPeopleByMentions", []string{"mallory"}).Return([]db.Person{mallory}, nil)
+		mockDb.On("GetWorkspaceByUuid", "ws-1").Return(workspace)
+		mockDb.On("GetWorkspaceUser", "mallory-pubkey", "ws-1").Return(db.WorkspaceUsers{})
+		mockDb.On("CreateTicketComment", mock.MatchedBy(func(comment *db.TicketComment) bool {
+			return len(comment.Mentions) == 0
+		})).Return(func(comment *db.TicketComment) db.TicketComment { return *comment }, nil)
+
+		rr := httptest.NewRecorder()
+		th.CreateTicketComment(rr, newBranchRequest(http.MethodPost, "/comments", `{"body":"@mallory look"}`, params))
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		assert.Empty(t, sent)
+	})
+
+	t.Run("Create On Unknown Ticket", func(t *testing.T) {
+		th, mockDb, _ := newCommentHandler(t)
+		mockDb.On("GetLatestTicketByGroup", group).Return(db.Tickets{}, errors.New("not found"))
+		mockDb.On("GetTicket", group.String()).Return(db.Tickets{}, errors.New("ticket not found"))
+
+		rr := httptest.NewRecorder()
+		th.CreateTicketComment(rr, newBranchRequest(http.MethodPost, "/comments", `{"body":"hi"}`, params))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("List Threads", func(t *testing.T) {
+		th, mockDb, _ := newCommentHandler(t)
+		root := db.TicketComment{ID: uuid.New(), Body: "question"}
+		reply := db.TicketComment{ID: uuid.New(), ParentID: &root.ID, Body: "answer"}
+		mockDb.On("GetLatestTicketByGroup", group).Return(ticket, nil)
+		mockDb.On("GetTicketComments", db.TicketCommentTarget, group.String()).Return([]db.TicketComment{root, reply}, nil)
+
+		rr := httptest.NewRecorder()
+		th.GetTicketComments(rr, newBranchRequest(http.MethodGet, "/comments", "", params))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var threads []db.TicketCommentThread
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&threads))
+		require.Len(t, threads, 1)
+		assert.Len(t, threads[0].Replies, 1)
+	})
+
+	t.Run("Edit Notifies Only New Mentions", func(t *testing.T) {
+		th, mockDb, sent := newCommentHandler(t)
+		carol := db.Person{OwnerPubKey: "carol-pubkey", OwnerAlias: "carol"}
+		comment := db.TicketComment{ID: uuid.New(), TargetType: db.TicketCommentTarget, TargetUUID: group.String(), AuthorPubKey: "test-pubkey", AuthorAlias: "bob", Body: "@alice", Mentions: pq.StringArray{"alice-pubkey"}}
+		mockDb.On("GetTicketComment", comment.ID).Return(comment, nil)
+		mockDb.On("GetPeopleByMentions", []string{"alice", "carol"}).Return([]db.Person{alice, carol}, nil)
+		mockDb.On("GetWorkspaceByUuid", "ws-1").Return(workspace)
+		mockDb.On("GetWorkspaceUser", mock.Anything, "ws-1").Return(func(pubKey, workspace string) db.WorkspaceUsers {
+			return db.WorkspaceUsers{OwnerPubKey: pubKey}
+		})
+		mockDb.On("UpdateTicketCommentBody", comment.ID, "@alice and @carol", []string{"alice-pubkey", "carol-pubkey"}).
+			Return(db.TicketComment{ID: comment.ID, AuthorPubKey: "test-pubkey", AuthorAlias: "bob", Body: "@alice and @carol"}, nil)
+		mockDb.On("GetLatestTicketByGroup", group).Return(ticket, nil)
+
+		rr := httptest.NewRecorder()
+		th.UpdateTicketComment(rr, newBranchRequest(http.MethodPatch, "/comments", `{"body":"@alice and @carol"}`, map[string]string{"comment_id": comment.ID.String()}))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "carol-pubkey", nextNotification(t, sent).pubKey)
+		assert.Empty(t, sent)
+	})
+
+	t.Run("Only The Author Edits", func(t *testing.T) {
+		th, mockDb, _ := newCommentHandler(t)
+		comment := db.TicketComment{ID: uuid.New(), AuthorPubKey: "someone-else"}
+		mockDb.On("GetTicketComment", comment.ID).Return(comment, nil)
+
+		rr := httptest.NewRecorder()
+		th.UpdateTicketComment(rr, newBranchRequest(http.MethodPatch, "/comments", `{"body":"changed"}`, map[string]string{"comment_id": comment.ID.String()}))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Resolve Thread", func(t *testing.T) {
+		th, mockDb, _ := newCommentHandler(t)
+		comment := db.TicketComment{ID: uuid.New(), AuthorPubKey: "someone-else"}
+		mockDb.On("GetTicketComment", comment.ID).Return(comment, nil)
+		mockDb.On("ResolveTicketCommentThread", comment.ID, true, "test-pubkey").
+			Return(db.TicketComment{ID: comment.ID, Resolved: true, ResolvedBy: "test-pubkey"}, nil)
+
+		rr := httptest.NewRecorder()
+		th.ResolveTicketComment(rr, newBranchRequest(http.MethodPost, "/resolve", `{"resolved":true}`, map[string]string{"comment_id": comment.ID.String()}))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resolved db.TicketComment
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resolved))
+		assert.True(t, resolved.Resolved)
+	})
+
+	t.Run("Delete Own Comment", func(t *testing.T) {
+		th, mockDb, _ := newCommentHandler(t)
+		comment := db.TicketComment{ID: uuid.New(), AuthorPubKey: "test-pubkey"}
+		mockDb.On("GetTicketComment", comment.ID).Return(comment, nil)
+		mockDb.On("DeleteTicketComment", comment.ID).Return(nil)
+
+		rr := httptest.NewRecorder()
+		th.DeleteTicketComment(rr, newBranchRequest(http.MethodDelete, "/comments", "", map[string]string{"comment_id": comment.ID.String()}))
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+}
+
+func TestTicketPlanComments(t *testing.T) {
+	plan := &db.TicketPlan{UUID: uuid.New(), Name: "Auth plan"}
+	params := map[string]string{"uuid": plan.UUID.String()}
+
+	t.Run("Create", func(t *testing.T) {
+		th, mockDb, sent := newCommentHandler(t)
+		mockDb.On("GetTicketPlan", plan.UUID.String()).Return(plan, nil)
+		mockDb.On("GetPersonByPubkey", "test-pubkey").Return(db.Person{OwnerPubKey: "test-pubkey"})
+		mockDb.On("GetPeopleByMentions", []string{}).Return([]db.Person{}, nil)
+		mockDb.On("CreateTicketComment", mock.MatchedBy(func(comment *db.TicketComment) bool {
+			return comment.TargetType == db.TicketPlanCommentTarget && comment.TargetUUID == plan.UUID.String()
+		})).Return(func(comment *db.TicketComment) db.TicketComment { return *comment }, nil)
+
+		rr := httptest.NewRecorder()
+		th.CreateTicketPlanComment(rr, newBranchRequest(http.MethodPost, "/comments", `{"body":"Looks good"}`, params))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Empty(t, sent)
+	})
+
+	t.Run("Unknown Plan", func(t *testing.T) {
+		th, mockDb, _ := newCommentHandler(t)
+		mockDb.On("GetTicketPlan", plan.UUID.String()).Return(nil, errors.New("plan not found"))
+
+		rr := httptest.NewRecorder()
+		th.GetTicketPlanComments(rr, newBranchRequest(http.MethodGet, "/comments", "", params))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestGetTicketsByPhaseUUIDCommentCounts(t *testing.T) {
+	th, mockDb, _ := newCommentHandler(t)
+	first := newGraphTicket(db.DraftTicket)
+	second := newGraphTicket(db.DraftTicket)
+	mockDb.On("GetFeatureByUuid", "feature-1").Return(db.WorkspaceFeatures{Uuid: "feature-1"})
+	mockDb.On("GetFeaturePhaseByUuid", "feature-1", "phase-1").Return(db.FeaturePhase{Uuid: "phase-1"}, nil)
+	mockDb.On("GetTicketsByPhaseUUID", "feature-1", "phase-1").Return([]db.Tickets{first, second}, nil)
+	mockDb.On("GetTicketCommentCounts", db.TicketCommentTarget, []string{first.TicketGroup.String(), second.TicketGroup.String()}).
+		Return(map[string]db.TicketCommentCount{first.TicketGroup.String(): {Total: 3, Unresolved: 1}}, nil)
+
+	rr := httptest.NewRecorder()
+	th.GetTicketsByPhaseUUID(rr, newBranchRequest(http.MethodGet, "/tickets", "", map[string]string{"feature_uuid": "feature-1", "phase_uuid": "phase-1"}))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var tickets []db.Tickets
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&tickets))
+	require.Len(t, tickets, 2)
+	assert.Equal(t, db.TicketCommentCount{Total: 3, Unresolved: 1}, *tickets[0].Comments)
+	assert.Equal(t, db.TicketCommentCount{}, *tickets[1].Comments)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// dedupeDependencies drops empty and repeated ticket groups.
+func dedupeDependencies(dependsOn []string) []string {
+	seen := map[string]bool{}
+	deps := []string{}
+	for _, dep := range dependsOn {
+		if dep == "" || seen[dep] {
+			continue
+		}
+		seen[dep] = true
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+// ticketCheckError is a reason a ticket cannot be saved, with the status
+// and extra fields of the error response.
+type ticketCheckError struct {
+	status  int
+	message string
+	extra   map[string]interface{}
+}
+
+func (e *ticketCheckError) Error() string {
+	return e.message
+}
+
+func (e *ticketCheckError) write(w http.ResponseWriter) {
+	body := map[string]interface{}{"error": e.message}
+	for key, value := range e.extra {
+		body[key] = value
+	}
+	w.WriteHeader(e.status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// checkTicketDependencies validates the dependencies of a new version of a
+// ticket. It writes the error response and returns false when the ticket
+// cannot be saved.
+func (th *ticketHandler) checkTicketDependencies(w http.ResponseWriter, ticket *db.Tickets) bool {
+	if err := ticketDependencyError(th.db, ticket); err != nil {
+		err.write(w)
+		return false
+	}
+	return true
+}
+
+func ticketDependencyError(database db.Database, ticket *db.Tickets) *ticketCheckError {
+	ticket.DependsOn = dedupeDependencies(ticket.DependsOn)
+	if len(ticket.DependsOn) == 0 {
+		return nil
+	}
+
+	tickets, err := database.GetTicketsByPhaseUUID(ticket.FeatureUUID, ticket.PhaseUUID)
+	if err != nil {
+		return &ticketCheckError{status: http.StatusInternalServerError, message: err.Error()}
+	}
+	if err := db.ValidateTicketDependencies(db.TicketGroupID(*ticket), ticket.DependsOn, db.LatestTickets(tickets)); err != nil {
+		return &ticketCheckError{status: http.StatusBadRequest, message: err.Error()}
+	}
+	return nil
+}
+
+// ticketBlockersError refuses to start a ticket while its blockers are not
+// done.
+func ticketBlockersError(database db.Database, ticket db.Tickets, done db.TicketStatus) *ticketCheckError {
+	if len(ticket.DependsOn) == 0 {
+		return nil
+	}
+	tickets, err := database.GetTicketsByPhaseUUID(ticket.FeatureUUID, ticket.PhaseUUID)
+	if err != nil {
+		return &ticketCheckError{status: http.StatusInternalServerError, message: err.Error()}
+	}
+	blockers := db.UnfinishedBlockers(ticket, db.LatestTickets(tickets), done)
+	if len(blockers) == 0 {
+		return nil
+	}
+	groups := make([]string, len(blockers))
+	for i, blocker := range blockers {
+		groups[i] = db.TicketGroupID(blocker)
+	}
+	return &ticketCheckError{
+		status:  http.StatusConflict,
+		message: "ticket cannot start before its blockers are completed",
+		extra:   map[string]interface{}{"blockers": groups},
+	}
+}
+
+// GetTicketGraph godoc
+//
+//	@Summary		Get the ticket dependency graph of a phase
+//	@Description	Get the latest tickets of a phase as a dependency graph with a topological order and the critical path
+//	@Tags			Bounty Tickets
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			feature_uuid	path		string	true	"Feature UUID"
+//	@Param			phase_uuid		path		string	true	"Phase UUID"
+//	@Success		200				{object}	db.TicketGraph
+//	@Router			/bounties/ticket/feature/{feature_uuid}/phase/{phase_uuid}/graph [get]
+func (th *ticketHandler) GetTicketGraph(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	featureUUID := chi.URLParam(r, "feature_uuid")
+	phaseUUID := chi.URLParam(r, "phase_uuid")
+	if featureUUID == "" || phaseUUID == "" {
+		http.Error(w, "Missing feature or phase uuid", http.StatusBadRequest)
+		return
+	}
+
+	if feature := th.db.GetFeatureByUuid(featureUUID); feature.Uuid == "" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "feature not found"})
+		return
+	}
+	if _, err := th.db.GetFeaturePhaseByUuid(featureUUID, phaseUUID); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Phase not found"})
+		return
+	}
+
+	tickets, err := th.db.GetTicketsByPhaseUUID(featureUUID, phaseUUID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	graph, err := db.BuildTicketGraph(tickets)
+	if errors.Is(err, db.ErrDependencyCycle) {
+		log.Printf("tickets of phase %s have a dependency cycle", phaseUUID)
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(graph)
+}
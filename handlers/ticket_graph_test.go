@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newGraphTicket(status db.TicketStatus, dependsOn ...string) db.Tickets {
+	group := uuid.New()
+	return db.Tickets{
+		UUID:        uuid.New(),
+		TicketGroup: &group,
+		FeatureUUID: "feature-1",
+		PhaseUUID:   "phase-1",
+		Name:        "ticket",
+		Status:      status,
+		Version:     1,
+		DependsOn:   pq.StringArray(dependsOn),
+	}
+}
+
+func TestGetTicketGraph(t *testing.T) {
+	params := map[string]string{"feature_uuid": "feature-1", "phase_uuid": "phase-1"}
+
+	t.Run("Returns Graph", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		first := newGraphTicket(db.CompletedTicket)
+		second := newGraphTicket(db.DraftTicket, first.TicketGroup.String())
+		mockDb.On("GetFeatureByUuid", "feature-1").Return(db.WorkspaceFeatures{Uuid: "feature-1"})
+		mockDb.On("GetFeaturePhaseByUuid", "feature-1", "phase-1").Return(db.FeaturePhase{Uuid: "phase-1"}, nil)
+		mockDb.On("GetTicketsByPhaseUUID", "feature-1", "phase-1").Return([]db.Tickets{second, first}, nil)
+
+		rr := httptest.NewRecorder()
+		th.GetTicketGraph(rr, newBranchRequest(http.MethodGet, "/bounties/ticket/feature/feature-1/phase/phase-1/graph", "", params))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var graph db.TicketGraph
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&graph))
+		assert.Equal(t, []string{first.TicketGroup.String(), second.TicketGroup.String()}, graph.Order)
+		assert.Equal(t, graph.Order, graph.CriticalPath)
+	})
+
+	t.Run("Unknown Phase", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		mockDb.On("GetFeatureByUuid", "feature-1").Return(db.WorkspaceFeatures{Uuid: "feature-1"})
+		mockDb.On("GetFeaturePhaseByUuid", "feature-1", "phase-1").Return(db.FeaturePhase{}, errors.New("not found"))
+
+		rr := httptest.NewRecorder()
+		th.GetTicketGraph(rr, newBranchRequest(http.MethodGet, "/graph", "", params))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestUpdateTicketDependencies(t *testing.T) {
+	update := func(th *ticketHandler, ticket db.Tickets) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(UpdateTicketRequest{Ticket: &ticket})
+		rr := httptest.NewRecorder()
+		th.UpdateTicket(rr, newBranchRequest(http.MethodPost, "/bounties/ticket/"+ticket.UUID.String(), string(body), map[string]string{"uuid": ticket.UUID.String()}))
+		return rr
+	}
+	// work starts in DOING, where in-progress bounties show, and ends in SHIPPED
+	shippingWorkflow := db.TicketWorkflow{
+		WorkspaceUuid: "workspace-1",
+		Columns: db.WorkflowColumns{
+			{Status: "TODO"},
+			{Status: "DOING", BountyStatuses: []db.BountyStatus{db.StatusInProgress}},
+			{Status: "SHIPPED"},
+		},
+	}
+
+	t.Run("Rejects Cycle", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		blocker := newGraphTicket(db.DraftTicket)
+		ticket := newGraphTicket(db.DraftTicket)
+		blocker.DependsOn = pq.StringArray{ticket.TicketGroup.String()}
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockDb.On("GetTicketsByPhaseUUID", "feature-1", "phase-1").Return([]db.Tickets{blocker, ticket}, nil)
+
+		ticket.DependsOn = pq.StringArray{blocker.TicketGroup.String()}
+		rr := update(th, ticket)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "cycle")
+	})
+
+	t.Run("Cannot Start Before Blockers Complete", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		blocker := newGraphTicket(db.TestTicket)
+		ticket := newGraphTicket(db.ReadyTicket, blocker.TicketGroup.String())
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockTicketWorkflow(mockDb, db.DefaultTicketWorkflow("workspace-1"))
+		mockDb.On("GetTicketsByPhaseUUID", "feature-1", "phase-1").Return([]db.Tickets{blocker, ticket}, nil)
+
+		started := ticket
+		started.Status = db.InProgressTicket
+		started.DependsOn = nil
+		rr := update(th, started)
+
+		require.Equal(t, http.StatusConflict, rr.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, []interface{}{blocker.TicketGroup.String()}, response["blockers"])
+	})
+
+	t.Run("Starts Once Blockers Complete", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		blocker := newGraphTicket(db.CompletedTicket)
+		ticket := newGraphTicket(db.ReadyTicket, blocker.TicketGroup.String())
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockTicketWorkflow(mockDb, db.DefaultTicketWorkflow("workspace-1"))
+		mockDb.On("GetTicketsByPhaseUUID", "feature-1", "phase-1").Return([]db.Tickets{blocker, ticket}, nil)
+		mockDb.On("CreateOrEditTicket", mock.MatchedBy(func(saved *db.Tickets) bool {
+			return saved.Status == db.InProgressTicket && saved.Version == 2 &&
+				len(saved.DependsOn) == 1 && saved.DependsOn[0] == blocker.TicketGroup.String()
+		})).Return(func(saved *db.Tickets) db.Tickets { return *saved }, nil)
+
+		started := ticket
+		started.Status = db.InProgressTicket
+		started.DependsOn = nil
+		rr := update(th, started)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Custom Start Column Waits For Blockers", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		blocker := newGraphTicket("DOING")
+		ticket := newGraphTicket("TODO", blocker.TicketGroup.String())
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockTicketWorkflow(mockDb, shippingWorkflow)
+		mockDb.On("GetTicketsByPhaseUUID", "feature-1", "phase-1").Return([]db.Tickets{blocker, ticket}, nil)
+
+		started := ticket
+		started.Status = "DOING"
+		rr := update(th, started)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Moving Drops Dependencies And Releases Dependents", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		blocker := newGraphTicket(db.DraftTicket)
+		ticket := newGraphTicket(db.DraftTicket, blocker.TicketGroup.String())
+		dependent := newGraphTicket(db.DraftTicket, ticket.TicketGroup.String(), blocker.TicketGroup.String())
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockTicketWorkflow(mockDb, db.DefaultTicketWorkflow("workspace-1"))
+		mockDb.On("GetTicketsByPhaseUUID", "feature-1", "phase-1").Return([]db.Tickets{blocker, ticket, dependent}, nil)
+		mockDb.On("InTransaction", mock.Anything).Return(func(fn func(db.Database) error) error { return fn(mockDb) })
+		var saved []db.Tickets
+		mockDb.On("CreateOrEditTicket", mock.AnythingOfType("*db.Tickets")).Return(func(ticket *db.Tickets) db.Tickets {
+			saved = append(saved, *ticket)
+			return *ticket
+		}, nil)
+
+		moved := ticket
+		moved.PhaseUUID = "phase-2"
+		rr := update(th, moved)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Len(t, saved, 2)
+		assert.Equal(t, "phase-2", saved[0].PhaseUUID)
+		assert.Empty(t, saved[0].DependsOn)
+		assert.Equal(t, *dependent.TicketGroup, *saved[1].TicketGroup)
+		assert.Equal(t, []string{blocker.TicketGroup.String()}, []string(saved[1].DependsOn))
+	})
+
+	t.Run("Starts Once Blockers Reach The Done Column", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		blocker := newGraphTicket("SHIPPED")
+		ticket := newGraphTicket("TODO", blocker.TicketGroup.String())
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockTicketWorkflow(mockDb, shippingWorkflow)
+		mockDb.On("GetTicketsByPhaseUUID", "feature-1", "phase-1").Return([]db.Tickets{blocker, ticket}, nil)
+		mockDb.On("CreateOrEditTicket", mock.MatchedBy(func(saved *db.Tickets) bool {
+			return saved.Status == "DOING" && saved.Version == 2
+		})).Return(func(saved *db.Tickets) db.Tickets { return *saved }, nil)
+
+		started := ticket
+		started.Status = "DOING"
+		rr := update(th, started)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
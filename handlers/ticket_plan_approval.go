@@ -0,0 +1,496 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// PlanTicketAction is what approving a plan does with one of its stub tickets.
+type PlanTicketAction string
+
+const (
+	PlanTicketCreate    PlanTicketAction = "create"
+	PlanTicketUpdate    PlanTicketAction = "update"
+	PlanTicketUnchanged PlanTicketAction = "unchanged"
+	PlanTicketRemove    PlanTicketAction = "remove"
+)
+
+const planApprovalNote = "plan approval"
+
+type ApproveTicketPlanRequest struct {
+	// StubTickets revise the stub tickets of the plan before it is approved.
+	StubTickets []db.StubTicket `json:"stub_tickets,omitempty"`
+	ToBounty    bool            `json:"to_bounty"`
+	TemplateID  string          `json:"template_id,omitempty"`
+	DryRun      bool            `json:"dry_run"`
+}
+
+type TicketPlanApprovalItem struct {
+	Key         string           `json:"key"`
+	Name        string           `json:"name"`
+	Action      PlanTicketAction `json:"action"`
+	TicketGroup string           `json:"ticket_group,omitempty"`
+	TicketUUID  string           `json:"ticket_uuid,omitempty"`
+	BountyID    uint             `json:"bounty_id,omitempty"`
+	Reason      string           `json:"reason,omitempty"`
+}
+
+type TicketPlanApprovalResponse struct {
+	PlanID      string                   `json:"plan_id"`
+	Version     int                      `json:"version"`
+	Status      db.PlanStatus            `json:"status"`
+	DryRun      bool                     `json:"dry_run"`
+	BountyTotal int64                    `json:"bounty_total,omitempty"`
+	Items       []TicketPlanApprovalItem `json:"items"`
+}
+
+// planStep is a stub ticket of the plan matched against what an earlier
+// approval made of it, or a ticket of an earlier approval whose stub is gone.
+type planStep struct {
+	item   TicketPlanApprovalItem
+	link   *db.TicketPlanTicket
+	ticket db.Tickets
+	// existing is set when ticket is a new version of a saved ticket.
+	existing bool
+	// bounty is set when the ticket becomes a bounty.
+	bounty bool
+	// deleteGroup is set when a removed stub takes its draft ticket along.
+	deleteGroup bool
+	// kept is set when the ticket is left as it is.
+	kept bool
+}
+
+// planStubDescription is the ticket description made from a stub ticket.
+func planStubDescription(stub db.StubTicket) string {
+	if strings.TrimSpace(stub.Reasoning) == "" {
+		return stub.TicketDescription
+	}
+	return fmt.Sprintf("%s\n\nReasoning: %s", stub.TicketDescription, stub.Reasoning)
+}
+
+func sameDependencies(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, dep := range a {
+		seen[dep] = true
+	}
+	for _, dep := range b {
+		if !seen[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+func planValidationError(format string, args ...interface{}) *ticketCheckError {
+	return &ticketCheckError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+// planTicketSteps validates the stub tickets of a plan and diffs them against
+// the tickets an earlier approval made: stubs are matched by key, tickets
+// whose content differs get a new version and stubs no longer in the plan
+// are removed. Draft tickets of removed stubs are deleted, the others are
+// only unlinked from the plan.
+func planTicketSteps(database db.Database, plan *db.TicketPlan, pubkey string, toBounty bool) ([]planStep, *ticketCheckError) {
+	if len(plan.StubTickets) == 0 {
+		return nil, planValidationError("the plan has no stub tickets")
+	}
+
+	links, err := database.GetTicketPlanTickets(plan.UUID.String())
+	if err != nil {
+		return nil, &ticketCheckError{status: http.StatusInternalServerError, message: err.Error()}
+	}
+	linkByKey := map[string]*db.TicketPlanTicket{}
+	for i := range links {
+		linkByKey[links[i].StubKey] = &links[i]
+	}
+
+	keys := make([]string, len(plan.StubTickets))
+	isKey := map[string]bool{}
+	keyByName := map[string]string{}
+	steps := make([]planStep, len(plan.StubTickets))
+	for i, stub := range plan.StubTickets {
+		if strings.TrimSpace(stub.TicketName) == "" {
+			return nil, planValidationError("stub ticket %d has no name", i+1)
+		}
+		key := db.StubTicketKey(stub)
+		if isKey[key] {
+			return nil, planValidationError("stub ticket key %q is used twice", key)
+		}
+		if stub.Amount < 0 {
+			return nil, planValidationError("stub ticket %q has a negative amount", key)
+		}
+		keys[i] = key
+		isKey[key] = true
+		keyByName[strings.ToLower(strings.TrimSpace(stub.TicketName))] = key
+
+		step := planStep{
+			item: TicketPlanApprovalItem{Key: key, Name: stub.TicketName},
+			link: linkByKey[key],
+		}
+		if step.link != nil && step.link.BountyID != nil {
+			step.item.Action = PlanTicketUnchanged
+			step.item.BountyID = *step.link.BountyID
+			step.item.Reason = "already a bounty"
+			steps[i] = step
+			continue
+		}
+		if step.link != nil && step.link.TicketGroup != nil {
+			if current, err := database.GetLatestTicketByGroup(*step.link.TicketGroup); err == nil {
+				step.ticket = current
+				step.existing = true
+			}
+		}
+		if !step.existing {
+			group := uuid.New()
+			step.ticket = db.Tickets{
+				TicketGroup:   &group,
+				WorkspaceUuid: plan.WorkspaceUuid,
+				FeatureUUID:   plan.FeatureUUID,
+				PhaseUUID:     plan.PhaseUUID,
+				Status:        db.DraftTicket,
+			}
+		}
+		// only drafts become bounties, work on the others has started
+		if toBounty && step.existing && step.ticket.Status != db.DraftTicket {
+			step.kept = true
+			step.item.Action = PlanTicketUnchanged
+			step.item.TicketGroup = step.ticket.TicketGroup.String()
+			step.item.TicketUUID = step.ticket.UUID.String()
+			step.item.Reason = "not made a bounty, the ticket is past draft"
+			steps[i] = step
+			continue
+		}
+		if toBounty && stub.Amount == 0 {
+			return nil, planValidationError("stub ticket %q needs an amount to become a bounty", key)
+		}
+		step.bounty = toBounty
+		steps[i] = step
+	}
+
+	groupByKey := map[string]string{}
+	for i, step := range steps {
+		if step.ticket.TicketGroup != nil {
+			groupByKey[keys[i]] = step.ticket.TicketGroup.String()
+		}
+	}
+
+	var graphTickets []db.Tickets
+	for i, stub := range plan.StubTickets {
+		step := &steps[i]
+		dependsOn := []string{}
+		for _, dep := range stub.DependsOn {
+			depKey := strings.TrimSpace(dep)
+			if !isKey[depKey] {
+				depKey = keyByName[strings.ToLower(depKey)]
+			}
+			if depKey == "" {
+				return nil, planValidationError("stub ticket %q depends on unknown stub %q", keys[i], dep)
+			}
+			if depKey == keys[i] {
+				return nil, planValidationError("stub ticket %q cannot depend on itself", keys[i])
+			}
+			// stubs that became bounties have no ticket to depend on
+			if group, ok := groupByKey[depKey]; ok {
+				dependsOn = append(dependsOn, group)
+			}
+		}
+		dependsOn = dedupeDependencies(dependsOn)
+		if step.ticket.TicketGroup == nil {
+			continue
+		}
+		if step.kept {
+			graphTickets = append(graphTickets, step.ticket)
+			continue
+		}
+
+		current := step.ticket
+		var amount *int64
+		if stub.Amount > 0 {
+			stubAmount := stub.Amount
+			amount = &stubAmount
+		}
+		changed := !step.existing ||
+			current.Name != stub.TicketName ||
+			current.Description != planStubDescription(stub) ||
+			current.Sequence != i ||
+			!sameDependencies(current.DependsOn, dependsOn) ||
+			(amount != nil && (current.Amount == nil || *current.Amount != *amount))
+
+		switch {
+		case !step.existing:
+			step.item.Action = PlanTicketCreate
+		case changed:
+			step.item.Action = PlanTicketUpdate
+		default:
+			step.item.Action = PlanTicketUnchanged
+		}
+		if changed {
+			author := db.HumanAuthor
+			authorID := pubkey
+			next := current
+			next.UUID = uuid.New()
+			next.Features = db.WorkspaceFeatures{}
+			next.FeaturePhase = db.FeaturePhase{}
+			next.Name = stub.TicketName
+			next.Description = planStubDescription(stub)
+			next.Sequence = i
+			next.DependsOn = dependsOn
+			if amount != nil {
+				next.Amount = amount
+			}
+			next.Version = current.Version + 1
+			next.Author = &author
+			next.AuthorID = &authorID
+			next.CreatedAt = time.Now()
+			next.UpdatedAt = time.Now()
+			next.RevisionNote = planApprovalNote
+			step.ticket = next
+		}
+		if !step.bounty {
+			step.item.TicketGroup = step.ticket.TicketGroup.String()
+			step.item.TicketUUID = step.ticket.UUID.String()
+		}
+		graphTickets = append(graphTickets, step.ticket)
+	}
+
+	if _, err := db.BuildTicketGraph(graphTickets); err != nil {
+		return nil, planValidationError("stub ticket dependencies form a cycle")
+	}
+
+	for i := range links {
+		link := &links[i]
+		if isKey[link.StubKey] {
+			continue
+		}
+		step := planStep{
+			item: TicketPlanApprovalItem{Key: link.StubKey, Action: PlanTicketRemove},
+			link: link,
+		}
+		switch {
+		case link.BountyID != nil:
+			step.item.BountyID = *link.BountyID
+			step.item.Reason = "bounty kept"
+		case link.TicketGroup != nil:
+			step.item.TicketGroup = link.TicketGroup.String()
+			current, err := database.GetLatestTicketByGroup(*link.TicketGroup)
+			if err != nil {
+				step.item.Reason = "ticket already deleted"
+				break
+			}
+			step.item.Name = current.Name
+			if current.Status == db.DraftTicket {
+				step.deleteGroup = true
+				step.item.Reason = "draft ticket deleted"
+			} else {
+				step.item.Reason = "ticket kept, it is past draft"
+			}
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// applyPlanStep saves what approving the plan makes of one stub ticket and
+// records it against the plan.
+func applyPlanStep(tx db.Database, step *planStep, planUUID uuid.UUID, planVersion int, pubkey string, template db.WorkspaceTemplate) error {
+	if step.item.Action == PlanTicketRemove {
+		if step.deleteGroup {
+			if err := tx.DeleteTicketGroup(*step.link.TicketGroup); err != nil {
+				return err
+			}
+		}
+		return tx.DeleteTicketPlanTicket(step.link.ID)
+	}
+	if step.ticket.TicketGroup == nil {
+		return nil
+	}
+
+	group := *step.ticket.TicketGroup
+	ticketGroup := &group
+	var bountyID *uint
+	if step.bounty {
+		bounty, err := tx.CreateBountyFromTicket(step.ticket, pubkey, template)
+		if err != nil {
+			return err
+		}
+		if step.existing {
+			if err := tx.DeleteTicketGroup(group); err != nil {
+				return err
+			}
+		}
+		bountyID = &bounty.ID
+		ticketGroup = nil
+		step.item.BountyID = bounty.ID
+	} else if step.item.Action == PlanTicketUnchanged {
+		return nil
+	} else if _, err := tx.CreateOrEditTicket(&step.ticket); err != nil {
+		return err
+	}
+
+	if step.link == nil {
+		_, err := tx.CreateTicketPlanTicket(&db.TicketPlanTicket{
+			PlanUUID:    planUUID,
+			StubKey:     step.item.Key,
+			TicketGroup: ticketGroup,
+			BountyID:    bountyID,
+			PlanVersion: planVersion,
+		})
+		return err
+	}
+	step.link.TicketGroup = ticketGroup
+	step.link.BountyID = bountyID
+	step.link.PlanVersion = planVersion
+	return tx.UpdateTicketPlanTicket(step.link)
+}
+
+// ApproveTicketPlan godoc
+//
+//	@Summary		Approve Ticket Plan
+//	@Description	Approve a ticket plan, turning its stub tickets into tickets or bounties. Approving a revised plan again updates the tickets it made.
+//	@Tags			Ticket Plans
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			uuid	path		string						true	"Ticket Plan UUID"
+//	@Param			request	body		ApproveTicketPlanRequest	false	"Approval options"
+//	@Success		200		{object}	TicketPlanApprovalResponse
+//	@Router			/bounties/ticket/plan/{uuid}/approve [post]
+func (th *ticketHandler) ApproveTicketPlan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[ticket plan] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	planUUID := chi.URLParam(r, "uuid")
+	if _, err := uuid.Parse(planUUID); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid plan UUID format"})
+		return
+	}
+	plan, err := th.db.GetTicketPlan(planUUID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "ticket plan not found"})
+		return
+	}
+
+	workspace := th.db.GetWorkspaceByUuid(plan.WorkspaceUuid)
+	if workspace.OwnerPubKey != pubKeyFromAuth && th.db.GetWorkspaceUser(pubKeyFromAuth, plan.WorkspaceUuid).OwnerPubKey != pubKeyFromAuth {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only workspace members can approve a plan"})
+		return
+	}
+
+	var request ApproveTicketPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+		return
+	}
+	if len(request.StubTickets) > 0 {
+		plan.StubTickets = db.PlanStubTickets(request.StubTickets)
+	}
+
+	steps, checkErr := planTicketSteps(th.db, plan, pubKeyFromAuth, request.ToBounty)
+	if checkErr != nil {
+		checkErr.write(w)
+		return
+	}
+
+	response := TicketPlanApprovalResponse{
+		PlanID:  plan.UUID.String(),
+		Version: plan.Version,
+		Status:  plan.Status,
+		DryRun:  request.DryRun,
+		Items:   []TicketPlanApprovalItem{},
+	}
+	for _, step := range steps {
+		if step.bounty {
+			response.BountyTotal += *step.ticket.Amount
+		}
+	}
+
+	var template db.WorkspaceTemplate
+	if request.ToBounty {
+		// unpaid bounties of the workspace already hold part of the budget
+		budget := th.db.GetWorkspaceStatusBudget(plan.WorkspaceUuid)
+		committed := budget.OpenBudget + budget.AssignedBudget + budget.CompletedBudget
+		available := uint(0)
+		if budget.CurrentBudget > committed {
+			available = budget.CurrentBudget - committed
+		}
+		if uint(response.BountyTotal) > available {
+			(&ticketCheckError{
+				status:  http.StatusForbidden,
+				message: "workspace budget is not enough for the plan bounties",
+				extra: map[string]interface{}{
+					"bounty_total":     response.BountyTotal,
+					"total_budget":     budget.CurrentBudget,
+					"committed_budget": committed,
+				},
+			}).write(w)
+			return
+		}
+		if template, checkErr = resolveWorkspaceTemplate(th.db, plan.WorkspaceUuid, db.BountyTemplateKind, request.TemplateID); checkErr != nil {
+			checkErr.write(w)
+			return
+		}
+	}
+
+	if !request.DryRun {
+		err = th.db.InTransaction(func(tx db.Database) error {
+			plan.Status = db.ApprovedPlan
+			plan.UpdatedBy = pubKeyFromAuth
+			plan.TicketGroups = []string{}
+			for _, step := range steps {
+				if !step.bounty && step.item.Action != PlanTicketRemove && step.ticket.TicketGroup != nil {
+					plan.TicketGroups = append(plan.TicketGroups, step.ticket.TicketGroup.String())
+				}
+			}
+			saved, err := tx.CreateOrEditTicketPlan(plan)
+			if err != nil {
+				return err
+			}
+			response.Version = saved.Version
+			response.Status = saved.Status
+
+			for i := range steps {
+				if err := applyPlanStep(tx, &steps[i], plan.UUID, saved.Version, pubKeyFromAuth, template); err != nil {
+					return fmt.Errorf("stub ticket %q: %w", steps[i].item.Key, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Log.Error("[ticket plan] failed to approve plan %s: %v", planUUID, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+	}
+
+	for _, step := range steps {
+		response.Items = append(response.Items, step.item)
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newApprovalPlan(stubs ...db.StubTicket) *db.TicketPlan {
+	return &db.TicketPlan{
+		UUID:          uuid.New(),
+		WorkspaceUuid: "workspace-1",
+		FeatureUUID:   "feature-1",
+		PhaseUUID:     "phase-1",
+		Name:          "Phase plan",
+		Status:        db.DraftPlan,
+		Version:       1,
+		StubTickets:   db.PlanStubTickets(stubs),
+	}
+}
+
+func mockPlanApproval(mockDb *datamocks.Database, plan *db.TicketPlan, links []db.TicketPlanTicket) {
+	mockDb.On("GetTicketPlan", plan.UUID.String()).Return(plan, nil)
+	mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: "test-pubkey"})
+	mockDb.On("GetTicketPlanTickets", plan.UUID.String()).Return(links, nil)
+}
+
+func mockPlanSave(mockDb *datamocks.Database) {
+	mockDb.On("InTransaction", mock.Anything).Return(func(fn func(db.Database) error) error { return fn(mockDb) })
+	mockDb.On("CreateOrEditTicketPlan", mock.AnythingOfType("*db.TicketPlan")).Return(func(plan *db.TicketPlan) *db.TicketPlan {
+		saved := *plan
+		saved.Version++
+		return &saved
+	}, nil)
+}
+
+func approvePlan(th *ticketHandler, plan *db.TicketPlan, body string) (*httptest.ResponseRecorder, TicketPlanApprovalResponse) {
+	rr := httptest.NewRecorder()
+	th.ApproveTicketPlan(rr, newBranchRequest(http.MethodPost, "/plan/approve", body, map[string]string{"uuid": plan.UUID.String()}))
+	var response TicketPlanApprovalResponse
+	json.NewDecoder(rr.Body).Decode(&response)
+	return rr, response
+}
+
+func TestApproveTicketPlan(t *testing.T) {
+	t.Run("Materializes Stub Tickets", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		plan := newApprovalPlan(
+			db.StubTicket{TicketName: "Schema", TicketDescription: "Add tables", Reasoning: "Needed first"},
+			db.StubTicket{TicketName: "API", TicketDescription: "Add endpoints", DependsOn: []string{"schema"}},
+		)
+		mockPlanApproval(mockDb, plan, nil)
+		mockPlanSave(mockDb)
+		var tickets []db.Tickets
+		mockDb.On("CreateOrEditTicket", mock.AnythingOfType("*db.Tickets")).Return(func(ticket *db.Tickets) db.Tickets {
+			tickets = append(tickets, *ticket)
+			return *ticket
+		}, nil)
+		var links []db.TicketPlanTicket
+		mockDb.On("CreateTicketPlanTicket", mock.AnythingOfType("*db.TicketPlanTicket")).Return(func(link *db.TicketPlanTicket) db.TicketPlanTicket {
+			links = append(links, *link)
+			return *link
+		}, nil)
+
+		rr, response := approvePlan(NewTicketHandler(&http.Client{}, mockDb), plan, "")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, db.ApprovedPlan, response.Status)
+		assert.Equal(t, 2, response.Version)
+		require.Len(t, response.Items, 2)
+		assert.Equal(t, PlanTicketCreate, response.Items[0].Action)
+		assert.Equal(t, PlanTicketCreate, response.Items[1].Action)
+
+		require.Len(t, tickets, 2)
+		assert.Equal(t, "Add tables\n\nReasoning: Needed first", tickets[0].Description)
+		assert.Equal(t, 0, tickets[0].Sequence)
+		assert.Equal(t, 1, tickets[1].Sequence)
+		assert.Equal(t, db.DraftTicket, tickets[1].Status)
+		assert.Equal(t, "phase-1", tickets[1].PhaseUUID)
+		assert.Equal(t, []string{tickets[0].TicketGroup.String()}, []string(tickets[1].DependsOn))
+
+		require.Len(t, links, 2)
+		assert.Equal(t, "schema", links[0].StubKey)
+		assert.Equal(t, tickets[0].TicketGroup, links[0].TicketGroup)
+		assert.Equal(t, 2, links[0].PlanVersion)
+		assert.ElementsMatch(t, []string{tickets[0].TicketGroup.String(), tickets[1].TicketGroup.String()}, []string(plan.TicketGroups))
+	})
+
+	t.Run("Re-Running A Revised Plan Diffs Its Tickets", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		same := newGraphTicket(db.InProgressTicket)
+		same.Name = "Schema"
+		same.Description = "Add tables"
+		renamed := newGraphTicket(db.DraftTicket)
+		renamed.Name = "API"
+		renamed.Sequence = 1
+		dropped := newGraphTicket(db.DraftTicket)
+		links := []db.TicketPlanTicket{
+			{ID: uuid.New(), StubKey: "schema", TicketGroup: same.TicketGroup},
+			{ID: uuid.New(), StubKey: "api", TicketGroup: renamed.TicketGroup},
+			{ID: uuid.New(), StubKey: "docs", TicketGroup: dropped.TicketGroup},
+		}
+		plan := newApprovalPlan(
+			db.StubTicket{Key: "schema", TicketName: "Schema", TicketDescription: "Add tables"},
+			db.StubTicket{Key: "api", TicketName: "REST API", TicketDescription: "Add endpoints"},
+			db.StubTicket{Key: "ui", TicketName: "UI", DependsOn: []string{"api"}},
+		)
+		plan.Status = db.ApprovedPlan
+		mockPlanApproval(mockDb, plan, links)
+		mockPlanSave(mockDb)
+		mockDb.On("GetLatestTicketByGroup", *same.TicketGroup).Return(same, nil)
+		mockDb.On("GetLatestTicketByGroup", *renamed.TicketGroup).Return(renamed, nil)
+		mockDb.On("GetLatestTicketByGroup", *dropped.TicketGroup).Return(dropped, nil)
+		var tickets []db.Tickets
+		mockDb.On("CreateOrEditTicket", mock.AnythingOfType("*db.Tickets")).Return(func(ticket *db.Tickets) db.Tickets {
+			tickets = append(tickets, *ticket)
+			return *ticket
+		}, nil)
+		mockDb.On("UpdateTicketPlanTicket", mock.MatchedBy(func(link *db.TicketPlanTicket) bool {
+			return link.ID == links[1].ID && link.PlanVersion == 2
+		})).Return(nil)
+		mockDb.On("CreateTicketPlanTicket", mock.AnythingOfType("*db.TicketPlanTicket")).Return(func(link *db.TicketPlanTicket) db.TicketPlanTicket { return *link }, nil)
+		mockDb.On("DeleteTicketGroup", *dropped.TicketGroup).Return(nil)
+		mockDb.On("DeleteTicketPlanTicket", links[2].ID).Return(nil)
+
+		rr, response := approvePlan(NewTicketHandler(&http.Client{}, mockDb), plan, "")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Len(t, response.Items, 4)
+		assert.Equal(t, PlanTicketUnchanged, response.Items[0].Action)
+		assert.Equal(t, PlanTicketUpdate, response.Items[1].Action)
+		assert.Equal(t, PlanTicketCreate, response.Items[2].Action)
+		assert.Equal(t, PlanTicketRemove, response.Items[3].Action)
+		assert.Equal(t, "draft ticket deleted", response.Items[3].Reason)
+
+		require.Len(t, tickets, 2)
+		assert.Equal(t, "REST API", tickets[0].Name)
+		assert.Equal(t, *renamed.TicketGroup, *tickets[0].TicketGroup)
+		assert.Equal(t, renamed.Version+1, tickets[0].Version)
+		assert.Equal(t, planApprovalNote, tickets[0].RevisionNote)
+		assert.Equal(t, []string{renamed.TicketGroup.String()}, []string(tickets[1].DependsOn))
+	})
+
+	t.Run("Dry Run Saves Nothing", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		plan := newApprovalPlan(db.StubTicket{TicketName: "Schema"})
+		mockPlanApproval(mockDb, plan, nil)
+
+		rr, response := approvePlan(NewTicketHandler(&http.Client{}, mockDb), plan, `{"dry_run":true}`)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.True(t, response.DryRun)
+		assert.Equal(t, db.DraftPlan, response.Status)
+		require.Len(t, response.Items, 1)
+		assert.Equal(t, PlanTicketCreate, response.Items[0].Action)
+		mockDb.AssertNotCalled(t, "InTransaction", mock.Anything)
+	})
+
+	t.Run("Rejects Invalid Plans", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			stubs []db.StubTicket
+			error string
+		}{
+			{"No Stubs", nil, "the plan has no stub tickets"},
+			{"Unknown Dependency", []db.StubTicket{{TicketName: "A", DependsOn: []string{"B"}}}, `stub ticket "a" depends on unknown stub "B"`},
+			{"Cycle", []db.StubTicket{{TicketName: "A", DependsOn: []string{"B"}}, {TicketName: "B", DependsOn: []string{"A"}}}, "stub ticket dependencies form a cycle"},
+			{"Duplicate Keys", []db.StubTicket{{TicketName: "A"}, {TicketName: "a"}}, `stub ticket key "a" is used twice`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockDb := datamocks.NewDatabase(t)
+				plan := newApprovalPlan(tt.stubs...)
+				mockDb.On("GetTicketPlan", plan.UUID.String()).Return(plan, nil)
+				mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: "test-pubkey"})
+				if len(tt.stubs) > 0 {
+					mockDb.On("GetTicketPlanTickets", plan.UUID.String()).Return(nil, nil)
+				}
+
+				rr := httptest.NewRecorder()
+				NewTicketHandler(&http.Client{}, mockDb).ApproveTicketPlan(rr, newBranchRequest(http.MethodPost, "/plan/approve", "", map[string]string{"uuid": plan.UUID.String()}))
+
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				var body map[string]string
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+				assert.Equal(t, tt.error, body["error"])
+			})
+		}
+	})
+
+	t.Run("Converts To Bounties Within Budget", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		plan := newApprovalPlan(
+			db.StubTicket{TicketName: "Schema", Amount: 3000},
+			db.StubTicket{TicketName: "API", Amount: 2000},
+		)
+		mockPlanApproval(mockDb, plan, nil)
+		mockDb.On("GetWorkspaceStatusBudget", "workspace-1").Return(db.StatusBudget{CurrentBudget: 5000})
+		mockDb.On("GetDefaultWorkspaceTemplate", "workspace-1", db.BountyTemplateKind).Return(db.BuiltinWorkspaceTemplate("workspace-1", db.BountyTemplateKind), nil)
+		mockPlanSave(mockDb)
+		bountyID := uint(0)
+		mockDb.On("CreateBountyFromTicket", mock.AnythingOfType("db.Tickets"), "test-pubkey", mock.AnythingOfType("db.WorkspaceTemplate")).Return(func(ticket db.Tickets, pubkey string, template db.WorkspaceTemplate) *db.NewBounty {
+			bountyID++
+			return &db.NewBounty{ID: bountyID, Title: ticket.Name, Price: uint(*ticket.Amount)}
+		}, nil)
+		var links []db.TicketPlanTicket
+		mockDb.On("CreateTicketPlanTicket", mock.AnythingOfType("*db.TicketPlanTicket")).Return(func(link *db.TicketPlanTicket) db.TicketPlanTicket {
+			links = append(links, *link)
+			return *link
+		}, nil)
+
+		rr, response := approvePlan(NewTicketHandler(&http.Client{}, mockDb), plan, `{"to_bounty":true}`)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, int64(5000), response.BountyTotal)
+		assert.Equal(t, uint(2), response.Items[1].BountyID)
+		assert.Empty(t, response.Items[1].TicketGroup)
+		require.Len(t, links, 2)
+		assert.Nil(t, links[0].TicketGroup)
+		assert.Equal(t, uint(1), *links[0].BountyID)
+		assert.Empty(t, plan.TicketGroups)
+		mockDb.AssertNotCalled(t, "CreateOrEditTicket", mock.Anything)
+	})
+
+	t.Run("Bounties Over Budget", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		plan := newApprovalPlan(db.StubTicket{TicketName: "Schema", Amount: 6000})
+		mockPlanApproval(mockDb, plan, nil)
+		mockDb.On("GetWorkspaceStatusBudget", "workspace-1").Return(db.StatusBudget{CurrentBudget: 5000})
+
+		rr, _ := approvePlan(NewTicketHandler(&http.Client{}, mockDb), plan, `{"to_bounty":true}`)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockDb.AssertNotCalled(t, "InTransaction", mock.Anything)
+	})
+
+	t.Run("Unpaid Bounties Hold Budget", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		plan := newApprovalPlan(db.StubTicket{TicketName: "Schema", Amount: 3000})
+		mockPlanApproval(mockDb, plan, nil)
+		mockDb.On("GetWorkspaceStatusBudget", "workspace-1").Return(db.StatusBudget{CurrentBudget: 5000, OpenBudget: 1000, AssignedBudget: 1000, CompletedBudget: 500})
+
+		rr, _ := approvePlan(NewTicketHandler(&http.Client{}, mockDb), plan, `{"to_bounty":true}`)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockDb.AssertNotCalled(t, "InTransaction", mock.Anything)
+	})
+
+	t.Run("Tickets Past Draft Stay Tickets", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		plan := newApprovalPlan(db.StubTicket{TicketName: "Schema", Amount: 3000})
+		group := uuid.New()
+		link := db.TicketPlanTicket{ID: uuid.New(), PlanUUID: plan.UUID, StubKey: "schema", TicketGroup: &group}
+		mockPlanApproval(mockDb, plan, []db.TicketPlanTicket{link})
+		mockDb.On("GetLatestTicketByGroup", group).Return(db.Tickets{UUID: uuid.New(), TicketGroup: &group, Name: "Schema", Status: db.InProgressTicket, Version: 2}, nil)
+		mockDb.On("GetWorkspaceStatusBudget", "workspace-1").Return(db.StatusBudget{CurrentBudget: 5000})
+		mockDb.On("GetDefaultWorkspaceTemplate", "workspace-1", db.BountyTemplateKind).Return(db.BuiltinWorkspaceTemplate("workspace-1", db.BountyTemplateKind), nil)
+		mockPlanSave(mockDb)
+
+		rr, response := approvePlan(NewTicketHandler(&http.Client{}, mockDb), plan, `{"to_bounty":true}`)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, int64(0), response.BountyTotal)
+		require.Len(t, response.Items, 1)
+		assert.Equal(t, PlanTicketUnchanged, response.Items[0].Action)
+		assert.Equal(t, group.String(), response.Items[0].TicketGroup)
+		assert.Contains(t, response.Items[0].Reason, "past draft")
+		mockDb.AssertNotCalled(t, "CreateBountyFromTicket", mock.Anything, mock.Anything, mock.Anything)
+		mockDb.AssertNotCalled(t, "DeleteTicketGroup", mock.Anything)
+	})
+
+	t.Run("Members Only", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		plan := newApprovalPlan(db.StubTicket{TicketName: "Schema"})
+		mockDb.On("GetTicketPlan", plan.UUID.String()).Return(plan, nil)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: "owner-pubkey"})
+		mockDb.On("GetWorkspaceUser", "test-pubkey", "workspace-1").Return(db.WorkspaceUsers{})
+
+		rr, _ := approvePlan(NewTicketHandler(&http.Client{}, mockDb), plan, "")
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestProcessTicketPlanReviewStoresStubTickets(t *testing.T) {
+	mockDb := datamocks.NewDatabase(t)
+	plan := newApprovalPlan()
+	plan.Status = db.ApprovedPlan
+	mockDb.On("GetFeatureByUuid", "feature-1").Return(db.WorkspaceFeatures{Uuid: "feature-1", WorkspaceUuid: "workspace-1"})
+	mockDb.On("GetPhaseByUuid", "phase-1").Return(db.FeaturePhase{Uuid: "phase-1"}, nil)
+	mockDb.On("GetTicketPlan", plan.UUID.String()).Return(plan, nil)
+	var saved db.TicketPlan
+	mockDb.On("CreateOrEditTicketPlan", mock.AnythingOfType("*db.TicketPlan")).Return(func(p *db.TicketPlan) *db.TicketPlan {
+		saved = *p
+		return p
+	}, nil)
+
+	body := `{"ticketPlanUUID":"` + plan.UUID.String() + `","value":{"featureUUID":"feature-1","phaseUUID":"phase-1","phasePlan":{"stubTickets":[{"ticketName":"Schema","ticketDescription":"Add tables"}]}}}`
+	rr := httptest.NewRecorder()
+	NewTicketHandler(&http.Client{}, mockDb).ProcessTicketPlanReview(rr, newBranchRequest(http.MethodPost, "/plan/review", body, nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, db.DraftPlan, saved.Status)
+	require.Len(t, saved.StubTickets, 1)
+	assert.Equal(t, "Schema", saved.StubTickets[0].TicketName)
+	mockDb.AssertNotCalled(t, "CreateOrEditTicket", mock.Anything)
+}
+
+func TestProcessTicketPlanReviewRejectsPlanOfAnotherPhase(t *testing.T) {
+	mockDb := datamocks.NewDatabase(t)
+	plan := newApprovalPlan()
+	plan.PhaseUUID = "phase-2"
+	mockDb.On("GetFeatureByUuid", "feature-1").Return(db.WorkspaceFeatures{Uuid: "feature-1", WorkspaceUuid: "workspace-1"})
+	mockDb.On("GetPhaseByUuid", "phase-1").Return(db.FeaturePhase{Uuid: "phase-1", FeatureUuid: "feature-1"}, nil)
+	mockDb.On("GetTicketPlan", plan.UUID.String()).Return(plan, nil)
+
+	body := `{"ticketPlanUUID":"` + plan.UUID.String() + `","value":{"featureUUID":"feature-1","phaseUUID":"phase-1","phasePlan":{"stubTickets":[{"ticketName":"Schema"}]}}}`
+	rr := httptest.NewRecorder()
+	NewTicketHandler(&http.Client{}, mockDb).ProcessTicketPlanReview(rr, newBranchRequest(http.MethodPost, "/plan/review", body, nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockDb.AssertNotCalled(t, "CreateOrEditTicketPlan", mock.Anything)
+}
+
+func TestSendTicketPlanToStakworkChecksThePlan(t *testing.T) {
+	send := func(mockDb *datamocks.Database, planID string) *httptest.ResponseRecorder {
+		body := `{"feature_id":"feature-1","phase_id":"phase-1","plan_id":"` + planID + `","ticket_group_ids":["` + uuid.New().String() + `"]}`
+		rr := httptest.NewRecorder()
+		NewTicketHandler(&http.Client{}, mockDb).SendTicketPlanToStakwork(rr, newBranchRequest(http.MethodPost, "/plan/send", body, nil))
+		return rr
+	}
+	newMockDb := func(t *testing.T, owner string) *datamocks.Database {
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetPersonByPubkey", "test-pubkey").Return(db.Person{OwnerPubKey: "test-pubkey"})
+		mockDb.On("GetFeatureByUuid", "feature-1").Return(db.WorkspaceFeatures{Uuid: "feature-1", WorkspaceUuid: "workspace-1"})
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: owner})
+		return mockDb
+	}
+
+	t.Run("Outsider Is Forbidden", func(t *testing.T) {
+		mockDb := newMockDb(t, "someone-else")
+		mockDb.On("GetWorkspaceUser", "test-pubkey", "workspace-1").Return(db.WorkspaceUsers{})
+
+		rr := send(mockDb, "")
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Plan Of Another Workspace", func(t *testing.T) {
+		mockDb := newMockDb(t, "test-pubkey")
+		plan := newApprovalPlan()
+		plan.WorkspaceUuid = "workspace-2"
+		mockDb.On("GetPhaseByUuid", "phase-1").Return(db.FeaturePhase{Uuid: "phase-1", FeatureUuid: "feature-1"}, nil)
+		mockDb.On("GetTicketPlan", plan.UUID.String()).Return(plan, nil)
+
+		rr := send(mockDb, plan.UUID.String())
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "plan_id belongs to another feature phase")
+	})
+
+	t.Run("Phase Of Another Feature", func(t *testing.T) {
+		mockDb := newMockDb(t, "test-pubkey")
+		mockDb.On("GetPhaseByUuid", "phase-1").Return(db.FeaturePhase{Uuid: "phase-1", FeatureUuid: "feature-2"}, nil)
+
+		rr := send(mockDb, "")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -45,6 +45,8 @@ type SendTicketPlanRequest struct {
 	TicketGroupIDs  []string `json:"ticket_group_ids"`
 	SourceWebsocket string   `json:"source_websocket"`
 	RequestUUID     string   `json:"request_uuid"`
+	// PlanID is the plan the generated stub tickets are stored on.
+	PlanID string `json:"plan_id,omitempty"`
 }
 
 type SendTicketPlanResponse struct {
@@ -449,6 +451,57 @@ func (th *ticketHandler) SendTicketPlanToStakwork(w http.ResponseWriter, r *http
 		return
 	}
 
+	workspace := th.db.GetWorkspaceByUuid(feature.WorkspaceUuid)
+	if workspace.Uuid == "" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(TicketPlanResponse{
+			Success: false,
+			Message: "Workspace not found",
+		})
+		return
+	}
+	if workspace.OwnerPubKey != pubKeyFromAuth && th.db.GetWorkspaceUser(pubKeyFromAuth, workspace.Uuid).OwnerPubKey != pubKeyFromAuth {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(TicketPlanResponse{
+			Success: false,
+			Message: "Only workspace members can send a ticket plan",
+		})
+		return
+	}
+
+	phase, err := th.db.GetPhaseByUuid(planRequest.PhaseID)
+	if err != nil || phase.FeatureUuid != feature.Uuid {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(TicketPlanResponse{
+			Success: false,
+			Message: "Phase not found in the feature",
+		})
+		return
+	}
+
+	// a named plan is revised in place, so it has to be the plan of this
+	// feature phase
+	if planRequest.PlanID != "" {
+		plan, err := th.db.GetTicketPlan(planRequest.PlanID)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(TicketPlanResponse{
+				Success: false,
+				Message: "Ticket plan not found",
+			})
+			return
+		}
+		if plan.WorkspaceUuid != feature.WorkspaceUuid || plan.FeatureUUID != planRequest.FeatureID || plan.PhaseUUID != planRequest.PhaseID {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TicketPlanResponse{
+				Success: false,
+				Message: "Validation failed",
+				Errors:  []string{"plan_id belongs to another feature phase"},
+			})
+			return
+		}
+	}
+
 	productBrief, err = th.db.GetProductBrief(feature.WorkspaceUuid)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -494,38 +547,20 @@ func (th *ticketHandler) SendTicketPlanToStakwork(w http.ResponseWriter, r *http
 
 	webhookURL := fmt.Sprintf("%s/bounties/ticket/plan/review", host)
 
-	var schematicURL string
-	if feature.WorkspaceUuid != "" {
-		workspace := th.db.GetWorkspaceByUuid(feature.WorkspaceUuid)
-		if workspace.Uuid == "" {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(TicketPlanResponse{
-				Success: false,
-				Message: "Workspace not found",
-			})
-			return
-		}
-
-		schematicURL = workspace.SchematicUrl
-
-		codeGraph, err := th.db.GetCodeGraphByWorkspaceUuid(feature.WorkspaceUuid)
-		if err == nil {
-			codeGraphURL = codeGraph.Url
-			codeGraphAlias = codeGraph.SecretAlias
-		} else {
-			codeGraphURL = ""
-			codeGraphAlias = ""
-		}
-	}
-
-	phase, err := th.db.GetPhaseByUuid(planRequest.PhaseID)
-	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		return
+	schematicURL := workspace.SchematicUrl
+	codeGraph, err := th.db.GetCodeGraphByWorkspaceUuid(feature.WorkspaceUuid)
+	if err == nil {
+		codeGraphURL = codeGraph.Url
+		codeGraphAlias = codeGraph.SecretAlias
 	}
 
 	ticketArray := th.db.BuildTicketArray(planRequest.TicketGroupIDs)
 
+	ticketPlanUUID := planRequest.PlanID
+	if ticketPlanUUID == "" {
+		ticketPlanUUID = uuid.New().String()
+	}
+
 	stakworkPayload := map[string]interface{}{
 		"name":        "Ticket Plan Builder",
 		"workflow_id": 42472,
@@ -535,7 +570,7 @@ func (th *ticketHandler) SendTicketPlanToStakwork(w http.ResponseWriter, r *http
 					"vars": map[string]interface{}{
 						"featureUUID":     planRequest.FeatureID,
 						"phaseUUID":       planRequest.PhaseID,
-						"ticketPlanUUID":  uuid.New().String(),
+						"ticketPlanUUID":  ticketPlanUUID,
 						"phaseOutcome":    phase.PhaseOutcome,
 						"phasePurpose":    phase.PhasePurpose,
 						"phaseScope":      phase.PhaseScope,
@@ -755,6 +790,21 @@ func (th *ticketHandler) ProcessTicketPlanReview(w http.ResponseWriter, r *http.
 		return
 	}
 
+	if planReview.TicketPlanUUID != "" {
+		if plan, err := th.db.GetTicketPlan(planReview.TicketPlanUUID); err == nil {
+			if plan.FeatureUUID != feature.Uuid || plan.PhaseUUID != phase.Uuid {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(db.TicketPlanReviewResponse{
+					Success: false,
+					Message: "The ticket plan belongs to another feature phase",
+				})
+				return
+			}
+			th.storePlanStubTickets(w, plan, planReview)
+			return
+		}
+	}
+
 	var createdTickets []db.Tickets
 	for i, stub := range planReview.Value.PhasePlan.StubTickets {
 		ticketGroup := uuid.New()
@@ -834,3 +884,46 @@ func (th *ticketHandler) ProcessTicketPlanReview(w http.ResponseWriter, r *http.
 		Message: fmt.Sprintf("Successfully created %d tickets", len(createdTickets)),
 	})
 }
+
+// storePlanStubTickets keeps the stub tickets of a plan review on the plan,
+// to be turned into tickets when the plan is approved. The plan goes back
+// to draft until then.
+func (th *ticketHandler) storePlanStubTickets(w http.ResponseWriter, plan *db.TicketPlan, planReview db.TicketPlanReviewRequest) {
+	plan.StubTickets = db.PlanStubTickets(planReview.Value.PhasePlan.StubTickets)
+	plan.Status = db.DraftPlan
+	savedPlan, err := th.db.CreateOrEditTicketPlan(plan)
+	if err != nil {
+		log.Printf("Error storing plan stub tickets: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(db.TicketPlanReviewResponse{
+			Success: false,
+			Message: "Error storing plan stub tickets",
+			Errors:  []string{err.Error()},
+		})
+		return
+	}
+
+	if planReview.SourceWebsocket != "" {
+		completionMsg := websocket.TicketPlanMessage{
+			BroadcastType:   "direct",
+			SourceSessionID: planReview.SourceWebsocket,
+			Message:         fmt.Sprintf("Received %d stub tickets for plan %s", len(savedPlan.StubTickets), savedPlan.Name),
+			Action:          "TICKET_PLAN_COMPLETED",
+			PlanDetails: websocket.TicketPlanDetails{
+				RequestUUID: planReview.RequestUUID,
+				FeatureUUID: planReview.Value.FeatureUUID,
+				PhaseUUID:   planReview.Value.PhaseUUID,
+			},
+		}
+
+		if err := websocket.WebsocketPool.SendTicketPlanMessage(completionMsg); err != nil {
+			log.Printf("Failed to send completion websocket message: %v", err)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(db.TicketPlanReviewResponse{
+		Success: true,
+		Message: fmt.Sprintf("Stored %d stub tickets on plan %s", len(savedPlan.StubTickets), savedPlan.UUID.String()),
+	})
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type TicketRevisionDiff struct {
+	TicketGroup uuid.UUID              `json:"ticket_group"`
+	From        db.TicketRevision      `json:"from"`
+	To          db.TicketRevision      `json:"to"`
+	Changes     []db.TicketFieldChange `json:"changes"`
+}
+
+// ticketGroupParam parses the ticket group of a request, writing the error
+// response when it is missing or invalid.
+func ticketGroupParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+
+	group, err := uuid.Parse(chi.URLParam(r, "group_uuid"))
+	if err != nil {
+		http.Error(w, "invalid ticket group UUID format", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return group, true
+}
+
+// GetTicketRevisions godoc
+//
+//	@Summary		List ticket revisions
+//	@Description	List every revision of a ticket group, oldest first, with its author and content
+//	@Tags			Bounty Tickets
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			group_uuid	path	string	true	"Ticket group UUID"
+//	@Success		200			{array}	db.TicketRevision
+//	@Router			/bounties/ticket/group/{group_uuid}/revisions [get]
+func (th *ticketHandler) GetTicketRevisions(w http.ResponseWriter, r *http.Request) {
+	group, ok := ticketGroupParam(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, err := th.db.GetTicketRevisions(group)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// DiffTicketRevisions godoc
+//
+//	@Summary		Diff ticket revisions
+//	@Description	List the fields that changed between two revisions of a ticket group
+//	@Tags			Bounty Tickets
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			group_uuid	path		string	true	"Ticket group UUID"
+//	@Param			from		query		int		true	"Older version"
+//	@Param			to			query		int		true	"Newer version"
+//	@Success		200			{object}	TicketRevisionDiff
+//	@Router			/bounties/ticket/group/{group_uuid}/revisions/diff [get]
+func (th *ticketHandler) DiffTicketRevisions(w http.ResponseWriter, r *http.Request) {
+	group, ok := ticketGroupParam(w, r)
+	if !ok {
+		return
+	}
+
+	fromVersion, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+	toVersion, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+	if errFrom != nil || errTo != nil {
+		http.Error(w, "from and to versions are required", http.StatusBadRequest)
+		return
+	}
+
+	from, err := th.db.GetTicketRevision(group, fromVersion)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("version %d: %v", fromVersion, err)})
+		return
+	}
+	to, err := th.db.GetTicketRevision(group, toVersion)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("version %d: %v", toVersion, err)})
+		return
+	}
+
+	changes, err := db.DiffTicketSnapshots(from.Snapshot, to.Snapshot)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(TicketRevisionDiff{
+		TicketGroup: group,
+		From:        from,
+		To:          to,
+		Changes:     changes,
+	})
+}
+
+// RestoreTicketRevision godoc
+//
+//	@Summary		Restore a ticket revision
+//	@Description	Save the content of an old revision as a new version of the ticket group
+//	@Tags			Bounty Tickets
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			group_uuid	path		string	true	"Ticket group UUID"
+//	@Param			version		path		int		true	"Version to restore"
+//	@Success		200			{object}	db.Tickets
+//	@Router			/bounties/ticket/group/{group_uuid}/revisions/{version}/restore [post]
+func (th *ticketHandler) RestoreTicketRevision(w http.ResponseWriter, r *http.Request) {
+	group, ok := ticketGroupParam(w, r)
+	if !ok {
+		return
+	}
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil {
+		http.Error(w, "invalid version", http.StatusBadRequest)
+		return
+	}
+
+	revision, err := th.db.GetTicketRevision(group, version)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	latest, err := th.db.GetLatestTicketByGroup(group)
+	if err != nil {
+		latest, err = th.db.GetTicket(group.String())
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Ticket not found"})
+		return
+	}
+
+	author := db.HumanAuthor
+	note := fmt.Sprintf("restored version %d", version)
+
+	// drafts are edited in place, tickets of a phase get a new version
+	if latest.FeatureUUID == "" {
+		draft := latest
+		revision.Snapshot.Apply(&draft)
+		draft.Author = &author
+		draft.AuthorID = &pubKeyFromAuth
+		draft.RevisionNote = note
+		if !th.checkTicketWorkflow(w, &draft, latest.Status) {
+			return
+		}
+		restored, err := th.db.UpdateWorkspaceDraftTicket(&draft)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to restore ticket: %v", err)})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(restored)
+		return
+	}
+
+	ticketGroup := group
+	newTicket := db.Tickets{
+		UUID:         uuid.New(),
+		TicketGroup:  &ticketGroup,
+		Dependency:   latest.Dependency,
+		Version:      latest.Version + 1,
+		Author:       &author,
+		AuthorID:     &pubKeyFromAuth,
+		Mode:         latest.Mode,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		RevisionNote: note,
+		// the issue link is not part of the snapshot
+		GithubIssueURL: latest.GithubIssueURL,
+	}
+	revision.Snapshot.Apply(&newTicket)
+
+	if !th.checkTicketDependencies(w, &newTicket) {
+		return
+	}
+	if !th.checkTicketWorkflow(w, &newTicket, latest.Status) {
+		return
+	}
+
+	restored, err := th.db.CreateOrEditTicket(&newTicket)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to restore ticket: %v", err)})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(restored)
+}
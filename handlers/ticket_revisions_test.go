@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTicketRevisions(t *testing.T) {
+	group := uuid.New()
+	revisions := []db.TicketRevision{
+		{TicketGroup: group, Version: 1, Author: db.HumanAuthor, Snapshot: db.TicketSnapshot{Name: "Login", Description: "Add login", Status: db.DraftTicket, FeatureUUID: "feature-1", PhaseUUID: "phase-1"}},
+		{TicketGroup: group, Version: 2, Author: db.AgentAuthor, Snapshot: db.TicketSnapshot{Name: "Login", Description: "Add LNURL login", Status: db.ReadyTicket, FeatureUUID: "feature-1", PhaseUUID: "phase-1"}},
+	}
+	params := func(extra map[string]string) map[string]string {
+		p := map[string]string{"group_uuid": group.String()}
+		for k, v := range extra {
+			p[k] = v
+		}
+		return p
+	}
+
+	t.Run("List", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		mockDb.On("GetTicketRevisions", group).Return(revisions, nil)
+
+		rr := httptest.NewRecorder()
+		th.GetTicketRevisions(rr, newBranchRequest(http.MethodGet, "/revisions", "", params(nil)))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var listed []db.TicketRevision
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&listed))
+		assert.Len(t, listed, 2)
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		mockDb.On("GetTicketRevision", group, 1).Return(revisions[0], nil)
+		mockDb.On("GetTicketRevision", group, 2).Return(revisions[1], nil)
+
+		rr := httptest.NewRecorder()
+		th.DiffTicketRevisions(rr, newBranchRequest(http.MethodGet, "/revisions/diff?from=1&to=2", "", params(nil)))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var diff TicketRevisionDiff
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&diff))
+		require.Len(t, diff.Changes, 2)
+		assert.Equal(t, "description", diff.Changes[0].Field)
+		assert.Equal(t, "status", diff.Changes[1].Field)
+	})
+
+	t.Run("Diff Unknown Version", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		mockDb.On("GetTicketRevision", group, 1).Return(revisions[0], nil)
+		mockDb.On("GetTicketRevision", group, 7).Return(db.TicketRevision{}, errors.New("ticket revision not found"))
+
+		rr := httptest.NewRecorder()
+		th.DiffTicketRevisions(rr, newBranchRequest(http.MethodGet, "/revisions/diff?from=1&to=7", "", params(nil)))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Restore As New Version", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		latest := db.Tickets{UUID: uuid.New(), TicketGroup: &group, FeatureUUID: "feature-1", PhaseUUID: "phase-1", Name: "Login", Description: "Add LNURL login", Status: db.ReadyTicket, Version: 2}
+		mockDb.On("GetTicketRevision", group, 1).Return(revisions[0], nil)
+		mockDb.On("GetLatestTicketByGroup", group).Return(latest, nil)
+		mockTicketWorkflow(mockDb, db.DefaultTicketWorkflow("workspace-1"))
+		mockDb.On("CreateOrEditTicket", mock.MatchedBy(func(ticket *db.Tickets) bool {
+			return ticket.UUID != latest.UUID && *ticket.TicketGroup == group && ticket.Version == 3 &&
+				ticket.Description == "Add login" && ticket.Status == db.DraftTicket &&
+				*ticket.Author == db.HumanAuthor && *ticket.AuthorID == "test-pubkey" &&
+				ticket.RevisionNote == "restored version 1"
+		})).Return(func(ticket *db.Tickets) db.Tickets { return *ticket }, nil)
+
+		rr := httptest.NewRecorder()
+		th.RestoreTicketRevision(rr, newBranchRequest(http.MethodPost, "/revisions/1/restore", "", params(map[string]string{"version": "1"})))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var restored db.Tickets
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&restored))
+		assert.Equal(t, "Add login", restored.Description)
+	})
+
+	t.Run("Restore Unknown Version", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		mockDb.On("GetTicketRevision", group, 5).Return(db.TicketRevision{}, errors.New("ticket revision not found"))
+
+		rr := httptest.NewRecorder()
+		th.RestoreTicketRevision(rr, newBranchRequest(http.MethodPost, "/revisions/5/restore", "", params(map[string]string{"version": "5"})))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
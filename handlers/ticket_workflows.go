@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type UpdateTicketWorkflowRequest struct {
+	Columns     db.WorkflowColumns     `json:"columns"`
+	Transitions db.WorkflowTransitions `json:"transitions"`
+}
+
+// BoardColumn is a column of a workspace board with the tickets and bounty
+// cards it holds.
+type BoardColumn struct {
+	db.WorkflowColumn
+	Tickets   []db.Tickets    `json:"tickets"`
+	Bounties  []db.BountyCard `json:"bounties"`
+	OverLimit bool            `json:"over_limit"`
+}
+
+// WorkspaceBoard groups the tickets and bounties of a workspace by the
+// columns of its workflow. Tickets and bounties whose status has no column
+// are listed apart.
+type WorkspaceBoard struct {
+	WorkspaceUuid    string            `json:"workspace_uuid"`
+	Workflow         db.TicketWorkflow `json:"workflow"`
+	Columns          []BoardColumn     `json:"columns"`
+	UnmappedTickets  []db.Tickets      `json:"unmapped_tickets"`
+	UnmappedBounties []db.BountyCard   `json:"unmapped_bounties"`
+}
+
+// ticketWorkspace is the workspace of a ticket, through its feature for
+// tickets of a phase.
+func ticketWorkspace(database db.Database, ticket db.Tickets) string {
+	if ticket.FeatureUUID != "" {
+		if feature := database.GetFeatureByUuid(ticket.FeatureUUID); feature.WorkspaceUuid != "" {
+			return feature.WorkspaceUuid
+		}
+	}
+	return ticket.WorkspaceUuid
+}
+
+// checkTicketWorkflow enforces the workflow of the ticket's workspace on a
+// new version of a ticket: its status must be a column reachable from the
+// previous status, a ticket entering the start column needs its blockers
+// done, and a ticket entering a column needs the column's required fields
+// and room under its WIP limit. Tickets without a status
+// are placed in the first column. It writes the error response and returns
+// false when the ticket cannot be saved.
+func (th *ticketHandler) checkTicketWorkflow(w http.ResponseWriter, ticket *db.Tickets, previousStatus db.TicketStatus) bool {
+	if err := ticketWorkflowError(th.db, ticket, previousStatus); err != nil {
+		err.write(w)
+		return false
+	}
+	return true
+}
+
+func ticketWorkflowError(database db.Database, ticket *db.Tickets, previousStatus db.TicketStatus) *ticketCheckError {
+	workspaceUuid := ticketWorkspace(database, *ticket)
+	if workspaceUuid == "" {
+		return nil
+	}
+
+	workflow, err := database.GetTicketWorkflow(workspaceUuid)
+	if err != nil {
+		return &ticketCheckError{status: http.StatusInternalServerError, message: err.Error()}
+	}
+
+	if ticket.Status == "" {
+		ticket.Status = workflow.Columns[0].Status
+	}
+	column, ok := workflow.Column(ticket.Status)
+	if !ok {
+		return &ticketCheckError{status: http.StatusBadRequest, message: fmt.Sprintf("status %s is not a column of the workspace workflow", ticket.Status)}
+	}
+	if ticket.Status == previousStatus {
+		return nil
+	}
+
+	if previousStatus != "" && !workflow.CanTransition(previousStatus, ticket.Status) {
+		return &ticketCheckError{status: http.StatusConflict, message: fmt.Sprintf("ticket cannot move from %s to %s", previousStatus, ticket.Status)}
+	}
+
+	if ticket.Status == workflow.StartStatus() {
+		if err := ticketBlockersError(database, *ticket, workflow.DoneStatus()); err != nil {
+			return err
+		}
+	}
+
+	if missing := db.MissingTicketFields(*ticket, column.RequiredFields); len(missing) > 0 {
+		return &ticketCheckError{
+			status:  http.StatusBadRequest,
+			message: fmt.Sprintf("column %s requires fields that are not set", column.Status),
+			extra:   map[string]interface{}{"missing_fields": missing},
+		}
+	}
+
+	if column.WIPLimit > 0 {
+		tickets, err := database.GetWorkspaceTickets(workspaceUuid)
+		if err != nil {
+			return &ticketCheckError{status: http.StatusInternalServerError, message: err.Error()}
+		}
+		group := db.TicketGroupID(*ticket)
+		count := 0
+		for _, other := range tickets {
+			if other.Status == column.Status && db.TicketGroupID(other) != group {
+				count++
+			}
+		}
+		if count >= column.WIPLimit {
+			return &ticketCheckError{status: http.StatusConflict, message: fmt.Sprintf("column %s is at its WIP limit of %d", column.Status, column.WIPLimit)}
+		}
+	}
+	return nil
+}
+
+// GetTicketWorkflow godoc
+//
+//	@Summary		Get the ticket workflow of a workspace
+//	@Description	Get the board columns and allowed transitions of a workspace, or the default workflow when it has none
+//	@Tags			Bounty Tickets
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Success		200				{object}	db.TicketWorkflow
+//	@Router			/bounties/ticket/workspace/{workspace_uuid}/workflow [get]
+func (th *ticketHandler) GetTicketWorkflow(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	if workspaceUuid == "" {
+		http.Error(w, "Missing workspace uuid", http.StatusBadRequest)
+		return
+	}
+
+	workflow, err := th.db.GetTicketWorkflow(workspaceUuid)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(workflow)
+}
+
+// UpdateTicketWorkflow godoc
+//
+//	@Summary		Update the ticket workflow of a workspace
+//	@Description	Replace the board columns and allowed transitions of a workspace
+//	@Tags			Bounty Tickets
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string						true	"Workspace UUID"
+//	@Param			workflow		body		UpdateTicketWorkflowRequest	true	"Workflow"
+//	@Success		200				{object}	db.TicketWorkflow
+//	@Router			/bounties/ticket/workspace/{workspace_uuid}/workflow [put]
+func (th *ticketHandler) UpdateTicketWorkflow(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	workspace := th.db.GetWorkspaceByUuid(workspaceUuid)
+	if workspace.Uuid == "" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "workspace not found"})
+		return
+	}
+	if workspace.OwnerPubKey != pubKeyFromAuth && th.db.GetWorkspaceUser(pubKeyFromAuth, workspaceUuid).OwnerPubKey != pubKeyFromAuth {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only workspace members can change its workflow"})
+		return
+	}
+
+	var request UpdateTicketWorkflowRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error parsing request body"})
+		return
+	}
+
+	workflow := db.TicketWorkflow{
+		WorkspaceUuid: workspaceUuid,
+		Columns:       request.Columns,
+		Transitions:   request.Transitions,
+		UpdatedBy:     pubKeyFromAuth,
+	}
+	if err := db.ValidateTicketWorkflow(workflow); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	saved, err := th.db.UpsertTicketWorkflow(&workflow)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(saved)
+}
+
+// GetWorkspaceBoard godoc
+//
+//	@Summary		Get the board of a workspace
+//	@Description	Get the tickets and bounty cards of a workspace grouped by the columns of its workflow
+//	@Tags			Bounties
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	query		string	true	"Workspace UUID"
+//	@Success		200				{object}	WorkspaceBoard
+//	@Router			/gobounties/board [get]
+func (h *bountyHandler) GetWorkspaceBoard(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := r.URL.Query().Get("workspace_uuid")
+	if workspaceUuid == "" {
+		http.Error(w, "Missing workspace uuid", http.StatusBadRequest)
+		return
+	}
+
+	workflow, err := h.db.GetTicketWorkflow(workspaceUuid)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	tickets, err := h.db.GetWorkspaceTickets(workspaceUuid)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	bounties := h.GenerateBountyCardResponse(h.db.GetWorkspaceBountyCardsData(r))
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(buildWorkspaceBoard(workflow, tickets, bounties))
+}
+
+func buildWorkspaceBoard(workflow db.TicketWorkflow, tickets []db.Tickets, bounties []db.BountyCard) WorkspaceBoard {
+	board := WorkspaceBoard{
+		WorkspaceUuid:    workflow.WorkspaceUuid,
+		Workflow:         workflow,
+		Columns:          make([]BoardColumn, len(workflow.Columns)),
+		UnmappedTickets:  []db.Tickets{},
+		UnmappedBounties: []db.BountyCard{},
+	}
+	index := map[db.TicketStatus]int{}
+	for i, column := range workflow.Columns {
+		board.Columns[i] = BoardColumn{WorkflowColumn: column, Tickets: []db.Tickets{}, Bounties: []db.BountyCard{}}
+		index[column.Status] = i
+	}
+
+	for _, ticket := range tickets {
+		i, ok := index[ticket.Status]
+		if !ok {
+			board.UnmappedTickets = append(board.UnmappedTickets, ticket)
+			continue
+		}
+		board.Columns[i].Tickets = append(board.Columns[i].Tickets, ticket)
+	}
+	for _, bounty := range bounties {
+		column, ok := workflow.BountyColumn(bounty.Status)
+		if !ok {
+			board.UnmappedBounties = append(board.UnmappedBounties, bounty)
+			continue
+		}
+		i := index[column.Status]
+		board.Columns[i].Bounties = append(board.Columns[i].Bounties, bounty)
+	}
+
+	for i, column := range board.Columns {
+		board.Columns[i].OverLimit = column.WIPLimit > 0 && len(column.Tickets) > column.WIPLimit
+	}
+	return board
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// mockTicketWorkflow places the tickets of feature-1 in workspace-1 with the
+// given workflow.
+func mockTicketWorkflow(mockDb *datamocks.Database, workflow db.TicketWorkflow) {
+	mockDb.On("GetFeatureByUuid", "feature-1").Return(db.WorkspaceFeatures{Uuid: "feature-1", WorkspaceUuid: "workspace-1"})
+	mockDb.On("GetTicketWorkflow", "workspace-1").Return(workflow, nil)
+}
+
+func kanbanWorkflow() db.TicketWorkflow {
+	return db.TicketWorkflow{
+		WorkspaceUuid: "workspace-1",
+		Columns: db.WorkflowColumns{
+			{Status: "BACKLOG", Name: "Backlog"},
+			{Status: "DOING", Name: "Doing", WIPLimit: 1, RequiredFields: []string{"description", "amount"}},
+			{Status: "DONE", Name: "Done", BountyStatuses: []db.BountyStatus{db.StatusPaid}},
+		},
+		Transitions: db.WorkflowTransitions{
+			{From: "BACKLOG", To: "DOING"},
+			{From: "DOING", To: "DONE"},
+		},
+	}
+}
+
+func TestUpdateTicketWorkflow(t *testing.T) {
+	update := func(th *ticketHandler, ticket db.Tickets) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(UpdateTicketRequest{Ticket: &ticket})
+		rr := httptest.NewRecorder()
+		th.UpdateTicket(rr, newBranchRequest(http.MethodPost, "/bounties/ticket/"+ticket.UUID.String(), string(body), map[string]string{"uuid": ticket.UUID.String()}))
+		return rr
+	}
+	amount := int64(1000)
+
+	t.Run("Rejects Status Outside The Workflow", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		ticket := newGraphTicket("BACKLOG")
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockTicketWorkflow(mockDb, kanbanWorkflow())
+
+		ticket.Status = db.ReadyTicket
+		rr := update(th, ticket)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "not a column")
+	})
+
+	t.Run("Rejects Disallowed Transition", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		ticket := newGraphTicket("BACKLOG")
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockTicketWorkflow(mockDb, kanbanWorkflow())
+
+		ticket.Status = "DONE"
+		rr := update(th, ticket)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Contains(t, rr.Body.String(), "cannot move from BACKLOG to DONE")
+	})
+
+	t.Run("Requires Column Fields", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		ticket := newGraphTicket("BACKLOG")
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockTicketWorkflow(mockDb, kanbanWorkflow())
+
+		ticket.Status = "DOING"
+		ticket.Description = "Add login"
+		rr := update(th, ticket)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, []interface{}{"amount"}, response["missing_fields"])
+	})
+
+	t.Run("Enforces WIP Limit", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		ticket := newGraphTicket("BACKLOG")
+		busy := newGraphTicket("DOING")
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockTicketWorkflow(mockDb, kanbanWorkflow())
+		mockDb.On("GetWorkspaceTickets", "workspace-1").Return([]db.Tickets{ticket, busy}, nil)
+
+		ticket.Status = "DOING"
+		ticket.Description = "Add login"
+		ticket.Amount = &amount
+		rr := update(th, ticket)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Contains(t, rr.Body.String(), "WIP limit of 1")
+	})
+
+	t.Run("Moves Along The Workflow", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		ticket := newGraphTicket("BACKLOG")
+		done := newGraphTicket("DONE")
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockTicketWorkflow(mockDb, kanbanWorkflow())
+		mockDb.On("GetWorkspaceTickets", "workspace-1").Return([]db.Tickets{ticket, done}, nil)
+		mockDb.On("CreateOrEditTicket", mock.MatchedBy(func(saved *db.Tickets) bool {
+			return saved.Status == "DOING" && saved.Version == 2
+		})).Return(func(saved *db.Tickets) db.Tickets { return *saved }, nil)
+
+		ticket.Status = "DOING"
+		ticket.Description = "Add login"
+		ticket.Amount = &amount
+		rr := update(th, ticket)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Saves The Assignee", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		ticket := newGraphTicket("BACKLOG")
+		ticket.Assignee = "old-pubkey"
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockTicketWorkflow(mockDb, kanbanWorkflow())
+		mockDb.On("CreateOrEditTicket", mock.MatchedBy(func(saved *db.Tickets) bool {
+			return saved.Assignee == "dev-pubkey" && saved.Version == 2
+		})).Return(func(saved *db.Tickets) db.Tickets { return *saved }, nil).Once()
+		mockDb.On("CreateOrEditTicket", mock.MatchedBy(func(saved *db.Tickets) bool {
+			return saved.Assignee == "old-pubkey" && saved.Version == 2
+		})).Return(func(saved *db.Tickets) db.Tickets { return *saved }, nil).Once()
+
+		reassigned := ticket
+		reassigned.Assignee = "dev-pubkey"
+		assert.Equal(t, http.StatusOK, update(th, reassigned).Code)
+
+		// clients that do not send an assignee keep the existing one
+		unchanged := ticket
+		unchanged.Assignee = ""
+		assert.Equal(t, http.StatusOK, update(th, unchanged).Code)
+	})
+
+	t.Run("Places Tickets Without Status In The First Column", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		ticket := newGraphTicket("")
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(db.Tickets{}, assert.AnError)
+		mockTicketWorkflow(mockDb, kanbanWorkflow())
+		mockDb.On("CreateOrEditTicket", mock.MatchedBy(func(saved *db.Tickets) bool {
+			return saved.Status == "BACKLOG"
+		})).Return(func(saved *db.Tickets) db.Tickets { return *saved }, nil)
+
+		rr := update(th, ticket)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestTicketWorkflowEndpoints(t *testing.T) {
+	params := map[string]string{"workspace_uuid": "workspace-1"}
+	workspace := db.Workspace{Uuid: "workspace-1", OwnerPubKey: "test-pubkey"}
+
+	t.Run("Get Default Workflow", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		mockDb.On("GetTicketWorkflow", "workspace-1").Return(db.DefaultTicketWorkflow("workspace-1"), nil)
+
+		rr := httptest.NewRecorder()
+		th.GetTicketWorkflow(rr, newBranchRequest(http.MethodGet, "/workflow", "", params))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var workflow db.TicketWorkflow
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&workflow))
+		assert.True(t, workflow.IsDefault)
+		assert.Len(t, workflow.Columns, 7)
+	})
+
+	t.Run("Update Requires Membership", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1", OwnerPubKey: "owner"})
+		mockDb.On("GetWorkspaceUser", "test-pubkey", "workspace-1").Return(db.WorkspaceUsers{})
+
+		rr := httptest.NewRecorder()
+		th.UpdateTicketWorkflow(rr, newBranchRequest(http.MethodPut, "/workflow", `{"columns":[{"status":"TODO"}]}`, params))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Update Rejects Invalid Workflow", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(workspace)
+
+		rr := httptest.NewRecorder()
+		th.UpdateTicketWorkflow(rr, newBranchRequest(http.MethodPut, "/workflow", `{"columns":[{"status":"TODO"},{"status":"TODO"}]}`, params))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "duplicate column status TODO")
+	})
+
+	t.Run("Update Saves Workflow", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(workspace)
+		mockDb.On("UpsertTicketWorkflow", mock.MatchedBy(func(workflow *db.TicketWorkflow) bool {
+			return workflow.WorkspaceUuid == "workspace-1" && workflow.UpdatedBy == "test-pubkey" && len(workflow.Columns) == 3
+		})).Return(func(workflow *db.TicketWorkflow) db.TicketWorkflow { return *workflow }, nil)
+
+		body, _ := json.Marshal(UpdateTicketWorkflowRequest{Columns: kanbanWorkflow().Columns, Transitions: kanbanWorkflow().Transitions})
+		rr := httptest.NewRecorder()
+		th.UpdateTicketWorkflow(rr, newBranchRequest(http.MethodPut, "/workflow", string(body), params))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestGetWorkspaceBoard(t *testing.T) {
+	mockDb := datamocks.NewDatabase(t)
+	bh := NewBountyHandler(&http.Client{}, mockDb)
+	backlog := newGraphTicket("BACKLOG")
+	doing := newGraphTicket("DOING")
+	doingToo := newGraphTicket("DOING")
+	stale := newGraphTicket(db.ReadyTicket)
+	mockDb.On("GetTicketWorkflow", "workspace-1").Return(kanbanWorkflow(), nil)
+	mockDb.On("GetWorkspaceTickets", "workspace-1").Return([]db.Tickets{backlog, doing, doingToo, stale}, nil)
+	mockDb.On("GetWorkspaceBountyCardsData", mock.Anything).Return([]db.NewBounty{
+		{ID: 1, Title: "Paid", WorkspaceUuid: "workspace-1", Paid: true},
+		{ID: 2, Title: "Open", WorkspaceUuid: "workspace-1"},
+	})
+	mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{Uuid: "workspace-1"})
+
+	rr := httptest.NewRecorder()
+	bh.GetWorkspaceBoard(rr, newBranchRequest(http.MethodGet, "/gobounties/board?workspace_uuid=workspace-1", "", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var board WorkspaceBoard
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&board))
+	require.Len(t, board.Columns, 3)
+	assert.Len(t, board.Columns[0].Tickets, 1)
+	assert.Len(t, board.Columns[1].Tickets, 2)
+	assert.True(t, board.Columns[1].OverLimit)
+	require.Len(t, board.Columns[2].Bounties, 1)
+	assert.Equal(t, "Paid", board.Columns[2].Bounties[0].Title)
+	require.Len(t, board.UnmappedTickets, 1)
+	assert.Equal(t, stale.UUID, board.UnmappedTickets[0].UUID)
+	require.Len(t, board.UnmappedBounties, 1)
+	assert.Equal(t, "Open", board.UnmappedBounties[0].Title)
+}
@@ -22,6 +22,7 @@ type tribeHandler struct {
 	db                      db.Database
 	verifyTribeUUID         func(uuid string, checkTimestamp bool) (string, error)
 	tribeUniqueNameFromName func(name string) (string, error)
+	cfg                     *config.Config
 }
 
 func NewTribeHandler(db db.Database) *tribeHandler {
@@ -29,6 +30,7 @@ func NewTribeHandler(db db.Database) *tribeHandler {
 		db:                      db,
 		verifyTribeUUID:         auth.VerifyTribeUUID,
 		tribeUniqueNameFromName: TribeUniqueNameFromName,
+		cfg:                     config.Current(),
 	}
 }
 
@@ -627,13 +629,13 @@ func UpdateLeaderBoard(w http.ResponseWriter, r *http.Request) {
 //	@Param			invoice	body		db.InvoiceRequest	true	"Invoice request"
 //	@Success		200		{object}	db.InvoiceResponse
 //	@Router			/invoice [post]
-func GenerateInvoice(w http.ResponseWriter, r *http.Request) {
+func (th *tribeHandler) GenerateInvoice(w http.ResponseWriter, r *http.Request) {
 	invoiceRes, invoiceErr := db.InvoiceResponse{}, db.InvoiceError{}
 
-	if config.IsV2Payment {
-		invoiceRes, invoiceErr = GenerateV2Invoice(w, r)
+	if th.cfg.IsV2Payment() {
+		invoiceRes, invoiceErr = th.GenerateV2Invoice(w, r)
 	} else {
-		invoiceRes, invoiceErr = GenerateV1Invoice(w, r)
+		invoiceRes, invoiceErr = th.GenerateV1Invoice(w, r)
 	}
 
 	if invoiceErr.Error != "" {
@@ -693,7 +695,7 @@ func GenerateInvoice(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(invoiceRes)
 }
 
-func GenerateV1Invoice(w http.ResponseWriter, r *http.Request) (db.InvoiceResponse, db.InvoiceError) {
+func (th *tribeHandler) GenerateV1Invoice(w http.ResponseWriter, r *http.Request) (db.InvoiceResponse, db.InvoiceError) {
 	invoice := db.InvoiceRequest{}
 	body, err := io.ReadAll(r.Body)
 
@@ -714,7 +716,7 @@ func GenerateV1Invoice(w http.ResponseWriter, r *http.Request) (db.InvoiceRespon
 	memo := invoice.Memo
 	amount, _ := utils.ConvertStringToUint(invoice.Amount)
 
-	url := fmt.Sprintf("%s/invoices", config.RelayUrl)
+	url := fmt.Sprintf("%s/invoices", th.cfg.Relay.URL)
 
 	bodyData := fmt.Sprintf(`{"amount": %d, "memo": "%s"}`, amount, memo)
 
@@ -723,7 +725,7 @@ func GenerateV1Invoice(w http.ResponseWriter, r *http.Request) (db.InvoiceRespon
 	client := &http.Client{}
 	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
 
-	req.Header.Set("x-user-token", config.RelayAuthKey)
+	req.Header.Set("x-user-token", th.cfg.Relay.AuthKey)
 	req.Header.Set("Content-Type", "application/json")
 	res, _ := client.Do(req)
 
@@ -762,7 +764,7 @@ func GenerateV1Invoice(w http.ResponseWriter, r *http.Request) (db.InvoiceRespon
 //	@Param			invoice	body		db.InvoiceRequest	true	"Invoice request"
 //	@Success		200		{object}	db.InvoiceResponse
 //	@Router			/tribes/invoice [post]
-func GenerateV2Invoice(w http.ResponseWriter, r *http.Request) (db.InvoiceResponse, db.InvoiceError) {
+func (th *tribeHandler) GenerateV2Invoice(w http.ResponseWriter, r *http.Request) (db.InvoiceResponse, db.InvoiceError) {
 	invoice := db.InvoiceRequest{}
 
 	var err error
@@ -782,7 +784,7 @@ func GenerateV2Invoice(w http.ResponseWriter, r *http.Request) (db.InvoiceRespon
 		return db.InvoiceResponse{}, db.InvoiceError{Success: false, Error: err.Error()}
 	}
 
-	url := fmt.Sprintf("%s/invoice", config.V2BotUrl)
+	url := fmt.Sprintf("%s/invoice", th.cfg.V2Bot.URL)
 
 	amount, _ := utils.ConvertStringToUint(invoice.Amount)
 
@@ -795,7 +797,7 @@ func GenerateV2Invoice(w http.ResponseWriter, r *http.Request) (db.InvoiceRespon
 	client := &http.Client{}
 	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
 
-	req.Header.Set("x-admin-token", config.V2BotToken)
+	req.Header.Set("x-admin-token", th.cfg.V2Bot.Token)
 	req.Header.Set("Content-Type", "application/json")
 	res, err := client.Do(req)
 
@@ -837,7 +839,7 @@ func GenerateV2Invoice(w http.ResponseWriter, r *http.Request) (db.InvoiceRespon
 //	@Success		200		{object}	db.InvoiceResponse
 //	@Router			/tribes/budget_invoice [post]
 func (th *tribeHandler) GenerateBudgetInvoice(w http.ResponseWriter, r *http.Request) {
-	if config.IsV2Payment {
+	if th.cfg.IsV2Payment() {
 		th.GenerateV2BudgetInvoice(w, r)
 	} else {
 		th.GenerateV1BudgetInvoice(w, r)
@@ -870,7 +872,7 @@ func (th *tribeHandler) GenerateV1BudgetInvoice(w http.ResponseWriter, r *http.R
 		invoice.WorkspaceUuid = invoice.OrgUuid
 	}
 
-	url := fmt.Sprintf("%s/invoices", config.RelayUrl)
+	url := fmt.Sprintf("%s/invoices", th.cfg.Relay.URL)
 
 	bodyData := fmt.Sprintf(`{"amount": %d, "memo": "%s"}`, invoice.Amount, "Budget Invoice")
 
@@ -879,7 +881,7 @@ func (th *tribeHandler) GenerateV1BudgetInvoice(w http.ResponseWriter, r *http.R
 	client := &http.Client{}
 	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
 
-	req.Header.Set("x-user-token", config.RelayAuthKey)
+	req.Header.Set("x-user-token", th.cfg.Relay.AuthKey)
 	req.Header.Set("Content-Type", "application/json")
 	res, _ := client.Do(req)
 
@@ -962,7 +964,7 @@ func (th *tribeHandler) GenerateV2BudgetInvoice(w http.ResponseWriter, r *http.R
 		invoice.WorkspaceUuid = invoice.OrgUuid
 	}
 
-	url := fmt.Sprintf("%s/invoice", config.V2BotUrl)
+	url := fmt.Sprintf("%s/invoice", th.cfg.V2Bot.URL)
 
 	amountMsat := invoice.Amount * 1000
 
@@ -973,7 +975,7 @@ func (th *tribeHandler) GenerateV2BudgetInvoice(w http.ResponseWriter, r *http.R
 	client := &http.Client{}
 	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
 
-	req.Header.Set("x-admin-token", config.V2BotToken)
+	req.Header.Set("x-admin-token", th.cfg.V2Bot.Token)
 	req.Header.Set("Content-Type", "application/json")
 	res, err := client.Do(req)
 
@@ -875,7 +875,7 @@ func TestGenerateBudgetInvoice(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		config.RelayUrl = ts.URL
+		tHandler.cfg = &config.Config{Relay: config.RelayConfig{URL: ts.URL}}
 
 		reqBody := map[string]interface{}{
 			"amount":         uint(0),
@@ -910,7 +910,7 @@ func TestGenerateBudgetInvoice(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		config.RelayUrl = ts.URL
+		tHandler.cfg = &config.Config{Relay: config.RelayConfig{URL: ts.URL}}
 
 		reqBody := map[string]interface{}{
 			"amount":         userAmount,
@@ -940,7 +940,10 @@ func TestGenerateBudgetInvoice(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		config.RelayUrl = ts.URL
+		tHandler.cfg = &config.Config{
+			Relay: config.RelayConfig{URL: ts.URL},
+			V2Bot: config.V2BotConfig{URL: botURL, Token: botToken},
+		}
 
 		reqBody := map[string]interface{}{
 			"amount":         userAmount,
@@ -976,9 +979,7 @@ func TestGenerateBudgetInvoice(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		config.RelayUrl = ts.URL
-		config.IsV2Payment = false
-		config.RelayAuthKey = "test_auth_key"
+		tHandler.cfg = &config.Config{Relay: config.RelayConfig{URL: ts.URL, AuthKey: "test_auth_key"}}
 		reqBody := db.BudgetInvoiceRequest{
 			Amount:        userAmount,
 			SenderPubKey:  person.OwnerPubKey,
@@ -1010,9 +1011,7 @@ func TestGenerateBudgetInvoice(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		config.V2BotUrl = ts.URL
-		config.V2BotToken = "test_bot_token"
-		config.IsV2Payment = true
+		tHandler.cfg = &config.Config{V2Bot: config.V2BotConfig{URL: ts.URL, Token: "test_bot_token"}}
 		reqBody := db.BudgetInvoiceRequest{
 			Amount:        userAmount,
 			SenderPubKey:  person.OwnerPubKey,
@@ -1055,15 +1054,16 @@ func TestGenerateInvoice(t *testing.T) {
 	teardownSuite := SetupSuite(t)
 	defer teardownSuite(t)
 
-	config.IsV2Payment = true
-	config.V2BotUrl = "http://v2-bot-url.com"
-	config.V2BotToken = "v2-bot-token"
+	testConfig := *config.Current()
+	testConfig.V2Bot = config.V2BotConfig{URL: "http://v2-bot-url.com", Token: "v2-bot-token"}
+	tHandler := NewTribeHandler(db.TestDB)
+	tHandler.cfg = &testConfig
 
 	t.Run("Create Invoice - Happy Path", func(t *testing.T) {
 
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-			assert.Equal(t, config.V2BotToken, r.Header.Get("x-admin-token"))
+			assert.Equal(t, testConfig.V2Bot.Token, r.Header.Get("x-admin-token"))
 			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
 
 			body, err := io.ReadAll(r.Body)
@@ -1082,10 +1082,10 @@ func TestGenerateInvoice(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		originalUrl := config.V2BotUrl
-		config.V2BotUrl = ts.URL
+		originalUrl := testConfig.V2Bot.URL
+		testConfig.V2Bot.URL = ts.URL
 		defer func() {
-			config.V2BotUrl = originalUrl
+			testConfig.V2Bot.URL = originalUrl
 		}()
 
 		requestBody := `{
@@ -1101,7 +1101,7 @@ func TestGenerateInvoice(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
-		GenerateInvoice(rr, req)
+		tHandler.GenerateInvoice(rr, req)
 
 		assert.Equal(t, http.StatusNotAcceptable, rr.Code)
 	})
@@ -1111,7 +1111,7 @@ func TestGenerateInvoice(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
-		GenerateInvoice(rr, req)
+		tHandler.GenerateInvoice(rr, req)
 
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 	})
@@ -1123,7 +1123,7 @@ func TestGenerateInvoice(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
-		GenerateInvoice(rr, req)
+		tHandler.GenerateInvoice(rr, req)
 
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 	})
@@ -1140,7 +1140,7 @@ func TestGenerateInvoice(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
-		GenerateInvoice(rr, req)
+		tHandler.GenerateInvoice(rr, req)
 
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 	})
@@ -1153,10 +1153,10 @@ func TestGenerateInvoice(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		originalUrl := config.V2BotUrl
-		config.V2BotUrl = ts.URL
+		originalUrl := testConfig.V2Bot.URL
+		testConfig.V2Bot.URL = ts.URL
 		defer func() {
-			config.V2BotUrl = originalUrl
+			testConfig.V2Bot.URL = originalUrl
 		}()
 
 		requestBody := `{
@@ -1172,7 +1172,7 @@ func TestGenerateInvoice(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
-		GenerateInvoice(rr, req)
+		tHandler.GenerateInvoice(rr, req)
 
 		assert.Equal(t, http.StatusNotAcceptable, rr.Code)
 	})
@@ -1185,10 +1185,10 @@ func TestGenerateInvoice(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		originalUrl := config.V2BotUrl
-		config.V2BotUrl = ts.URL
+		originalUrl := testConfig.V2Bot.URL
+		testConfig.V2Bot.URL = ts.URL
 		defer func() {
-			config.V2BotUrl = originalUrl
+			testConfig.V2Bot.URL = originalUrl
 		}()
 
 		var wg sync.WaitGroup
@@ -1212,7 +1212,7 @@ func TestGenerateInvoice(t *testing.T) {
 				req.Header.Set("Content-Type", "application/json")
 				rr := httptest.NewRecorder()
 
-				GenerateInvoice(rr, req)
+				tHandler.GenerateInvoice(rr, req)
 				responses[index] = rr
 			}(i)
 		}
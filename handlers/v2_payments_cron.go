@@ -13,7 +13,7 @@ import (
 	"github.com/stakwork/sphinx-tribes/utils"
 )
 
-func InitV2PaymentsCron() {
+func InitV2PaymentsCron(cfg *config.Config) {
 	log.Println("Pending Invoice Cron Job Started")
 	paymentHistories := db.DB.GetPendingPaymentHistory()
 	for _, payment := range paymentHistories {
@@ -23,7 +23,7 @@ func InitV2PaymentsCron() {
 
 		if bounty.ID > 0 {
 			tag := payment.Tag
-			tagResult := GetInvoiceStatusByTag(tag)
+			tagResult := GetInvoiceStatusByTag(cfg.V2Bot, tag)
 			log.Println("Tag Result =================", tagResult)
 
 			if tagResult.Status == db.PaymentComplete {
@@ -81,8 +81,8 @@ func InitV2PaymentsCron() {
 	}
 }
 
-func GetInvoiceStatusByTag(tag string) db.V2TagRes {
-	url := fmt.Sprintf("%s/sends/%s", config.V2BotUrl, tag)
+func GetInvoiceStatusByTag(bot config.V2BotConfig, tag string) db.V2TagRes {
+	url := fmt.Sprintf("%s/sends/%s", bot.URL, tag)
 
 	client := http.Client{}
 
@@ -92,7 +92,7 @@ func GetInvoiceStatusByTag(tag string) db.V2TagRes {
 		log.Printf("Error paying invoice: %s", err)
 	}
 
-	req.Header.Set("x-admin-token", config.V2BotToken)
+	req.Header.Set("x-admin-token", bot.Token)
 	req.Header.Set("Content-Type", "application/json")
 	res, err := client.Do(req)
 
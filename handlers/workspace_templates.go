@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type WorkspaceTemplateRequest struct {
+	Kind               db.TemplateKind           `json:"kind"`
+	Name               string                    `json:"name"`
+	Title              string                    `json:"title"`
+	Description        string                    `json:"description"`
+	Category           *db.Category              `json:"category,omitempty"`
+	Amount             *int64                    `json:"amount,omitempty"`
+	BountyType         string                    `json:"bounty_type,omitempty"`
+	CodingLanguages    []string                  `json:"coding_languages"`
+	AcceptanceCriteria []string                  `json:"acceptance_criteria"`
+	AccessRestriction  *db.AccessRestrictionType `json:"access_restriction,omitempty"`
+	IsDefault          bool                      `json:"is_default"`
+}
+
+// resolveWorkspaceTemplate returns the template named by templateID, which
+// must be of kind and belong to the workspace, or the default template of
+// kind of the workspace when templateID is empty. Named templates are
+// rejected when there is no workspace to check them against.
+func resolveWorkspaceTemplate(database db.Database, workspaceUuid string, kind db.TemplateKind, templateID string) (db.WorkspaceTemplate, *ticketCheckError) {
+	if templateID == "" {
+		if workspaceUuid == "" {
+			return db.BuiltinWorkspaceTemplate("", kind), nil
+		}
+		template, err := database.GetDefaultWorkspaceTemplate(workspaceUuid, kind)
+		if err != nil {
+			return db.WorkspaceTemplate{}, &ticketCheckError{status: http.StatusInternalServerError, message: err.Error()}
+		}
+		return template, nil
+	}
+	if workspaceUuid == "" {
+		return db.WorkspaceTemplate{}, &ticketCheckError{status: http.StatusBadRequest, message: "templates can only be used inside a workspace"}
+	}
+
+	id, err := uuid.Parse(templateID)
+	if err != nil {
+		return db.WorkspaceTemplate{}, &ticketCheckError{status: http.StatusBadRequest, message: "invalid template ID"}
+	}
+	template, err := database.GetWorkspaceTemplate(id)
+	if errors.Is(err, db.ErrTemplateNotFound) {
+		return db.WorkspaceTemplate{}, &ticketCheckError{status: http.StatusNotFound, message: err.Error()}
+	}
+	if err != nil {
+		return db.WorkspaceTemplate{}, &ticketCheckError{status: http.StatusInternalServerError, message: err.Error()}
+	}
+	if template.Kind != kind {
+		return db.WorkspaceTemplate{}, &ticketCheckError{status: http.StatusBadRequest, message: "template is not a " + string(kind) + " template"}
+	}
+	if template.WorkspaceUuid != workspaceUuid {
+		return db.WorkspaceTemplate{}, &ticketCheckError{status: http.StatusBadRequest, message: "template belongs to another workspace"}
+	}
+	return template, nil
+}
+
+// GetWorkspaceTemplates godoc
+//
+//	@Summary		List the templates of a workspace
+//	@Description	List the ticket and bounty templates of a workspace, of one kind when kind is set
+//	@Tags			Workspaces
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path	string	true	"Workspace UUID"
+//	@Param			kind			query	string	false	"ticket or bounty"
+//	@Success		200				{array}	db.WorkspaceTemplate
+//	@Router			/workspaces/{workspace_uuid}/templates [get]
+func (oh *workspaceHandler) GetWorkspaceTemplates(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	kind := db.TemplateKind(r.URL.Query().Get("kind"))
+	if kind != "" && kind != db.TicketTemplateKind && kind != db.BountyTemplateKind {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "kind must be ticket or bounty"})
+		return
+	}
+
+	templates, err := oh.db.GetWorkspaceTemplates(workspaceUuid, kind)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(templates)
+}
+
+// CreateWorkspaceTemplate godoc
+//
+//	@Summary		Create a workspace template
+//	@Description	Create a ticket or bounty template. Title and description may use {{name}}, {{description}} and {{feature}} placeholders.
+//	@Tags			Workspaces
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string						true	"Workspace UUID"
+//	@Param			template		body		WorkspaceTemplateRequest	true	"Template"
+//	@Success		201				{object}	db.WorkspaceTemplate
+//	@Router			/workspaces/{workspace_uuid}/templates [post]
+func (oh *workspaceHandler) CreateWorkspaceTemplate(w http.ResponseWriter, r *http.Request) {
+	oh.saveWorkspaceTemplate(w, r, uuid.Nil)
+}
+
+// UpdateWorkspaceTemplate godoc
+//
+//	@Summary		Update a workspace template
+//	@Description	Replace a ticket or bounty template
+//	@Tags			Workspaces
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string						true	"Workspace UUID"
+//	@Param			id				path		string						true	"Template ID"
+//	@Param			template		body		WorkspaceTemplateRequest	true	"Template"
+//	@Success		200				{object}	db.WorkspaceTemplate
+//	@Router			/workspaces/{workspace_uuid}/templates/{id} [put]
+func (oh *workspaceHandler) UpdateWorkspaceTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid template ID"})
+		return
+	}
+	oh.saveWorkspaceTemplate(w, r, id)
+}
+
+func (oh *workspaceHandler) saveWorkspaceTemplate(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	workspace := oh.db.GetWorkspaceByUuid(workspaceUuid)
+	if workspace.Uuid == "" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "workspace not found"})
+		return
+	}
+	if workspace.OwnerPubKey != pubKeyFromAuth && oh.db.GetWorkspaceUser(pubKeyFromAuth, workspaceUuid).OwnerPubKey != pubKeyFromAuth {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only workspace members can change its templates"})
+		return
+	}
+
+	var request WorkspaceTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error parsing request body"})
+		return
+	}
+
+	template := db.WorkspaceTemplate{
+		ID:                 id,
+		WorkspaceUuid:      workspaceUuid,
+		Kind:               request.Kind,
+		Name:               request.Name,
+		Title:              request.Title,
+		Description:        request.Description,
+		Category:           request.Category,
+		Amount:             request.Amount,
+		BountyType:         request.BountyType,
+		CodingLanguages:    pq.StringArray(request.CodingLanguages),
+		AcceptanceCriteria: pq.StringArray(request.AcceptanceCriteria),
+		AccessRestriction:  request.AccessRestriction,
+		IsDefault:          request.IsDefault,
+		CreatedBy:          pubKeyFromAuth,
+	}
+	if err := db.ValidateWorkspaceTemplate(template); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	saved, err := oh.db.CreateOrEditWorkspaceTemplate(&template)
+	if errors.Is(err, db.ErrTemplateNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if id == uuid.Nil {
+		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(saved)
+}
+
+// DeleteWorkspaceTemplate godoc
+//
+//	@Summary		Delete a workspace template
+//	@Tags			Workspaces
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path	string	true	"Workspace UUID"
+//	@Param			id				path	string	true	"Template ID"
+//	@Success		204
+//	@Router			/workspaces/{workspace_uuid}/templates/{id} [delete]
+func (oh *workspaceHandler) DeleteWorkspaceTemplate(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid template ID"})
+		return
+	}
+
+	workspace := oh.db.GetWorkspaceByUuid(workspaceUuid)
+	if workspace.Uuid == "" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "workspace not found"})
+		return
+	}
+	if workspace.OwnerPubKey != pubKeyFromAuth && oh.db.GetWorkspaceUser(pubKeyFromAuth, workspaceUuid).OwnerPubKey != pubKeyFromAuth {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "only workspace members can change its templates"})
+		return
+	}
+
+	template, err := oh.db.GetWorkspaceTemplate(id)
+	if err != nil || template.WorkspaceUuid != workspaceUuid {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": db.ErrTemplateNotFound.Error()})
+		return
+	}
+
+	if err := oh.db.DeleteWorkspaceTemplate(id); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
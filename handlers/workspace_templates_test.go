@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveWorkspaceTemplate(t *testing.T) {
+	params := map[string]string{"workspace_uuid": "workspace-1"}
+	workspace := db.Workspace{ID: 1, Uuid: "workspace-1", OwnerPubKey: "test-pubkey"}
+
+	t.Run("Create", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		oh := NewWorkspaceHandler(mockDb)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(workspace)
+		mockDb.On("CreateOrEditWorkspaceTemplate", mock.MatchedBy(func(template *db.WorkspaceTemplate) bool {
+			return template.WorkspaceUuid == "workspace-1" && template.Kind == db.BountyTemplateKind &&
+				template.IsDefault && len(template.AcceptanceCriteria) == 1
+		})).Return(func(template *db.WorkspaceTemplate) db.WorkspaceTemplate { return *template }, nil)
+
+		rr := httptest.NewRecorder()
+		oh.CreateWorkspaceTemplate(rr, newBranchRequest(http.MethodPost, "/templates",
+			`{"kind":"bounty","name":"Bug","description":"Steps: {{description}}","amount":5000,"acceptance_criteria":["Has a test"],"is_default":true}`, params))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Invalid Kind", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		oh := NewWorkspaceHandler(mockDb)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(workspace)
+
+		rr := httptest.NewRecorder()
+		oh.CreateWorkspaceTemplate(rr, newBranchRequest(http.MethodPost, "/templates", `{"kind":"epic","name":"Bug"}`, params))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Only Members", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		oh := NewWorkspaceHandler(mockDb)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{ID: 1, Uuid: "workspace-1", OwnerPubKey: "owner"})
+		mockDb.On("GetWorkspaceUser", "test-pubkey", "workspace-1").Return(db.WorkspaceUsers{})
+
+		rr := httptest.NewRecorder()
+		oh.CreateWorkspaceTemplate(rr, newBranchRequest(http.MethodPost, "/templates", `{"kind":"ticket","name":"Bug"}`, params))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestTicketTemplatesAtCreation(t *testing.T) {
+	amount := int64(800)
+	ticketTemplate := db.WorkspaceTemplate{
+		ID:            uuid.New(),
+		WorkspaceUuid: "workspace-1",
+		Kind:          db.TicketTemplateKind,
+		Description:   "## Problem\n{{description}}",
+		Amount:        &amount,
+	}
+
+	t.Run("Draft Ticket From Template", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{ID: 1, Uuid: "workspace-1"})
+		mockDb.On("GetWorkspaceTemplate", ticketTemplate.ID).Return(ticketTemplate, nil)
+		mockDb.On("CreateWorkspaceDraftTicket", mock.MatchedBy(func(ticket *db.Tickets) bool {
+			return ticket.Description == "## Problem\nLogin fails" && *ticket.Amount == 800
+		})).Return(func(ticket *db.Tickets) db.Tickets { return *ticket }, nil)
+
+		body := `{"name":"Login bug","description":"Login fails","template_id":"` + ticketTemplate.ID.String() + `"}`
+		rr := httptest.NewRecorder()
+		th.CreateWorkspaceDraftTicket(rr, newBranchRequest(http.MethodPost, "/draft", body, map[string]string{"workspace_uuid": "workspace-1"}))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Template Of Another Kind", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		bountyTemplate := ticketTemplate
+		bountyTemplate.Kind = db.BountyTemplateKind
+		mockDb.On("GetWorkspaceByUuid", "workspace-1").Return(db.Workspace{ID: 1, Uuid: "workspace-1"})
+		mockDb.On("GetWorkspaceTemplate", ticketTemplate.ID).Return(bountyTemplate, nil)
+
+		body := `{"name":"Login bug","template_id":"` + ticketTemplate.ID.String() + `"}`
+		rr := httptest.NewRecorder()
+		th.CreateWorkspaceDraftTicket(rr, newBranchRequest(http.MethodPost, "/draft", body, map[string]string{"workspace_uuid": "workspace-1"}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Ticket To Bounty With Template", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+		th := NewTicketHandler(&http.Client{}, mockDb)
+		ticket := newGraphTicket(db.ReadyTicket)
+		bountyTemplate := db.WorkspaceTemplate{ID: uuid.New(), WorkspaceUuid: "workspace-1", Kind: db.BountyTemplateKind, BountyType: "coding_task"}
+		mockDb.On("GetTicket", ticket.UUID.String()).Return(ticket, nil)
+		mockDb.On("GetFeatureByUuid", "feature-1").Return(db.WorkspaceFeatures{Uuid: "feature-1", WorkspaceUuid: "workspace-1"})
+		mockDb.On("GetWorkspaceTemplate", bountyTemplate.ID).Return(bountyTemplate, nil)
+		mockDb.On("CreateBountyFromTicket", ticket, "test-pubkey", bountyTemplate).Return(&db.NewBounty{ID: 7}, nil)
+		mockDb.On("DeleteTicketGroup", *ticket.TicketGroup).Return(nil)
+
+		rr := httptest.NewRecorder()
+		th.TicketToBounty(rr, newBranchRequest(http.MethodPost, "/bounty?template_id="+bountyTemplate.ID.String(), "", map[string]string{"ticket_uuid": ticket.UUID.String()}))
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var response CreateBountyResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, uint(7), response.BountyID)
+	})
+
+	t.Run("Named Template Without Workspace", func(t *testing.T) {
+		mockDb := datamocks.NewDatabase(t)
+
+		_, checkErr := resolveWorkspaceTemplate(mockDb, "", db.BountyTemplateKind, ticketTemplate.ID.String())
+
+		require.NotNil(t, checkErr)
+		assert.Equal(t, http.StatusBadRequest, checkErr.status)
+	})
+}
@@ -28,6 +28,7 @@ type workspaceHandler struct {
 	configUserHasManageBountyRoles func(pubKeyFromAuth string, uuid string) bool
 	userHasManageBountyRoles       func(pubKeyFromAuth string, uuid string) bool
 	getAllUserWorkspaces           func(pubKeyFromAuth string) []db.Workspace
+	getInvoiceStatusByTag          func(tag string) db.V2TagRes
 }
 
 func NewWorkspaceHandler(database db.Database) *workspaceHandler {
@@ -43,6 +44,7 @@ func NewWorkspaceHandler(database db.Database) *workspaceHandler {
 		configUserHasManageBountyRoles: configHandler.UserHasManageBountyRoles,
 		userHasManageBountyRoles:       dbConf.UserHasManageBountyRoles,
 		getAllUserWorkspaces:           GetAllUserWorkspaces,
+		getInvoiceStatusByTag:          bHandler.getInvoiceStatusByTag,
 	}
 }
 
@@ -896,7 +898,7 @@ func GetPaymentHistory(w http.ResponseWriter, r *http.Request) {
 //	@Param			workspace_uuid	path		string	true	"Workspace UUID"
 //	@Success		200				{string}	string	"Updated Payments Successfully"
 //	@Router			/workspaces/{workspace_uuid}/payments [put]
-func UpdateWorkspacePendingPayments(w http.ResponseWriter, r *http.Request) {
+func (oh *workspaceHandler) UpdateWorkspacePendingPayments(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
 	workspace_uuid := chi.URLParam(r, "workspace_uuid")
@@ -911,7 +913,7 @@ func UpdateWorkspacePendingPayments(w http.ResponseWriter, r *http.Request) {
 
 	for _, payment := range paymentsHistory {
 		tag := payment.Tag
-		tagResult := GetInvoiceStatusByTag(tag)
+		tagResult := oh.getInvoiceStatusByTag(tag)
 
 		if tagResult.Status == db.PaymentComplete {
 			db.DB.SetPaymentAsComplete(tag)
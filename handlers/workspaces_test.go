@@ -2305,9 +2305,11 @@ func TestPollBudgetInvoices(t *testing.T) {
 	teardownSuite := SetupSuite(t)
 	defer teardownSuite(t)
 
-	config.IsV2Payment = true
-	config.V2BotUrl = "http://v2-bot-url.com"
-	config.V2BotToken = "v2-bot-token"
+	originalConfig := config.Current()
+	testConfig := *originalConfig
+	testConfig.V2Bot = config.V2BotConfig{URL: "http://v2-bot-url.com", Token: "v2-bot-token"}
+	config.SetCurrent(&testConfig)
+	defer config.SetCurrent(originalConfig)
 
 	// create a user
 	person := db.Person{
@@ -2491,9 +2493,11 @@ func TestPollUserWorkspacesBudget(t *testing.T) {
 	teardownSuite := SetupSuite(t)
 	defer teardownSuite(t)
 
-	config.IsV2Payment = true
-	config.V2BotUrl = "http://v2-bot-url.com"
-	config.V2BotToken = "v2-bot-token"
+	originalConfig := config.Current()
+	testConfig := *originalConfig
+	testConfig.V2Bot = config.V2BotConfig{URL: "http://v2-bot-url.com", Token: "v2-bot-token"}
+	config.SetCurrent(&testConfig)
+	defer config.SetCurrent(originalConfig)
 
 	// create a user
 	person := db.Person{
@@ -0,0 +1,114 @@
+package knowledge
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/stakwork/sphinx-tribes/db"
+)
+
+// BM25 ranks items by the Okapi BM25 score of their words. It needs no
+// external service and is the fallback of the other rankers.
+type BM25 struct {
+	K1 float64
+	B  float64
+}
+
+func NewBM25() *BM25 {
+	return &BM25{K1: 1.2, B: 0.75}
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "can": true, "do": true, "for": true,
+	"from": true, "has": true, "have": true, "how": true, "i": true, "in": true,
+	"is": true, "it": true, "its": true, "of": true, "on": true, "or": true,
+	"so": true, "that": true, "the": true, "this": true, "to": true, "we": true,
+	"what": true, "when": true, "which": true, "will": true, "with": true, "you": true,
+}
+
+// Tokenize lower cases text and splits it into words, dropping stop words.
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := fields[:0]
+	for _, field := range fields {
+		if stopWords[field] {
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+// itemTokens counts the title twice so items named after the query rank
+// above items that only mention it.
+func itemTokens(item db.KnowledgeItem) []string {
+	title := Tokenize(item.Title)
+	return append(append(title, title...), Tokenize(item.Content)...)
+}
+
+func (r *BM25) Rank(ctx context.Context, query string, items []db.KnowledgeItem, limit int) ([]Scored, error) {
+	terms := map[string]bool{}
+	for _, token := range Tokenize(query) {
+		terms[token] = true
+	}
+	if len(terms) == 0 || len(items) == 0 {
+		return []Scored{}, nil
+	}
+
+	frequencies := make([]map[string]int, len(items))
+	lengths := make([]int, len(items))
+	documents := map[string]int{}
+	total := 0
+	for i, item := range items {
+		tokens := itemTokens(item)
+		frequency := map[string]int{}
+		for _, token := range tokens {
+			if terms[token] {
+				frequency[token]++
+			}
+		}
+		for term := range frequency {
+			documents[term]++
+		}
+		frequencies[i] = frequency
+		lengths[i] = len(tokens)
+		total += len(tokens)
+	}
+
+	n := float64(len(items))
+	average := float64(total) / n
+	if average == 0 {
+		average = 1
+	}
+
+	scored := []Scored{}
+	for i, item := range items {
+		score := 0.0
+		for term, count := range frequencies[i] {
+			idf := math.Log(1 + (n-float64(documents[term])+0.5)/(float64(documents[term])+0.5))
+			tf := float64(count)
+			score += idf * tf * (r.K1 + 1) / (tf + r.K1*(1-r.B+r.B*float64(lengths[i])/average))
+		}
+		if score > 0 {
+			scored = append(scored, Scored{Item: item, Score: score})
+		}
+	}
+	return best(scored, limit), nil
+}
+
+// best sorts scored items, best first, and keeps the first limit of them.
+func best(scored []Scored, limit int) []Scored {
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	if limit > 0 && len(scored) > limit {
+		scored = scored[:limit]
+	}
+	return scored
+}
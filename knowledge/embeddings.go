@@ -0,0 +1,175 @@
+package knowledge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+const (
+	embeddingBatchSize = 64
+	maxEmbeddingChars  = 8000
+)
+
+// Embeddings ranks items by the cosine similarity of their embeddings to the
+// embedding of the query, using an OpenAI compatible embeddings API. Item
+// embeddings are computed once and stored until the item changes. When the
+// API fails the Fallback ranker is used instead.
+type Embeddings struct {
+	URL      string
+	Model    string
+	APIKey   string
+	Client   *http.Client
+	DB       db.Database
+	Fallback Ranker
+}
+
+type embeddingsRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingsResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding db.Vector `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *Embeddings) Rank(ctx context.Context, query string, items []db.KnowledgeItem, limit int) ([]Scored, error) {
+	scored, err := e.rank(ctx, query, items, limit)
+	if err != nil && e.Fallback != nil {
+		logger.Log.Error("knowledge embeddings failed, ranking with the fallback: %v", err)
+		return e.Fallback.Rank(ctx, query, items, limit)
+	}
+	return scored, err
+}
+
+func (e *Embeddings) rank(ctx context.Context, query string, items []db.KnowledgeItem, limit int) ([]Scored, error) {
+	if len(items) == 0 {
+		return []Scored{}, nil
+	}
+
+	var missing []int
+	for i, item := range items {
+		if len(item.Embedding) == 0 || item.EmbeddingModel != e.Model {
+			missing = append(missing, i)
+		}
+	}
+	for start := 0; start < len(missing); start += embeddingBatchSize {
+		end := start + embeddingBatchSize
+		if end > len(missing) {
+			end = len(missing)
+		}
+		batch := missing[start:end]
+
+		inputs := make([]string, len(batch))
+		for i, index := range batch {
+			inputs[i] = embeddingInput(items[index])
+		}
+		vectors, err := e.embed(ctx, inputs)
+		if err != nil {
+			return nil, err
+		}
+		for i, index := range batch {
+			items[index].Embedding = vectors[i]
+			items[index].EmbeddingModel = e.Model
+			if e.DB != nil {
+				if err := e.DB.UpdateKnowledgeItemEmbedding(items[index].ID, vectors[i], e.Model); err != nil {
+					logger.Log.Error("failed to store embedding of knowledge item %d: %v", items[index].ID, err)
+				}
+			}
+		}
+	}
+
+	queryVectors, err := e.embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	scored := []Scored{}
+	for _, item := range items {
+		if score := cosine(queryVectors[0], item.Embedding); score > 0 {
+			scored = append(scored, Scored{Item: item, Score: score})
+		}
+	}
+	return best(scored, limit), nil
+}
+
+func embeddingInput(item db.KnowledgeItem) string {
+	text := []rune(item.Title + "\n\n" + item.Content)
+	if len(text) > maxEmbeddingChars {
+		text = text[:maxEmbeddingChars]
+	}
+	return string(text)
+}
+
+func (e *Embeddings) embed(ctx context.Context, inputs []string) ([]db.Vector, error) {
+	body, err := json.Marshal(embeddingsRequest{Model: e.Model, Input: inputs})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	}
+
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("embeddings request failed: status %d: %s", resp.StatusCode, message)
+	}
+
+	var response embeddingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid embeddings response: %w", err)
+	}
+	vectors := make([]db.Vector, len(inputs))
+	for _, data := range response.Data {
+		if data.Index < 0 || data.Index >= len(inputs) {
+			return nil, fmt.Errorf("invalid embeddings response: index %d out of range", data.Index)
+		}
+		vectors[data.Index] = data.Embedding
+	}
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, fmt.Errorf("invalid embeddings response: no embedding for input %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+func cosine(a, b db.Vector) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
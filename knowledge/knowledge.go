@@ -0,0 +1,231 @@
+// Package knowledge keeps a searchable knowledge base for every workspace,
+// built from its mission and tactics, feature briefs, requirements and
+// architecture, phase designs, stories and text snippets. The most relevant
+// items are attached to chat and ticket review payloads.
+package knowledge
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// Ranker orders knowledge items by relevance to a query.
+type Ranker interface {
+	// Rank returns at most limit of items that match query, best first. A
+	// limit of zero returns every match.
+	Rank(ctx context.Context, query string, items []db.KnowledgeItem, limit int) ([]Scored, error)
+}
+
+type Scored struct {
+	Item  db.KnowledgeItem
+	Score float64
+}
+
+// Match is a knowledge item as returned by search and sent to Stakwork.
+type Match struct {
+	ID         uint                   `json:"id"`
+	SourceType db.KnowledgeSourceType `json:"sourceType"`
+	SourceID   string                 `json:"sourceId"`
+	Title      string                 `json:"title"`
+	Content    string                 `json:"content"`
+	Score      float64                `json:"score"`
+	Pinned     bool                   `json:"pinned,omitempty"`
+	Truncated  bool                   `json:"truncated,omitempty"`
+}
+
+// Base indexes and searches the knowledge of workspaces. A workspace is
+// indexed again on use once RefreshAfter has passed since its last index.
+type Base struct {
+	DB           db.Database
+	Ranker       Ranker
+	MaxItems     int
+	MaxChars     int
+	RefreshAfter time.Duration
+	Now          func() time.Time
+
+	mu        sync.Mutex
+	refreshed map[string]time.Time
+}
+
+// Default is the knowledge base used by the handlers, set up by Init.
+var Default *Base
+
+// Init creates the knowledge base selected by the configuration and makes it
+// the default.
+func Init(database db.Database, cfg *config.Config) {
+	Default = New(database, cfg.Knowledge, &http.Client{Timeout: 30 * time.Second})
+}
+
+func New(database db.Database, cfg config.KnowledgeConfig, client *http.Client) *Base {
+	var ranker Ranker = NewBM25()
+	if cfg.Provider == "embeddings" {
+		ranker = &Embeddings{
+			URL:      cfg.EmbeddingsURL,
+			Model:    cfg.EmbeddingsModel,
+			APIKey:   cfg.EmbeddingsKey,
+			Client:   client,
+			DB:       database,
+			Fallback: ranker,
+		}
+	}
+	return &Base{
+		DB:           database,
+		Ranker:       ranker,
+		MaxItems:     cfg.MaxItems,
+		MaxChars:     cfg.MaxChars,
+		RefreshAfter: time.Duration(cfg.RefreshSeconds) * time.Second,
+	}
+}
+
+func (b *Base) now() time.Time {
+	if b.Now != nil {
+		return b.Now()
+	}
+	return time.Now()
+}
+
+// Index rebuilds the knowledge of a workspace from its current briefs,
+// features and snippets, and returns the number of items.
+func (b *Base) Index(workspaceUUID string) (int, error) {
+	b.mu.Lock()
+	if b.refreshed == nil {
+		b.refreshed = map[string]time.Time{}
+	}
+	b.refreshed[workspaceUUID] = b.now()
+	b.mu.Unlock()
+
+	count, err := b.index(workspaceUUID)
+	if err != nil {
+		b.mu.Lock()
+		delete(b.refreshed, workspaceUUID)
+		b.mu.Unlock()
+	}
+	return count, err
+}
+
+func (b *Base) index(workspaceUUID string) (int, error) {
+	items, err := b.DB.GetKnowledgeSources(workspaceUUID)
+	if err != nil {
+		return 0, err
+	}
+	if err := b.DB.SyncKnowledgeItems(workspaceUUID, items); err != nil {
+		return 0, err
+	}
+	return len(items), nil
+}
+
+// refresh indexes a workspace that has not been indexed recently. Failures
+// are logged and the items from the last index are used.
+func (b *Base) refresh(workspaceUUID string) {
+	b.mu.Lock()
+	last, ok := b.refreshed[workspaceUUID]
+	fresh := ok && b.now().Sub(last) < b.RefreshAfter
+	b.mu.Unlock()
+	if fresh {
+		return
+	}
+
+	if _, err := b.Index(workspaceUUID); err != nil {
+		logger.Log.Error("failed to index knowledge of workspace %s: %v", workspaceUUID, err)
+	}
+}
+
+// Search ranks the knowledge of a workspace against query.
+func (b *Base) Search(ctx context.Context, workspaceUUID, query string, limit int) ([]Match, error) {
+	b.refresh(workspaceUUID)
+
+	items, err := b.DB.GetKnowledgeItems(workspaceUUID)
+	if err != nil {
+		return nil, err
+	}
+	scored, err := b.Ranker.Rank(ctx, query, items, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]Match, len(scored))
+	for i, s := range scored {
+		matches[i] = newMatch(s.Item, s.Score)
+	}
+	return matches, nil
+}
+
+// Context picks the knowledge to attach to a payload: the pinned items of
+// the workspace first, then the items most relevant to query, up to MaxItems
+// in total and MaxChars of content. It never fails; problems are logged and
+// fewer items are returned.
+func (b *Base) Context(ctx context.Context, workspaceUUID, query string, pinned []uint) []Match {
+	matches := []Match{}
+	if workspaceUUID == "" {
+		return matches
+	}
+
+	seen := map[uint]bool{}
+	for _, id := range pinned {
+		if seen[id] {
+			continue
+		}
+		item, err := b.DB.GetKnowledgeItemByID(id)
+		if err != nil || item.WorkspaceUUID != workspaceUUID {
+			continue
+		}
+		seen[id] = true
+		match := newMatch(*item, 0)
+		match.Pinned = true
+		matches = append(matches, match)
+	}
+
+	if len(matches) < b.MaxItems {
+		found, err := b.Search(ctx, workspaceUUID, query, b.MaxItems+len(seen))
+		if err != nil {
+			logger.Log.Error("failed to search knowledge of workspace %s: %v", workspaceUUID, err)
+		}
+		for _, match := range found {
+			if len(matches) >= b.MaxItems {
+				break
+			}
+			if !seen[match.ID] {
+				seen[match.ID] = true
+				matches = append(matches, match)
+			}
+		}
+	}
+
+	return truncate(matches, b.MaxChars)
+}
+
+// truncate cuts the content of matches so that together they stay within
+// maxChars, dropping the matches that no longer fit.
+func truncate(matches []Match, maxChars int) []Match {
+	remaining := maxChars
+	for i := range matches {
+		if remaining <= 0 {
+			return matches[:i]
+		}
+		content := []rune(matches[i].Content)
+		if len(content) > remaining {
+			matches[i].Content = string(content[:remaining])
+			matches[i].Truncated = true
+			content = content[:remaining]
+		}
+		remaining -= len(content)
+	}
+	return matches
+}
+
+func newMatch(item db.KnowledgeItem, score float64) Match {
+	return Match{
+		ID:         item.ID,
+		SourceType: item.SourceType,
+		SourceID:   item.SourceID,
+		Title:      item.Title,
+		Content:    item.Content,
+		Score:      score,
+	}
+}
@@ -0,0 +1,224 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testItems = []db.KnowledgeItem{
+	{ID: 1, WorkspaceUUID: "ws-1", SourceType: db.KnowledgeMission, SourceID: "ws-1", Title: "Hive mission", Content: "Help teams ship software with bounties."},
+	{ID: 2, WorkspaceUUID: "ws-1", SourceType: db.KnowledgeFeatureBrief, SourceID: "f-1", Title: "Payments brief", Content: "Pay bounty hunters over lightning when a pull request is merged."},
+	{ID: 3, WorkspaceUUID: "ws-1", SourceType: db.KnowledgeSnippet, SourceID: "7", Title: "Style guide", Content: "Use short sentences in ticket descriptions."},
+	{ID: 4, WorkspaceUUID: "ws-1", SourceType: db.KnowledgeArchitecture, SourceID: "f-1", Title: "Payments architecture", Content: "The lightning node is reached through the v2 bot."},
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"pay", "hunters", "over", "lightning", "v2"}, Tokenize("Pay the hunters over Lightning (v2)!"))
+	assert.Empty(t, Tokenize("the and of"))
+}
+
+func TestBM25(t *testing.T) {
+	ranker := NewBM25()
+	ctx := context.Background()
+
+	t.Run("Ranks Matching Items", func(t *testing.T) {
+		scored, err := ranker.Rank(ctx, "how are lightning payments made?", testItems, 0)
+
+		require.NoError(t, err)
+		require.Len(t, scored, 2)
+		assert.Equal(t, uint(4), scored[0].Item.ID)
+		assert.Equal(t, uint(2), scored[1].Item.ID)
+		assert.Greater(t, scored[0].Score, scored[1].Score)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		scored, err := ranker.Rank(ctx, "lightning", testItems, 1)
+
+		require.NoError(t, err)
+		assert.Len(t, scored, 1)
+	})
+
+	t.Run("No Terms", func(t *testing.T) {
+		scored, err := ranker.Rank(ctx, "the", testItems, 0)
+
+		require.NoError(t, err)
+		assert.Empty(t, scored)
+	})
+}
+
+func TestEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	vectors := map[string]db.Vector{
+		"ship":    {1, 0},
+		"payment": {0, 1},
+	}
+	newServer := func(t *testing.T, status int) (*httptest.Server, *int) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+			var request embeddingsRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Equal(t, "test-model", request.Model)
+
+			response := embeddingsResponse{}
+			for i, input := range request.Input {
+				vector := db.Vector{0.1, 0.1}
+				for word, v := range vectors {
+					if strings.Contains(strings.ToLower(input), word) {
+						vector = v
+					}
+				}
+				response.Data = append(response.Data, struct {
+					Index     int       `json:"index"`
+					Embedding db.Vector `json:"embedding"`
+				}{Index: i, Embedding: vector})
+			}
+			json.NewEncoder(w).Encode(response)
+		}))
+		t.Cleanup(server.Close)
+		return server, &calls
+	}
+
+	items := func() []db.KnowledgeItem {
+		items := make([]db.KnowledgeItem, 2)
+		copy(items, testItems[:2])
+		return items
+	}
+
+	t.Run("Embeds Missing Items And Stores Them", func(t *testing.T) {
+		server, calls := newServer(t, http.StatusOK)
+		mockDb := mocks.NewDatabase(t)
+		mockDb.On("UpdateKnowledgeItemEmbedding", uint(1), db.Vector{1, 0}, "test-model").Return(nil).Once()
+		mockDb.On("UpdateKnowledgeItemEmbedding", uint(2), db.Vector{0, 1}, "test-model").Return(nil).Once()
+		ranker := &Embeddings{URL: server.URL, Model: "test-model", APIKey: "key", DB: mockDb, Fallback: NewBM25()}
+
+		scored, err := ranker.Rank(ctx, "payment flow", items(), 0)
+
+		require.NoError(t, err)
+		require.Len(t, scored, 1)
+		assert.Equal(t, uint(2), scored[0].Item.ID)
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("Reuses Stored Embeddings", func(t *testing.T) {
+		server, calls := newServer(t, http.StatusOK)
+		ranker := &Embeddings{URL: server.URL, Model: "test-model", APIKey: "key", Fallback: NewBM25()}
+		stored := items()
+		stored[0].Embedding, stored[0].EmbeddingModel = db.Vector{1, 0}, "test-model"
+		stored[1].Embedding, stored[1].EmbeddingModel = db.Vector{0, 1}, "test-model"
+
+		scored, err := ranker.Rank(ctx, "how do we ship", stored, 0)
+
+		require.NoError(t, err)
+		require.Len(t, scored, 1)
+		assert.Equal(t, uint(1), scored[0].Item.ID)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("Falls Back To BM25", func(t *testing.T) {
+		server, _ := newServer(t, http.StatusInternalServerError)
+		ranker := &Embeddings{URL: server.URL, Model: "test-model", APIKey: "key", Fallback: NewBM25()}
+
+		scored, err := ranker.Rank(ctx, "lightning", items(), 0)
+
+		require.NoError(t, err)
+		require.Len(t, scored, 1)
+		assert.Equal(t, uint(2), scored[0].Item.ID)
+	})
+}
+
+func TestBase(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	newBase := func(t *testing.T) (*Base, *mocks.Database) {
+		mockDb := mocks.NewDatabase(t)
+		base := New(mockDb, config.Defaults().Knowledge, nil)
+		base.Now = func() time.Time { return now }
+		return base, mockDb
+	}
+
+	t.Run("Indexes Once Per Refresh Interval", func(t *testing.T) {
+		base, mockDb := newBase(t)
+		mockDb.On("GetKnowledgeSources", "ws-1").Return(testItems, nil).Once()
+		mockDb.On("SyncKnowledgeItems", "ws-1", testItems).Return(nil).Once()
+		mockDb.On("GetKnowledgeItems", "ws-1").Return(testItems, nil).Twice()
+
+		_, err := base.Search(ctx, "ws-1", "lightning", 0)
+		require.NoError(t, err)
+		matches, err := base.Search(ctx, "ws-1", "lightning", 0)
+		require.NoError(t, err)
+
+		require.Len(t, matches, 2)
+		assert.Equal(t, db.KnowledgeArchitecture, matches[0].SourceType)
+	})
+
+	t.Run("Index Failure Keeps Old Items", func(t *testing.T) {
+		base, mockDb := newBase(t)
+		mockDb.On("GetKnowledgeSources", "ws-1").Return(nil, errors.New("db down")).Twice()
+		mockDb.On("GetKnowledgeItems", "ws-1").Return(testItems, nil).Twice()
+
+		matches, err := base.Search(ctx, "ws-1", "bounties", 0)
+		require.NoError(t, err)
+		assert.Len(t, matches, 1)
+
+		_, err = base.Search(ctx, "ws-1", "bounties", 0)
+		require.NoError(t, err)
+	})
+
+	t.Run("Context Puts Pinned Items First", func(t *testing.T) {
+		base, mockDb := newBase(t)
+		base.MaxItems = 2
+		mockDb.On("GetKnowledgeItemByID", uint(3)).Return(&testItems[2], nil).Once()
+		mockDb.On("GetKnowledgeItemByID", uint(9)).Return(&db.KnowledgeItem{ID: 9, WorkspaceUUID: "ws-2"}, nil).Once()
+		mockDb.On("GetKnowledgeSources", "ws-1").Return(testItems, nil).Once()
+		mockDb.On("SyncKnowledgeItems", "ws-1", mock.Anything).Return(nil).Once()
+		mockDb.On("GetKnowledgeItems", "ws-1").Return(testItems, nil).Once()
+
+		matches := base.Context(ctx, "ws-1", "lightning payments", []uint{3, 9, 3})
+
+		require.Len(t, matches, 2)
+		assert.Equal(t, uint(3), matches[0].ID)
+		assert.True(t, matches[0].Pinned)
+		assert.Equal(t, uint(4), matches[1].ID)
+	})
+
+	t.Run("Context Truncates Content", func(t *testing.T) {
+		base, mockDb := newBase(t)
+		base.MaxChars = 50
+		mockDb.On("GetKnowledgeSources", "ws-1").Return(testItems, nil).Once()
+		mockDb.On("SyncKnowledgeItems", "ws-1", mock.Anything).Return(nil).Once()
+		mockDb.On("GetKnowledgeItems", "ws-1").Return(testItems, nil).Once()
+
+		matches := base.Context(ctx, "ws-1", "lightning payments", nil)
+
+		require.Len(t, matches, 2)
+		assert.False(t, matches[0].Truncated)
+		assert.True(t, matches[1].Truncated)
+		assert.Len(t, matches[0].Content+matches[1].Content, 50)
+	})
+
+	t.Run("Context Without Workspace", func(t *testing.T) {
+		base, _ := newBase(t)
+
+		assert.Empty(t, base.Context(ctx, "", "lightning", nil))
+	})
+}
@@ -0,0 +1,116 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// Locker hands out locks shared by every replica. unlock must be called once
+// the lock is no longer needed.
+type Locker interface {
+	TryLock(ctx context.Context, name string) (unlock func(), acquired bool, err error)
+}
+
+// JobRecorder keeps the run history of exclusive jobs.
+type JobRecorder interface {
+	StartJobRun(name, holder string) (uint, error)
+	FinishJobRun(id uint, runErr error) error
+	GetLastJobSuccess(name string) (*time.Time, error)
+}
+
+// Holder identifies this process in job run history.
+func Holder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// UseCluster makes exclusive jobs take locker before they run and record
+// every run with recorder. Either may be nil.
+func (m *Manager) UseCluster(holder string, locker Locker, recorder JobRecorder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.holder = holder
+	m.locker = locker
+	m.recorder = recorder
+}
+
+// ExclusiveJob wraps job for a scheduler such as cron.AddFunc, see RunExclusive.
+func (m *Manager) ExclusiveJob(name string, every time.Duration, job func()) func() {
+	return func() {
+		m.RunExclusive(name, every, job)
+	}
+}
+
+// RunExclusive runs job at most once per interval across every replica. The
+// replica that takes the lock checks when the job last succeeded and skips it
+// when another replica already ran it within the interval. Panics are
+// recorded as a failed run.
+func (m *Manager) RunExclusive(name string, every time.Duration, job func()) bool {
+	m.mu.Lock()
+	holder, locker, recorder := m.holder, m.locker, m.recorder
+	m.mu.Unlock()
+
+	if locker != nil {
+		unlock, acquired, err := locker.TryLock(context.Background(), name)
+		if err != nil {
+			logger.Log.Error("[lifecycle] could not lock job %s: %v", name, err)
+			return false
+		}
+		if !acquired {
+			logger.Log.Info("[lifecycle] job %s is running on another replica", name)
+			return false
+		}
+		defer unlock()
+	}
+
+	if recorder != nil && every > 0 {
+		lastSuccess, err := recorder.GetLastJobSuccess(name)
+		if err != nil {
+			logger.Log.Error("[lifecycle] could not read history of job %s: %v", name, err)
+			return false
+		}
+		// allow some slack, replicas do not tick at exactly the same moment
+		if lastSuccess != nil && time.Since(*lastSuccess) < every-every/10 {
+			return false
+		}
+	}
+
+	return m.RunJob(name, func() {
+		var runID uint
+		if recorder != nil {
+			id, err := recorder.StartJobRun(name, holder)
+			if err != nil {
+				logger.Log.Error("[lifecycle] could not record run of job %s: %v", name, err)
+			}
+			runID = id
+		}
+
+		runErr := runRecovered(job)
+		if runErr != nil {
+			logger.Log.Error("[lifecycle] job %s failed: %v", name, runErr)
+		}
+
+		if recorder != nil && runID != 0 {
+			if err := recorder.FinishJobRun(runID, runErr); err != nil {
+				logger.Log.Error("[lifecycle] could not record result of job %s: %v", name, err)
+			}
+		}
+	})
+}
+
+func runRecovered(job func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	job()
+	return nil
+}
@@ -0,0 +1,165 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+	err  error
+}
+
+func newFakeLocker() *fakeLocker {
+	return &fakeLocker{held: map[string]bool{}}
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return nil, false, l.err
+	}
+	if l.held[name] {
+		return nil, false, nil
+	}
+	l.held[name] = true
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.held, name)
+	}, true, nil
+}
+
+type fakeRun struct {
+	name   string
+	holder string
+	err    error
+	start  time.Time
+	done   bool
+}
+
+type fakeRecorder struct {
+	mu   sync.Mutex
+	runs []*fakeRun
+}
+
+func (r *fakeRecorder) StartJobRun(name, holder string) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, &fakeRun{name: name, holder: holder, start: time.Now()})
+	return uint(len(r.runs)), nil
+}
+
+func (r *fakeRecorder) FinishJobRun(id uint, runErr error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs[id-1].err = runErr
+	r.runs[id-1].done = true
+	return nil
+}
+
+func (r *fakeRecorder) GetLastJobSuccess(name string) (*time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var last *time.Time
+	for _, run := range r.runs {
+		if run.name == name && run.done && run.err == nil {
+			start := run.start
+			last = &start
+		}
+	}
+	return last, nil
+}
+
+func TestRunExclusive(t *testing.T) {
+	t.Run("Runs Without Cluster", func(t *testing.T) {
+		m := NewManager()
+		ran := false
+		assert.True(t, m.RunExclusive("payments", time.Minute, func() { ran = true }))
+		assert.True(t, ran)
+	})
+
+	t.Run("Records Successful Run", func(t *testing.T) {
+		recorder := &fakeRecorder{}
+		m := NewManager()
+		m.UseCluster("replica-a", newFakeLocker(), recorder)
+
+		assert.True(t, m.RunExclusive("payments", time.Minute, func() {}))
+		assert.Len(t, recorder.runs, 1)
+		assert.Equal(t, "replica-a", recorder.runs[0].holder)
+		assert.True(t, recorder.runs[0].done)
+		assert.NoError(t, recorder.runs[0].err)
+	})
+
+	t.Run("Records Panic As Failure", func(t *testing.T) {
+		recorder := &fakeRecorder{}
+		m := NewManager()
+		m.UseCluster("replica-a", newFakeLocker(), recorder)
+
+		assert.True(t, m.RunExclusive("payments", time.Minute, func() { panic("boom") }))
+		assert.Len(t, recorder.runs, 1)
+		assert.EqualError(t, recorder.runs[0].err, "panic: boom")
+	})
+
+	t.Run("Skips While Another Replica Holds The Lock", func(t *testing.T) {
+		locker := newFakeLocker()
+		recorder := &fakeRecorder{}
+		first := NewManager()
+		second := NewManager()
+		first.UseCluster("replica-a", locker, recorder)
+		second.UseCluster("replica-b", locker, recorder)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		go first.RunExclusive("payments", time.Minute, func() {
+			close(started)
+			<-release
+		})
+		<-started
+
+		ran := false
+		assert.False(t, second.RunExclusive("payments", time.Minute, func() { ran = true }))
+		assert.False(t, ran)
+		close(release)
+	})
+
+	t.Run("Skips When Another Replica Ran Within The Interval", func(t *testing.T) {
+		locker := newFakeLocker()
+		recorder := &fakeRecorder{}
+		first := NewManager()
+		second := NewManager()
+		first.UseCluster("replica-a", locker, recorder)
+		second.UseCluster("replica-b", locker, recorder)
+
+		assert.True(t, first.RunExclusive("payments", time.Minute, func() {}))
+		assert.False(t, second.RunExclusive("payments", time.Minute, func() {}))
+		assert.True(t, second.RunExclusive("other job", time.Minute, func() {}))
+	})
+
+	t.Run("Retries After Failed Run", func(t *testing.T) {
+		recorder := &fakeRecorder{}
+		m := NewManager()
+		m.UseCluster("replica-a", newFakeLocker(), recorder)
+
+		m.RunExclusive("payments", time.Minute, func() { panic("boom") })
+		assert.True(t, m.RunExclusive("payments", time.Minute, func() {}))
+	})
+
+	t.Run("Lock Error", func(t *testing.T) {
+		locker := newFakeLocker()
+		locker.err = errors.New("connection refused")
+		m := NewManager()
+		m.UseCluster("replica-a", locker, nil)
+
+		ran := false
+		assert.False(t, m.RunExclusive("payments", time.Minute, func() { ran = true }))
+		assert.False(t, ran)
+	})
+}
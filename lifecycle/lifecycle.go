@@ -0,0 +1,214 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// StopFunc stops a component and returns how many in-flight items it drained.
+type StopFunc func(ctx context.Context) (int, error)
+
+type component struct {
+	name string
+	stop StopFunc
+}
+
+type ComponentReport struct {
+	Name     string        `json:"name"`
+	Drained  int           `json:"drained"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+type Report struct {
+	Components []ComponentReport `json:"components"`
+	Duration   time.Duration     `json:"duration"`
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "shutdown finished in %v", r.Duration)
+	for _, c := range r.Components {
+		fmt.Fprintf(&b, "\n  %s: drained %d in %v", c.Name, c.Drained, c.Duration)
+		if c.Error != "" {
+			fmt.Fprintf(&b, " (error: %s)", c.Error)
+		}
+	}
+	return b.String()
+}
+
+func (r Report) HasErrors() bool {
+	for _, c := range r.Components {
+		if c.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Manager coordinates the shutdown of long running components. Like defer,
+// components are stopped in the reverse of the order they were registered, so
+// whatever feeds work into a component is stopped before the component itself.
+type Manager struct {
+	mu         sync.Mutex
+	components []component
+	jobs       sync.WaitGroup
+	running    map[string]int
+	draining   bool
+	done       chan struct{}
+	holder     string
+	locker     Locker
+	recorder   JobRecorder
+}
+
+var Default = NewManager()
+
+func NewManager() *Manager {
+	return &Manager{
+		running: make(map[string]int),
+		done:    make(chan struct{}),
+	}
+}
+
+func (m *Manager) Register(name string, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, component{name: name, stop: stop})
+}
+
+// Done is closed once shutdown starts, so loops can stop picking up new work.
+func (m *Manager) Done() <-chan struct{} {
+	return m.done
+}
+
+func (m *Manager) Draining() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.draining
+}
+
+// RunJob runs job synchronously and tracks it as in-flight. It refuses to
+// start new jobs once shutdown has begun.
+func (m *Manager) RunJob(name string, job func()) bool {
+	m.mu.Lock()
+	if m.draining {
+		m.mu.Unlock()
+		logger.Log.Info("[lifecycle] skipping job %s, shutting down", name)
+		return false
+	}
+	m.jobs.Add(1)
+	m.running[name]++
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		m.running[name]--
+		if m.running[name] == 0 {
+			delete(m.running, name)
+		}
+		m.mu.Unlock()
+		m.jobs.Done()
+	}()
+
+	job()
+	return true
+}
+
+// Job wraps job for a scheduler such as cron.AddFunc.
+func (m *Manager) Job(name string, job func()) func() {
+	return func() {
+		m.RunJob(name, job)
+	}
+}
+
+// Go starts fn in a goroutine and registers a component that waits for it to
+// return. fn is expected to watch Done.
+func (m *Manager) Go(name string, fn func()) {
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		fn()
+	}()
+
+	m.Register(name, func(ctx context.Context) (int, error) {
+		select {
+		case <-exited:
+			return 1, nil
+		case <-ctx.Done():
+			return 0, fmt.Errorf("%s did not exit: %w", name, ctx.Err())
+		}
+	})
+}
+
+func (m *Manager) RunningJobs() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	running := make(map[string]int, len(m.running))
+	for name, count := range m.running {
+		running[name] = count
+	}
+	return running
+}
+
+// WaitForJobs is a StopFunc that waits for jobs started with RunJob.
+func (m *Manager) WaitForJobs(ctx context.Context) (int, error) {
+	inFlight := 0
+	for _, count := range m.RunningJobs() {
+		inFlight += count
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		m.jobs.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return inFlight, nil
+	case <-ctx.Done():
+		return 0, fmt.Errorf("jobs still running %v: %w", m.RunningJobs(), ctx.Err())
+	}
+}
+
+// Shutdown stops accepting new jobs, then stops every registered component.
+// It is safe to call more than once; later calls return an empty report.
+func (m *Manager) Shutdown(ctx context.Context) Report {
+	start := time.Now()
+
+	m.mu.Lock()
+	if m.draining {
+		m.mu.Unlock()
+		return Report{}
+	}
+	m.draining = true
+	close(m.done)
+	components := append([]component(nil), m.components...)
+	m.mu.Unlock()
+
+	report := Report{}
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		componentStart := time.Now()
+		drained, err := c.stop(ctx)
+
+		entry := ComponentReport{
+			Name:     c.name,
+			Drained:  drained,
+			Duration: time.Since(componentStart),
+		}
+		if err != nil {
+			entry.Error = err.Error()
+			logger.Log.Error("[lifecycle] %s failed to stop: %v", c.name, err)
+		}
+		report.Components = append(report.Components, entry)
+	}
+
+	report.Duration = time.Since(start)
+	return report
+}
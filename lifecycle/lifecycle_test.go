@@ -0,0 +1,166 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	m := NewManager()
+
+	var mu sync.Mutex
+	var order []string
+	stopper := func(name string) StopFunc {
+		return func(ctx context.Context) (int, error) {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return 0, nil
+		}
+	}
+
+	m.Register("websocket pool", stopper("websocket pool"))
+	m.Register("cron scheduler", stopper("cron scheduler"))
+	m.Register("http server", stopper("http server"))
+
+	report := m.Shutdown(context.Background())
+
+	assert.Equal(t, []string{"http server", "cron scheduler", "websocket pool"}, order)
+	assert.Len(t, report.Components, 3)
+	assert.False(t, report.HasErrors())
+	assert.True(t, m.Draining())
+}
+
+func TestShutdownReportsErrors(t *testing.T) {
+	m := NewManager()
+	m.Register("healthy", func(ctx context.Context) (int, error) { return 3, nil })
+	m.Register("broken", func(ctx context.Context) (int, error) { return 0, errors.New("boom") })
+
+	report := m.Shutdown(context.Background())
+
+	assert.True(t, report.HasErrors())
+	assert.Equal(t, "broken", report.Components[0].Name)
+	assert.Equal(t, "boom", report.Components[0].Error)
+	assert.Equal(t, "healthy", report.Components[1].Name)
+	assert.Equal(t, 3, report.Components[1].Drained)
+	assert.Contains(t, report.String(), "healthy: drained 3")
+}
+
+func TestShutdownIsIdempotent(t *testing.T) {
+	m := NewManager()
+	calls := 0
+	m.Register("component", func(ctx context.Context) (int, error) {
+		calls++
+		return 0, nil
+	})
+
+	m.Shutdown(context.Background())
+	second := m.Shutdown(context.Background())
+
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, second.Components)
+}
+
+func TestRunJob(t *testing.T) {
+	tests := []struct {
+		name        string
+		draining    bool
+		expectedRun bool
+	}{
+		{
+			name:        "Runs Job Before Shutdown",
+			draining:    false,
+			expectedRun: true,
+		},
+		{
+			name:        "Refuses Job While Draining",
+			draining:    true,
+			expectedRun: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager()
+			if tt.draining {
+				m.Shutdown(context.Background())
+			}
+
+			ran := false
+			started := m.RunJob("payments", func() { ran = true })
+
+			assert.Equal(t, tt.expectedRun, started)
+			assert.Equal(t, tt.expectedRun, ran)
+			assert.Empty(t, m.RunningJobs())
+		})
+	}
+}
+
+func TestWaitForJobsLetsInFlightJobFinish(t *testing.T) {
+	m := NewManager()
+	m.Register("cron jobs", m.WaitForJobs)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	finished := false
+	go m.Job("payments", func() {
+		close(started)
+		<-release
+		finished = true
+	})()
+	<-started
+
+	assert.Equal(t, map[string]int{"payments": 1}, m.RunningJobs())
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+
+	report := m.Shutdown(context.Background())
+
+	assert.True(t, finished)
+	assert.False(t, report.HasErrors())
+	assert.Equal(t, 1, report.Components[0].Drained)
+}
+
+func TestWaitForJobsTimesOut(t *testing.T) {
+	m := NewManager()
+	m.Register("cron jobs", m.WaitForJobs)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	go m.RunJob("payments", func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	report := m.Shutdown(ctx)
+
+	assert.True(t, report.HasErrors())
+	assert.Contains(t, report.Components[0].Error, "payments")
+}
+
+func TestGoStopsWithDone(t *testing.T) {
+	m := NewManager()
+	m.Go("loop", func() {
+		<-m.Done()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	report := m.Shutdown(ctx)
+
+	assert.False(t, report.HasErrors())
+	assert.Equal(t, "loop", report.Components[0].Name)
+	assert.Equal(t, 1, report.Components[0].Drained)
+}
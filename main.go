@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -15,7 +16,12 @@ import (
 	"github.com/stakwork/sphinx-tribes/db"
 	_ "github.com/stakwork/sphinx-tribes/docs"
 	"github.com/stakwork/sphinx-tribes/handlers"
+	"github.com/stakwork/sphinx-tribes/knowledge"
+	"github.com/stakwork/sphinx-tribes/lifecycle"
+	"github.com/stakwork/sphinx-tribes/logger"
 	"github.com/stakwork/sphinx-tribes/routes"
+	"github.com/stakwork/sphinx-tribes/sse"
+	"github.com/stakwork/sphinx-tribes/storage"
 	"github.com/stakwork/sphinx-tribes/websocket"
 	"gopkg.in/go-playground/validator.v9"
 )
@@ -45,50 +51,216 @@ func main() {
 		fmt.Println("no .env file")
 	}
 
-	db.InitDB()
-	db.InitRedis()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	// Config has to be inited before JWT, if not it will lead to NO JWT error
+	cfg := config.InitConfig()
+
+	db.InitDB(cfg)
+	db.InitRedis(cfg.Redis)
 	db.InitCache()
 	db.InitRoles()
-	db.DB.ProcessUpdateTicketsWithoutGroup()
 
-	// Config has to be inited before JWT, if not it will lead to NO JWT error
-	config.InitConfig()
 	auth.InitJwt()
 
+	if err := storage.Init(cfg); err != nil {
+		logger.Log.Error("file storage unavailable: %v", err)
+	}
+	knowledge.Init(db.DB, cfg)
+
 	// validate
 	db.Validate = validator.New()
 	// Start websocket pool
+	initWebsocketBus()
 	go websocket.WebsocketPool.Start()
+	lifecycle.Default.Register("websocket pool", websocket.WebsocketPool.Shutdown)
+	lifecycle.Default.Register("sse clients", sse.ClientRegistry.Shutdown)
+	db.OnChatEvent = sse.Streams.Notify
+
+	initJobCluster(cfg.Jobs)
+
+	if !cfg.Server.SkipLoops {
+		lifecycle.Default.Go("twitter confirmations loop", handlers.ProcessTwitterConfirmationsLoop)
+		lifecycle.Default.Go("github issues loop", func() { handlers.ProcessGithubIssuesLoop(cfg.Github.Token) })
+
+		// every replica starts together on a deploy, only the one holding the
+		// job lock restarts the clients so each chat is streamed once
+		lifecycle.Default.RunExclusive("restore sse clients", time.Minute, restoreSSEClients)
+	}
+
+	runCron(cfg)
+	run(cfg)
+}
+
+// initWebsocketBus shares websocket messages between replicas through redis,
+// falling back to an in-process bus when redis is not reachable.
+func initWebsocketBus() {
+	var bus websocket.Bus = websocket.NewLocalBus()
+	if db.RedisClient != nil && db.RedisError == nil {
+		bus = websocket.NewRedisBus(db.RedisClient)
+	}
+
+	if err := websocket.WebsocketPool.SetBus(bus); err != nil {
+		logger.Log.Error("websocket bus unavailable, using in-process bus: %v", err)
+		bus = websocket.NewLocalBus()
+		websocket.WebsocketPool.SetBus(bus)
+	}
+
+	lifecycle.Default.Register("websocket bus", func(ctx context.Context) (int, error) {
+		return 0, bus.Close()
+	})
+}
 
-	skipLoops := os.Getenv("SKIP_LOOPS")
-	if skipLoops != "true" {
-		go handlers.ProcessTwitterConfirmationsLoop()
-		go handlers.ProcessGithubIssuesLoop()
+// initJobCluster makes sure crons and background loops run on one replica at
+// a time. JOB_LOCK_BACKEND selects postgres advisory locks (default) or redis.
+func initJobCluster(jobs config.JobsConfig) {
+	var locker lifecycle.Locker = db.DB.NewAdvisoryLocker()
+	if jobs.LockBackend == "redis" {
+		if db.RedisError != nil {
+			logger.Log.Error("redis unavailable for job locks, using postgres: %v", db.RedisError)
+		} else {
+			locker = db.NewRedisLocker(db.RedisClient, 5*time.Minute)
+		}
 	}
 
-	runCron()
-	run()
+	lifecycle.Default.UseCluster(lifecycle.Holder(), locker, db.DB)
 }
 
-func runCron() {
+func restoreSSEClients() {
+	if restored, err := sse.RestoreClients(db.DB); err != nil {
+		logger.Log.Error("failed to restore SSE clients: %v", err)
+	} else {
+		logger.Log.Info("restored %d SSE clients", restored)
+	}
+}
+
+func runCron(cfg *config.Config) {
 	c := cron.New()
-	c.AddFunc("@every 0h30m0s", handlers.InitV2PaymentsCron)
-	c.AddFunc("@every 0h0m30s", handlers.ProcessWaitingNotifications)
+	c.AddFunc("@every 0h30m0s", lifecycle.Default.ExclusiveJob("v2 payments", 30*time.Minute, func() { handlers.InitV2PaymentsCron(cfg) }))
+	c.AddFunc("@every 0h0m30s", lifecycle.Default.ExclusiveJob("waiting notifications", 30*time.Second, func() { handlers.ProcessWaitingNotifications(cfg) }))
+	c.AddFunc("@every 1h", lifecycle.Default.ExclusiveJob("file storage gc", time.Hour, func() { storage.RunCollector(cfg.Storage) }))
+	c.AddFunc("@every 0h5m0s", lifecycle.Default.ExclusiveJob("queued bounty payments", 5*time.Minute, handlers.ProcessQueuedBountyPayments))
+	c.AddFunc("@every 0h5m0s", lifecycle.Default.ExclusiveJob("streaming chat replies", 5*time.Minute, handlers.ExpireStreamingChatReplies))
+	if minutes := cfg.Github.SyncMinutes; minutes > 0 {
+		every := time.Duration(minutes) * time.Minute
+		c.AddFunc(fmt.Sprintf("@every %s", every), lifecycle.Default.ExclusiveJob("github issue sync", every, handlers.SyncGithubIssues))
+	}
 	c.Start()
+
+	// stop scheduling first, then let jobs that already started finish
+	lifecycle.Default.Register("cron jobs", lifecycle.Default.WaitForJobs)
+	lifecycle.Default.Register("cron scheduler", func(ctx context.Context) (int, error) {
+		c.Stop()
+		return 0, nil
+	})
+}
+
+// runMigrate handles `sphinx-tribes migrate [status|up|down|redo] [steps]`
+func runMigrate(args []string) {
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	db.ConnectDB(cfg.Database)
+
+	migrator, err := db.DB.NewMigrator()
+	if err != nil {
+		fmt.Printf("error loading migrations: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	command := "status"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	steps := 0
+	if len(args) > 1 {
+		steps, err = strconv.Atoi(args[1])
+		if err != nil || steps < 0 {
+			fmt.Printf("invalid steps: %s\n", args[1])
+			os.Exit(1)
+		}
+	}
+
+	switch command {
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			fmt.Printf("error reading migration status: %s\n", err.Error())
+			os.Exit(1)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, state)
+		}
+	case "up":
+		applied, err := migrator.Up(steps)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Printf("error applying migrations: %s\n", err.Error())
+			os.Exit(1)
+		}
+	case "down":
+		if steps == 0 {
+			steps = 1
+		}
+		reverted, err := migrator.Down(steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Printf("error reverting migrations: %s\n", err.Error())
+			os.Exit(1)
+		}
+	case "redo":
+		m, err := migrator.Redo()
+		if err != nil {
+			fmt.Printf("error redoing migration: %s\n", err.Error())
+			os.Exit(1)
+		}
+		fmt.Printf("redid %04d_%s\n", m.Version, m.Name)
+	default:
+		fmt.Println("usage: sphinx-tribes migrate [status|up|down|redo] [steps]")
+		os.Exit(1)
+	}
 }
 
 // Start the MQTT plugin
-func run() {
-	router := routes.NewRouter()
+func run(cfg *config.Config) {
+	router := routes.NewRouter(cfg)
+	lifecycle.Default.Register("http server", func(ctx context.Context) (int, error) {
+		return 0, router.Shutdown(ctx)
+	})
+	// components stop in reverse order, the open chat streams have to end
+	// before the http server waits for its connections to finish
+	lifecycle.Default.Register("chat streams", sse.Streams.Shutdown)
 
-	shutdownSignal := make(chan os.Signal)
+	shutdownSignal := make(chan os.Signal, 1)
 	signal.Notify(shutdownSignal, syscall.SIGINT, syscall.SIGTERM)
 	<-shutdownSignal
 
-	// shutdown web server
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout(cfg.Server))
 	defer cancel()
-	if err := router.Shutdown(ctx); err != nil {
-		fmt.Printf("error shutting down server: %s", err.Error())
+
+	report := lifecycle.Default.Shutdown(ctx)
+	if report.HasErrors() {
+		logger.Log.Error("%s", report.String())
+	} else {
+		logger.Log.Info("%s", report.String())
 	}
 }
+
+// shutdownTimeout gives in-flight payment jobs time to finish, defaulting to 30s.
+func shutdownTimeout(server config.ServerConfig) time.Duration {
+	return time.Duration(server.ShutdownTimeout) * time.Second
+}
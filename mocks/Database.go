@@ -1084,9 +1084,9 @@ func (_c *Database_CreateArtifact_Call) RunAndReturn(run func(*db.Artifact) (*db
 	return _c
 }
 
-// CreateBountyFromTicket provides a mock function with given fields: ticket, pubkey
-func (_m *Database) CreateBountyFromTicket(ticket db.Tickets, pubkey string) (*db.NewBounty, error) {
-	ret := _m.Called(ticket, pubkey)
+// CreateBountyFromTicket provides a mock function with given fields: ticket, pubkey, template
+func (_m *Database) CreateBountyFromTicket(ticket db.Tickets, pubkey string, template db.WorkspaceTemplate) (*db.NewBounty, error) {
+	ret := _m.Called(ticket, pubkey, template)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateBountyFromTicket")
@@ -1094,19 +1094,19 @@ func (_m *Database) CreateBountyFromTicket(ticket db.Tickets, pubkey string) (*d
 
 	var r0 *db.NewBounty
 	var r1 error
-	if rf, ok := ret.Get(0).(func(db.Tickets, string) (*db.NewBounty, error)); ok {
-		return rf(ticket, pubkey)
+	if rf, ok := ret.Get(0).(func(db.Tickets, string, db.WorkspaceTemplate) (*db.NewBounty, error)); ok {
+		return rf(ticket, pubkey, template)
 	}
-	if rf, ok := ret.Get(0).(func(db.Tickets, string) *db.NewBounty); ok {
-		r0 = rf(ticket, pubkey)
+	if rf, ok := ret.Get(0).(func(db.Tickets, string, db.WorkspaceTemplate) *db.NewBounty); ok {
+		r0 = rf(ticket, pubkey, template)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*db.NewBounty)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(db.Tickets, string) error); ok {
-		r1 = rf(ticket, pubkey)
+	if rf, ok := ret.Get(1).(func(db.Tickets, string, db.WorkspaceTemplate) error); ok {
+		r1 = rf(ticket, pubkey, template)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1122,13 +1122,14 @@ type Database_CreateBountyFromTicket_Call struct {
 // CreateBountyFromTicket is a helper method to define mock.On call
 //   - ticket db.Tickets
 //   - pubkey string
-func (_e *Database_Expecter) CreateBountyFromTicket(ticket interface{}, pubkey interface{}) *Database_CreateBountyFromTicket_Call {
-	return &Database_CreateBountyFromTicket_Call{Call: _e.mock.On("CreateBountyFromTicket", ticket, pubkey)}
+//   - template db.WorkspaceTemplate
+func (_e *Database_Expecter) CreateBountyFromTicket(ticket interface{}, pubkey interface{}, template interface{}) *Database_CreateBountyFromTicket_Call {
+	return &Database_CreateBountyFromTicket_Call{Call: _e.mock.On("CreateBountyFromTicket", ticket, pubkey, template)}
 }
 
-func (_c *Database_CreateBountyFromTicket_Call) Run(run func(ticket db.Tickets, pubkey string)) *Database_CreateBountyFromTicket_Call {
+func (_c *Database_CreateBountyFromTicket_Call) Run(run func(ticket db.Tickets, pubkey string, template db.WorkspaceTemplate)) *Database_CreateBountyFromTicket_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(db.Tickets), args[1].(string))
+		run(args[0].(db.Tickets), args[1].(string), args[2].(db.WorkspaceTemplate))
 	})
 	return _c
 }
@@ -1138,7 +1139,7 @@ func (_c *Database_CreateBountyFromTicket_Call) Return(_a0 *db.NewBounty, _a1 er
 	return _c
 }
 
-func (_c *Database_CreateBountyFromTicket_Call) RunAndReturn(run func(db.Tickets, string) (*db.NewBounty, error)) *Database_CreateBountyFromTicket_Call {
+func (_c *Database_CreateBountyFromTicket_Call) RunAndReturn(run func(db.Tickets, string, db.WorkspaceTemplate) (*db.NewBounty, error)) *Database_CreateBountyFromTicket_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -13178,6 +13179,52 @@ func (_c *Database_SaveNotification_Call) RunAndReturn(run func(string, string,
 	return _c
 }
 
+// SaveSSEClientState provides a mock function with given fields: state
+func (_m *Database) SaveSSEClientState(state db.SSEClientState) error {
+	ret := _m.Called(state)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSSEClientState")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(db.SSEClientState) error); ok {
+		r0 = rf(state)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_SaveSSEClientState_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveSSEClientState'
+type Database_SaveSSEClientState_Call struct {
+	*mock.Call
+}
+
+// SaveSSEClientState is a helper method to define mock.On call
+//   - state db.SSEClientState
+func (_e *Database_Expecter) SaveSSEClientState(state interface{}) *Database_SaveSSEClientState_Call {
+	return &Database_SaveSSEClientState_Call{Call: _e.mock.On("SaveSSEClientState", state)}
+}
+
+func (_c *Database_SaveSSEClientState_Call) Run(run func(state db.SSEClientState)) *Database_SaveSSEClientState_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.SSEClientState))
+	})
+	return _c
+}
+
+func (_c *Database_SaveSSEClientState_Call) Return(_a0 error) *Database_SaveSSEClientState_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_SaveSSEClientState_Call) RunAndReturn(run func(db.SSEClientState) error) *Database_SaveSSEClientState_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SearchBots provides a mock function with given fields: s, limit, offset
 func (_m *Database) SearchBots(s string, limit int, offset int) []db.BotRes {
 	ret := _m.Called(s, limit, offset)
@@ -15807,6 +15854,4699 @@ func (_c *Database_WithdrawBudget_Call) RunAndReturn(run func(string, string, ui
 	return _c
 }
 
+// GetSSEClientState provides a mock function with given fields: chatID, url
+func (_m *Database) GetSSEClientState(chatID string, url string) (*db.SSEClientState, error) {
+	ret := _m.Called(chatID, url)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSSEClientState")
+	}
+
+	var r0 *db.SSEClientState
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*db.SSEClientState, error)); ok {
+		return rf(chatID, url)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *db.SSEClientState); ok {
+		r0 = rf(chatID, url)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.SSEClientState)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(chatID, url)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetSSEClientState_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSSEClientState'
+type Database_GetSSEClientState_Call struct {
+	*mock.Call
+}
+
+// GetSSEClientState is a helper method to define mock.On call
+//   - chatID string
+//   - url string
+func (_e *Database_Expecter) GetSSEClientState(chatID interface{}, url interface{}) *Database_GetSSEClientState_Call {
+	return &Database_GetSSEClientState_Call{Call: _e.mock.On("GetSSEClientState", chatID, url)}
+}
+
+func (_c *Database_GetSSEClientState_Call) Run(run func(chatID string, url string)) *Database_GetSSEClientState_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetSSEClientState_Call) Return(_a0 *db.SSEClientState, _a1 error) *Database_GetSSEClientState_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetSSEClientState_Call) RunAndReturn(run func(string, string) (*db.SSEClientState, error)) *Database_GetSSEClientState_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartJobRun provides a mock function with given fields: name, holder
+func (_m *Database) StartJobRun(name string, holder string) (uint, error) {
+	ret := _m.Called(name, holder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartJobRun")
+	}
+
+	var r0 uint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (uint, error)); ok {
+		return rf(name, holder)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) uint); ok {
+		r0 = rf(name, holder)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(name, holder)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_StartJobRun_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartJobRun'
+type Database_StartJobRun_Call struct {
+	*mock.Call
+}
+
+// StartJobRun is a helper method to define mock.On call
+//   - name string
+//   - holder string
+func (_e *Database_Expecter) StartJobRun(name interface{}, holder interface{}) *Database_StartJobRun_Call {
+	return &Database_StartJobRun_Call{Call: _e.mock.On("StartJobRun", name, holder)}
+}
+
+func (_c *Database_StartJobRun_Call) Run(run func(name string, holder string)) *Database_StartJobRun_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_StartJobRun_Call) Return(_a0 uint, _a1 error) *Database_StartJobRun_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_StartJobRun_Call) RunAndReturn(run func(string, string) (uint, error)) *Database_StartJobRun_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FinishJobRun provides a mock function with given fields: id, runErr
+func (_m *Database) FinishJobRun(id uint, runErr error) error {
+	ret := _m.Called(id, runErr)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinishJobRun")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, error) error); ok {
+		r0 = rf(id, runErr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_FinishJobRun_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FinishJobRun'
+type Database_FinishJobRun_Call struct {
+	*mock.Call
+}
+
+// FinishJobRun is a helper method to define mock.On call
+//   - id uint
+//   - runErr error
+func (_e *Database_Expecter) FinishJobRun(id interface{}, runErr interface{}) *Database_FinishJobRun_Call {
+	return &Database_FinishJobRun_Call{Call: _e.mock.On("FinishJobRun", id, runErr)}
+}
+
+func (_c *Database_FinishJobRun_Call) Run(run func(id uint, runErr error)) *Database_FinishJobRun_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint), args[1].(error))
+	})
+	return _c
+}
+
+func (_c *Database_FinishJobRun_Call) Return(_a0 error) *Database_FinishJobRun_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_FinishJobRun_Call) RunAndReturn(run func(uint, error) error) *Database_FinishJobRun_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetLastJobSuccess provides a mock function with given fields: name
+func (_m *Database) GetLastJobSuccess(name string) (*time.Time, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLastJobSuccess")
+	}
+
+	var r0 *time.Time
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*time.Time, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) *time.Time); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*time.Time)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetLastJobSuccess_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLastJobSuccess'
+type Database_GetLastJobSuccess_Call struct {
+	*mock.Call
+}
+
+// GetLastJobSuccess is a helper method to define mock.On call
+//   - name string
+func (_e *Database_Expecter) GetLastJobSuccess(name interface{}) *Database_GetLastJobSuccess_Call {
+	return &Database_GetLastJobSuccess_Call{Call: _e.mock.On("GetLastJobSuccess", name)}
+}
+
+func (_c *Database_GetLastJobSuccess_Call) Run(run func(name string)) *Database_GetLastJobSuccess_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetLastJobSuccess_Call) Return(_a0 *time.Time, _a1 error) *Database_GetLastJobSuccess_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetLastJobSuccess_Call) RunAndReturn(run func(string) (*time.Time, error)) *Database_GetLastJobSuccess_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetJobRuns provides a mock function with given fields: name, limit
+func (_m *Database) GetJobRuns(name string, limit int) ([]db.JobRun, error) {
+	ret := _m.Called(name, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJobRuns")
+	}
+
+	var r0 []db.JobRun
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]db.JobRun, error)); ok {
+		return rf(name, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []db.JobRun); ok {
+		r0 = rf(name, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.JobRun)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(name, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetJobRuns_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetJobRuns'
+type Database_GetJobRuns_Call struct {
+	*mock.Call
+}
+
+// GetJobRuns is a helper method to define mock.On call
+//   - name string
+//   - limit int
+func (_e *Database_Expecter) GetJobRuns(name interface{}, limit interface{}) *Database_GetJobRuns_Call {
+	return &Database_GetJobRuns_Call{Call: _e.mock.On("GetJobRuns", name, limit)}
+}
+
+func (_c *Database_GetJobRuns_Call) Run(run func(name string, limit int)) *Database_GetJobRuns_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Database_GetJobRuns_Call) Return(_a0 []db.JobRun, _a1 error) *Database_GetJobRuns_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetJobRuns_Call) RunAndReturn(run func(string, int) ([]db.JobRun, error)) *Database_GetJobRuns_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetJobStatuses provides a mock function with no fields
+func (_m *Database) GetJobStatuses() ([]db.JobStatus, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJobStatuses")
+	}
+
+	var r0 []db.JobStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]db.JobStatus, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []db.JobStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.JobStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetJobStatuses_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetJobStatuses'
+type Database_GetJobStatuses_Call struct {
+	*mock.Call
+}
+
+// GetJobStatuses is a helper method to define mock.On call
+func (_e *Database_Expecter) GetJobStatuses() *Database_GetJobStatuses_Call {
+	return &Database_GetJobStatuses_Call{Call: _e.mock.On("GetJobStatuses")}
+}
+
+func (_c *Database_GetJobStatuses_Call) Run(run func()) *Database_GetJobStatuses_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Database_GetJobStatuses_Call) Return(_a0 []db.JobStatus, _a1 error) *Database_GetJobStatuses_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetJobStatuses_Call) RunAndReturn(run func() ([]db.JobStatus, error)) *Database_GetJobStatuses_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordChatEvent provides a mock function with given fields: chatID, eventType, payload
+func (_m *Database) RecordChatEvent(chatID string, eventType db.ChatEventType, payload interface{}) (*db.ChatEvent, error) {
+	ret := _m.Called(chatID, eventType, payload)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordChatEvent")
+	}
+
+	var r0 *db.ChatEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, db.ChatEventType, interface{}) (*db.ChatEvent, error)); ok {
+		return rf(chatID, eventType, payload)
+	}
+	if rf, ok := ret.Get(0).(func(string, db.ChatEventType, interface{}) *db.ChatEvent); ok {
+		r0 = rf(chatID, eventType, payload)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.ChatEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, db.ChatEventType, interface{}) error); ok {
+		r1 = rf(chatID, eventType, payload)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_RecordChatEvent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordChatEvent'
+type Database_RecordChatEvent_Call struct {
+	*mock.Call
+}
+
+// RecordChatEvent is a helper method to define mock.On call
+//   - chatID string
+//   - eventType db.ChatEventType
+//   - payload interface{}
+func (_e *Database_Expecter) RecordChatEvent(chatID interface{}, eventType interface{}, payload interface{}) *Database_RecordChatEvent_Call {
+	return &Database_RecordChatEvent_Call{Call: _e.mock.On("RecordChatEvent", chatID, eventType, payload)}
+}
+
+func (_c *Database_RecordChatEvent_Call) Run(run func(chatID string, eventType db.ChatEventType, payload interface{})) *Database_RecordChatEvent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(db.ChatEventType), args[2].(interface{}))
+	})
+	return _c
+}
+
+func (_c *Database_RecordChatEvent_Call) Return(_a0 *db.ChatEvent, _a1 error) *Database_RecordChatEvent_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_RecordChatEvent_Call) RunAndReturn(run func(string, db.ChatEventType, interface{}) (*db.ChatEvent, error)) *Database_RecordChatEvent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChatEventsAfter provides a mock function with given fields: chatID, afterID, limit
+func (_m *Database) GetChatEventsAfter(chatID string, afterID uint64, limit int) ([]db.ChatEvent, error) {
+	ret := _m.Called(chatID, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatEventsAfter")
+	}
+
+	var r0 []db.ChatEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, uint64, int) ([]db.ChatEvent, error)); ok {
+		return rf(chatID, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, uint64, int) []db.ChatEvent); ok {
+		r0 = rf(chatID, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ChatEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, uint64, int) error); ok {
+		r1 = rf(chatID, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetChatEventsAfter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChatEventsAfter'
+type Database_GetChatEventsAfter_Call struct {
+	*mock.Call
+}
+
+// GetChatEventsAfter is a helper method to define mock.On call
+//   - chatID string
+//   - afterID uint64
+//   - limit int
+func (_e *Database_Expecter) GetChatEventsAfter(chatID interface{}, afterID interface{}, limit interface{}) *Database_GetChatEventsAfter_Call {
+	return &Database_GetChatEventsAfter_Call{Call: _e.mock.On("GetChatEventsAfter", chatID, afterID, limit)}
+}
+
+func (_c *Database_GetChatEventsAfter_Call) Run(run func(chatID string, afterID uint64, limit int)) *Database_GetChatEventsAfter_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(uint64), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatEventsAfter_Call) Return(_a0 []db.ChatEvent, _a1 error) *Database_GetChatEventsAfter_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatEventsAfter_Call) RunAndReturn(run func(string, uint64, int) ([]db.ChatEvent, error)) *Database_GetChatEventsAfter_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AppendChatMessageChunk provides a mock function with given fields: chatID, messageID, sequence, delta
+func (_m *Database) AppendChatMessageChunk(chatID string, messageID string, sequence int, delta string) (db.ChatMessage, []db.ChatMessageDelta, error) {
+	ret := _m.Called(chatID, messageID, sequence, delta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AppendChatMessageChunk")
+	}
+
+	var r0 db.ChatMessage
+	var r1 []db.ChatMessageDelta
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, string, int, string) (db.ChatMessage, []db.ChatMessageDelta, error)); ok {
+		return rf(chatID, messageID, sequence, delta)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int, string) db.ChatMessage); ok {
+		r0 = rf(chatID, messageID, sequence, delta)
+	} else {
+		r0 = ret.Get(0).(db.ChatMessage)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int, string) []db.ChatMessageDelta); ok {
+		r1 = rf(chatID, messageID, sequence, delta)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]db.ChatMessageDelta)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(string, string, int, string) error); ok {
+		r2 = rf(chatID, messageID, sequence, delta)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Database_AppendChatMessageChunk_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AppendChatMessageChunk'
+type Database_AppendChatMessageChunk_Call struct {
+	*mock.Call
+}
+
+// AppendChatMessageChunk is a helper method to define mock.On call
+//   - chatID string
+//   - messageID string
+//   - sequence int
+//   - delta string
+func (_e *Database_Expecter) AppendChatMessageChunk(chatID interface{}, messageID interface{}, sequence interface{}, delta interface{}) *Database_AppendChatMessageChunk_Call {
+	return &Database_AppendChatMessageChunk_Call{Call: _e.mock.On("AppendChatMessageChunk", chatID, messageID, sequence, delta)}
+}
+
+func (_c *Database_AppendChatMessageChunk_Call) Run(run func(chatID string, messageID string, sequence int, delta string)) *Database_AppendChatMessageChunk_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(int), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *Database_AppendChatMessageChunk_Call) Return(_a0 db.ChatMessage, _a1 []db.ChatMessageDelta, _a2 error) *Database_AppendChatMessageChunk_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Database_AppendChatMessageChunk_Call) RunAndReturn(run func(string, string, int, string) (db.ChatMessage, []db.ChatMessageDelta, error)) *Database_AppendChatMessageChunk_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FinalizeChatMessage provides a mock function with given fields: chatID, messageID, response
+func (_m *Database) FinalizeChatMessage(chatID string, messageID string, response string) (db.ChatMessage, bool, error) {
+	ret := _m.Called(chatID, messageID, response)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinalizeChatMessage")
+	}
+
+	var r0 db.ChatMessage
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (db.ChatMessage, bool, error)); ok {
+		return rf(chatID, messageID, response)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) db.ChatMessage); ok {
+		r0 = rf(chatID, messageID, response)
+	} else {
+		r0 = ret.Get(0).(db.ChatMessage)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) bool); ok {
+		r1 = rf(chatID, messageID, response)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, string, string) error); ok {
+		r2 = rf(chatID, messageID, response)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Database_FinalizeChatMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FinalizeChatMessage'
+type Database_FinalizeChatMessage_Call struct {
+	*mock.Call
+}
+
+// FinalizeChatMessage is a helper method to define mock.On call
+//   - chatID string
+//   - messageID string
+//   - response string
+func (_e *Database_Expecter) FinalizeChatMessage(chatID interface{}, messageID interface{}, response interface{}) *Database_FinalizeChatMessage_Call {
+	return &Database_FinalizeChatMessage_Call{Call: _e.mock.On("FinalizeChatMessage", chatID, messageID, response)}
+}
+
+func (_c *Database_FinalizeChatMessage_Call) Run(run func(chatID string, messageID string, response string)) *Database_FinalizeChatMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Database_FinalizeChatMessage_Call) Return(_a0 db.ChatMessage, _a1 bool, _a2 error) *Database_FinalizeChatMessage_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Database_FinalizeChatMessage_Call) RunAndReturn(run func(string, string, string) (db.ChatMessage, bool, error)) *Database_FinalizeChatMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSSEClientStates provides a mock function with given fields: chatID
+func (_m *Database) GetSSEClientStates(chatID string) ([]db.SSEClientState, error) {
+	ret := _m.Called(chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSSEClientStates")
+	}
+
+	var r0 []db.SSEClientState
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.SSEClientState, error)); ok {
+		return rf(chatID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.SSEClientState); ok {
+		r0 = rf(chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.SSEClientState)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetSSEClientStates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSSEClientStates'
+type Database_GetSSEClientStates_Call struct {
+	*mock.Call
+}
+
+// GetSSEClientStates is a helper method to define mock.On call
+//   - chatID string
+func (_e *Database_Expecter) GetSSEClientStates(chatID interface{}) *Database_GetSSEClientStates_Call {
+	return &Database_GetSSEClientStates_Call{Call: _e.mock.On("GetSSEClientStates", chatID)}
+}
+
+func (_c *Database_GetSSEClientStates_Call) Run(run func(chatID string)) *Database_GetSSEClientStates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetSSEClientStates_Call) Return(_a0 []db.SSEClientState, _a1 error) *Database_GetSSEClientStates_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetSSEClientStates_Call) RunAndReturn(run func(string) ([]db.SSEClientState, error)) *Database_GetSSEClientStates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetResumableSSEClientStates provides a mock function with no fields
+func (_m *Database) GetResumableSSEClientStates() ([]db.SSEClientState, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetResumableSSEClientStates")
+	}
+
+	var r0 []db.SSEClientState
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]db.SSEClientState, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []db.SSEClientState); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.SSEClientState)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetResumableSSEClientStates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetResumableSSEClientStates'
+type Database_GetResumableSSEClientStates_Call struct {
+	*mock.Call
+}
+
+// GetResumableSSEClientStates is a helper method to define mock.On call
+func (_e *Database_Expecter) GetResumableSSEClientStates() *Database_GetResumableSSEClientStates_Call {
+	return &Database_GetResumableSSEClientStates_Call{Call: _e.mock.On("GetResumableSSEClientStates")}
+}
+
+func (_c *Database_GetResumableSSEClientStates_Call) Run(run func()) *Database_GetResumableSSEClientStates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Database_GetResumableSSEClientStates_Call) Return(_a0 []db.SSEClientState, _a1 error) *Database_GetResumableSSEClientStates_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetResumableSSEClientStates_Call) RunAndReturn(run func() ([]db.SSEClientState, error)) *Database_GetResumableSSEClientStates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChatBranchHistory provides a mock function with given fields: chatID, branchID
+func (_m *Database) GetChatBranchHistory(chatID string, branchID string) ([]db.ChatMessage, error) {
+	ret := _m.Called(chatID, branchID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatBranchHistory")
+	}
+
+	var r0 []db.ChatMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]db.ChatMessage, error)); ok {
+		return rf(chatID, branchID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []db.ChatMessage); ok {
+		r0 = rf(chatID, branchID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ChatMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(chatID, branchID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetChatBranchHistory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChatBranchHistory'
+type Database_GetChatBranchHistory_Call struct {
+	*mock.Call
+}
+
+// GetChatBranchHistory is a helper method to define mock.On call
+//   - chatID string
+//   - branchID string
+func (_e *Database_Expecter) GetChatBranchHistory(chatID interface{}, branchID interface{}) *Database_GetChatBranchHistory_Call {
+	return &Database_GetChatBranchHistory_Call{Call: _e.mock.On("GetChatBranchHistory", chatID, branchID)}
+}
+
+func (_c *Database_GetChatBranchHistory_Call) Run(run func(chatID string, branchID string)) *Database_GetChatBranchHistory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatBranchHistory_Call) Return(_a0 []db.ChatMessage, _a1 error) *Database_GetChatBranchHistory_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatBranchHistory_Call) RunAndReturn(run func(string, string) ([]db.ChatMessage, error)) *Database_GetChatBranchHistory_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateChatBranch provides a mock function with given fields: chatID, parentMessageID
+func (_m *Database) CreateChatBranch(chatID string, parentMessageID string) (db.ChatBranch, error) {
+	ret := _m.Called(chatID, parentMessageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateChatBranch")
+	}
+
+	var r0 db.ChatBranch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (db.ChatBranch, error)); ok {
+		return rf(chatID, parentMessageID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) db.ChatBranch); ok {
+		r0 = rf(chatID, parentMessageID)
+	} else {
+		r0 = ret.Get(0).(db.ChatBranch)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(chatID, parentMessageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_CreateChatBranch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateChatBranch'
+type Database_CreateChatBranch_Call struct {
+	*mock.Call
+}
+
+// CreateChatBranch is a helper method to define mock.On call
+//   - chatID string
+//   - parentMessageID string
+func (_e *Database_Expecter) CreateChatBranch(chatID interface{}, parentMessageID interface{}) *Database_CreateChatBranch_Call {
+	return &Database_CreateChatBranch_Call{Call: _e.mock.On("CreateChatBranch", chatID, parentMessageID)}
+}
+
+func (_c *Database_CreateChatBranch_Call) Run(run func(chatID string, parentMessageID string)) *Database_CreateChatBranch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_CreateChatBranch_Call) Return(_a0 db.ChatBranch, _a1 error) *Database_CreateChatBranch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CreateChatBranch_Call) RunAndReturn(run func(string, string) (db.ChatBranch, error)) *Database_CreateChatBranch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChatBranches provides a mock function with given fields: chatID
+func (_m *Database) GetChatBranches(chatID string) ([]db.ChatBranch, error) {
+	ret := _m.Called(chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatBranches")
+	}
+
+	var r0 []db.ChatBranch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.ChatBranch, error)); ok {
+		return rf(chatID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.ChatBranch); ok {
+		r0 = rf(chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ChatBranch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetChatBranches_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChatBranches'
+type Database_GetChatBranches_Call struct {
+	*mock.Call
+}
+
+// GetChatBranches is a helper method to define mock.On call
+//   - chatID string
+func (_e *Database_Expecter) GetChatBranches(chatID interface{}) *Database_GetChatBranches_Call {
+	return &Database_GetChatBranches_Call{Call: _e.mock.On("GetChatBranches", chatID)}
+}
+
+func (_c *Database_GetChatBranches_Call) Run(run func(chatID string)) *Database_GetChatBranches_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatBranches_Call) Return(_a0 []db.ChatBranch, _a1 error) *Database_GetChatBranches_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatBranches_Call) RunAndReturn(run func(string) ([]db.ChatBranch, error)) *Database_GetChatBranches_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetActiveChatBranch provides a mock function with given fields: chatID, branchID
+func (_m *Database) SetActiveChatBranch(chatID string, branchID string) (db.Chat, error) {
+	ret := _m.Called(chatID, branchID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetActiveChatBranch")
+	}
+
+	var r0 db.Chat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (db.Chat, error)); ok {
+		return rf(chatID, branchID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) db.Chat); ok {
+		r0 = rf(chatID, branchID)
+	} else {
+		r0 = ret.Get(0).(db.Chat)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(chatID, branchID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_SetActiveChatBranch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetActiveChatBranch'
+type Database_SetActiveChatBranch_Call struct {
+	*mock.Call
+}
+
+// SetActiveChatBranch is a helper method to define mock.On call
+//   - chatID string
+//   - branchID string
+func (_e *Database_Expecter) SetActiveChatBranch(chatID interface{}, branchID interface{}) *Database_SetActiveChatBranch_Call {
+	return &Database_SetActiveChatBranch_Call{Call: _e.mock.On("SetActiveChatBranch", chatID, branchID)}
+}
+
+func (_c *Database_SetActiveChatBranch_Call) Run(run func(chatID string, branchID string)) *Database_SetActiveChatBranch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_SetActiveChatBranch_Call) Return(_a0 db.Chat, _a1 error) *Database_SetActiveChatBranch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SetActiveChatBranch_Call) RunAndReturn(run func(string, string) (db.Chat, error)) *Database_SetActiveChatBranch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFileAssetsByStoragePaths provides a mock function with given fields: paths
+func (_m *Database) GetFileAssetsByStoragePaths(paths []string) ([]db.FileAsset, error) {
+	ret := _m.Called(paths)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFileAssetsByStoragePaths")
+	}
+
+	var r0 []db.FileAsset
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string) ([]db.FileAsset, error)); ok {
+		return rf(paths)
+	}
+	if rf, ok := ret.Get(0).(func([]string) []db.FileAsset); ok {
+		r0 = rf(paths)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.FileAsset)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(paths)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetFileAssetsByStoragePaths_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFileAssetsByStoragePaths'
+type Database_GetFileAssetsByStoragePaths_Call struct {
+	*mock.Call
+}
+
+// GetFileAssetsByStoragePaths is a helper method to define mock.On call
+//   - paths []string
+func (_e *Database_Expecter) GetFileAssetsByStoragePaths(paths interface{}) *Database_GetFileAssetsByStoragePaths_Call {
+	return &Database_GetFileAssetsByStoragePaths_Call{Call: _e.mock.On("GetFileAssetsByStoragePaths", paths)}
+}
+
+func (_c *Database_GetFileAssetsByStoragePaths_Call) Run(run func(paths []string)) *Database_GetFileAssetsByStoragePaths_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]string))
+	})
+	return _c
+}
+
+func (_c *Database_GetFileAssetsByStoragePaths_Call) Return(_a0 []db.FileAsset, _a1 error) *Database_GetFileAssetsByStoragePaths_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetFileAssetsByStoragePaths_Call) RunAndReturn(run func([]string) ([]db.FileAsset, error)) *Database_GetFileAssetsByStoragePaths_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ImportChat provides a mock function with given fields: chat, branches, messages, artifacts
+func (_m *Database) ImportChat(chat db.Chat, branches []db.ChatBranch, messages []db.ChatMessage, artifacts []db.Artifact) (db.Chat, error) {
+	ret := _m.Called(chat, branches, messages, artifacts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportChat")
+	}
+
+	var r0 db.Chat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(db.Chat, []db.ChatBranch, []db.ChatMessage, []db.Artifact) (db.Chat, error)); ok {
+		return rf(chat, branches, messages, artifacts)
+	}
+	if rf, ok := ret.Get(0).(func(db.Chat, []db.ChatBranch, []db.ChatMessage, []db.Artifact) db.Chat); ok {
+		r0 = rf(chat, branches, messages, artifacts)
+	} else {
+		r0 = ret.Get(0).(db.Chat)
+	}
+
+	if rf, ok := ret.Get(1).(func(db.Chat, []db.ChatBranch, []db.ChatMessage, []db.Artifact) error); ok {
+		r1 = rf(chat, branches, messages, artifacts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_ImportChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ImportChat'
+type Database_ImportChat_Call struct {
+	*mock.Call
+}
+
+// ImportChat is a helper method to define mock.On call
+//   - chat db.Chat
+//   - branches []db.ChatBranch
+//   - messages []db.ChatMessage
+//   - artifacts []db.Artifact
+func (_e *Database_Expecter) ImportChat(chat interface{}, branches interface{}, messages interface{}, artifacts interface{}) *Database_ImportChat_Call {
+	return &Database_ImportChat_Call{Call: _e.mock.On("ImportChat", chat, branches, messages, artifacts)}
+}
+
+func (_c *Database_ImportChat_Call) Run(run func(chat db.Chat, branches []db.ChatBranch, messages []db.ChatMessage, artifacts []db.Artifact)) *Database_ImportChat_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.Chat), args[1].([]db.ChatBranch), args[2].([]db.ChatMessage), args[3].([]db.Artifact))
+	})
+	return _c
+}
+
+func (_c *Database_ImportChat_Call) Return(_a0 db.Chat, _a1 error) *Database_ImportChat_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_ImportChat_Call) RunAndReturn(run func(db.Chat, []db.ChatBranch, []db.ChatMessage, []db.Artifact) (db.Chat, error)) *Database_ImportChat_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFileAssetByUploadFilename provides a mock function with given fields: name
+func (_m *Database) GetFileAssetByUploadFilename(name string) (*db.FileAsset, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFileAssetByUploadFilename")
+	}
+
+	var r0 *db.FileAsset
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*db.FileAsset, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) *db.FileAsset); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.FileAsset)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetFileAssetByUploadFilename_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFileAssetByUploadFilename'
+type Database_GetFileAssetByUploadFilename_Call struct {
+	*mock.Call
+}
+
+// GetFileAssetByUploadFilename is a helper method to define mock.On call
+//   - name string
+func (_e *Database_Expecter) GetFileAssetByUploadFilename(name interface{}) *Database_GetFileAssetByUploadFilename_Call {
+	return &Database_GetFileAssetByUploadFilename_Call{Call: _e.mock.On("GetFileAssetByUploadFilename", name)}
+}
+
+func (_c *Database_GetFileAssetByUploadFilename_Call) Run(run func(name string)) *Database_GetFileAssetByUploadFilename_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetFileAssetByUploadFilename_Call) Return(_a0 *db.FileAsset, _a1 error) *Database_GetFileAssetByUploadFilename_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetFileAssetByUploadFilename_Call) RunAndReturn(run func(string) (*db.FileAsset, error)) *Database_GetFileAssetByUploadFilename_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWorkspaceStorageUsage provides a mock function with given fields: workspaceID
+func (_m *Database) GetWorkspaceStorageUsage(workspaceID string) (int64, error) {
+	ret := _m.Called(workspaceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWorkspaceStorageUsage")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int64, error)); ok {
+		return rf(workspaceID)
+	}
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(workspaceID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetWorkspaceStorageUsage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWorkspaceStorageUsage'
+type Database_GetWorkspaceStorageUsage_Call struct {
+	*mock.Call
+}
+
+// GetWorkspaceStorageUsage is a helper method to define mock.On call
+//   - workspaceID string
+func (_e *Database_Expecter) GetWorkspaceStorageUsage(workspaceID interface{}) *Database_GetWorkspaceStorageUsage_Call {
+	return &Database_GetWorkspaceStorageUsage_Call{Call: _e.mock.On("GetWorkspaceStorageUsage", workspaceID)}
+}
+
+func (_c *Database_GetWorkspaceStorageUsage_Call) Run(run func(workspaceID string)) *Database_GetWorkspaceStorageUsage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetWorkspaceStorageUsage_Call) Return(_a0 int64, _a1 error) *Database_GetWorkspaceStorageUsage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetWorkspaceStorageUsage_Call) RunAndReturn(run func(string) (int64, error)) *Database_GetWorkspaceStorageUsage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWorkspaceStorageQuota provides a mock function with given fields: workspaceID
+func (_m *Database) GetWorkspaceStorageQuota(workspaceID string) (*db.WorkspaceStorageQuota, error) {
+	ret := _m.Called(workspaceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWorkspaceStorageQuota")
+	}
+
+	var r0 *db.WorkspaceStorageQuota
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*db.WorkspaceStorageQuota, error)); ok {
+		return rf(workspaceID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *db.WorkspaceStorageQuota); ok {
+		r0 = rf(workspaceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.WorkspaceStorageQuota)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetWorkspaceStorageQuota_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWorkspaceStorageQuota'
+type Database_GetWorkspaceStorageQuota_Call struct {
+	*mock.Call
+}
+
+// GetWorkspaceStorageQuota is a helper method to define mock.On call
+//   - workspaceID string
+func (_e *Database_Expecter) GetWorkspaceStorageQuota(workspaceID interface{}) *Database_GetWorkspaceStorageQuota_Call {
+	return &Database_GetWorkspaceStorageQuota_Call{Call: _e.mock.On("GetWorkspaceStorageQuota", workspaceID)}
+}
+
+func (_c *Database_GetWorkspaceStorageQuota_Call) Run(run func(workspaceID string)) *Database_GetWorkspaceStorageQuota_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetWorkspaceStorageQuota_Call) Return(_a0 *db.WorkspaceStorageQuota, _a1 error) *Database_GetWorkspaceStorageQuota_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetWorkspaceStorageQuota_Call) RunAndReturn(run func(string) (*db.WorkspaceStorageQuota, error)) *Database_GetWorkspaceStorageQuota_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetWorkspaceStorageQuota provides a mock function with given fields: quota
+func (_m *Database) SetWorkspaceStorageQuota(quota *db.WorkspaceStorageQuota) error {
+	ret := _m.Called(quota)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetWorkspaceStorageQuota")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*db.WorkspaceStorageQuota) error); ok {
+		r0 = rf(quota)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_SetWorkspaceStorageQuota_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetWorkspaceStorageQuota'
+type Database_SetWorkspaceStorageQuota_Call struct {
+	*mock.Call
+}
+
+// SetWorkspaceStorageQuota is a helper method to define mock.On call
+//   - quota *db.WorkspaceStorageQuota
+func (_e *Database_Expecter) SetWorkspaceStorageQuota(quota interface{}) *Database_SetWorkspaceStorageQuota_Call {
+	return &Database_SetWorkspaceStorageQuota_Call{Call: _e.mock.On("SetWorkspaceStorageQuota", quota)}
+}
+
+func (_c *Database_SetWorkspaceStorageQuota_Call) Run(run func(quota *db.WorkspaceStorageQuota)) *Database_SetWorkspaceStorageQuota_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.WorkspaceStorageQuota))
+	})
+	return _c
+}
+
+func (_c *Database_SetWorkspaceStorageQuota_Call) Return(_a0 error) *Database_SetWorkspaceStorageQuota_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_SetWorkspaceStorageQuota_Call) RunAndReturn(run func(*db.WorkspaceStorageQuota) error) *Database_SetWorkspaceStorageQuota_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReplaceFileChunks provides a mock function with given fields: fileID, chunks
+func (_m *Database) ReplaceFileChunks(fileID uint, chunks []db.FileChunk) error {
+	ret := _m.Called(fileID, chunks)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceFileChunks")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, []db.FileChunk) error); ok {
+		r0 = rf(fileID, chunks)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_ReplaceFileChunks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReplaceFileChunks'
+type Database_ReplaceFileChunks_Call struct {
+	*mock.Call
+}
+
+// ReplaceFileChunks is a helper method to define mock.On call
+//   - fileID uint
+//   - chunks []db.FileChunk
+func (_e *Database_Expecter) ReplaceFileChunks(fileID interface{}, chunks interface{}) *Database_ReplaceFileChunks_Call {
+	return &Database_ReplaceFileChunks_Call{Call: _e.mock.On("ReplaceFileChunks", fileID, chunks)}
+}
+
+func (_c *Database_ReplaceFileChunks_Call) Run(run func(fileID uint, chunks []db.FileChunk)) *Database_ReplaceFileChunks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint), args[1].([]db.FileChunk))
+	})
+	return _c
+}
+
+func (_c *Database_ReplaceFileChunks_Call) Return(_a0 error) *Database_ReplaceFileChunks_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_ReplaceFileChunks_Call) RunAndReturn(run func(uint, []db.FileChunk) error) *Database_ReplaceFileChunks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFileChunks provides a mock function with given fields: fileID
+func (_m *Database) GetFileChunks(fileID uint) ([]db.FileChunk, error) {
+	ret := _m.Called(fileID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFileChunks")
+	}
+
+	var r0 []db.FileChunk
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]db.FileChunk, error)); ok {
+		return rf(fileID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []db.FileChunk); ok {
+		r0 = rf(fileID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.FileChunk)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(fileID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetFileChunks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFileChunks'
+type Database_GetFileChunks_Call struct {
+	*mock.Call
+}
+
+// GetFileChunks is a helper method to define mock.On call
+//   - fileID uint
+func (_e *Database_Expecter) GetFileChunks(fileID interface{}) *Database_GetFileChunks_Call {
+	return &Database_GetFileChunks_Call{Call: _e.mock.On("GetFileChunks", fileID)}
+}
+
+func (_c *Database_GetFileChunks_Call) Run(run func(fileID uint)) *Database_GetFileChunks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint))
+	})
+	return _c
+}
+
+func (_c *Database_GetFileChunks_Call) Return(_a0 []db.FileChunk, _a1 error) *Database_GetFileChunks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetFileChunks_Call) RunAndReturn(run func(uint) ([]db.FileChunk, error)) *Database_GetFileChunks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetFileAssetExtractionStatus provides a mock function with given fields: id, status
+func (_m *Database) SetFileAssetExtractionStatus(id uint, status db.ExtractionStatus) error {
+	ret := _m.Called(id, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFileAssetExtractionStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, db.ExtractionStatus) error); ok {
+		r0 = rf(id, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_SetFileAssetExtractionStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFileAssetExtractionStatus'
+type Database_SetFileAssetExtractionStatus_Call struct {
+	*mock.Call
+}
+
+// SetFileAssetExtractionStatus is a helper method to define mock.On call
+//   - id uint
+//   - status db.ExtractionStatus
+func (_e *Database_Expecter) SetFileAssetExtractionStatus(id interface{}, status interface{}) *Database_SetFileAssetExtractionStatus_Call {
+	return &Database_SetFileAssetExtractionStatus_Call{Call: _e.mock.On("SetFileAssetExtractionStatus", id, status)}
+}
+
+func (_c *Database_SetFileAssetExtractionStatus_Call) Run(run func(id uint, status db.ExtractionStatus)) *Database_SetFileAssetExtractionStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint), args[1].(db.ExtractionStatus))
+	})
+	return _c
+}
+
+func (_c *Database_SetFileAssetExtractionStatus_Call) Return(_a0 error) *Database_SetFileAssetExtractionStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_SetFileAssetExtractionStatus_Call) RunAndReturn(run func(uint, db.ExtractionStatus) error) *Database_SetFileAssetExtractionStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchFileChunks provides a mock function with given fields: workspaceID, query, limit
+func (_m *Database) SearchFileChunks(workspaceID string, query string, limit int) ([]db.FileChunkMatch, error) {
+	ret := _m.Called(workspaceID, query, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchFileChunks")
+	}
+
+	var r0 []db.FileChunkMatch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int) ([]db.FileChunkMatch, error)); ok {
+		return rf(workspaceID, query, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int) []db.FileChunkMatch); ok {
+		r0 = rf(workspaceID, query, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.FileChunkMatch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int) error); ok {
+		r1 = rf(workspaceID, query, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_SearchFileChunks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchFileChunks'
+type Database_SearchFileChunks_Call struct {
+	*mock.Call
+}
+
+// SearchFileChunks is a helper method to define mock.On call
+//   - workspaceID string
+//   - query string
+//   - limit int
+func (_e *Database_Expecter) SearchFileChunks(workspaceID interface{}, query interface{}, limit interface{}) *Database_SearchFileChunks_Call {
+	return &Database_SearchFileChunks_Call{Call: _e.mock.On("SearchFileChunks", workspaceID, query, limit)}
+}
+
+func (_c *Database_SearchFileChunks_Call) Run(run func(workspaceID string, query string, limit int)) *Database_SearchFileChunks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Database_SearchFileChunks_Call) Return(_a0 []db.FileChunkMatch, _a1 error) *Database_SearchFileChunks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SearchFileChunks_Call) RunAndReturn(run func(string, string, int) ([]db.FileChunkMatch, error)) *Database_SearchFileChunks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetKnowledgeSources provides a mock function with given fields: workspaceUUID
+func (_m *Database) GetKnowledgeSources(workspaceUUID string) ([]db.KnowledgeItem, error) {
+	ret := _m.Called(workspaceUUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetKnowledgeSources")
+	}
+
+	var r0 []db.KnowledgeItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.KnowledgeItem, error)); ok {
+		return rf(workspaceUUID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.KnowledgeItem); ok {
+		r0 = rf(workspaceUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.KnowledgeItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetKnowledgeSources_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetKnowledgeSources'
+type Database_GetKnowledgeSources_Call struct {
+	*mock.Call
+}
+
+// GetKnowledgeSources is a helper method to define mock.On call
+//   - workspaceUUID string
+func (_e *Database_Expecter) GetKnowledgeSources(workspaceUUID interface{}) *Database_GetKnowledgeSources_Call {
+	return &Database_GetKnowledgeSources_Call{Call: _e.mock.On("GetKnowledgeSources", workspaceUUID)}
+}
+
+func (_c *Database_GetKnowledgeSources_Call) Run(run func(workspaceUUID string)) *Database_GetKnowledgeSources_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetKnowledgeSources_Call) Return(_a0 []db.KnowledgeItem, _a1 error) *Database_GetKnowledgeSources_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetKnowledgeSources_Call) RunAndReturn(run func(string) ([]db.KnowledgeItem, error)) *Database_GetKnowledgeSources_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SyncKnowledgeItems provides a mock function with given fields: workspaceUUID, items
+func (_m *Database) SyncKnowledgeItems(workspaceUUID string, items []db.KnowledgeItem) error {
+	ret := _m.Called(workspaceUUID, items)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SyncKnowledgeItems")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []db.KnowledgeItem) error); ok {
+		r0 = rf(workspaceUUID, items)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_SyncKnowledgeItems_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SyncKnowledgeItems'
+type Database_SyncKnowledgeItems_Call struct {
+	*mock.Call
+}
+
+// SyncKnowledgeItems is a helper method to define mock.On call
+//   - workspaceUUID string
+//   - items []db.KnowledgeItem
+func (_e *Database_Expecter) SyncKnowledgeItems(workspaceUUID interface{}, items interface{}) *Database_SyncKnowledgeItems_Call {
+	return &Database_SyncKnowledgeItems_Call{Call: _e.mock.On("SyncKnowledgeItems", workspaceUUID, items)}
+}
+
+func (_c *Database_SyncKnowledgeItems_Call) Run(run func(workspaceUUID string, items []db.KnowledgeItem)) *Database_SyncKnowledgeItems_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]db.KnowledgeItem))
+	})
+	return _c
+}
+
+func (_c *Database_SyncKnowledgeItems_Call) Return(_a0 error) *Database_SyncKnowledgeItems_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_SyncKnowledgeItems_Call) RunAndReturn(run func(string, []db.KnowledgeItem) error) *Database_SyncKnowledgeItems_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetKnowledgeItems provides a mock function with given fields: workspaceUUID
+func (_m *Database) GetKnowledgeItems(workspaceUUID string) ([]db.KnowledgeItem, error) {
+	ret := _m.Called(workspaceUUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetKnowledgeItems")
+	}
+
+	var r0 []db.KnowledgeItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.KnowledgeItem, error)); ok {
+		return rf(workspaceUUID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.KnowledgeItem); ok {
+		r0 = rf(workspaceUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.KnowledgeItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetKnowledgeItems_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetKnowledgeItems'
+type Database_GetKnowledgeItems_Call struct {
+	*mock.Call
+}
+
+// GetKnowledgeItems is a helper method to define mock.On call
+//   - workspaceUUID string
+func (_e *Database_Expecter) GetKnowledgeItems(workspaceUUID interface{}) *Database_GetKnowledgeItems_Call {
+	return &Database_GetKnowledgeItems_Call{Call: _e.mock.On("GetKnowledgeItems", workspaceUUID)}
+}
+
+func (_c *Database_GetKnowledgeItems_Call) Run(run func(workspaceUUID string)) *Database_GetKnowledgeItems_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetKnowledgeItems_Call) Return(_a0 []db.KnowledgeItem, _a1 error) *Database_GetKnowledgeItems_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetKnowledgeItems_Call) RunAndReturn(run func(string) ([]db.KnowledgeItem, error)) *Database_GetKnowledgeItems_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetKnowledgeItemByID provides a mock function with given fields: id
+func (_m *Database) GetKnowledgeItemByID(id uint) (*db.KnowledgeItem, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetKnowledgeItemByID")
+	}
+
+	var r0 *db.KnowledgeItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) (*db.KnowledgeItem, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uint) *db.KnowledgeItem); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.KnowledgeItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetKnowledgeItemByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetKnowledgeItemByID'
+type Database_GetKnowledgeItemByID_Call struct {
+	*mock.Call
+}
+
+// GetKnowledgeItemByID is a helper method to define mock.On call
+//   - id uint
+func (_e *Database_Expecter) GetKnowledgeItemByID(id interface{}) *Database_GetKnowledgeItemByID_Call {
+	return &Database_GetKnowledgeItemByID_Call{Call: _e.mock.On("GetKnowledgeItemByID", id)}
+}
+
+func (_c *Database_GetKnowledgeItemByID_Call) Run(run func(id uint)) *Database_GetKnowledgeItemByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint))
+	})
+	return _c
+}
+
+func (_c *Database_GetKnowledgeItemByID_Call) Return(_a0 *db.KnowledgeItem, _a1 error) *Database_GetKnowledgeItemByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetKnowledgeItemByID_Call) RunAndReturn(run func(uint) (*db.KnowledgeItem, error)) *Database_GetKnowledgeItemByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateKnowledgeItemEmbedding provides a mock function with given fields: id, embedding, model
+func (_m *Database) UpdateKnowledgeItemEmbedding(id uint, embedding db.Vector, model string) error {
+	ret := _m.Called(id, embedding, model)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateKnowledgeItemEmbedding")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, db.Vector, string) error); ok {
+		r0 = rf(id, embedding, model)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_UpdateKnowledgeItemEmbedding_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateKnowledgeItemEmbedding'
+type Database_UpdateKnowledgeItemEmbedding_Call struct {
+	*mock.Call
+}
+
+// UpdateKnowledgeItemEmbedding is a helper method to define mock.On call
+//   - id uint
+//   - embedding db.Vector
+//   - model string
+func (_e *Database_Expecter) UpdateKnowledgeItemEmbedding(id interface{}, embedding interface{}, model interface{}) *Database_UpdateKnowledgeItemEmbedding_Call {
+	return &Database_UpdateKnowledgeItemEmbedding_Call{Call: _e.mock.On("UpdateKnowledgeItemEmbedding", id, embedding, model)}
+}
+
+func (_c *Database_UpdateKnowledgeItemEmbedding_Call) Run(run func(id uint, embedding db.Vector, model string)) *Database_UpdateKnowledgeItemEmbedding_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint), args[1].(db.Vector), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Database_UpdateKnowledgeItemEmbedding_Call) Return(_a0 error) *Database_UpdateKnowledgeItemEmbedding_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_UpdateKnowledgeItemEmbedding_Call) RunAndReturn(run func(uint, db.Vector, string) error) *Database_UpdateKnowledgeItemEmbedding_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChatMessageByID provides a mock function with given fields: messageID
+func (_m *Database) GetChatMessageByID(messageID string) (db.ChatMessage, error) {
+	ret := _m.Called(messageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatMessageByID")
+	}
+
+	var r0 db.ChatMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (db.ChatMessage, error)); ok {
+		return rf(messageID)
+	}
+	if rf, ok := ret.Get(0).(func(string) db.ChatMessage); ok {
+		r0 = rf(messageID)
+	} else {
+		r0 = ret.Get(0).(db.ChatMessage)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(messageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetChatMessageByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChatMessageByID'
+type Database_GetChatMessageByID_Call struct {
+	*mock.Call
+}
+
+// GetChatMessageByID is a helper method to define mock.On call
+//   - messageID string
+func (_e *Database_Expecter) GetChatMessageByID(messageID interface{}) *Database_GetChatMessageByID_Call {
+	return &Database_GetChatMessageByID_Call{Call: _e.mock.On("GetChatMessageByID", messageID)}
+}
+
+func (_c *Database_GetChatMessageByID_Call) Run(run func(messageID string)) *Database_GetChatMessageByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatMessageByID_Call) Return(_a0 db.ChatMessage, _a1 error) *Database_GetChatMessageByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatMessageByID_Call) RunAndReturn(run func(string) (db.ChatMessage, error)) *Database_GetChatMessageByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChatStatusByUUID provides a mock function with given fields: id
+func (_m *Database) GetChatStatusByUUID(id uuid.UUID) (db.ChatWorkflowStatus, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatStatusByUUID")
+	}
+
+	var r0 db.ChatWorkflowStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (db.ChatWorkflowStatus, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) db.ChatWorkflowStatus); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(db.ChatWorkflowStatus)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetChatStatusByUUID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChatStatusByUUID'
+type Database_GetChatStatusByUUID_Call struct {
+	*mock.Call
+}
+
+// GetChatStatusByUUID is a helper method to define mock.On call
+//   - id uuid.UUID
+func (_e *Database_Expecter) GetChatStatusByUUID(id interface{}) *Database_GetChatStatusByUUID_Call {
+	return &Database_GetChatStatusByUUID_Call{Call: _e.mock.On("GetChatStatusByUUID", id)}
+}
+
+func (_c *Database_GetChatStatusByUUID_Call) Run(run func(id uuid.UUID)) *Database_GetChatStatusByUUID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatStatusByUUID_Call) Return(_a0 db.ChatWorkflowStatus, _a1 error) *Database_GetChatStatusByUUID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatStatusByUUID_Call) RunAndReturn(run func(uuid.UUID) (db.ChatWorkflowStatus, error)) *Database_GetChatStatusByUUID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateChatShareLink provides a mock function with given fields: link
+func (_m *Database) CreateChatShareLink(link *db.ChatShareLink) (db.ChatShareLink, error) {
+	ret := _m.Called(link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateChatShareLink")
+	}
+
+	var r0 db.ChatShareLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*db.ChatShareLink) (db.ChatShareLink, error)); ok {
+		return rf(link)
+	}
+	if rf, ok := ret.Get(0).(func(*db.ChatShareLink) db.ChatShareLink); ok {
+		r0 = rf(link)
+	} else {
+		r0 = ret.Get(0).(db.ChatShareLink)
+	}
+
+	if rf, ok := ret.Get(1).(func(*db.ChatShareLink) error); ok {
+		r1 = rf(link)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_CreateChatShareLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateChatShareLink'
+type Database_CreateChatShareLink_Call struct {
+	*mock.Call
+}
+
+// CreateChatShareLink is a helper method to define mock.On call
+//   - link *db.ChatShareLink
+func (_e *Database_Expecter) CreateChatShareLink(link interface{}) *Database_CreateChatShareLink_Call {
+	return &Database_CreateChatShareLink_Call{Call: _e.mock.On("CreateChatShareLink", link)}
+}
+
+func (_c *Database_CreateChatShareLink_Call) Run(run func(link *db.ChatShareLink)) *Database_CreateChatShareLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.ChatShareLink))
+	})
+	return _c
+}
+
+func (_c *Database_CreateChatShareLink_Call) Return(_a0 db.ChatShareLink, _a1 error) *Database_CreateChatShareLink_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CreateChatShareLink_Call) RunAndReturn(run func(*db.ChatShareLink) (db.ChatShareLink, error)) *Database_CreateChatShareLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChatShareLinkByTokenHash provides a mock function with given fields: tokenHash
+func (_m *Database) GetChatShareLinkByTokenHash(tokenHash string) (db.ChatShareLink, error) {
+	ret := _m.Called(tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatShareLinkByTokenHash")
+	}
+
+	var r0 db.ChatShareLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (db.ChatShareLink, error)); ok {
+		return rf(tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(string) db.ChatShareLink); ok {
+		r0 = rf(tokenHash)
+	} else {
+		r0 = ret.Get(0).(db.ChatShareLink)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetChatShareLinkByTokenHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChatShareLinkByTokenHash'
+type Database_GetChatShareLinkByTokenHash_Call struct {
+	*mock.Call
+}
+
+// GetChatShareLinkByTokenHash is a helper method to define mock.On call
+//   - tokenHash string
+func (_e *Database_Expecter) GetChatShareLinkByTokenHash(tokenHash interface{}) *Database_GetChatShareLinkByTokenHash_Call {
+	return &Database_GetChatShareLinkByTokenHash_Call{Call: _e.mock.On("GetChatShareLinkByTokenHash", tokenHash)}
+}
+
+func (_c *Database_GetChatShareLinkByTokenHash_Call) Run(run func(tokenHash string)) *Database_GetChatShareLinkByTokenHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatShareLinkByTokenHash_Call) Return(_a0 db.ChatShareLink, _a1 error) *Database_GetChatShareLinkByTokenHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatShareLinkByTokenHash_Call) RunAndReturn(run func(string) (db.ChatShareLink, error)) *Database_GetChatShareLinkByTokenHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChatShareLinks provides a mock function with given fields: chatID
+func (_m *Database) GetChatShareLinks(chatID string) ([]db.ChatShareLink, error) {
+	ret := _m.Called(chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatShareLinks")
+	}
+
+	var r0 []db.ChatShareLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.ChatShareLink, error)); ok {
+		return rf(chatID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.ChatShareLink); ok {
+		r0 = rf(chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ChatShareLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetChatShareLinks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChatShareLinks'
+type Database_GetChatShareLinks_Call struct {
+	*mock.Call
+}
+
+// GetChatShareLinks is a helper method to define mock.On call
+//   - chatID string
+func (_e *Database_Expecter) GetChatShareLinks(chatID interface{}) *Database_GetChatShareLinks_Call {
+	return &Database_GetChatShareLinks_Call{Call: _e.mock.On("GetChatShareLinks", chatID)}
+}
+
+func (_c *Database_GetChatShareLinks_Call) Run(run func(chatID string)) *Database_GetChatShareLinks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatShareLinks_Call) Return(_a0 []db.ChatShareLink, _a1 error) *Database_GetChatShareLinks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatShareLinks_Call) RunAndReturn(run func(string) ([]db.ChatShareLink, error)) *Database_GetChatShareLinks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeChatShareLink provides a mock function with given fields: chatID, linkID
+func (_m *Database) RevokeChatShareLink(chatID string, linkID string) error {
+	ret := _m.Called(chatID, linkID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeChatShareLink")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(chatID, linkID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_RevokeChatShareLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeChatShareLink'
+type Database_RevokeChatShareLink_Call struct {
+	*mock.Call
+}
+
+// RevokeChatShareLink is a helper method to define mock.On call
+//   - chatID string
+//   - linkID string
+func (_e *Database_Expecter) RevokeChatShareLink(chatID interface{}, linkID interface{}) *Database_RevokeChatShareLink_Call {
+	return &Database_RevokeChatShareLink_Call{Call: _e.mock.On("RevokeChatShareLink", chatID, linkID)}
+}
+
+func (_c *Database_RevokeChatShareLink_Call) Run(run func(chatID string, linkID string)) *Database_RevokeChatShareLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_RevokeChatShareLink_Call) Return(_a0 error) *Database_RevokeChatShareLink_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_RevokeChatShareLink_Call) RunAndReturn(run func(string, string) error) *Database_RevokeChatShareLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ClaimActionExecution provides a mock function with given fields: execution
+func (_m *Database) ClaimActionExecution(execution *db.ActionExecution) (db.ActionExecution, error) {
+	ret := _m.Called(execution)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimActionExecution")
+	}
+
+	var r0 db.ActionExecution
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*db.ActionExecution) (db.ActionExecution, error)); ok {
+		return rf(execution)
+	}
+	if rf, ok := ret.Get(0).(func(*db.ActionExecution) db.ActionExecution); ok {
+		r0 = rf(execution)
+	} else {
+		r0 = ret.Get(0).(db.ActionExecution)
+	}
+
+	if rf, ok := ret.Get(1).(func(*db.ActionExecution) error); ok {
+		r1 = rf(execution)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_ClaimActionExecution_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimActionExecution'
+type Database_ClaimActionExecution_Call struct {
+	*mock.Call
+}
+
+// ClaimActionExecution is a helper method to define mock.On call
+//   - execution *db.ActionExecution
+func (_e *Database_Expecter) ClaimActionExecution(execution interface{}) *Database_ClaimActionExecution_Call {
+	return &Database_ClaimActionExecution_Call{Call: _e.mock.On("ClaimActionExecution", execution)}
+}
+
+func (_c *Database_ClaimActionExecution_Call) Run(run func(execution *db.ActionExecution)) *Database_ClaimActionExecution_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.ActionExecution))
+	})
+	return _c
+}
+
+func (_c *Database_ClaimActionExecution_Call) Return(_a0 db.ActionExecution, _a1 error) *Database_ClaimActionExecution_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_ClaimActionExecution_Call) RunAndReturn(run func(*db.ActionExecution) (db.ActionExecution, error)) *Database_ClaimActionExecution_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CompleteActionExecution provides a mock function with given fields: execution
+func (_m *Database) CompleteActionExecution(execution *db.ActionExecution) (db.ActionExecution, error) {
+	ret := _m.Called(execution)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteActionExecution")
+	}
+
+	var r0 db.ActionExecution
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*db.ActionExecution) (db.ActionExecution, error)); ok {
+		return rf(execution)
+	}
+	if rf, ok := ret.Get(0).(func(*db.ActionExecution) db.ActionExecution); ok {
+		r0 = rf(execution)
+	} else {
+		r0 = ret.Get(0).(db.ActionExecution)
+	}
+
+	if rf, ok := ret.Get(1).(func(*db.ActionExecution) error); ok {
+		r1 = rf(execution)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_CompleteActionExecution_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CompleteActionExecution'
+type Database_CompleteActionExecution_Call struct {
+	*mock.Call
+}
+
+// CompleteActionExecution is a helper method to define mock.On call
+//   - execution *db.ActionExecution
+func (_e *Database_Expecter) CompleteActionExecution(execution interface{}) *Database_CompleteActionExecution_Call {
+	return &Database_CompleteActionExecution_Call{Call: _e.mock.On("CompleteActionExecution", execution)}
+}
+
+func (_c *Database_CompleteActionExecution_Call) Run(run func(execution *db.ActionExecution)) *Database_CompleteActionExecution_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.ActionExecution))
+	})
+	return _c
+}
+
+func (_c *Database_CompleteActionExecution_Call) Return(_a0 db.ActionExecution, _a1 error) *Database_CompleteActionExecution_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CompleteActionExecution_Call) RunAndReturn(run func(*db.ActionExecution) (db.ActionExecution, error)) *Database_CompleteActionExecution_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetActionExecutions provides a mock function with given fields: artifactID
+func (_m *Database) GetActionExecutions(artifactID uuid.UUID) ([]db.ActionExecution, error) {
+	ret := _m.Called(artifactID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActionExecutions")
+	}
+
+	var r0 []db.ActionExecution
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]db.ActionExecution, error)); ok {
+		return rf(artifactID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []db.ActionExecution); ok {
+		r0 = rf(artifactID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ActionExecution)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(artifactID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetActionExecutions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetActionExecutions'
+type Database_GetActionExecutions_Call struct {
+	*mock.Call
+}
+
+// GetActionExecutions is a helper method to define mock.On call
+//   - artifactID uuid.UUID
+func (_e *Database_Expecter) GetActionExecutions(artifactID interface{}) *Database_GetActionExecutions_Call {
+	return &Database_GetActionExecutions_Call{Call: _e.mock.On("GetActionExecutions", artifactID)}
+}
+
+func (_c *Database_GetActionExecutions_Call) Run(run func(artifactID uuid.UUID)) *Database_GetActionExecutions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetActionExecutions_Call) Return(_a0 []db.ActionExecution, _a1 error) *Database_GetActionExecutions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetActionExecutions_Call) RunAndReturn(run func(uuid.UUID) ([]db.ActionExecution, error)) *Database_GetActionExecutions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTicketRevisions provides a mock function with given fields: ticketGroup
+func (_m *Database) GetTicketRevisions(ticketGroup uuid.UUID) ([]db.TicketRevision, error) {
+	ret := _m.Called(ticketGroup)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketRevisions")
+	}
+
+	var r0 []db.TicketRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]db.TicketRevision, error)); ok {
+		return rf(ticketGroup)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []db.TicketRevision); ok {
+		r0 = rf(ticketGroup)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.TicketRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(ticketGroup)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetTicketRevisions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTicketRevisions'
+type Database_GetTicketRevisions_Call struct {
+	*mock.Call
+}
+
+// GetTicketRevisions is a helper method to define mock.On call
+//   - ticketGroup uuid.UUID
+func (_e *Database_Expecter) GetTicketRevisions(ticketGroup interface{}) *Database_GetTicketRevisions_Call {
+	return &Database_GetTicketRevisions_Call{Call: _e.mock.On("GetTicketRevisions", ticketGroup)}
+}
+
+func (_c *Database_GetTicketRevisions_Call) Run(run func(ticketGroup uuid.UUID)) *Database_GetTicketRevisions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketRevisions_Call) Return(_a0 []db.TicketRevision, _a1 error) *Database_GetTicketRevisions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketRevisions_Call) RunAndReturn(run func(uuid.UUID) ([]db.TicketRevision, error)) *Database_GetTicketRevisions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTicketRevision provides a mock function with given fields: ticketGroup, version
+func (_m *Database) GetTicketRevision(ticketGroup uuid.UUID, version int) (db.TicketRevision, error) {
+	ret := _m.Called(ticketGroup, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketRevision")
+	}
+
+	var r0 db.TicketRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) (db.TicketRevision, error)); ok {
+		return rf(ticketGroup, version)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) db.TicketRevision); ok {
+		r0 = rf(ticketGroup, version)
+	} else {
+		r0 = ret.Get(0).(db.TicketRevision)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int) error); ok {
+		r1 = rf(ticketGroup, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetTicketRevision_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTicketRevision'
+type Database_GetTicketRevision_Call struct {
+	*mock.Call
+}
+
+// GetTicketRevision is a helper method to define mock.On call
+//   - ticketGroup uuid.UUID
+//   - version int
+func (_e *Database_Expecter) GetTicketRevision(ticketGroup interface{}, version interface{}) *Database_GetTicketRevision_Call {
+	return &Database_GetTicketRevision_Call{Call: _e.mock.On("GetTicketRevision", ticketGroup, version)}
+}
+
+func (_c *Database_GetTicketRevision_Call) Run(run func(ticketGroup uuid.UUID, version int)) *Database_GetTicketRevision_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketRevision_Call) Return(_a0 db.TicketRevision, _a1 error) *Database_GetTicketRevision_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketRevision_Call) RunAndReturn(run func(uuid.UUID, int) (db.TicketRevision, error)) *Database_GetTicketRevision_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTicketWorkflow provides a mock function with given fields: workspaceUuid
+func (_m *Database) GetTicketWorkflow(workspaceUuid string) (db.TicketWorkflow, error) {
+	ret := _m.Called(workspaceUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketWorkflow")
+	}
+
+	var r0 db.TicketWorkflow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (db.TicketWorkflow, error)); ok {
+		return rf(workspaceUuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) db.TicketWorkflow); ok {
+		r0 = rf(workspaceUuid)
+	} else {
+		r0 = ret.Get(0).(db.TicketWorkflow)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetTicketWorkflow_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTicketWorkflow'
+type Database_GetTicketWorkflow_Call struct {
+	*mock.Call
+}
+
+// GetTicketWorkflow is a helper method to define mock.On call
+//   - workspaceUuid string
+func (_e *Database_Expecter) GetTicketWorkflow(workspaceUuid interface{}) *Database_GetTicketWorkflow_Call {
+	return &Database_GetTicketWorkflow_Call{Call: _e.mock.On("GetTicketWorkflow", workspaceUuid)}
+}
+
+func (_c *Database_GetTicketWorkflow_Call) Run(run func(workspaceUuid string)) *Database_GetTicketWorkflow_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketWorkflow_Call) Return(_a0 db.TicketWorkflow, _a1 error) *Database_GetTicketWorkflow_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketWorkflow_Call) RunAndReturn(run func(string) (db.TicketWorkflow, error)) *Database_GetTicketWorkflow_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpsertTicketWorkflow provides a mock function with given fields: workflow
+func (_m *Database) UpsertTicketWorkflow(workflow *db.TicketWorkflow) (db.TicketWorkflow, error) {
+	ret := _m.Called(workflow)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertTicketWorkflow")
+	}
+
+	var r0 db.TicketWorkflow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*db.TicketWorkflow) (db.TicketWorkflow, error)); ok {
+		return rf(workflow)
+	}
+	if rf, ok := ret.Get(0).(func(*db.TicketWorkflow) db.TicketWorkflow); ok {
+		r0 = rf(workflow)
+	} else {
+		r0 = ret.Get(0).(db.TicketWorkflow)
+	}
+
+	if rf, ok := ret.Get(1).(func(*db.TicketWorkflow) error); ok {
+		r1 = rf(workflow)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_UpsertTicketWorkflow_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpsertTicketWorkflow'
+type Database_UpsertTicketWorkflow_Call struct {
+	*mock.Call
+}
+
+// UpsertTicketWorkflow is a helper method to define mock.On call
+//   - workflow *db.TicketWorkflow
+func (_e *Database_Expecter) UpsertTicketWorkflow(workflow interface{}) *Database_UpsertTicketWorkflow_Call {
+	return &Database_UpsertTicketWorkflow_Call{Call: _e.mock.On("UpsertTicketWorkflow", workflow)}
+}
+
+func (_c *Database_UpsertTicketWorkflow_Call) Run(run func(workflow *db.TicketWorkflow)) *Database_UpsertTicketWorkflow_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.TicketWorkflow))
+	})
+	return _c
+}
+
+func (_c *Database_UpsertTicketWorkflow_Call) Return(_a0 db.TicketWorkflow, _a1 error) *Database_UpsertTicketWorkflow_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_UpsertTicketWorkflow_Call) RunAndReturn(run func(*db.TicketWorkflow) (db.TicketWorkflow, error)) *Database_UpsertTicketWorkflow_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWorkspaceTickets provides a mock function with given fields: workspaceUuid
+func (_m *Database) GetWorkspaceTickets(workspaceUuid string) ([]db.Tickets, error) {
+	ret := _m.Called(workspaceUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWorkspaceTickets")
+	}
+
+	var r0 []db.Tickets
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.Tickets, error)); ok {
+		return rf(workspaceUuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.Tickets); ok {
+		r0 = rf(workspaceUuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Tickets)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetWorkspaceTickets_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWorkspaceTickets'
+type Database_GetWorkspaceTickets_Call struct {
+	*mock.Call
+}
+
+// GetWorkspaceTickets is a helper method to define mock.On call
+//   - workspaceUuid string
+func (_e *Database_Expecter) GetWorkspaceTickets(workspaceUuid interface{}) *Database_GetWorkspaceTickets_Call {
+	return &Database_GetWorkspaceTickets_Call{Call: _e.mock.On("GetWorkspaceTickets", workspaceUuid)}
+}
+
+func (_c *Database_GetWorkspaceTickets_Call) Run(run func(workspaceUuid string)) *Database_GetWorkspaceTickets_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetWorkspaceTickets_Call) Return(_a0 []db.Tickets, _a1 error) *Database_GetWorkspaceTickets_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetWorkspaceTickets_Call) RunAndReturn(run func(string) ([]db.Tickets, error)) *Database_GetWorkspaceTickets_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPeopleByMentions provides a mock function with given fields: names
+func (_m *Database) GetPeopleByMentions(names []string) ([]db.Person, error) {
+	ret := _m.Called(names)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPeopleByMentions")
+	}
+
+	var r0 []db.Person
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string) ([]db.Person, error)); ok {
+		return rf(names)
+	}
+	if rf, ok := ret.Get(0).(func([]string) []db.Person); ok {
+		r0 = rf(names)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Person)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(names)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetPeopleByMentions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPeopleByMentions'
+type Database_GetPeopleByMentions_Call struct {
+	*mock.Call
+}
+
+// GetPeopleByMentions is a helper method to define mock.On call
+//   - names []string
+func (_e *Database_Expecter) GetPeopleByMentions(names interface{}) *Database_GetPeopleByMentions_Call {
+	return &Database_GetPeopleByMentions_Call{Call: _e.mock.On("GetPeopleByMentions", names)}
+}
+
+func (_c *Database_GetPeopleByMentions_Call) Run(run func(names []string)) *Database_GetPeopleByMentions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]string))
+	})
+	return _c
+}
+
+func (_c *Database_GetPeopleByMentions_Call) Return(_a0 []db.Person, _a1 error) *Database_GetPeopleByMentions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetPeopleByMentions_Call) RunAndReturn(run func([]string) ([]db.Person, error)) *Database_GetPeopleByMentions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateTicketComment provides a mock function with given fields: comment
+func (_m *Database) CreateTicketComment(comment *db.TicketComment) (db.TicketComment, error) {
+	ret := _m.Called(comment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTicketComment")
+	}
+
+	var r0 db.TicketComment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*db.TicketComment) (db.TicketComment, error)); ok {
+		return rf(comment)
+	}
+	if rf, ok := ret.Get(0).(func(*db.TicketComment) db.TicketComment); ok {
+		r0 = rf(comment)
+	} else {
+		r0 = ret.Get(0).(db.TicketComment)
+	}
+
+	if rf, ok := ret.Get(1).(func(*db.TicketComment) error); ok {
+		r1 = rf(comment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_CreateTicketComment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateTicketComment'
+type Database_CreateTicketComment_Call struct {
+	*mock.Call
+}
+
+// CreateTicketComment is a helper method to define mock.On call
+//   - comment *db.TicketComment
+func (_e *Database_Expecter) CreateTicketComment(comment interface{}) *Database_CreateTicketComment_Call {
+	return &Database_CreateTicketComment_Call{Call: _e.mock.On("CreateTicketComment", comment)}
+}
+
+func (_c *Database_CreateTicketComment_Call) Run(run func(comment *db.TicketComment)) *Database_CreateTicketComment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.TicketComment))
+	})
+	return _c
+}
+
+func (_c *Database_CreateTicketComment_Call) Return(_a0 db.TicketComment, _a1 error) *Database_CreateTicketComment_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CreateTicketComment_Call) RunAndReturn(run func(*db.TicketComment) (db.TicketComment, error)) *Database_CreateTicketComment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTicketComment provides a mock function with given fields: id
+func (_m *Database) GetTicketComment(id uuid.UUID) (db.TicketComment, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketComment")
+	}
+
+	var r0 db.TicketComment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (db.TicketComment, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) db.TicketComment); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(db.TicketComment)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetTicketComment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTicketComment'
+type Database_GetTicketComment_Call struct {
+	*mock.Call
+}
+
+// GetTicketComment is a helper method to define mock.On call
+//   - id uuid.UUID
+func (_e *Database_Expecter) GetTicketComment(id interface{}) *Database_GetTicketComment_Call {
+	return &Database_GetTicketComment_Call{Call: _e.mock.On("GetTicketComment", id)}
+}
+
+func (_c *Database_GetTicketComment_Call) Run(run func(id uuid.UUID)) *Database_GetTicketComment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketComment_Call) Return(_a0 db.TicketComment, _a1 error) *Database_GetTicketComment_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketComment_Call) RunAndReturn(run func(uuid.UUID) (db.TicketComment, error)) *Database_GetTicketComment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTicketComments provides a mock function with given fields: targetType, targetUUID
+func (_m *Database) GetTicketComments(targetType db.CommentTargetType, targetUUID string) ([]db.TicketComment, error) {
+	ret := _m.Called(targetType, targetUUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketComments")
+	}
+
+	var r0 []db.TicketComment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(db.CommentTargetType, string) ([]db.TicketComment, error)); ok {
+		return rf(targetType, targetUUID)
+	}
+	if rf, ok := ret.Get(0).(func(db.CommentTargetType, string) []db.TicketComment); ok {
+		r0 = rf(targetType, targetUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.TicketComment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(db.CommentTargetType, string) error); ok {
+		r1 = rf(targetType, targetUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetTicketComments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTicketComments'
+type Database_GetTicketComments_Call struct {
+	*mock.Call
+}
+
+// GetTicketComments is a helper method to define mock.On call
+//   - targetType db.CommentTargetType
+//   - targetUUID string
+func (_e *Database_Expecter) GetTicketComments(targetType interface{}, targetUUID interface{}) *Database_GetTicketComments_Call {
+	return &Database_GetTicketComments_Call{Call: _e.mock.On("GetTicketComments", targetType, targetUUID)}
+}
+
+func (_c *Database_GetTicketComments_Call) Run(run func(targetType db.CommentTargetType, targetUUID string)) *Database_GetTicketComments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.CommentTargetType), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketComments_Call) Return(_a0 []db.TicketComment, _a1 error) *Database_GetTicketComments_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketComments_Call) RunAndReturn(run func(db.CommentTargetType, string) ([]db.TicketComment, error)) *Database_GetTicketComments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateTicketCommentBody provides a mock function with given fields: id, body, mentions
+func (_m *Database) UpdateTicketCommentBody(id uuid.UUID, body string, mentions []string) (db.TicketComment, error) {
+	ret := _m.Called(id, body, mentions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTicketCommentBody")
+	}
+
+	var r0 db.TicketComment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string, []string) (db.TicketComment, error)); ok {
+		return rf(id, body, mentions)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string, []string) db.TicketComment); ok {
+		r0 = rf(id, body, mentions)
+	} else {
+		r0 = ret.Get(0).(db.TicketComment)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string, []string) error); ok {
+		r1 = rf(id, body, mentions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_UpdateTicketCommentBody_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateTicketCommentBody'
+type Database_UpdateTicketCommentBody_Call struct {
+	*mock.Call
+}
+
+// UpdateTicketCommentBody is a helper method to define mock.On call
+//   - id uuid.UUID
+//   - body string
+//   - mentions []string
+func (_e *Database_Expecter) UpdateTicketCommentBody(id interface{}, body interface{}, mentions interface{}) *Database_UpdateTicketCommentBody_Call {
+	return &Database_UpdateTicketCommentBody_Call{Call: _e.mock.On("UpdateTicketCommentBody", id, body, mentions)}
+}
+
+func (_c *Database_UpdateTicketCommentBody_Call) Run(run func(id uuid.UUID, body string, mentions []string)) *Database_UpdateTicketCommentBody_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(string), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *Database_UpdateTicketCommentBody_Call) Return(_a0 db.TicketComment, _a1 error) *Database_UpdateTicketCommentBody_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_UpdateTicketCommentBody_Call) RunAndReturn(run func(uuid.UUID, string, []string) (db.TicketComment, error)) *Database_UpdateTicketCommentBody_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResolveTicketCommentThread provides a mock function with given fields: id, resolved, resolvedBy
+func (_m *Database) ResolveTicketCommentThread(id uuid.UUID, resolved bool, resolvedBy string) (db.TicketComment, error) {
+	ret := _m.Called(id, resolved, resolvedBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveTicketCommentThread")
+	}
+
+	var r0 db.TicketComment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, bool, string) (db.TicketComment, error)); ok {
+		return rf(id, resolved, resolvedBy)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, bool, string) db.TicketComment); ok {
+		r0 = rf(id, resolved, resolvedBy)
+	} else {
+		r0 = ret.Get(0).(db.TicketComment)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, bool, string) error); ok {
+		r1 = rf(id, resolved, resolvedBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_ResolveTicketCommentThread_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResolveTicketCommentThread'
+type Database_ResolveTicketCommentThread_Call struct {
+	*mock.Call
+}
+
+// ResolveTicketCommentThread is a helper method to define mock.On call
+//   - id uuid.UUID
+//   - resolved bool
+//   - resolvedBy string
+func (_e *Database_Expecter) ResolveTicketCommentThread(id interface{}, resolved interface{}, resolvedBy interface{}) *Database_ResolveTicketCommentThread_Call {
+	return &Database_ResolveTicketCommentThread_Call{Call: _e.mock.On("ResolveTicketCommentThread", id, resolved, resolvedBy)}
+}
+
+func (_c *Database_ResolveTicketCommentThread_Call) Run(run func(id uuid.UUID, resolved bool, resolvedBy string)) *Database_ResolveTicketCommentThread_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(bool), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Database_ResolveTicketCommentThread_Call) Return(_a0 db.TicketComment, _a1 error) *Database_ResolveTicketCommentThread_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_ResolveTicketCommentThread_Call) RunAndReturn(run func(uuid.UUID, bool, string) (db.TicketComment, error)) *Database_ResolveTicketCommentThread_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteTicketComment provides a mock function with given fields: id
+func (_m *Database) DeleteTicketComment(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteTicketComment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_DeleteTicketComment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteTicketComment'
+type Database_DeleteTicketComment_Call struct {
+	*mock.Call
+}
+
+// DeleteTicketComment is a helper method to define mock.On call
+//   - id uuid.UUID
+func (_e *Database_Expecter) DeleteTicketComment(id interface{}) *Database_DeleteTicketComment_Call {
+	return &Database_DeleteTicketComment_Call{Call: _e.mock.On("DeleteTicketComment", id)}
+}
+
+func (_c *Database_DeleteTicketComment_Call) Run(run func(id uuid.UUID)) *Database_DeleteTicketComment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_DeleteTicketComment_Call) Return(_a0 error) *Database_DeleteTicketComment_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_DeleteTicketComment_Call) RunAndReturn(run func(uuid.UUID) error) *Database_DeleteTicketComment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTicketCommentCounts provides a mock function with given fields: targetType, targetUUIDs
+func (_m *Database) GetTicketCommentCounts(targetType db.CommentTargetType, targetUUIDs []string) (map[string]db.TicketCommentCount, error) {
+	ret := _m.Called(targetType, targetUUIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketCommentCounts")
+	}
+
+	var r0 map[string]db.TicketCommentCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(db.CommentTargetType, []string) (map[string]db.TicketCommentCount, error)); ok {
+		return rf(targetType, targetUUIDs)
+	}
+	if rf, ok := ret.Get(0).(func(db.CommentTargetType, []string) map[string]db.TicketCommentCount); ok {
+		r0 = rf(targetType, targetUUIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]db.TicketCommentCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(db.CommentTargetType, []string) error); ok {
+		r1 = rf(targetType, targetUUIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetTicketCommentCounts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTicketCommentCounts'
+type Database_GetTicketCommentCounts_Call struct {
+	*mock.Call
+}
+
+// GetTicketCommentCounts is a helper method to define mock.On call
+//   - targetType db.CommentTargetType
+//   - targetUUIDs []string
+func (_e *Database_Expecter) GetTicketCommentCounts(targetType interface{}, targetUUIDs interface{}) *Database_GetTicketCommentCounts_Call {
+	return &Database_GetTicketCommentCounts_Call{Call: _e.mock.On("GetTicketCommentCounts", targetType, targetUUIDs)}
+}
+
+func (_c *Database_GetTicketCommentCounts_Call) Run(run func(targetType db.CommentTargetType, targetUUIDs []string)) *Database_GetTicketCommentCounts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.CommentTargetType), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketCommentCounts_Call) Return(_a0 map[string]db.TicketCommentCount, _a1 error) *Database_GetTicketCommentCounts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketCommentCounts_Call) RunAndReturn(run func(db.CommentTargetType, []string) (map[string]db.TicketCommentCount, error)) *Database_GetTicketCommentCounts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InTransaction provides a mock function with given fields: fn
+func (_m *Database) InTransaction(fn func(db.Database) error) error {
+	ret := _m.Called(fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InTransaction")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(func(db.Database) error) error); ok {
+		r0 = rf(fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_InTransaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InTransaction'
+type Database_InTransaction_Call struct {
+	*mock.Call
+}
+
+// InTransaction is a helper method to define mock.On call
+//   - fn func(db.Database) error
+func (_e *Database_Expecter) InTransaction(fn interface{}) *Database_InTransaction_Call {
+	return &Database_InTransaction_Call{Call: _e.mock.On("InTransaction", fn)}
+}
+
+func (_c *Database_InTransaction_Call) Run(run func(fn func(db.Database) error)) *Database_InTransaction_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(func(db.Database) error))
+	})
+	return _c
+}
+
+func (_c *Database_InTransaction_Call) Return(_a0 error) *Database_InTransaction_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_InTransaction_Call) RunAndReturn(run func(func(db.Database) error) error) *Database_InTransaction_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWorkspaceTemplates provides a mock function with given fields: workspaceUuid, kind
+func (_m *Database) GetWorkspaceTemplates(workspaceUuid string, kind db.TemplateKind) ([]db.WorkspaceTemplate, error) {
+	ret := _m.Called(workspaceUuid, kind)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWorkspaceTemplates")
+	}
+
+	var r0 []db.WorkspaceTemplate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, db.TemplateKind) ([]db.WorkspaceTemplate, error)); ok {
+		return rf(workspaceUuid, kind)
+	}
+	if rf, ok := ret.Get(0).(func(string, db.TemplateKind) []db.WorkspaceTemplate); ok {
+		r0 = rf(workspaceUuid, kind)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.WorkspaceTemplate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, db.TemplateKind) error); ok {
+		r1 = rf(workspaceUuid, kind)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetWorkspaceTemplates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWorkspaceTemplates'
+type Database_GetWorkspaceTemplates_Call struct {
+	*mock.Call
+}
+
+// GetWorkspaceTemplates is a helper method to define mock.On call
+//   - workspaceUuid string
+//   - kind db.TemplateKind
+func (_e *Database_Expecter) GetWorkspaceTemplates(workspaceUuid interface{}, kind interface{}) *Database_GetWorkspaceTemplates_Call {
+	return &Database_GetWorkspaceTemplates_Call{Call: _e.mock.On("GetWorkspaceTemplates", workspaceUuid, kind)}
+}
+
+func (_c *Database_GetWorkspaceTemplates_Call) Run(run func(workspaceUuid string, kind db.TemplateKind)) *Database_GetWorkspaceTemplates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(db.TemplateKind))
+	})
+	return _c
+}
+
+func (_c *Database_GetWorkspaceTemplates_Call) Return(_a0 []db.WorkspaceTemplate, _a1 error) *Database_GetWorkspaceTemplates_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetWorkspaceTemplates_Call) RunAndReturn(run func(string, db.TemplateKind) ([]db.WorkspaceTemplate, error)) *Database_GetWorkspaceTemplates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWorkspaceTemplate provides a mock function with given fields: id
+func (_m *Database) GetWorkspaceTemplate(id uuid.UUID) (db.WorkspaceTemplate, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWorkspaceTemplate")
+	}
+
+	var r0 db.WorkspaceTemplate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (db.WorkspaceTemplate, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) db.WorkspaceTemplate); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(db.WorkspaceTemplate)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetWorkspaceTemplate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWorkspaceTemplate'
+type Database_GetWorkspaceTemplate_Call struct {
+	*mock.Call
+}
+
+// GetWorkspaceTemplate is a helper method to define mock.On call
+//   - id uuid.UUID
+func (_e *Database_Expecter) GetWorkspaceTemplate(id interface{}) *Database_GetWorkspaceTemplate_Call {
+	return &Database_GetWorkspaceTemplate_Call{Call: _e.mock.On("GetWorkspaceTemplate", id)}
+}
+
+func (_c *Database_GetWorkspaceTemplate_Call) Run(run func(id uuid.UUID)) *Database_GetWorkspaceTemplate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetWorkspaceTemplate_Call) Return(_a0 db.WorkspaceTemplate, _a1 error) *Database_GetWorkspaceTemplate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetWorkspaceTemplate_Call) RunAndReturn(run func(uuid.UUID) (db.WorkspaceTemplate, error)) *Database_GetWorkspaceTemplate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDefaultWorkspaceTemplate provides a mock function with given fields: workspaceUuid, kind
+func (_m *Database) GetDefaultWorkspaceTemplate(workspaceUuid string, kind db.TemplateKind) (db.WorkspaceTemplate, error) {
+	ret := _m.Called(workspaceUuid, kind)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDefaultWorkspaceTemplate")
+	}
+
+	var r0 db.WorkspaceTemplate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, db.TemplateKind) (db.WorkspaceTemplate, error)); ok {
+		return rf(workspaceUuid, kind)
+	}
+	if rf, ok := ret.Get(0).(func(string, db.TemplateKind) db.WorkspaceTemplate); ok {
+		r0 = rf(workspaceUuid, kind)
+	} else {
+		r0 = ret.Get(0).(db.WorkspaceTemplate)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, db.TemplateKind) error); ok {
+		r1 = rf(workspaceUuid, kind)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetDefaultWorkspaceTemplate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDefaultWorkspaceTemplate'
+type Database_GetDefaultWorkspaceTemplate_Call struct {
+	*mock.Call
+}
+
+// GetDefaultWorkspaceTemplate is a helper method to define mock.On call
+//   - workspaceUuid string
+//   - kind db.TemplateKind
+func (_e *Database_Expecter) GetDefaultWorkspaceTemplate(workspaceUuid interface{}, kind interface{}) *Database_GetDefaultWorkspaceTemplate_Call {
+	return &Database_GetDefaultWorkspaceTemplate_Call{Call: _e.mock.On("GetDefaultWorkspaceTemplate", workspaceUuid, kind)}
+}
+
+func (_c *Database_GetDefaultWorkspaceTemplate_Call) Run(run func(workspaceUuid string, kind db.TemplateKind)) *Database_GetDefaultWorkspaceTemplate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(db.TemplateKind))
+	})
+	return _c
+}
+
+func (_c *Database_GetDefaultWorkspaceTemplate_Call) Return(_a0 db.WorkspaceTemplate, _a1 error) *Database_GetDefaultWorkspaceTemplate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetDefaultWorkspaceTemplate_Call) RunAndReturn(run func(string, db.TemplateKind) (db.WorkspaceTemplate, error)) *Database_GetDefaultWorkspaceTemplate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateOrEditWorkspaceTemplate provides a mock function with given fields: template
+func (_m *Database) CreateOrEditWorkspaceTemplate(template *db.WorkspaceTemplate) (db.WorkspaceTemplate, error) {
+	ret := _m.Called(template)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOrEditWorkspaceTemplate")
+	}
+
+	var r0 db.WorkspaceTemplate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*db.WorkspaceTemplate) (db.WorkspaceTemplate, error)); ok {
+		return rf(template)
+	}
+	if rf, ok := ret.Get(0).(func(*db.WorkspaceTemplate) db.WorkspaceTemplate); ok {
+		r0 = rf(template)
+	} else {
+		r0 = ret.Get(0).(db.WorkspaceTemplate)
+	}
+
+	if rf, ok := ret.Get(1).(func(*db.WorkspaceTemplate) error); ok {
+		r1 = rf(template)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_CreateOrEditWorkspaceTemplate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateOrEditWorkspaceTemplate'
+type Database_CreateOrEditWorkspaceTemplate_Call struct {
+	*mock.Call
+}
+
+// CreateOrEditWorkspaceTemplate is a helper method to define mock.On call
+//   - template *db.WorkspaceTemplate
+func (_e *Database_Expecter) CreateOrEditWorkspaceTemplate(template interface{}) *Database_CreateOrEditWorkspaceTemplate_Call {
+	return &Database_CreateOrEditWorkspaceTemplate_Call{Call: _e.mock.On("CreateOrEditWorkspaceTemplate", template)}
+}
+
+func (_c *Database_CreateOrEditWorkspaceTemplate_Call) Run(run func(template *db.WorkspaceTemplate)) *Database_CreateOrEditWorkspaceTemplate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.WorkspaceTemplate))
+	})
+	return _c
+}
+
+func (_c *Database_CreateOrEditWorkspaceTemplate_Call) Return(_a0 db.WorkspaceTemplate, _a1 error) *Database_CreateOrEditWorkspaceTemplate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CreateOrEditWorkspaceTemplate_Call) RunAndReturn(run func(*db.WorkspaceTemplate) (db.WorkspaceTemplate, error)) *Database_CreateOrEditWorkspaceTemplate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteWorkspaceTemplate provides a mock function with given fields: id
+func (_m *Database) DeleteWorkspaceTemplate(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWorkspaceTemplate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_DeleteWorkspaceTemplate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteWorkspaceTemplate'
+type Database_DeleteWorkspaceTemplate_Call struct {
+	*mock.Call
+}
+
+// DeleteWorkspaceTemplate is a helper method to define mock.On call
+//   - id uuid.UUID
+func (_e *Database_Expecter) DeleteWorkspaceTemplate(id interface{}) *Database_DeleteWorkspaceTemplate_Call {
+	return &Database_DeleteWorkspaceTemplate_Call{Call: _e.mock.On("DeleteWorkspaceTemplate", id)}
+}
+
+func (_c *Database_DeleteWorkspaceTemplate_Call) Run(run func(id uuid.UUID)) *Database_DeleteWorkspaceTemplate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_DeleteWorkspaceTemplate_Call) Return(_a0 error) *Database_DeleteWorkspaceTemplate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_DeleteWorkspaceTemplate_Call) RunAndReturn(run func(uuid.UUID) error) *Database_DeleteWorkspaceTemplate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateTicketGithubIssue provides a mock function with given fields: link
+func (_m *Database) CreateTicketGithubIssue(link *db.TicketGithubIssue) (db.TicketGithubIssue, error) {
+	ret := _m.Called(link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTicketGithubIssue")
+	}
+
+	var r0 db.TicketGithubIssue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*db.TicketGithubIssue) (db.TicketGithubIssue, error)); ok {
+		return rf(link)
+	}
+	if rf, ok := ret.Get(0).(func(*db.TicketGithubIssue) db.TicketGithubIssue); ok {
+		r0 = rf(link)
+	} else {
+		r0 = ret.Get(0).(db.TicketGithubIssue)
+	}
+
+	if rf, ok := ret.Get(1).(func(*db.TicketGithubIssue) error); ok {
+		r1 = rf(link)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_CreateTicketGithubIssue_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateTicketGithubIssue'
+type Database_CreateTicketGithubIssue_Call struct {
+	*mock.Call
+}
+
+// CreateTicketGithubIssue is a helper method to define mock.On call
+//   - link *db.TicketGithubIssue
+func (_e *Database_Expecter) CreateTicketGithubIssue(link interface{}) *Database_CreateTicketGithubIssue_Call {
+	return &Database_CreateTicketGithubIssue_Call{Call: _e.mock.On("CreateTicketGithubIssue", link)}
+}
+
+func (_c *Database_CreateTicketGithubIssue_Call) Run(run func(link *db.TicketGithubIssue)) *Database_CreateTicketGithubIssue_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.TicketGithubIssue))
+	})
+	return _c
+}
+
+func (_c *Database_CreateTicketGithubIssue_Call) Return(_a0 db.TicketGithubIssue, _a1 error) *Database_CreateTicketGithubIssue_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CreateTicketGithubIssue_Call) RunAndReturn(run func(*db.TicketGithubIssue) (db.TicketGithubIssue, error)) *Database_CreateTicketGithubIssue_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTicketGithubIssuesByRepo provides a mock function with given fields: owner, repo
+func (_m *Database) GetTicketGithubIssuesByRepo(owner string, repo string) ([]db.TicketGithubIssue, error) {
+	ret := _m.Called(owner, repo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketGithubIssuesByRepo")
+	}
+
+	var r0 []db.TicketGithubIssue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]db.TicketGithubIssue, error)); ok {
+		return rf(owner, repo)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []db.TicketGithubIssue); ok {
+		r0 = rf(owner, repo)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.TicketGithubIssue)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(owner, repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetTicketGithubIssuesByRepo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTicketGithubIssuesByRepo'
+type Database_GetTicketGithubIssuesByRepo_Call struct {
+	*mock.Call
+}
+
+// GetTicketGithubIssuesByRepo is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+func (_e *Database_Expecter) GetTicketGithubIssuesByRepo(owner interface{}, repo interface{}) *Database_GetTicketGithubIssuesByRepo_Call {
+	return &Database_GetTicketGithubIssuesByRepo_Call{Call: _e.mock.On("GetTicketGithubIssuesByRepo", owner, repo)}
+}
+
+func (_c *Database_GetTicketGithubIssuesByRepo_Call) Run(run func(owner string, repo string)) *Database_GetTicketGithubIssuesByRepo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketGithubIssuesByRepo_Call) Return(_a0 []db.TicketGithubIssue, _a1 error) *Database_GetTicketGithubIssuesByRepo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketGithubIssuesByRepo_Call) RunAndReturn(run func(string, string) ([]db.TicketGithubIssue, error)) *Database_GetTicketGithubIssuesByRepo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAllTicketGithubIssues provides a mock function with no fields
+func (_m *Database) GetAllTicketGithubIssues() ([]db.TicketGithubIssue, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllTicketGithubIssues")
+	}
+
+	var r0 []db.TicketGithubIssue
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]db.TicketGithubIssue, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []db.TicketGithubIssue); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.TicketGithubIssue)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetAllTicketGithubIssues_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllTicketGithubIssues'
+type Database_GetAllTicketGithubIssues_Call struct {
+	*mock.Call
+}
+
+// GetAllTicketGithubIssues is a helper method to define mock.On call
+func (_e *Database_Expecter) GetAllTicketGithubIssues() *Database_GetAllTicketGithubIssues_Call {
+	return &Database_GetAllTicketGithubIssues_Call{Call: _e.mock.On("GetAllTicketGithubIssues")}
+}
+
+func (_c *Database_GetAllTicketGithubIssues_Call) Run(run func()) *Database_GetAllTicketGithubIssues_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Database_GetAllTicketGithubIssues_Call) Return(_a0 []db.TicketGithubIssue, _a1 error) *Database_GetAllTicketGithubIssues_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetAllTicketGithubIssues_Call) RunAndReturn(run func() ([]db.TicketGithubIssue, error)) *Database_GetAllTicketGithubIssues_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateTicketGithubIssue provides a mock function with given fields: link
+func (_m *Database) UpdateTicketGithubIssue(link *db.TicketGithubIssue) error {
+	ret := _m.Called(link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTicketGithubIssue")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*db.TicketGithubIssue) error); ok {
+		r0 = rf(link)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_UpdateTicketGithubIssue_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateTicketGithubIssue'
+type Database_UpdateTicketGithubIssue_Call struct {
+	*mock.Call
+}
+
+// UpdateTicketGithubIssue is a helper method to define mock.On call
+//   - link *db.TicketGithubIssue
+func (_e *Database_Expecter) UpdateTicketGithubIssue(link interface{}) *Database_UpdateTicketGithubIssue_Call {
+	return &Database_UpdateTicketGithubIssue_Call{Call: _e.mock.On("UpdateTicketGithubIssue", link)}
+}
+
+func (_c *Database_UpdateTicketGithubIssue_Call) Run(run func(link *db.TicketGithubIssue)) *Database_UpdateTicketGithubIssue_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.TicketGithubIssue))
+	})
+	return _c
+}
+
+func (_c *Database_UpdateTicketGithubIssue_Call) Return(_a0 error) *Database_UpdateTicketGithubIssue_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_UpdateTicketGithubIssue_Call) RunAndReturn(run func(*db.TicketGithubIssue) error) *Database_UpdateTicketGithubIssue_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteTicketGithubIssue provides a mock function with given fields: id
+func (_m *Database) DeleteTicketGithubIssue(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteTicketGithubIssue")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_DeleteTicketGithubIssue_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteTicketGithubIssue'
+type Database_DeleteTicketGithubIssue_Call struct {
+	*mock.Call
+}
+
+// DeleteTicketGithubIssue is a helper method to define mock.On call
+//   - id uuid.UUID
+func (_e *Database_Expecter) DeleteTicketGithubIssue(id interface{}) *Database_DeleteTicketGithubIssue_Call {
+	return &Database_DeleteTicketGithubIssue_Call{Call: _e.mock.On("DeleteTicketGithubIssue", id)}
+}
+
+func (_c *Database_DeleteTicketGithubIssue_Call) Run(run func(id uuid.UUID)) *Database_DeleteTicketGithubIssue_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_DeleteTicketGithubIssue_Call) Return(_a0 error) *Database_DeleteTicketGithubIssue_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_DeleteTicketGithubIssue_Call) RunAndReturn(run func(uuid.UUID) error) *Database_DeleteTicketGithubIssue_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateBountyPullRequest provides a mock function with given fields: pull
+func (_m *Database) CreateBountyPullRequest(pull *db.BountyPullRequest) (db.BountyPullRequest, error) {
+	ret := _m.Called(pull)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateBountyPullRequest")
+	}
+
+	var r0 db.BountyPullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*db.BountyPullRequest) (db.BountyPullRequest, error)); ok {
+		return rf(pull)
+	}
+	if rf, ok := ret.Get(0).(func(*db.BountyPullRequest) db.BountyPullRequest); ok {
+		r0 = rf(pull)
+	} else {
+		r0 = ret.Get(0).(db.BountyPullRequest)
+	}
+
+	if rf, ok := ret.Get(1).(func(*db.BountyPullRequest) error); ok {
+		r1 = rf(pull)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_CreateBountyPullRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateBountyPullRequest'
+type Database_CreateBountyPullRequest_Call struct {
+	*mock.Call
+}
+
+// CreateBountyPullRequest is a helper method to define mock.On call
+//   - pull *db.BountyPullRequest
+func (_e *Database_Expecter) CreateBountyPullRequest(pull interface{}) *Database_CreateBountyPullRequest_Call {
+	return &Database_CreateBountyPullRequest_Call{Call: _e.mock.On("CreateBountyPullRequest", pull)}
+}
+
+func (_c *Database_CreateBountyPullRequest_Call) Run(run func(pull *db.BountyPullRequest)) *Database_CreateBountyPullRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.BountyPullRequest))
+	})
+	return _c
+}
+
+func (_c *Database_CreateBountyPullRequest_Call) Return(_a0 db.BountyPullRequest, _a1 error) *Database_CreateBountyPullRequest_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CreateBountyPullRequest_Call) RunAndReturn(run func(*db.BountyPullRequest) (db.BountyPullRequest, error)) *Database_CreateBountyPullRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBountyPullRequest provides a mock function with given fields: id
+func (_m *Database) GetBountyPullRequest(id uuid.UUID) (db.BountyPullRequest, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBountyPullRequest")
+	}
+
+	var r0 db.BountyPullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (db.BountyPullRequest, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) db.BountyPullRequest); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(db.BountyPullRequest)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetBountyPullRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBountyPullRequest'
+type Database_GetBountyPullRequest_Call struct {
+	*mock.Call
+}
+
+// GetBountyPullRequest is a helper method to define mock.On call
+//   - id uuid.UUID
+func (_e *Database_Expecter) GetBountyPullRequest(id interface{}) *Database_GetBountyPullRequest_Call {
+	return &Database_GetBountyPullRequest_Call{Call: _e.mock.On("GetBountyPullRequest", id)}
+}
+
+func (_c *Database_GetBountyPullRequest_Call) Run(run func(id uuid.UUID)) *Database_GetBountyPullRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetBountyPullRequest_Call) Return(_a0 db.BountyPullRequest, _a1 error) *Database_GetBountyPullRequest_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetBountyPullRequest_Call) RunAndReturn(run func(uuid.UUID) (db.BountyPullRequest, error)) *Database_GetBountyPullRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBountyPullRequests provides a mock function with given fields: bountyID
+func (_m *Database) GetBountyPullRequests(bountyID uint) ([]db.BountyPullRequest, error) {
+	ret := _m.Called(bountyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBountyPullRequests")
+	}
+
+	var r0 []db.BountyPullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]db.BountyPullRequest, error)); ok {
+		return rf(bountyID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []db.BountyPullRequest); ok {
+		r0 = rf(bountyID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.BountyPullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(bountyID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetBountyPullRequests_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBountyPullRequests'
+type Database_GetBountyPullRequests_Call struct {
+	*mock.Call
+}
+
+// GetBountyPullRequests is a helper method to define mock.On call
+//   - bountyID uint
+func (_e *Database_Expecter) GetBountyPullRequests(bountyID interface{}) *Database_GetBountyPullRequests_Call {
+	return &Database_GetBountyPullRequests_Call{Call: _e.mock.On("GetBountyPullRequests", bountyID)}
+}
+
+func (_c *Database_GetBountyPullRequests_Call) Run(run func(bountyID uint)) *Database_GetBountyPullRequests_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint))
+	})
+	return _c
+}
+
+func (_c *Database_GetBountyPullRequests_Call) Return(_a0 []db.BountyPullRequest, _a1 error) *Database_GetBountyPullRequests_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetBountyPullRequests_Call) RunAndReturn(run func(uint) ([]db.BountyPullRequest, error)) *Database_GetBountyPullRequests_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBountyPullRequestsByPull provides a mock function with given fields: owner, repo, number
+func (_m *Database) GetBountyPullRequestsByPull(owner string, repo string, number int) ([]db.BountyPullRequest, error) {
+	ret := _m.Called(owner, repo, number)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBountyPullRequestsByPull")
+	}
+
+	var r0 []db.BountyPullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int) ([]db.BountyPullRequest, error)); ok {
+		return rf(owner, repo, number)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int) []db.BountyPullRequest); ok {
+		r0 = rf(owner, repo, number)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.BountyPullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int) error); ok {
+		r1 = rf(owner, repo, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetBountyPullRequestsByPull_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBountyPullRequestsByPull'
+type Database_GetBountyPullRequestsByPull_Call struct {
+	*mock.Call
+}
+
+// GetBountyPullRequestsByPull is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+//   - number int
+func (_e *Database_Expecter) GetBountyPullRequestsByPull(owner interface{}, repo interface{}, number interface{}) *Database_GetBountyPullRequestsByPull_Call {
+	return &Database_GetBountyPullRequestsByPull_Call{Call: _e.mock.On("GetBountyPullRequestsByPull", owner, repo, number)}
+}
+
+func (_c *Database_GetBountyPullRequestsByPull_Call) Run(run func(owner string, repo string, number int)) *Database_GetBountyPullRequestsByPull_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Database_GetBountyPullRequestsByPull_Call) Return(_a0 []db.BountyPullRequest, _a1 error) *Database_GetBountyPullRequestsByPull_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetBountyPullRequestsByPull_Call) RunAndReturn(run func(string, string, int) ([]db.BountyPullRequest, error)) *Database_GetBountyPullRequestsByPull_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetQueuedBountyPullRequests provides a mock function with no fields
+func (_m *Database) GetQueuedBountyPullRequests() ([]db.BountyPullRequest, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQueuedBountyPullRequests")
+	}
+
+	var r0 []db.BountyPullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]db.BountyPullRequest, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []db.BountyPullRequest); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.BountyPullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetQueuedBountyPullRequests_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetQueuedBountyPullRequests'
+type Database_GetQueuedBountyPullRequests_Call struct {
+	*mock.Call
+}
+
+// GetQueuedBountyPullRequests is a helper method to define mock.On call
+func (_e *Database_Expecter) GetQueuedBountyPullRequests() *Database_GetQueuedBountyPullRequests_Call {
+	return &Database_GetQueuedBountyPullRequests_Call{Call: _e.mock.On("GetQueuedBountyPullRequests")}
+}
+
+func (_c *Database_GetQueuedBountyPullRequests_Call) Run(run func()) *Database_GetQueuedBountyPullRequests_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Database_GetQueuedBountyPullRequests_Call) Return(_a0 []db.BountyPullRequest, _a1 error) *Database_GetQueuedBountyPullRequests_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetQueuedBountyPullRequests_Call) RunAndReturn(run func() ([]db.BountyPullRequest, error)) *Database_GetQueuedBountyPullRequests_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateBountyPullRequest provides a mock function with given fields: pull
+func (_m *Database) UpdateBountyPullRequest(pull *db.BountyPullRequest) error {
+	ret := _m.Called(pull)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateBountyPullRequest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*db.BountyPullRequest) error); ok {
+		r0 = rf(pull)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_UpdateBountyPullRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateBountyPullRequest'
+type Database_UpdateBountyPullRequest_Call struct {
+	*mock.Call
+}
+
+// UpdateBountyPullRequest is a helper method to define mock.On call
+//   - pull *db.BountyPullRequest
+func (_e *Database_Expecter) UpdateBountyPullRequest(pull interface{}) *Database_UpdateBountyPullRequest_Call {
+	return &Database_UpdateBountyPullRequest_Call{Call: _e.mock.On("UpdateBountyPullRequest", pull)}
+}
+
+func (_c *Database_UpdateBountyPullRequest_Call) Run(run func(pull *db.BountyPullRequest)) *Database_UpdateBountyPullRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.BountyPullRequest))
+	})
+	return _c
+}
+
+func (_c *Database_UpdateBountyPullRequest_Call) Return(_a0 error) *Database_UpdateBountyPullRequest_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_UpdateBountyPullRequest_Call) RunAndReturn(run func(*db.BountyPullRequest) error) *Database_UpdateBountyPullRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteBountyPullRequest provides a mock function with given fields: id
+func (_m *Database) DeleteBountyPullRequest(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBountyPullRequest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_DeleteBountyPullRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteBountyPullRequest'
+type Database_DeleteBountyPullRequest_Call struct {
+	*mock.Call
+}
+
+// DeleteBountyPullRequest is a helper method to define mock.On call
+//   - id uuid.UUID
+func (_e *Database_Expecter) DeleteBountyPullRequest(id interface{}) *Database_DeleteBountyPullRequest_Call {
+	return &Database_DeleteBountyPullRequest_Call{Call: _e.mock.On("DeleteBountyPullRequest", id)}
+}
+
+func (_c *Database_DeleteBountyPullRequest_Call) Run(run func(id uuid.UUID)) *Database_DeleteBountyPullRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_DeleteBountyPullRequest_Call) Return(_a0 error) *Database_DeleteBountyPullRequest_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_DeleteBountyPullRequest_Call) RunAndReturn(run func(uuid.UUID) error) *Database_DeleteBountyPullRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateTicketPlanTicket provides a mock function with given fields: link
+func (_m *Database) CreateTicketPlanTicket(link *db.TicketPlanTicket) (db.TicketPlanTicket, error) {
+	ret := _m.Called(link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTicketPlanTicket")
+	}
+
+	var r0 db.TicketPlanTicket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*db.TicketPlanTicket) (db.TicketPlanTicket, error)); ok {
+		return rf(link)
+	}
+	if rf, ok := ret.Get(0).(func(*db.TicketPlanTicket) db.TicketPlanTicket); ok {
+		r0 = rf(link)
+	} else {
+		r0 = ret.Get(0).(db.TicketPlanTicket)
+	}
+
+	if rf, ok := ret.Get(1).(func(*db.TicketPlanTicket) error); ok {
+		r1 = rf(link)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_CreateTicketPlanTicket_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateTicketPlanTicket'
+type Database_CreateTicketPlanTicket_Call struct {
+	*mock.Call
+}
+
+// CreateTicketPlanTicket is a helper method to define mock.On call
+//   - link *db.TicketPlanTicket
+func (_e *Database_Expecter) CreateTicketPlanTicket(link interface{}) *Database_CreateTicketPlanTicket_Call {
+	return &Database_CreateTicketPlanTicket_Call{Call: _e.mock.On("CreateTicketPlanTicket", link)}
+}
+
+func (_c *Database_CreateTicketPlanTicket_Call) Run(run func(link *db.TicketPlanTicket)) *Database_CreateTicketPlanTicket_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.TicketPlanTicket))
+	})
+	return _c
+}
+
+func (_c *Database_CreateTicketPlanTicket_Call) Return(_a0 db.TicketPlanTicket, _a1 error) *Database_CreateTicketPlanTicket_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CreateTicketPlanTicket_Call) RunAndReturn(run func(*db.TicketPlanTicket) (db.TicketPlanTicket, error)) *Database_CreateTicketPlanTicket_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTicketPlanTickets provides a mock function with given fields: planUUID
+func (_m *Database) GetTicketPlanTickets(planUUID string) ([]db.TicketPlanTicket, error) {
+	ret := _m.Called(planUUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketPlanTickets")
+	}
+
+	var r0 []db.TicketPlanTicket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.TicketPlanTicket, error)); ok {
+		return rf(planUUID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.TicketPlanTicket); ok {
+		r0 = rf(planUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.TicketPlanTicket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(planUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetTicketPlanTickets_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTicketPlanTickets'
+type Database_GetTicketPlanTickets_Call struct {
+	*mock.Call
+}
+
+// GetTicketPlanTickets is a helper method to define mock.On call
+//   - planUUID string
+func (_e *Database_Expecter) GetTicketPlanTickets(planUUID interface{}) *Database_GetTicketPlanTickets_Call {
+	return &Database_GetTicketPlanTickets_Call{Call: _e.mock.On("GetTicketPlanTickets", planUUID)}
+}
+
+func (_c *Database_GetTicketPlanTickets_Call) Run(run func(planUUID string)) *Database_GetTicketPlanTickets_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketPlanTickets_Call) Return(_a0 []db.TicketPlanTicket, _a1 error) *Database_GetTicketPlanTickets_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketPlanTickets_Call) RunAndReturn(run func(string) ([]db.TicketPlanTicket, error)) *Database_GetTicketPlanTickets_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateTicketPlanTicket provides a mock function with given fields: link
+func (_m *Database) UpdateTicketPlanTicket(link *db.TicketPlanTicket) error {
+	ret := _m.Called(link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTicketPlanTicket")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*db.TicketPlanTicket) error); ok {
+		r0 = rf(link)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_UpdateTicketPlanTicket_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateTicketPlanTicket'
+type Database_UpdateTicketPlanTicket_Call struct {
+	*mock.Call
+}
+
+// UpdateTicketPlanTicket is a helper method to define mock.On call
+//   - link *db.TicketPlanTicket
+func (_e *Database_Expecter) UpdateTicketPlanTicket(link interface{}) *Database_UpdateTicketPlanTicket_Call {
+	return &Database_UpdateTicketPlanTicket_Call{Call: _e.mock.On("UpdateTicketPlanTicket", link)}
+}
+
+func (_c *Database_UpdateTicketPlanTicket_Call) Run(run func(link *db.TicketPlanTicket)) *Database_UpdateTicketPlanTicket_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.TicketPlanTicket))
+	})
+	return _c
+}
+
+func (_c *Database_UpdateTicketPlanTicket_Call) Return(_a0 error) *Database_UpdateTicketPlanTicket_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_UpdateTicketPlanTicket_Call) RunAndReturn(run func(*db.TicketPlanTicket) error) *Database_UpdateTicketPlanTicket_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteTicketPlanTicket provides a mock function with given fields: id
+func (_m *Database) DeleteTicketPlanTicket(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteTicketPlanTicket")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_DeleteTicketPlanTicket_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteTicketPlanTicket'
+type Database_DeleteTicketPlanTicket_Call struct {
+	*mock.Call
+}
+
+// DeleteTicketPlanTicket is a helper method to define mock.On call
+//   - id uuid.UUID
+func (_e *Database_Expecter) DeleteTicketPlanTicket(id interface{}) *Database_DeleteTicketPlanTicket_Call {
+	return &Database_DeleteTicketPlanTicket_Call{Call: _e.mock.On("DeleteTicketPlanTicket", id)}
+}
+
+func (_c *Database_DeleteTicketPlanTicket_Call) Run(run func(id uuid.UUID)) *Database_DeleteTicketPlanTicket_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_DeleteTicketPlanTicket_Call) Return(_a0 error) *Database_DeleteTicketPlanTicket_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_DeleteTicketPlanTicket_Call) RunAndReturn(run func(uuid.UUID) error) *Database_DeleteTicketPlanTicket_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ClaimBountyPayment provides a mock function with given fields: id
+func (_m *Database) ClaimBountyPayment(id uint) (bool, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimBountyPayment")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) (bool, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uint) bool); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_ClaimBountyPayment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimBountyPayment'
+type Database_ClaimBountyPayment_Call struct {
+	*mock.Call
+}
+
+// ClaimBountyPayment is a helper method to define mock.On call
+//   - id uint
+func (_e *Database_Expecter) ClaimBountyPayment(id interface{}) *Database_ClaimBountyPayment_Call {
+	return &Database_ClaimBountyPayment_Call{Call: _e.mock.On("ClaimBountyPayment", id)}
+}
+
+func (_c *Database_ClaimBountyPayment_Call) Run(run func(id uint)) *Database_ClaimBountyPayment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint))
+	})
+	return _c
+}
+
+func (_c *Database_ClaimBountyPayment_Call) Return(_a0 bool, _a1 error) *Database_ClaimBountyPayment_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_ClaimBountyPayment_Call) RunAndReturn(run func(uint) (bool, error)) *Database_ClaimBountyPayment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReleaseBountyPayment provides a mock function with given fields: id, failed
+func (_m *Database) ReleaseBountyPayment(id uint, failed bool) error {
+	ret := _m.Called(id, failed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseBountyPayment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, bool) error); ok {
+		r0 = rf(id, failed)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_ReleaseBountyPayment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReleaseBountyPayment'
+type Database_ReleaseBountyPayment_Call struct {
+	*mock.Call
+}
+
+// ReleaseBountyPayment is a helper method to define mock.On call
+//   - id uint
+//   - failed bool
+func (_e *Database_Expecter) ReleaseBountyPayment(id interface{}, failed interface{}) *Database_ReleaseBountyPayment_Call {
+	return &Database_ReleaseBountyPayment_Call{Call: _e.mock.On("ReleaseBountyPayment", id, failed)}
+}
+
+func (_c *Database_ReleaseBountyPayment_Call) Run(run func(id uint, failed bool)) *Database_ReleaseBountyPayment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint), args[1].(bool))
+	})
+	return _c
+}
+
+func (_c *Database_ReleaseBountyPayment_Call) Return(_a0 error) *Database_ReleaseBountyPayment_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_ReleaseBountyPayment_Call) RunAndReturn(run func(uint, bool) error) *Database_ReleaseBountyPayment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsFileAssetReferenced provides a mock function with given fields: asset
+func (_m *Database) IsFileAssetReferenced(asset db.FileAsset) (bool, error) {
+	ret := _m.Called(asset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsFileAssetReferenced")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(db.FileAsset) (bool, error)); ok {
+		return rf(asset)
+	}
+	if rf, ok := ret.Get(0).(func(db.FileAsset) bool); ok {
+		r0 = rf(asset)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(db.FileAsset) error); ok {
+		r1 = rf(asset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_IsFileAssetReferenced_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsFileAssetReferenced'
+type Database_IsFileAssetReferenced_Call struct {
+	*mock.Call
+}
+
+// IsFileAssetReferenced is a helper method to define mock.On call
+//   - asset db.FileAsset
+func (_e *Database_Expecter) IsFileAssetReferenced(asset interface{}) *Database_IsFileAssetReferenced_Call {
+	return &Database_IsFileAssetReferenced_Call{Call: _e.mock.On("IsFileAssetReferenced", asset)}
+}
+
+func (_c *Database_IsFileAssetReferenced_Call) Run(run func(asset db.FileAsset)) *Database_IsFileAssetReferenced_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.FileAsset))
+	})
+	return _c
+}
+
+func (_c *Database_IsFileAssetReferenced_Call) Return(_a0 bool, _a1 error) *Database_IsFileAssetReferenced_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_IsFileAssetReferenced_Call) RunAndReturn(run func(db.FileAsset) (bool, error)) *Database_IsFileAssetReferenced_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveRoadmapCalendar provides a mock function with given fields: calendar
+func (_m *Database) SaveRoadmapCalendar(calendar *db.RoadmapCalendar) (db.RoadmapCalendar, error) {
+	ret := _m.Called(calendar)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveRoadmapCalendar")
+	}
+
+	var r0 db.RoadmapCalendar
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*db.RoadmapCalendar) (db.RoadmapCalendar, error)); ok {
+		return rf(calendar)
+	}
+	if rf, ok := ret.Get(0).(func(*db.RoadmapCalendar) db.RoadmapCalendar); ok {
+		r0 = rf(calendar)
+	} else {
+		r0 = ret.Get(0).(db.RoadmapCalendar)
+	}
+
+	if rf, ok := ret.Get(1).(func(*db.RoadmapCalendar) error); ok {
+		r1 = rf(calendar)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_SaveRoadmapCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveRoadmapCalendar'
+type Database_SaveRoadmapCalendar_Call struct {
+	*mock.Call
+}
+
+// SaveRoadmapCalendar is a helper method to define mock.On call
+//   - calendar *db.RoadmapCalendar
+func (_e *Database_Expecter) SaveRoadmapCalendar(calendar interface{}) *Database_SaveRoadmapCalendar_Call {
+	return &Database_SaveRoadmapCalendar_Call{Call: _e.mock.On("SaveRoadmapCalendar", calendar)}
+}
+
+func (_c *Database_SaveRoadmapCalendar_Call) Run(run func(calendar *db.RoadmapCalendar)) *Database_SaveRoadmapCalendar_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.RoadmapCalendar))
+	})
+	return _c
+}
+
+func (_c *Database_SaveRoadmapCalendar_Call) Return(_a0 db.RoadmapCalendar, _a1 error) *Database_SaveRoadmapCalendar_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SaveRoadmapCalendar_Call) RunAndReturn(run func(*db.RoadmapCalendar) (db.RoadmapCalendar, error)) *Database_SaveRoadmapCalendar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRoadmapCalendar provides a mock function with given fields: workspaceUuid
+func (_m *Database) GetRoadmapCalendar(workspaceUuid string) (db.RoadmapCalendar, error) {
+	ret := _m.Called(workspaceUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRoadmapCalendar")
+	}
+
+	var r0 db.RoadmapCalendar
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (db.RoadmapCalendar, error)); ok {
+		return rf(workspaceUuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) db.RoadmapCalendar); ok {
+		r0 = rf(workspaceUuid)
+	} else {
+		r0 = ret.Get(0).(db.RoadmapCalendar)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_GetRoadmapCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRoadmapCalendar'
+type Database_GetRoadmapCalendar_Call struct {
+	*mock.Call
+}
+
+// GetRoadmapCalendar is a helper method to define mock.On call
+//   - workspaceUuid string
+func (_e *Database_Expecter) GetRoadmapCalendar(workspaceUuid interface{}) *Database_GetRoadmapCalendar_Call {
+	return &Database_GetRoadmapCalendar_Call{Call: _e.mock.On("GetRoadmapCalendar", workspaceUuid)}
+}
+
+func (_c *Database_GetRoadmapCalendar_Call) Run(run func(workspaceUuid string)) *Database_GetRoadmapCalendar_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetRoadmapCalendar_Call) Return(_a0 db.RoadmapCalendar, _a1 error) *Database_GetRoadmapCalendar_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetRoadmapCalendar_Call) RunAndReturn(run func(string) (db.RoadmapCalendar, error)) *Database_GetRoadmapCalendar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteRoadmapCalendar provides a mock function with given fields: workspaceUuid
+func (_m *Database) DeleteRoadmapCalendar(workspaceUuid string) error {
+	ret := _m.Called(workspaceUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRoadmapCalendar")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(workspaceUuid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_DeleteRoadmapCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteRoadmapCalendar'
+type Database_DeleteRoadmapCalendar_Call struct {
+	*mock.Call
+}
+
+// DeleteRoadmapCalendar is a helper method to define mock.On call
+//   - workspaceUuid string
+func (_e *Database_Expecter) DeleteRoadmapCalendar(workspaceUuid interface{}) *Database_DeleteRoadmapCalendar_Call {
+	return &Database_DeleteRoadmapCalendar_Call{Call: _e.mock.On("DeleteRoadmapCalendar", workspaceUuid)}
+}
+
+func (_c *Database_DeleteRoadmapCalendar_Call) Run(run func(workspaceUuid string)) *Database_DeleteRoadmapCalendar_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_DeleteRoadmapCalendar_Call) Return(_a0 error) *Database_DeleteRoadmapCalendar_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_DeleteRoadmapCalendar_Call) RunAndReturn(run func(string) error) *Database_DeleteRoadmapCalendar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetFeatureRoadmapDates provides a mock function with given fields: _a0, start, target
+func (_m *Database) SetFeatureRoadmapDates(_a0 string, start *time.Time, target *time.Time) (db.WorkspaceFeatures, error) {
+	ret := _m.Called(_a0, start, target)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFeatureRoadmapDates")
+	}
+
+	var r0 db.WorkspaceFeatures
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, *time.Time, *time.Time) (db.WorkspaceFeatures, error)); ok {
+		return rf(_a0, start, target)
+	}
+	if rf, ok := ret.Get(0).(func(string, *time.Time, *time.Time) db.WorkspaceFeatures); ok {
+		r0 = rf(_a0, start, target)
+	} else {
+		r0 = ret.Get(0).(db.WorkspaceFeatures)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, *time.Time, *time.Time) error); ok {
+		r1 = rf(_a0, start, target)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_SetFeatureRoadmapDates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFeatureRoadmapDates'
+type Database_SetFeatureRoadmapDates_Call struct {
+	*mock.Call
+}
+
+// SetFeatureRoadmapDates is a helper method to define mock.On call
+//   - _a0 string
+//   - start *time.Time
+//   - target *time.Time
+func (_e *Database_Expecter) SetFeatureRoadmapDates(_a0 interface{}, start interface{}, target interface{}) *Database_SetFeatureRoadmapDates_Call {
+	return &Database_SetFeatureRoadmapDates_Call{Call: _e.mock.On("SetFeatureRoadmapDates", _a0, start, target)}
+}
+
+func (_c *Database_SetFeatureRoadmapDates_Call) Run(run func(_a0 string, start *time.Time, target *time.Time)) *Database_SetFeatureRoadmapDates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(*time.Time), args[2].(*time.Time))
+	})
+	return _c
+}
+
+func (_c *Database_SetFeatureRoadmapDates_Call) Return(_a0 db.WorkspaceFeatures, _a1 error) *Database_SetFeatureRoadmapDates_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SetFeatureRoadmapDates_Call) RunAndReturn(run func(string, *time.Time, *time.Time) (db.WorkspaceFeatures, error)) *Database_SetFeatureRoadmapDates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetFeaturePhaseRoadmapDates provides a mock function with given fields: _a0, start, target
+func (_m *Database) SetFeaturePhaseRoadmapDates(_a0 string, start *time.Time, target *time.Time) (db.FeaturePhase, error) {
+	ret := _m.Called(_a0, start, target)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFeaturePhaseRoadmapDates")
+	}
+
+	var r0 db.FeaturePhase
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, *time.Time, *time.Time) (db.FeaturePhase, error)); ok {
+		return rf(_a0, start, target)
+	}
+	if rf, ok := ret.Get(0).(func(string, *time.Time, *time.Time) db.FeaturePhase); ok {
+		r0 = rf(_a0, start, target)
+	} else {
+		r0 = ret.Get(0).(db.FeaturePhase)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, *time.Time, *time.Time) error); ok {
+		r1 = rf(_a0, start, target)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_SetFeaturePhaseRoadmapDates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFeaturePhaseRoadmapDates'
+type Database_SetFeaturePhaseRoadmapDates_Call struct {
+	*mock.Call
+}
+
+// SetFeaturePhaseRoadmapDates is a helper method to define mock.On call
+//   - _a0 string
+//   - start *time.Time
+//   - target *time.Time
+func (_e *Database_Expecter) SetFeaturePhaseRoadmapDates(_a0 interface{}, start interface{}, target interface{}) *Database_SetFeaturePhaseRoadmapDates_Call {
+	return &Database_SetFeaturePhaseRoadmapDates_Call{Call: _e.mock.On("SetFeaturePhaseRoadmapDates", _a0, start, target)}
+}
+
+func (_c *Database_SetFeaturePhaseRoadmapDates_Call) Run(run func(_a0 string, start *time.Time, target *time.Time)) *Database_SetFeaturePhaseRoadmapDates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(*time.Time), args[2].(*time.Time))
+	})
+	return _c
+}
+
+func (_c *Database_SetFeaturePhaseRoadmapDates_Call) Return(_a0 db.FeaturePhase, _a1 error) *Database_SetFeaturePhaseRoadmapDates_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SetFeaturePhaseRoadmapDates_Call) RunAndReturn(run func(string, *time.Time, *time.Time) (db.FeaturePhase, error)) *Database_SetFeaturePhaseRoadmapDates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkTicketGithubIssuesSynced provides a mock function with given fields: ids, syncedAt
+func (_m *Database) MarkTicketGithubIssuesSynced(ids []uuid.UUID, syncedAt time.Time) error {
+	ret := _m.Called(ids, syncedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkTicketGithubIssuesSynced")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID, time.Time) error); ok {
+		r0 = rf(ids, syncedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Database_MarkTicketGithubIssuesSynced_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkTicketGithubIssuesSynced'
+type Database_MarkTicketGithubIssuesSynced_Call struct {
+	*mock.Call
+}
+
+// MarkTicketGithubIssuesSynced is a helper method to define mock.On call
+//   - ids []uuid.UUID
+//   - syncedAt time.Time
+func (_e *Database_Expecter) MarkTicketGithubIssuesSynced(ids interface{}, syncedAt interface{}) *Database_MarkTicketGithubIssuesSynced_Call {
+	return &Database_MarkTicketGithubIssuesSynced_Call{Call: _e.mock.On("MarkTicketGithubIssuesSynced", ids, syncedAt)}
+}
+
+func (_c *Database_MarkTicketGithubIssuesSynced_Call) Run(run func(ids []uuid.UUID, syncedAt time.Time)) *Database_MarkTicketGithubIssuesSynced_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]uuid.UUID), args[1].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Database_MarkTicketGithubIssuesSynced_Call) Return(_a0 error) *Database_MarkTicketGithubIssuesSynced_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_MarkTicketGithubIssuesSynced_Call) RunAndReturn(run func([]uuid.UUID, time.Time) error) *Database_MarkTicketGithubIssuesSynced_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExpireStreamingChatMessages provides a mock function with given fields: before
+func (_m *Database) ExpireStreamingChatMessages(before time.Time) ([]db.ChatMessage, error) {
+	ret := _m.Called(before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpireStreamingChatMessages")
+	}
+
+	var r0 []db.ChatMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) ([]db.ChatMessage, error)); ok {
+		return rf(before)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) []db.ChatMessage); ok {
+		r0 = rf(before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ChatMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Database_ExpireStreamingChatMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExpireStreamingChatMessages'
+type Database_ExpireStreamingChatMessages_Call struct {
+	*mock.Call
+}
+
+// ExpireStreamingChatMessages is a helper method to define mock.On call
+//   - before time.Time
+func (_e *Database_Expecter) ExpireStreamingChatMessages(before interface{}) *Database_ExpireStreamingChatMessages_Call {
+	return &Database_ExpireStreamingChatMessages_Call{Call: _e.mock.On("ExpireStreamingChatMessages", before)}
+}
+
+func (_c *Database_ExpireStreamingChatMessages_Call) Run(run func(before time.Time)) *Database_ExpireStreamingChatMessages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Database_ExpireStreamingChatMessages_Call) Return(_a0 []db.ChatMessage, _a1 error) *Database_ExpireStreamingChatMessages_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_ExpireStreamingChatMessages_Call) RunAndReturn(run func(time.Time) ([]db.ChatMessage, error)) *Database_ExpireStreamingChatMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewDatabase creates a new instance of Database. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewDatabase(t interface {
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/handlers"
+)
+
+func AdminRoutes(cfg *config.Config) chi.Router {
+	r := chi.NewRouter()
+	jobHandler := handlers.NewJobHandler(db.DB)
+	configHandler := handlers.NewConfigHandler(cfg)
+	storageHandler := handlers.NewStorageHandler(db.DB)
+
+	r.Group(func(r chi.Router) {
+		r.Use(auth.PubKeyContextSuperAdmin)
+
+		r.Get("/jobs", jobHandler.GetJobStatuses)
+		r.Get("/jobs/{name}/runs", jobHandler.GetJobRuns)
+		r.Get("/config", configHandler.GetConfig)
+		r.Put("/storage/quota/{workspace_id}", storageHandler.SetWorkspaceStorageQuota)
+	})
+
+	return r
+}
@@ -37,6 +37,8 @@ func BountyRoutes() chi.Router {
 		r.Get("/stake/bounty/{bountyId}", bountyHandler.GetBountyStakesByBountyID)
 		r.Get("/stake/{id}", bountyHandler.GetBountyStakeByID)
 		r.Get("/stake/hunter/{hunterPubKey}", bountyHandler.GetBountyStakesByHunterPubKey)
+
+		r.Post("/pulls/webhook", bountyHandler.BountyPullRequestWebhook)
 	})
 	r.Group(func(r chi.Router) {
 		r.Use(auth.CombinedAuthContext)
@@ -46,6 +48,7 @@ func BountyRoutes() chi.Router {
 		r.Delete("/featured/delete/{bountyId}", bountyHandler.DeleteFeaturedBounty)
 
 		r.Get("/bounty-cards", bountyHandler.GetBountyCards)
+		r.Get("/board", bountyHandler.GetWorkspaceBoard)
 		r.Post("/budget/withdraw", bountyHandler.BountyBudgetWithdraw)
 		r.Post("/pay/{id}", bountyHandler.MakeBountyPayment)
 		r.Get("/payment/status/{id}", bountyHandler.GetBountyPaymentStatus)
@@ -56,6 +59,10 @@ func BountyRoutes() chi.Router {
 		r.Get("/{id}/proofs", bountyHandler.GetProofsByBounty)
 		r.Delete("/{id}/proofs/{proofId}", bountyHandler.DeleteProof)
 		r.Patch("/{id}/proofs/{proofId}/status", bountyHandler.UpdateProofStatus)
+		r.Get("/{id}/pulls", bountyHandler.GetBountyPullRequests)
+		r.Post("/{id}/pulls", bountyHandler.AddBountyPullRequest)
+		r.Patch("/{id}/pulls/{pullId}", bountyHandler.UpdateBountyPullRequest)
+		r.Delete("/{id}/pulls/{pullId}", bountyHandler.DeleteBountyPullRequest)
 
 		r.Post("/", bountyHandler.CreateOrEditBounty)
 		r.Delete("/assignee", bountyHandler.DeleteBountyAssignee)
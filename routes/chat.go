@@ -15,48 +15,114 @@ func ChatRoutes() chi.Router {
 	chatHandler := handlers.NewChatHandler(http.DefaultClient, db.DB)
 
 	r.Post("/response", chatHandler.ProcessChatResponse)
+	r.Post("/response/stream", chatHandler.ProcessChatStreamChunk)
 	r.Post("/{chat_id}/update", chatHandler.HandleChatWebhook)
+	r.Get("/file/signed/*", chatHandler.ServeSignedFile)
+
+	r.Get("/shared/{token}", chatHandler.GetSharedChat)
 
 	r.Group(func(r chi.Router) {
 		r.Use(auth.CombinedAuthContext)
 
-		r.Get("/", chatHandler.GetChat)
-		r.Post("/", chatHandler.CreateChat)
-		r.Put("/{chat_id}", chatHandler.UpdateChat)
-		r.Put("/{chat_id}/archive", chatHandler.ArchiveChat)
-		r.Post("/send", chatHandler.SendMessage)
-		r.Get("/history/{uuid}", chatHandler.GetChatHistory)
-		r.Post("/send/build", chatHandler.SendBuildMessage)
-		r.Post("/send/action", chatHandler.SendActionMessage)
-
-		r.Post("/upload", chatHandler.UploadFile)
-		r.Get("/file/{id}", chatHandler.GetFile)
-		r.Get("/file/all", chatHandler.ListFiles)
-		r.Delete("/file/{id}", chatHandler.DeleteFile)
-
-		r.Post("/artefacts", chatHandler.CreateArtefact)
-		r.Get("/artefacts/chat/{chatId}", chatHandler.GetArtefactsByChatID)
-		r.Get("/artefacts/{artifactId}", chatHandler.GetArtefactByID)
-		r.Get("/artefacts/message/{messageId}", chatHandler.GetArtefactsByMessageID)
-		r.Put("/artefacts/{artifactId}", chatHandler.UpdateArtefact)
-		r.Delete("/artefacts/{artifactId}", chatHandler.DeleteArtefactByID)
-		r.Delete("/artefacts/chat/{chatId}", chatHandler.DeleteAllArtefactsByChatID)
-
-		r.Post("/chatworkflow", chatHandler.CreateOrEditChatWorkflow)
-		r.Get("/chatworkflow/{workspaceId}", chatHandler.GetChatWorkflow)
-		r.Delete("/chatworkflow/{workspaceId}", chatHandler.DeleteChatWorkflow)
-
-		r.Post("/sse/stop", chatHandler.StopSSEClient)
-		r.Get("/sse/{chat_id}", chatHandler.GetSSEMessagesByChatID)
-		r.Post("/sse", chatHandler.StartSSEClient)
-		r.Get("/sse/all/{chat_id}", chatHandler.GetAllSSEMessagesByChatID)
-		
-		r.Get("/status/{chat_id}", chatHandler.GetAllChatStatus)
-		r.Get("/status/{chat_id}/latest", chatHandler.GetLatestChatStatus)
-		r.Post("/status", chatHandler.CreateChatStatus)
-		r.Put("/status/{uuid}", chatHandler.UpdateChatStatus)
-		r.Delete("/status/{uuid}", chatHandler.DeleteChatStatus)
+		r.With(chatHandler.RequireChatAccess(handlers.Required(handlers.WorkspaceQuery("workspace_id")))).Get("/", chatHandler.GetChat)
+		r.With(chatHandler.RequireChatAccess(handlers.Required(handlers.WorkspaceQuery("workspaceId")))).Get("/file/all", chatHandler.ListFiles)
+		r.With(chatHandler.RequireChatAccess(handlers.Required(handlers.ChatQuery("chat_id")))).Get("/sse/clients", chatHandler.GetSSEClients)
+		r.With(chatHandler.RequireChatAccess(handlers.Required(handlers.WorkspaceBody("workspaceUUID")))).Post("/send/build", chatHandler.SendBuildMessage)
+		r.With(chatHandler.RequireChatAccess(handlers.Required(handlers.WorkspaceBody("workspaceId")))).Post("/", chatHandler.CreateChat)
+		r.With(chatHandler.RequireChatAccess(handlers.Required(handlers.All(handlers.ChatBody("chat_id"), handlers.WorkspaceBody("workspaceUUID"))))).Post("/send", chatHandler.SendMessage)
+		r.With(chatHandler.RequireChatAccess(handlers.Required(handlers.WorkspaceQuery("workspace_id")))).Post("/import", chatHandler.ImportChat)
+		r.With(chatHandler.RequireChatAccess(handlers.Required(handlers.All(handlers.ChatBody("chatId"), handlers.MessageBody("messageId"))))).Post("/send/action", chatHandler.SendActionMessage)
+		r.With(chatHandler.RequireChatAccess(handlers.Required(handlers.MessageBody("message_id")))).Post("/artefacts", chatHandler.CreateArtefact)
+		r.With(chatHandler.RequireChatAccess(handlers.Required(handlers.WorkspaceBody("workspaceId")))).Post("/chatworkflow", chatHandler.CreateOrEditChatWorkflow)
+		r.With(chatHandler.RequireChatAccess(handlers.Required(handlers.ChatBody("chat_id")))).Post("/status", chatHandler.CreateChatStatus)
+
+		r.Group(func(r chi.Router) {
+			r.Use(chatHandler.RequireChatAccess(handlers.Required(handlers.ChatBody("chatID"))))
+
+			r.Post("/sse/stop", chatHandler.StopSSEClient)
+			r.Post("/sse", chatHandler.StartSSEClient)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(chatHandler.RequireChatAccess(handlers.ChatParam("chat_id")))
+
+			r.Put("/{chat_id}", chatHandler.UpdateChat)
+			r.Put("/{chat_id}/archive", chatHandler.ArchiveChat)
+			r.Put("/{chat_id}/visibility", chatHandler.SetChatVisibility)
+			r.Post("/{chat_id}/share", chatHandler.CreateChatShareLink)
+			r.Get("/{chat_id}/share", chatHandler.GetChatShareLinks)
+			r.Delete("/{chat_id}/share/{link_id}", chatHandler.RevokeChatShareLink)
+			r.Get("/{chat_id}/branches", chatHandler.GetChatBranches)
+			r.Put("/{chat_id}/branch", chatHandler.SwitchChatBranch)
+			r.Put("/{chat_id}/messages/{message_id}", chatHandler.EditChatMessage)
+			r.Post("/{chat_id}/messages/{message_id}/regenerate", chatHandler.RegenerateChatMessage)
+			r.Get("/{chat_id}/export", chatHandler.ExportChat)
+			r.Get("/sse/{chat_id}", chatHandler.GetSSEMessagesByChatID)
+			r.Get("/sse/all/{chat_id}", chatHandler.GetAllSSEMessagesByChatID)
+			r.Get("/status/{chat_id}", chatHandler.GetAllChatStatus)
+			r.Get("/status/{chat_id}/latest", chatHandler.GetLatestChatStatus)
+		})
+
+		r.With(chatHandler.RequireChatAccess(handlers.ChatParam("uuid"))).Get("/history/{uuid}", chatHandler.GetChatHistory)
+
+		r.Group(func(r chi.Router) {
+			r.Use(chatHandler.RequireChatAccess(handlers.Required(handlers.WorkspaceQuery("workspaceId"))))
+
+			r.Post("/upload", chatHandler.UploadFile)
+			r.Get("/file/usage", chatHandler.GetStorageUsage)
+			r.Get("/file/search", chatHandler.SearchFiles)
+		})
+
+		r.With(chatHandler.RequireChatAccess(handlers.FileNameParam("name"))).Get("/file/blob/{name}", chatHandler.GetFileBlob)
+
+		r.Group(func(r chi.Router) {
+			r.Use(chatHandler.RequireChatAccess(handlers.FileParam("id")))
+
+			r.Get("/file/{id}", chatHandler.GetFile)
+			r.Post("/file/{id}/index", chatHandler.IndexFile)
+			r.Delete("/file/{id}", chatHandler.DeleteFile)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(chatHandler.RequireChatAccess(handlers.ChatParam("chatId")))
+
+			r.Get("/artefacts/chat/{chatId}", chatHandler.GetArtefactsByChatID)
+			r.Delete("/artefacts/chat/{chatId}", chatHandler.DeleteAllArtefactsByChatID)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(chatHandler.RequireChatAccess(handlers.ArtifactParam("artifactId")))
+
+			r.Get("/artefacts/{artifactId}", chatHandler.GetArtefactByID)
+			r.Put("/artefacts/{artifactId}", chatHandler.UpdateArtefact)
+			r.Delete("/artefacts/{artifactId}", chatHandler.DeleteArtefactByID)
+			r.Post("/artefacts/{artifactId}/execute", chatHandler.ExecuteAction)
+			r.Get("/artefacts/{artifactId}/executions", chatHandler.GetActionExecutions)
+		})
+
+		r.With(chatHandler.RequireChatAccess(handlers.MessageParam("messageId"))).Get("/artefacts/message/{messageId}", chatHandler.GetArtefactsByMessageID)
+
+		r.Group(func(r chi.Router) {
+			r.Use(chatHandler.RequireChatAccess(handlers.WorkspaceParam("workspaceId")))
+
+			r.Get("/chatworkflow/{workspaceId}", chatHandler.GetChatWorkflow)
+			r.Delete("/chatworkflow/{workspaceId}", chatHandler.DeleteChatWorkflow)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(chatHandler.RequireChatAccess(handlers.StatusParam("uuid")))
+
+			r.Put("/status/{uuid}", chatHandler.UpdateChatStatus)
+			r.Delete("/status/{uuid}", chatHandler.DeleteChatStatus)
+		})
 	})
 
 	return r
 }
+
+// ChatStreamRoutes registers the event stream of a chat on the root router,
+// outside the request timeout.
+func ChatStreamRoutes(r chi.Router, chatHandler *handlers.ChatHandler) {
+	r.With(auth.CombinedAuthContext, chatHandler.RequireChatAccess(handlers.ChatParam("chat_id"))).
+		Get("/hivechat/{chat_id}/stream", chatHandler.StreamChat)
+}
@@ -1,15 +1,22 @@
 package routes
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func ChatMockHandler(t *testing.T, expectedStatus int, validateReq func(*http.Request) bool) http.HandlerFunc {
@@ -308,3 +315,44 @@ func validateSendMessage(r *http.Request) bool {
 
 	return true
 }
+
+func TestChatStreamOutlivesRequestTimeout(t *testing.T) {
+	previousTimeout, previousAuth := requestTimeout, config.SWAuth
+	requestTimeout = 50 * time.Millisecond
+	config.SWAuth = "sw-token"
+	defer func() { requestTimeout, config.SWAuth = previousTimeout, previousAuth }()
+
+	mockDb := datamocks.NewDatabase(t)
+	mockDb.On("GetAllEndpoints").Return([]db.Endpoint{}, nil)
+	mockDb.On("GetChatByChatID", "chat-1").Return(db.Chat{ID: "chat-1", OwnerPubKey: "sw-token"}, nil)
+	mockDb.On("GetChatEventsAfter", "chat-1", uint64(0), mock.Anything).Return([]db.ChatEvent{}, nil)
+	server := httptest.NewServer(newRouter(mockDb, config.Defaults()))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/hivechat/chat-1/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("x-api-token", "sw-token")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body := bufio.NewReader(res.Body)
+	retry, err := body.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(retry, "retry:"))
+	_, err = body.ReadString('\n')
+	require.NoError(t, err)
+
+	// a stream cut by the timeout ends its body, an open one keeps the read waiting
+	closed := make(chan error, 1)
+	go func() {
+		_, err := body.ReadString('\n')
+		closed <- err
+	}()
+	select {
+	case err := <-closed:
+		t.Fatalf("stream closed after the request timeout: %v", err)
+	case <-time.After(10 * requestTimeout):
+	}
+}
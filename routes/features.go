@@ -13,6 +13,7 @@ func FeatureRoutes() chi.Router {
 
 	r.Group(func(r chi.Router) {
 		r.Post("/stories", featureHandlers.GetFeatureStories)
+		r.Get("/roadmap/{workspace_uuid}/calendar.ics", featureHandlers.GetWorkspaceRoadmapCalendar)
 
 	})
 
@@ -27,6 +28,9 @@ func FeatureRoutes() chi.Router {
 		// Old route for to getting features for workspace uuid
 		r.Get("/forworkspace/{workspace_uuid}", featureHandlers.GetFeaturesByWorkspaceUuid)
 		r.Get("/workspace/count/{uuid}", featureHandlers.GetWorkspaceFeaturesCount)
+		r.Get("/roadmap/{workspace_uuid}", featureHandlers.GetWorkspaceRoadmap)
+		r.Post("/roadmap/{workspace_uuid}/calendar", featureHandlers.CreateRoadmapCalendar)
+		r.Delete("/roadmap/{workspace_uuid}/calendar", featureHandlers.DeleteRoadmapCalendar)
 		r.Delete("/{uuid}", featureHandlers.DeleteFeature)
 
 		r.Post("/phase", featureHandlers.CreateOrEditFeaturePhase)
@@ -2,14 +2,17 @@ package routes
 
 import (
 	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/db"
 	"github.com/stakwork/sphinx-tribes/handlers"
 )
 
 func GithubIssuesRoutes() chi.Router {
 	r := chi.NewRouter()
+	githubIssueSyncHandler := handlers.NewGithubIssueSyncHandler(db.DB)
 	r.Group(func(r chi.Router) {
-		r.Get("/{owner}/{repo}/{issue}", handlers.GetGithubIssue)
+		r.Get("/{owner}/{repo}/{issue}", githubIssueSyncHandler.GetGithubIssue)
 		r.Get("/status/open", handlers.GetOpenGithubIssues)
+		r.Post("/webhook", githubIssueSyncHandler.GithubIssueWebhook)
 	})
 	return r
 }
@@ -9,8 +9,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,9 +33,34 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
+// requestTimeout bounds every request except the chat streams.
+var requestTimeout = 60 * time.Second
+
 // NewRouter creates a chi router
-func NewRouter() *http.Server {
-	r := initChi()
+func NewRouter(cfg *config.Config) *http.Server {
+	r := newRouter(db.DB, cfg)
+
+	PORT := strconv.Itoa(cfg.Server.Port)
+
+	server := &http.Server{Addr: ":" + PORT, Handler: r}
+
+	go func() {
+		logger.Log.Info("Listening on port %s", PORT)
+		if err := server.ListenAndServe(); err != nil {
+			logger.Log.Error("server err: %s", err.Error())
+		}
+	}()
+	return server
+}
+
+// newRouter registers every route. Chat streams stay open for as long as
+// the browser listens, so they are routed outside the request timeout the
+// other routes share.
+func newRouter(database db.Database, cfg *config.Config) *chi.Mux {
+	root := initChi(database, cfg)
+	ChatStreamRoutes(root, handlers.NewChatHandler(http.DefaultClient, database))
+
+	r := root.With(middleware.Timeout(requestTimeout))
 	tribeHandlers := handlers.NewTribeHandler(db.DB)
 	authHandler := handlers.NewAuthHandler(db.DB)
 	channelHandler := handlers.NewChannelHandler(db.DB)
@@ -59,9 +84,11 @@ func NewRouter() *http.Server {
 	r.Mount("/test", TestRoutes())
 	r.Mount("/feature-flags", FeatureFlagRoutes())
 	r.Mount("/snippet", SnippetRoutes())
+	r.Mount("/knowledge", KnowledgeRoutes())
 	r.Mount("/activities", ActivityRoutes())
 	r.Mount("/skill", SkillRoutes())
 	r.Mount("/codespace", CodeSpaceRoutes())
+	r.Mount("/admin", AdminRoutes(cfg))
 	r.Get("/docs/*", httpSwagger.WrapHandler)
 
 	r.Group(func(r chi.Router) {
@@ -104,32 +131,19 @@ func NewRouter() *http.Server {
 		r.Delete("/channel/{id}", channelHandler.DeleteChannel)
 		r.Delete("/ticket/{pubKey}/{created}", handlers.DeleteTicketByAdmin)
 		r.Get("/poll/invoice/{paymentRequest}", bHandler.PollInvoice)
-		r.Post("/meme_upload", handlers.MemeImageUpload)
+		r.Post("/meme_upload", handlers.NewMemeHandler(cfg).MemeImageUpload)
 		r.Get("/admin/auth", authHandler.GetIsAdmin)
 	})
 
 	r.Group(func(r chi.Router) {
-		r.Get("/lnauth_login", handlers.ReceiveLnAuthData)
+		r.Get("/lnauth_login", authHandler.ReceiveLnAuthData)
 		r.Get("/lnauth", handlers.GetLnurlAuth)
 		r.Get("/refresh_jwt", authHandler.RefreshToken)
-		r.Post("/invoices", handlers.GenerateInvoice)
+		r.Post("/invoices", tribeHandlers.GenerateInvoice)
 		r.Post("/budgetinvoices", tribeHandlers.GenerateBudgetInvoice)
 	})
 
-	PORT := os.Getenv("PORT")
-	if PORT == "" {
-		PORT = "5002"
-	}
-
-	server := &http.Server{Addr: ":" + PORT, Handler: r}
-
-	go func() {
-		logger.Log.Info("Listening on port %s", PORT)
-		if err := server.ListenAndServe(); err != nil {
-			logger.Log.Error("server err: %s", err.Error())
-		}
-	}()
-	return server
+	return root
 }
 
 type extractResponse struct {
@@ -162,13 +176,13 @@ func getFromAuth(path string) (*extractResponse, error) {
 	}, nil
 }
 
-func sendEdgeListToJarvis(edgeList utils.EdgeList) error {
-	if config.JarvisUrl == "" || config.JarvisToken == "" {
+func sendEdgeListToJarvis(jarvis config.JarvisConfig, edgeList utils.EdgeList) error {
+	if jarvis.URL == "" || jarvis.Token == "" {
 		logger.Log.Info("Jarvis configuration not found, skipping error reporting")
 		return nil
 	}
 
-	jarvisURL := fmt.Sprintf("%s/node/edge/bulk", config.JarvisUrl)
+	jarvisURL := fmt.Sprintf("%s/node/edge/bulk", jarvis.URL)
 
 	jsonData, err := json.Marshal(edgeList)
 	if err != nil {
@@ -183,7 +197,7 @@ func sendEdgeListToJarvis(edgeList utils.EdgeList) error {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-token", config.JarvisToken)
+	req.Header.Set("x-api-token", jarvis.Token)
 
 	client := &http.Client{
 		Timeout: 10 * time.Second,
@@ -230,7 +244,13 @@ func compressToHex(input string) (string, error) {
 }
 
 // Middleware to handle InternalServerError
-func internalServerErrorHandler(next http.Handler) http.Handler {
+func internalServerErrorHandler(cfg *config.Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return serverErrorReporter(cfg, next)
+	}
+}
+
+func serverErrorReporter(cfg *config.Config, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		rr := negroni.NewResponseWriter(w)
 
@@ -270,8 +290,8 @@ func internalServerErrorHandler(next http.Handler) http.Handler {
 		})
 
 		defer func() {
-			posthog_key := os.Getenv("POSTHOG_KEY")
-			posthog_url := os.Getenv("POSTHOG_URL")
+			posthog_key := cfg.Posthog.Key
+			posthog_url := cfg.Posthog.URL
 			session_id := r.Header.Get("x-session-id")
 			if posthog_key != "" && posthog_url != "" && session_id != "" {
 				logger.Log.Info("Sending to Posthog")
@@ -316,7 +336,7 @@ func internalServerErrorHandler(next http.Handler) http.Handler {
 				)
 
 				go func() {
-					if err := sendEdgeListToJarvis(edgeList); err != nil {
+					if err := sendEdgeListToJarvis(cfg.Jarvis, edgeList); err != nil {
 						logger.Log.Error("Error sending to Jarvis: %v\n", err)
 					}
 				}()
@@ -329,14 +349,14 @@ func internalServerErrorHandler(next http.Handler) http.Handler {
 	})
 }
 
-func initChi() *chi.Mux {
+func initChi(database db.Database, cfg *config.Config) *chi.Mux {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(logger.RouteBasedUUIDMiddleware)
-	r.Use(internalServerErrorHandler)
-	r.Use(customMiddleware.FeatureFlag(db.DB))
+	r.Use(internalServerErrorHandler(cfg))
+	r.Use(customMiddleware.FeatureFlag(database))
 	cors := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
@@ -345,6 +365,5 @@ func initChi() *chi.Mux {
 		MaxAge:           300,
 	})
 	r.Use(cors.Handler)
-	r.Use(middleware.Timeout(60 * time.Second))
 	return r
 }
@@ -25,12 +25,7 @@ import (
 const defaultAuthURL = "http://auth:9090"
 
 func TestSendEdgeListToJarvis(t *testing.T) {
-	originalURL := config.JarvisUrl
-	originalToken := config.JarvisToken
-	defer func() {
-		config.JarvisUrl = originalURL
-		config.JarvisToken = originalToken
-	}()
+	var jarvis config.JarvisConfig
 
 	tests := []struct {
 		name          string
@@ -69,7 +64,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				}))
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: false,
 		},
@@ -84,7 +79,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				}))
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: false,
 		},
@@ -102,8 +97,8 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 			},
 			setupMock: nil,
 			setupConfig: func() {
-				config.JarvisUrl = ""
-				config.JarvisToken = "test-token"
+				jarvis.URL = ""
+				jarvis.Token = "test-token"
 			},
 			expectedError: false,
 		},
@@ -121,8 +116,8 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 			},
 			setupMock: nil,
 			setupConfig: func() {
-				config.JarvisUrl = "http://test.com"
-				config.JarvisToken = ""
+				jarvis.URL = "http://test.com"
+				jarvis.Token = ""
 			},
 			expectedError: false,
 		},
@@ -148,7 +143,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				}))
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: false,
 		},
@@ -166,8 +161,8 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 			},
 			setupMock: nil,
 			setupConfig: func() {
-				config.JarvisUrl = "http://[::1]:namedport"
-				config.JarvisToken = "test-token"
+				jarvis.URL = "http://[::1]:namedport"
+				jarvis.Token = "test-token"
 			},
 			expectedError: false,
 		},
@@ -191,7 +186,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				return server
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: true,
 		},
@@ -216,7 +211,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				}))
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: true,
 		},
@@ -248,7 +243,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				}))
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: false,
 		},
@@ -269,7 +264,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				}))
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: true,
 		},
@@ -289,7 +284,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				}))
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: false,
 		},
@@ -315,7 +310,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				}))
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: false,
 		},
@@ -337,7 +332,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				}))
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: true,
 		},
@@ -357,7 +352,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				}))
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: false,
 		},
@@ -379,7 +374,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				}))
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: false,
 		},
@@ -399,7 +394,7 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 				}))
 			},
 			setupConfig: func() {
-				config.JarvisToken = "test-token"
+				jarvis.Token = "test-token"
 			},
 			expectedError: true,
 		},
@@ -414,10 +409,10 @@ func TestSendEdgeListToJarvis(t *testing.T) {
 			if tt.setupMock != nil {
 				server := tt.setupMock()
 				defer server.Close()
-				config.JarvisUrl = server.URL
+				jarvis.URL = server.URL
 			}
 
-			err := sendEdgeListToJarvis(tt.edgeList)
+			err := sendEdgeListToJarvis(jarvis, tt.edgeList)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -1234,7 +1229,7 @@ func TestInternalServerErrorHandler(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockLogger.Clear()
-			handler := internalServerErrorHandler(tt.handler)
+			handler := internalServerErrorHandler(config.Defaults())(tt.handler)
 
 			req := httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil)
 			rr := httptest.NewRecorder()
@@ -1253,7 +1248,7 @@ func TestInternalServerErrorHandler(t *testing.T) {
 
 func TestNewRouter(t *testing.T) {
 	t.Run("Basic Router Initialization", func(t *testing.T) {
-		server := NewRouter()
+		server := NewRouter(config.Defaults())
 		assert.NotNil(t, server)
 		assert.NotNil(t, server.Handler)
 		
@@ -1265,12 +1260,15 @@ func TestNewRouter(t *testing.T) {
 		os.Setenv("PORT", "8080")
 		defer os.Setenv("PORT", originalPort)
 
-		server := NewRouter()
+		cfg, err := config.Load("")
+		assert.NoError(t, err)
+
+		server := NewRouter(cfg)
 		assert.Equal(t, ":8080", server.Addr)
 	})
 
 	t.Run("Route Mounting Verification", func(t *testing.T) {
-		server := NewRouter()
+		server := NewRouter(config.Defaults())
 		router := server.Handler.(*chi.Mux)
 
 		routes := []string{
@@ -1303,7 +1301,7 @@ func TestNewRouter(t *testing.T) {
 	})
 
 	t.Run("Public Endpoints Group", func(t *testing.T) {
-		server := NewRouter()
+		server := NewRouter(config.Defaults())
 		router := server.Handler.(*chi.Mux)
 
 		publicEndpoints := []struct {
@@ -1469,7 +1467,7 @@ func TestNewRouter(t *testing.T) {
 	})
 
 	t.Run("Authentication Endpoints Group", func(t *testing.T) {
-		server := NewRouter()
+		server := NewRouter(config.Defaults())
 		router := server.Handler.(*chi.Mux)
 
 		authEndpoints := []struct {
@@ -1527,7 +1525,7 @@ func TestNewRouter(t *testing.T) {
 	})
 
 	t.Run("Error Handler", func(t *testing.T) {
-		server := NewRouter()
+		server := NewRouter(config.Defaults())
 		router := server.Handler.(*chi.Mux)
 
 		router.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
@@ -1542,7 +1540,7 @@ func TestNewRouter(t *testing.T) {
 	})
 
 	t.Run("Feature Flag Middleware", func(t *testing.T) {
-		server := NewRouter()
+		server := NewRouter(config.Defaults())
 		router := server.Handler.(*chi.Mux)
 
 		req := httptest.NewRequest("GET", "/test", nil)
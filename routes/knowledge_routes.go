@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/handlers"
+)
+
+func KnowledgeRoutes() chi.Router {
+	r := chi.NewRouter()
+	knowledgeHandler := handlers.NewKnowledgeHandler(db.DB)
+
+	r.Group(func(r chi.Router) {
+		r.Use(auth.PubKeyContext)
+
+		r.Get("/workspace/{workspace_uuid}", knowledgeHandler.GetWorkspaceKnowledge)
+		r.Post("/workspace/{workspace_uuid}/index", knowledgeHandler.IndexWorkspaceKnowledge)
+	})
+
+	return r
+}
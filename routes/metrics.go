@@ -22,7 +22,7 @@ func MetricsRoutes() chi.Router {
 		r.Post("/bounties", mh.MetricsBounties)
 		r.Post("/bounties/count", mh.MetricsBountiesCount)
 		r.Post("/bounties/providers", mh.MetricsBountiesProviders)
-		r.Post("/csv", handlers.MetricsCsv)
+		r.Post("/csv", mh.MetricsCsv)
 	})
 	return r
 }
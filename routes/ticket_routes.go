@@ -12,6 +12,7 @@ import (
 func TicketRoutes() chi.Router {
 	r := chi.NewRouter()
 	ticketHandler := handlers.NewTicketHandler(http.DefaultClient, db.DB)
+	githubIssueSyncHandler := handlers.NewGithubIssueSyncHandler(db.DB)
 
 	r.Group(func(r chi.Router) {
 		r.Get("/{uuid}", ticketHandler.GetTicket)
@@ -23,23 +24,39 @@ func TicketRoutes() chi.Router {
 		r.Use(auth.CombinedAuthContext)
 
 		r.Get("/feature/{feature_uuid}/phase/{phase_uuid}", ticketHandler.GetTicketsByPhaseUUID)
+		r.Get("/feature/{feature_uuid}/phase/{phase_uuid}/graph", ticketHandler.GetTicketGraph)
+		r.Post("/feature/{feature_uuid}/phase/{phase_uuid}/github/import", githubIssueSyncHandler.ImportGithubIssues)
 		r.Post("/review/send", ticketHandler.PostTicketDataToStakwork)
 		r.Post("/{uuid}", ticketHandler.UpdateTicket)
 		r.Post("/{ticket_group}/sequence", ticketHandler.UpdateTicketSequence)
 		r.Post("/{ticket_uuid}/bounty", ticketHandler.TicketToBounty)
 		r.Post("/bounty/bulk", ticketHandler.TicketsToBounties)
+		r.Post("/bulk", ticketHandler.BulkTicketOperations)
 		r.Delete("/{uuid}", ticketHandler.DeleteTicket)
 		r.Get("/group/{group_uuid}", ticketHandler.GetTicketsByGroup)
+		r.Get("/group/{group_uuid}/revisions", ticketHandler.GetTicketRevisions)
+		r.Get("/group/{group_uuid}/revisions/diff", ticketHandler.DiffTicketRevisions)
+		r.Post("/group/{group_uuid}/revisions/{version}/restore", ticketHandler.RestoreTicketRevision)
+		r.Get("/group/{group_uuid}/comments", ticketHandler.GetTicketComments)
+		r.Post("/group/{group_uuid}/comments", ticketHandler.CreateTicketComment)
+		r.Patch("/comments/{comment_id}", ticketHandler.UpdateTicketComment)
+		r.Delete("/comments/{comment_id}", ticketHandler.DeleteTicketComment)
+		r.Post("/comments/{comment_id}/resolve", ticketHandler.ResolveTicketComment)
 
 		r.Post("/workspace/{workspace_uuid}/draft", ticketHandler.CreateWorkspaceDraftTicket)
 		r.Get("/workspace/{workspace_uuid}/draft/{uuid}", ticketHandler.GetWorkspaceDraftTicket)
 		r.Post("/workspace/{workspace_uuid}/draft/{uuid}", ticketHandler.UpdateWorkspaceDraftTicket)
 		r.Delete("/workspace/{workspace_uuid}/draft/{uuid}", ticketHandler.DeleteWorkspaceDraftTicket)
+		r.Get("/workspace/{workspace_uuid}/workflow", ticketHandler.GetTicketWorkflow)
+		r.Put("/workspace/{workspace_uuid}/workflow", ticketHandler.UpdateTicketWorkflow)
 
 		r.Post("/plan", ticketHandler.CreateTicketPlan)
 		r.Post("/plan/send", ticketHandler.SendTicketPlanToStakwork)
 		r.Get("/plan/{uuid}", ticketHandler.GetTicketPlan)
 		r.Delete("/plan/{uuid}", ticketHandler.DeleteTicketPlan)
+		r.Post("/plan/{uuid}/approve", ticketHandler.ApproveTicketPlan)
+		r.Get("/plan/{uuid}/comments", ticketHandler.GetTicketPlanComments)
+		r.Post("/plan/{uuid}/comments", ticketHandler.CreateTicketPlanComment)
 		r.Get("/plan/feature/{feature_uuid}", ticketHandler.GetTicketPlansByFeature)
 		r.Get("/plan/phase/{phase_uuid}", ticketHandler.GetTicketPlansByPhase)
 		r.Get("/plan/workspace/{workspace_uuid}", ticketHandler.GetTicketPlansByWorkspace)
@@ -44,7 +44,7 @@ func WorkspaceRoutes() chi.Router {
 		r.Post("/mission", workspaceHandlers.UpdateWorkspace)
 		r.Post("/tactics", workspaceHandlers.UpdateWorkspace)
 		r.Post("/schematicurl", workspaceHandlers.UpdateWorkspace)
-		r.Put("/{workspace_uuid}/payments", handlers.UpdateWorkspacePendingPayments)
+		r.Put("/{workspace_uuid}/payments", workspaceHandlers.UpdateWorkspacePendingPayments)
 
 		r.Post("/repositories", workspaceHandlers.CreateOrEditWorkspaceRepository)
 		r.Get("/repositories/{uuid}", workspaceHandlers.GetWorkspaceRepositorByWorkspaceUuid)
@@ -56,6 +56,11 @@ func WorkspaceRoutes() chi.Router {
 
 		r.Get("/{workspace_uuid}/lastwithdrawal", workspaceHandlers.GetLastWithdrawal)
 
+		r.Get("/{workspace_uuid}/templates", workspaceHandlers.GetWorkspaceTemplates)
+		r.Post("/{workspace_uuid}/templates", workspaceHandlers.CreateWorkspaceTemplate)
+		r.Put("/{workspace_uuid}/templates/{id}", workspaceHandlers.UpdateWorkspaceTemplate)
+		r.Delete("/{workspace_uuid}/templates/{id}", workspaceHandlers.DeleteWorkspaceTemplate)
+
 		r.Post("/codegraph", workspaceHandlers.CreateOrEditWorkspaceCodeGraph)
 		r.Get("/codegraph/{uuid}", workspaceHandlers.GetWorkspaceCodeGraphByUUID)
 		r.Get("/{workspace_uuid}/codegraph", workspaceHandlers.GetCodeGraphByWorkspaceUuid)
@@ -0,0 +1,45 @@
+package sse
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff hands out exponentially growing delays with jitter, so clients that
+// failed together do not retry together.
+type Backoff struct {
+	Base    time.Duration
+	Max     time.Duration
+	attempt int
+}
+
+func NewBackoff(base, max time.Duration) *Backoff {
+	return &Backoff{Base: base, Max: max}
+}
+
+// Next returns a delay between half and all of Base * 2^attempts, capped at
+// Max, and counts the attempt.
+func (b *Backoff) Next() time.Duration {
+	delay := b.Base
+	for i := 0; i < b.attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	b.attempt++
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func (b *Backoff) Attempts() int {
+	return b.attempt
+}
+
+func (b *Backoff) Reset() {
+	b.attempt = 0
+}
@@ -0,0 +1,34 @@
+package sse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Run("Grows With Jitter Up To Max", func(t *testing.T) {
+		backoff := NewBackoff(time.Second, 10*time.Second)
+
+		expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+		for i, ceiling := range expected {
+			delay := backoff.Next()
+			assert.GreaterOrEqual(t, delay, ceiling/2, "attempt %d", i)
+			assert.LessOrEqual(t, delay, ceiling, "attempt %d", i)
+		}
+		assert.Equal(t, len(expected), backoff.Attempts())
+	})
+
+	t.Run("Reset Starts Over", func(t *testing.T) {
+		backoff := NewBackoff(time.Second, time.Minute)
+		backoff.Next()
+		backoff.Next()
+		backoff.Next()
+
+		backoff.Reset()
+
+		assert.Equal(t, 0, backoff.Attempts())
+		assert.LessOrEqual(t, backoff.Next(), time.Second)
+	})
+}
@@ -2,7 +2,9 @@ package sse
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -34,6 +36,7 @@ func (r *Registry) Register(client *Client) {
 	r.clients[key] = client
 }
 
+// Unregister stops a client on request, it is not restarted at boot.
 func (r *Registry) Unregister(sseURL, chatID string) bool {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -42,6 +45,7 @@ func (r *Registry) Unregister(sseURL, chatID string) bool {
 	logger.Log.Info("Attempting to unregister client with key: %s", key)
 	
 	if client, exists := r.clients[key]; exists {
+		client.setStatus(db.SSEClientStopped, nil)
 		client.Stop()
 		delete(r.clients, key)
 		return true
@@ -55,29 +59,60 @@ func (r *Registry) Unregister(sseURL, chatID string) bool {
 	return false
 }
 
+// remove drops a client whose loop ended, unless it was already replaced.
+func (r *Registry) remove(client *Client) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := GenerateClientKey(client.ChatID, client.URL)
+	if r.clients[key] == client {
+		delete(r.clients, key)
+	}
+}
+
 type Client struct {
-	URL           string
-	ChatID        string
-	WebhookURL    string
-	LastEventID   string
-	RetryInterval time.Duration
-	Client        *http.Client
-	DB            db.Database
-	stopChan      chan struct{}
-	firstFailTime time.Time
+	URL         string
+	ChatID      string
+	WebhookURL  string
+	LastEventID string
+	// RetryInterval is the first reconnect delay, later ones back off up to
+	// MaxRetryInterval. The server can change it with a retry field.
+	RetryInterval    time.Duration
+	MaxRetryInterval time.Duration
+	// MaxRetryDuration is how long the server may stay unreachable before
+	// the client gives up and is marked failed.
+	MaxRetryDuration time.Duration
+	Client           *http.Client
+	DB               db.Database
+	stopChan         chan struct{}
+	stopOnce         sync.Once
+	ctx              context.Context
+	cancel           context.CancelFunc
+	firstFailTime    time.Time
+	backoff          *Backoff
+	mu               sync.Mutex
+	status           db.SSEClientStatus
+	retryCount       int
+	lastError        string
 }
 
 func NewClient(sseURL string, chatID string, webhookURL string, database db.Database) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Client{
-		URL:           sseURL,
-		ChatID:        chatID,
-		WebhookURL:    webhookURL,
-		RetryInterval: 3 * time.Second,
+		URL:              sseURL,
+		ChatID:           chatID,
+		WebhookURL:       webhookURL,
+		RetryInterval:    3 * time.Second,
+		MaxRetryInterval: 5 * time.Minute,
+		MaxRetryDuration: 24 * time.Hour,
 		Client: &http.Client{
 			Timeout: 0,
 		},
 		DB:       database,
 		stopChan: make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+		status:   db.SSEClientConnecting,
 	}
 }
 
@@ -85,54 +120,140 @@ func (c *Client) Start() {
 
 	ClientRegistry.Register(c)
 
-	go func() {
-		defer func() {
+	if c.LastEventID == "" {
+		if state, err := c.DB.GetSSEClientState(c.ChatID, c.URL); err != nil {
+			logger.Log.Error("[ChatID: %s] Failed to load SSE client state: %v", c.ChatID, err)
+		} else if state != nil {
+			c.LastEventID = state.LastEventID
+		}
+	}
+
+	c.backoff = NewBackoff(c.RetryInterval, c.MaxRetryInterval)
+	c.setStatus(db.SSEClientConnecting, nil)
 
-			ClientRegistry.Unregister(c.URL, c.ChatID)
-		}()
+	go c.run()
+}
 
-		for {
-			select {
-			case <-c.stopChan:
-				logger.Log.Info("[ChatID: %s] SSE client stopped", c.ChatID)
-				return
-			default:
-				err := c.connect()
-				if err != nil {
-					if c.firstFailTime.IsZero() {
-						c.firstFailTime = time.Now()
-					} else if time.Since(c.firstFailTime) > 60*time.Minute {
-						logger.Log.Error("[ChatID: %s] Server unreachable for 60 minutes, stopping client", c.ChatID)
-						return
-					}
+func (c *Client) run() {
+	defer ClientRegistry.remove(c)
 
-					logger.Log.Error("[ChatID: %s] Connection error: %v. Retrying in %v...", c.ChatID, err, c.RetryInterval)
-					time.Sleep(c.RetryInterval)
-					continue
-				}
+	for {
+		select {
+		case <-c.stopChan:
+			logger.Log.Info("[ChatID: %s] SSE client stopped", c.ChatID)
+			return
+		default:
+		}
 
-				c.firstFailTime = time.Time{}
+		err := c.connect()
+		if c.ctx != nil && c.ctx.Err() != nil {
+			logger.Log.Info("[ChatID: %s] SSE client stopped", c.ChatID)
+			return
+		}
 
-				time.Sleep(c.RetryInterval)
+		if err != nil {
+			if c.firstFailTime.IsZero() {
+				c.firstFailTime = time.Now()
+			} else if time.Since(c.firstFailTime) > c.MaxRetryDuration {
+				logger.Log.Error("[ChatID: %s] Server unreachable for %v, stopping client", c.ChatID, c.MaxRetryDuration)
+				c.setStatus(db.SSEClientFailed, err)
+				return
 			}
+			c.setStatus(db.SSEClientRetrying, err)
+		} else {
+			// the server closed a healthy stream, reconnect from a fresh backoff
+			c.backoff.Reset()
+		}
+
+		delay := c.backoff.Next()
+		if err != nil {
+			logger.Log.Error("[ChatID: %s] Connection error: %v. Retrying in %v...", c.ChatID, err, delay)
+		}
+
+		select {
+		case <-c.stopChan:
+			logger.Log.Info("[ChatID: %s] SSE client stopped", c.ChatID)
+			return
+		case <-time.After(delay):
 		}
-	}()
+	}
 }
 
 func (c *Client) Stop() {
-	close(c.stopChan)
+	c.stopOnce.Do(func() {
+		close(c.stopChan)
+		if c.cancel != nil {
+			c.cancel()
+		}
+	})
+}
+
+// SaveState persists the last event id and status so a restarted client can
+// resume.
+func (c *Client) SaveState() error {
+	return c.DB.SaveSSEClientState(c.State())
+}
+
+func (c *Client) State() db.SSEClientState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return db.SSEClientState{
+		ChatID:      c.ChatID,
+		URL:         c.URL,
+		WebhookURL:  c.WebhookURL,
+		LastEventID: c.LastEventID,
+		Status:      c.status,
+		RetryCount:  c.retryCount,
+		LastError:   c.lastError,
+	}
+}
+
+func (c *Client) setStatus(status db.SSEClientStatus, err error) {
+	c.mu.Lock()
+	c.status = status
+	switch {
+	case status == db.SSEClientConnected:
+		c.retryCount = 0
+		c.lastError = ""
+	case err != nil:
+		c.lastError = err.Error()
+		if status == db.SSEClientRetrying {
+			c.retryCount++
+		}
+	}
+	c.mu.Unlock()
+
+	if saveErr := c.SaveState(); saveErr != nil {
+		logger.Log.Error("[ChatID: %s] Failed to persist SSE client state: %v", c.ChatID, saveErr)
+	}
+}
+
+func (c *Client) setLastEventID(id string) {
+	c.mu.Lock()
+	c.LastEventID = id
+	c.mu.Unlock()
+
+	if err := c.SaveState(); err != nil {
+		logger.Log.Error("[ChatID: %s] Failed to persist last event id: %v", c.ChatID, err)
+	}
 }
 
 func (c *Client) connect() error {
-	req, err := http.NewRequest("GET", c.URL, nil)
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.URL, nil)
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
 
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
-	if c.LastEventID != "" {
-		req.Header.Set("Last-Event-ID", c.LastEventID)
+	if lastEventID := c.State().LastEventID; lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
 	}
 
 	logger.Log.Info("[ChatID: %s] Connecting to SSE endpoint: %s", c.ChatID, c.URL)
@@ -152,6 +273,9 @@ func (c *Client) connect() error {
 	}
 
 	logger.Log.Info("[ChatID: %s] Connected successfully, waiting for events...", c.ChatID, c.URL)
+	c.firstFailTime = time.Time{}
+	c.backoff.Reset()
+	c.setStatus(db.SSEClientConnected, nil)
 	return c.processEvents(resp)
 }
 
@@ -179,7 +303,7 @@ func (c *Client) processEvents(resp *http.Response) error {
 					}
 
 					if eventData["id"] != "" {
-						c.LastEventID = eventData["id"]
+						c.setLastEventID(eventData["id"])
 					}
 
 					eventData = map[string]string{
@@ -210,6 +334,7 @@ func (c *Client) processEvents(resp *http.Response) error {
 				retryStr := strings.TrimSpace(strings.TrimPrefix(line, "retry:"))
 				if retry, err := time.ParseDuration(retryStr + "ms"); err == nil {
 					c.RetryInterval = retry
+					c.backoff.Base = retry
 				}
 			}
 		}
@@ -246,6 +371,10 @@ func (c *Client) storeEvent(eventData map[string]string) error {
 	}
 
 	messageLog, err := c.DB.CreateSSEMessageLog(parsedEvent, c.ChatID, c.URL, c.WebhookURL)
+	if errors.Is(err, db.ErrDuplicateSSEEvent) {
+		logger.Log.Info("[ChatID: %s] Skipped SSE event %s, already stored", c.ChatID, eventData["id"])
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create SSE message log: %w", err)
 	}
@@ -254,6 +383,36 @@ func (c *Client) storeEvent(eventData map[string]string) error {
 	return nil
 }
 
+// Shutdown stops every registered client after persisting its last event id.
+// It returns the number of clients stopped.
+func (r *Registry) Shutdown(ctx context.Context) (int, error) {
+	r.mutex.Lock()
+	clients := make([]*Client, 0, len(r.clients))
+	for key, client := range r.clients {
+		clients = append(clients, client)
+		delete(r.clients, key)
+	}
+	r.mutex.Unlock()
+
+	var failed []string
+	for _, client := range clients {
+		if err := client.SaveState(); err != nil {
+			logger.Log.Error("[ChatID: %s] Failed to persist SSE client state: %v", client.ChatID, err)
+			failed = append(failed, client.ChatID)
+		}
+		client.Stop()
+
+		if ctx.Err() != nil {
+			return len(clients), ctx.Err()
+		}
+	}
+
+	if len(failed) > 0 {
+		return len(clients), fmt.Errorf("failed to persist state for chats %v", failed)
+	}
+	return len(clients), nil
+}
+
 func (r *Registry) HasClient(sseURL, chatID string) bool {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -264,3 +423,27 @@ func (r *Registry) HasClient(sseURL, chatID string) bool {
 
 	return exists
 }
+
+// RestoreClients restarts every client that was running when the process last
+// stopped, each resuming after its last stored event. It returns the number
+// of clients started.
+func RestoreClients(database db.Database) (int, error) {
+	states, err := database.GetResumableSSEClientStates()
+	if err != nil {
+		return 0, err
+	}
+
+	started := 0
+	for _, state := range states {
+		if ClientRegistry.HasClient(state.URL, state.ChatID) {
+			continue
+		}
+
+		client := NewClient(state.URL, state.ChatID, state.WebhookURL, database)
+		client.LastEventID = state.LastEventID
+		client.Start()
+		started++
+	}
+
+	return started, nil
+}
@@ -0,0 +1,87 @@
+package sse
+
+import (
+	"context"
+	"sync"
+
+	"github.com/stakwork/sphinx-tribes/db"
+)
+
+// Streams wakes up browser streams of a chat when one of its events is
+// recorded on this replica. Events recorded by other replicas are picked up
+// when the stream polls the event log.
+var Streams = NewBroker()
+
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+	closed      bool
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[string]map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe returns a channel that receives a signal whenever an event of the
+// chat is recorded. The channel is closed by unsubscribe or on shutdown.
+func (b *Broker) Subscribe(chatID string) (<-chan struct{}, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan struct{}, 1)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if b.subscribers[chatID] == nil {
+		b.subscribers[chatID] = make(map[chan struct{}]struct{})
+	}
+	b.subscribers[chatID][ch] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[chatID][ch]; !ok {
+			return
+		}
+		delete(b.subscribers[chatID], ch)
+		if len(b.subscribers[chatID]) == 0 {
+			delete(b.subscribers, chatID)
+		}
+		close(ch)
+	}
+	return ch, unsubscribe
+}
+
+func (b *Broker) Notify(event db.ChatEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[event.ChatID] {
+		// a pending signal already makes the stream read the log
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Shutdown ends every open stream. It returns the number of streams closed.
+func (b *Broker) Shutdown(ctx context.Context) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	closed := 0
+	for chatID, subs := range b.subscribers {
+		for ch := range subs {
+			close(ch)
+			closed++
+		}
+		delete(b.subscribers, chatID)
+	}
+	b.closed = true
+	return closed, nil
+}
@@ -0,0 +1,59 @@
+package sse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroker(t *testing.T) {
+	t.Run("Notifies Subscribers Of The Chat", func(t *testing.T) {
+		broker := NewBroker()
+		notify, unsubscribe := broker.Subscribe("chat-1")
+		defer unsubscribe()
+		other, unsubscribeOther := broker.Subscribe("chat-2")
+		defer unsubscribeOther()
+
+		broker.Notify(db.ChatEvent{ID: 1, ChatID: "chat-1"})
+		broker.Notify(db.ChatEvent{ID: 2, ChatID: "chat-1"})
+
+		assert.Len(t, notify, 1)
+		assert.Len(t, other, 0)
+	})
+
+	t.Run("Unsubscribe Closes The Channel", func(t *testing.T) {
+		broker := NewBroker()
+		notify, unsubscribe := broker.Subscribe("chat-1")
+
+		unsubscribe()
+		unsubscribe()
+
+		_, open := <-notify
+		assert.False(t, open)
+		broker.Notify(db.ChatEvent{ID: 1, ChatID: "chat-1"})
+	})
+
+	t.Run("Shutdown Ends Every Stream", func(t *testing.T) {
+		broker := NewBroker()
+		first, unsubscribeFirst := broker.Subscribe("chat-1")
+		second, unsubscribeSecond := broker.Subscribe("chat-2")
+
+		closed, err := broker.Shutdown(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, closed)
+		_, open := <-first
+		assert.False(t, open)
+		_, open = <-second
+		assert.False(t, open)
+
+		unsubscribeFirst()
+		unsubscribeSecond()
+
+		late, _ := broker.Subscribe("chat-3")
+		_, open = <-late
+		assert.False(t, open)
+	})
+}
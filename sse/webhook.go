@@ -0,0 +1,169 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// Webhooks delivers stored SSE events to the webhook of their chat.
+var Webhooks = NewWebhookSender()
+
+type WebhookSender struct {
+	Client      *http.Client
+	MaxEvents   int
+	MaxBytes    int
+	MaxAttempts int
+	RetryBase   time.Duration
+	RetryMax    time.Duration
+}
+
+func NewWebhookSender() *WebhookSender {
+	return &WebhookSender{
+		Client:      &http.Client{Timeout: 30 * time.Second},
+		MaxEvents:   100,
+		MaxBytes:    512 * 1024,
+		MaxAttempts: 4,
+		RetryBase:   time.Second,
+		RetryMax:    30 * time.Second,
+	}
+}
+
+// webhookError is a response the webhook will keep giving, so it is not retried.
+type webhookError struct {
+	status int
+	body   string
+}
+
+func (e *webhookError) Error() string {
+	return fmt.Sprintf("webhook returned status %d: %s", e.status, e.body)
+}
+
+// Deliver sends every new event of the chat to webhookURL, oldest first, in
+// batches bounded by MaxEvents and MaxBytes. Each batch is retried with
+// backoff and marked sent once accepted. Delivery stops at the first batch
+// that keeps failing so the webhook never sees events out of order. It
+// returns the number of events sent.
+func (s *WebhookSender) Deliver(ctx context.Context, database db.Database, chatID, webhookURL string) (int, error) {
+	logs, err := database.GetNewSSEMessageLogsByChatID(chatID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve unsent events: %w", err)
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].CreatedAt.Before(logs[j].CreatedAt)
+	})
+
+	sent := 0
+	for _, batch := range s.batches(logs) {
+		if err := s.sendBatch(ctx, chatID, webhookURL, batch); err != nil {
+			return sent, err
+		}
+
+		ids := make([]uuid.UUID, len(batch))
+		for i, messageLog := range batch {
+			ids[i] = messageLog.ID
+		}
+		if err := database.UpdateSSEMessageLogStatusBatch(ids); err != nil {
+			return sent, fmt.Errorf("failed to mark events as sent: %w", err)
+		}
+		sent += len(batch)
+	}
+
+	return sent, nil
+}
+
+// batches splits logs into batches of at most MaxEvents events and MaxBytes
+// of encoded events. An event larger than MaxBytes is sent on its own.
+func (s *WebhookSender) batches(logs []db.SSEMessageLog) [][]db.SSEMessageLog {
+	var batches [][]db.SSEMessageLog
+	var current []db.SSEMessageLog
+	currentBytes := 0
+
+	for _, messageLog := range logs {
+		encoded, _ := json.Marshal(map[string]interface{}{"event": messageLog.Event})
+		size := len(encoded)
+
+		if len(current) > 0 && (len(current) >= s.MaxEvents || currentBytes+size > s.MaxBytes) {
+			batches = append(batches, current)
+			current = nil
+			currentBytes = 0
+		}
+		current = append(current, messageLog)
+		currentBytes += size
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+
+	return batches
+}
+
+func (s *WebhookSender) sendBatch(ctx context.Context, chatID, webhookURL string, batch []db.SSEMessageLog) error {
+	events := make([]map[string]interface{}, len(batch))
+	for i, messageLog := range batch {
+		events[i] = map[string]interface{}{
+			"event": messageLog.Event,
+		}
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"chatID":  chatID,
+		"events":  events,
+		"sse_url": batch[0].From,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	backoff := NewBackoff(s.RetryBase, s.RetryMax)
+	for {
+		err = s.post(ctx, webhookURL, payload)
+		if err == nil {
+			return nil
+		}
+		if _, permanent := err.(*webhookError); permanent || backoff.Attempts()+1 >= s.MaxAttempts {
+			return err
+		}
+
+		delay := backoff.Next()
+		logger.Log.Error("[ChatID: %s] Webhook delivery failed: %v. Retrying in %v...", chatID, err, delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (s *WebhookSender) post(ctx context.Context, webhookURL string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return &webhookError{body: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return &webhookError{status: resp.StatusCode, body: string(body)}
+}
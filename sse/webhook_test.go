@@ -0,0 +1,156 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	datamocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestLogs(n int, content string) []db.SSEMessageLog {
+	start := time.Now().Add(-time.Hour)
+	logs := make([]db.SSEMessageLog, n)
+	for i := range logs {
+		// newest first, like GetNewSSEMessageLogsByChatID
+		logs[i] = db.SSEMessageLog{
+			ID:        uuid.New(),
+			ChatID:    "chat-1",
+			From:      "https://source.example.com/sse",
+			Event:     db.PropertyMap{"seq": float64(n - i), "content": content},
+			CreatedAt: start.Add(time.Duration(n-i) * time.Second),
+		}
+	}
+	return logs
+}
+
+func newTestSender() *WebhookSender {
+	sender := NewWebhookSender()
+	sender.RetryBase = time.Millisecond
+	sender.RetryMax = time.Millisecond
+	return sender
+}
+
+func TestWebhookSenderDeliver(t *testing.T) {
+	t.Run("Sends Batches Oldest First", func(t *testing.T) {
+		var received [][]float64
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload struct {
+				ChatID string `json:"chatID"`
+				Events []struct {
+					Event map[string]interface{} `json:"event"`
+				} `json:"events"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			assert.Equal(t, "chat-1", payload.ChatID)
+
+			var seqs []float64
+			for _, event := range payload.Events {
+				seqs = append(seqs, event.Event["seq"].(float64))
+			}
+			received = append(received, seqs)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetNewSSEMessageLogsByChatID", "chat-1").Return(newTestLogs(5, "x"), nil).Once()
+		mockDb.On("UpdateSSEMessageLogStatusBatch", mock.Anything).Return(nil).Times(3)
+
+		sender := newTestSender()
+		sender.MaxEvents = 2
+		sent, err := sender.Deliver(context.Background(), mockDb, "chat-1", ts.URL)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, sent)
+		assert.Equal(t, [][]float64{{1, 2}, {3, 4}, {5}}, received)
+	})
+
+	t.Run("Splits Batches By Size", func(t *testing.T) {
+		sender := newTestSender()
+		sender.MaxBytes = 2500
+
+		batches := sender.batches(newTestLogs(5, strings.Repeat("a", 1000)))
+
+		assert.Len(t, batches, 3)
+		assert.Len(t, batches[0], 2)
+		assert.Len(t, batches[2], 1)
+	})
+
+	t.Run("Oversized Event Is Sent Alone", func(t *testing.T) {
+		sender := newTestSender()
+		sender.MaxBytes = 100
+
+		batches := sender.batches(newTestLogs(2, strings.Repeat("a", 1000)))
+
+		assert.Len(t, batches, 2)
+	})
+
+	t.Run("Retries Server Errors", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetNewSSEMessageLogsByChatID", "chat-1").Return(newTestLogs(1, "x"), nil).Once()
+		mockDb.On("UpdateSSEMessageLogStatusBatch", mock.Anything).Return(nil).Once()
+
+		sent, err := newTestSender().Deliver(context.Background(), mockDb, "chat-1", ts.URL)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Stops After Max Attempts", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer ts.Close()
+
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetNewSSEMessageLogsByChatID", "chat-1").Return(newTestLogs(3, "x"), nil).Once()
+
+		sender := newTestSender()
+		sender.MaxEvents = 1
+		sent, err := sender.Deliver(context.Background(), mockDb, "chat-1", ts.URL)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, sent)
+		assert.Equal(t, int32(sender.MaxAttempts), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Does Not Retry Client Errors", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer ts.Close()
+
+		mockDb := datamocks.NewDatabase(t)
+		mockDb.On("GetNewSSEMessageLogsByChatID", "chat-1").Return(newTestLogs(1, "x"), nil).Once()
+
+		_, err := newTestSender().Deliver(context.Background(), mockDb, "chat-1", ts.URL)
+
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
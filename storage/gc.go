@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+const gcPageSize = 100
+
+// Assets is the part of the database the collector needs.
+type Assets interface {
+	ListFileAssets(params db.ListFileAssetsParams) ([]db.FileAsset, int64, error)
+	UpdateFileAsset(asset *db.FileAsset) error
+	UpdateFileAssetReference(id uint) error
+	IsFileAssetReferenced(asset db.FileAsset) (bool, error)
+	DeleteFileAsset(id uint) error
+}
+
+// Collector reclaims space from uploads nobody refers to. Files that have not
+// been referenced for ArchiveAfter are archived, and archived files are
+// deleted once they go unreferenced for DeleteAfter more. Referencing an
+// archived file makes it active again. Files that chat messages still attach
+// or tag are never collected and count as referenced on every run.
+type Collector struct {
+	DB           Assets
+	Store        BlobStore
+	ArchiveAfter time.Duration
+	DeleteAfter  time.Duration
+	Now          func() time.Time
+}
+
+func (c *Collector) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// Run archives and deletes the files that are due and reports how many of
+// each it handled. It keeps going past single failures and returns the
+// first one.
+func (c *Collector) Run(ctx context.Context) (archived int, deleted int, err error) {
+	now := c.now()
+
+	toDelete, err := c.candidates(db.ArchivedFileStatus, now.Add(-(c.ArchiveAfter + c.DeleteAfter)))
+	if err != nil {
+		return 0, 0, err
+	}
+	toArchive, err := c.candidates(db.ActiveFileStatus, now.Add(-c.ArchiveAfter))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var firstErr error
+	fail := func(e error) {
+		logger.Log.Error("file storage gc: %v", e)
+		if firstErr == nil {
+			firstErr = e
+		}
+	}
+
+	for _, asset := range toDelete {
+		if inUse, e := c.inUse(asset); e != nil || inUse {
+			if e != nil {
+				fail(e)
+			}
+			continue
+		}
+		if asset.StorageKey != "" && c.Store != nil {
+			if e := c.Store.Delete(ctx, asset.StorageKey); e != nil {
+				fail(fmt.Errorf("failed to delete blob of file %d: %w", asset.ID, e))
+				continue
+			}
+		}
+		if e := c.DB.DeleteFileAsset(asset.ID); e != nil {
+			fail(fmt.Errorf("failed to delete file %d: %w", asset.ID, e))
+			continue
+		}
+		deleted++
+	}
+
+	for i := range toArchive {
+		asset := toArchive[i]
+		if inUse, e := c.inUse(asset); e != nil || inUse {
+			if e != nil {
+				fail(e)
+			}
+			continue
+		}
+		asset.Status = db.ArchivedFileStatus
+		if e := c.DB.UpdateFileAsset(&asset); e != nil {
+			fail(fmt.Errorf("failed to archive file %d: %w", asset.ID, e))
+			continue
+		}
+		archived++
+	}
+
+	return archived, deleted, firstErr
+}
+
+// inUse reports whether a message still refers to the file, and marks the
+// file as referenced when one does so it is not picked up again.
+func (c *Collector) inUse(asset db.FileAsset) (bool, error) {
+	referenced, err := c.DB.IsFileAssetReferenced(asset)
+	if err != nil {
+		return false, fmt.Errorf("failed to check references of file %d: %w", asset.ID, err)
+	}
+	if !referenced {
+		return false, nil
+	}
+	if err := c.DB.UpdateFileAssetReference(asset.ID); err != nil {
+		return true, fmt.Errorf("failed to update reference of file %d: %w", asset.ID, err)
+	}
+	return true, nil
+}
+
+// candidates lists every file with the status that was last referenced
+// before the cutoff. Everything is listed before anything changes so paging
+// is not thrown off by the updates.
+func (c *Collector) candidates(status db.FileStatus, cutoff time.Time) ([]db.FileAsset, error) {
+	var assets []db.FileAsset
+	for page := 1; ; page++ {
+		batch, _, err := c.DB.ListFileAssets(db.ListFileAssetsParams{
+			Status:             &status,
+			LastAccessedBefore: &cutoff,
+			Page:               page,
+			PageSize:           gcPageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s files: %w", status, err)
+		}
+		assets = append(assets, batch...)
+		if len(batch) < gcPageSize {
+			return assets, nil
+		}
+	}
+}
+
+// RunCollector runs a collection with the store and the retention of cfg.
+func RunCollector(cfg config.StorageConfig) {
+	if Files == nil {
+		return
+	}
+	collector := &Collector{
+		DB:           db.DB,
+		Store:        Files,
+		ArchiveAfter: time.Duration(cfg.ArchiveAfterDays) * 24 * time.Hour,
+		DeleteAfter:  time.Duration(cfg.DeleteAfterDays) * 24 * time.Hour,
+	}
+	archived, deleted, err := collector.Run(context.Background())
+	if err != nil {
+		logger.Log.Error("file storage gc finished with errors: %v", err)
+	}
+	logger.Log.Info("file storage gc archived %d and deleted %d files", archived, deleted)
+}
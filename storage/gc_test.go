@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	listing := func(status db.FileStatus, cutoff time.Time) interface{} {
+		return mock.MatchedBy(func(params db.ListFileAssetsParams) bool {
+			return params.Status != nil && *params.Status == status &&
+				params.LastAccessedBefore != nil && params.LastAccessedBefore.Equal(cutoff)
+		})
+	}
+
+	newCollector := func(t *testing.T) (*Collector, *mocks.Database, *LocalStore) {
+		store, err := NewLocalStore(t.TempDir(), "", []byte("secret"))
+		require.NoError(t, err)
+		mockDb := mocks.NewDatabase(t)
+		mockDb.On("IsFileAssetReferenced", mock.AnythingOfType("db.FileAsset")).Return(false, nil).Maybe()
+		return &Collector{
+			DB:           mockDb,
+			Store:        store,
+			ArchiveAfter: 90 * 24 * time.Hour,
+			DeleteAfter:  30 * 24 * time.Hour,
+			Now:          func() time.Time { return now },
+		}, mockDb, store
+	}
+
+	t.Run("Archives Then Deletes", func(t *testing.T) {
+		collector, mockDb, store := newCollector(t)
+		require.NoError(t, store.Put(ctx, "chat/old.pdf", strings.NewReader("old"), 3, "application/pdf"))
+
+		stale := db.FileAsset{ID: 1, Status: db.ActiveFileStatus}
+		expired := db.FileAsset{ID: 2, Status: db.ArchivedFileStatus, StorageKey: "chat/old.pdf"}
+		legacy := db.FileAsset{ID: 3, Status: db.ArchivedFileStatus}
+
+		mockDb.On("ListFileAssets", listing(db.ArchivedFileStatus, now.Add(-120*24*time.Hour))).
+			Return([]db.FileAsset{expired, legacy}, int64(2), nil).Once()
+		mockDb.On("ListFileAssets", listing(db.ActiveFileStatus, now.Add(-90*24*time.Hour))).
+			Return([]db.FileAsset{stale}, int64(1), nil).Once()
+		mockDb.On("DeleteFileAsset", uint(2)).Return(nil).Once()
+		mockDb.On("DeleteFileAsset", uint(3)).Return(nil).Once()
+		mockDb.On("UpdateFileAsset", mock.MatchedBy(func(asset *db.FileAsset) bool {
+			return asset.ID == 1 && asset.Status == db.ArchivedFileStatus
+		})).Return(nil).Once()
+
+		archived, deleted, err := collector.Run(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, archived)
+		assert.Equal(t, 2, deleted)
+		_, err = store.Open(ctx, "chat/old.pdf")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Keeps Files Messages Refer To", func(t *testing.T) {
+		store, err := NewLocalStore(t.TempDir(), "", []byte("secret"))
+		require.NoError(t, err)
+		mockDb := mocks.NewDatabase(t)
+		collector := &Collector{
+			DB:           mockDb,
+			Store:        store,
+			ArchiveAfter: 90 * 24 * time.Hour,
+			DeleteAfter:  30 * 24 * time.Hour,
+			Now:          func() time.Time { return now },
+		}
+		require.NoError(t, store.Put(ctx, "chat/attached.pdf", strings.NewReader("pdf"), 3, "application/pdf"))
+
+		attached := db.FileAsset{ID: 1, Status: db.ArchivedFileStatus, StorageKey: "chat/attached.pdf"}
+		tagged := db.FileAsset{ID: 2, Status: db.ActiveFileStatus}
+
+		mockDb.On("ListFileAssets", listing(db.ArchivedFileStatus, now.Add(-120*24*time.Hour))).
+			Return([]db.FileAsset{attached}, int64(1), nil).Once()
+		mockDb.On("ListFileAssets", listing(db.ActiveFileStatus, now.Add(-90*24*time.Hour))).
+			Return([]db.FileAsset{tagged}, int64(1), nil).Once()
+		mockDb.On("IsFileAssetReferenced", attached).Return(true, nil).Once()
+		mockDb.On("IsFileAssetReferenced", tagged).Return(true, nil).Once()
+		mockDb.On("UpdateFileAssetReference", uint(1)).Return(nil).Once()
+		mockDb.On("UpdateFileAssetReference", uint(2)).Return(nil).Once()
+
+		archived, deleted, err := collector.Run(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, archived)
+		assert.Equal(t, 0, deleted)
+		_, err = store.Open(ctx, "chat/attached.pdf")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Pages Through Candidates", func(t *testing.T) {
+		collector, mockDb, _ := newCollector(t)
+
+		full := make([]db.FileAsset, gcPageSize)
+		for i := range full {
+			full[i] = db.FileAsset{ID: uint(i + 1)}
+		}
+		mockDb.On("ListFileAssets", mock.MatchedBy(func(params db.ListFileAssetsParams) bool {
+			return *params.Status == db.ArchivedFileStatus
+		})).Return([]db.FileAsset{}, int64(0), nil).Once()
+		mockDb.On("ListFileAssets", mock.MatchedBy(func(params db.ListFileAssetsParams) bool {
+			return *params.Status == db.ActiveFileStatus && params.Page == 1
+		})).Return(full, int64(gcPageSize+1), nil).Once()
+		mockDb.On("ListFileAssets", mock.MatchedBy(func(params db.ListFileAssetsParams) bool {
+			return *params.Status == db.ActiveFileStatus && params.Page == 2
+		})).Return([]db.FileAsset{{ID: 500}}, int64(gcPageSize+1), nil).Once()
+		mockDb.On("UpdateFileAsset", mock.Anything).Return(nil).Times(gcPageSize + 1)
+
+		archived, deleted, err := collector.Run(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, gcPageSize+1, archived)
+		assert.Equal(t, 0, deleted)
+	})
+
+	t.Run("Keeps Going After A Failure", func(t *testing.T) {
+		collector, mockDb, _ := newCollector(t)
+
+		mockDb.On("ListFileAssets", mock.MatchedBy(func(params db.ListFileAssetsParams) bool {
+			return *params.Status == db.ArchivedFileStatus
+		})).Return([]db.FileAsset{{ID: 1}, {ID: 2}}, int64(2), nil).Once()
+		mockDb.On("ListFileAssets", mock.MatchedBy(func(params db.ListFileAssetsParams) bool {
+			return *params.Status == db.ActiveFileStatus
+		})).Return([]db.FileAsset{}, int64(0), nil).Once()
+		mockDb.On("DeleteFileAsset", uint(1)).Return(errors.New("boom")).Once()
+		mockDb.On("DeleteFileAsset", uint(2)).Return(nil).Once()
+
+		_, deleted, err := collector.Run(ctx)
+
+		assert.ErrorContains(t, err, "boom")
+		assert.Equal(t, 1, deleted)
+	})
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir(), "https://api.example.com/hivechat/file/signed", []byte("secret"))
+	require.NoError(t, err)
+
+	t.Run("Put, Open And Delete", func(t *testing.T) {
+		err := store.Put(ctx, "chat/notes.txt", strings.NewReader("hello"), 5, "text/plain")
+		assert.NoError(t, err)
+
+		blob, err := store.Open(ctx, "chat/notes.txt")
+		require.NoError(t, err)
+		content, _ := io.ReadAll(blob)
+		blob.Close()
+		assert.Equal(t, "hello", string(content))
+
+		assert.NoError(t, store.Delete(ctx, "chat/notes.txt"))
+		_, err = store.Open(ctx, "chat/notes.txt")
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, store.Delete(ctx, "chat/notes.txt"))
+	})
+
+	t.Run("Rejects Keys Outside The Store", func(t *testing.T) {
+		err := store.Put(ctx, "../escape.txt", strings.NewReader("x"), 1, "text/plain")
+		assert.Error(t, err)
+
+		_, err = store.Open(ctx, "chat/../../escape.txt")
+		assert.Error(t, err)
+	})
+
+	t.Run("Signed URL", func(t *testing.T) {
+		signed, err := store.SignedURL(ctx, "chat/notes.txt", time.Minute)
+		require.NoError(t, err)
+
+		parsed, err := url.Parse(signed)
+		require.NoError(t, err)
+		assert.Equal(t, "/hivechat/file/signed/chat/notes.txt", parsed.Path)
+
+		query := parsed.Query()
+		assert.NoError(t, store.Verify("chat/notes.txt", query.Get("expires"), query.Get("signature")))
+		assert.EqualError(t, store.Verify("chat/other.txt", query.Get("expires"), query.Get("signature")), "invalid signature")
+		assert.EqualError(t, store.Verify("chat/notes.txt", "not-a-time", query.Get("signature")), "invalid expiry")
+	})
+
+	t.Run("Expired Signed URL", func(t *testing.T) {
+		signed, err := store.SignedURL(ctx, "chat/notes.txt", -time.Minute)
+		require.NoError(t, err)
+
+		parsed, _ := url.Parse(signed)
+		query := parsed.Query()
+		assert.EqualError(t, store.Verify("chat/notes.txt", query.Get("expires"), query.Get("signature")), "signed URL expired")
+	})
+
+	t.Run("Requires A Signing Key", func(t *testing.T) {
+		_, err := NewLocalStore(t.TempDir(), "", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestCleanKey(t *testing.T) {
+	for _, key := range []string{"", "../x", "a/../../x", "/abs", "a//b", "a\\b"} {
+		_, err := CleanKey(key)
+		assert.Error(t, err, key)
+	}
+
+	key, err := CleanKey("chat/file.pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, "chat/file.pdf", key)
+}
@@ -0,0 +1,76 @@
+// Package storage keeps the files uploaded to chats in a pluggable blob
+// store. The local driver writes to disk and serves signed URLs through the
+// API; the s3 driver works with AWS S3 and S3 compatible services like MinIO.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/config"
+)
+
+// ErrNotFound is returned when a key has no blob.
+var ErrNotFound = errors.New("blob not found")
+
+type BlobStore interface {
+	// Put stores size bytes from r under key, replacing any previous blob.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the blob stored under key.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key. Deleting a missing key is not
+	// an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that downloads the blob without other
+	// credentials until it expires.
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// SignedPath is where the API serves blobs of the local driver.
+const SignedPath = "/hivechat/file/signed"
+
+// Files is the store used for chat uploads, set up by Init.
+var Files BlobStore
+
+// Init creates the store selected by the configuration and makes it the
+// store for chat uploads.
+func Init(cfg *config.Config) error {
+	store, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	Files = store
+	return nil
+}
+
+// New creates the store selected by the configuration. The signed URLs of the
+// local driver are served by this API, under the configured host.
+func New(cfg *config.Config) (BlobStore, error) {
+	switch cfg.Storage.Driver {
+	case "s3":
+		return NewS3Store(context.Background(), cfg.Storage)
+	case "local", "":
+		signingKey := cfg.Storage.SigningKey
+		if signingKey == "" {
+			signingKey = cfg.Auth.JwtKey
+		}
+		return NewLocalStore(cfg.Storage.LocalDir, strings.TrimSuffix(cfg.Server.Host, "/")+SignedPath, []byte(signingKey))
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
+
+// CleanKey rejects keys that are empty or could escape the store, and
+// returns the key in its canonical form.
+func CleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != key || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,58 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// Envelope carries a message for a websocket session that may be connected to
+// another replica.
+type Envelope struct {
+	Origin    string          `json:"origin"`
+	SessionID string          `json:"session_id"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// Bus fans messages out to every replica. Each replica delivers the envelopes
+// for sessions it holds a connection for and ignores the rest.
+type Bus interface {
+	Publish(ctx context.Context, envelope Envelope) error
+	Subscribe(handler func(Envelope)) error
+	Close() error
+}
+
+// LocalBus is an in-process Bus for single node deployments and tests.
+type LocalBus struct {
+	mu       sync.RWMutex
+	handlers []func(Envelope)
+}
+
+func NewLocalBus() *LocalBus {
+	return &LocalBus{}
+}
+
+func (b *LocalBus) Publish(ctx context.Context, envelope Envelope) error {
+	b.mu.RLock()
+	handlers := append([]func(Envelope){}, b.handlers...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(envelope)
+	}
+	return nil
+}
+
+func (b *LocalBus) Subscribe(handler func(Envelope)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+	return nil
+}
+
+func (b *LocalBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = nil
+	return nil
+}
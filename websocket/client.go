@@ -63,7 +63,10 @@ func (c *Client) Read() {
 	defer func() {
 		// ceck to acoid nil pointer
 		if c.Pool != nil {
-			c.Pool.Unregister <- c
+			select {
+			case c.Pool.Unregister <- c:
+			case <-c.Pool.done:
+			}
 			c.Conn.Close()
 			db.Store.DeleteCache(c.Host)
 		}
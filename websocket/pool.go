@@ -1,16 +1,32 @@
 package websocket
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/utils"
 )
 
+var errClientNotFound = errors.New("client not found")
+
 type Pool struct {
 	Register   chan *Client
 	Unregister chan *Client
 	Clients    map[string]*ClientData
 	Broadcast  chan Message
+	shutdown   chan chan int
+	done       chan struct{}
+	mu         sync.RWMutex
+	writeMu    sync.Mutex
+	bus        Bus
+	replicaID  string
 }
 
 func NewPool() *Pool {
@@ -19,6 +35,8 @@ func NewPool() *Pool {
 		Unregister: make(chan *Client),
 		Clients:    make(map[string]*ClientData),
 		Broadcast:  make(chan Message),
+		shutdown:   make(chan chan int),
+		done:       make(chan struct{}),
 	}
 }
 
@@ -31,17 +49,19 @@ func (pool *Pool) Start() {
 				pool.Clients = make(map[string]*ClientData)
 			}
 
+			pool.mu.Lock()
 			pool.Clients[client.Host] = &ClientData{
 				Client: client,
 				Status: true,
 			}
+			pool.mu.Unlock()
 			fmt.Println("Size of Websocket Connection Pool: ", len(pool.Clients))
 			err := db.Store.SetSocketConnections(db.Client{
 				Host: client.Host,
 				Conn: client.Conn,
 			})
 			if err == nil {
-				pool.Clients[client.Host].Client.Conn.WriteJSON(Message{Type: 1, Msg: "user_connect", Body: client.Host})
+				pool.writeJSON(client.Conn, Message{Type: 1, Msg: "user_connect", Body: client.Host})
 				go client.Read()
 			} else {
 				fmt.Println("Websocket pool client save error")
@@ -49,17 +69,23 @@ func (pool *Pool) Start() {
 		case client := <-pool.Unregister:
 			// ceck to acoid nil pointer
 			if pool.Clients[client.Host] != nil {
-				pool.Clients[client.Host].Client.Conn.WriteJSON(Message{Type: 1, Body: "User Disconnected..."})
+				pool.writeJSON(pool.Clients[client.Host].Client.Conn, Message{Type: 1, Body: "User Disconnected..."})
+				pool.mu.Lock()
 				delete(pool.Clients, client.Host)
+				pool.mu.Unlock()
 				fmt.Println("Size of Connection Pool: ", len(pool.Clients))
 			}
 
+		case closed := <-pool.shutdown:
+			closed <- pool.closeAll()
+			return
+
 		case message := <-pool.Broadcast:
 			fmt.Println("Sending message to all clients in Pool")
 			// ceck to acoid nil pointer
 			if pool.Clients != nil {
 				for client, _ := range pool.Clients {
-					if err := pool.Clients[client].Client.Conn.WriteJSON(message); err != nil {
+					if err := pool.writeJSON(pool.Clients[client].Client.Conn, message); err != nil {
 						fmt.Println(err)
 						return
 					}
@@ -69,6 +95,142 @@ func (pool *Pool) Start() {
 	}
 }
 
+// Shutdown stops the pool loop and closes every client connection with a
+// close frame. It returns the number of connections that were closed.
+func (pool *Pool) Shutdown(ctx context.Context) (int, error) {
+	if pool == nil || pool.shutdown == nil {
+		return 0, nil
+	}
+
+	closed := make(chan int, 1)
+	select {
+	case pool.shutdown <- closed:
+	case <-ctx.Done():
+		return 0, fmt.Errorf("websocket pool did not stop: %w", ctx.Err())
+	}
+
+	select {
+	case count := <-closed:
+		return count, nil
+	case <-ctx.Done():
+		return 0, fmt.Errorf("websocket pool did not close connections: %w", ctx.Err())
+	}
+}
+
+func (pool *Pool) closeAll() int {
+	if pool.done != nil {
+		close(pool.done)
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	count := 0
+	deadline := time.Now().Add(time.Second)
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	for host, clientData := range pool.Clients {
+		if clientData != nil && clientData.Client != nil && clientData.Client.Conn != nil {
+			clientData.Client.Conn.WriteControl(websocket.CloseMessage, message, deadline)
+			clientData.Client.Conn.Close()
+			count++
+		}
+		delete(pool.Clients, host)
+		db.Store.DeleteCache(host)
+	}
+	return count
+}
+
+// SetBus connects the pool to a Bus so messages for sessions held by other
+// replicas still reach them.
+func (pool *Pool) SetBus(bus Bus) error {
+	pool.replicaID = utils.GetRandomToken(20)
+	if err := bus.Subscribe(pool.deliver); err != nil {
+		return err
+	}
+	pool.bus = bus
+	return nil
+}
+
+// SendToSession writes message to the websocket of sessionID. When the
+// session is not connected to this replica the message is published on the
+// bus for the replica that holds it.
+func (pool *Pool) SendToSession(sessionID string, message interface{}) error {
+	if pool == nil {
+		return fmt.Errorf("pool is nil")
+	}
+	if sessionID == "" {
+		return errClientNotFound
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	err = pool.writeLocal(sessionID, payload)
+	if !errors.Is(err, errClientNotFound) {
+		return err
+	}
+	if pool.bus == nil {
+		return fmt.Errorf("%w: %s", errClientNotFound, sessionID)
+	}
+
+	return pool.bus.Publish(context.Background(), Envelope{
+		Origin:    pool.replicaID,
+		SessionID: sessionID,
+		Payload:   payload,
+	})
+}
+
+func (pool *Pool) deliver(envelope Envelope) {
+	if envelope.Origin == pool.replicaID {
+		return
+	}
+
+	err := pool.writeLocal(envelope.SessionID, envelope.Payload)
+	if err != nil && !errors.Is(err, errClientNotFound) {
+		logger.Log.Error("[websocket] failed to deliver message to %s: %v", envelope.SessionID, err)
+	}
+}
+
+// writeLocal writes to a connection held by this replica, either a pool
+// client or a socket cached under another key such as an LNURL challenge.
+func (pool *Pool) writeLocal(sessionID string, payload []byte) error {
+	pool.mu.RLock()
+	clientData, ok := pool.Clients[sessionID]
+	pool.mu.RUnlock()
+
+	var conn *websocket.Conn
+	if ok && clientData != nil && clientData.Client != nil {
+		conn = clientData.Client.Conn
+	} else if db.Store.Cache != nil {
+		if socket, err := db.Store.GetSocketConnections(sessionID); err == nil {
+			conn = socket.Conn
+		}
+	}
+	if conn == nil {
+		return errClientNotFound
+	}
+
+	return pool.write(conn, payload)
+}
+
+// write sends payload as a text frame. Every write to a socket goes through
+// here because a websocket connection supports only one concurrent writer.
+func (pool *Pool) write(conn *websocket.Conn, payload []byte) error {
+	pool.writeMu.Lock()
+	defer pool.writeMu.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, payload)
+}
+
+func (pool *Pool) writeJSON(conn *websocket.Conn, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return pool.write(conn, payload)
+}
+
 func (pool *Pool) SendTicketMessage(message TicketMessage) error {
 
 	if pool == nil {
@@ -76,14 +238,7 @@ func (pool *Pool) SendTicketMessage(message TicketMessage) error {
 	}
 
 	if message.BroadcastType == "direct" {
-		if message.SourceSessionID == "" {
-			return fmt.Errorf("client not found")
-		}
-		// check if client
-		if client, ok := pool.Clients[message.SourceSessionID]; ok {
-			return client.Client.Conn.WriteJSON(message)
-		}
-		return fmt.Errorf("client not found: %s", message.SourceSessionID)
+		return pool.SendToSession(message.SourceSessionID, message)
 	}
 
 	return nil
@@ -96,14 +251,7 @@ func (pool *Pool) SendTicketPlanMessage(message TicketPlanMessage) error {
     }
 
     if message.BroadcastType == "direct" {
-        if message.SourceSessionID == "" {
-            return fmt.Errorf("client not found")
-        }
-
-		if client, ok := pool.Clients[message.SourceSessionID]; ok {
-            return client.Client.Conn.WriteJSON(message)
-        }
-        return fmt.Errorf("client not found: %s", message.SourceSessionID)
+        return pool.SendToSession(message.SourceSessionID, message)
     }
 
     return nil
@@ -1,12 +1,16 @@
 package websocket
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/stakwork/sphinx-tribes/db"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -574,6 +578,228 @@ func TestSendTicketMessageScenarios(t *testing.T) {
 	})
 }
 
+func TestPoolShutdown(t *testing.T) {
+	db.InitCache()
+
+	t.Run("Closes Connections With Close Frame", func(t *testing.T) {
+		closeCodes := make(chan int, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					if closeErr, ok := err.(*websocket.CloseError); ok {
+						closeCodes <- closeErr.Code
+					}
+					return
+				}
+			}
+		}))
+		defer server.Close()
+
+		ws, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:], nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pool := NewPool()
+		pool.Clients["test-client"] = &ClientData{
+			Client: &Client{Host: "test-client", Conn: ws, Pool: pool},
+			Status: true,
+		}
+		go pool.Start()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		closed, err := pool.Shutdown(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, closed)
+		assert.Empty(t, pool.Clients)
+
+		select {
+		case code := <-closeCodes:
+			assert.Equal(t, websocket.CloseGoingAway, code)
+		case <-time.After(2 * time.Second):
+			t.Fatal("client did not receive a close frame")
+		}
+	})
+
+	t.Run("Pool Not Running", func(t *testing.T) {
+		pool := NewPool()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		closed, err := pool.Shutdown(ctx)
+		assert.Error(t, err)
+		assert.Equal(t, 0, closed)
+	})
+
+	t.Run("Pool Without Shutdown Channel", func(t *testing.T) {
+		pool := &Pool{Clients: make(map[string]*ClientData)}
+
+		closed, err := pool.Shutdown(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, closed)
+	})
+}
+
+func TestSendToSessionAcrossReplicas(t *testing.T) {
+	db.InitCache()
+
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			_, p, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- string(p)
+		}
+	}))
+	defer server.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	bus := NewLocalBus()
+	sender := NewPool()
+	holder := NewPool()
+	assert.NoError(t, sender.SetBus(bus))
+	assert.NoError(t, holder.SetBus(bus))
+
+	holder.Clients["remote-session"] = &ClientData{
+		Client: &Client{Host: "remote-session", Conn: ws, Pool: holder},
+		Status: true,
+	}
+
+	t.Run("Delivers To Session On Another Replica", func(t *testing.T) {
+		err := sender.SendTicketMessage(TicketMessage{
+			BroadcastType:   "direct",
+			SourceSessionID: "remote-session",
+			Message:         "hello from another replica",
+		})
+		assert.NoError(t, err)
+
+		select {
+		case msg := <-received:
+			assert.Contains(t, msg, "hello from another replica")
+		case <-time.After(2 * time.Second):
+			t.Fatal("message was not delivered")
+		}
+	})
+
+	t.Run("Unknown Session Is Dropped Quietly", func(t *testing.T) {
+		err := sender.SendTicketPlanMessage(TicketPlanMessage{
+			BroadcastType:   "direct",
+			SourceSessionID: "unknown-session",
+			Message:         "nobody is listening",
+		})
+		assert.NoError(t, err)
+
+		select {
+		case msg := <-received:
+			t.Fatalf("unexpected message delivered: %s", msg)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("Ignores Own Messages", func(t *testing.T) {
+		holder.deliver(Envelope{
+			Origin:    holder.replicaID,
+			SessionID: "remote-session",
+			Payload:   []byte(`{"message":"echo"}`),
+		})
+
+		select {
+		case msg := <-received:
+			t.Fatalf("unexpected message delivered: %s", msg)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
+
+func TestPoolSerializesWrites(t *testing.T) {
+	db.InitCache()
+
+	received := make(chan string, 64)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			_, p, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- string(p)
+		}
+	}))
+	defer server.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	pool := NewPool()
+	go pool.Start()
+	defer pool.Shutdown(context.Background())
+
+	pool.Register <- &Client{Host: "write-session", Conn: ws}
+
+	select {
+	case msg := <-received:
+		assert.Contains(t, msg, "user_connect")
+	case <-time.After(2 * time.Second):
+		t.Fatal("connect message was not delivered")
+	}
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, pool.SendToSession("write-session", Message{Type: 1, Msg: "direct"}))
+		}()
+		go func() {
+			defer wg.Done()
+			pool.Broadcast <- Message{Type: 1, Msg: "broadcast"}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 2*writers; i++ {
+		select {
+		case <-received:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d of %d messages", i, 2*writers)
+		}
+	}
+}
+
 func setupTestWebsocket(t *testing.T) (*websocket.Conn, *httptest.Server) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		upgrader := websocket.Upgrader{}
@@ -0,0 +1,79 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+const RedisBusChannel = "tribes:websocket:messages"
+
+// RedisBus is a Bus backed by redis pub/sub, used when several replicas
+// serve websocket connections.
+type RedisBus struct {
+	client  *redis.Client
+	channel string
+	mu      sync.Mutex
+	subs    []*redis.PubSub
+}
+
+func NewRedisBus(client *redis.Client) *RedisBus {
+	return &RedisBus{
+		client:  client,
+		channel: RedisBusChannel,
+	}
+}
+
+func (b *RedisBus) Publish(ctx context.Context, envelope Envelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to encode websocket envelope: %w", err)
+	}
+	return b.client.Publish(ctx, b.channel, data).Err()
+}
+
+func (b *RedisBus) Subscribe(handler func(Envelope)) error {
+	ctx := context.Background()
+	sub := b.client.Subscribe(ctx, b.channel)
+
+	// wait for the subscription to be confirmed so no message is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return fmt.Errorf("failed to subscribe to %s: %w", b.channel, err)
+	}
+
+	b.mu.Lock()
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+
+	go func() {
+		for msg := range sub.Channel() {
+			var envelope Envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &envelope); err != nil {
+				logger.Log.Error("[websocket] invalid message on %s: %v", b.channel, err)
+				continue
+			}
+			handler(envelope)
+		}
+	}()
+
+	return nil
+}
+
+func (b *RedisBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var firstErr error
+	for _, sub := range b.subs {
+		if err := sub.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	b.subs = nil
+	return firstErr
+}
@@ -215,9 +215,11 @@ func TestServeWs(t *testing.T) {
 	})
 
 	t.Run("Origin Check for Production Host", func(t *testing.T) {
-		originalHost := config.Host
-		config.Host = "https://people.sphinx.chat"
-		defer func() { config.Host = originalHost }()
+		previous := config.Current()
+		cfg := *previous
+		cfg.Server.Host = "https://people.sphinx.chat"
+		config.SetCurrent(&cfg)
+		defer config.SetCurrent(previous)
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			pool := NewPool()